@@ -63,6 +63,22 @@ case "$HOOK_EVENT" in
         ;;
 esac
 
+# POST to flock's status server when it is enabled, falling back to the status file
+if [ -n "${FLOCK_STATUS_URL:-}" ] && command -v curl >/dev/null 2>&1; then
+    CURL_ARGS=(-fsS -m 2 -X POST --data-binary @-)
+    if [ -n "${FLOCK_STATUS_SOCKET:-}" ]; then
+        CURL_ARGS+=(--unix-socket "$FLOCK_STATUS_SOCKET")
+    fi
+    if [ -n "${FLOCK_STATUS_TOKEN:-}" ]; then
+        CURL_ARGS+=(-H "Authorization: Bearer $FLOCK_STATUS_TOKEN")
+    fi
+    if printf 'status=%s\ntask_id=%s\ntask_name=%s\nupdated=%s\ntab_name=%s\n' \
+        "$STATUS" "$TASK_ID" "$TASK_NAME" "$(date +%s)" "$TAB_NAME" |
+        curl "${CURL_ARGS[@]}" "$FLOCK_STATUS_URL" >/dev/null 2>&1; then
+        exit 0
+    fi
+fi
+
 # Ensure status directory exists
 mkdir -p "$STATUS_DIR"
 
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/setup"
+	"github.com/dfowler/flock/internal/tmux"
+	"github.com/dfowler/flock/internal/zellij"
+)
+
+// newBackend returns the multiplexer backend selected in config.
+// With no explicit choice, the backend is detected from the enclosing session.
+func newBackend(cfg *config.Config) (multiplexer.Backend, error) {
+	backend, err := newController(cfg)
+	if err != nil || cfg.HookScope != config.HookScopeProject {
+		return backend, err
+	}
+	checker, err := newHookChecker(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return projectHooksBackend{Backend: backend, checker: checker}, nil
+}
+
+// newController returns the zellij or tmux controller for the session
+func newController(cfg *config.Config) (multiplexer.Backend, error) {
+	name := cfg.Multiplexer
+	if name == "" {
+		switch {
+		case zellij.IsInZellij():
+			name = config.MultiplexerZellij
+		case tmux.IsInTmux():
+			name = config.MultiplexerTmux
+		default:
+			return nil, fmt.Errorf("flock must be run inside a zellij or tmux session")
+		}
+	}
+
+	switch name {
+	case config.MultiplexerZellij:
+		if !zellij.IsInZellij() {
+			return nil, fmt.Errorf("multiplexer is set to zellij but this is not a zellij session")
+		}
+		controller := zellij.NewController(cfg.ConfigDir())
+		controller.SetTimeout(cfg.ZellijTimeout())
+		controller.SetStatusDir(multiplexer.SessionStatusDir(controller.SessionName()))
+		return controller, nil
+	case config.MultiplexerTmux:
+		if !tmux.IsInTmux() {
+			return nil, fmt.Errorf("multiplexer is set to tmux but this is not a tmux session")
+		}
+		controller := tmux.NewController()
+		controller.SetStatusDir(multiplexer.SessionStatusDir(controller.SessionName()))
+		return controller, nil
+	default:
+		return nil, fmt.Errorf("unknown multiplexer %q (expected %q or %q)", name, config.MultiplexerZellij, config.MultiplexerTmux)
+	}
+}
+
+// projectHooksBackend registers flock's hook in the project settings of each task's
+// directory before its agent starts, for hook_scope "project"
+type projectHooksBackend struct {
+	multiplexer.Backend
+	checker *setup.Checker
+}
+
+// NewTab adds the hooks Claude Code will read in the task's directory, then opens the tab
+func (b projectHooksBackend) NewTab(l multiplexer.Launch) error {
+	if l.Agent.StatusHook == config.StatusHookClaude {
+		changed, err := b.checker.InstallProjectHooks(l.Cwd)
+		if err != nil {
+			return fmt.Errorf("failed to add flock's hooks to the project settings: %w", err)
+		}
+		if changed {
+			slog.Info("added flock's hooks to project settings", "task", l.TaskID, "dir", l.Cwd)
+		}
+	}
+	return b.Backend.NewTab(l)
+}
+
+// newAssigner returns the worktree assigner for the config, or nil if worktrees are disabled
+func newAssigner(cfg *config.Config) *git.Assigner {
+	// Set even with worktrees off, so worktrees made before are still recognized
+	git.SetWorktreeRoot(cfg.WorktreeRoot())
+	if !cfg.Worktrees.Enabled {
+		return nil
+	}
+	assigner := git.NewAssigner(true, cfg.Worktrees.MaxPerRepo, cfg.Worktrees.SpareCount)
+	assigner.SetBranchTemplate(cfg.Worktrees.BranchTemplate)
+	assigner.SetWarmCommand(cfg.Worktrees.WarmCommand)
+	assigner.SetFetchBase(cfg.Worktrees.FetchBase)
+	assigner.SetWorktreeFiles(cfg.Worktrees.CopyFiles, cfg.Worktrees.LinkFiles)
+	return assigner
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/retention"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// runCleanupCommand reports archived tasks and transcripts older than the retention
+// period, removing them with -apply
+func runCleanupCommand(args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fs := flag.NewFlagSet("flock cleanup", flag.ContinueOnError)
+	days := fs.Int("days", cfg.RetentionDays, "Remove data older than this many days (defaults to retention_days)")
+	apply := fs.Bool("apply", false, "Remove the data instead of only reporting it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: flock cleanup [-days N] [-apply]")
+	}
+	if *days <= 0 {
+		return fmt.Errorf("no retention period: set retention_days in %s or pass -days", filepath.Join(cfg.ConfigDir(), "config.json"))
+	}
+	if *apply {
+		if err := checkNotRunning(cfg); err != nil {
+			return err
+		}
+	}
+
+	manager, err := loadManager(cfg)
+	if err != nil {
+		return err
+	}
+	archive, err := task.LoadArchive(cfg.ArchivePath(), cfg.Vault())
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-time.Duration(*days) * 24 * time.Hour)
+	plan, err := retention.Find(archive, cfg.LogsDir(), cutoff, tabOpen(manager))
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Items) == 0 {
+		fmt.Printf("Nothing older than %d days\n", *days)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tDATE\tSIZE")
+	for _, item := range plan.Items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Kind, item.Name, item.ModTime.Format("2006-01-02"), formatSize(item.Size))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !*apply {
+		fmt.Printf("\n%d items older than %d days, %s would be reclaimed. Run with -apply to remove them.\n", len(plan.Items), *days, formatSize(plan.Bytes()))
+		return nil
+	}
+	removed, err := retention.Apply(plan, archive)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nRemoved %d items, reclaimed %s\n", removed, formatSize(plan.Bytes()))
+	return nil
+}
+
+// autoCleanup purges data older than retention_days when flock starts
+func autoCleanup(cfg *config.Config, manager *task.Manager) {
+	if cfg.Retention() == 0 {
+		return
+	}
+	archive, err := task.LoadArchive(cfg.ArchivePath(), cfg.Vault())
+	if err != nil {
+		slog.Warn("retention cleanup skipped", "err", err)
+		return
+	}
+	plan, err := retention.Find(archive, cfg.LogsDir(), time.Now().Add(-cfg.Retention()), tabOpen(manager))
+	if err == nil && len(plan.Items) > 0 {
+		_, err = retention.Apply(plan, archive)
+	}
+	if err != nil {
+		slog.Warn("retention cleanup failed", "err", err)
+	}
+}
+
+// tabOpen reports whether a task's tab is still open, so its transcript may still be written
+func tabOpen(manager *task.Manager) func(string) bool {
+	return func(taskID string) bool {
+		t, ok := manager.Get(taskID)
+		return ok && t.HasTab()
+	}
+}
+
+// formatSize formats a byte count in kilobytes or megabytes
+func formatSize(n int64) string {
+	if n < 1<<20 {
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/status"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// runSubcommand dispatches non-TUI subcommands (e.g. "flock tab next")
+func runSubcommand(args []string) error {
+	switch args[0] {
+	case "tab":
+		return runTabCommand(args[1:])
+	case "metrics":
+		return runMetricsCommand(args[1:])
+	case "daemon":
+		return runDaemon(args[1:])
+	case "task":
+		return runTaskCommand(args[1:])
+	case "version":
+		return runVersionCommand(args[1:])
+	case "self-update":
+		return runSelfUpdateCommand(args[1:])
+	case "import":
+		return runImportCommand(args[1:])
+	case "quick":
+		return runQuickCommand(args[1:])
+	case "tutorial":
+		return runTutorialCommand(args[1:])
+	case "search":
+		return runSearchCommand(args[1:])
+	case "templates":
+		return runTemplatesCommand(args[1:])
+	case "telemetry":
+		return runTelemetryCommand(args[1:])
+	case "cleanup":
+		return runCleanupCommand(args[1:])
+	case "prompt-segment":
+		return runPromptSegmentCommand(args[1:])
+	case "hooks":
+		return runHooksCommand(args[1:])
+	case "hook":
+		return runHookCommand(args[1:])
+	case "doctor":
+		return runDoctorCommand(args[1:])
+	case "report":
+		return runReportCommand(args[1:])
+	case "status-event":
+		return runStatusEventCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// runStatusEventCommand sends a status event read from stdin to flock's events socket.
+// The hook script runs it with the socket transport and falls back to a status file on failure.
+func runStatusEventCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: flock status-event SOCKET < event.json")
+	}
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, 64<<10))
+	if err != nil {
+		return fmt.Errorf("failed to read status event: %w", err)
+	}
+	return status.SendEvent(args[0], data)
+}
+
+// runTabCommand switches to the next or previous agent tab relative to the focused tab
+// Intended to be bound to multiplexer keys so agents can be cycled from inside any agent tab
+func runTabCommand(args []string) error {
+	if len(args) != 1 || (args[0] != "next" && args[0] != "prev") {
+		return fmt.Errorf("usage: flock tab next|prev")
+	}
+	step := 1
+	if args[0] == "prev" {
+		step = -1
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	manager, err := loadManager(cfg)
+	if err != nil {
+		return err
+	}
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the focused tab to a task; unrelated tabs fall back to the first/last agent
+	fromID := ""
+	if current, err := backend.CurrentTabName(); err == nil {
+		if t, ok := manager.FindByTabName(current); ok {
+			fromID = t.ID
+		}
+	}
+
+	t, ok := manager.CycleTabs(fromID, step)
+	if !ok {
+		return fmt.Errorf("no agent tabs to cycle through")
+	}
+	return backend.GoToTab(t.TabName)
+}
+
+// loadManager opens the default task store, unlocking it if it is encrypted, and loads its tasks
+func loadManager(cfg *config.Config) (*task.Manager, error) {
+	if err := unlockVault(cfg); err != nil {
+		return nil, err
+	}
+	store, err := task.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create store: %w", err)
+	}
+	store.SetVault(cfg.Vault())
+	store.SetBackups(cfg.StoreBackups)
+
+	manager := task.NewManager(store)
+	if err := manager.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	backfillProjects(manager)
+	sealLeftovers(cfg, manager)
+	return manager, nil
+}
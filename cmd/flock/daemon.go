@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/instance"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/status"
+	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/tasklog"
+)
+
+// runDaemon manages tasks headlessly, serving `flock task` commands over a unix socket
+// Agents are still started in the enclosing zellij/tmux session
+func runDaemon(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: flock daemon")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	defer setupLog(cfg, os.Stderr)()
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockInstance(cfg, instance.RoleDaemon)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := checkAndSetupHooks(cfg); err != nil {
+		return fmt.Errorf("setup failed: %w", err)
+	}
+
+	manager, err := loadManager(cfg)
+	if err != nil {
+		return err
+	}
+	if err := openAtRest(cfg); err != nil {
+		return err
+	}
+	defer sealAtRest(cfg, manager)
+	cleanupStatusDirs(manager)
+	autoCleanup(cfg, manager)
+	rotator := tasklog.NewRotator(cfg.TaskLogsDir(), cfg.LogMaxBytes(), cfg.Tabs.LogRotations)
+	rotator.Start()
+	defer rotator.Stop()
+
+	gitAssigner := newAssigner(cfg)
+
+	// Shut down cleanly on a signal, so the socket is removed and the tasks saved. Deferred
+	// calls run in reverse: cancel, wait for the goroutines below, then shut down the rest.
+	ctx, stop := interruptContext()
+	var wg sync.WaitGroup
+	spawn := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+
+	// Apply status hook updates to the task store, as the TUI would
+	statusChan := make(chan status.Update, 100)
+	watcher := status.NewWatcher(backend.StatusDir(), statusChan, cfg)
+	watcher.SetTaskLookup(manager.Get)
+	watcher.SetTaskList(manager.List)
+	if err := watcher.Start(ctx); err != nil {
+		stop()
+		return fmt.Errorf("failed to start status watcher: %w", err)
+	}
+	defer shutdown(watcher, gitAssigner, manager)
+	defer wg.Wait()
+	defer stop()
+	server := daemon.NewServer(manager, backend, cfg, gitAssigner)
+	spawn(func() {
+		for {
+			var update status.Update
+			select {
+			case <-ctx.Done():
+				return
+			case update = <-statusChan:
+			}
+			if t, ok := manager.Get(update.TaskID); ok {
+				oldStatus := t.Status
+				if err := manager.UpdateWaiting(update.TaskID, update.Status, update.Reason); err != nil {
+					slog.Error("failed to update status", "task", update.TaskID, "status", update.Status, "err", err)
+				}
+				server.PublishStatus(update.TaskID, update.Message)
+				if update.Event == multiplexer.EventSetup {
+					if err := server.RecordSetupFailure(update.TaskID, update.Message); err != nil {
+						slog.Error("failed to record setup failure", "task", update.TaskID, "err", err)
+					}
+				}
+				if update.Status == task.StatusDone && update.Message != "" {
+					if err := manager.Update(update.TaskID, func(t *task.Task) { t.FinalMessage = update.Message }); err != nil {
+						slog.Error("failed to save final message", "task", update.TaskID, "err", err)
+					}
+				}
+				if update.Status == task.StatusDone && oldStatus != task.StatusDone {
+					id := update.TaskID
+					spawn(func() {
+						if note, _ := server.RunTeardown(id); note != "" {
+							slog.Info(note)
+						}
+					})
+				}
+				if update.Status == task.StatusDone {
+					server.StartReadyDependents()
+				}
+			}
+		}
+	})
+
+	// Start scheduled tasks when due, beginning with runs missed while the daemon was down,
+	// nudge agents left waiting and send the scheduled report
+	spawn(func() {
+		ticker := time.NewTicker(daemon.ScheduleInterval)
+		defer ticker.Stop()
+		for now := time.Now(); ; {
+			notes := append(server.RunDue(now), server.NudgeWaiting(now)...)
+			for _, note := range append(notes, server.SendDueReport(now)...) {
+				slog.Info(note)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case now = <-ticker.C:
+			}
+		}
+	})
+
+	if err := server.Listen(cfg.SocketPath()); err != nil {
+		return err
+	}
+	spawn(func() {
+		<-ctx.Done()
+		server.Close()
+	})
+	api, err := serveAPI(cfg, server)
+	if err != nil {
+		return err
+	}
+	if api != nil {
+		spawn(func() {
+			<-ctx.Done()
+			api.Close()
+		})
+	}
+
+	slog.Info("flock daemon listening", "socket", cfg.SocketPath())
+	return server.Serve()
+}
+
+// serveAPI starts the control API on server when api.enabled is set, returning nil when
+// it is off. The daemon and the dashboard both serve it.
+func serveAPI(cfg *config.Config, server *daemon.Server) (*http.Server, error) {
+	if !cfg.API.Enabled {
+		return nil, nil
+	}
+	token, err := cfg.APIToken()
+	if err != nil {
+		return nil, err
+	}
+	api, err := server.ServeAPI(cfg.API, token)
+	if err != nil {
+		return nil, err
+	}
+	_, address := cfg.API.Listener()
+	slog.Info("flock API listening", "address", address)
+	return api, nil
+}
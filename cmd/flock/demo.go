@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/dfowler/flock/internal/batch"
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// demoAgentName is the agent the demo tasks run
+const demoAgentName = "demo"
+
+// demoAgentScript is a fake agent: it prints progress, reports status like the Claude hooks
+// do, asks for input on odd task IDs, and finishes within a minute
+const demoAgentScript = `#!/bin/sh
+status() {
+	printf '{"version":2,"status":"%s","task_id":"%s","task_name":"%s","updated":%s,"tab_name":"%s","event":"%s","message":"%s"}\n' \
+		"$1" "$FLOCK_TASK_ID" "$FLOCK_TASK_NAME" "$(date +%s)" "$FLOCK_TAB_NAME" "$2" "$3" > "$FLOCK_STATUS_DIR/$FLOCK_TASK_ID.status"
+}
+pause() {
+	sleep "$(awk -v seed="$$$n" 'BEGIN { srand(seed); print int(2 + rand() * 5) }')"
+}
+
+status WORKING UserPromptSubmit
+echo "fake agent working on: $FLOCK_TASK_NAME"
+sed -n '/^## Goal/,/^## /p' "$1" | sed '1d;$d'
+
+n=0
+for step in "Reading the prompt" "Exploring the codebase" "Planning the change" "Editing files" \
+	"Running the tests" "Fixing a failing test" "Running the tests again" "Writing a summary"; do
+	n=$((n + 1))
+	echo "[$n/8] $step..."
+	pause
+	if [ "$n" -eq 4 ]; then
+		case "$FLOCK_TASK_ID" in
+		*1 | *3 | *5 | *7 | *9)
+			status WAITING Notification "Should I update the docs as well?"
+			printf 'Should I update the docs as well? [press enter] '
+			read -r answer
+			status WORKING UserPromptSubmit
+			;;
+		esac
+	fi
+done
+
+echo "Done: 3 files changed, all tests passing (not really, this is a demo)"
+status DONE Stop
+`
+
+// demoTasks are the sample tasks a demo session starts with
+var demoTasks = []batch.Entry{
+	{Name: "add-login-form", Prompt: "Add a login form with email and password validation.", Start: demoBool(true)},
+	{Name: "fix-flaky-tests", Prompt: "Find out why the integration tests fail intermittently and fix them.", Start: demoBool(true)},
+	{Name: "dark-mode", Prompt: "Add a dark mode toggle that follows the system preference.", Start: demoBool(true)},
+	{Name: "release-notes", Prompt: "Write release notes for the login form and the test fixes.", DependsOn: []string{"add-login-form", "fix-flaky-tests"}},
+	{Name: "update-readme", Prompt: "Document the new configuration options in the README."},
+	{Name: "refactor-config", Prompt: "Split the config loader into parsing and validation."},
+}
+
+// demoBool returns a pointer to b for batch entry options
+func demoBool(b bool) *bool {
+	return &b
+}
+
+// runDemo runs the dashboard against fake agents and throwaway tasks, so flock can be
+// tried, recorded or themed without Claude installed. Nothing under ~/.flock is touched.
+func runDemo() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "flock-demo-")
+	if err != nil {
+		return fmt.Errorf("failed to create demo directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := cfg.UseDir(dir); err != nil {
+		return fmt.Errorf("failed to set up demo directory: %w", err)
+	}
+	defer setupLog(cfg, nil)()
+	script := filepath.Join(dir, "fake-agent.sh")
+	if err := os.WriteFile(script, []byte(demoAgentScript), 0755); err != nil {
+		return fmt.Errorf("failed to write demo agent: %w", err)
+	}
+	project := filepath.Join(dir, "project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		return err
+	}
+
+	// Demo tasks run the fake agent in plain directories
+	if cfg.Agents == nil {
+		cfg.Agents = make(map[string]config.AgentConfig)
+	}
+	cfg.Agents[demoAgentName] = config.AgentConfig{
+		Command:    fmt.Sprintf("sh %q {{prompt_file}}", script),
+		StatusHook: config.StatusHookNone,
+	}
+	cfg.DefaultAgent = demoAgentName
+	cfg.UseWorktree = false
+	cfg.Worktrees.Enabled = false
+	cfg.ProjectOnly = false
+	cfg.CheckForUpdates = false
+	cfg.Telemetry.Enabled = false
+	cfg.API.Enabled = false
+
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return err
+	}
+	statusDir := filepath.Join(dir, "status")
+	if err := os.MkdirAll(statusDir, 0755); err != nil {
+		return err
+	}
+	backend.SetStatusDir(statusDir)
+
+	store, err := task.NewStoreWithPath(filepath.Join(dir, "tasks.json"))
+	if err != nil {
+		return err
+	}
+	manager := task.NewManager(store)
+
+	for i := range demoTasks {
+		demoTasks[i].Cwd = project
+	}
+	requests, err := batch.Requests(demoTasks, false, false)
+	if err != nil {
+		return err
+	}
+	server := daemon.NewServer(manager, backend, cfg, nil)
+	if _, err := batch.Import(requests, server.Handle); err != nil {
+		return err
+	}
+
+	if !*debugMode {
+		if err := backend.RenameCurrentTab("flock"); err != nil {
+			slog.Warn("failed to rename tab", "err", err)
+		}
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	err = runDashboard(ctx, cfg, backend, manager, nil, false)
+
+	// Close the fake agents' tabs along with the demo
+	for _, t := range manager.List() {
+		if t.HasTab() {
+			backend.CloseTab(t.TabName)
+		}
+	}
+	return err
+}
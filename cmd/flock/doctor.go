@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/doctor"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// runDoctorCommand checks flock's prerequisites and prints each result with a fix for
+// anything that failed
+func runDoctorCommand(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: flock doctor")
+	}
+
+	env := doctor.Env{
+		Commands: runner.Exec{},
+		Getenv:   os.Getenv,
+		LookPath: exec.LookPath,
+	}
+	env.Config, env.ConfigErr = loadConfig()
+	cfg := env.Config
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if checker, err := newHookChecker(cfg); err == nil {
+		env.Hooks = checker
+	}
+	env.WorkDir, _ = os.Getwd()
+	env.StatusDir = multiplexer.SessionStatusDir("")
+	if backend, err := newBackend(cfg); err == nil {
+		env.StatusDir = backend.StatusDir()
+	}
+
+	results := doctor.Run(env)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tRESULT\tDETAILS")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Check, r.Level, r.Detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var fixes []doctor.Result
+	for _, r := range results {
+		if r.Level != doctor.Pass && r.Fix != "" {
+			fixes = append(fixes, r)
+		}
+	}
+	if len(fixes) > 0 {
+		fmt.Println("\nTo fix:")
+		for _, r := range fixes {
+			fmt.Printf("  %s: %s\n", r.Check, r.Fix)
+		}
+	}
+	if doctor.Failed(results) {
+		return fmt.Errorf("some checks failed")
+	}
+	return nil
+}
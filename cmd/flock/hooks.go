@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/setup"
+	"github.com/dfowler/flock/internal/status"
+)
+
+// newHookChecker returns the setup checker for the hook scope and type in config
+func newHookChecker(cfg *config.Config) (*setup.Checker, error) {
+	checker, err := setup.NewChecker()
+	if err != nil {
+		return nil, err
+	}
+	checker.SetProjectScope(cfg.HookScope == config.HookScopeProject)
+	if cfg.BuiltinHook() {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the flock binary for the built-in hook: %w", err)
+		}
+		checker.SetBuiltinHook(exe)
+	}
+	return checker, nil
+}
+
+// runHookCommand is the built-in status hook Claude Code runs with hook_type "builtin". It
+// reads the hook event from stdin and reports the task's status to flock as the hook
+// script would, without needing a shell.
+func runHookCommand(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: flock hook < event.json")
+	}
+	// Read it all: tool events carry the tool's input, e.g. a whole file being written
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read hook input: %w", err)
+	}
+	s, ok := status.FromHook(data, os.Getenv, time.Now())
+	if !ok {
+		return nil
+	}
+	return status.DeliverHook(s, os.Getenv)
+}
+
+// runHooksCommand dispatches "flock hooks" subcommands
+func runHooksCommand(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "test":
+			return runHooksTestCommand(args[1:])
+		case "upgrade":
+			return runHooksUpgradeCommand(args[1:])
+		case "uninstall":
+			return runHooksUninstallCommand(args[1:])
+		}
+	}
+	return fmt.Errorf("usage: flock hooks test [-script PATH] [-v] | flock hooks upgrade | flock hooks uninstall [-project DIR]")
+}
+
+// runHooksUninstallCommand removes flock's hooks from Claude's settings, keeping the user's
+// own, and deletes the hook script. With -project it only removes them from that
+// project's settings.
+func runHooksUninstallCommand(args []string) error {
+	fs := flag.NewFlagSet("flock hooks uninstall", flag.ContinueOnError)
+	project := fs.String("project", "", "Remove the hooks from this directory's .claude/settings.json only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: flock hooks uninstall [-project DIR]")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	checker, err := newHookChecker(cfg)
+	if err != nil {
+		return err
+	}
+	if *project != "" {
+		settings := checker.ForProject(*project)
+		if err := settings.RemoveClaudeSettings(); err != nil {
+			return err
+		}
+		fmt.Printf("Removed flock's hooks from %s\n", settings.GetSettingsPath())
+		return nil
+	}
+	if err := checker.RemoveClaudeSettings(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed flock's hooks from %s\n", checker.GetSettingsPath())
+
+	// A checker for the built-in hook reports the flock binary rather than the script
+	script, err := setup.NewChecker()
+	if err != nil {
+		return err
+	}
+	for _, path := range []string{script.GetHookPath(), script.GetHookPath() + ".old"} {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil {
+			fmt.Printf("Deleted %s\n", path)
+		}
+	}
+	fmt.Println("flock asks to install them again the next time the dashboard or daemon starts")
+	return nil
+}
+
+// runHooksUpgradeCommand replaces the installed hook script with this version's, keeping
+// the old one beside it
+func runHooksUpgradeCommand(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: flock hooks upgrade")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.BuiltinHook() {
+		fmt.Println("The built-in hook runs this flock binary, so there is no script to upgrade")
+		return nil
+	}
+	checker, err := setup.NewChecker()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(checker.GetHookPath()); err != nil {
+		return fmt.Errorf("no hook script installed; start flock to install it")
+	}
+	if !checker.HookOutdated() {
+		fmt.Printf("%s is up to date\n", checker.GetHookPath())
+		return nil
+	}
+	old, err := checker.UpgradeHookScript()
+	if err != nil {
+		return err
+	}
+	if err := checker.VerifyHook(runner.Exec{}); err != nil {
+		return fmt.Errorf("upgraded %s, but it failed the check: %w", checker.GetHookPath(), err)
+	}
+	fmt.Printf("Upgraded %s (the old script is in %s)\n", checker.GetHookPath(), old)
+	return nil
+}
+
+// runHooksTestCommand checks the hook installation, then feeds the hook script sample input
+// for each Claude Code event and checks the status file it writes. With hook_scope
+// "project", the settings checked are those of the current directory.
+func runHooksTestCommand(args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	checker, err := newHookChecker(cfg)
+	if err != nil {
+		return err
+	}
+	settings := checker
+	if checker.ProjectScope() {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		settings = checker.ForProject(dir)
+	}
+
+	fs := flag.NewFlagSet("flock hooks test", flag.ContinueOnError)
+	script := fs.String("script", checker.GetHookPath(), "Hook script to test")
+	verbose := fs.Bool("v", false, "Print each status file and anything the hook printed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: flock hooks test [-script PATH] [-v]")
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tRESULT\tDETAILS")
+
+	result, err := checker.Check()
+	switch {
+	case err != nil:
+		failed++
+		fmt.Fprintf(w, "installation\tFAIL\t%v\n", err)
+	case result.ScriptOutdated && *script == checker.GetHookPath():
+		fmt.Fprintf(w, "installation\tWARN\t%s is from another flock version or was edited; run flock hooks upgrade to replace it\n", *script)
+	case !result.HooksInstalled:
+		failed++
+		fmt.Fprintf(w, "installation\tFAIL\t%s; run flock to install the hooks\n", result.Message)
+	default:
+		fmt.Fprintf(w, "installation\tok\t%s\n", result.Message)
+	}
+
+	registered, err := settings.RegisteredEvents()
+	if err != nil {
+		failed++
+		fmt.Fprintf(w, "settings\tFAIL\t%v\n", err)
+	}
+	for _, event := range setup.HookEvents {
+		if err == nil && !registered[event] {
+			failed++
+			fmt.Fprintf(w, "settings: %s\tFAIL\tnot registered in %s\n", event, settings.GetSettingsPath())
+		}
+	}
+
+	if _, err := os.Stat(*script); err != nil {
+		failed++
+		fmt.Fprintf(w, "script\tFAIL\t%v\n", err)
+		w.Flush()
+		return fmt.Errorf("%d hook checks failed", failed)
+	}
+
+	hook := checker.HookCommand()
+	if *script != checker.GetHookPath() {
+		hook = runner.Command(*script)
+	}
+	results := setup.TestHook(hook, setup.HookCases, runner.Exec{})
+	for _, r := range results {
+		expected := "no status file"
+		if r.Case.Status != "" {
+			expected = r.Case.Status
+		}
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(w, "event: %s\tFAIL\t%v\n", r.Case.Name, r.Err)
+		} else {
+			fmt.Fprintf(w, "event: %s\tok\t%s\n", r.Case.Name, expected)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if *verbose {
+		for _, r := range results {
+			fmt.Printf("\n%s\n  input:  %s\n", r.Case.Name, r.Case.Input)
+			if r.Status != nil {
+				data, _ := json.Marshal(r.Status)
+				fmt.Printf("  status: %s\n", data)
+			}
+			if r.Output != "" {
+				fmt.Printf("  output: %s\n", r.Output)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d hook checks failed", failed)
+	}
+	return nil
+}
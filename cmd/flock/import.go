@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/dfowler/flock/internal/batch"
+)
+
+// runImportCommand creates the tasks described in a YAML or JSON file
+func runImportCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: flock import FILE.yaml|FILE.json")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	entries, err := batch.Load(args[0])
+	if err != nil {
+		return err
+	}
+	requests, err := batch.Requests(entries, cfg.UseWorktree, cfg.AutoStartTasks)
+	if err != nil {
+		return err
+	}
+
+	handle, err := requestHandler(cfg)
+	if err != nil {
+		return err
+	}
+
+	created, err := batch.Import(requests, handle)
+	for _, t := range created {
+		fmt.Printf("%s\t%s\t%s\n", t.ID, t.Status, t.Name)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d tasks\n", len(created))
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/instance"
+)
+
+// lockInstance makes this process the one managing the tasks, returning a function that
+// lets go. It fails when a dashboard or daemon is already running, in this zellij session
+// or another, since both would write tasks.json and clean up each other's status files.
+func lockInstance(cfg *config.Config, role string) (func(), error) {
+	lock, err := instance.Acquire(cfg.LockPath(), instance.Holder{
+		PID:     os.Getpid(),
+		Role:    role,
+		Session: os.Getenv("ZELLIJ_SESSION_NAME"),
+		Started: time.Now(),
+	})
+	var held *instance.HeldError
+	if errors.As(err, &held) {
+		return nil, fmt.Errorf("%w\n%s", err, runningHint(held.Holder))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() { lock.Release() }, nil
+}
+
+// checkNotRunning refuses to change tasks directly while a dashboard or daemon is
+// running, since its next save would undo the change
+func checkNotRunning(cfg *config.Config) error {
+	holder, running := instance.Running(cfg.LockPath())
+	if !running {
+		return nil
+	}
+	return fmt.Errorf("%s is managing the tasks\n%s", holder, runningHint(holder))
+}
+
+// runningHint tells the user what to do instead while holder is running
+func runningHint(holder instance.Holder) string {
+	if holder.Role == instance.RoleDaemon {
+		return "Use `flock task` commands while the daemon runs, or stop it first"
+	}
+	return "Switch to its tab, or quit it first"
+}
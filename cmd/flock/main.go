@@ -5,104 +5,274 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/flocklog"
 	"github.com/dfowler/flock/internal/git"
-	"github.com/dfowler/flock/internal/setup"
+	"github.com/dfowler/flock/internal/instance"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/runner"
 	"github.com/dfowler/flock/internal/status"
 	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/tasklog"
 	"github.com/dfowler/flock/internal/tui"
-	"github.com/dfowler/flock/internal/zellij"
 )
 
-const statusDir = "/tmp/flock"
+// shutdownTimeout bounds how long quitting waits for background work, e.g. a spare
+// worktree being created
+const shutdownTimeout = 10 * time.Second
 
-var debugMode = flag.Bool("debug", false, "Debug mode: skip tab rename (useful for testing in agent tabs)")
+var (
+	debugMode = flag.Bool("debug", false, "Debug mode: skip tab rename (useful for testing in agent tabs)")
+	demoMode  = flag.Bool("demo", false, "Demo mode: run the dashboard with fake agents and throwaway tasks")
+)
 
 func main() {
 	flag.Parse()
-	// Check if running in zellij
-	if !zellij.IsInZellij() {
-		fmt.Fprintln(os.Stderr, "flock must be run inside a zellij session")
-		fmt.Fprintln(os.Stderr, "Start zellij first: zellij")
-		os.Exit(1)
+
+	// Subcommands run without the TUI
+	if flag.NArg() > 0 {
+		if err := runSubcommand(flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "flock: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Check and setup global Claude hooks
-	if err := checkAndSetupHooks(); err != nil {
-		log.Fatalf("setup failed: %v", err)
+	if *demoMode {
+		if err := runDemo(); err != nil {
+			fmt.Fprintf(os.Stderr, "flock: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	// From here log output goes to ~/.flock/flock.log, where it can't draw over the dashboard
+	defer setupLog(cfg, nil)()
+
+	// Check that we're running inside a supported multiplexer
+	backend, err := newBackend(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, "Start zellij or tmux first")
+		os.Exit(1)
+	}
 
-	// Get project directory
-	cwd, err := os.Getwd()
+	// Only one dashboard or daemon may manage the tasks
+	unlock, err := lockInstance(cfg, instance.RoleDashboard)
 	if err != nil {
-		log.Fatal(err)
+		fatal("%v", err)
+	}
+	defer unlock()
+
+	// Check and setup Claude hooks
+	if err := checkAndSetupHooks(cfg); err != nil {
+		fatal("setup failed: %v", err)
+	}
+
+	// Unlock encrypted files before anything reads them
+	if err := unlockVault(cfg); err != nil {
+		fatal("%v", err)
 	}
 
 	// Initialize task store
 	store, err := task.NewStore()
 	if err != nil {
-		log.Fatalf("failed to create store: %v", err)
+		fatal("failed to create store: %v", err)
 	}
+	store.SetVault(cfg.Vault())
+	store.SetBackups(cfg.StoreBackups)
 
 	// Initialize task manager
 	manager := task.NewManager(store)
 	if err := manager.Load(); err != nil {
-		log.Printf("warning: failed to load tasks: %v", err)
+		slog.Warn("failed to load tasks", "err", err)
 	}
+	backfillProjects(manager)
 
 	// Clean up stale status files (for tasks that no longer exist)
-	cleanupStaleStatusFiles(statusDir, manager)
-
-	// Initialize zellij controller
-	zjController := zellij.NewController(cwd)
+	cleanupStatusDirs(manager)
+	autoCleanup(cfg, manager)
 
 	// Rename current tab to 'flock' (skip in debug mode)
 	if !*debugMode {
-		if err := zjController.RenameCurrentTab("flock"); err != nil {
-			log.Printf("warning: failed to rename tab: %v", err)
+		if err := backend.RenameCurrentTab("flock"); err != nil {
+			slog.Warn("failed to rename tab", "err", err)
 		}
 	}
 
 	// Initialize git worktree assigner (nil if disabled)
-	var gitAssigner *git.Assigner
-	if cfg.Worktrees.Enabled {
-		gitAssigner = git.NewAssigner(true, cfg.Worktrees.MaxPerRepo)
+	gitAssigner := newAssigner(cfg)
+
+	// Prompts and transcripts are plaintext only while flock runs
+	if err := openAtRest(cfg); err != nil {
+		fatal("%v", err)
 	}
+	ctx, stop := interruptContext()
+	defer stop()
+	err = runDashboard(ctx, cfg, backend, manager, gitAssigner, false)
+	sealAtRest(cfg, manager)
+	if err != nil {
+		fatal("%v", err)
+	}
+}
 
+// runDashboard watches the backend's status directory and runs the TUI until it quits or
+// ctx is canceled, with the tutorial's hints when tutorial is set. Background work is shut
+// down and the tasks saved before it returns.
+func runDashboard(ctx context.Context, cfg *config.Config, backend multiplexer.Backend, manager *task.Manager, gitAssigner *git.Assigner, tutorial bool) error {
 	// Create status update channel
-	statusChan := make(chan tui.StatusUpdate, 100)
+	statusChan := make(chan status.Update, 100)
 
 	// Start status watcher
-	watcher := status.NewWatcher(statusDir, statusChan, cfg)
-	if err := watcher.Start(); err != nil {
-		log.Fatalf("failed to start status watcher: %v", err)
+	watcher := status.NewWatcher(backend.StatusDir(), statusChan, cfg)
+	watcher.SetTaskLookup(manager.Get)
+	watcher.SetTaskList(manager.List)
+	if err := watcher.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start status watcher: %w", err)
+	}
+	defer shutdown(watcher, gitAssigner, manager)
+
+	// Keep agent logs from growing without bound
+	rotator := tasklog.NewRotator(cfg.TaskLogsDir(), cfg.LogMaxBytes(), cfg.Tabs.LogRotations)
+	rotator.Start()
+	defer rotator.Stop()
+
+	// The dashboard serves the control API too, handling its requests on the same server
+	server := daemon.NewServer(manager, backend, cfg, gitAssigner)
+	api, err := serveAPI(cfg, server)
+	if err != nil {
+		return err
+	}
+	if api != nil {
+		defer api.Close()
 	}
-	defer watcher.Stop()
 
 	// Create and run TUI
-	model := tui.NewModel(manager, zjController, cfg, gitAssigner, statusChan)
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	model := tui.NewModel(manager, backend, cfg, gitAssigner, statusChan)
+	model.SetServer(server)
+	if checker, err := newHookChecker(cfg); err == nil {
+		model.SetHookScript(checker)
+	}
+	if tutorial {
+		model.StartTutorial()
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(ctx))
 
-	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+	_, err = p.Run()
+	if err != nil && ctx.Err() != nil {
+		return nil // Interrupted or terminated: shut down as if the user quit
 	}
+	return err
 }
 
-// cleanupStaleStatusFiles removes status files for tasks that no longer exist
+// interruptContext returns a context canceled when flock is interrupted, terminated or
+// loses its terminal, so it can still shut down cleanly. External commands still running
+// then are killed, so a hung git fetch can't hold up the shutdown.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	runner.SetContext(ctx)
+	return ctx, stop
+}
+
+// setupLog sends flock's log to the log file in its config directory, and to echo as
+// well when set, returning a function that closes the file. If the file can't be
+// opened, the log goes to echo or nowhere.
+func setupLog(cfg *config.Config, echo io.Writer) func() {
+	level, err := flocklog.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "flock: %v\n", err)
+	}
+	closer, err := flocklog.Setup(cfg.LogPath(), level, echo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "flock: %v\n", err)
+		if echo == nil {
+			echo = io.Discard
+		}
+		log.SetOutput(echo)
+		return func() {}
+	}
+	return func() { closer.Close() }
+}
+
+// fatal reports an error that stops flock, in the log and on the terminal, and exits
+func fatal(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	slog.Error(msg)
+	fmt.Fprintf(os.Stderr, "flock: %s\n", msg)
+	os.Exit(1)
+}
+
+// loadConfig loads the config and applies its limit on how long external commands may run
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	runner.SetTimeout(cfg.CommandTimeout())
+	return cfg, nil
+}
+
+// shutdown stops background work in order: status updates first, then worktree creation,
+// then the tasks get their last save. Nothing writes the task store after it returns.
+func shutdown(watcher *status.Watcher, gitAssigner *git.Assigner, manager *task.Manager) {
+	watcher.Stop()
+	if gitAssigner != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := gitAssigner.Shutdown(ctx); err != nil {
+			slog.Warn("shutdown did not finish", "err", err)
+		}
+	}
+	if err := manager.Close(); err != nil {
+		slog.Warn("failed to save tasks", "err", err)
+	}
+}
+
+// backfillProjects keys tasks from before per-project filtering by their repository
+func backfillProjects(manager *task.Manager) {
+	_, err := manager.BackfillProjects(func(t *task.Task) string {
+		if t.RepoRoot != "" {
+			return t.RepoRoot
+		}
+		return git.ProjectRoot(t.Cwd)
+	})
+	if err != nil {
+		slog.Warn("failed to save task projects", "err", err)
+	}
+}
+
+// cleanupStatusDirs removes stale status files from every session's status directory,
+// and the directories of sessions with nothing left in them. Callers hold the instance
+// lock, which keeps two flocks from cleaning up under each other.
+func cleanupStatusDirs(manager *task.Manager) {
+	dirs, _ := filepath.Glob(filepath.Join(multiplexer.StatusRoot(), "*"))
+	for _, dir := range dirs {
+		cleanupStaleStatusFiles(dir, manager)
+		os.Remove(dir) // Fails unless empty, e.g. while agents there still run
+	}
+}
+
+// cleanupStaleStatusFiles removes status and agent PID files for tasks that no longer exist
 func cleanupStaleStatusFiles(statusDir string, manager *task.Manager) {
 	files, err := os.ReadDir(statusDir)
 	if err != nil {
@@ -110,9 +280,9 @@ func cleanupStaleStatusFiles(statusDir string, manager *task.Manager) {
 	}
 
 	for _, f := range files {
-		if !f.IsDir() && filepath.Ext(f.Name()) == ".status" {
+		if ext := filepath.Ext(f.Name()); !f.IsDir() && (ext == ".status" || ext == ".pid") {
 			// Extract task ID from filename (e.g., "001.status" -> "001")
-			taskID := f.Name()[:len(f.Name())-7] // Remove ".status"
+			taskID := strings.TrimSuffix(f.Name(), ext)
 			if _, exists := manager.Get(taskID); !exists {
 				// Task doesn't exist, remove stale status file
 				statusFile := filepath.Join(statusDir, f.Name())
@@ -122,9 +292,14 @@ func cleanupStaleStatusFiles(statusDir string, manager *task.Manager) {
 	}
 }
 
-// checkAndSetupHooks verifies and optionally installs global Claude hooks
-func checkAndSetupHooks() error {
-	checker, err := setup.NewChecker()
+// checkAndSetupHooks verifies and optionally installs the Claude hooks. Nothing is
+// checked when no agent relies on them, e.g. with Claude's status read from its transcripts.
+func checkAndSetupHooks(cfg *config.Config) error {
+	if !cfg.UsesStatusHook(config.StatusHookClaude) {
+		return nil
+	}
+
+	checker, err := newHookChecker(cfg)
 	if err != nil {
 		return err
 	}
@@ -134,8 +309,12 @@ func checkAndSetupHooks() error {
 		return err
 	}
 
-	// Already configured
+	// Already configured. A script that differs from this binary's, after an update or an
+	// edit, is offered for upgrade by the dashboard rather than replaced behind the user's back.
 	if result.HooksInstalled && !result.NeedsUserConsent {
+		if result.ScriptOutdated {
+			slog.Warn("hook script differs from this version's; run `flock hooks upgrade` to replace it", "path", checker.GetHookPath())
+		}
 		return nil
 	}
 
@@ -145,10 +324,15 @@ func checkAndSetupHooks() error {
 	fmt.Println()
 	fmt.Println(result.Message)
 	fmt.Println()
-	fmt.Println("Flock needs to install global Claude Code hooks to track agent status.")
+	fmt.Println("Flock needs to install Claude Code hooks to track agent status.")
 	fmt.Println("This will:")
 	fmt.Printf("  1. Install hook script to: %s\n", checker.GetHookPath())
-	fmt.Printf("  2. Update Claude settings: %s\n", checker.GetSettingsPath())
+	if checker.ProjectScope() {
+		fmt.Println("  2. Add them to .claude/settings.json in each task's directory as it starts,")
+		fmt.Println("     keeping your own hooks (hook_scope is \"project\")")
+	} else {
+		fmt.Printf("  2. Add them to Claude settings, keeping your own hooks: %s\n", checker.GetSettingsPath())
+	}
 	fmt.Println()
 	fmt.Println("The hooks are safe - they only activate when FLOCK_TASK_ID is set,")
 	fmt.Println("so they won't affect your normal Claude Code usage.")
@@ -166,6 +350,8 @@ func checkAndSetupHooks() error {
 		fmt.Println()
 		fmt.Println("Setup cancelled. Flock cannot function without the hooks.")
 		fmt.Println("You can manually configure the hooks later or run flock again.")
+		fmt.Println(`To track Claude Code without hooks, set "status_hook": "transcript" for the`)
+		fmt.Println(`claude agent in ~/.flock/config.json (see Agents in the README).`)
 		os.Exit(0)
 	}
 
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dfowler/flock/internal/metrics"
+)
+
+// runMetricsCommand prints merge outcomes (reverts, fixups) grouped by prompt template
+func runMetricsCommand(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: flock metrics")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	manager, err := loadManager(cfg)
+	if err != nil {
+		return err
+	}
+
+	var outcomes []metrics.Outcome
+	for _, t := range manager.List() {
+		if t.MergeCommit == "" || t.RepoRoot == "" {
+			continue
+		}
+		outcome, err := metrics.Analyze(metrics.MergedTask{
+			TaskID:       t.ID,
+			Template:     t.Template,
+			RepoRoot:     t.RepoRoot,
+			Branch:       t.GitBranch,
+			PreMergeHead: t.PreMergeHead,
+			MergeCommit:  t.MergeCommit,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping task %s: %v\n", t.ID, err)
+			continue
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	if len(outcomes) == 0 {
+		fmt.Println("No merged tasks recorded yet.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TEMPLATE\tMERGED\tREVERTED\tFIXUPS\tCLEAN")
+	for _, s := range metrics.Summarize(outcomes) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.0f%%\n", s.Template, s.Merged, s.Reverted, s.NeedsFixups, s.CleanRate()*100)
+	}
+	return w.Flush()
+}
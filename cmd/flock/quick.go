@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dfowler/flock/internal/daemon"
+)
+
+// quickNameLength is the longest task name derived from a quick goal
+const quickNameLength = 48
+
+// runQuickCommand creates and starts a task from a one-line goal in the current directory
+// Goes through a running daemon when there is one, otherwise starts the agent directly
+func runQuickCommand(args []string) error {
+	goal := strings.Join(strings.Fields(strings.Join(args, " ")), " ")
+	if goal == "" {
+		return fmt.Errorf(`usage: flock quick "GOAL"`)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	req := daemon.Request{
+		Action:      daemon.ActionAdd,
+		Name:        quickTaskName(goal),
+		Cwd:         cwd,
+		Prompt:      goal,
+		UseWorktree: cfg.UseWorktree,
+		Start:       true,
+	}
+
+	handle, err := requestHandler(cfg)
+	if err != nil {
+		return err
+	}
+	tasks, err := handle(req)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		fmt.Printf("Started task %s: %s\n", t.ID, t.Name)
+	}
+	return nil
+}
+
+// quickTaskName shortens a goal to a task name, cutting at a word boundary
+func quickTaskName(goal string) string {
+	runes := []rune(goal)
+	if len(runes) <= quickNameLength {
+		return goal
+	}
+	name := string(runes[:quickNameLength])
+	if i := strings.LastIndex(name, " "); i > quickNameLength/2 {
+		name = name[:i]
+	}
+	return name + "..."
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/dfowler/flock/internal/report"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// runReportCommand prints a summary of task activity as Markdown, or with -send saves it
+// and sends it to the configured email recipients and webhook
+func runReportCommand(args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fs := flag.NewFlagSet("flock report", flag.ContinueOnError)
+	period := fs.String("period", cfg.Reports.Period, "Span to summarize: daily or weekly (defaults to reports.period)")
+	send := fs.Bool("send", false, "Save the report and send it to the configured email and webhook")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: flock report [-period daily|weekly] [-send]")
+	}
+	span, err := report.Span(*period)
+	if err != nil {
+		return err
+	}
+
+	manager, err := loadManager(cfg)
+	if err != nil {
+		return err
+	}
+	archive, err := task.LoadArchive(cfg.ArchivePath(), cfg.Vault())
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	r := report.Build(*period, manager.List(), archive.ArchivedBefore(now), now.Add(-span), now)
+
+	if !*send {
+		fmt.Print(r.Markdown())
+		return nil
+	}
+	path, err := report.Deliver(cfg, r, runner.Exec{})
+	if path != "" {
+		fmt.Printf("Saved report to %s\n", path)
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dfowler/flock/internal/prompt"
+)
+
+// runSearchCommand prints prompt lines matching a query, across current and past prompts
+func runSearchCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: flock search QUERY")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	manager, err := loadManager(cfg)
+	if err != nil {
+		return err
+	}
+
+	matches, err := prompt.NewManager(cfg).Search(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		fmt.Println("No matches.")
+		return nil
+	}
+
+	for _, match := range matches {
+		name := "(deleted)"
+		if t, ok := manager.Get(match.TaskID); ok {
+			name = t.Name
+		}
+		where := fmt.Sprintf("line %d", match.Line)
+		if match.Snapshot {
+			where += ", older version"
+		}
+		fmt.Printf("%s %s (%s): %s\n", match.TaskID, name, where, match.Text)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/status"
+	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/vault"
+)
+
+// runPromptSegmentCommand prints a short summary of running agents for shell prompts,
+// e.g. "W2 ⏳1", reading the task store and status files without starting the TUI.
+// An encrypted store is never unlocked here; the status files are counted alone instead.
+func runPromptSegmentCommand(args []string) error {
+	fs := flag.NewFlagSet("flock prompt-segment", flag.ContinueOnError)
+	ascii := fs.Bool("ascii", false, "Use ? and ! instead of emoji for waiting and stalled agents")
+	asJSON := fs.Bool("json", false, "Print the counts for every status as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: flock prompt-segment [-ascii] [-json]")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var tasks []*task.Task
+	if !vault.Exists(cfg.VaultPath()) {
+		store, err := task.NewStore()
+		if err != nil {
+			return fmt.Errorf("failed to create store: %w", err)
+		}
+		if tasks, err = store.Load(); err != nil {
+			return fmt.Errorf("failed to load tasks: %w", err)
+		}
+	}
+	// Agents in every session count, as the task store covers them all
+	files, err := status.ReadSessions(multiplexer.StatusRoot())
+	if err != nil {
+		return err
+	}
+
+	counts := status.Count(tasks, files, time.Now(), cfg.StallThreshold())
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(counts)
+	}
+	if segment := counts.Segment(*ascii); segment != "" {
+		fmt.Println(segment)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/schedule"
+	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/timefmt"
+)
+
+const taskUsage = "usage: flock task add|start|list|delete"
+
+// runTaskCommand sends task commands to a running `flock daemon`
+func runTaskCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(taskUsage)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	req, err := parseTaskRequest(args[0], args[1:], cfg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := daemon.Send(cfg.SocketPath(), req)
+	if err != nil {
+		return err
+	}
+
+	switch req.Action {
+	case daemon.ActionList:
+		return printTasks(resp.Tasks, cfg.TimeFormat)
+	case daemon.ActionDelete:
+		fmt.Printf("Deleted task %s\n", req.TaskID)
+	default:
+		for _, t := range resp.Tasks {
+			fmt.Printf("%s\t%s\t%s\n", t.ID, t.Status, t.Name)
+		}
+	}
+	return nil
+}
+
+// requestHandler returns a function that runs task requests through a running daemon,
+// or in this process against the task store when no daemon is listening
+func requestHandler(cfg *config.Config) (func(daemon.Request) ([]*task.Task, error), error) {
+	if daemon.Running(cfg.SocketPath()) {
+		return func(req daemon.Request) ([]*task.Task, error) {
+			resp, err := daemon.Send(cfg.SocketPath(), req)
+			if resp == nil {
+				return nil, err
+			}
+			return resp.Tasks, err
+		}, nil
+	}
+
+	if err := checkNotRunning(cfg); err != nil {
+		return nil, err
+	}
+
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAndSetupHooks(cfg); err != nil {
+		return nil, fmt.Errorf("setup failed: %w", err)
+	}
+	manager, err := loadManager(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	server := daemon.NewServer(manager, backend, cfg, newAssigner(cfg))
+	return sealingHandler(cfg, manager, server), nil
+}
+
+// sealingHandler handles requests with an in-process server, encrypting the prompt files
+// they write as soon as each returns, since no dashboard or daemon is around to do it
+func sealingHandler(cfg *config.Config, manager *task.Manager, server *daemon.Server) func(daemon.Request) ([]*task.Task, error) {
+	server.SetInlinePrompts(cfg.Vault().Sealing())
+	return func(req daemon.Request) ([]*task.Task, error) {
+		tasks, err := server.Handle(req)
+		sealAfterCommand(cfg, manager)
+		return tasks, err
+	}
+}
+
+// parseTaskRequest builds a daemon request from a task subcommand and its flags
+func parseTaskRequest(action string, args []string, cfg *config.Config) (daemon.Request, error) {
+	fs := flag.NewFlagSet("flock task "+action, flag.ContinueOnError)
+	req := daemon.Request{Action: action}
+
+	switch action {
+	case daemon.ActionAdd:
+		fs.StringVar(&req.Name, "name", "", "Task name (required)")
+		fs.StringVar(&req.Cwd, "cwd", "", "Working directory (defaults to the current directory)")
+		fs.StringVar(&req.Prompt, "prompt", "", "Goal text for the prompt template")
+		fs.StringVar(&req.Agent, "agent", "", "Agent to launch (claude, aider, codex, gemini, or a configured agent)")
+		fs.Func("mode", "Permission mode: default, plan, acceptEdits or bypassPermissions", func(value string) error {
+			mode, err := task.ParsePermissionMode(value)
+			req.Permission = mode
+			return err
+		})
+		fs.BoolVar(&req.UseWorktree, "worktree", cfg.UseWorktree, "Run the task in its own git worktree")
+		fs.BoolVar(&req.Start, "start", cfg.AutoStartTasks, "Start the task immediately")
+		fs.BoolVar(&req.AutoNudge, "nudge", false, "Nudge the agent when it waits for input longer than nudge.after_minutes")
+		fs.StringVar(&req.Setup, "setup", "", "Shell command to run in the task's directory before the agent starts, e.g. \"npm install\"")
+		fs.StringVar(&req.Teardown, "teardown", "", "Shell command to run in the task's directory once the task is DONE, e.g. \"npm test\"")
+		fs.Func("attach", "File path or URL to list in the prompt's Context section (repeatable)", func(value string) error {
+			req.Attachments = append(req.Attachments, value)
+			return nil
+		})
+		fs.Func("after", "Comma-separated task IDs to wait for; the task auto-starts when they are DONE", func(value string) error {
+			req.DependsOn = strings.Split(value, ",")
+			return nil
+		})
+		fs.Func("chain", "After dependencies: start (default), worktree (reuse the first dependency's worktree), or merge", func(value string) error {
+			mode, err := task.ParseChainMode(value)
+			req.ChainMode = mode
+			return err
+		})
+		fs.BoolVar(&req.Handoff, "handoff", false, "Add the -after tasks' changes and final messages to the prompt when they are DONE")
+		fs.StringVar(&req.Schedule, "schedule", "", "Cron expression (e.g. \"0 2 * * *\" or @daily); each match starts a copy of the task")
+		fs.Func("at", "Start the task once at this time: 15:04, \"2006-01-02 15:04\" or RFC 3339", func(value string) error {
+			at, err := schedule.ParseAt(value, time.Now())
+			req.RunAt = &at
+			return err
+		})
+	case daemon.ActionDelete:
+		fs.BoolVar(&req.DeleteWorktree, "worktree", false, "Also delete the task's worktree")
+	case daemon.ActionStart, daemon.ActionList:
+	default:
+		return req, fmt.Errorf(taskUsage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return req, err
+	}
+
+	switch action {
+	case daemon.ActionAdd:
+		if req.Name == "" {
+			return req, fmt.Errorf("usage: flock task add -name NAME [-cwd DIR] [-prompt TEXT] [-agent NAME] [-mode MODE] [-attach PATH|URL] [-after IDS] [-chain MODE] [-handoff] [-schedule CRON | -at TIME] [-nudge] [-worktree] [-start]")
+		}
+		if req.Cwd == "" {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return req, err
+			}
+			req.Cwd = cwd
+		}
+	case daemon.ActionStart, daemon.ActionDelete:
+		if fs.NArg() != 1 {
+			return req, fmt.Errorf("usage: flock task %s [flags] ID", action)
+		}
+		req.TaskID = fs.Arg(0)
+	}
+	return req, nil
+}
+
+// printTasks prints tasks as a table, with run times in the given time format
+func printTasks(tasks []*task.Task, timeFormat string) error {
+	if len(tasks) == 0 {
+		fmt.Println("No tasks.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSTATUS\tBRANCH\tAGE\tNEXT RUN")
+	for _, t := range tasks {
+		var nextRun string
+		if t.Status == task.StatusPending && t.NextRun != nil {
+			nextRun = timefmt.Format(*t.NextRun, timeFormat)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.Name, t.Status, t.GitBranch, t.AgeString(), nextRun)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/telemetry"
+	"github.com/dfowler/flock/internal/timefmt"
+)
+
+// runTelemetryCommand shows, previews, enables or disables anonymous usage reporting
+func runTelemetryCommand(args []string) error {
+	action := "status"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: flock telemetry [status|preview|enable|disable]")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	switch action {
+	case "status":
+		printTelemetryStatus(cfg)
+		return nil
+
+	case "preview":
+		report, err := buildTelemetryReport(cfg)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+
+	case "enable", "disable":
+		cfg.Telemetry.Enabled = action == "enable"
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		printTelemetryStatus(cfg)
+		return nil
+
+	default:
+		return fmt.Errorf("usage: flock telemetry [status|preview|enable|disable]")
+	}
+}
+
+// printTelemetryStatus explains whether reports are sent, where, and how to inspect them
+func printTelemetryStatus(cfg *config.Config) {
+	if cfg.Telemetry.Enabled {
+		fmt.Println("Anonymous usage reporting is on.")
+	} else {
+		fmt.Println("Anonymous usage reporting is off (the default).")
+	}
+
+	endpoint := telemetry.EndpointFor(cfg)
+	if endpoint == "" {
+		fmt.Println("Endpoint: none; this build never sends reports")
+	} else {
+		fmt.Printf("Endpoint: %s\n", endpoint)
+	}
+	if sent := telemetry.LastSent(cfg.TelemetryStatePath()); !sent.IsZero() {
+		fmt.Printf("Last sent: %s\n", timefmt.Format(sent, cfg.TimeFormat))
+	}
+
+	fmt.Println()
+	fmt.Println("When on, flock sends task counts, the agents and multiplexer in use, and which")
+	fmt.Println("settings are enabled, at most once a week. Task names, prompts, paths and branch")
+	fmt.Println("names are never sent. Run `flock telemetry preview` to see the exact report.")
+}
+
+// buildTelemetryReport builds the report that would be sent right now
+func buildTelemetryReport(cfg *config.Config) (telemetry.Report, error) {
+	manager, err := loadManager(cfg)
+	if err != nil {
+		return telemetry.Report{}, err
+	}
+	installID, err := telemetry.InstallID(cfg.TelemetryStatePath())
+	if err != nil {
+		return telemetry.Report{}, err
+	}
+
+	backendName := cfg.Multiplexer
+	if backend, err := newBackend(cfg); err == nil {
+		backendName = backend.Name()
+	}
+	return telemetry.Build(cfg, manager.List(), backendName, installID), nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/dfowler/flock/internal/prompt"
+)
+
+// runTemplatesCommand pulls the shared prompt templates configured in templates.repo
+func runTemplatesCommand(args []string) error {
+	if len(args) != 1 || args[0] != "pull" {
+		return fmt.Errorf("usage: flock templates pull")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	summary, err := prompt.NewManager(cfg).SyncSharedTemplates()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Pulled %s into %s\n", summary, cfg.SharedTemplatesCheckout())
+	return nil
+}
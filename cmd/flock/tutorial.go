@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfowler/flock/internal/batch"
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// tutorialAgentScript is the tutorial's fake agent: it reports status like the Claude hooks
+// do, asks one question on add-greeting, then copies its task's pre-baked change into the
+// worktree and commits it
+const tutorialAgentScript = `#!/bin/sh
+status() {
+	printf '{"version":2,"status":"%s","task_id":"%s","task_name":"%s","updated":%s,"tab_name":"%s","event":"%s","message":"%s"}\n' \
+		"$1" "$FLOCK_TASK_ID" "$FLOCK_TASK_NAME" "$(date +%s)" "$FLOCK_TAB_NAME" "$2" "$3" > "$FLOCK_STATUS_DIR/$FLOCK_TASK_ID.status"
+}
+
+status WORKING UserPromptSubmit
+echo "fake agent working on: $FLOCK_TASK_NAME"
+sed -n '/^## Goal/,/^## /p' "$1" | sed '1d;$d'
+
+for step in "Reading the prompt" "Exploring the code" "Editing files"; do
+	echo "$step..."
+	sleep 2
+done
+if [ "$FLOCK_TASK_NAME" = "add-greeting" ]; then
+	status WAITING Notification "Should the name default to world?"
+	printf 'Should the name default to "world"? [press enter] '
+	read -r answer
+	status WORKING UserPromptSubmit
+fi
+
+cp -R "$2/$FLOCK_TASK_NAME/." .
+git add -A && git commit -q -m "$FLOCK_TASK_NAME"
+echo "Done: committed the change to $(git branch --show-current)"
+status DONE Stop
+`
+
+// tutorialFiles is the sample project the tutorial starts from
+var tutorialFiles = map[string]string{
+	"hello.sh":  "#!/bin/sh\necho \"Hello, world!\"\n",
+	"README.md": "# Sample project\n\nA tiny script for the flock tutorial. Run `sh hello.sh` to have it greeet you.\n",
+}
+
+// tutorialChanges are the files each tutorial task's agent writes, by task name
+var tutorialChanges = map[string]map[string]string{
+	"add-greeting": {"hello.sh": "#!/bin/sh\necho \"Hello, ${1:-world}!\"\n"},
+	"fix-typo":     {"README.md": "# Sample project\n\nA tiny script for the flock tutorial. Run `sh hello.sh Ada` to have it greet you.\n"},
+}
+
+// tutorialTasks are the pending tasks the tutorial walks through
+var tutorialTasks = []batch.Entry{
+	{Name: "add-greeting", Prompt: "Let hello.sh take a name: `sh hello.sh Ada` should print `Hello, Ada!`."},
+	{Name: "fix-typo", Prompt: "Fix the typo in the README."},
+}
+
+// runTutorialCommand walks a new user through starting a task, following its status,
+// reviewing its diff and merging it, in a scratch git repository with fake agents.
+// Like the demo, nothing under ~/.flock is touched and everything is deleted on exit.
+func runTutorialCommand(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: flock tutorial")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "flock-tutorial-")
+	if err != nil {
+		return fmt.Errorf("failed to create tutorial directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := cfg.UseDir(dir); err != nil {
+		return fmt.Errorf("failed to set up tutorial directory: %w", err)
+	}
+	defer setupLog(cfg, nil)()
+	project := filepath.Join(dir, "sample-project")
+	if err := createTutorialRepo(project); err != nil {
+		return err
+	}
+	changes := filepath.Join(dir, "changes")
+	for name, files := range tutorialChanges {
+		if err := writeFiles(filepath.Join(changes, name), files); err != nil {
+			return err
+		}
+	}
+	script := filepath.Join(dir, "tutorial-agent.sh")
+	if err := os.WriteFile(script, []byte(tutorialAgentScript), 0755); err != nil {
+		return fmt.Errorf("failed to write tutorial agent: %w", err)
+	}
+
+	// Tutorial tasks run the fake agent in worktrees, so each has a branch to review and merge
+	if cfg.Agents == nil {
+		cfg.Agents = make(map[string]config.AgentConfig)
+	}
+	cfg.Agents[demoAgentName] = config.AgentConfig{
+		Command:    fmt.Sprintf("sh %q {{prompt_file}} %q", script, changes),
+		StatusHook: config.StatusHookNone,
+	}
+	cfg.DefaultAgent = demoAgentName
+	cfg.UseWorktree = true
+	cfg.Worktrees.Enabled = true
+	cfg.Worktrees.SpareCount = 0
+	cfg.Worktrees.ChangelogFile = ""
+	cfg.Merge.RequireCommand = ""
+	cfg.ProjectOnly = false
+	cfg.CheckForUpdates = false
+	cfg.Telemetry.Enabled = false
+	cfg.API.Enabled = false
+
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return err
+	}
+	statusDir := filepath.Join(dir, "status")
+	if err := os.MkdirAll(statusDir, 0755); err != nil {
+		return err
+	}
+	backend.SetStatusDir(statusDir)
+
+	store, err := task.NewStoreWithPath(filepath.Join(dir, "tasks.json"))
+	if err != nil {
+		return err
+	}
+	manager := task.NewManager(store)
+	gitAssigner := newAssigner(cfg)
+
+	entries := make([]batch.Entry, len(tutorialTasks))
+	for i, e := range tutorialTasks {
+		e.Cwd = project
+		entries[i] = e
+	}
+	requests, err := batch.Requests(entries, true, false)
+	if err != nil {
+		return err
+	}
+	server := daemon.NewServer(manager, backend, cfg, gitAssigner)
+	if _, err := batch.Import(requests, server.Handle); err != nil {
+		return err
+	}
+
+	if !*debugMode {
+		if err := backend.RenameCurrentTab("flock"); err != nil {
+			slog.Warn("failed to rename tab", "err", err)
+		}
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	err = runDashboard(ctx, cfg, backend, manager, gitAssigner, true)
+
+	// Close the fake agents' tabs along with the tutorial
+	for _, t := range manager.List() {
+		if t.HasTab() {
+			backend.CloseTab(t.TabName)
+		}
+	}
+	return err
+}
+
+// createTutorialRepo creates the sample project as a git repository with one commit on main.
+// The repository gets its own identity so merges work without a global git config.
+func createTutorialRepo(dir string) error {
+	if err := writeFiles(dir, tutorialFiles); err != nil {
+		return err
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "flock tutorial"},
+		{"config", "user.email", "tutorial@flock.invalid"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "Initial commit"},
+	} {
+		output, err := runner.Exec{}.CombinedOutput(context.Background(), runner.Command("git", args...).In(dir))
+		if err != nil {
+			return fmt.Errorf("failed to create the sample project (git %s): %s", args[0], strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// writeFiles writes files, by path relative to dir, creating dir if needed
+func writeFiles(dir string, files map[string]string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/dfowler/flock/internal/update"
+)
+
+// updateTimeout bounds the release lookup and download of self-update
+const updateTimeout = 5 * time.Minute
+
+// runVersionCommand prints the running flock version
+func runVersionCommand(args []string) error {
+	fmt.Println(update.Current())
+	return nil
+}
+
+// runSelfUpdateCommand replaces the flock binary with the latest GitHub release
+func runSelfUpdateCommand(args []string) error {
+	fs := flag.NewFlagSet("flock self-update", flag.ContinueOnError)
+	force := fs.Bool("force", false, "Replace the binary even if it is a development build or up to date")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: flock self-update [-force]")
+	}
+	current := update.Current()
+	if current == "dev" && !*force {
+		return fmt.Errorf("this is a development build; pass -force to replace it with the latest release")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	defer cancel()
+
+	rel, err := update.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	if !update.Newer(current, rel.TagName) && !*force {
+		fmt.Printf("flock %s is up to date\n", current)
+		return nil
+	}
+
+	asset, err := update.AssetFor(rel, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	checksum, err := update.ChecksumFor(rel, asset)
+	if err != nil {
+		return err
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the flock binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+
+	fmt.Printf("Updating flock %s to %s...\n", current, rel.TagName)
+	if err := update.Apply(ctx, asset, checksum, exePath); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s. Restart flock (and any `flock daemon`) to use it; the hook script is refreshed on the next start.\n", exePath)
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/vault"
+	"golang.org/x/term"
+)
+
+// passphraseEnv supplies the encryption passphrase without prompting, e.g. for `flock tab next`
+const passphraseEnv = "FLOCK_PASSPHRASE"
+
+// unlockVault unlocks encrypted files with the user's passphrase, creating the vault the
+// first time encrypt_at_rest is enabled. A vault is still unlocked after encryption is
+// turned off, so its files can be read and are written back in plaintext.
+func unlockVault(cfg *config.Config) error {
+	path := cfg.VaultPath()
+	exists := vault.Exists(path)
+	if !cfg.EncryptAtRest && !exists {
+		return nil
+	}
+
+	passphrase, err := readPassphrase("Flock passphrase: ")
+	if err != nil {
+		return err
+	}
+
+	var v *vault.Vault
+	if exists {
+		v, err = vault.Unlock(path, passphrase)
+	} else {
+		if os.Getenv(passphraseEnv) == "" {
+			confirm, err := readPassphrase("Repeat passphrase: ")
+			if err != nil {
+				return err
+			}
+			if confirm != passphrase {
+				return fmt.Errorf("passphrases do not match")
+			}
+		}
+		v, err = vault.Create(path, passphrase)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unlock encrypted files: %w", err)
+	}
+
+	v.SetSealing(cfg.EncryptAtRest)
+	cfg.SetVault(v)
+	return nil
+}
+
+// readPassphrase returns $FLOCK_PASSPHRASE, or asks for the passphrase on the terminal
+func readPassphrase(prompt string) (string, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("flock's files are encrypted; set %s to unlock them", passphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return strings.TrimSpace(string(passphrase)), nil
+}
+
+// openAtRest decrypts prompt files, prompt history and transcripts in place while
+// flock runs, since agents, editors and the output panel read them directly
+func openAtRest(cfg *config.Config) error {
+	if cfg.Vault() == nil {
+		return nil
+	}
+	for _, path := range restFiles(cfg) {
+		if err := cfg.Vault().OpenFile(path); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// sealAtRest encrypts prompt files, prompt history and transcripts when flock exits,
+// returning how many were in plaintext. Transcripts of tasks whose tab is still open
+// are left alone, since the agent's output is still being appended to them.
+func sealAtRest(cfg *config.Config, manager *task.Manager) int {
+	return sealFiles(cfg, manager, false)
+}
+
+// sealAfterCommand encrypts what a one-shot command handling task requests in-process
+// wrote, like sealAtRest. The daemon server it runs hands agents their prompt's text
+// (SetInlinePrompts), except agents that read {{prompt_file}}, whose running tasks keep
+// the file in plaintext until a dashboard or daemon exits.
+func sealAfterCommand(cfg *config.Config, manager *task.Manager) int {
+	return sealFiles(cfg, manager, true)
+}
+
+// sealFiles encrypts the plaintext files restFiles lists, returning how many it sealed.
+// Transcripts of tasks with an open tab are skipped, and with keepFilePrompts so are the
+// prompt files of running tasks whose agent reads the file.
+func sealFiles(cfg *config.Config, manager *task.Manager, keepFilePrompts bool) int {
+	if !cfg.Vault().Sealing() {
+		return 0
+	}
+	sealed := 0
+	for _, path := range restFiles(cfg) {
+		if filepath.Dir(path) == cfg.TaskLogsDir() {
+			taskID, _, _ := strings.Cut(filepath.Base(path), ".")
+			if t, ok := manager.Get(taskID); ok && t.HasTab() {
+				continue
+			}
+		}
+		if keepFilePrompts && filepath.Dir(path) == cfg.PromptsDir && readsPromptFile(cfg, manager, strings.TrimSuffix(filepath.Base(path), ".md")) {
+			continue
+		}
+		if data, err := os.ReadFile(path); err != nil || vault.IsSealed(data) {
+			continue
+		}
+		if err := cfg.Vault().SealFile(path); err != nil {
+			slog.Warn("failed to encrypt", "path", path, "err", err)
+			continue
+		}
+		sealed++
+	}
+	return sealed
+}
+
+// readsPromptFile reports whether a task is running an agent that reads its prompt file
+func readsPromptFile(cfg *config.Config, manager *task.Manager, taskID string) bool {
+	t, ok := manager.Get(taskID)
+	if !ok || !t.HasTab() {
+		return false
+	}
+	agent, err := cfg.Agent(t.Agent)
+	return err == nil && strings.Contains(agent.Command, "{{prompt_file}}")
+}
+
+// sealLeftovers encrypts the files a dashboard or daemon that crashed or was killed left
+// decrypted. While one runs its files are meant to be plaintext, so they are left alone,
+// as are the prompts a one-shot command left for running agents (sealAfterCommand).
+func sealLeftovers(cfg *config.Config, manager *task.Manager) {
+	if !cfg.Vault().Sealing() || checkNotRunning(cfg) != nil {
+		return
+	}
+	if n := sealAfterCommand(cfg, manager); n > 0 {
+		fmt.Fprintf(os.Stderr, "flock didn't exit cleanly last time; encrypted the %d prompt and transcript files it left decrypted\n", n)
+	}
+}
+
+// restFiles lists the prompt, prompt history and transcript files kept under ~/.flock
+func restFiles(cfg *config.Config) []string {
+	var files []string
+	for _, pattern := range []string{
+		filepath.Join(cfg.PromptsDir, "*.md"),
+		filepath.Join(cfg.PromptsDir, "history", "*", "*.md"),
+		filepath.Join(cfg.TaskLogsDir(), "*.log"),
+		filepath.Join(cfg.TaskLogsDir(), "*.log.*"),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		files = append(files, matches...)
+	}
+	return files
+}
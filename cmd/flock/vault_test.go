@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/tmux"
+	"github.com/dfowler/flock/internal/vault"
+)
+
+func TestSealingHandler(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := vault.Create(cfg.VaultPath(), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.SetSealing(true)
+	cfg.SetVault(v)
+	store, err := task.NewStoreWithPath(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SetVault(v)
+	manager := task.NewManager(store)
+
+	// git and tmux only ever see the fake
+	fake := runner.NewFake()
+	defer git.SetRunner(git.SetRunner(fake))
+	backend := tmux.NewController()
+	backend.SetRunner(fake)
+	backend.SetStatusDir(t.TempDir())
+
+	// What `flock quick` sends when no daemon is running
+	handle := sealingHandler(cfg, manager, daemon.NewServer(manager, backend, cfg, nil))
+	tasks, err := handle(daemon.Request{Action: daemon.ActionAdd, Name: "fix tests", Cwd: t.TempDir(), Prompt: "Fix the login test", Start: true})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if !tasks[0].HasTab() {
+		t.Fatalf("expected the task to be started, got %s", tasks[0].Status)
+	}
+
+	files := restFiles(cfg)
+	if len(files) == 0 {
+		t.Fatal("expected a prompt file")
+	}
+	for _, path := range files {
+		if data, err := os.ReadFile(path); err != nil || !vault.IsSealed(data) {
+			t.Errorf("expected %s to be encrypted (%v)", path, err)
+		}
+	}
+	// With its file encrypted, the agent gets the prompt's text
+	if !strings.Contains(strings.Join(fake.Commands(), "\n"), "Fix the login test") {
+		t.Errorf("expected the prompt's text in the agent command, got:\n%s", strings.Join(fake.Commands(), "\n"))
+	}
+	if n := sealAfterCommand(cfg, manager); n != 0 {
+		t.Errorf("expected nothing left to encrypt, got %d files", n)
+	}
+}
@@ -0,0 +1,248 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/schedule"
+	"github.com/dfowler/flock/internal/task"
+	"gopkg.in/yaml.v3"
+)
+
+// Entry describes one task in an import file
+type Entry struct {
+	Name        string   `json:"name" yaml:"name"`
+	Cwd         string   `json:"cwd" yaml:"cwd"`                 // Relative paths are resolved against the import file's directory
+	Prompt      string   `json:"prompt" yaml:"prompt"`           // Goal text inserted into the template
+	Template    string   `json:"template" yaml:"template"`       // Project template name (default.md if empty)
+	Agent       string   `json:"agent" yaml:"agent"`             // Agent to launch (configured default if empty)
+	Mode        string   `json:"mode" yaml:"mode"`               // Permission mode: plan, acceptEdits or bypassPermissions
+	DependsOn   []string `json:"depends_on" yaml:"depends_on"`   // Names of other entries in the file, or existing task IDs
+	Chain       string   `json:"chain" yaml:"chain"`             // start, worktree, or merge
+	Attachments []string `json:"attachments" yaml:"attachments"` // File paths and URLs for the prompt's Context section
+	Schedule    string   `json:"schedule" yaml:"schedule"`       // Cron expression; each match starts a copy of the task
+	At          string   `json:"at" yaml:"at"`                   // Start once at this time ("15:04" or "2006-01-02 15:04")
+	Worktree    *bool    `json:"worktree" yaml:"worktree"`       // Run in a worktree (config default if unset)
+	Start       *bool    `json:"start" yaml:"start"`             // Start right away (config default if unset)
+	Nudge       *bool    `json:"nudge" yaml:"nudge"`             // Auto-nudge the agent when it waits too long (off if unset)
+	Setup       string   `json:"setup" yaml:"setup"`             // Shell command run in the task's directory before the agent starts
+	Teardown    string   `json:"teardown" yaml:"teardown"`       // Shell command run in the task's directory once it is DONE
+	Handoff     bool     `json:"handoff" yaml:"handoff"`         // Add the dependencies' changes and final messages to the prompt
+}
+
+// File is the import file format: optional defaults applied to every task, then the tasks
+type File struct {
+	Defaults Entry   `json:"defaults" yaml:"defaults"`
+	Tasks    []Entry `json:"tasks" yaml:"tasks"`
+}
+
+// Load reads an import file (.json, or YAML otherwise), applies its defaults and
+// resolves relative working directories
+func Load(path string) ([]Entry, error) {
+	path = expandHome(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var file File
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	for i := range file.Tasks {
+		e := &file.Tasks[i]
+		e.applyDefaults(file.Defaults)
+		e.Cwd = resolveDir(e.Cwd, baseDir)
+	}
+	return file.Tasks, nil
+}
+
+// applyDefaults fills unset fields from the file's defaults
+func (e *Entry) applyDefaults(d Entry) {
+	if e.Cwd == "" {
+		e.Cwd = d.Cwd
+	}
+	if e.Template == "" {
+		e.Template = d.Template
+	}
+	if e.Agent == "" {
+		e.Agent = d.Agent
+	}
+	if e.Mode == "" {
+		e.Mode = d.Mode
+	}
+	if e.Chain == "" {
+		e.Chain = d.Chain
+	}
+	if e.Worktree == nil {
+		e.Worktree = d.Worktree
+	}
+	if e.Start == nil {
+		e.Start = d.Start
+	}
+	if e.Nudge == nil {
+		e.Nudge = d.Nudge
+	}
+	if e.Setup == "" {
+		e.Setup = d.Setup
+	}
+	if e.Teardown == "" {
+		e.Teardown = d.Teardown
+	}
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// resolveDir expands ~ and makes dir absolute relative to baseDir (empty means baseDir)
+func resolveDir(dir, baseDir string) string {
+	dir = expandHome(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// Requests orders entries so dependencies come first and builds the daemon add request for each,
+// using the config defaults for worktree and start when an entry leaves them unset
+func Requests(entries []Entry, useWorktree, start bool) ([]daemon.Request, error) {
+	index := make(map[string]int)
+	for i, e := range entries {
+		if e.Name == "" {
+			return nil, fmt.Errorf("task %d has no name", i+1)
+		}
+		if _, ok := index[e.Name]; ok {
+			return nil, fmt.Errorf("duplicate task name %q", e.Name)
+		}
+		index[e.Name] = i
+	}
+
+	// Depth-first topological sort over dependencies within the file
+	order := make([]int, 0, len(entries))
+	state := make([]int, len(entries)) // 0 unvisited, 1 visiting, 2 done
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case 1:
+			return fmt.Errorf("dependency cycle involving %q", entries[i].Name)
+		case 2:
+			return nil
+		}
+		state[i] = 1
+		for _, dep := range entries[i].DependsOn {
+			if j, ok := index[dep]; ok {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		state[i] = 2
+		order = append(order, i)
+		return nil
+	}
+	for i := range entries {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	var requests []daemon.Request
+	for _, i := range order {
+		e := entries[i]
+		chainMode, err := task.ParseChainMode(e.Chain)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name, err)
+		}
+		permission, err := task.ParsePermissionMode(e.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name, err)
+		}
+		var runAt *time.Time
+		if e.At != "" {
+			at, err := schedule.ParseAt(e.At, time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", e.Name, err)
+			}
+			runAt = &at
+		}
+		req := daemon.Request{
+			Action:      daemon.ActionAdd,
+			Name:        e.Name,
+			Cwd:         e.Cwd,
+			Prompt:      strings.TrimSpace(e.Prompt),
+			Template:    e.Template,
+			Agent:       e.Agent,
+			Permission:  permission,
+			DependsOn:   e.DependsOn,
+			ChainMode:   chainMode,
+			Attachments: e.Attachments,
+			Schedule:    e.Schedule,
+			RunAt:       runAt,
+			Setup:       e.Setup,
+			Teardown:    e.Teardown,
+			Handoff:     e.Handoff,
+			UseWorktree: useWorktree,
+			Start:       start,
+		}
+		if e.Worktree != nil {
+			req.UseWorktree = *e.Worktree
+		}
+		if e.Start != nil {
+			req.Start = *e.Start
+		}
+		if e.Nudge != nil {
+			req.AutoNudge = *e.Nudge
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// Import sends the requests in order, replacing dependencies on entry names with the
+// IDs of the tasks created for them. It stops at the first failure and returns the tasks created so far.
+func Import(requests []daemon.Request, handle func(daemon.Request) ([]*task.Task, error)) ([]*task.Task, error) {
+	ids := make(map[string]string) // entry name -> created task ID
+	var created []*task.Task
+	for _, req := range requests {
+		var deps []string
+		for _, dep := range req.DependsOn {
+			if id, ok := ids[dep]; ok {
+				dep = id
+			}
+			deps = append(deps, dep)
+		}
+		req.DependsOn = deps
+
+		tasks, err := handle(req)
+		created = append(created, tasks...)
+		if err != nil {
+			return created, fmt.Errorf("failed to import %s: %w", req.Name, err)
+		}
+		for _, t := range tasks {
+			ids[req.Name] = t.ID
+		}
+	}
+	return created, nil
+}
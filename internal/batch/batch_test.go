@@ -0,0 +1,108 @@
+package batch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/task"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.yaml")
+	content := `defaults:
+  cwd: app
+  worktree: true
+tasks:
+  - name: schema
+    prompt: |
+      Add the users table
+  - name: api
+    cwd: /src/api
+    worktree: false
+    depends_on: [schema]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Cwd != filepath.Join(dir, "app") {
+		t.Errorf("expected relative cwd resolved against the file, got %q", entries[0].Cwd)
+	}
+	if entries[0].Worktree == nil || !*entries[0].Worktree {
+		t.Errorf("expected worktree default to apply")
+	}
+	if entries[1].Cwd != "/src/api" || entries[1].Worktree == nil || *entries[1].Worktree {
+		t.Errorf("expected entry values to override defaults, got %+v", entries[1])
+	}
+}
+
+func TestRequestsAndImport(t *testing.T) {
+	entries := []Entry{
+		{Name: "docs", DependsOn: []string{"api", "004"}},
+		{Name: "api", DependsOn: []string{"schema"}, Chain: "merge"},
+		{Name: "schema"},
+	}
+
+	requests, err := Requests(entries, false, true)
+	if err != nil {
+		t.Fatalf("Requests failed: %v", err)
+	}
+	var names []string
+	for _, req := range requests {
+		names = append(names, req.Name)
+	}
+	if got := strings.Join(names, ","); got != "schema,api,docs" {
+		t.Errorf("expected dependencies first, got %s", got)
+	}
+	if requests[1].ChainMode != task.ChainMerge {
+		t.Errorf("expected merge chain mode, got %q", requests[1].ChainMode)
+	}
+
+	next := 10
+	var deps []string
+	created, err := Import(requests, func(req daemon.Request) ([]*task.Task, error) {
+		deps = append(deps, req.Name+":"+strings.Join(req.DependsOn, "+"))
+		next++
+		return []*task.Task{{ID: fmt.Sprintf("%03d", next), Name: req.Name}}, nil
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(created) != 3 {
+		t.Errorf("expected 3 tasks created, got %d", len(created))
+	}
+	if got := strings.Join(deps, " "); got != "schema: api:011 docs:012+004" {
+		t.Errorf("expected names replaced with created IDs, got %s", got)
+	}
+}
+
+func TestRequestsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+	}{
+		{"missing name", []Entry{{Prompt: "x"}}},
+		{"duplicate name", []Entry{{Name: "a"}, {Name: "a"}}},
+		{"cycle", []Entry{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}}},
+		{"bad chain", []Entry{{Name: "a", Chain: "rebase"}}},
+		{"bad run time", []Entry{{Name: "a", At: "tonight"}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := Requests(tt.entries, false, false); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
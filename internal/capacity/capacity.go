@@ -0,0 +1,182 @@
+// Package capacity records how much CPU and memory running agents use, and when they hit
+// API rate limits, to suggest how many agents the machine and API tier can run at once.
+package capacity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// maxSamples is how many samples the history keeps, about eight hours at one per 30s
+const maxSamples = 1000
+
+// Headroom left for everything else on the machine
+const (
+	cpuBudget    = 0.8 // Share of all cores agents may use
+	memoryBudget = 0.7 // Share of physical memory agents may use
+)
+
+// MinSamples is how many samples with agents running a plan needs before it suggests a limit
+const MinSamples = 10
+
+// Sample is the combined resource use of the agents running at one moment
+type Sample struct {
+	At         time.Time `json:"at"`
+	Agents     int       `json:"agents"`      // WORKING agents
+	CPU        float64   `json:"cpu"`         // Percent of one core, summed over the agents
+	RSS        int64     `json:"rss"`         // Resident memory in bytes, summed
+	Load       float64   `json:"load"`        // One-minute load average (0 if unknown)
+	RateLimits int       `json:"rate_limits"` // Rate limit errors agents reported since the last sample
+}
+
+// History is the recorded samples, oldest first
+type History struct {
+	Samples []Sample `json:"samples"`
+}
+
+// Load reads the history file; a missing or unreadable file is an empty history
+func Load(path string) *History {
+	h := &History{}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, h)
+	}
+	return h
+}
+
+// Save writes the history file
+func (h *History) Save(path string) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save capacity history: %w", err)
+	}
+	return nil
+}
+
+// Add records a sample, dropping the oldest once the history is full
+func (h *History) Add(s Sample) {
+	h.Samples = append(h.Samples, s)
+	if len(h.Samples) > maxSamples {
+		h.Samples = h.Samples[len(h.Samples)-maxSamples:]
+	}
+}
+
+// rateLimitPhrases are what agents and their APIs say when throttled
+var rateLimitPhrases = []string{"rate limit", "rate_limit", "ratelimit", "429", "overloaded", "usage limit", "too many requests"}
+
+// IsRateLimit reports whether an agent's message says it was rate limited
+func IsRateLimit(message string) bool {
+	message = strings.ToLower(message)
+	for _, phrase := range rateLimitPhrases {
+		if strings.Contains(message, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// Machine is what the agents share
+type Machine struct {
+	CPUs   int
+	Memory int64 // Physical memory in bytes (0 if unknown)
+}
+
+// DetectMachine finds the number of CPUs and the physical memory, from /proc/meminfo on
+// Linux and sysctl on macOS
+func DetectMachine(r runner.Runner) Machine {
+	m := Machine{CPUs: runtime.NumCPU()}
+	if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "MemTotal:" {
+				if kb, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+					m.Memory = kb * 1024
+				}
+			}
+		}
+		return m
+	}
+	if output, err := r.Output(context.Background(), runner.Command("sysctl", "-n", "hw.memsize")); err == nil {
+		m.Memory, _ = strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	}
+	return m
+}
+
+// LoadAverage returns the one-minute load average, or 0 if it can't be read
+func LoadAverage(r runner.Runner) float64 {
+	if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) > 0 {
+			load, _ := strconv.ParseFloat(fields[0], 64)
+			return load
+		}
+	}
+	// macOS prints "{ 1.52 1.61 1.70 }"
+	if output, err := r.Output(context.Background(), runner.Command("sysctl", "-n", "vm.loadavg")); err == nil {
+		if fields := strings.Fields(strings.Trim(strings.TrimSpace(string(output)), "{}")); len(fields) > 0 {
+			load, _ := strconv.ParseFloat(fields[0], 64)
+			return load
+		}
+	}
+	return 0
+}
+
+// Plan is the planner's estimate of how many agents can run at once. A limit of 0 means
+// there wasn't enough data for it.
+type Plan struct {
+	Samples     int     // Samples with agents running
+	AgentCPU    float64 // Average percent of one core per agent
+	AgentRSS    int64   // Average resident memory per agent
+	PeakAgents  int     // Most agents seen running at once
+	PeakLoad    float64 // Highest load average seen
+	CPULimit    int     // Agents that fit in the CPU budget
+	MemoryLimit int     // Agents that fit in the memory budget
+	RateLimit   int     // Fewest agents running when a rate limit was hit, minus one
+	Suggested   int     // The smallest known limit (0 without enough data)
+}
+
+// NewPlan estimates how many agents the machine can run from the recorded samples
+func NewPlan(h *History, machine Machine) Plan {
+	var p Plan
+	var cpuPerAgent, rssPerAgent float64
+	for _, s := range h.Samples {
+		if s.Agents == 0 {
+			continue
+		}
+		p.Samples++
+		cpuPerAgent += s.CPU / float64(s.Agents)
+		rssPerAgent += float64(s.RSS) / float64(s.Agents)
+		p.PeakAgents = max(p.PeakAgents, s.Agents)
+		p.PeakLoad = max(p.PeakLoad, s.Load)
+		if s.RateLimits > 0 && (p.RateLimit == 0 || s.Agents-1 < p.RateLimit) {
+			p.RateLimit = max(s.Agents-1, 1)
+		}
+	}
+	if p.Samples < MinSamples {
+		p.RateLimit = 0
+		return p
+	}
+	p.AgentCPU = cpuPerAgent / float64(p.Samples)
+	p.AgentRSS = int64(rssPerAgent / float64(p.Samples))
+
+	if p.AgentCPU > 0 {
+		p.CPULimit = max(int(float64(machine.CPUs)*100*cpuBudget/p.AgentCPU), 1)
+	}
+	if p.AgentRSS > 0 && machine.Memory > 0 {
+		p.MemoryLimit = max(int(float64(machine.Memory)*memoryBudget/float64(p.AgentRSS)), 1)
+	}
+	for _, limit := range []int{p.CPULimit, p.MemoryLimit, p.RateLimit} {
+		if limit > 0 && (p.Suggested == 0 || limit < p.Suggested) {
+			p.Suggested = limit
+		}
+	}
+	return p
+}
@@ -0,0 +1,65 @@
+package capacity
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNewPlan(t *testing.T) {
+	const gb = 1 << 30
+	machine := Machine{CPUs: 8, Memory: 16 * gb}
+	samples := func(n, agents int, cpu float64, rss int64, rateLimits int) []Sample {
+		var s []Sample
+		for range n {
+			s = append(s, Sample{Agents: agents, CPU: cpu, RSS: rss, RateLimits: rateLimits})
+		}
+		return s
+	}
+
+	tests := []struct {
+		name      string
+		samples   []Sample
+		cpu, mem  int
+		rate      int
+		suggested int
+	}{
+		{"too few samples", samples(5, 2, 100, 2*gb, 1), 0, 0, 0, 0},
+		{"idle samples ignored", samples(20, 0, 0, 0, 0), 0, 0, 0, 0},
+		// 80% of 800% over 40% per agent, 70% of 16GB over 1GB per agent
+		{"cpu bound", samples(10, 2, 80, 2*gb, 0), 16, 11, 0, 11},
+		{"memory bound", samples(10, 4, 40, 8*gb, 0), 64, 5, 0, 5},
+		{"rate limited", append(samples(10, 2, 20, gb, 0), samples(2, 4, 40, 2*gb, 1)...), 64, 22, 3, 3},
+	}
+	for _, tt := range tests {
+		p := NewPlan(&History{Samples: tt.samples}, machine)
+		if p.CPULimit != tt.cpu || p.MemoryLimit != tt.mem || p.RateLimit != tt.rate || p.Suggested != tt.suggested {
+			t.Errorf("%s: expected limits %d/%d/%d and %d suggested, got %+v", tt.name, tt.cpu, tt.mem, tt.rate, tt.suggested, p)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capacity.json")
+	h := Load(path)
+	for i := range maxSamples + 5 {
+		h.Add(Sample{Agents: i})
+	}
+	if err := h.Save(path); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	h = Load(path)
+	if len(h.Samples) != maxSamples || h.Samples[0].Agents != 5 {
+		t.Errorf("expected the newest %d samples, got %d starting at %d", maxSamples, len(h.Samples), h.Samples[0].Agents)
+	}
+
+	for message, want := range map[string]bool{
+		"API Error: 429 Too Many Requests": true,
+		"Claude usage limit reached":       true,
+		"Overloaded, retrying":             true,
+		"Waiting for permission":           false,
+	} {
+		if IsRateLimit(message) != want {
+			t.Errorf("expected IsRateLimit(%q) to be %v", message, want)
+		}
+	}
+}
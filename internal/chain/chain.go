@@ -0,0 +1,205 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/prompt"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// DefaultMergeMessage is the merge and squash commit message used when
+// worktrees.merge_message is empty
+const DefaultMergeMessage = "{name} ({branch})\n\n{goal}"
+
+// Prepare gets a task whose dependencies are DONE ready to start according to
+// its chain mode. It returns progress notes for the user; on error the task
+// should not be started. Dependencies are merged with the trailers and changelog
+// entries the worktree settings ask for. A handoff task first gets its dependencies'
+// changes and final messages added to its prompt, before merging changes the diffs.
+func Prepare(tasks *task.Manager, t *task.Task, worktrees config.WorktreeConfig) ([]string, error) {
+	var notes []string
+
+	if t.Handoff && t.PromptFile != "" {
+		if err := prompt.ApplyHandoff(t.PromptFile, Handoffs(tasks, t)); err != nil {
+			return notes, err
+		}
+		notes = append(notes, fmt.Sprintf("Handed %s's work over to %s", strings.Join(t.DependsOn, ", "), t.Name))
+	}
+
+	switch t.ChainMode {
+	case task.ChainMerge:
+		// Merge each dependency branch that hasn't been merged yet
+		for _, id := range t.DependsOn {
+			dep, ok := tasks.Get(id)
+			if !ok || dep.GitBranch == "" || dep.RepoRoot == "" || dep.MergeCommit != "" {
+				continue
+			}
+			result, err := Merge(dep, git.StrategyMerge, worktrees)
+			if err != nil {
+				return notes, fmt.Errorf("failed to merge %s: %w", dep.GitBranch, err)
+			}
+			if !result.Success {
+				return notes, fmt.Errorf("failed to merge %s: %s", dep.GitBranch, result.Message)
+			}
+			note, err := RecordMerge(tasks, dep, result, worktrees.ChangelogFile)
+			if err != nil {
+				return notes, err
+			}
+			notes = append(notes, result.Message)
+			if note != "" {
+				notes = append(notes, note)
+			}
+		}
+
+	case task.ChainWorktree:
+		// Continue in the first dependency worktree so the task builds on its changes
+		for _, id := range t.DependsOn {
+			dep, ok := tasks.Get(id)
+			if !ok || dep.WorktreePath == "" {
+				continue
+			}
+			if err := tasks.Update(t.ID, func(t *task.Task) {
+				t.UseWorktree = true
+				t.WorktreePath = dep.WorktreePath
+				t.GitBranch = dep.GitBranch
+				t.RepoRoot = dep.RepoRoot
+			}); err != nil {
+				return notes, err
+			}
+			notes = append(notes, fmt.Sprintf("%s continues in %s's worktree (%s)", t.Name, dep.Name, dep.GitBranch))
+			break
+		}
+	}
+
+	return notes, nil
+}
+
+// Handoffs collects what each of t's dependencies passes on: its branch, a summary of
+// its changes and the agent's final message
+func Handoffs(tasks *task.Manager, t *task.Task) []prompt.Handoff {
+	var handoffs []prompt.Handoff
+	for _, id := range t.DependsOn {
+		dep, ok := tasks.Get(id)
+		if !ok {
+			continue
+		}
+		h := prompt.Handoff{TaskID: dep.ID, Name: dep.Name, Branch: dep.GitBranch, FinalMessage: dep.FinalMessage}
+		switch {
+		case dep.MergeCommit != "" && dep.PreMergeHead != "":
+			// The branch is already in the default branch, so it has nothing left to compare
+			if stat, err := git.DiffShortStat(dep.RepoRoot, dep.PreMergeHead+".."+dep.MergeCommit); err == nil && stat != "" {
+				h.Diff = "Merged: " + stat
+			}
+		case dep.GitBranch != "" && dep.RepoRoot != "":
+			if diff, err := git.GetBranchDiff(dep.RepoRoot, dep.GitBranch); err == nil {
+				h.Diff = diff
+			}
+		}
+		handoffs = append(handoffs, h)
+	}
+	return handoffs
+}
+
+// RecordMerge records a successful merge of t's branch so later reverts and fixups
+// can be traced to it. With changelogFile set, an entry for the task is first added
+// to that file in the repository and committed with the merge; if that fails the
+// merge still stands and the returned note says why.
+func RecordMerge(tasks *task.Manager, t *task.Task, result *git.MergeResult, changelogFile string) (string, error) {
+	mergeCommit, note := result.MergeCommit, ""
+	if changelogFile != "" {
+		entry := git.ChangelogEntry{
+			TaskID:  t.ID,
+			Name:    t.Name,
+			Summary: prompt.Summary(promptText(t)),
+			Branch:  t.GitBranch,
+			Date:    time.Now(),
+		}
+		// An isolated merge's changelog is committed where the merge was made, then published
+		dir := t.RepoRoot
+		if result.Dir != "" {
+			dir = result.Dir
+		}
+		if head, err := git.CommitChangelog(dir, changelogFile, entry); err != nil {
+			note = fmt.Sprintf("Changelog not updated: %v", err)
+		} else if result.Dir != "" {
+			if err := git.PublishIntegration(t.RepoRoot, result.Dir, result.MergeCommit); err != nil {
+				note = fmt.Sprintf("Changelog not updated: %v", err)
+			} else {
+				mergeCommit = head
+			}
+		} else {
+			mergeCommit = head
+		}
+	}
+	return note, tasks.RecordMerge(t.ID, result.PreMergeHead, mergeCommit)
+}
+
+// Merge merges a task's branch into the default branch with the commit message and
+// trailers the worktree settings ask for, without touching the main checkout when
+// isolated_merge is on
+func Merge(t *task.Task, strategy git.MergeStrategy, worktrees config.WorktreeConfig) (*git.MergeResult, error) {
+	message := MergeMessage(t, worktrees.MergeMessage)
+	trailers := Trailers(t, worktrees.CommitTrailers)
+	if worktrees.IsolatedMerge {
+		return git.MergeBranchIsolated(t.RepoRoot, t.GitBranch, strategy, message, trailers)
+	}
+	return git.MergeBranch(t.RepoRoot, t.GitBranch, strategy, message, trailers)
+}
+
+// UndoMerge undoes a task's recorded merge with method (git.PlanUndo picks it), without
+// touching the main checkout when isolated_merge is on
+func UndoMerge(t *task.Task, method git.UndoMethod, worktrees config.WorktreeConfig) (string, error) {
+	if worktrees.IsolatedMerge {
+		return git.UndoMergeIsolated(t.RepoRoot, t.GitBranch, t.PreMergeHead, t.MergeCommit, method)
+	}
+	return git.UndoMerge(t.RepoRoot, t.GitBranch, t.PreMergeHead, t.MergeCommit, method)
+}
+
+// MergeMessage expands a merge message template for a task. Supported placeholders are
+// {name}, {id}, {branch}, {goal} (the prompt's whole Goal section) and {summary} (its
+// first line). An empty template uses DefaultMergeMessage.
+func MergeMessage(t *task.Task, template string) string {
+	if template == "" {
+		template = DefaultMergeMessage
+	}
+	text := promptText(t)
+	message := strings.NewReplacer(
+		"{name}", t.Name,
+		"{id}", t.ID,
+		"{branch}", t.GitBranch,
+		"{goal}", prompt.Goal(text),
+		"{summary}", prompt.Summary(text),
+	).Replace(template)
+	return strings.TrimSpace(message)
+}
+
+// Trailers returns the git trailers tracing a task's merged commits back to it:
+// Flock-Task with its ID and Flock-Prompt with a hash of its prompt. None when disabled.
+func Trailers(t *task.Task, enabled bool) []string {
+	if !enabled {
+		return nil
+	}
+	trailers := []string{"Flock-Task: " + t.ID}
+	if text := promptText(t); text != "" {
+		sum := sha256.Sum256([]byte(text))
+		trailers = append(trailers, "Flock-Prompt: "+hex.EncodeToString(sum[:])[:12])
+	}
+	return trailers
+}
+
+// promptText returns a task's prompt, from its prompt file when it has one
+func promptText(t *task.Task) string {
+	if t.PromptFile != "" {
+		if content, err := os.ReadFile(t.PromptFile); err == nil {
+			return string(content)
+		}
+	}
+	return t.Prompt
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultAgentName is the agent used when neither the task nor the config picks one
+const DefaultAgentName = "claude"
+
+// Status hook strategies for agents
+const (
+	// StatusHookClaude relies on the global Claude Code hooks to report status
+	StatusHookClaude = "claude-hooks"
+	// StatusHookProcess reports WORKING when the agent starts and DONE when its process exits
+	StatusHookProcess = "process"
+	// StatusHookNone leaves the task status alone after launch
+	StatusHookNone = "none"
+	// StatusHookTranscript infers status from Claude Code's own session files, without hooks
+	StatusHookTranscript = "transcript"
+)
+
+// Keys that interrupt an agent's current turn without exiting it
+const (
+	InterruptEscape = "escape"
+	InterruptCtrlC  = "ctrl-c"
+)
+
+// AgentConfig defines how to launch a coding agent in a task's tab
+type AgentConfig struct {
+	Command      string            `json:"command"`       // Shell command; {{prompt}} and {{prompt_file}} are substituted (shell-quoted)
+	Env          map[string]string `json:"env"`           // Extra environment variables for the agent
+	StatusHook   string            `json:"status_hook"`   // "claude-hooks", "process", or "none"
+	InterruptKey string            `json:"interrupt_key"` // Key that pauses the agent: "escape" or "ctrl-c" (default)
+	// Flags added after the command name for each permission mode ("plan", "acceptEdits", "bypassPermissions")
+	PermissionFlags map[string]string `json:"permission_flags"`
+}
+
+// SupportsPermission reports whether the agent has flags for a permission mode; every agent supports the default ("")
+func (a AgentConfig) SupportsPermission(mode string) bool {
+	if mode == "" {
+		return true
+	}
+	_, ok := a.PermissionFlags[mode]
+	return ok
+}
+
+// claudePermissionFlags maps permission modes to Claude Code's --permission-mode values
+var claudePermissionFlags = map[string]string{
+	"plan":              "--permission-mode plan",
+	"acceptEdits":       "--permission-mode acceptEdits",
+	"bypassPermissions": "--permission-mode bypassPermissions",
+}
+
+// builtinAgents are available without any configuration; config entries with the same name override them
+var builtinAgents = map[string]AgentConfig{
+	"claude": {Command: "claude {{prompt}}", StatusHook: StatusHookClaude, InterruptKey: InterruptEscape, PermissionFlags: claudePermissionFlags},
+	"aider":  {Command: "aider --message-file {{prompt_file}}", StatusHook: StatusHookProcess, InterruptKey: InterruptCtrlC},
+	"codex":  {Command: "codex {{prompt}}", StatusHook: StatusHookProcess, InterruptKey: InterruptEscape},
+	"gemini": {Command: "gemini -i {{prompt}}", StatusHook: StatusHookProcess, InterruptKey: InterruptEscape},
+}
+
+// IsBuiltinAgent reports whether name is one of the agents flock ships with
+func IsBuiltinAgent(name string) bool {
+	_, ok := builtinAgents[name]
+	return ok
+}
+
+// Agent returns the agent config for name; an empty name selects the default agent
+func (c *Config) Agent(name string) (AgentConfig, error) {
+	if name == "" {
+		name = c.DefaultAgent
+	}
+	if name == "" {
+		name = DefaultAgentName
+	}
+
+	agent, ok := c.Agents[name]
+	if !ok {
+		agent, ok = builtinAgents[name]
+	}
+	if !ok {
+		return AgentConfig{}, fmt.Errorf("unknown agent %q", name)
+	}
+	if agent.PermissionFlags == nil {
+		// A configured agent overriding a built-in keeps its permission flags
+		agent.PermissionFlags = builtinAgents[name].PermissionFlags
+	}
+	if agent.StatusHook == "" {
+		agent.StatusHook = StatusHookProcess
+	}
+	if agent.InterruptKey == "" {
+		agent.InterruptKey = InterruptCtrlC
+	}
+	return agent, nil
+}
+
+// UsesStatusHook reports whether any available agent tracks its status with hook
+func (c *Config) UsesStatusHook(hook string) bool {
+	for _, name := range c.AgentNames() {
+		if agent, err := c.Agent(name); err == nil && agent.StatusHook == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// AgentNames returns the names of all available agents, default agent first
+func (c *Config) AgentNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range builtinAgents {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range c.Agents {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+
+	defaultName := c.DefaultAgent
+	if defaultName == "" {
+		defaultName = DefaultAgentName
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == defaultName) != (names[j] == defaultName) {
+			return names[i] == defaultName
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const apiTokenFileName = "api_token"
+
+// DefaultAPIAddress is where the control API listens unless api.address is set
+const DefaultAPIAddress = "127.0.0.1:7474"
+
+// APIConfig holds the daemon's HTTP control API, for scripts and other tools to manage tasks
+type APIConfig struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"` // host:port, or a unix socket path (default 127.0.0.1:7474)
+	Token   string `json:"token"`   // Bearer token required on every request (empty generates one into ~/.flock/api_token)
+}
+
+// Listener returns the network ("unix" or "tcp") and address the control API listens on
+func (a APIConfig) Listener() (network, address string) {
+	switch {
+	case a.Address == "":
+		return "tcp", DefaultAPIAddress
+	case strings.Contains(a.Address, "/"):
+		return "unix", a.Address
+	default:
+		return "tcp", a.Address
+	}
+}
+
+// APITokenPath returns the file holding the generated control API token (~/.flock/api_token)
+func (c *Config) APITokenPath() string {
+	return filepath.Join(c.configDir, apiTokenFileName)
+}
+
+// APIToken returns the token the control API requires: api.token if set, otherwise the one
+// in ~/.flock/api_token, generated on first use and readable only by the user
+func (c *Config) APIToken() (string, error) {
+	if c.API.Token != "" {
+		return c.API.Token, nil
+	}
+	path := c.APITokenPath()
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read API token: %w", err)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to save API token: %w", err)
+	}
+	return token, nil
+}
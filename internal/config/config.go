@@ -2,16 +2,41 @@ package config
 
 import (
 	"encoding/json"
+	"net"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/vault"
 )
 
 const (
 	DefaultConfigDir = ".flock"
 	configFileName   = "config.json"
 	promptsDir       = "prompts"
+	logsDir          = "logs"
+	taskLogsDir      = "tasks"
+	socketFileName   = "flock.sock"
+	logFileName      = "flock.log"
+	lockFileName     = "flock.lock"
+	updateFileName   = "update.json"
+	telemetryFile    = "telemetry.json"
+	archiveFileName  = "archive.json"
+	vaultFileName    = "vault.json"
+	reportsDir       = "reports"
+	reportFileName   = "report.json"
+	sharedTemplates  = "shared-templates"
+	capacityFileName = "capacity.json"
 )
 
+// DefaultResumeMessage is typed into a paused agent's tab when the task is resumed
+const DefaultResumeMessage = "Continue with the task where you left off."
+
+// DefaultNudgeMessage is typed into a waiting agent's tab when an auto-nudge fires
+const DefaultNudgeMessage = "Continue with your best judgment; don't wait for my input."
+
 // WorktreeCleanup defines worktree cleanup behavior on task deletion
 type WorktreeCleanup string
 
@@ -24,24 +49,308 @@ const (
 	WorktreeCleanupKeep WorktreeCleanup = "keep"
 )
 
+// Supported terminal multiplexers
+const (
+	MultiplexerZellij = "zellij"
+	MultiplexerTmux   = "tmux"
+)
+
 // WorktreeConfig holds worktree-related configuration
 type WorktreeConfig struct {
-	Enabled    bool            `json:"enabled"`
-	MaxPerRepo int             `json:"max_per_repo"`
-	Cleanup    WorktreeCleanup `json:"cleanup"`
+	Enabled        bool            `json:"enabled"`
+	MaxPerRepo     int             `json:"max_per_repo"`
+	Cleanup        WorktreeCleanup `json:"cleanup"`
+	SpareCount     int             `json:"spare_count"`     // Unassigned worktrees kept ready per repo (0 disables spares)
+	WarmCommand    string          `json:"warm_command"`    // Shell command run in each new spare worktree, such as "npm ci" or "go mod download" (empty disables)
+	CopyFiles      []string        `json:"copy_files"`      // Globs of untracked files (".env", "certs/*.pem") copied from the main checkout into a worktree when a task gets it
+	LinkFiles      []string        `json:"link_files"`      // Like copy_files, but symlinked so edits in the main checkout reach every worktree
+	GCDays         int             `json:"gc_days"`         // Worktrees view garbage collection removes unused worktrees idle this many days
+	IsolatedMerge  bool            `json:"isolated_merge"`  // Merge in a separate integration worktree, never checking out anything in the main checkout
+	FetchBase      bool            `json:"fetch_base"`      // Fetch origin before a task gets a worktree and start its branch from origin/<default> instead of the local default branch
+	Root           string          `json:"root"`            // Keep worktrees under this directory, one subdirectory per repo (e.g. "~/.flock/worktrees"), instead of <repo>/.flock-worktrees
+	MergeStrategy  string          `json:"merge_strategy"`  // "merge" (default), "squash" or "rebase", the initial choice in the merge dialog
+	MergeMessage   string          `json:"merge_message"`   // Merge and squash commit message, with {name}, {id}, {branch}, {goal} and {summary} placeholders (empty uses "{name} ({branch})\n\n{goal}")
+	BranchTemplate string          `json:"branch_template"` // Task branch names, with {id} and {task-slug} placeholders
+	ChangelogFile  string          `json:"changelog_file"`  // Append an entry for each merged task to this file in the repo (empty disables)
+	CommitTrailers bool            `json:"commit_trailers"` // Add Flock-Task/Flock-Prompt trailers to merge commits (or rebased commits)
+}
+
+// MergeConfig holds checks applied before a task's branch is merged
+type MergeConfig struct {
+	RequireCommand string `json:"require_command"` // Shell command run in the task's worktree that must pass before merging (e.g. "go test ./..."); empty disables the check
+	TimeoutMinutes int    `json:"timeout_minutes"` // How long the command may run before it counts as failed (default 10)
+}
+
+// DiffConfig holds an external program to render branch diffs with, in place of flock's
+// own highlighting
+type DiffConfig struct {
+	Pager    string `json:"pager"`    // Shell command the unified diff is piped into, e.g. "delta --paging=never --width=$COLUMNS"
+	External string `json:"external"` // Run by git for each changed file instead (GIT_EXTERNAL_DIFF), e.g. "difft"; takes precedence over pager
+}
+
+// Enabled reports whether an external diff renderer is configured
+func (d DiffConfig) Enabled() bool {
+	return strings.TrimSpace(d.Pager) != "" || strings.TrimSpace(d.External) != ""
+}
+
+// TemplatesConfig points at a git repository holding prompt templates shared by a team
+type TemplatesConfig struct {
+	Repo   string `json:"repo"`   // Git URL of the shared template library (empty disables)
+	Branch string `json:"branch"` // Branch to follow (default: the repository's default branch)
+	Path   string `json:"path"`   // Directory in the repository holding the templates (default: its root)
+}
+
+// TelemetryConfig holds the opt-in anonymous usage reporting settings
+type TelemetryConfig struct {
+	Enabled  bool   `json:"enabled"`  // Off unless the user opts in; see `flock telemetry preview`
+	Endpoint string `json:"endpoint"` // Overrides the endpoint built into flock
+}
+
+// MessagesConfig sizes the status panel's message history and filters it by level
+type MessagesConfig struct {
+	Size  int    `json:"size"`  // Messages kept (default 50)
+	Level string `json:"level"` // Lowest level shown at startup: "info" (default), "warn" or "error"
+}
+
+// DefaultAnswers are the canned replies offered for waiting agents when the config lists none
+var DefaultAnswers = []string{
+	"Yes, proceed.",
+	"Yes, and write tests for it too.",
+	"Skip the database migrations for now.",
+	"Use your best judgment.",
+	"No. Stop here and summarize what you have done so far.",
+}
+
+// NudgeConfig controls auto-nudges: a message typed into agents that sit in WAITING too long
+type NudgeConfig struct {
+	AfterMinutes int    `json:"after_minutes"` // Nudge after this long in WAITING (0 disables nudging)
+	Message      string `json:"message"`       // Typed into the tab; {{prompt}} expands to the task prompt instruction
+	MaxNudges    int    `json:"max_nudges"`    // Stop nudging a task after this many (0 means no limit)
+	AllTasks     bool   `json:"all_tasks"`     // Nudge every task, not only those with auto-nudge turned on
+	// Message per WAITING reason ("permission", "question", "plan" or "input") instead of message,
+	// e.g. {"permission": "1"} to allow the tool; "" never nudges for that reason
+	Reasons map[string]string `json:"reasons"`
+}
+
+// MessageFor returns what to type into an agent WAITING for reason, or false if it
+// shouldn't be nudged
+func (n NudgeConfig) MessageFor(reason string) (string, bool) {
+	if message, ok := n.Reasons[reason]; ok {
+		return message, message != ""
+	}
+	if n.Message == "" {
+		return DefaultNudgeMessage, true
+	}
+	return n.Message, true
+}
+
+// ReportConfig schedules summaries of task activity, saved as Markdown and optionally sent on
+type ReportConfig struct {
+	Schedule string            `json:"schedule"` // Cron expression or descriptor (e.g. "@weekly", "0 9 * * 1"); empty disables scheduled reports
+	Period   string            `json:"period"`   // Span each report covers: "daily" or "weekly" (default)
+	Dir      string            `json:"dir"`      // Where reports are saved (default ~/.flock/reports)
+	Webhook  string            `json:"webhook"`  // URL reports are POSTed to; Slack and Discord URLs get a chat message
+	Email    ReportEmailConfig `json:"email"`
+}
+
+// ReportEmailConfig sends reports by email, through an SMTP server or the local sendmail
+type ReportEmailConfig struct {
+	To          []string `json:"to"`           // Recipients; empty disables email
+	From        string   `json:"from"`         // Sender address (default flock@<hostname>)
+	SMTP        string   `json:"smtp"`         // host:port of an SMTP server; empty pipes the message to sendmail
+	Username    string   `json:"username"`     // SMTP login, if the server needs one
+	PasswordEnv string   `json:"password_env"` // Environment variable holding the SMTP password
+}
+
+// NotificationConfig selects how desktop notifications are shown and for which statuses
+type NotificationConfig struct {
+	Backend  string          `json:"backend"`  // "auto" (default), "notify-send", "terminal-notifier", "osascript" or "none"
+	Statuses map[string]bool `json:"statuses"` // Per-status switch, e.g. {"WORKING": false}; statuses not listed notify
+	Webhooks []WebhookConfig `json:"webhooks"` // Chat or HTTP endpoints status changes are POSTed to
+	// Urgency of WAITING notifications per reason ("permission", "question", "plan" or "input"),
+	// e.g. {"input": "low"}; reasons not listed are critical
+	Urgency map[string]string `json:"urgency"`
+}
+
+// WebhookConfig is an endpoint that status changes are POSTed to as JSON
+type WebhookConfig struct {
+	URL      string   `json:"url"`
+	Format   string   `json:"format"`   // "slack", "discord" or "generic"; empty detects from the URL
+	Statuses []string `json:"statuses"` // Statuses that trigger it (default WAITING and DONE)
+}
+
+// Fires reports whether a change to status should be sent to the webhook
+func (w WebhookConfig) Fires(status string) bool {
+	statuses := w.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{"WAITING", "DONE"}
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifies reports whether a change to status should raise a desktop notification
+func (n NotificationConfig) Notifies(status string) bool {
+	enabled, ok := n.Statuses[status]
+	return !ok || enabled
+}
+
+// WaitingUrgency returns the configured urgency for an agent WAITING for reason ("" if not set)
+func (n NotificationConfig) WaitingUrgency(reason string) string {
+	return n.Urgency[reason]
+}
+
+// StatusServerConfig holds the optional endpoint hook scripts POST status updates to
+// instead of writing status files
+type StatusServerConfig struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"` // host:port for HTTP, or a unix socket path (default <status dir>/status.sock)
+	Token   string `json:"token"`   // Bearer token required on every update when set; use one beyond localhost
+}
+
+// Status transports: how hook scripts deliver status updates
+const (
+	StatusTransportFile   = "file"   // Write <task-id>.status files that flock watches (default)
+	StatusTransportSocket = "socket" // Send each update over flock's events socket, acknowledged and in order
+)
+
+// Hook scopes: which Claude Code settings file flock registers its hook script in
+const (
+	HookScopeGlobal  = "global"  // ~/.claude/settings.json, for every project (default)
+	HookScopeProject = "project" // .claude/settings.json in each task's directory, added as the task starts
+)
+
+// Hook types: what Claude Code runs for flock's hook
+const (
+	HookTypeScript  = "script"  // The bash script in ~/.flock/hooks (default, except on Windows)
+	HookTypeBuiltin = "builtin" // `flock hook`, which needs no shell (default on Windows)
+)
+
+// BuiltinHook reports whether Claude Code runs `flock hook` rather than the bash hook script
+func (c *Config) BuiltinHook() bool {
+	if c.HookType == "" {
+		return runtime.GOOS == "windows"
+	}
+	return c.HookType == HookTypeBuiltin
+}
+
+// EventsSocketPath returns the unix socket hooks send status events to with the socket transport
+func EventsSocketPath(statusDir string) string {
+	return filepath.Join(statusDir, "events.sock")
+}
+
+// Listener returns the network ("unix" or "tcp") and address the status server listens on
+func (s StatusServerConfig) Listener(statusDir string) (network, address string) {
+	switch {
+	case s.Address == "":
+		return "unix", filepath.Join(statusDir, "status.sock")
+	case strings.Contains(s.Address, "/"):
+		return "unix", s.Address
+	default:
+		return "tcp", s.Address
+	}
+}
+
+// URL returns the URL hook scripts POST to, and the unix socket to reach it through (if any)
+func (s StatusServerConfig) URL(statusDir string) (url, socket string) {
+	network, address := s.Listener(statusDir)
+	if network == "unix" {
+		return "http://localhost/status", address
+	}
+	// Agents on this machine reach a wildcard listener through loopback
+	if host, port, err := net.SplitHostPort(address); err == nil && (host == "" || host == "0.0.0.0" || host == "::") {
+		address = net.JoinHostPort("127.0.0.1", port)
+	}
+	return "http://" + address + "/status", ""
+}
+
+// TabConfig holds agent tab configuration
+type TabConfig struct {
+	// AutoCloseDoneMinutes closes a DONE task's tab after this many minutes (0 disables)
+	AutoCloseDoneMinutes int  `json:"auto_close_done_minutes"`
+	CaptureOutput        bool `json:"capture_output"` // Record agent output to ~/.flock/logs/tasks/<id>.log
+	LogMaxMB             int  `json:"log_max_mb"`     // Rotate a task's log once it grows past this size (0 disables)
+	LogRotations         int  `json:"log_rotations"`  // Rotated copies kept per task (<id>.log.1, .2, ...)
+}
+
+// ColumnConfig defines an extra dashboard column whose value comes from a shell command
+type ColumnConfig struct {
+	Name            string `json:"name"`             // Column header
+	Command         string `json:"command"`          // Run with sh -c in the task's worktree (or cwd)
+	Width           int    `json:"width"`            // Column width in characters (default 8)
+	IntervalSeconds int    `json:"interval_seconds"` // How often to re-run the command (default 60)
+}
+
+// ColumnWidth returns the configured width, falling back to the default
+func (c ColumnConfig) ColumnWidth() int {
+	if c.Width <= 0 {
+		return 8
+	}
+	if c.Width < 4 {
+		return 4 // room for a truncated value
+	}
+	return c.Width
+}
+
+// Interval returns how often the column should be refreshed
+func (c ColumnConfig) Interval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
 }
 
 // Config holds flock configuration
 type Config struct {
-	PromptsDir           string         `json:"prompts_dir"`
-	NotificationsEnabled bool           `json:"notifications_enabled"`
-	AutoStartTasks       bool           `json:"auto_start_tasks"`
-	ConfirmBeforeDelete  bool           `json:"confirm_before_delete"`
-	UseWorktree          bool           `json:"use_worktree"` // Default for new tasks
-	Worktrees            WorktreeConfig `json:"worktrees"`
+	PromptsDir            string                 `json:"prompts_dir"`
+	NotificationsEnabled  bool                   `json:"notifications_enabled"`
+	Notifications         NotificationConfig     `json:"notifications"` // Desktop notification backend and which statuses notify
+	Messages              MessagesConfig         `json:"messages"`      // Status panel history size and level filter
+	AutoStartTasks        bool                   `json:"auto_start_tasks"`
+	ConfirmBeforeDelete   bool                   `json:"confirm_before_delete"`
+	UseWorktree           bool                   `json:"use_worktree"`      // Default for new tasks
+	ProjectOnly           bool                   `json:"project_only"`      // Show only the tasks of the project flock runs in
+	TaskSort              string                 `json:"task_sort"`         // Task list order: "" (creation), "status", "age" or "name"
+	BoardView             bool                   `json:"board_view"`        // Show tasks as a board with a column per status instead of a table
+	CheckForUpdates       bool                   `json:"check_for_updates"` // Look for new releases on GitHub once a day
+	StallMinutes          int                    `json:"stall_minutes"`     // Mark WORKING tasks STALLED after this long without a status update (0 disables)
+	ResumeMessage         string                 `json:"resume_message"`    // Typed into a paused task's tab on resume; {{prompt}} expands to the task prompt instruction
+	Nudge                 NudgeConfig            `json:"nudge"`             // Auto-nudge agents left waiting for input
+	Answers               []string               `json:"answers"`           // Canned replies sent to waiting agents from the dashboard
+	EncryptAtRest         bool                   `json:"encrypt_at_rest"`   // Encrypt tasks, prompts and transcripts with a passphrase
+	RetentionDays         int                    `json:"retention_days"`    // Purge archived tasks and transcripts older than this on start (0 keeps everything)
+	StoreBackups          int                    `json:"store_backups"`     // Earlier versions of tasks.json kept as tasks.json.1 to .N, at most one per 10 minutes (default 5)
+	Reports               ReportConfig           `json:"reports"`           // Scheduled daily or weekly activity summaries
+	Worktrees             WorktreeConfig         `json:"worktrees"`
+	Merge                 MergeConfig            `json:"merge"`     // Command that must pass before merging
+	Diff                  DiffConfig             `json:"diff"`      // External diff renderer (delta, difftastic)
+	Templates             TemplatesConfig        `json:"templates"` // Shared prompt templates pulled from a git repository
+	Tabs                  TabConfig              `json:"tabs"`
+	Telemetry             TelemetryConfig        `json:"telemetry"`
+	StatusServer          StatusServerConfig     `json:"status_server"`
+	API                   APIConfig              `json:"api"`                     // HTTP control API served by the daemon
+	StatusTransport       string                 `json:"status_transport"`        // "file" (default) or "socket" for hooks to send updates to flock directly
+	HookScope             string                 `json:"hook_scope"`              // "global" (default) or "project" to register the hook in each project's .claude/settings.json
+	HookType              string                 `json:"hook_type"`               // "script" (bash, the default) or "builtin" for `flock hook` (the default on Windows)
+	Columns               []ColumnConfig         `json:"columns"`                 // Custom dashboard columns
+	Multiplexer           string                 `json:"multiplexer"`             // "zellij" or "tmux" (empty detects from the session)
+	ZellijTimeoutSeconds  int                    `json:"zellij_timeout_seconds"`  // How long a zellij command may take before it counts as hung (default 5)
+	CommandTimeoutSeconds int                    `json:"command_timeout_seconds"` // How long git, tmux and other external commands may run before they are killed (default 120)
+	Agents                map[string]AgentConfig `json:"agents"`                  // Custom agents (override built-in claude/aider/codex/gemini)
+	DefaultAgent          string                 `json:"default_agent"`           // Agent for new tasks (empty means claude)
+	MaxConcurrentTasks    int                    `json:"max_concurrent_tasks"`    // Warn before starting more agents than this at once (0 uses the capacity planner's suggestion)
+	TimeFormat            string                 `json:"time_format"`             // "local" (default) or "iso" for ISO 8601 timestamps in task details and CLI output
+	LogLevel              string                 `json:"log_level"`               // Lowest level written to ~/.flock/flock.log: "debug", "info" (default), "warn" or "error"
 
 	// Internal paths (not saved to config file)
 	configDir string
+
+	// Unlocked vault for encrypted files (nil when encryption was never enabled)
+	vault *vault.Vault
 }
 
 // Load loads configuration from ~/.flock/config.json
@@ -63,10 +372,28 @@ func Load() (*Config, error) {
 		AutoStartTasks:       false, // disabled by default
 		ConfirmBeforeDelete:  true,  // enabled by default
 		UseWorktree:          true,  // enabled by default
+		CheckForUpdates:      true,  // enabled by default
+		StallMinutes:         30,    // half an hour without a hook firing
+		ResumeMessage:        DefaultResumeMessage,
+		Answers:              append([]string(nil), DefaultAnswers...),
+		Nudge: NudgeConfig{
+			AfterMinutes: 10,
+			Message:      DefaultNudgeMessage,
+			MaxNudges:    3, // an agent that keeps asking probably needs a person
+		},
 		Worktrees: WorktreeConfig{
-			Enabled:    true,               // enabled by default
-			MaxPerRepo: 10,                 // reasonable default limit
-			Cleanup:    WorktreeCleanupAsk, // prompt by default
+			Enabled:        true,               // enabled by default
+			MaxPerRepo:     10,                 // reasonable default limit
+			Cleanup:        WorktreeCleanupAsk, // prompt by default
+			SpareCount:     1,                  // keep one spare ready
+			BranchTemplate: "flock-{id}",       // branch names like flock-007
+			GCDays:         14,                 // two weeks without a task or a commit
+			CommitTrailers: true,               // trace merged code back to its task
+		},
+		Tabs: TabConfig{
+			CaptureOutput: true, // enabled by default
+			LogMaxMB:      10,
+			LogRotations:  3,
 		},
 		configDir: configDir,
 	}
@@ -95,6 +422,7 @@ func Load() (*Config, error) {
 	if err := cfg.ensureDirectories(); err != nil {
 		return nil, err
 	}
+	cfg.migrateTranscripts()
 
 	return cfg, nil
 }
@@ -110,9 +438,33 @@ func (c *Config) Save() error {
 	return os.WriteFile(configPath, data, 0644)
 }
 
-// ensureDirectories creates the prompts directory if it doesn't exist
+// ensureDirectories creates the prompts and logs directories if they don't exist
 func (c *Config) ensureDirectories() error {
-	return os.MkdirAll(c.PromptsDir, 0755)
+	if err := os.MkdirAll(c.PromptsDir, 0755); err != nil {
+		return err
+	}
+	return os.MkdirAll(c.TaskLogsDir(), 0755)
+}
+
+// migrateTranscripts moves transcripts from ~/.flock/logs, where they were kept
+// before per-task logs had their own directory, into ~/.flock/logs/tasks
+func (c *Config) migrateTranscripts() {
+	matches, _ := filepath.Glob(filepath.Join(c.LogsDir(), "*.log"))
+	for _, path := range matches {
+		target := filepath.Join(c.TaskLogsDir(), filepath.Base(path))
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		os.Rename(path, target)
+	}
+}
+
+// UseDir keeps all of flock's files (prompts, logs, state) under dir instead of ~/.flock,
+// so a throwaway session cannot touch the real ones. The loaded settings are kept.
+func (c *Config) UseDir(dir string) error {
+	c.configDir = dir
+	c.PromptsDir = filepath.Join(dir, promptsDir)
+	return c.ensureDirectories()
 }
 
 // ConfigDir returns the base config directory (~/.flock)
@@ -124,3 +476,172 @@ func (c *Config) ConfigDir() string {
 func (c *Config) PromptFilePath(taskID string) string {
 	return filepath.Join(c.PromptsDir, taskID+".md")
 }
+
+// LogsDir returns the directory holding flock's logs (~/.flock/logs)
+func (c *Config) LogsDir() string {
+	return filepath.Join(c.configDir, logsDir)
+}
+
+// TaskLogsDir returns the directory holding per-task transcripts (~/.flock/logs/tasks)
+func (c *Config) TaskLogsDir() string {
+	return filepath.Join(c.LogsDir(), taskLogsDir)
+}
+
+// TranscriptPath returns the path where a task's terminal transcript is saved
+func (c *Config) TranscriptPath(taskID string) string {
+	return filepath.Join(c.TaskLogsDir(), taskID+".log")
+}
+
+// HookLogPath returns where the output of a task's setup or teardown command is saved
+func (c *Config) HookLogPath(taskID, hook string) string {
+	return filepath.Join(c.TaskLogsDir(), taskID+"."+hook+".log")
+}
+
+// LogMaxBytes returns the size at which a task's log is rotated, or 0 if rotation is disabled
+func (c *Config) LogMaxBytes() int64 {
+	if c.Tabs.LogMaxMB <= 0 {
+		return 0
+	}
+	return int64(c.Tabs.LogMaxMB) << 20
+}
+
+// CaptureLogPath returns where a launched agent's output is recorded live, or "" if capture is disabled
+func (c *Config) CaptureLogPath(taskID string) string {
+	if !c.Tabs.CaptureOutput {
+		return ""
+	}
+	return c.TranscriptPath(taskID)
+}
+
+// StallThreshold returns how long a WORKING task may go without a status update before
+// it is marked STALLED, or 0 if stall detection is disabled
+func (c *Config) StallThreshold() time.Duration {
+	if c.StallMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(c.StallMinutes) * time.Minute
+}
+
+// ZellijTimeout returns how long each zellij command may take (0 uses the backend default)
+func (c *Config) ZellijTimeout() time.Duration {
+	if c.ZellijTimeoutSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.ZellijTimeoutSeconds) * time.Second
+}
+
+// CommandTimeout returns how long other external commands may run (0 uses the runner default)
+func (c *Config) CommandTimeout() time.Duration {
+	if c.CommandTimeoutSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.CommandTimeoutSeconds) * time.Second
+}
+
+// MergeCheckTimeout returns how long merge.require_command may run
+func (c *Config) MergeCheckTimeout() time.Duration {
+	if c.Merge.TimeoutMinutes <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(c.Merge.TimeoutMinutes) * time.Minute
+}
+
+// NudgeThreshold returns how long a task may sit in WAITING before it is nudged, or 0 if nudging is disabled
+func (c *Config) NudgeThreshold() time.Duration {
+	if c.Nudge.AfterMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(c.Nudge.AfterMinutes) * time.Minute
+}
+
+// Retention returns how long archived tasks and transcripts are kept, or 0 to keep them forever
+func (c *Config) Retention() time.Duration {
+	if c.RetentionDays <= 0 {
+		return 0
+	}
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// UpdateStatePath returns the file caching the last update check (~/.flock/update.json)
+func (c *Config) UpdateStatePath() string {
+	return filepath.Join(c.configDir, updateFileName)
+}
+
+// TelemetryStatePath returns the file holding the telemetry install ID and last send (~/.flock/telemetry.json)
+func (c *Config) TelemetryStatePath() string {
+	return filepath.Join(c.configDir, telemetryFile)
+}
+
+// ArchivePath returns the file holding archived tasks (~/.flock/archive.json)
+func (c *Config) ArchivePath() string {
+	return filepath.Join(c.configDir, archiveFileName)
+}
+
+// VaultPath returns the file holding the encryption salt and passphrase check (~/.flock/vault.json)
+func (c *Config) VaultPath() string {
+	return filepath.Join(c.configDir, vaultFileName)
+}
+
+// Vault returns the unlocked vault, or nil if files are stored in plaintext
+func (c *Config) Vault() *vault.Vault {
+	return c.vault
+}
+
+// SetVault sets the unlocked vault used to read and write flock's files
+func (c *Config) SetVault(v *vault.Vault) {
+	c.vault = v
+}
+
+// ReportsDir returns where activity reports are saved (reports.dir, or ~/.flock/reports)
+func (c *Config) ReportsDir() string {
+	if c.Reports.Dir != "" {
+		return c.Reports.Dir
+	}
+	return filepath.Join(c.configDir, reportsDir)
+}
+
+// SharedTemplatesCheckout returns where templates.repo is cloned (~/.flock/shared-templates)
+func (c *Config) SharedTemplatesCheckout() string {
+	return filepath.Join(c.configDir, sharedTemplates)
+}
+
+// ReportStatePath returns the file recording when a scheduled report last ran (~/.flock/report.json)
+func (c *Config) ReportStatePath() string {
+	return filepath.Join(c.configDir, reportFileName)
+}
+
+// WorktreeRoot returns worktrees.root with ~ expanded, or "" to keep worktrees in each repo
+func (c *Config) WorktreeRoot() string {
+	root := c.Worktrees.Root
+	if root == "~" || strings.HasPrefix(root, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			root = filepath.Join(home, root[1:])
+		}
+	}
+	if root != "" {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+	}
+	return root
+}
+
+// CapacityPath returns the file holding agent resource and rate limit samples (~/.flock/capacity.json)
+func (c *Config) CapacityPath() string {
+	return filepath.Join(c.configDir, capacityFileName)
+}
+
+// LogPath returns where flock writes its own log (~/.flock/flock.log)
+func (c *Config) LogPath() string {
+	return filepath.Join(c.configDir, logFileName)
+}
+
+// LockPath returns the lock held by the running dashboard or daemon (~/.flock/flock.lock)
+func (c *Config) LockPath() string {
+	return filepath.Join(c.configDir, lockFileName)
+}
+
+// SocketPath returns the unix socket the daemon listens on (~/.flock/flock.sock)
+func (c *Config) SocketPath() string {
+	return filepath.Join(c.configDir, socketFileName)
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const reposFileName = "repos.json"
+
+// RepoDefaults are the new-task form values last used in a repository
+type RepoDefaults struct {
+	Cwd         string    `json:"cwd"`
+	Template    string    `json:"template"`
+	Agent       string    `json:"agent"`
+	UseWorktree bool      `json:"use_worktree"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RepoDefaultsStore remembers new-task form values per repository root
+type RepoDefaultsStore struct {
+	path  string
+	repos map[string]RepoDefaults
+}
+
+// RepoDefaultsPath returns the file holding per-repository form defaults (~/.flock/repos.json)
+func (c *Config) RepoDefaultsPath() string {
+	return filepath.Join(c.configDir, reposFileName)
+}
+
+// LoadRepoDefaults reads per-repository defaults; a missing file yields an empty store
+func LoadRepoDefaults(path string) (*RepoDefaultsStore, error) {
+	s := &RepoDefaultsStore{path: path, repos: make(map[string]RepoDefaults)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s.repos); err != nil {
+		return s, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns the defaults last used in a repository
+func (s *RepoDefaultsStore) Get(repoRoot string) (RepoDefaults, bool) {
+	d, ok := s.repos[repoRoot]
+	return d, ok
+}
+
+// Repos returns every repository new tasks have been created in, sorted
+func (s *RepoDefaultsStore) Repos() []string {
+	repos := make([]string, 0, len(s.repos))
+	for repoRoot := range s.repos {
+		repos = append(repos, repoRoot)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// Remember records the values used for a new task in a repository and saves the store
+func (s *RepoDefaultsStore) Remember(repoRoot string, d RepoDefaults) error {
+	d.UpdatedAt = time.Now()
+	s.repos[repoRoot] = d
+
+	data, err := json.MarshalIndent(s.repos, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
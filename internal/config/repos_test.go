@@ -0,0 +1,35 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoDefaultsStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repos.json")
+
+	store, err := LoadRepoDefaults(path)
+	if err != nil {
+		t.Fatalf("expected missing file to load empty, got %v", err)
+	}
+	if _, ok := store.Get("/src/app"); ok {
+		t.Errorf("expected no defaults in an empty store")
+	}
+
+	want := RepoDefaults{Cwd: "/src/app/web", Template: "bugfix.md", Agent: "aider", UseWorktree: true}
+	if err := store.Remember("/src/app", want); err != nil {
+		t.Fatalf("Remember failed: %v", err)
+	}
+
+	reloaded, err := LoadRepoDefaults(path)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	got, ok := reloaded.Get("/src/app")
+	if !ok {
+		t.Fatalf("expected defaults for /src/app after reload")
+	}
+	if got.Cwd != want.Cwd || got.Template != want.Template || got.Agent != want.Agent || got.UseWorktree != want.UseWorktree {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,270 @@
+package daemon
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/sockets"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// maxAPIBody caps the size of an API request body
+const maxAPIBody = 1 << 20
+
+// eventBuffer is how many events a slow event stream may fall behind before it is dropped
+const eventBuffer = 64
+
+// Kinds of task events streamed from GET /v1/events
+const (
+	EventAdded   = "added"
+	EventUpdated = "updated" // Started, merged or its settings changed
+	EventStatus  = "status"  // The agent reported a new status
+	EventDeleted = "deleted"
+)
+
+// Event is a change to a task, streamed to API clients
+type Event struct {
+	Type    string     `json:"type"`
+	TaskID  string     `json:"task_id"`
+	Task    *task.Task `json:"task,omitempty"`    // The task after the change (nil once deleted)
+	Message string     `json:"message,omitempty"` // What the agent said with a status change
+	At      time.Time  `json:"at"`
+}
+
+// broker fans events out to the open event streams
+type broker struct {
+	mu   sync.Mutex
+	subs map[chan []byte]struct{}
+}
+
+// subscribe returns a channel receiving each event as JSON, and a function to stop.
+// The channel is closed when the subscriber falls too far behind.
+func (b *broker) subscribe() (<-chan []byte, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = make(map[chan []byte]struct{})
+	}
+	ch := make(chan []byte, eventBuffer)
+	b.subs[ch] = struct{}{}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish sends an event to every subscriber
+func (b *broker) publish(e Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("failed to encode event", "err", err)
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- data:
+		default:
+			// A stream that can't keep up ends rather than silently missing events
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publishChanges tells event subscribers about the tasks a request changed
+func (s *Server) publishChanges(req Request, tasks []*task.Task, err error) {
+	now := time.Now()
+	switch req.Action {
+	case ActionAdd:
+		for _, t := range tasks {
+			s.events.publish(Event{Type: EventAdded, TaskID: t.ID, Task: t, At: now})
+		}
+	case ActionStart, ActionUpdate, ActionMerge:
+		for _, t := range tasks {
+			s.events.publish(Event{Type: EventUpdated, TaskID: t.ID, Task: t, At: now})
+		}
+	case ActionDelete:
+		if err == nil {
+			s.events.publish(Event{Type: EventDeleted, TaskID: req.TaskID, At: now})
+		}
+	}
+}
+
+// PublishStatus tells event subscribers that an agent reported a new status
+func (s *Server) PublishStatus(taskID, message string) {
+	if t, ok := s.tasks.Get(taskID); ok {
+		s.events.publish(Event{Type: EventStatus, TaskID: taskID, Task: t, Message: message, At: time.Now()})
+	}
+}
+
+// ServeAPI serves the HTTP control API on the configured address until the returned
+// server is closed. Every request needs the token as a bearer token.
+func (s *Server) ServeAPI(cfg config.APIConfig, token string) (*http.Server, error) {
+	network, address := cfg.Listener()
+	listener, err := sockets.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the API on %s: %w", address, err)
+	}
+
+	server := &http.Server{
+		Handler:           s.APIHandler(token),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("API server failed", "err", err)
+		}
+	}()
+	return server, nil
+}
+
+// APIHandler routes the control API onto the same requests the daemon socket takes:
+//
+//	GET    /v1/tasks             list tasks
+//	POST   /v1/tasks             add a task (a Request body without action)
+//	GET    /v1/tasks/{id}        one task
+//	PATCH  /v1/tasks/{id}        change its settings (a TaskUpdate body)
+//	DELETE /v1/tasks/{id}        delete it (?delete_worktree=true releases its worktree)
+//	POST   /v1/tasks/{id}/start  start it
+//	POST   /v1/tasks/{id}/merge  merge its branch ({"strategy": "squash"} or "rebase" optional)
+//	GET    /v1/events            stream task events (server-sent events)
+func (s *Server) APIHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/tasks", func(rw http.ResponseWriter, r *http.Request) {
+		s.serveRequest(rw, Request{Action: ActionList}, http.StatusOK)
+	})
+	mux.HandleFunc("POST /v1/tasks", func(rw http.ResponseWriter, r *http.Request) {
+		var req Request
+		if !decodeBody(rw, r, &req) {
+			return
+		}
+		req.Action = ActionAdd
+		s.serveRequest(rw, req, http.StatusCreated)
+	})
+	mux.HandleFunc("GET /v1/tasks/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		t, ok := s.tasks.Get(r.PathValue("id"))
+		if !ok {
+			writeAPIError(rw, http.StatusNotFound, fmt.Sprintf("task %s not found", r.PathValue("id")))
+			return
+		}
+		writeJSON(rw, http.StatusOK, Response{Tasks: []*task.Task{t}})
+	})
+	mux.HandleFunc("PATCH /v1/tasks/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		var update TaskUpdate
+		if !decodeBody(rw, r, &update) {
+			return
+		}
+		s.serveRequest(rw, Request{Action: ActionUpdate, TaskID: r.PathValue("id"), Update: &update}, http.StatusOK)
+	})
+	mux.HandleFunc("DELETE /v1/tasks/{id}", func(rw http.ResponseWriter, r *http.Request) {
+		req := Request{Action: ActionDelete, TaskID: r.PathValue("id"), DeleteWorktree: r.URL.Query().Get("delete_worktree") == "true"}
+		s.serveRequest(rw, req, http.StatusOK)
+	})
+	mux.HandleFunc("POST /v1/tasks/{id}/start", func(rw http.ResponseWriter, r *http.Request) {
+		s.serveRequest(rw, Request{Action: ActionStart, TaskID: r.PathValue("id")}, http.StatusOK)
+	})
+	mux.HandleFunc("POST /v1/tasks/{id}/merge", func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Strategy string `json:"strategy"`
+		}
+		if r.ContentLength != 0 && !decodeBody(rw, r, &body) {
+			return
+		}
+		s.serveRequest(rw, Request{Action: ActionMerge, TaskID: r.PathValue("id"), Strategy: body.Strategy}, http.StatusOK)
+	})
+	mux.HandleFunc("GET /v1/events", s.serveEvents)
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			writeAPIError(rw, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		mux.ServeHTTP(rw, r)
+	})
+}
+
+// serveRequest handles a request and writes the daemon's response as JSON
+func (s *Server) serveRequest(rw http.ResponseWriter, req Request, code int) {
+	tasks, err := s.Handle(req)
+	if err != nil {
+		code = http.StatusBadRequest
+		if strings.HasSuffix(err.Error(), "not found") {
+			code = http.StatusNotFound
+		}
+		writeJSON(rw, code, Response{Error: err.Error(), Tasks: tasks})
+		return
+	}
+	writeJSON(rw, code, Response{Tasks: tasks})
+}
+
+// serveEvents streams task events as server-sent events until the client goes away
+func (s *Server) serveEvents(rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		writeAPIError(rw, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	events, stop := s.events.subscribe()
+	defer stop()
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data, ok := <-events:
+			if !ok {
+				return
+			}
+			var e struct {
+				Type string `json:"type"`
+			}
+			json.Unmarshal(data, &e)
+			if _, err := fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// decodeBody reads a JSON request body into v, answering 400 if it can't
+func decodeBody(rw http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAPIBody)).Decode(v); err != nil {
+		writeAPIError(rw, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return false
+	}
+	return true
+}
+
+// writeAPIError writes an error response
+func writeAPIError(rw http.ResponseWriter, code int, message string) {
+	writeJSON(rw, code, Response{Error: message})
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(rw http.ResponseWriter, code int, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		slog.Warn("failed to write API response", "err", err)
+	}
+}
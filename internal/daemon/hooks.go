@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// TeardownTimeout bounds how long a task's teardown command may run
+const TeardownTimeout = 10 * time.Minute
+
+// RecordSetupFailure flags a task whose setup command failed, as its status event reported
+func (s *Server) RecordSetupFailure(taskID, message string) error {
+	if message == "" {
+		message = "Setup failed"
+	}
+	logPath := s.config.HookLogPath(taskID, "setup")
+	return s.tasks.Update(taskID, func(t *task.Task) { t.HookError = fmt.Sprintf("%s, see %s", message, logPath) })
+}
+
+// RunTeardown runs a DONE task's teardown command in its directory, saving the output to
+// the task's teardown log. A failure is recorded on the task and reported with failed set.
+// It returns no note for tasks without a teardown command.
+func (s *Server) RunTeardown(taskID string) (note string, failed bool) {
+	t, ok := s.tasks.Get(taskID)
+	if !ok || strings.TrimSpace(t.Teardown) == "" {
+		return "", false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), TeardownTimeout)
+	defer cancel()
+	output, err := runner.Exec{}.CombinedOutput(ctx, runner.Command("sh", "-c", t.Teardown).In(t.WorkDir()))
+
+	logPath := s.config.HookLogPath(t.ID, "teardown")
+	if writeErr := os.WriteFile(logPath, output, 0644); writeErr != nil {
+		logPath = "" // the note can't point at the log
+	}
+
+	hookError := ""
+	if err != nil {
+		hookError = fmt.Sprintf("Teardown failed (%v)", err)
+		if ctx.Err() != nil {
+			hookError = fmt.Sprintf("Teardown timed out after %s", TeardownTimeout)
+		}
+		if logPath != "" {
+			hookError += ", see " + logPath
+		}
+	}
+	if err := s.tasks.Update(t.ID, func(t *task.Task) { t.HookError = hookError }); err != nil {
+		return fmt.Sprintf("Failed to record teardown of %s: %v", t.Name, err), true
+	}
+	if hookError != "" {
+		return fmt.Sprintf("%s: %s", t.Name, hookError), true
+	}
+	return fmt.Sprintf("Teardown of %s passed", t.Name), false
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/dfowler/flock/internal/chain"
+	"github.com/dfowler/flock/internal/gate"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// checkMerge runs merge.require_command in a task's worktree, as the merge dialog does,
+// and fails if it doesn't pass. Nothing is checked without a command.
+func (s *Server) checkMerge(taskID string) error {
+	command := s.config.Merge.RequireCommand
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+	t, ok := s.tasks.Get(taskID)
+	if !ok {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	result := gate.Run(runner.Exec{}, command, t.WorkDir(), s.config.HookLogPath(t.ID, "merge-check"), s.config.MergeCheckTimeout())
+	if !result.Passed {
+		return fmt.Errorf("merge blocked: %s", result.Summary())
+	}
+	return nil
+}
+
+// mergeTask merges a task's branch into the default branch with strategyName ("" uses the
+// configured strategy) and records the merge. A merge that conflicts is undone and
+// reported as an error.
+func (s *Server) mergeTask(taskID, strategyName string) (*task.Task, error) {
+	t, ok := s.tasks.Get(taskID)
+	if !ok {
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+	if t.GitBranch == "" || t.RepoRoot == "" {
+		return nil, fmt.Errorf("task %s has no branch to merge", taskID)
+	}
+	if strategyName == "" {
+		strategyName = s.config.Worktrees.MergeStrategy
+	}
+	strategy, err := git.ParseMergeStrategy(strategyName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := chain.Merge(t, strategy, s.config.Worktrees)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge %s: %w", t.GitBranch, err)
+	}
+	if !result.Success {
+		return nil, errors.New(result.Message)
+	}
+	note, err := chain.RecordMerge(s.tasks, t, result, s.config.Worktrees.ChangelogFile)
+	if err != nil {
+		slog.Error("failed to record merge", "task", t.Name, "err", err)
+	} else if note != "" {
+		slog.Info(note)
+	}
+	t, _ = s.tasks.Get(taskID)
+	return t, nil
+}
@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dfowler/flock/internal/multiplexer"
+)
+
+// NudgeWaiting types the nudge message for each task's waiting reason into the tabs of tasks
+// that have sat in WAITING past the configured threshold, and returns a note for each
+func (s *Server) NudgeWaiting(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nudge := s.config.Nudge
+	var notes []string
+	for _, t := range s.tasks.List() {
+		if !t.NudgeDue(now, s.config.NudgeThreshold(), nudge.MaxNudges, nudge.AllTasks) {
+			continue
+		}
+		message, ok := nudge.MessageFor(string(t.WaitReason))
+		if !ok {
+			continue // Left for a person, e.g. permission prompts
+		}
+		agent, err := s.config.Agent(t.Agent)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("Failed to nudge %s: %v", t.Name, err))
+			continue
+		}
+		// Count the attempt first so a tab that can't be written to isn't retried every check
+		if err := s.tasks.RecordNudge(t.ID, now); err != nil {
+			notes = append(notes, fmt.Sprintf("Failed to nudge %s: %v", t.Name, err))
+			continue
+		}
+		text := multiplexer.ResumeMessage(message, multiplexer.NewLaunch(t, agent, ""))
+		if err := multiplexer.SendMessage(s.mux, t.TabName, text); err != nil {
+			notes = append(notes, fmt.Sprintf("Failed to nudge %s: %v", t.Name, err))
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("Nudged %s after %d minutes waiting", t.Name, int(now.Sub(t.StatusSince()).Minutes())))
+	}
+	return notes
+}
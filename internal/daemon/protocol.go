@@ -0,0 +1,100 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/dfowler/flock/internal/task"
+)
+
+// Actions understood by the daemon
+const (
+	ActionAdd    = "add"
+	ActionStart  = "start"
+	ActionList   = "list"
+	ActionDelete = "delete"
+	ActionUpdate = "update"
+	ActionMerge  = "merge"
+)
+
+// clientTimeout bounds a single request/response exchange
+const clientTimeout = 30 * time.Second
+
+// Request is a single command sent to the daemon (one JSON object per connection)
+type Request struct {
+	Action         string              `json:"action"`
+	TaskID         string              `json:"task_id,omitempty"`
+	Name           string              `json:"name,omitempty"`
+	Cwd            string              `json:"cwd,omitempty"`
+	Prompt         string              `json:"prompt,omitempty"`          // Goal text inserted into the prompt template
+	Template       string              `json:"template,omitempty"`        // Project template to start from (empty means default.md)
+	PromptText     string              `json:"prompt_text,omitempty"`     // Complete prompt file content, used instead of the template
+	Agent          string              `json:"agent,omitempty"`           // Agent to launch (empty means the configured default)
+	Permission     task.PermissionMode `json:"permission_mode,omitempty"` // Agent permission mode, e.g. plan or acceptEdits
+	DependsOn      []string            `json:"depends_on,omitempty"`      // Tasks that must be DONE before this one auto-starts
+	ChainMode      task.ChainMode      `json:"chain_mode,omitempty"`      // How to prepare the task once dependencies are DONE
+	Attachments    []string            `json:"attachments,omitempty"`     // File paths and URLs to list in the prompt's Context section
+	Schedule       string              `json:"schedule,omitempty"`        // Cron expression; each match starts a copy of the task
+	RunAt          *time.Time          `json:"run_at,omitempty"`          // Start the task once at this time
+	AutoNudge      bool                `json:"auto_nudge,omitempty"`      // Nudge the agent when it waits for input too long
+	Setup          string              `json:"setup,omitempty"`           // Shell command run in the task's directory before the agent starts
+	Teardown       string              `json:"teardown,omitempty"`        // Shell command run in the task's directory once it is DONE
+	Handoff        bool                `json:"handoff,omitempty"`         // Hand the dependencies' changes and final messages to the task
+	ReviewOf       string              `json:"review_of,omitempty"`       // Task to review; the reviewer shares its worktree unless UseWorktree is set
+	Diff           string              `json:"diff,omitempty"`            // Changes for the template's {{diff}}, e.g. the ones to review
+	UseWorktree    bool                `json:"use_worktree,omitempty"`    // Assign a worktree when adding
+	Start          bool                `json:"start,omitempty"`           // Start the task right after adding it
+	DeleteWorktree bool                `json:"delete_worktree,omitempty"` // Remove the task's worktree when deleting
+	Strategy       string              `json:"strategy,omitempty"`        // "merge", "squash" or "rebase" when merging (empty uses worktrees.merge_strategy)
+	Update         *TaskUpdate         `json:"update,omitempty"`          // Settings to change with the update action
+}
+
+// TaskUpdate changes a task's settings; fields left out are unchanged. The agent and
+// permission mode only apply at launch, so they can only change while the task is PENDING.
+type TaskUpdate struct {
+	Name       *string              `json:"name,omitempty"`
+	Agent      *string              `json:"agent,omitempty"`
+	Permission *task.PermissionMode `json:"permission_mode,omitempty"`
+	AutoNudge  *bool                `json:"auto_nudge,omitempty"`
+}
+
+// Response is the daemon's reply to a Request
+type Response struct {
+	Error string       `json:"error,omitempty"`
+	Tasks []*task.Task `json:"tasks,omitempty"`
+}
+
+// Running reports whether a daemon is accepting connections on socketPath
+func Running(socketPath string) bool {
+	conn, err := net.DialTimeout("unix", socketPath, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Send sends a request to the daemon listening on socketPath and waits for its response
+func Send(socketPath string, req Request) (*Response, error) {
+	conn, err := net.DialTimeout("unix", socketPath, clientTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon (is `flock daemon` running?): %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(clientTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Error != "" {
+		return &resp, fmt.Errorf("%s", resp.Error)
+	}
+	return &resp, nil
+}
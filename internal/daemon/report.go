@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dfowler/flock/internal/report"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// SendDueReport saves and sends the scheduled activity report when it is due,
+// and returns a note about it
+func (s *Server) SendDueReport(now time.Time) []string {
+	if s.config.Reports.Schedule == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	archive, err := task.LoadArchive(s.config.ArchivePath(), s.config.Vault())
+	if err != nil {
+		return []string{fmt.Sprintf("Failed to send report: %v", err)}
+	}
+	note, err := report.RunDue(s.config, s.tasks.List(), archive.ArchivedBefore(now), runner.Exec{}, now)
+	if err != nil {
+		return []string{fmt.Sprintf("Failed to send report: %v", err)}
+	}
+	if note == "" {
+		return nil
+	}
+	return []string{note}
+}
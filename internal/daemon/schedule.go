@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dfowler/flock/internal/task"
+)
+
+// ScheduleInterval is how often the daemon and the TUI look for scheduled tasks that are due
+const ScheduleInterval = 30 * time.Second
+
+// RunDue starts scheduled tasks whose time has come and returns a note for each.
+// A recurring task stays pending as a template: each run adds and starts a copy of it.
+func (s *Server) RunDue(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var notes []string
+	for _, t := range s.tasks.DueTasks(now) {
+		// Advance first so a task that fails to start is not retried on every check
+		if err := s.tasks.AdvanceSchedule(t.ID, now); err != nil {
+			notes = append(notes, fmt.Sprintf("Failed to update schedule of %s: %v", t.Name, err))
+			continue
+		}
+
+		if !t.IsRecurring() {
+			if err := s.startTask(t.ID); err != nil {
+				notes = append(notes, fmt.Sprintf("Failed to start scheduled task %s: %v", t.Name, err))
+				continue
+			}
+			notes = append(notes, fmt.Sprintf("Started scheduled task %s", t.Name))
+			continue
+		}
+
+		run, err := s.addTask(scheduledRunRequest(t))
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("Failed to add scheduled run of %s: %v", t.Name, err))
+			continue
+		}
+		if err := s.startTask(run.ID); err != nil {
+			notes = append(notes, fmt.Sprintf("Failed to start scheduled run of %s: %v", t.Name, err))
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("Started scheduled run of %s as %s", t.Name, run.ID))
+	}
+	return notes
+}
+
+// scheduledRunRequest describes one run of a recurring task: the same prompt, directory
+// and agent, with a fresh worktree if the task uses them, and no schedule of its own
+func scheduledRunRequest(t *task.Task) Request {
+	promptText := t.Prompt
+	if t.PromptFile != "" {
+		if data, err := os.ReadFile(t.PromptFile); err == nil {
+			promptText = string(data)
+		}
+	}
+	return Request{
+		Action:      ActionAdd,
+		Name:        t.Name,
+		Cwd:         t.Cwd,
+		Template:    t.Template,
+		PromptText:  promptText,
+		Agent:       t.Agent,
+		Permission:  t.Permission,
+		Attachments: t.Attachments,
+		AutoNudge:   t.AutoNudge,
+		Setup:       t.Setup,
+		Teardown:    t.Teardown,
+		UseWorktree: t.UseWorktree || t.WorktreePath != "",
+	}
+}
@@ -0,0 +1,442 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dfowler/flock/internal/chain"
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/prompt"
+	"github.com/dfowler/flock/internal/sockets"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// Server manages tasks without the TUI, serving requests over a unix socket
+type Server struct {
+	tasks       *task.Manager
+	mux         multiplexer.Backend
+	config      *config.Config
+	promptMgr   *prompt.Manager
+	gitAssigner *git.Assigner
+	listener    net.Listener
+	events      broker // Task events for the control API's event stream
+
+	// Agents get the prompt's text rather than its file, which is encrypted as soon as
+	// the one-shot command handling requests in-process returns
+	inlinePrompts bool
+
+	// Requests touch the multiplexer and worktrees, so they are handled one at a time
+	mu sync.Mutex
+}
+
+// NewServer creates a daemon server (gitAssigner may be nil if worktrees are disabled)
+func NewServer(tasks *task.Manager, mux multiplexer.Backend, cfg *config.Config, gitAssigner *git.Assigner) *Server {
+	return &Server{
+		tasks:       tasks,
+		mux:         mux,
+		config:      cfg,
+		promptMgr:   prompt.NewManager(cfg),
+		gitAssigner: gitAssigner,
+	}
+}
+
+// SetInlinePrompts has agents started from now on take their prompt's text instead of the
+// prompt file's path, so the file can be encrypted while they start. Agents whose command
+// reads {{prompt_file}} still get the file.
+func (s *Server) SetInlinePrompts(inline bool) {
+	s.inlinePrompts = inline
+}
+
+// Listen opens the unix socket, private to the user, replacing a stale socket left by a
+// previous daemon. Anyone who can connect can add tasks with setup commands, hence 0600.
+func (s *Server) Listen(socketPath string) error {
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("daemon already running on %s", socketPath)
+	}
+
+	listener, err := sockets.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	s.listener = listener
+	return nil
+}
+
+// Serve accepts connections until the listener is closed
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Close stops accepting connections and removes the socket
+func (s *Server) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+// handleConn reads one request from a connection and writes the response
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	var req Request
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		resp.Error = fmt.Sprintf("invalid request: %v", err)
+	} else {
+		tasks, err := s.Handle(req)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		resp.Tasks = tasks
+	}
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		slog.Warn("failed to write response", "err", err)
+	}
+}
+
+// Handle runs a request in-process, as if it had arrived over the socket, and tells
+// event subscribers about the tasks it changed
+func (s *Server) Handle(req Request) ([]*task.Task, error) {
+	if req.Action == ActionMerge {
+		// The merge check can take minutes, so it runs before other requests are held up
+		if err := s.checkMerge(req.TaskID); err != nil {
+			return nil, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks, err := s.handle(req)
+	s.publishChanges(req, tasks, err)
+	return tasks, err
+}
+
+// handle dispatches a request to its action
+func (s *Server) handle(req Request) ([]*task.Task, error) {
+	switch req.Action {
+	case ActionList:
+		return s.tasks.List(), nil
+	case ActionAdd:
+		t, err := s.addTask(req)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.NextRun != nil:
+			// Scheduled tasks wait for RunDue
+		case len(t.DependsOn) > 0:
+			// Dependent tasks start on their own once their dependencies are DONE
+			if len(s.tasks.PendingDependencies(t.ID)) == 0 {
+				if err := s.startDependent(t); err != nil {
+					return []*task.Task{t}, err
+				}
+			}
+		case req.Start:
+			if err := s.startTask(t.ID); err != nil {
+				return []*task.Task{t}, err
+			}
+		}
+		return []*task.Task{t}, nil
+	case ActionStart:
+		if err := s.startTask(req.TaskID); err != nil {
+			return nil, err
+		}
+		t, _ := s.tasks.Get(req.TaskID)
+		return []*task.Task{t}, nil
+	case ActionDelete:
+		return nil, s.deleteTask(req.TaskID, req.DeleteWorktree)
+	case ActionUpdate:
+		t, err := s.updateTask(req.TaskID, req.Update)
+		if err != nil {
+			return nil, err
+		}
+		return []*task.Task{t}, nil
+	case ActionMerge:
+		t, err := s.mergeTask(req.TaskID, req.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		return []*task.Task{t}, nil
+	default:
+		return nil, fmt.Errorf("unknown action %q", req.Action)
+	}
+}
+
+// addTask creates a task with a prompt file from the project template
+func (s *Server) addTask(req Request) (*task.Task, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("task name is required")
+	}
+	agent, err := s.config.Agent(req.Agent)
+	if err != nil {
+		return nil, err
+	}
+	if !agent.SupportsPermission(string(req.Permission)) {
+		return nil, fmt.Errorf("agent has no flags for permission mode %q (set permission_flags in its config)", req.Permission)
+	}
+	cwd := req.Cwd
+	if cwd == "" {
+		cwd = "."
+	}
+	if absCwd, err := filepath.Abs(cwd); err == nil {
+		cwd = absCwd
+	}
+
+	var reviewed *task.Task
+	if req.ReviewOf != "" {
+		var ok bool
+		if reviewed, ok = s.tasks.Get(req.ReviewOf); !ok {
+			return nil, fmt.Errorf("task %s not found", req.ReviewOf)
+		}
+	}
+	sharesWorktree := reviewed != nil && reviewed.WorktreePath != "" && !req.UseWorktree
+
+	taskID := s.tasks.NextID()
+	template := prompt.TemplateFileName(req.Template)
+	vars := s.promptMgr.TaskVars(taskID, req.Name, cwd, req.Prompt, req.UseWorktree && s.gitAssigner != nil)
+	vars.Diff = req.Diff
+	if sharesWorktree {
+		vars.Branch = reviewed.GitBranch
+	}
+	promptFile, err := s.promptMgr.CreatePromptFileFromTemplate(template, vars)
+	if err != nil {
+		return nil, err
+	}
+	if req.PromptText != "" {
+		if err := os.WriteFile(promptFile, []byte(req.PromptText), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write prompt file: %w", err)
+		}
+	}
+
+	createOpts := &task.CreateOptions{
+		UseWorktree: req.UseWorktree,
+		Template:    template,
+		Agent:       req.Agent,
+		Permission:  req.Permission,
+		Project:     git.ProjectRoot(cwd),
+	}
+	if sharesWorktree {
+		// The reviewer looks at the changes in place, on the reviewed task's branch
+		createOpts.UseWorktree = true
+		createOpts.WorktreePath = reviewed.WorktreePath
+		createOpts.GitBranch = reviewed.GitBranch
+		createOpts.RepoRoot = reviewed.RepoRoot
+	} else if req.UseWorktree && s.gitAssigner != nil {
+		// Nobody is around to answer the leftover-branch question, so pick a fresh name
+		assignment, err := s.gitAssigner.AssignWorktreeWithCollision(taskID, req.Name, cwd, s.taskWorktreeInfos(), git.BranchCollisionSuffix)
+		if err != nil {
+			slog.Warn("failed to assign worktree", "task", req.Name, "err", err)
+		} else if assignment != nil {
+			createOpts.WorktreePath = assignment.WorktreePath
+			createOpts.GitBranch = assignment.GitBranch
+			createOpts.RepoRoot = assignment.RepoRoot
+		}
+	}
+
+	t, err := s.tasks.CreateWithOptions(req.Name, promptFile, cwd, createOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	if len(req.DependsOn) > 0 {
+		if err := s.tasks.SetDependencies(t.ID, req.DependsOn, req.ChainMode); err != nil {
+			s.tasks.Delete(t.ID)
+			s.promptMgr.DeletePromptFile(t.ID)
+			return nil, err
+		}
+	}
+	if req.Schedule != "" || req.RunAt != nil {
+		if err := s.tasks.SetSchedule(t.ID, req.Schedule, req.RunAt); err != nil {
+			s.tasks.Delete(t.ID)
+			s.promptMgr.DeletePromptFile(t.ID)
+			return nil, err
+		}
+	}
+	if req.AutoNudge || req.Setup != "" || req.Teardown != "" || req.Handoff || req.ReviewOf != "" {
+		err := s.tasks.Update(t.ID, func(t *task.Task) {
+			t.AutoNudge = req.AutoNudge
+			t.Setup = req.Setup
+			t.Teardown = req.Teardown
+			t.Handoff = req.Handoff
+			t.ReviewOf = req.ReviewOf
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Attachments) > 0 {
+		if err := s.tasks.Update(t.ID, func(t *task.Task) { t.Attachments = req.Attachments }); err != nil {
+			return nil, err
+		}
+		if err := prompt.ApplyAttachments(promptFile, req.Attachments); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.promptMgr.Snapshot(t.ID, promptFile, prompt.SnapshotCreated); err != nil {
+		slog.Warn("failed to save prompt history", "task", t.Name, "err", err)
+	}
+	return t, nil
+}
+
+// startTask opens the agent tab for a pending task
+func (s *Server) startTask(taskID string) error {
+	t, ok := s.tasks.Get(taskID)
+	if !ok {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if t.Status != task.StatusPending {
+		return fmt.Errorf("task %s already started", taskID)
+	}
+
+	agent, err := s.config.Agent(t.Agent)
+	if err != nil {
+		return err
+	}
+	launch := multiplexer.NewLaunch(t, agent, s.config.CaptureLogPath(t.ID))
+	launch.StatusServer = s.config.StatusServer
+	launch.StatusEvents = s.config.StatusTransport == config.StatusTransportSocket
+	launch.SetupLog = s.config.HookLogPath(t.ID, "setup")
+	if s.inlinePrompts && launch.IsFile && !strings.Contains(agent.Command, "{{prompt_file}}") {
+		content, err := s.config.Vault().ReadFile(launch.PromptOrFile)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt: %w", err)
+		}
+		launch.PromptOrFile, launch.IsFile = string(content), false
+	}
+	slog.Info("opening tab", "task", t.ID, "command", agent.Command, "dir", t.WorkDir(), "backend", s.mux.Name())
+	if err := s.mux.NewTab(launch); err != nil {
+		slog.Error("failed to open tab", "task", t.ID, "backend", s.mux.Name(), "err", err)
+		return fmt.Errorf("failed to start task: %w", err)
+	}
+	if err := s.tasks.Update(t.ID, func(t *task.Task) { t.HookError = "" }); err != nil {
+		return err
+	}
+	if t.PromptFile != "" {
+		if err := s.promptMgr.Snapshot(t.ID, t.PromptFile, prompt.SnapshotLaunch); err != nil {
+			slog.Warn("failed to save prompt history", "task", t.Name, "err", err)
+		}
+	}
+	return s.tasks.UpdateStatus(t.ID, task.StatusWorking)
+}
+
+// StartReadyDependents starts pending tasks whose dependencies are all DONE
+func (s *Server) StartReadyDependents() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks.ReadyDependents() {
+		if err := s.startDependent(t); err != nil {
+			slog.Warn("not starting dependent task", "task", t.Name, "err", err)
+		}
+	}
+}
+
+// startDependent prepares a task according to its chain mode and starts it
+func (s *Server) startDependent(t *task.Task) error {
+	notes, err := chain.Prepare(s.tasks, t, s.config.Worktrees)
+	for _, note := range notes {
+		slog.Info(note)
+	}
+	if err != nil {
+		return err
+	}
+	return s.startTask(t.ID)
+}
+
+// deleteTask closes a task's tab and removes its files, optionally releasing its worktree
+func (s *Server) deleteTask(taskID string, deleteWorktree bool) error {
+	t, ok := s.tasks.Get(taskID)
+	if !ok {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	if t.HasTab() {
+		if err := s.mux.CloseTab(t.TabName); err != nil {
+			return err
+		}
+	}
+	s.mux.DeleteStatusFile(taskID)
+	s.promptMgr.DeletePromptFile(taskID)
+	s.promptMgr.DeleteHistory(taskID)
+	if deleteWorktree && s.gitAssigner != nil && t.WorktreePath != "" && !s.tasks.WorktreeShared(taskID) {
+		if err := s.gitAssigner.ReleaseWorktree(t.WorktreePath, t.RepoRoot); err != nil {
+			slog.Warn("failed to clean up worktree", "task", t.Name, "err", err)
+		}
+	}
+	return s.tasks.Delete(taskID)
+}
+
+// taskWorktreeInfos returns all tasks as worktree info for assignment
+func (s *Server) taskWorktreeInfos() []git.TaskWorktreeInfo {
+	tasks := s.tasks.List()
+	infos := make([]git.TaskWorktreeInfo, len(tasks))
+	for i, t := range tasks {
+		infos[i] = t
+	}
+	return infos
+}
+
+// updateTask applies a settings change to a task
+func (s *Server) updateTask(taskID string, update *TaskUpdate) (*task.Task, error) {
+	t, ok := s.tasks.Get(taskID)
+	if !ok {
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+	if update == nil {
+		return t, nil
+	}
+	if update.Name != nil && strings.TrimSpace(*update.Name) == "" {
+		return nil, fmt.Errorf("task name is required")
+	}
+	if (update.Agent != nil || update.Permission != nil) && t.Status != task.StatusPending {
+		return nil, fmt.Errorf("task %s already started; its agent and permission mode apply at launch", taskID)
+	}
+	agentName, permission := t.Agent, t.Permission
+	if update.Agent != nil {
+		agentName = *update.Agent
+	}
+	if update.Permission != nil {
+		permission = *update.Permission
+	}
+	agent, err := s.config.Agent(agentName)
+	if err != nil {
+		return nil, err
+	}
+	if !agent.SupportsPermission(string(permission)) {
+		return nil, fmt.Errorf("agent has no flags for permission mode %q (set permission_flags in its config)", permission)
+	}
+
+	err = s.tasks.Update(taskID, func(t *task.Task) {
+		if update.Name != nil {
+			t.Name = strings.TrimSpace(*update.Name)
+		}
+		t.Agent, t.Permission = agentName, permission
+		if update.AutoNudge != nil {
+			t.AutoNudge = *update.AutoNudge
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	t, _ = s.tasks.Get(taskID)
+	return t, nil
+}
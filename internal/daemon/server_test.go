@@ -0,0 +1,322 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/tmux"
+)
+
+func TestServerTaskLifecycle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := task.NewStoreWithPath(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// git and tmux only ever see the fake; the repo is a plain directory
+	fake := runner.NewFake()
+	defer git.SetRunner(git.SetRunner(fake))
+	repo := t.TempDir()
+	fake.On("git -C "+repo+" rev-parse --is-inside-work-tree", runner.Response{Output: "true\n"})
+	fake.On("git -C "+repo+" rev-parse --show-toplevel", runner.Response{Output: repo + "\n"})
+	fake.On("git -C "+repo+" symbolic-ref refs/remotes/origin/HEAD", runner.Response{Output: "refs/remotes/origin/main\n"})
+	fake.On("git -C "+repo+" show-ref", runner.Response{ExitCode: 1})
+	fake.On("git -C "+repo+" worktree list", runner.Response{Output: "worktree " + repo + "\nbranch refs/heads/main\n"})
+
+	backend := tmux.NewController()
+	backend.SetRunner(fake)
+	backend.SetStatusDir(t.TempDir())
+	server := NewServer(task.NewManager(store), backend, cfg, git.NewAssigner(true, 0, 0))
+
+	tasks, err := server.Handle(Request{Action: ActionAdd, Name: "fix tests", Cwd: repo, UseWorktree: true, Start: true})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	created := tasks[0]
+	worktree := git.WorktreePath(repo, created.ID)
+	if created.GitBranch != "flock-"+created.ID || created.WorktreePath != worktree {
+		t.Errorf("expected worktree %s on flock-%s, got %s on %s", worktree, created.ID, created.WorktreePath, created.GitBranch)
+	}
+	if created.Status != task.StatusWorking {
+		t.Errorf("expected the task to be WORKING, got %s", created.Status)
+	}
+	for _, prefix := range []string{
+		"git -C " + repo + " worktree add --no-track -b flock-" + created.ID + " " + worktree + " main",
+		"tmux new-window -d -n " + created.TabName + " -c " + worktree,
+		"tmux send-keys -t :=" + created.TabName + " -l",
+	} {
+		if !fake.Ran(prefix) {
+			t.Errorf("expected %q to run, got:\n%s", prefix, strings.Join(fake.Commands(), "\n"))
+		}
+	}
+	if _, err := os.Stat(created.PromptFile); err != nil {
+		t.Errorf("expected a prompt file: %v", err)
+	}
+
+	fake.On("git -C "+repo+" worktree list", runner.Response{Output: "worktree " + repo + "\nbranch refs/heads/main\n\n" +
+		"worktree " + worktree + "\nbranch refs/heads/flock-" + created.ID + "\n"})
+	fake.On("tmux list-windows", runner.Response{Output: "flock\n" + created.TabName + "\n"})
+	if _, err := server.Handle(Request{Action: ActionDelete, TaskID: created.ID, DeleteWorktree: true}); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	for _, prefix := range []string{
+		"tmux kill-window -t :=" + created.TabName,
+		"git -C " + repo + " worktree remove --force " + worktree,
+		"git -C " + repo + " branch -D flock-" + created.ID,
+	} {
+		if !fake.Ran(prefix) {
+			t.Errorf("expected %q to run, got:\n%s", prefix, strings.Join(fake.Commands(), "\n"))
+		}
+	}
+	if _, ok := server.tasks.Get(created.ID); ok {
+		t.Errorf("expected the task to be deleted")
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := task.NewStoreWithPath(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := runner.NewFake()
+	defer git.SetRunner(git.SetRunner(fake))
+	backend := tmux.NewController()
+	backend.SetRunner(fake)
+	backend.SetStatusDir(t.TempDir())
+	server := NewServer(task.NewManager(store), backend, cfg, nil)
+
+	dir := t.TempDir()
+	past := time.Now().Add(-time.Minute)
+	added, err := server.Handle(Request{Action: ActionAdd, Name: "nightly deps", Cwd: dir, Schedule: "0 2 * * *", Start: true})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	nightly := added[0]
+	if nightly.Status != task.StatusPending || nightly.NextRun == nil {
+		t.Fatalf("expected a pending task with a next run, got %s", nightly.Status)
+	}
+	added, err = server.Handle(Request{Action: ActionAdd, Name: "once", Cwd: dir, RunAt: &past})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	once := added[0]
+
+	if notes := server.RunDue(time.Now()); len(notes) != 1 {
+		t.Errorf("expected only the one-off task to run, got %v", notes)
+	}
+	if got, _ := server.tasks.Get(once.ID); got.Status != task.StatusWorking || got.NextRun != nil {
+		t.Errorf("expected the one-off task to be WORKING without a next run, got %s", got.Status)
+	}
+
+	// Runs missed while nothing was checking fire once
+	later := nightly.NextRun.Add(48 * time.Hour)
+	if notes := server.RunDue(later); len(notes) != 1 {
+		t.Errorf("expected one run of the nightly task, got %v", notes)
+	}
+	if got, _ := server.tasks.Get(nightly.ID); got.Status != task.StatusPending || !got.NextRun.After(later) {
+		t.Errorf("expected the nightly task to stay pending with a later next run, got %s at %v", got.Status, got.NextRun)
+	}
+	tasks := server.tasks.List()
+	if len(tasks) != 3 || tasks[2].Name != "nightly deps" || tasks[2].Status != task.StatusWorking || tasks[2].Schedule != "" {
+		t.Errorf("expected a WORKING copy of the nightly task, got %d tasks", len(tasks))
+	}
+}
+
+func TestHandoff(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := task.NewStoreWithPath(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := runner.NewFake()
+	defer git.SetRunner(git.SetRunner(fake))
+	backend := tmux.NewController()
+	backend.SetRunner(fake)
+	backend.SetStatusDir(t.TempDir())
+	server := NewServer(task.NewManager(store), backend, cfg, nil)
+
+	dir := t.TempDir()
+	added, err := server.Handle(Request{Action: ActionAdd, Name: "add login", Cwd: dir, Start: true})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	first := added[0]
+	added, err = server.Handle(Request{Action: ActionAdd, Name: "review login", Cwd: dir, Prompt: "Review the login", DependsOn: []string{first.ID}, Handoff: true})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	review := added[0]
+	if review.Status != task.StatusPending || !review.Handoff {
+		t.Fatalf("expected a pending handoff task, got %s (handoff %v)", review.Status, review.Handoff)
+	}
+
+	// The final message reaches the next task's prompt once the first is DONE
+	if err := server.tasks.Update(first.ID, func(t *task.Task) { t.FinalMessage = "Added the login endpoint." }); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.tasks.UpdateStatus(first.ID, task.StatusDone); err != nil {
+		t.Fatal(err)
+	}
+	server.StartReadyDependents()
+	if got, _ := server.tasks.Get(review.ID); got.Status != task.StatusWorking {
+		t.Errorf("expected the review to be WORKING, got %s", got.Status)
+	}
+	content, err := os.ReadFile(review.PromptFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Review the login", "### " + first.ID + ": add login", "> Added the login endpoint."} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected %q in the prompt, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestNudgeWaitingReasons(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Nudge.AllTasks = true
+	cfg.Nudge.Reasons = map[string]string{"permission": "", "plan": "Looks good, go ahead"}
+	store, err := task.NewStoreWithPath(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := runner.NewFake()
+	defer git.SetRunner(git.SetRunner(fake))
+	backend := tmux.NewController()
+	backend.SetRunner(fake)
+	backend.SetStatusDir(t.TempDir())
+	server := NewServer(task.NewManager(store), backend, cfg, nil)
+
+	reasons := []task.WaitReason{task.WaitPermission, task.WaitPlan, task.WaitInput}
+	for _, reason := range reasons {
+		added, err := server.Handle(Request{Action: ActionAdd, Name: string(reason), Cwd: t.TempDir(), Start: true})
+		if err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+		if err := server.tasks.UpdateWaiting(added[0].ID, task.StatusWaiting, reason); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notes := server.NudgeWaiting(time.Now().Add(time.Hour))
+	if len(notes) != 2 {
+		t.Fatalf("expected the plan and input tasks to be nudged, got %v", notes)
+	}
+	for _, note := range notes {
+		if strings.Contains(note, "permission") {
+			t.Errorf("expected a permission prompt to be left alone, got %q", note)
+		}
+	}
+	var typed []string
+	for _, call := range fake.Calls() {
+		typed = append(typed, strings.Join(call.Args, " "))
+	}
+	if all := strings.Join(typed, "\n"); !strings.Contains(all, "Looks good, go ahead") || !strings.Contains(all, config.DefaultNudgeMessage) {
+		t.Errorf("expected the plan message and the default message to be typed, got:\n%s", all)
+	}
+}
+
+func TestAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := task.NewStoreWithPath(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := runner.NewFake()
+	defer git.SetRunner(git.SetRunner(fake))
+	backend := tmux.NewController()
+	backend.SetRunner(fake)
+	backend.SetStatusDir(t.TempDir())
+	server := NewServer(task.NewManager(store), backend, cfg, nil)
+	api := httptest.NewServer(server.APIHandler("secret"))
+	defer api.Close()
+
+	call := func(method, path, token, body string) (int, Response) {
+		t.Helper()
+		req, err := http.NewRequest(method, api.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var r Response
+		json.NewDecoder(resp.Body).Decode(&r)
+		return resp.StatusCode, r
+	}
+
+	if code, _ := call("GET", "/v1/tasks", "wrong", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with a wrong token, got %d", code)
+	}
+
+	events, stop := server.events.subscribe()
+	defer stop()
+	code, r := call("POST", "/v1/tasks", "secret", `{"name": "fix tests", "cwd": "`+t.TempDir()+`"}`)
+	if code != http.StatusCreated || len(r.Tasks) != 1 {
+		t.Fatalf("expected the task to be created, got %d: %s", code, r.Error)
+	}
+	id := r.Tasks[0].ID
+	select {
+	case data := <-events:
+		var e Event
+		if json.Unmarshal(data, &e); e.Type != EventAdded || e.TaskID != id {
+			t.Errorf("expected an added event for %s, got %s", id, data)
+		}
+	default:
+		t.Errorf("expected an event for the new task")
+	}
+
+	if code, r := call("PATCH", "/v1/tasks/"+id, "secret", `{"name": "fix flaky tests", "auto_nudge": true}`); code != http.StatusOK || r.Tasks[0].Name != "fix flaky tests" || !r.Tasks[0].AutoNudge {
+		t.Errorf("expected the task to be renamed with auto-nudge on, got %d: %s", code, r.Error)
+	}
+	if code, r := call("PATCH", "/v1/tasks/"+id, "secret", `{"name": " "}`); code != http.StatusBadRequest {
+		t.Errorf("expected an empty name to be refused, got %d: %s", code, r.Error)
+	}
+	if code, r := call("GET", "/v1/tasks", "secret", ""); code != http.StatusOK || len(r.Tasks) != 1 {
+		t.Errorf("expected one task listed, got %d: %d tasks", code, len(r.Tasks))
+	}
+	if code, _ := call("DELETE", "/v1/tasks/"+id, "secret", ""); code != http.StatusOK {
+		t.Errorf("expected the task to be deleted, got %d", code)
+	}
+	if code, _ := call("GET", "/v1/tasks/"+id, "secret", ""); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a deleted task, got %d", code)
+	}
+	if code, _ := call("POST", "/v1/tasks/"+id+"/start", "secret", ""); code != http.StatusNotFound {
+		t.Errorf("expected 404 starting a deleted task, got %d", code)
+	}
+}
@@ -0,0 +1,366 @@
+// Package doctor checks what flock needs from the machine it runs on: a multiplexer and a
+// session of it, the agent and git binaries, the status hook and Claude's settings, a
+// writable status directory and the zellij layout. `flock doctor` prints the results.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/setup"
+	"github.com/dfowler/flock/internal/zellij"
+)
+
+// versionTimeout bounds each `--version` call, so a hung binary can't stall the checks
+const versionTimeout = 10 * time.Second
+
+// Git versions flock relies on
+var (
+	minGit       = [2]int{2, 17} // git worktree remove
+	mergeTreeGit = [2]int{2, 38} // git merge-tree --write-tree, for conflict prediction
+)
+
+// Level is how a check turned out
+type Level int
+
+const (
+	Pass Level = iota
+	Warn       // Works, but something is missing or out of date
+	Fail       // flock can't work as it should until this is fixed
+)
+
+// String returns the level as `flock doctor` prints it
+func (l Level) String() string {
+	switch l {
+	case Warn:
+		return "WARN"
+	case Fail:
+		return "FAIL"
+	}
+	return "ok"
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Check  string
+	Level  Level
+	Detail string
+	Fix    string // What to do about a warning or failure
+}
+
+// Env is what the checks look at; tests supply their own lookups
+type Env struct {
+	Config    *config.Config // nil when config.json couldn't be loaded
+	ConfigErr error          // Why it couldn't
+	Commands  runner.Runner
+	Getenv    func(string) string
+	LookPath  func(string) (string, error)
+	Hooks     *setup.Checker // nil skips the hook checks
+	StatusDir string         // The session's status directory
+	WorkDir   string         // Whose project settings are checked with hook_scope "project"
+}
+
+// Run runs every check in order
+func Run(env Env) []Result {
+	cfg := env.Config
+	if cfg == nil {
+		cfg = &config.Config{} // Built-in agents and multiplexer detection still apply
+	}
+	results := []Result{checkConfig(env)}
+	results = append(results, checkMultiplexer(env, cfg)...)
+	results = append(results, checkAgent(env, cfg), checkGit(env))
+	if env.Hooks != nil && cfg.UsesStatusHook(config.StatusHookClaude) {
+		results = append(results, checkHooks(env))
+		if env.Hooks.ProjectScope() {
+			// The global file is still read, but flock's hooks live in the project's
+			results = append(results, checkSettings("settings", env.Hooks.GetSettingsPath(), ""))
+			if env.WorkDir != "" {
+				results = append(results, checkSettings("project settings", env.Hooks.ForProject(env.WorkDir).GetSettingsPath(),
+					"Start a task in this directory: flock adds its hooks to the project settings then"))
+			}
+		} else {
+			results = append(results, checkSettings("settings", env.Hooks.GetSettingsPath(), "Start flock to create it with the hooks"))
+		}
+	}
+	results = append(results, checkStatusDir(env.StatusDir))
+	if multiplexerName(env, cfg) == config.MultiplexerZellij && env.Config != nil {
+		results = append(results, checkLayout(zellij.LayoutPath(env.Config.ConfigDir())))
+	}
+	return results
+}
+
+// Failed reports whether any check failed
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Level == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConfig reports whether ~/.flock/config.json loaded
+func checkConfig(env Env) Result {
+	if env.ConfigErr != nil {
+		return Result{Check: "config", Level: Fail, Detail: env.ConfigErr.Error(),
+			Fix: "Fix ~/.flock/config.json, which must be valid JSON, or move it aside to start from the defaults"}
+	}
+	return Result{Check: "config", Detail: filepath.Join(env.Config.ConfigDir(), "config.json")}
+}
+
+// multiplexerName returns the configured multiplexer, or the one flock would detect
+func multiplexerName(env Env, cfg *config.Config) string {
+	switch {
+	case cfg.Multiplexer != "":
+		return cfg.Multiplexer
+	case env.Getenv("ZELLIJ") == "" && env.Getenv("TMUX") != "":
+		return config.MultiplexerTmux
+	}
+	return config.MultiplexerZellij
+}
+
+// checkMultiplexer checks that the multiplexer is installed and flock runs inside a session of it
+func checkMultiplexer(env Env, cfg *config.Config) []Result {
+	name := multiplexerName(env, cfg)
+	versionArg, sessionVar, start := "--version", "ZELLIJ", "zellij"
+	if name == config.MultiplexerTmux {
+		versionArg, sessionVar, start = "-V", "TMUX", "tmux"
+	}
+
+	installed := Result{Check: name}
+	if _, err := env.LookPath(name); err != nil {
+		installed.Level = Fail
+		installed.Detail = "not found in PATH"
+		installed.Fix = fmt.Sprintf("Install %s (or set \"multiplexer\" in ~/.flock/config.json to the one you use)", name)
+		return []Result{installed}
+	}
+	installed.Detail = version(env, name, versionArg)
+
+	session := Result{Check: "session", Detail: "inside " + name}
+	if env.Getenv(sessionVar) == "" {
+		session.Level = Fail
+		session.Detail = "not inside a " + name + " session"
+		session.Fix = fmt.Sprintf("Start %s with `%s` and run flock in it", name, start)
+	} else if name == config.MultiplexerZellij && env.Getenv("ZELLIJ_SESSION_NAME") != "" {
+		session.Detail = "inside zellij session " + env.Getenv("ZELLIJ_SESSION_NAME")
+	}
+	return []Result{installed, session}
+}
+
+// checkAgent checks that the default agent's binary is installed
+func checkAgent(env Env, cfg *config.Config) Result {
+	name := cfg.DefaultAgent
+	if name == "" {
+		name = config.DefaultAgentName
+	}
+	result := Result{Check: "agent " + name}
+	agent, err := cfg.Agent(name)
+	if err != nil {
+		result.Level = Fail
+		result.Detail = err.Error()
+		result.Fix = "Set default_agent in ~/.flock/config.json to a built-in or configured agent"
+		return result
+	}
+	binary, _, _ := strings.Cut(strings.TrimSpace(agent.Command), " ")
+	path, err := env.LookPath(binary)
+	if err != nil {
+		result.Level = Fail
+		result.Detail = binary + " not found in PATH"
+		result.Fix = fmt.Sprintf("Install %s, or set default_agent in ~/.flock/config.json to an agent you have", binary)
+		if binary == "claude" {
+			result.Fix = "Install Claude Code (npm install -g @anthropic-ai/claude-code)"
+		}
+		return result
+	}
+	result.Detail = path
+	if v := version(env, binary, "--version"); v != "" {
+		result.Detail = fmt.Sprintf("%s (%s)", v, path)
+	}
+	return result
+}
+
+// checkGit checks that git is installed and new enough for worktrees and merge checks
+func checkGit(env Env) Result {
+	result := Result{Check: "git"}
+	if _, err := env.LookPath("git"); err != nil {
+		result.Level = Fail
+		result.Detail = "not found in PATH"
+		result.Fix = "Install git 2.38 or newer"
+		return result
+	}
+	result.Detail = version(env, "git", "--version")
+	v, ok := parseGitVersion(result.Detail)
+	switch {
+	case !ok:
+		result.Level = Warn
+		result.Fix = "Check that `git --version` works"
+	case older(v, minGit):
+		result.Level = Fail
+		result.Fix = "Upgrade git: worktrees need 2.17 or newer"
+	case older(v, mergeTreeGit):
+		result.Level = Warn
+		result.Fix = "Upgrade git to 2.38 or newer so the merge dialog can predict conflicts"
+	}
+	return result
+}
+
+// checkHooks checks that the status hook is installed, current and writes status files
+func checkHooks(env Env) Result {
+	result := Result{Check: "hooks", Detail: env.Hooks.GetHookPath()}
+	installation, err := env.Hooks.Check()
+	switch {
+	case err != nil:
+		result.Level = Fail
+		result.Detail = err.Error()
+		result.Fix = "Fix ~/.claude/settings.json (see settings below)"
+		return result
+	case !installation.HooksInstalled:
+		result.Level = Fail
+		result.Detail = installation.Message
+		result.Fix = "Start flock and agree to install the hooks"
+		return result
+	}
+	if err := env.Hooks.VerifyHook(env.Commands); err != nil {
+		result.Level = Fail
+		result.Detail = "the script failed a test run: " + err.Error()
+		result.Fix = "Run `flock hooks test -v` to see why, or `flock hooks upgrade` to reinstall it"
+		return result
+	}
+	if installation.ScriptOutdated {
+		result.Level = Warn
+		result.Detail += " differs from this flock's (older version or edited)"
+		result.Fix = "Run `flock hooks upgrade`"
+		return result
+	}
+	result.Detail += " (installed and working)"
+	return result
+}
+
+// checkSettings checks that a Claude settings file is valid JSON, since Claude Code skips
+// hooks from a file it can't parse. A missing file warns with missingFix, or passes without one.
+func checkSettings(check, path, missingFix string) Result {
+	result := Result{Check: check, Detail: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		result.Detail = path + " does not exist"
+		if missingFix != "" {
+			result.Level = Warn
+			result.Fix = missingFix
+		}
+		return result
+	}
+	if err != nil {
+		result.Level = Fail
+		result.Detail = err.Error()
+		result.Fix = "Make the file readable"
+		return result
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		result.Level = Fail
+		result.Detail = fmt.Sprintf("%s is not valid JSON: %v", path, err)
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			result.Detail = fmt.Sprintf("%s is not valid JSON at line %d: %v", path, lineOf(data, syntax.Offset), err)
+		}
+		result.Fix = "Fix the JSON; Claude Code ignores a settings file it can't parse, hooks included"
+	}
+	return result
+}
+
+// checkStatusDir checks that agents can write status files to dir
+func checkStatusDir(dir string) Result {
+	result := Result{Check: "status dir", Detail: dir}
+	err := multiplexer.EnsureStatusDir(dir)
+	if err == nil {
+		var probe *os.File
+		if probe, err = os.CreateTemp(dir, ".doctor-*"); err == nil {
+			probe.Close()
+			os.Remove(probe.Name())
+		}
+	}
+	if err != nil {
+		result.Level = Fail
+		result.Detail = err.Error()
+		result.Fix = fmt.Sprintf("Remove %s so flock can create it, private to you", dir)
+	}
+	return result
+}
+
+// checkLayout checks that the zellij agent tab layout is written and current
+func checkLayout(path string) Result {
+	result := Result{Check: "layout", Detail: path}
+	current, err := zellij.LayoutCurrent(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		result.Level = Warn
+		result.Detail = path + " does not exist yet"
+		result.Fix = "Start a task from the dashboard: flock writes the layout when it opens the first agent tab"
+	case err != nil:
+		result.Level = Fail
+		result.Detail = err.Error()
+		result.Fix = "Make the file readable, or delete it so flock writes it again"
+	case !current:
+		result.Level = Warn
+		result.Detail = path + " differs from this flock's"
+		result.Fix = "Start a task from the dashboard: flock rewrites the layout when it opens the next agent tab"
+	}
+	return result
+}
+
+// version runs `binary arg` and returns the first line it prints ("" if it fails)
+func version(env Env, binary, arg string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	out, err := env.Commands.Output(ctx, runner.Command(binary, arg))
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return line
+}
+
+// parseGitVersion reads the major and minor version from `git --version` output,
+// e.g. "git version 2.39.3 (Apple Git-146)"
+func parseGitVersion(s string) ([2]int, bool) {
+	var v [2]int
+	fields := strings.Fields(s)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return v, false
+	}
+	parts := strings.SplitN(fields[2], ".", 3)
+	if len(parts) < 2 {
+		return v, false
+	}
+	for i := range v {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// older reports whether version v is older than min
+func older(v, min [2]int) bool {
+	return v[0] < min[0] || v[0] == min[0] && v[1] < min[1]
+}
+
+// lineOf returns the 1-based line of a byte offset in data
+func lineOf(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return strings.Count(string(data[:offset]), "\n") + 1
+}
@@ -0,0 +1,129 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// testEnv returns an Env where only the given binaries are installed and variables set
+func testEnv(commands runner.Runner, binaries []string, vars map[string]string) Env {
+	return Env{
+		Commands: commands,
+		Getenv:   func(key string) string { return vars[key] },
+		LookPath: func(name string) (string, error) {
+			for _, b := range binaries {
+				if b == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		},
+	}
+}
+
+func TestCheckGit(t *testing.T) {
+	tests := []struct {
+		output string
+		want   Level
+	}{
+		{"git version 2.43.0", Pass},
+		{"git version 2.39.3 (Apple Git-146)", Pass},
+		{"git version 2.34.1", Warn},
+		{"git version 2.11.0", Fail},
+		{"", Warn},
+	}
+	for _, tt := range tests {
+		fake := runner.NewFake()
+		fake.On("git --version", runner.Response{Output: tt.output})
+		if got := checkGit(testEnv(fake, []string{"git"}, nil)); got.Level != tt.want {
+			t.Errorf("%q: expected %s, got %s (%+v)", tt.output, tt.want, got.Level, got)
+		}
+	}
+
+	if got := checkGit(testEnv(runner.NewFake(), nil, nil)); got.Level != Fail || got.Fix == "" {
+		t.Errorf("expected a missing git to fail with a fix, got %+v", got)
+	}
+}
+
+func TestCheckMultiplexer(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		binaries []string
+		vars     map[string]string
+		want     []Level
+	}{
+		{"zellij session", "", []string{"zellij"}, map[string]string{"ZELLIJ": "0"}, []Level{Pass, Pass}},
+		{"tmux detected", "", []string{"tmux"}, map[string]string{"TMUX": "/tmp/tmux-0/default,1,0"}, []Level{Pass, Pass}},
+		{"outside a session", "", []string{"zellij"}, nil, []Level{Pass, Fail}},
+		{"not installed", "tmux", nil, nil, []Level{Fail}},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{Multiplexer: tt.config}
+		results := checkMultiplexer(testEnv(runner.NewFake(), tt.binaries, tt.vars), cfg)
+		if len(results) != len(tt.want) {
+			t.Errorf("%s: expected %d results, got %+v", tt.name, len(tt.want), results)
+			continue
+		}
+		for i, r := range results {
+			if r.Level != tt.want[i] {
+				t.Errorf("%s: expected %s for %s, got %s", tt.name, tt.want[i], r.Check, r.Level)
+			}
+			if r.Level != Pass && r.Fix == "" {
+				t.Errorf("%s: expected a fix for %s", tt.name, r.Check)
+			}
+		}
+	}
+}
+
+func TestCheckAgent(t *testing.T) {
+	fake := runner.NewFake()
+	fake.On("claude --version", runner.Response{Output: "2.1.0 (Claude Code)\n"})
+	got := checkAgent(testEnv(fake, []string{"claude"}, nil), &config.Config{})
+	if got.Level != Pass || !strings.Contains(got.Detail, "2.1.0") {
+		t.Errorf("expected claude to pass with its version, got %+v", got)
+	}
+
+	got = checkAgent(testEnv(fake, nil, nil), &config.Config{})
+	if got.Level != Fail || !strings.Contains(got.Fix, "Claude Code") {
+		t.Errorf("expected a missing claude to fail with install instructions, got %+v", got)
+	}
+}
+
+func TestCheckSettings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	if got := checkSettings("settings", path, "Start flock"); got.Level != Warn {
+		t.Errorf("expected a missing file to warn, got %+v", got)
+	}
+	if got := checkSettings("settings", path, ""); got.Level != Pass {
+		t.Errorf("expected a missing optional file to pass, got %+v", got)
+	}
+
+	os.WriteFile(path, []byte("{\n  \"hooks\": {},\n  \"model\": \"opus\",\n}\n"), 0644)
+	if got := checkSettings("settings", path, ""); got.Level != Fail || !strings.Contains(got.Detail, "line 4") {
+		t.Errorf("expected invalid JSON to fail at line 4, got %+v", got)
+	}
+
+	os.WriteFile(path, []byte(`{"hooks": {}}`), 0644)
+	if got := checkSettings("settings", path, ""); got.Level != Pass {
+		t.Errorf("expected valid JSON to pass, got %+v", got)
+	}
+}
+
+func TestCheckStatusDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "flock-1000", "work")
+	if got := checkStatusDir(dir); got.Level != Pass {
+		t.Errorf("expected a new status dir to pass, got %+v", got)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected the probe file to be removed, got %v", entries)
+	}
+}
@@ -0,0 +1,18 @@
+// Package filelock takes advisory locks on open files, for the locks flock processes use
+// to coordinate: flock(2) on Unix and LockFileEx on Windows. The lock goes away when the
+// file is closed or the process exits.
+package filelock
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrLocked is returned when another process holds a lock and Lock was asked not to wait
+var ErrLocked = errors.New("locked by another process")
+
+// Lock locks f, exclusively or shared with other readers. Unless wait is set it fails
+// with ErrLocked rather than waiting for a conflicting lock to go away.
+func Lock(f *os.File, exclusive, wait bool) error {
+	return lock(f, exclusive, wait)
+}
@@ -0,0 +1,122 @@
+// Package flocklog is flock's own log: leveled, structured records written to
+// ~/.flock/flock.log through log/slog, with the latest kept in memory for the
+// dashboard's log view.
+package flocklog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxBytes is how large the log may grow before it is moved to flock.log.1 on the next start
+const maxBytes = 5 << 20
+
+// keep is how many records stay in memory for the log view
+const keep = 500
+
+// Record is a log record as the log view shows it
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   string // key=value pairs, e.g. "task=007 err=..."
+}
+
+// recent holds the latest records, oldest first
+var recent = struct {
+	sync.Mutex
+	records []Record
+}{}
+
+// ParseLevel parses a level name ("debug", "info", "warn" or "error"); empty means info
+func ParseLevel(s string) (slog.Level, error) {
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", s)
+	}
+	return level, nil
+}
+
+// Setup makes the default slog logger, and the standard log package with it, write records
+// at or above level to the file at path and, when echo is set, to echo as well. A log that
+// has grown past 5 MiB is moved aside first. Close the returned file on exit.
+func Setup(path string, level slog.Level, echo io.Writer) (io.Closer, error) {
+	if info, err := os.Stat(path); err == nil && info.Size() > maxBytes {
+		os.Rename(path, path+".1")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log: %w", err)
+	}
+	var out io.Writer = file
+	if echo != nil {
+		out = io.MultiWriter(file, echo)
+	}
+	text := slog.NewTextHandler(out, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(&handler{text: text}))
+	return file, nil
+}
+
+// Recent returns the latest records at or above min, oldest first
+func Recent(min slog.Level) []Record {
+	recent.Lock()
+	defer recent.Unlock()
+	var records []Record
+	for _, r := range recent.records {
+		if r.Level >= min {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// remember keeps a record for the log view, dropping the oldest past keep
+func remember(r Record) {
+	recent.Lock()
+	defer recent.Unlock()
+	if len(recent.records) >= keep {
+		recent.records = append(recent.records[:0], recent.records[1:]...)
+	}
+	recent.records = append(recent.records, r)
+}
+
+// handler writes records as text and keeps them for the log view
+type handler struct {
+	text  slog.Handler
+	attrs []slog.Attr // Added with WithAttrs
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.text.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	var attrs []string
+	add := func(a slog.Attr) bool {
+		attrs = append(attrs, a.String())
+		return true
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(add)
+	remember(Record{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: strings.Join(attrs, " ")})
+	return h.text.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{text: h.text.WithAttrs(attrs), attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{text: h.text.WithGroup(name), attrs: h.attrs}
+}
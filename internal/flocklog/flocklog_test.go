@@ -0,0 +1,66 @@
+package flocklog
+
+import (
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	path := filepath.Join(t.TempDir(), "flock.log")
+	closer, err := Setup(path, slog.LevelInfo, nil)
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	defer closer.Close()
+
+	slog.Debug("left out", "task", "001")
+	slog.Warn("failed to open tab", "task", "002")
+	log.Printf("from the standard logger")
+
+	var messages []string
+	for _, r := range Recent(slog.LevelDebug) {
+		messages = append(messages, r.Message)
+	}
+	if got := strings.Join(messages, "|"); !strings.HasSuffix(got, "failed to open tab|from the standard logger") || strings.Contains(got, "left out") {
+		t.Errorf("expected the warning and the standard log line without the debug record, got %q", got)
+	}
+	if warnings := Recent(slog.LevelWarn); len(warnings) != 1 || warnings[0].Attrs != "task=002" {
+		t.Errorf("expected one warning with its task, got %+v", warnings)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	if !strings.Contains(string(data), `level=WARN msg="failed to open tab" task=002`) {
+		t.Errorf("expected the warning in the file, got %q", data)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"debug", slog.LevelDebug, false},
+		{"WARN", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"loud", slog.LevelInfo, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q): expected error %v, got %v", tt.input, tt.wantErr, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q): expected %v, got %v", tt.input, tt.want, got)
+		}
+	}
+}
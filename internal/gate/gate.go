@@ -0,0 +1,70 @@
+// Package gate runs the command a task's branch must pass before it is merged
+package gate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// Result is the outcome of running the merge check
+type Result struct {
+	Command  string
+	Passed   bool
+	Output   string        // Standard output and error together
+	Failure  string        // Why the check failed, e.g. "exit status 1"
+	Duration time.Duration // How long the command ran
+	LogPath  string        // Where the output was saved ("" if it could not be)
+}
+
+// Run runs command with sh in dir, failing it when it exits non-zero or runs past timeout.
+// The output is saved to logPath, if set, so the full log can be read after a failure.
+func Run(r runner.Runner, command, dir, logPath string, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	output, err := r.CombinedOutput(ctx, runner.Command("sh", "-c", command).In(dir))
+	result := Result{
+		Command:  command,
+		Passed:   err == nil,
+		Output:   string(output),
+		Duration: time.Since(start),
+	}
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Passed = false
+		result.Failure = fmt.Sprintf("timed out after %s", timeout)
+	case err != nil:
+		result.Failure = err.Error()
+	}
+
+	if logPath != "" && os.WriteFile(logPath, output, 0644) == nil {
+		result.LogPath = logPath
+	}
+	return result
+}
+
+// Summary describes the result in a few words, e.g. "go test ./... passed in 12s"
+func (r Result) Summary() string {
+	if r.Passed {
+		return fmt.Sprintf("%s passed in %s", r.Command, r.Duration.Round(time.Second))
+	}
+	return fmt.Sprintf("%s failed (%s)", r.Command, r.Failure)
+}
+
+// Tail returns the last n non-empty lines of the output, where test failures usually end up
+func (r Result) Tail(n int) []string {
+	lines := strings.Split(strings.TrimRight(r.Output, "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -0,0 +1,113 @@
+package gate
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/gittest"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    runner.Response
+		passed  bool
+		summary string
+		tail    []string
+	}{
+		{
+			name:    "passing",
+			resp:    runner.Response{Output: "ok  \tpkg/a\nok  \tpkg/b\n"},
+			passed:  true,
+			summary: "go test ./... passed in 0s",
+			tail:    []string{"ok  \tpkg/b"},
+		},
+		{
+			name:    "failing",
+			resp:    runner.Response{Output: "--- FAIL: TestFoo\nFAIL\tpkg/a\n", ExitCode: 1},
+			summary: "go test ./... failed (exit status 1)",
+			tail:    []string{"FAIL\tpkg/a"},
+		},
+		{
+			name:    "no output",
+			resp:    runner.Response{ExitCode: 2},
+			summary: "go test ./... failed (exit status 2)",
+		},
+	}
+
+	for _, tt := range tests {
+		fake := runner.NewFake()
+		fake.On("sh -c go test", tt.resp)
+		logPath := filepath.Join(t.TempDir(), "check.log")
+
+		result := Run(fake, "go test ./...", "/src/app", logPath, time.Minute)
+		if result.Passed != tt.passed {
+			t.Errorf("%s: expected passed %v, got %v", tt.name, tt.passed, result.Passed)
+		}
+		if got := result.Summary(); got != tt.summary {
+			t.Errorf("%s: expected summary %q, got %q", tt.name, tt.summary, got)
+		}
+		if got := result.Tail(1); len(got) != len(tt.tail) || (len(got) == 1 && got[0] != tt.tail[0]) {
+			t.Errorf("%s: expected tail %q, got %q", tt.name, tt.tail, got)
+		}
+		if calls := fake.Calls(); len(calls) != 1 || calls[0].Dir != "/src/app" {
+			t.Errorf("%s: expected the command to run in /src/app, got %v", tt.name, calls)
+		}
+		if data, err := os.ReadFile(result.LogPath); err != nil || string(data) != tt.resp.Output {
+			t.Errorf("%s: expected the output saved to the log, got %q (%v)", tt.name, data, err)
+		}
+	}
+}
+
+func TestCheckReady(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	gittest.Run(t, repo, "branch", "empty")
+	gittest.Run(t, repo, "checkout", "-q", "-b", "clean")
+	gittest.CommitFile(t, repo, "b.txt", "new\n")
+	gittest.Run(t, repo, "checkout", "-q", "-b", "conflicting", "main")
+	gittest.CommitFile(t, repo, "a.txt", "two\n")
+	gittest.Run(t, repo, "checkout", "-q", "main")
+	gittest.CommitFile(t, repo, "a.txt", "three\n")
+	if _, err := exec.Command("git", "-C", repo, "merge-tree", "--write-tree", "main", "clean").Output(); err != nil {
+		t.Skipf("merge-tree unavailable: %v", err)
+	}
+
+	checks := 0
+	passing := func() Result { checks++; return Result{Passed: true} }
+	failing := func() Result { checks++; return Result{Command: "make test", Failure: "exit status 2"} }
+
+	tests := []struct {
+		branch string
+		check  func() Result
+		ready  bool
+		reason string
+	}{
+		{"clean", passing, true, ""},
+		{"clean", nil, true, ""},
+		{"clean", failing, false, "make test failed (exit status 2)"},
+		{"empty", passing, false, "no commits to merge"},
+		{"conflicting", passing, false, "conflicts in 1 files"},
+	}
+	for _, tt := range tests {
+		r := CheckReady(repo, tt.branch, tt.check, nil)
+		if r.Ready != tt.ready || r.Reason != tt.reason {
+			t.Errorf("%s: expected ready %v (%q), got %v (%q)", tt.branch, tt.ready, tt.reason, r.Ready, r.Reason)
+		}
+	}
+
+	// An unchanged branch keeps its previous result without running the check again
+	prev := CheckReady(repo, "clean", passing, nil)
+	checks = 0
+	if r := CheckReady(repo, "clean", failing, &prev); !r.Ready || checks != 0 {
+		t.Errorf("expected the previous result to be reused, got %+v after %d checks", r, checks)
+	}
+	gittest.CommitFile(t, repo, "a.txt", "four\n")
+	if r := CheckReady(repo, "clean", failing, &prev); r.Ready || checks != 1 {
+		t.Errorf("expected a moved default branch to be checked again, got %+v after %d checks", r, checks)
+	}
+}
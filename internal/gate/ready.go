@@ -0,0 +1,67 @@
+package gate
+
+import (
+	"fmt"
+
+	"github.com/dfowler/flock/internal/git"
+)
+
+// Readiness records whether a task's branch is ready to merge, and the commits it was judged at
+type Readiness struct {
+	Head   string // Branch commit that was checked
+	Base   string // Default branch commit it was checked against
+	Ready  bool
+	Reason string // Why the branch is not ready, e.g. "conflicts in 2 files"
+}
+
+// CheckReady judges whether branch is ready to merge into the default branch: it has
+// commits the default branch lacks, merges without conflicts, and passes check (skipped
+// when nil). A previous result for the same two commits is returned as is, so the check
+// command only runs again once the branch or the default branch moves.
+func CheckReady(repoRoot, branch string, check func() Result, prev *Readiness) Readiness {
+	head, err := git.RevParse(repoRoot, branch)
+	if err != nil {
+		return Readiness{Reason: err.Error()}
+	}
+	defaultBranch, err := git.GetDefaultBranch(repoRoot)
+	if err != nil {
+		return Readiness{Head: head, Reason: err.Error()}
+	}
+	base, err := git.RevParse(repoRoot, defaultBranch)
+	if err != nil {
+		return Readiness{Head: head, Reason: err.Error()}
+	}
+	if prev != nil && prev.Head == head && prev.Base == base {
+		return *prev
+	}
+
+	r := Readiness{Head: head, Base: base}
+	ahead, err := git.CommitsAhead(repoRoot, branch)
+	switch {
+	case err != nil:
+		r.Reason = err.Error()
+		return r
+	case ahead == 0:
+		r.Reason = "no commits to merge"
+		return r
+	}
+
+	merge, err := git.CheckMerge(repoRoot, branch)
+	switch {
+	case err != nil:
+		r.Reason = err.Error()
+		return r
+	case len(merge.Conflicts) > 0:
+		r.Reason = fmt.Sprintf("conflicts in %d files", len(merge.Conflicts))
+		return r
+	}
+
+	if check != nil {
+		if result := check(); !result.Passed {
+			r.Reason = result.Summary()
+			return r
+		}
+	}
+	r.Ready = true
+	return r
+}
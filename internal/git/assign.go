@@ -1,9 +1,16 @@
 package git
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
 )
 
 // WorktreeAssignment holds info about a task's worktree assignment
@@ -13,21 +20,256 @@ type WorktreeAssignment struct {
 	RepoRoot     string
 }
 
+// Event reports the outcome of a background worktree operation
+type Event struct {
+	RepoRoot string
+	Message  string
+	Err      error
+}
+
+// Retry settings for worktree operations that hit git lock contention
+const (
+	worktreeOpAttempts = 3
+	worktreeOpBackoff  = 250 * time.Millisecond
+)
+
+// warmTimeout is how long a spare's warm-up command may run before it is killed
+const warmTimeout = 20 * time.Minute
+
 // Assigner manages worktree assignment for tasks
 type Assigner struct {
-	mu             sync.Mutex
-	maxPerRepo     int
-	enabled        bool
+	mu                sync.Mutex
+	maxPerRepo        int
+	spareCount        int      // spare worktrees to keep ready per repo (0 disables spares)
+	branchTemplate    string   // template for task branch names (see FormatBranchName)
+	warmCommand       string   // shell command run in each new spare worktree (empty disables)
+	copyFiles         []string // untracked files copied from the main checkout on assignment
+	linkFiles         []string // untracked files symlinked from the main checkout on assignment
+	fetchBase         bool     // fetch origin and start task branches from origin/<default>
+	enabled           bool
 	creatingWorktrees map[string]bool // tracks worktrees currently being created
+
+	// Per-repo locks serializing `git worktree add/remove` so concurrent
+	// task creation and background spares never race in the same repo
+	repoLocksMu sync.Mutex
+	repoLocks   map[string]*sync.Mutex
+
+	events chan Event // background failures, consumed by the TUI
+
+	// Background spare creation stops starting new worktrees once ctx is canceled,
+	// and Shutdown waits for the ones in progress
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 // NewAssigner creates a new worktree assigner
-func NewAssigner(enabled bool, maxPerRepo int) *Assigner {
+func NewAssigner(enabled bool, maxPerRepo, spareCount int) *Assigner {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Assigner{
+		ctx:               ctx,
+		cancel:            cancel,
 		enabled:           enabled,
 		maxPerRepo:        maxPerRepo,
+		spareCount:        spareCount,
 		creatingWorktrees: make(map[string]bool),
+		repoLocks:         make(map[string]*sync.Mutex),
+		events:            make(chan Event, 32),
+	}
+}
+
+// Shutdown stops creating spare worktrees and waits for the one being created, if any,
+// so git isn't interrupted halfway through `worktree add`. It gives up when ctx is done.
+func (a *Assigner) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	a.cancel()
+	a.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for spare worktrees: %w", ctx.Err())
+	}
+}
+
+// Events returns the channel on which background worktree failures are reported
+func (a *Assigner) Events() <-chan Event {
+	return a.events
+}
+
+// emit reports a background event without ever blocking worktree work
+func (a *Assigner) emit(ev Event) {
+	select {
+	case a.events <- ev:
+	default:
+		// Nobody is listening fast enough; the failure is still logged
+	}
+}
+
+// repoLock returns the lock serializing worktree operations for a repo
+func (a *Assigner) repoLock(repoRoot string) *sync.Mutex {
+	a.repoLocksMu.Lock()
+	defer a.repoLocksMu.Unlock()
+
+	lock, ok := a.repoLocks[repoRoot]
+	if !ok {
+		lock = &sync.Mutex{}
+		a.repoLocks[repoRoot] = lock
+	}
+	return lock
+}
+
+// createWorktree creates a worktree with a new branch starting at base while holding the
+// repo's lock, retrying transient failures
+func (a *Assigner) createWorktree(repoRoot, worktreePath, branch, base string) error {
+	lock := a.repoLock(repoRoot)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return retryWorktreeOp(func() error {
+		return CreateWorktreeFrom(repoRoot, worktreePath, branch, base)
+	})
+}
+
+// checkoutWorktree creates a worktree for an existing branch while holding the repo's lock
+func (a *Assigner) checkoutWorktree(repoRoot, worktreePath, branch string) error {
+	lock := a.repoLock(repoRoot)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return retryWorktreeOp(func() error {
+		return CreateWorktreeForBranch(repoRoot, worktreePath, branch)
+	})
+}
+
+// removeWorktree removes a worktree and its branch while holding the repo's lock
+func (a *Assigner) removeWorktree(repoRoot, worktreePath string) error {
+	lock := a.repoLock(repoRoot)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return retryWorktreeOp(func() error {
+		return RemoveWorktree(repoRoot, worktreePath, true)
+	})
+}
+
+// retryWorktreeOp runs op, retrying with backoff while it fails on git lock contention
+func retryWorktreeOp(op func() error) error {
+	var err error
+	for attempt := 1; attempt <= worktreeOpAttempts; attempt++ {
+		if err = op(); err == nil || !isTransientGitError(err) {
+			return err
+		}
+		if attempt < worktreeOpAttempts {
+			time.Sleep(worktreeOpBackoff * time.Duration(attempt))
+		}
+	}
+	return fmt.Errorf("gave up after %d attempts: %w", worktreeOpAttempts, err)
+}
+
+// describeWorktreeError turns raw git failure output into a short human-readable reason
+func describeWorktreeError(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "No space left on device"):
+		return "disk full"
+	case strings.Contains(msg, "already exists"):
+		return "branch or path already exists"
+	case strings.Contains(msg, "maximum worktrees"):
+		return "worktree limit reached"
+	case isTransientGitError(err):
+		return "repository locked by another git process"
+	}
+	// Fall back to the first line of git's output
+	if idx := strings.Index(msg, "\n"); idx != -1 {
+		msg = msg[:idx]
+	}
+	return msg
+}
+
+// isTransientGitError reports whether a git failure was caused by another git
+// process holding a lock, in which case the operation is worth retrying
+func isTransientGitError(err error) bool {
+	msg := err.Error()
+	for _, marker := range []string{
+		".lock': File exists",
+		"could not lock",
+		"Unable to create",
+		"another git process",
+	} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
 	}
+	return false
+}
+
+// SetSpareCount changes how many spare worktrees are kept ready per repo
+func (a *Assigner) SetSpareCount(n int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.spareCount = n
+}
+
+// SetBranchTemplate changes the template used to name task branches
+func (a *Assigner) SetBranchTemplate(template string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.branchTemplate = template
+}
+
+// SetWarmCommand changes the shell command run in each new spare worktree, such as
+// `npm ci`, so tasks assigned a spare start with its dependencies installed
+func (a *Assigner) SetWarmCommand(command string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.warmCommand = command
+}
+
+// SetWorktreeFiles changes the glob patterns of untracked files, such as .env, copied or
+// symlinked from the main checkout into a worktree when it is assigned to a task
+func (a *Assigner) SetWorktreeFiles(copyPatterns, linkPatterns []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.copyFiles = copyPatterns
+	a.linkFiles = linkPatterns
+}
+
+// SetFetchBase changes whether origin is fetched before a task gets a worktree, so its
+// branch starts from origin/<default> rather than a possibly stale local default branch
+func (a *Assigner) SetFetchBase(fetch bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.fetchBase = fetch
+}
+
+// resolveBase finds where a task's branch starts, reporting a failed fetch or a local
+// default branch that is behind origin
+func (a *Assigner) resolveBase(repoRoot string, fetch bool) Base {
+	base, err := ResolveBase(repoRoot, fetch)
+	name := filepath.Base(repoRoot)
+	switch {
+	case err != nil && base.Ref == "":
+		// Not even a default branch; git reports the real problem when the worktree is made
+		base.Ref = "HEAD"
+	case err != nil:
+		slog.Warn("fetch before worktree creation failed", "repo", repoRoot, "err", err)
+		a.emit(Event{RepoRoot: repoRoot, Message: fmt.Sprintf("%s: %v; the branch starts from local %s", name, err, base.Default), Err: err})
+	case base.Behind > 0 && base.Ref == base.Default:
+		a.emit(Event{
+			RepoRoot: repoRoot,
+			Message:  fmt.Sprintf("%s: local %s is %d commit(s) behind origin/%s, and the new branch starts from it (pull, or set worktrees.fetch_base)", name, base.Default, base.Behind, base.Default),
+			Err:      fmt.Errorf("local %s is behind origin", base.Default),
+		})
+	case base.Behind > 0:
+		a.emit(Event{RepoRoot: repoRoot, Message: fmt.Sprintf("%s: the branch starts from origin/%s, %d commit(s) ahead of local %s", name, base.Default, base.Behind, base.Default)})
+	}
+	return base
 }
 
 // TaskWorktreeInfo is the interface that tasks must implement for worktree assignment
@@ -37,9 +279,41 @@ type TaskWorktreeInfo interface {
 	GetWorktreePath() string
 }
 
+// BranchCollision defines what to do when a new worktree's branch already exists
+type BranchCollision int
+
+const (
+	// BranchCollisionFail returns a *BranchExistsError so the caller can ask the user
+	BranchCollisionFail BranchCollision = iota
+	// BranchCollisionReuse checks out the existing branch in the new worktree
+	BranchCollisionReuse
+	// BranchCollisionSuffix creates a new branch with a unique numeric suffix
+	BranchCollisionSuffix
+)
+
+// BranchExistsError is returned when a task's worktree branch is left over from a previous session
+type BranchExistsError struct {
+	Branch   string
+	RepoRoot string
+	Ahead    int // commits on the branch not in the default branch
+}
+
+func (e *BranchExistsError) Error() string {
+	if e.Ahead > 0 {
+		return fmt.Sprintf("branch %s already exists from a previous session (%d commit(s) ahead of the default branch)", e.Branch, e.Ahead)
+	}
+	return fmt.Sprintf("branch %s already exists from a previous session", e.Branch)
+}
+
 // AssignWorktree assigns a worktree to a task, creating one if needed
 // Returns the assignment info or nil if worktrees are disabled or not in a git repo
-func (a *Assigner) AssignWorktree(taskID, taskCwd string, activeTasks []TaskWorktreeInfo) (*WorktreeAssignment, error) {
+// If the task's branch already exists, a *BranchExistsError is returned
+func (a *Assigner) AssignWorktree(taskID, taskName, taskCwd string, activeTasks []TaskWorktreeInfo) (*WorktreeAssignment, error) {
+	return a.AssignWorktreeWithCollision(taskID, taskName, taskCwd, activeTasks, BranchCollisionFail)
+}
+
+// AssignWorktreeWithCollision assigns a worktree, resolving an existing branch per the given policy
+func (a *Assigner) AssignWorktreeWithCollision(taskID, taskName, taskCwd string, activeTasks []TaskWorktreeInfo, collision BranchCollision) (*WorktreeAssignment, error) {
 	if !a.enabled {
 		return nil, nil
 	}
@@ -68,6 +342,12 @@ func (a *Assigner) AssignWorktree(taskID, taskCwd string, activeTasks []TaskWork
 		}, nil
 	}
 
+	// Fetch before taking the lock, so a slow remote doesn't hold up other repos
+	a.mu.Lock()
+	fetch := a.fetchBase
+	a.mu.Unlock()
+	base := a.resolveBase(repoRoot, fetch)
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -78,21 +358,54 @@ func (a *Assigner) AssignWorktree(taskID, taskCwd string, activeTasks []TaskWork
 	}
 
 	var assignment *WorktreeAssignment
+	branch := FormatBranchName(a.branchTemplate, taskID, taskName)
 
 	if freePath != "" {
 		// Use existing free worktree
 		worktrees, _ := ListWorktrees(repoRoot)
 		for _, wt := range worktrees {
 			if wt.Path == freePath {
-				// Reset the branch to the current default branch HEAD
+				reuseBranch := false
+				if wt.Branch != branch && BranchExists(repoRoot, branch) {
+					switch collision {
+					case BranchCollisionReuse:
+						reuseBranch = true
+					case BranchCollisionSuffix:
+						branch = UniqueBranchName(repoRoot, branch)
+					default:
+						ahead, _ := CommitsAhead(repoRoot, branch)
+						return nil, &BranchExistsError{Branch: branch, RepoRoot: repoRoot, Ahead: ahead}
+					}
+				}
+
+				// Uncommitted work left behind by the last task survives in a named stash
+				owner := wt.Branch
+				if owner == "" {
+					owner = filepath.Base(wt.Path)
+				}
+				stash := StashMessage(owner, "reuse by task "+taskID)
+				if stashed, err := StashChanges(wt.Path, stash); err != nil {
+					return nil, fmt.Errorf("not reusing %s, whose uncommitted changes couldn't be saved: %w", filepath.Base(wt.Path), err)
+				} else if stashed {
+					a.emit(Event{RepoRoot: repoRoot, Message: fmt.Sprintf("Stashed uncommitted changes left in %s as %q (see git stash list)", filepath.Base(wt.Path), stash)})
+				}
+
+				// Reset the branch to the base (the default branch, or origin's after a fetch)
 				// This ensures the reused worktree starts fresh with latest code
-				if err := ResetWorktreeBranch(wt.Path); err != nil {
+				if err := ResetWorktreeTo(wt.Path, base.Ref); err != nil {
 					return nil, fmt.Errorf("failed to reset worktree branch: %w", err)
 				}
 
+				// Give the worktree's branch the task's name
+				if wt.Branch != branch {
+					if err := switchWorktreeBranch(wt.Path, repoRoot, wt.Branch, branch, reuseBranch); err != nil {
+						return nil, err
+					}
+				}
+
 				assignment = &WorktreeAssignment{
 					WorktreePath: wt.Path,
-					GitBranch:    wt.Branch,
+					GitBranch:    branch,
 					RepoRoot:     repoRoot,
 				}
 				break
@@ -107,15 +420,33 @@ func (a *Assigner) AssignWorktree(taskID, taskCwd string, activeTasks []TaskWork
 		}
 
 		// Create new worktree
-		worktreePath := WorktreePath(repoRoot, taskID)
-		branch := BranchName(taskID)
+		worktreePath := uniqueWorktreePath(WorktreePath(repoRoot, taskID))
+		reuseBranch := false
+
+		if BranchExists(repoRoot, branch) {
+			switch collision {
+			case BranchCollisionReuse:
+				reuseBranch = true
+			case BranchCollisionSuffix:
+				branch = UniqueBranchName(repoRoot, branch)
+			default:
+				ahead, _ := CommitsAhead(repoRoot, branch)
+				return nil, &BranchExistsError{Branch: branch, RepoRoot: repoRoot, Ahead: ahead}
+			}
+		}
 
 		if err := a.ensureWorktreeDir(repoRoot); err != nil {
 			return nil, fmt.Errorf("failed to create worktree directory: %w", err)
 		}
 
-		if err := CreateWorktree(repoRoot, worktreePath, branch); err != nil {
-			return nil, fmt.Errorf("failed to create worktree: %w", err)
+		var err error
+		if reuseBranch {
+			err = a.checkoutWorktree(repoRoot, worktreePath, branch)
+		} else {
+			err = a.createWorktree(repoRoot, worktreePath, branch, base.Ref)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create worktree: %s", describeWorktreeError(err))
 		}
 
 		assignment = &WorktreeAssignment{
@@ -125,8 +456,29 @@ func (a *Assigner) AssignWorktree(taskID, taskCwd string, activeTasks []TaskWork
 		}
 	}
 
-	// Trigger background +1 creation if needed
-	go a.ensurePlusOne(repoRoot, activeTasks, taskID)
+	// A worktree without the checkout's .env and the like can leave the agent stuck, but the
+	// task can still run, so a failure is reported rather than returned
+	if _, err := CopyWorktreeFiles(repoRoot, assignment.WorktreePath, a.copyFiles, a.linkFiles); err != nil {
+		slog.Warn("copying files into worktree failed", "worktree", assignment.WorktreePath, "err", err)
+		a.emit(Event{RepoRoot: repoRoot, Message: fmt.Sprintf("Failed to copy files into %s: %v", filepath.Base(assignment.WorktreePath), err), Err: err})
+	}
+
+	// Trigger background spare creation if needed. a.mu is held, so no spare starts
+	// once Shutdown is waiting.
+	if a.spareCount > 0 && a.ctx.Err() == nil {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.ensureSpares(repoRoot, activeTasks, assignment.WorktreePath); err != nil {
+				slog.Error("spare worktree creation failed", "repo", repoRoot, "err", err)
+				a.emit(Event{
+					RepoRoot: repoRoot,
+					Message:  fmt.Sprintf("Spare worktree creation failed in %s: %s", filepath.Base(repoRoot), describeWorktreeError(err)),
+					Err:      err,
+				})
+			}
+		}()
+	}
 
 	return assignment, nil
 }
@@ -137,7 +489,7 @@ func (a *Assigner) ReleaseWorktree(worktreePath, repoRoot string) error {
 		return nil
 	}
 
-	return RemoveWorktree(repoRoot, worktreePath, true)
+	return a.removeWorktree(repoRoot, worktreePath)
 }
 
 // findFreeWorktree finds a free flock worktree in the repo
@@ -148,12 +500,7 @@ func (a *Assigner) findFreeWorktree(repoRoot string, activeTasks []TaskWorktreeI
 	}
 
 	// Build set of assigned paths
-	assignedPaths := make(map[string]bool)
-	for _, t := range activeTasks {
-		if t.GetWorktreePath() != "" {
-			assignedPaths[t.GetWorktreePath()] = true
-		}
-	}
+	assignedPaths := assignedWorktreePaths(activeTasks)
 
 	// Also exclude worktrees currently being created
 	for path := range a.creatingWorktrees {
@@ -192,79 +539,205 @@ func (a *Assigner) ensureWorktreeDir(repoRoot string) error {
 	return os.MkdirAll(dir, 0755)
 }
 
-// ensurePlusOne creates an additional worktree in the background if needed
-func (a *Assigner) ensurePlusOne(repoRoot string, activeTasks []TaskWorktreeInfo, excludeTaskID string) {
-	a.mu.Lock()
+// ensureSpares creates spare worktrees in the background until spareCount are free
+// justAssigned is the worktree handed out by the caller, which is not yet recorded on a task
+func (a *Assigner) ensureSpares(repoRoot string, activeTasks []TaskWorktreeInfo, justAssigned string) error {
+	for a.ctx.Err() == nil {
+		a.mu.Lock()
 
-	// Count free worktrees (excluding the one we just assigned)
-	freeCount := 0
-	worktrees, err := ListWorktrees(repoRoot)
-	if err != nil {
-		a.mu.Unlock()
-		return
-	}
+		worktrees, err := ListWorktrees(repoRoot)
+		if err != nil {
+			a.mu.Unlock()
+			return err
+		}
 
-	assignedPaths := make(map[string]bool)
-	for _, t := range activeTasks {
-		if t.GetWorktreePath() != "" {
-			assignedPaths[t.GetWorktreePath()] = true
+		assignedPaths := assignedWorktreePaths(activeTasks)
+		assignedPaths[justAssigned] = true
+
+		// Count free worktrees, including ones already being created for this repo
+		freeCount := 0
+		for path := range a.creatingWorktrees {
+			if strings.HasPrefix(path, WorktreeDirPath(repoRoot)) {
+				freeCount++
+			}
+		}
+		for _, wt := range worktrees {
+			if IsFlockWorktree(wt.Path) && !assignedPaths[wt.Path] {
+				freeCount++
+			}
 		}
-	}
 
-	for _, wt := range worktrees {
-		if IsFlockWorktree(wt.Path) && !assignedPaths[wt.Path] {
-			freeCount++
+		// Stop once the buffer is full
+		if freeCount >= a.spareCount {
+			a.mu.Unlock()
+			return nil
 		}
-	}
 
-	// If we already have at least 1 free, no need to create more
-	if freeCount >= 1 {
+		// Check if we've hit the max; report it so the limit is visible before a launch fails
+		flockWorktreeCount := a.countFlockWorktrees(repoRoot)
+		if a.maxPerRepo > 0 && flockWorktreeCount >= a.maxPerRepo {
+			a.mu.Unlock()
+			return fmt.Errorf("maximum worktrees (%d) reached for this repository", a.maxPerRepo)
+		}
+
+		// Generate a unique ID for the spare worktree
+		spareID := nextSpareID(worktrees, a.creatingWorktrees)
+		worktreePath := WorktreePath(repoRoot, spareID)
+
+		// Mark as creating
+		a.creatingWorktrees[worktreePath] = true
+		warmCommand := a.warmCommand
+		copyFiles, linkFiles := a.copyFiles, a.linkFiles
 		a.mu.Unlock()
-		return
-	}
 
-	// Check if we've hit the max
-	flockWorktreeCount := a.countFlockWorktrees(repoRoot)
-	if a.maxPerRepo > 0 && flockWorktreeCount >= a.maxPerRepo {
+		// Create the worktree (outside lock, serialized per repo)
+		// Spares never reuse leftover branches; pick a fresh name instead
+		branch := UniqueBranchName(repoRoot, BranchName(spareID))
+		// They start from the local default branch; a task's branch is reset to its own
+		// base when it takes one
+		createErr := a.ensureWorktreeDir(repoRoot)
+		if createErr == nil {
+			var base string
+			if base, createErr = GetDefaultBranch(repoRoot); createErr == nil {
+				createErr = a.createWorktree(repoRoot, worktreePath, branch, base)
+			}
+		}
+
+		// Warm it up while it is still marked as creating, so no task is handed a
+		// half-installed worktree
+		if createErr == nil && warmCommand != "" {
+			// The warm-up may need the files tasks get, such as .npmrc
+			if _, err := CopyWorktreeFiles(repoRoot, worktreePath, copyFiles, linkFiles); err != nil {
+				slog.Warn("copying files into worktree failed", "worktree", worktreePath, "err", err)
+			}
+			a.warmWorktree(repoRoot, worktreePath, warmCommand)
+		}
+
+		// Unmark as creating
+		a.mu.Lock()
+		delete(a.creatingWorktrees, worktreePath)
 		a.mu.Unlock()
-		return
-	}
 
-	// Generate a unique ID for the +1 worktree
-	nextID := fmt.Sprintf("spare-%03d", flockWorktreeCount+1)
-	worktreePath := WorktreePath(repoRoot, nextID)
+		if createErr != nil {
+			return fmt.Errorf("failed to create spare worktree %s: %w", spareID, createErr)
+		}
+	}
+	return nil
+}
 
-	// Mark as creating
-	a.creatingWorktrees[worktreePath] = true
-	a.mu.Unlock()
+// warmWorktree runs the warm-up command in a new spare worktree and reports how it went.
+// A failed warm-up leaves the spare in the pool; the task's agent can still install what
+// it needs.
+func (a *Assigner) warmWorktree(repoRoot, worktreePath, command string) {
+	ctx, cancel := context.WithTimeout(a.ctx, warmTimeout)
+	defer cancel()
 
-	// Create the worktree (outside lock)
-	branch := BranchName(nextID)
-	_ = a.ensureWorktreeDir(repoRoot)
-	_ = CreateWorktree(repoRoot, worktreePath, branch)
+	start := time.Now()
+	output, err := commands.CombinedOutput(ctx, runner.Command("sh", "-c", command).In(worktreePath))
+	name := filepath.Base(worktreePath)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", warmTimeout)
+		} else if last := lastLine(string(output)); last != "" {
+			err = fmt.Errorf("%w: %s", err, last)
+		}
+		slog.Warn("worktree warm-up failed", "worktree", worktreePath, "err", err)
+		a.emit(Event{
+			RepoRoot: repoRoot,
+			Message:  fmt.Sprintf("Warm-up of %s in %s failed: %v", name, filepath.Base(repoRoot), err),
+			Err:      err,
+		})
+		return
+	}
+	a.emit(Event{
+		RepoRoot: repoRoot,
+		Message:  fmt.Sprintf("Warmed up %s in %s (%s)", name, filepath.Base(repoRoot), time.Since(start).Round(time.Second)),
+	})
+}
 
-	// Unmark as creating
-	a.mu.Lock()
-	delete(a.creatingWorktrees, worktreePath)
-	a.mu.Unlock()
+// lastLine returns the last non-empty line of output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
 }
 
-// CountFreeWorktrees returns the number of free worktrees for a repo
-func (a *Assigner) CountFreeWorktrees(repoRoot string, activeTasks []TaskWorktreeInfo) int {
+// TrimSpares removes free spare worktrees beyond the configured buffer.
+// Only pristine spares (clean, with no commits beyond the default branch) are
+// removed, so work left in a kept worktree is never discarded.
+// Returns the number of worktrees removed.
+func (a *Assigner) TrimSpares(repoRoot string, activeTasks []TaskWorktreeInfo) (int, error) {
+	if !a.enabled || repoRoot == "" {
+		return 0, nil
+	}
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	worktrees, err := ListWorktrees(repoRoot)
 	if err != nil {
-		return 0
+		return 0, err
 	}
 
+	assignedPaths := assignedWorktreePaths(activeTasks)
+
+	kept := 0
+	removed := 0
+	for _, wt := range worktrees {
+		if !IsSpareWorktree(wt.Path) || assignedPaths[wt.Path] || a.creatingWorktrees[wt.Path] {
+			continue
+		}
+		if kept < a.spareCount || !isPristineWorktree(repoRoot, wt.Path) {
+			kept++
+			continue
+		}
+		if err := a.removeWorktree(repoRoot, wt.Path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// nextSpareID returns the lowest spare ID not used by an existing or in-progress worktree
+func nextSpareID(worktrees []Worktree, creating map[string]bool) string {
+	used := make(map[string]bool)
+	for _, wt := range worktrees {
+		used[filepath.Base(wt.Path)] = true
+	}
+	for path := range creating {
+		used[filepath.Base(path)] = true
+	}
+
+	for n := 1; ; n++ {
+		id := fmt.Sprintf("spare-%03d", n)
+		if !used[FlockWorktreePrefix+id] {
+			return id
+		}
+	}
+}
+
+// assignedWorktreePaths returns the set of worktree paths held by tasks
+func assignedWorktreePaths(activeTasks []TaskWorktreeInfo) map[string]bool {
 	assignedPaths := make(map[string]bool)
 	for _, t := range activeTasks {
 		if t.GetWorktreePath() != "" {
 			assignedPaths[t.GetWorktreePath()] = true
 		}
 	}
+	return assignedPaths
+}
+
+// CountFreeWorktrees returns the number of free worktrees for a repo
+func (a *Assigner) CountFreeWorktrees(repoRoot string, activeTasks []TaskWorktreeInfo) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	worktrees, err := ListWorktrees(repoRoot)
+	if err != nil {
+		return 0
+	}
+
+	assignedPaths := assignedWorktreePaths(activeTasks)
 
 	count := 0
 	for _, wt := range worktrees {
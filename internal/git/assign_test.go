@@ -0,0 +1,85 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/gittest"
+)
+
+func TestIsTransientGitError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"fatal: Unable to create '/repo/.git/index.lock': File exists.", true},
+		{"error: could not lock config file .git/config: File exists", true},
+		{"fatal: cannot lock ref: another git process seems to be running", true},
+		{"fatal: 'flock-001' is already checked out", false},
+		{"fatal: No space left on device", false},
+	}
+	for _, tt := range tests {
+		if got := isTransientGitError(errors.New(tt.msg)); got != tt.want {
+			t.Errorf("isTransientGitError(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestRetryWorktreeOp(t *testing.T) {
+	locked := errors.New("fatal: Unable to create '/repo/.git/index.lock': File exists.")
+	tests := []struct {
+		name      string
+		failures  []error // errors returned by the first calls, before op succeeds
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "succeeds", wantCalls: 1},
+		{name: "retries lock contention", failures: []error{locked}, wantCalls: 2},
+		{name: "stops on other errors", failures: []error{errors.New("fatal: invalid reference")}, wantCalls: 1, wantErr: true},
+		{name: "gives up", failures: []error{locked, locked, locked}, wantCalls: worktreeOpAttempts, wantErr: true},
+	}
+	for _, tt := range tests {
+		calls := 0
+		err := retryWorktreeOp(func() error {
+			calls++
+			if calls <= len(tt.failures) {
+				return tt.failures[calls-1]
+			}
+			return nil
+		})
+		if calls != tt.wantCalls {
+			t.Errorf("%s: expected %d calls, got %d", tt.name, tt.wantCalls, calls)
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+}
+
+func TestAssignerRemoveWorktree(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+
+	a := NewAssigner(true, 0, 0)
+	path := WorktreePath(repo, "001")
+	if err := a.createWorktree(repo, path, "flock-001", "main"); err != nil {
+		t.Fatalf("createWorktree failed: %v", err)
+	}
+
+	// removeWorktree holds the repo's lock, so it must not take it again while retrying
+	done := make(chan error, 1)
+	go func() { done <- a.removeWorktree(repo, path) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("removeWorktree failed: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("removeWorktree did not return")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the worktree to be removed, got %v", err)
+	}
+}
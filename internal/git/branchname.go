@@ -0,0 +1,74 @@
+package git
+
+import (
+	"strings"
+)
+
+// DefaultBranchTemplate names task branches after the worktree ID, as flock always has
+const DefaultBranchTemplate = FlockWorktreePrefix + "{id}"
+
+// maxSlugLength keeps slugged task names from producing unwieldy branch names
+const maxSlugLength = 40
+
+// FormatBranchName expands a branch template for a task.
+// Supported placeholders are {id} (e.g. 007) and {task-slug} (e.g. fix-login-redirect).
+// An empty or invalid template falls back to DefaultBranchTemplate.
+func FormatBranchName(template, id, taskName string) string {
+	if template == "" {
+		template = DefaultBranchTemplate
+	}
+	slug := Slugify(taskName)
+	if slug == "" {
+		slug = "task"
+	}
+	name := strings.NewReplacer("{id}", id, "{task-slug}", slug).Replace(template)
+	if !validBranchName(name) {
+		return BranchName(id)
+	}
+	return name
+}
+
+// Slugify turns a task name into a lowercase, dash-separated branch component
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
+
+// validBranchName reports whether name is usable as a branch name, following the
+// rules of git check-ref-format that a template can break
+func validBranchName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, "/") ||
+		strings.HasSuffix(name, "/") || strings.HasSuffix(name, ".") || strings.HasSuffix(name, ".lock") {
+		return false
+	}
+	if strings.Contains(name, "..") || strings.Contains(name, "//") || strings.Contains(name, "@{") {
+		return false
+	}
+	for _, r := range name {
+		if r <= ' ' || r == 0x7f || strings.ContainsRune("~^:?*[\\{}", r) {
+			return false
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return true
+}
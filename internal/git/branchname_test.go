@@ -0,0 +1,164 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/gittest"
+)
+
+func TestFormatBranchName(t *testing.T) {
+	tests := []struct {
+		template string
+		name     string
+		expected string
+	}{
+		{"", "Fix login", "flock-007"},
+		{"flock-{id}", "Fix login", "flock-007"},
+		{"flock/{task-slug}-{id}", "Fix the Login redirect!", "flock/fix-the-login-redirect-007"},
+		{"feature/{task-slug}", "  ", "feature/task"},
+		{"flock/{task-slug}", "Ünïcode & spaces", "flock/n-code-spaces"},
+		{"bad name {id}", "x", "flock-007"},
+		{"flock/{date}-{id}", "x", "flock-007"},
+		{"flock/../{id}", "x", "flock-007"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBranchName(tt.template, "007", tt.name); got != tt.expected {
+			t.Errorf("FormatBranchName(%q, %q) = %q, expected %q", tt.template, tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestSlugifyTruncates(t *testing.T) {
+	slug := Slugify("refactor the entire authentication layer to use sessions")
+	if len(slug) > maxSlugLength {
+		t.Errorf("expected slug of at most %d characters, got %q", maxSlugLength, slug)
+	}
+	if slug != "refactor-the-entire-authentication-layer" {
+		t.Errorf("unexpected slug %q", slug)
+	}
+}
+
+func TestAssignWorktreeBranchTemplate(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+
+	a := NewAssigner(true, 0, 0)
+	a.SetBranchTemplate("flock/{task-slug}")
+
+	first, err := a.AssignWorktree("001", "Fix login", repo, nil)
+	if err != nil {
+		t.Fatalf("AssignWorktree failed: %v", err)
+	}
+	if first.GitBranch != "flock/fix-login" {
+		t.Errorf("expected branch flock/fix-login, got %s", first.GitBranch)
+	}
+
+	// The first worktree is taken, so a second task with the same name needs a new one
+	active := []TaskWorktreeInfo{fakeTaskInfo{id: "001", path: first.WorktreePath}}
+	_, err = a.AssignWorktree("002", "Fix login", repo, active)
+	var branchErr *BranchExistsError
+	if !errors.As(err, &branchErr) || branchErr.Branch != "flock/fix-login" {
+		t.Fatalf("expected a branch collision, got %v", err)
+	}
+	second, err := a.AssignWorktreeWithCollision("002", "Fix login", repo, active, BranchCollisionSuffix)
+	if err != nil {
+		t.Fatalf("AssignWorktreeWithCollision failed: %v", err)
+	}
+	if second.GitBranch != "flock/fix-login-2" {
+		t.Errorf("expected suffixed branch flock/fix-login-2, got %s", second.GitBranch)
+	}
+
+	// A freed worktree is renamed for the task that takes it over
+	third, err := a.AssignWorktree("003", "Add docs", repo, active)
+	if err != nil {
+		t.Fatalf("AssignWorktree of a free worktree failed: %v", err)
+	}
+	if third.WorktreePath != second.WorktreePath || third.GitBranch != "flock/add-docs" {
+		t.Errorf("expected %s renamed to flock/add-docs, got %s on %s", second.WorktreePath, third.GitBranch, third.WorktreePath)
+	}
+	if branch, _ := GetCurrentBranch(third.WorktreePath); branch != "flock/add-docs" {
+		t.Errorf("expected worktree checked out on flock/add-docs, got %s", branch)
+	}
+}
+
+func TestReuseStashesChanges(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+
+	a := NewAssigner(true, 0, 0)
+	first, err := a.AssignWorktree("001", "first", repo, nil)
+	if err != nil {
+		t.Fatalf("AssignWorktree failed: %v", err)
+	}
+	// The first task leaves an edit and a new file behind
+	for name, content := range map[string]string{"a.txt": "edited\n", "new.txt": "new\n"} {
+		if err := os.WriteFile(filepath.Join(first.WorktreePath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if files, _ := ChangedFiles(first.WorktreePath); len(files) != 2 {
+		t.Errorf("expected 2 changed files, got %v", files)
+	}
+
+	second, err := a.AssignWorktree("002", "second", repo, nil)
+	if err != nil || second.WorktreePath != first.WorktreePath {
+		t.Fatalf("expected the freed worktree to be reused, got %+v, %v", second, err)
+	}
+	if IsDirty(second.WorktreePath) {
+		t.Errorf("expected the reused worktree to start clean")
+	}
+	output, err := exec.Command("git", "-C", repo, "stash", "list").Output()
+	if err != nil || !strings.Contains(string(output), "flock: flock-001 before reuse by task 002") {
+		t.Errorf("expected a named stash of the left-over changes, got %q, %v", output, err)
+	}
+	select {
+	case ev := <-a.Events():
+		if ev.Err != nil || !strings.Contains(ev.Message, "Stashed") {
+			t.Errorf("expected a stash event, got %+v", ev)
+		}
+	default:
+		t.Errorf("expected the stash to be reported")
+	}
+}
+
+// fakeTaskInfo is a minimal TaskWorktreeInfo
+type fakeTaskInfo struct {
+	id   string
+	path string
+}
+
+func (f fakeTaskInfo) GetID() string           { return f.id }
+func (f fakeTaskInfo) GetCwd() string          { return "" }
+func (f fakeTaskInfo) GetWorktreePath() string { return f.path }
+
+func TestSpareWarmUp(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+
+	a := NewAssigner(true, 0, 1)
+	a.SetWarmCommand("touch warmed")
+	if _, err := a.AssignWorktree("001", "First", repo, nil); err != nil {
+		t.Fatalf("AssignWorktree failed: %v", err)
+	}
+	defer a.Shutdown(context.Background())
+
+	select {
+	case ev := <-a.Events():
+		if ev.Err != nil || !strings.Contains(ev.Message, "Warmed up flock-spare-001") {
+			t.Errorf("expected a warm-up event, got %+v", ev)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected a warm-up event")
+	}
+	if _, err := os.Stat(filepath.Join(WorktreePath(repo, "spare-001"), "warmed")); err != nil {
+		t.Errorf("expected the warm-up command to run in the spare: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// changelogHeader starts a changelog file flock creates
+const changelogHeader = "# Changelog\n\n"
+
+// ChangelogEntry describes a merged task for the repository's changelog
+type ChangelogEntry struct {
+	TaskID  string
+	Name    string
+	Summary string
+	Branch  string
+	Date    time.Time
+}
+
+// Format renders the entry as a markdown list item
+// Example: "- 2025-03-02 **fix-tests** (`flock-014`, task 014): Make the suite pass"
+func (e ChangelogEntry) Format() string {
+	line := fmt.Sprintf("- %s **%s** (`%s`, task %s)", e.Date.Format("2006-01-02"), e.Name, e.Branch, e.TaskID)
+	if e.Summary != "" {
+		line += ": " + e.Summary
+	}
+	return line + "\n"
+}
+
+// CommitChangelog appends an entry to file (relative to repoRoot) right after a merge.
+// The entry is folded into the merge commit when HEAD is one; a fast-forward gets a
+// commit of its own. Returns the new HEAD. A file with uncommitted changes is left alone.
+func CommitChangelog(repoRoot, file string, entry ChangelogEntry) (string, error) {
+	output, err := gitCommand("-C", repoRoot, "status", "--porcelain", "--", file).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s: %w", file, err)
+	}
+	if strings.TrimSpace(string(output)) != "" {
+		return "", fmt.Errorf("%s has uncommitted changes", file)
+	}
+
+	path := filepath.Join(repoRoot, file)
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	text := string(content)
+	if text == "" {
+		text = changelogHeader
+	} else if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(path, []byte(text+entry.Format()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", file, err)
+	}
+
+	if output, err := gitCommand("-C", repoRoot, "add", "--", file).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to stage %s: %s", file, firstLine(string(output), err))
+	}
+	args := []string{"-C", repoRoot, "commit", "-q", "-m", fmt.Sprintf("Add changelog entry for %s", entry.Name)}
+	if isMergeCommit(repoRoot, "HEAD") {
+		args = []string{"-C", repoRoot, "commit", "-q", "--amend", "--no-edit"}
+	}
+	if output, err := gitCommand(args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to commit %s: %s", file, firstLine(string(output), err))
+	}
+	return RevParse(repoRoot, "HEAD")
+}
+
+// isMergeCommit reports whether rev has more than one parent
+func isMergeCommit(repoRoot, rev string) bool {
+	_, err := gitCommand("-C", repoRoot, "rev-parse", "--verify", "-q", rev+"^2").Output()
+	return err == nil
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/gittest"
+)
+
+func TestCommitChangelog(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	gittest.Run(t, repo, "checkout", "-q", "-b", "flock-001")
+	gittest.CommitFile(t, repo, "b.txt", "two\n")
+	gittest.Run(t, repo, "checkout", "-q", "main")
+	gittest.CommitFile(t, repo, "c.txt", "three\n")
+	gittest.Run(t, repo, "merge", "-q", "--no-edit", "flock-001")
+	merge, _ := RevParse(repo, "HEAD")
+
+	entry := ChangelogEntry{TaskID: "001", Name: "add-b", Summary: "Add b.txt", Branch: "flock-001", Date: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)}
+	head, err := CommitChangelog(repo, "docs/CHANGELOG.md", entry)
+	if err != nil {
+		t.Fatalf("CommitChangelog failed: %v", err)
+	}
+	if head == merge || !isMergeCommit(repo, head) {
+		t.Errorf("expected the entry to be folded into an amended merge commit")
+	}
+	content, _ := os.ReadFile(filepath.Join(repo, "docs", "CHANGELOG.md"))
+	expected := "# Changelog\n\n- 2025-03-02 **add-b** (`flock-001`, task 001): Add b.txt\n"
+	if string(content) != expected {
+		t.Errorf("expected changelog %q, got %q", expected, content)
+	}
+
+	// A file with local edits is not touched
+	os.WriteFile(filepath.Join(repo, "docs", "CHANGELOG.md"), []byte("edited\n"), 0644)
+	if _, err := CommitChangelog(repo, "docs/CHANGELOG.md", entry); err == nil || !strings.Contains(err.Error(), "uncommitted") {
+		t.Errorf("expected uncommitted changes to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// RenderBranchDiff renders what merging branch would bring into the default branch with an
+// external diff tool, colored for the terminal. external is run by git for each changed
+// file (GIT_EXTERNAL_DIFF), as difftastic expects; otherwise the unified diff is piped
+// through pager, as delta and diff-so-fancy expect. Both see the width in $COLUMNS.
+func RenderBranchDiff(repoRoot, branch, pager, external string, width int) (string, error) {
+	columns := strconv.Itoa(width)
+	if external = strings.TrimSpace(external); external != "" {
+		defaultBranch, err := GetDefaultBranch(repoRoot)
+		if err != nil {
+			return "", err
+		}
+		// Passed as arguments so nothing needs quoting; difftastic reads DFT_WIDTH and DFT_COLOR
+		script := `export COLUMNS="$1" DFT_WIDTH="$1" DFT_COLOR=always GIT_EXTERNAL_DIFF="$2"; exec git diff --ext-diff "$3"`
+		cmd := runner.Command("sh", "-c", script, "sh", columns, external, defaultBranch+"..."+branch).In(repoRoot)
+		output, err := commands.CombinedOutput(context.Background(), cmd)
+		if err != nil {
+			return "", fmt.Errorf("%s failed: %s", external, strings.TrimSpace(string(output)))
+		}
+		return string(output), nil
+	}
+
+	diff, err := BranchDiff(repoRoot, branch)
+	if err != nil {
+		return "", err
+	}
+	cmd := runner.Command("sh", "-c", "export COLUMNS="+columns+"; "+pager).In(repoRoot).WithInput(diff)
+	output, err := commands.CombinedOutput(context.Background(), cmd)
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %s", pager, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
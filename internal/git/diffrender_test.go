@@ -0,0 +1,38 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/gittest"
+)
+
+func TestRenderBranchDiff(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	gittest.Run(t, repo, "checkout", "-q", "-b", "flock-001")
+	gittest.CommitFile(t, repo, "b.txt", "new\n")
+	gittest.Run(t, repo, "checkout", "-q", "main")
+
+	// A pager gets the unified diff on stdin
+	output, err := RenderBranchDiff(repo, "flock-001", `sed "s/^/$COLUMNS|/"`, "", 80)
+	if err != nil {
+		t.Fatalf("RenderBranchDiff with a pager failed: %v", err)
+	}
+	if !strings.Contains(output, "80|+new") {
+		t.Errorf("expected the piped diff with the width, got:\n%s", output)
+	}
+
+	// An external diff is run by git with the changed file's path
+	output, err = RenderBranchDiff(repo, "flock-001", "cat", `echo "$DFT_WIDTH"`, 80)
+	if err != nil {
+		t.Fatalf("RenderBranchDiff with an external diff failed: %v", err)
+	}
+	if !strings.HasPrefix(output, "80 b.txt") {
+		t.Errorf("expected the external diff to run for b.txt, got:\n%s", output)
+	}
+
+	if _, err := RenderBranchDiff(repo, "flock-001", "no-such-renderer", "", 80); err == nil {
+		t.Errorf("expected a missing renderer to fail")
+	}
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// fetchTimeout is how long fetching the default branch may take before the local one is used
+const fetchTimeout = 30 * time.Second
+
+// Base is where new task branches start
+type Base struct {
+	Ref     string // The default branch, or origin/<default> after a fetch
+	Default string // The local default branch
+	Behind  int    // Commits origin/<default> has that the local default branch lacks
+}
+
+// ResolveBase finds where new branches in a repo start. With fetch, the default branch
+// is fetched from origin and branches start from origin/<default>; otherwise they start
+// from the local default branch, and Behind says how stale it was at the last fetch.
+// A failed fetch falls back to the local branch and is returned alongside it.
+func ResolveBase(repoRoot string, fetch bool) (Base, error) {
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return Base{}, err
+	}
+	base := Base{Ref: defaultBranch, Default: defaultBranch}
+	remote := "origin/" + defaultBranch
+
+	var fetchErr error
+	if fetch {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+		output, err := commands.CombinedOutput(ctx, runner.Command("git", "-C", repoRoot, "fetch", "--quiet", "origin", defaultBranch))
+		if err != nil {
+			fetchErr = fmt.Errorf("failed to fetch origin/%s: %s", defaultBranch, firstLine(string(output), err))
+		}
+	}
+
+	if gitCommand("-C", repoRoot, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remote).Run() != nil {
+		// No remote to compare with
+		return base, fetchErr
+	}
+	if output, err := gitCommand("-C", repoRoot, "rev-list", "--count", defaultBranch+".."+remote).Output(); err == nil {
+		base.Behind, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+	}
+	if fetch && fetchErr == nil {
+		base.Ref = remote
+	}
+	return base, fetchErr
+}
@@ -0,0 +1,41 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/dfowler/flock/internal/gittest"
+)
+
+func TestResolveBase(t *testing.T) {
+	origin := gittest.NewRepo(t)
+	gittest.CommitFile(t, origin, "a.txt", "one\n")
+	repo := t.TempDir()
+	gittest.Run(t, repo, "clone", "-q", origin, ".")
+	gittest.CommitFile(t, origin, "a.txt", "two\n")
+
+	tests := []struct {
+		fetch  bool
+		ref    string
+		behind int
+	}{
+		{false, "main", 0}, // The new commit hasn't been fetched yet
+		{true, "origin/main", 1},
+		{false, "main", 1}, // Now the last fetch shows local main is behind
+	}
+	for i, tt := range tests {
+		base, err := ResolveBase(repo, tt.fetch)
+		if err != nil {
+			t.Fatalf("ResolveBase failed: %v", err)
+		}
+		if base.Ref != tt.ref || base.Behind != tt.behind {
+			t.Errorf("%d: expected %s, %d behind, got %+v", i, tt.ref, tt.behind, base)
+		}
+	}
+
+	// Without a reachable origin the local branch is used, and the fetch failure returned
+	gittest.Run(t, repo, "remote", "set-url", "origin", t.TempDir()+"/missing")
+	base, err := ResolveBase(repo, true)
+	if err == nil || base.Ref != "main" {
+		t.Errorf("expected a fetch error and local main, got %+v, %v", base, err)
+	}
+}
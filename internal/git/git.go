@@ -2,13 +2,29 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/dfowler/flock/internal/runner"
 )
 
+// commands runs every git command in the package; tests replace it with SetRunner
+var commands runner.Runner = runner.Exec{}
+
+// SetRunner replaces the runner used for git commands and returns the previous one
+func SetRunner(r runner.Runner) runner.Runner {
+	prev := commands
+	commands = r
+	return prev
+}
+
+// gitCommand returns a git command that runs through the package's runner
+func gitCommand(args ...string) *runner.Proc {
+	return runner.Bind(commands, "git", args...)
+}
+
 // Cache for git status results
 var (
 	statusCache     = make(map[string]cachedStatus)
@@ -92,7 +108,7 @@ func fetchBranchStatus(dir string) BranchStatus {
 
 // getCurrentBranch returns the current branch name
 func getCurrentBranch(dir string) (string, error) {
-	cmd := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD")
+	cmd := gitCommand("-C", dir, "rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("not a git repo")
@@ -103,13 +119,13 @@ func getCurrentBranch(dir string) (string, error) {
 // getMainBranch determines if the repo uses "main" or "master" as the primary branch
 func getMainBranch(dir string) string {
 	// Check if 'main' branch exists
-	cmd := exec.Command("git", "-C", dir, "rev-parse", "--verify", "main")
+	cmd := gitCommand("-C", dir, "rev-parse", "--verify", "main")
 	if err := cmd.Run(); err == nil {
 		return "main"
 	}
 
 	// Check if 'master' branch exists
-	cmd = exec.Command("git", "-C", dir, "rev-parse", "--verify", "master")
+	cmd = gitCommand("-C", dir, "rev-parse", "--verify", "master")
 	if err := cmd.Run(); err == nil {
 		return "master"
 	}
@@ -122,7 +138,7 @@ func getAheadBehind(dir, baseBranch, currentBranch string) (ahead, behind int, e
 	// Use git rev-list to count commits
 	// Ahead: commits in current branch not in base
 	// Behind: commits in base not in current branch
-	cmd := exec.Command("git", "-C", dir, "rev-list", "--left-right", "--count", baseBranch+"..."+currentBranch)
+	cmd := gitCommand("-C", dir, "rev-list", "--left-right", "--count", baseBranch+"..."+currentBranch)
 	output, err := cmd.Output()
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to get commit counts")
@@ -0,0 +1,120 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// integrationDir is the detached worktree isolated merges are made in. Its name lacks the
+// flock- prefix, so it is never taken for a task's worktree.
+func integrationDir(repoRoot string) string {
+	return filepath.Join(WorktreeDirPath(repoRoot), "integration")
+}
+
+// MergeBranchIsolated merges like MergeBranch, but never checks anything out in the main
+// checkout: the merge is made in a detached integration worktree and the default branch
+// is then moved to it. If the main checkout has the default branch checked out, it is
+// fast-forwarded, which keeps uncommitted changes; when they are in the way the merge
+// fails instead and nothing changes. A changelog entry can be committed in the result's
+// Dir before publishing that with PublishIntegration.
+func MergeBranchIsolated(repoRoot, branch string, strategy MergeStrategy, message string, trailers []string) (*MergeResult, error) {
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default branch: %w", err)
+	}
+	dir, err := prepareIntegration(repoRoot, defaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	if strategy == StrategyRebase {
+		result, err := rebaseOntoDefault(repoRoot, dir, branch, defaultBranch, trailers)
+		if err != nil || result != nil {
+			return result, err
+		}
+		// A rebase made here leaves the branch checked out; go back to the default branch
+		if output, err := gitCommand("-C", dir, "checkout", "-q", "--detach", defaultBranch).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to reset the integration worktree: %s", firstLine(string(output), err))
+		}
+	}
+
+	result, err := mergeInto(dir, branch, defaultBranch, strategy, message, trailers)
+	if err != nil || !result.Success {
+		return result, err
+	}
+	if err := PublishIntegration(repoRoot, dir, result.PreMergeHead); err != nil {
+		return &MergeResult{Success: false, Message: err.Error()}, nil
+	}
+	result.Dir = dir
+	return result, nil
+}
+
+// prepareIntegration creates the integration worktree, or cleans up the one left from the
+// last merge, detached at the default branch
+func prepareIntegration(repoRoot, defaultBranch string) (string, error) {
+	dir := integrationDir(repoRoot)
+	if _, err := os.Stat(dir); err == nil {
+		gitCommand("-C", dir, "merge", "--abort").Run()
+		gitCommand("-C", dir, "rebase", "--abort").Run()
+		if output, err := gitCommand("-C", dir, "checkout", "-q", "-f", "--detach", defaultBranch).CombinedOutput(); err == nil {
+			gitCommand("-C", dir, "clean", "-fdq").Run()
+			return dir, nil
+		} else if !strings.Contains(string(output), "not a git repository") {
+			return "", fmt.Errorf("failed to reset the integration worktree: %s", firstLine(string(output), err))
+		}
+		// Left over after the repo forgot it; make it again
+		if err := os.RemoveAll(dir); err != nil {
+			return "", fmt.Errorf("failed to remove stale integration worktree: %w", err)
+		}
+		gitCommand("-C", repoRoot, "worktree", "prune").Run()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	if output, err := gitCommand("-C", repoRoot, "worktree", "add", "-q", "--detach", dir, defaultBranch).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to create the integration worktree: %s", firstLine(string(output), err))
+	}
+	return dir, nil
+}
+
+// PublishIntegration moves the default branch from expected to the integration worktree's
+// HEAD. A checkout of the default branch is moved along with it (git reset --keep), which
+// keeps uncommitted changes; when they are in the way, the default branch stays put. So
+// does a default branch that moved away from expected, so no commit is ever dropped.
+func PublishIntegration(repoRoot, dir, expected string) error {
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to get default branch: %w", err)
+	}
+	head, err := RevParse(dir, "HEAD")
+	if err != nil {
+		return err
+	}
+	if current, err := RevParse(repoRoot, "refs/heads/"+defaultBranch); err != nil {
+		return err
+	} else if current != expected {
+		return fmt.Errorf("%s moved while merging; merge again", defaultBranch)
+	}
+
+	worktrees, err := ListWorktrees(repoRoot)
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == defaultBranch {
+			if output, err := gitCommand("-C", wt.Path, "reset", "-q", "--keep", head).CombinedOutput(); err != nil {
+				return fmt.Errorf("%s is checked out in %s with changes in the way: %s", defaultBranch, wt.Path, firstLine(string(output), err))
+			}
+			return nil
+		}
+	}
+
+	// Passing the expected value makes git refuse if the branch moved in the meantime
+	if output, err := gitCommand("-C", repoRoot, "update-ref", "refs/heads/"+defaultBranch, head, expected).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update %s: %s", defaultBranch, firstLine(string(output), err))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/gittest"
+)
+
+func TestMergeBranchIsolated(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	for _, branch := range []string{"flock-001", "flock-002", "flock-003"} {
+		gittest.Run(t, repo, "checkout", "-q", "-b", branch, "main")
+		gittest.CommitFile(t, repo, branch+".txt", branch+"\n")
+	}
+
+	// The main checkout is on a branch of its own, with uncommitted work
+	gittest.Run(t, repo, "checkout", "-q", "-b", "mine", "main")
+	dirty := filepath.Join(repo, "a.txt")
+	os.WriteFile(dirty, []byte("half done\n"), 0644)
+
+	for _, tt := range []struct {
+		branch   string
+		strategy MergeStrategy
+	}{
+		{"flock-001", StrategyMerge},
+		{"flock-002", StrategyRebase},
+	} {
+		result, err := MergeBranchIsolated(repo, tt.branch, tt.strategy, "", []string{"Flock-Task: " + tt.branch})
+		if err != nil || !result.Success {
+			t.Fatalf("%s: expected a successful merge, got %+v, %v", tt.branch, result, err)
+		}
+		if head, _ := RevParse(repo, "main"); head != result.MergeCommit {
+			t.Errorf("%s: expected main at the merge, got %s", tt.branch, head)
+		}
+		if branch, _ := GetCurrentBranch(repo); branch != "mine" {
+			t.Errorf("%s: expected the main checkout to stay on mine, got %s", tt.branch, branch)
+		}
+		if data, _ := os.ReadFile(dirty); string(data) != "half done\n" {
+			t.Errorf("%s: expected uncommitted work to be kept, got %q", tt.branch, data)
+		}
+	}
+
+	// With main checked out, it is fast-forwarded and the uncommitted work stays
+	gittest.Run(t, repo, "stash", "-q")
+	gittest.Run(t, repo, "checkout", "-q", "main")
+	gittest.Run(t, repo, "stash", "pop", "-q")
+	result, err := MergeBranchIsolated(repo, "flock-003", StrategyMerge, "", nil)
+	if err != nil || !result.Success {
+		t.Fatalf("expected a successful merge, got %+v, %v", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "flock-003.txt")); err != nil {
+		t.Errorf("expected the checkout of main to get the merged file")
+	}
+	if data, _ := os.ReadFile(dirty); string(data) != "half done\n" {
+		t.Errorf("expected uncommitted work to be kept, got %q", data)
+	}
+
+	// A changelog entry folded into the published merge commit reaches the checkout too
+	head, err := CommitChangelog(result.Dir, "CHANGELOG.md", ChangelogEntry{TaskID: "003", Name: "three", Branch: "flock-003"})
+	if err != nil {
+		t.Fatalf("CommitChangelog failed: %v", err)
+	}
+	if err := PublishIntegration(repo, result.Dir, result.MergeCommit); err != nil {
+		t.Fatalf("PublishIntegration failed: %v", err)
+	}
+	if current, _ := RevParse(repo, "HEAD"); current != head {
+		t.Errorf("expected main at the changelog commit %s, got %s", head, current)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "CHANGELOG.md")); err != nil {
+		t.Errorf("expected the checkout of main to get the changelog")
+	}
+	if output, _ := gitCommand("-C", repo, "status", "--porcelain", "--untracked-files=no").Output(); strings.TrimSpace(string(output)) != "M a.txt" {
+		t.Errorf("expected only the uncommitted change, got %q", output)
+	}
+	if err := PublishIntegration(repo, result.Dir, result.MergeCommit); err == nil {
+		t.Errorf("expected publishing over a branch that moved to fail")
+	}
+}
+
+func TestUndoMergeIsolated(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	for _, branch := range []string{"flock-001", "flock-002"} {
+		gittest.Run(t, repo, "checkout", "-q", "-b", branch, "main")
+		gittest.CommitFile(t, repo, branch+".txt", branch+"\n")
+	}
+
+	// The main checkout is on a branch of its own, with uncommitted work
+	gittest.Run(t, repo, "checkout", "-q", "-b", "mine", "main")
+	dirty := filepath.Join(repo, "a.txt")
+	os.WriteFile(dirty, []byte("half done\n"), 0644)
+
+	tests := []struct {
+		name      string
+		laterWork bool // Merge flock-002 after flock-001
+		method    UndoMethod
+	}{
+		{"merge is the tip", false, UndoReset},
+		{"work landed after the merge", true, UndoRevert},
+	}
+	for _, tt := range tests {
+		result, err := MergeBranchIsolated(repo, "flock-001", StrategyMerge, "", nil)
+		if err != nil || !result.Success {
+			t.Fatalf("%s: expected a successful merge, got %+v, %v", tt.name, result, err)
+		}
+		if tt.laterWork {
+			if later, err := MergeBranchIsolated(repo, "flock-002", StrategyMerge, "", nil); err != nil || !later.Success {
+				t.Fatalf("%s: expected a successful merge, got %+v, %v", tt.name, later, err)
+			}
+		}
+
+		method, err := PlanUndo(repo, result.PreMergeHead, result.MergeCommit)
+		if err != nil || method != tt.method {
+			t.Fatalf("%s: expected to %s, got %q, %v", tt.name, tt.method, method, err)
+		}
+		if _, err := UndoMergeIsolated(repo, "flock-001", result.PreMergeHead, result.MergeCommit, method); err != nil {
+			t.Fatalf("%s: undo failed: %v", tt.name, err)
+		}
+		if err := gitCommand("-C", repo, "cat-file", "-e", "main:flock-001.txt").Run(); err == nil {
+			t.Errorf("%s: expected the merged file to be gone from main", tt.name)
+		}
+		if err := gitCommand("-C", repo, "cat-file", "-e", "main:flock-002.txt").Run(); tt.laterWork && err != nil {
+			t.Errorf("%s: expected later work to be kept", tt.name)
+		}
+		if head, _ := RevParse(repo, "main"); tt.method == UndoReset && head != result.PreMergeHead {
+			t.Errorf("%s: expected main back at %s, got %s", tt.name, result.PreMergeHead, head)
+		}
+		if branch, _ := GetCurrentBranch(repo); branch != "mine" {
+			t.Errorf("%s: expected the main checkout to stay on mine, got %s", tt.name, branch)
+		}
+		if data, _ := os.ReadFile(dirty); string(data) != "half done\n" {
+			t.Errorf("%s: expected uncommitted work to be kept, got %q", tt.name, data)
+		}
+	}
+}
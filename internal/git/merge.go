@@ -0,0 +1,243 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// MergeStrategy controls how a task branch lands on the default branch
+type MergeStrategy string
+
+const (
+	// StrategyMerge merges the branch with a merge commit (or fast-forward)
+	StrategyMerge MergeStrategy = "merge"
+	// StrategySquash squashes the branch into a single commit on the default branch
+	StrategySquash MergeStrategy = "squash"
+	// StrategyRebase rebases the branch onto the default branch, then fast-forwards
+	StrategyRebase MergeStrategy = "rebase"
+)
+
+// ParseMergeStrategy parses a merge strategy name; empty means merge
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	switch MergeStrategy(s) {
+	case "", StrategyMerge:
+		return StrategyMerge, nil
+	case StrategySquash:
+		return StrategySquash, nil
+	case StrategyRebase:
+		return StrategyRebase, nil
+	default:
+		return "", fmt.Errorf("unknown merge strategy %q (expected merge, squash or rebase)", s)
+	}
+}
+
+// Next returns the strategy after s, cycling merge, squash, rebase
+func (s MergeStrategy) Next() MergeStrategy {
+	switch s {
+	case StrategyMerge:
+		return StrategySquash
+	case StrategySquash:
+		return StrategyRebase
+	default:
+		return StrategyMerge
+	}
+}
+
+// MergeCheck is the outcome of a dry-run merge
+type MergeCheck struct {
+	DefaultBranch string
+	Conflicts     []string // Files that would conflict; empty if the merge is clean
+}
+
+// CheckMerge predicts whether merging branch into the default branch conflicts,
+// without touching the working tree or any refs (needs git 2.38+)
+func CheckMerge(repoRoot, branch string) (*MergeCheck, error) {
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default branch: %w", err)
+	}
+
+	cmd := gitCommand("-C", repoRoot, "merge-tree", "--write-tree", "--name-only", "--no-messages", defaultBranch, branch)
+	output, err := cmd.Output()
+	check := &MergeCheck{DefaultBranch: defaultBranch}
+	if err == nil {
+		return check, nil
+	}
+	// Exit status 1 means the merge has conflicts; anything else is a failure
+	if runner.ExitCode(err) != 1 {
+		return nil, fmt.Errorf("failed to check merge of %s: %w", branch, err)
+	}
+
+	// The first line is the resulting tree, followed by the conflicted files
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	seen := make(map[string]bool)
+	for _, file := range lines[1:] {
+		if file != "" && !seen[file] {
+			seen[file] = true
+			check.Conflicts = append(check.Conflicts, file)
+		}
+	}
+	return check, nil
+}
+
+// PrepareConflicts starts merging (or rebasing onto) the default branch inside a task's
+// worktree and leaves the conflicts in place for someone to resolve. It returns the
+// conflicted files; none means the branch took in the default branch cleanly.
+func PrepareConflicts(worktreePath, defaultBranch string, strategy MergeStrategy) ([]string, error) {
+	args := []string{"-C", worktreePath, "merge", defaultBranch, "--no-edit"}
+	if strategy == StrategyRebase {
+		args = []string{"-C", worktreePath, "rebase", defaultBranch}
+	}
+	output, err := gitCommand(args...).CombinedOutput()
+	if err == nil {
+		return nil, nil
+	}
+
+	files, listErr := conflictedFiles(worktreePath)
+	if listErr != nil || len(files) == 0 {
+		return nil, fmt.Errorf("failed to %s %s: %s", args[2], defaultBranch, strings.TrimSpace(string(output)))
+	}
+	return files, nil
+}
+
+// conflictedFiles lists the unmerged files in a working tree
+func conflictedFiles(dir string) ([]string, error) {
+	output, err := gitCommand("-C", dir, "diff", "--name-only", "--diff-filter=U").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicts: %w", err)
+	}
+	var files []string
+	for _, file := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// rebaseOntoDefault rebases branch onto the default branch, in the branch's worktree
+// if it has one and otherwise in fallbackDir, adding trailers to each commit. A conflicting rebase is aborted and
+// reported in the result.
+func rebaseOntoDefault(repoRoot, fallbackDir, branch, defaultBranch string, trailers []string) (*MergeResult, error) {
+	worktrees, err := ListWorktrees(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	dir := ""
+	for _, wt := range worktrees {
+		if wt.Branch == branch {
+			dir = wt.Path
+			break
+		}
+	}
+
+	var cmd *runner.Proc
+	args := []string{"rebase"}
+	if len(trailers) > 0 {
+		// Rewrite every commit, even when the branch is already on top of the default branch
+		exec := "git commit --amend --no-edit --quiet " + strings.Join(shellQuoteAll(trailerArgs(trailers)), " ")
+		args = append(args, "--force-rebase", "--exec", exec)
+	}
+	if dir != "" {
+		cmd = gitCommand(append([]string{"-C", dir}, append(args, defaultBranch)...)...)
+	} else {
+		dir = fallbackDir
+		cmd = gitCommand(append([]string{"-C", dir}, append(args, defaultBranch, branch)...)...)
+	}
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil, nil
+	}
+
+	outputStr := strings.TrimSpace(string(output))
+	conflicts, _ := conflictedFiles(dir)
+	gitCommand("-C", dir, "rebase", "--abort").Run()
+	if len(conflicts) > 0 || strings.Contains(outputStr, "CONFLICT") {
+		return &MergeResult{
+			Success:      false,
+			HasConflicts: true,
+			Conflicts:    conflicts,
+			Message:      fmt.Sprintf("Rebasing %s onto %s conflicts; the rebase was aborted", branch, defaultBranch),
+		}, nil
+	}
+	return &MergeResult{
+		Success: false,
+		Message: fmt.Sprintf("Rebase failed: %s", outputStr),
+	}, nil
+}
+
+// squashInto squashes branch into a single commit on whatever dir has checked out, which
+// is the default branch. A conflicting squash is undone and reported in the result.
+func squashInto(dir, branch, defaultBranch, message string, trailers []string) (*MergeResult, error) {
+	preMergeHead, _ := RevParse(dir, "HEAD")
+
+	output, err := gitCommand("-C", dir, "merge", "--squash", branch).CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+	if err != nil {
+		conflicts, _ := conflictedFiles(dir)
+		// A squash leaves no MERGE_HEAD, so merge --abort can't undo it
+		gitCommand("-C", dir, "reset", "-q", "--merge").Run()
+		if len(conflicts) > 0 || strings.Contains(outputStr, "CONFLICT") {
+			return &MergeResult{
+				Success:      false,
+				HasConflicts: true,
+				Conflicts:    conflicts,
+				Message:      fmt.Sprintf("Squashing %s into %s conflicts; the merge was aborted", branch, defaultBranch),
+			}, nil
+		}
+		return &MergeResult{
+			Success: false,
+			Message: fmt.Sprintf("Squash failed: %s", outputStr),
+		}, nil
+	}
+
+	// Nothing staged means the branch has nothing the default branch lacks
+	if gitCommand("-C", dir, "diff", "--cached", "--quiet").Run() == nil {
+		return &MergeResult{
+			Success:      true,
+			Message:      fmt.Sprintf("%s has nothing to squash into %s", branch, defaultBranch),
+			PreMergeHead: preMergeHead,
+			MergeCommit:  preMergeHead,
+		}, nil
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Squash branch '%s'", branch)
+	}
+	args := append([]string{"-C", dir, "commit", "-q", "-m", message}, trailerArgs(trailers)...)
+	if output, err := gitCommand(args...).CombinedOutput(); err != nil {
+		gitCommand("-C", dir, "reset", "-q", "--merge").Run()
+		return &MergeResult{
+			Success: false,
+			Message: fmt.Sprintf("Squash failed: %s", firstLine(string(output), err)),
+		}, nil
+	}
+
+	mergeCommit, _ := RevParse(dir, "HEAD")
+	return &MergeResult{
+		Success:      true,
+		Message:      fmt.Sprintf("Squashed %s into %s", branch, defaultBranch),
+		PreMergeHead: preMergeHead,
+		MergeCommit:  mergeCommit,
+	}, nil
+}
+
+// trailerArgs turns "Key: value" trailers into --trailer flags for git commit
+func trailerArgs(trailers []string) []string {
+	var args []string
+	for _, trailer := range trailers {
+		args = append(args, "--trailer", trailer)
+	}
+	return args
+}
+
+// shellQuoteAll single-quotes each argument for sh
+func shellQuoteAll(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return quoted
+}
@@ -0,0 +1,194 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/gittest"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+func TestMergeConflicts(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+
+	// A branch in its own worktree that conflicts with main, and one that doesn't
+	wt := filepath.Join(t.TempDir(), "wt")
+	gittest.Run(t, repo, "worktree", "add", "-q", "-b", "flock-001", wt)
+	gittest.CommitFile(t, wt, "a.txt", "two\n")
+	gittest.Run(t, repo, "branch", "flock-002")
+	gittest.CommitFile(t, repo, "a.txt", "three\n")
+
+	check, err := CheckMerge(repo, "flock-001")
+	if err != nil {
+		t.Skipf("merge-tree unavailable: %v", err)
+	}
+	if len(check.Conflicts) != 1 || check.Conflicts[0] != "a.txt" {
+		t.Errorf("expected a.txt to conflict, got %v", check.Conflicts)
+	}
+	if check, _ := CheckMerge(repo, "flock-002"); len(check.Conflicts) != 0 {
+		t.Errorf("expected flock-002 to merge cleanly, got %v", check.Conflicts)
+	}
+
+	result, err := MergeBranch(repo, "flock-001", StrategyRebase, "", nil)
+	if err != nil {
+		t.Fatalf("MergeBranch failed: %v", err)
+	}
+	if result.Success || !result.HasConflicts {
+		t.Errorf("expected the rebase to conflict, got %+v", result)
+	}
+	if files, _ := conflictedFiles(wt); len(files) != 0 {
+		t.Errorf("expected the conflicting rebase to be aborted, got %v", files)
+	}
+
+	files, err := PrepareConflicts(wt, "main", StrategyMerge)
+	if err != nil {
+		t.Fatalf("PrepareConflicts failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != "a.txt" {
+		t.Errorf("expected a.txt left conflicted in the worktree, got %v", files)
+	}
+
+	result, err = MergeBranch(repo, "flock-002", StrategyRebase, "", nil)
+	if err != nil || !result.Success {
+		t.Errorf("expected flock-002 to rebase and fast-forward, got %+v, %v", result, err)
+	}
+}
+
+func TestMergeBranchAbortsConflicts(t *testing.T) {
+	fake := runner.NewFake()
+	defer SetRunner(SetRunner(fake))
+	fake.On("git -C /repo symbolic-ref", runner.Response{Output: "refs/remotes/origin/main\n"})
+	fake.On("git -C /repo merge flock-001", runner.Response{Output: "CONFLICT (content): Merge conflict in a.txt\n", ExitCode: 1})
+	fake.On("git -C /repo diff --name-only --diff-filter=U", runner.Response{Output: "a.txt\n"})
+
+	result, err := MergeBranch("/repo", "flock-001", StrategyMerge, "", nil)
+	if err != nil {
+		t.Fatalf("MergeBranch failed: %v", err)
+	}
+	if result.Success || !result.HasConflicts || len(result.Conflicts) != 1 {
+		t.Errorf("expected a conflicted merge on a.txt, got %+v", result)
+	}
+	if !fake.Ran("git -C /repo merge --abort") {
+		t.Errorf("expected the merge to be aborted, got:\n%s", strings.Join(fake.Commands(), "\n"))
+	}
+}
+
+func TestMergeTrailers(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	for _, branch := range []string{"flock-001", "flock-002", "flock-003"} {
+		gittest.Run(t, repo, "checkout", "-q", "-b", branch, "main")
+		gittest.CommitFile(t, repo, branch+".txt", branch+"\n")
+	}
+	gittest.Run(t, repo, "checkout", "-q", "main")
+
+	tests := []struct {
+		branch   string
+		strategy MergeStrategy
+		trailer  string
+	}{
+		// flock-001 could fast-forward, but gets a merge commit to carry the trailer
+		{"flock-001", StrategyMerge, "Flock-Task: 001"},
+		{"flock-002", StrategyRebase, "Flock-Task: 002"},
+		{"flock-003", StrategySquash, "Flock-Task: 003"},
+	}
+	for _, tt := range tests {
+		result, err := MergeBranch(repo, tt.branch, tt.strategy, "", []string{tt.trailer, "Flock-Prompt: abc123"})
+		if err != nil || !result.Success {
+			t.Fatalf("%s: expected a successful merge, got %+v, %v", tt.branch, result, err)
+		}
+		commits, err := Log(repo, result.PreMergeHead, result.MergeCommit)
+		if err != nil || len(commits) == 0 {
+			t.Fatalf("%s: expected merged commits, got %v", tt.branch, err)
+		}
+		if !strings.Contains(commits[0].Body, tt.trailer+"\nFlock-Prompt: abc123") {
+			t.Errorf("%s: expected the trailers on %q, got body %q", tt.branch, commits[0].Subject, commits[0].Body)
+		}
+	}
+}
+
+func TestSquashMerge(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	gittest.Run(t, repo, "checkout", "-q", "-b", "flock-001")
+	gittest.CommitFile(t, repo, "b.txt", "agent\n")
+	gittest.CommitFile(t, repo, "c.txt", "more\n")
+	gittest.Run(t, repo, "checkout", "-q", "-b", "flock-002", "main")
+	gittest.CommitFile(t, repo, "a.txt", "two\n")
+	gittest.Run(t, repo, "checkout", "-q", "main")
+	gittest.CommitFile(t, repo, "a.txt", "three\n")
+
+	message := "fix-login (flock-001)\n\nFix the redirect"
+	result, err := MergeBranch(repo, "flock-001", StrategySquash, message, nil)
+	if err != nil || !result.Success {
+		t.Fatalf("expected a successful squash, got %+v, %v", result, err)
+	}
+	commits, err := Log(repo, result.PreMergeHead, result.MergeCommit)
+	if err != nil || len(commits) != 1 {
+		t.Fatalf("expected one squashed commit, got %v, %v", commits, err)
+	}
+	if commits[0].Subject != "fix-login (flock-001)" || commits[0].Body != "Fix the redirect" {
+		t.Errorf("expected the given message, got %q / %q", commits[0].Subject, commits[0].Body)
+	}
+	if isMergeCommit(repo, "HEAD") {
+		t.Errorf("expected a squash to leave a single-parent commit")
+	}
+
+	// A conflicting squash leaves main as it was
+	result, err = MergeBranch(repo, "flock-002", StrategySquash, "", nil)
+	if err != nil || result.Success || !result.HasConflicts {
+		t.Fatalf("expected the squash to conflict, got %+v, %v", result, err)
+	}
+	output, _ := exec.Command("git", "-C", repo, "status", "--porcelain").Output()
+	if len(output) != 0 {
+		t.Errorf("expected a clean checkout after the aborted squash, got %q", output)
+	}
+}
+
+func TestUndoMerge(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	gittest.Run(t, repo, "checkout", "-q", "-b", "flock-001")
+	gittest.CommitFile(t, repo, "b.txt", "agent\n")
+	gittest.Run(t, repo, "checkout", "-q", "main")
+
+	tests := []struct {
+		name      string
+		laterWork bool // Commit on main after the merge
+		method    UndoMethod
+	}{
+		{"merge is the tip", false, UndoReset},
+		{"work landed after the merge", true, UndoRevert},
+	}
+	for _, tt := range tests {
+		result, err := MergeBranch(repo, "flock-001", StrategyMerge, "", []string{"Flock-Task: 001"})
+		if err != nil || !result.Success {
+			t.Fatalf("%s: expected a successful merge, got %+v, %v", tt.name, result, err)
+		}
+		if tt.laterWork {
+			gittest.CommitFile(t, repo, "c.txt", "later\n")
+		}
+
+		method, err := PlanUndo(repo, result.PreMergeHead, result.MergeCommit)
+		if err != nil || method != tt.method {
+			t.Fatalf("%s: expected to %s, got %q, %v", tt.name, tt.method, method, err)
+		}
+		if _, err := UndoMerge(repo, "flock-001", result.PreMergeHead, result.MergeCommit, method); err != nil {
+			t.Fatalf("%s: undo failed: %v", tt.name, err)
+		}
+		if _, err := os.Stat(filepath.Join(repo, "b.txt")); !os.IsNotExist(err) {
+			t.Errorf("%s: expected the merged file to be gone", tt.name)
+		}
+		if _, err := os.Stat(filepath.Join(repo, "c.txt")); tt.laterWork && err != nil {
+			t.Errorf("%s: expected later work to be kept", tt.name)
+		}
+		head, _ := RevParse(repo, "HEAD")
+		if tt.method == UndoReset && head != result.PreMergeHead {
+			t.Errorf("%s: expected main back at %s, got %s", tt.name, result.PreMergeHead, head)
+		}
+	}
+}
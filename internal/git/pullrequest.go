@@ -0,0 +1,188 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// githubAPI is the GitHub REST API used when the gh CLI is not installed
+var githubAPI = "https://api.github.com"
+
+// lookPath finds CLI tools; tests replace it to pick a code path
+var lookPath = exec.LookPath
+
+// PullRequest describes a pull request (or GitLab merge request) for a task branch
+type PullRequest struct {
+	Branch string
+	Base   string // Target branch (the default branch if empty)
+	Title  string
+	Body   string
+}
+
+// PushBranch pushes a branch to origin and sets it as the branch's upstream
+func PushBranch(repoRoot, branch string) error {
+	output, err := gitCommand("-C", repoRoot, "push", "-u", "origin", branch).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to push %s: %s", branch, firstLine(string(output), err))
+	}
+	return nil
+}
+
+// RemoteURL returns the URL of the origin remote
+func RemoteURL(repoRoot string) (string, error) {
+	output, err := gitCommand("-C", repoRoot, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return "", fmt.Errorf("repository has no origin remote")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// OpenPullRequest opens a pull request for an already pushed branch and returns its URL.
+// GitLab remotes use glab; anything else uses gh, or the GitHub API with $GITHUB_TOKEN
+// when gh is not installed. An existing pull request for the branch is returned as is.
+func OpenPullRequest(repoRoot string, pr PullRequest) (string, error) {
+	remote, err := RemoteURL(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	if pr.Base == "" {
+		if pr.Base, err = GetDefaultBranch(repoRoot); err != nil {
+			return "", err
+		}
+	}
+
+	if strings.Contains(remote, "gitlab") {
+		if _, err := lookPath("glab"); err != nil {
+			return "", fmt.Errorf("glab is required to open merge requests on GitLab")
+		}
+		return runPRTool(repoRoot, "glab", "mr", "create", "--yes",
+			"--source-branch", pr.Branch, "--target-branch", pr.Base,
+			"--title", pr.Title, "--description", pr.Body)
+	}
+
+	if _, err := lookPath("gh"); err == nil {
+		return runPRTool(repoRoot, "gh", "pr", "create",
+			"--head", pr.Branch, "--base", pr.Base,
+			"--title", pr.Title, "--body", pr.Body)
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	owner, repo, ok := parseGitHubRemote(remote)
+	if !ok || token == "" {
+		return "", fmt.Errorf("install gh (or glab), or set GITHUB_TOKEN, to open pull requests")
+	}
+	return createGitHubPR(owner, repo, token, pr)
+}
+
+// runPRTool runs gh or glab and returns the pull request URL it prints.
+// Both tools fail when a pull request already exists, but still print its URL.
+func runPRTool(repoRoot, name string, args ...string) (string, error) {
+	cmd := runner.Command(name, args...).In(repoRoot)
+	output, err := commands.CombinedOutput(context.Background(), cmd)
+	url := lastURL(string(output))
+	if err != nil && (url == "" || !strings.Contains(string(output), "already exists")) {
+		return "", fmt.Errorf("%s failed: %s", name, firstLine(string(output), err))
+	}
+	if url == "" {
+		return "", fmt.Errorf("%s did not report a pull request URL", name)
+	}
+	return url, nil
+}
+
+// createGitHubPR opens a pull request through the GitHub REST API
+func createGitHubPR(owner, repo, token string, pr PullRequest) (string, error) {
+	payload, err := json.Marshal(map[string]string{
+		"title": pr.Title,
+		"head":  pr.Branch,
+		"base":  pr.Base,
+		"body":  pr.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls", githubAPI, owner, repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to open pull request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		HTMLURL string `json:"html_url"`
+		Message string `json:"message"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusCreated {
+		if result.Message == "" {
+			result.Message = resp.Status
+		}
+		return "", fmt.Errorf("failed to open pull request: %s", result.Message)
+	}
+	return result.HTMLURL, nil
+}
+
+// parseGitHubRemote extracts the owner and repository from a github.com remote URL
+// (https://github.com/owner/repo.git or git@github.com:owner/repo.git)
+func parseGitHubRemote(remote string) (owner, repo string, ok bool) {
+	var path string
+	switch {
+	case strings.HasPrefix(remote, "git@github.com:"):
+		path = strings.TrimPrefix(remote, "git@github.com:")
+	case strings.HasPrefix(remote, "ssh://git@github.com/"):
+		path = strings.TrimPrefix(remote, "ssh://git@github.com/")
+	case strings.HasPrefix(remote, "https://github.com/"):
+		path = strings.TrimPrefix(remote, "https://github.com/")
+	default:
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// lastURL returns the last line of output that is a URL
+func lastURL(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); strings.HasPrefix(line, "https://") || strings.HasPrefix(line, "http://") {
+			return line
+		}
+	}
+	return ""
+}
+
+// firstLine returns the first line of a command's output, or err if there was none
+func firstLine(output string, err error) string {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return err.Error()
+	}
+	if idx := strings.Index(output, "\n"); idx != -1 {
+		output = output[:idx]
+	}
+	return output
+}
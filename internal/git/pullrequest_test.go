@@ -0,0 +1,75 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+func TestParseGitHubRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		owner  string
+		repo   string
+		ok     bool
+	}{
+		{"git@github.com:dfowler/flock.git", "dfowler", "flock", true},
+		{"https://github.com/dfowler/flock", "dfowler", "flock", true},
+		{"ssh://git@github.com/dfowler/flock.git", "dfowler", "flock", true},
+		{"https://gitlab.com/dfowler/flock.git", "", "", false},
+		{"https://github.com/dfowler", "", "", false},
+	}
+
+	for _, tt := range tests {
+		owner, repo, ok := parseGitHubRemote(tt.remote)
+		if owner != tt.owner || repo != tt.repo || ok != tt.ok {
+			t.Errorf("parseGitHubRemote(%q) = %q, %q, %v, expected %q, %q, %v", tt.remote, owner, repo, ok, tt.owner, tt.repo, tt.ok)
+		}
+	}
+}
+
+func TestOpenPullRequest(t *testing.T) {
+	fake := runner.NewFake()
+	defer SetRunner(SetRunner(fake))
+	defer func(orig func(string) (string, error)) { lookPath = orig }(lookPath)
+	fake.On("git -C /repo symbolic-ref", runner.Response{Output: "refs/remotes/origin/main\n"})
+	fake.On("git -C /repo remote get-url origin", runner.Response{Output: "git@github.com:dfowler/flock.git\n"})
+	pr := PullRequest{Branch: "flock-001", Title: "Fix login", Body: "## Goal"}
+
+	// With gh installed, its printed URL is returned, even for an existing pull request
+	lookPath = func(string) (string, error) { return "/usr/bin/gh", nil }
+	fake.On("gh pr create", runner.Response{Output: "a pull request for branch \"flock-001\" into branch \"main\" already exists:\nhttps://github.com/dfowler/flock/pull/7\n", ExitCode: 1})
+	url, err := OpenPullRequest("/repo", pr)
+	if err != nil || url != "https://github.com/dfowler/flock/pull/7" {
+		t.Errorf("expected the existing pull request, got %q, %v", url, err)
+	}
+	if !fake.Ran("gh pr create --head flock-001 --base main --title Fix login --body ## Goal") {
+		t.Errorf("expected gh pr create, got %v", fake.Commands())
+	}
+
+	// Without gh, the GitHub API is used with the token from the environment
+	lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/repos/dfowler/flock/pulls" || r.Header.Get("Authorization") != "Bearer secret" || body["head"] != "flock-001" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url": "https://github.com/dfowler/flock/pull/8"}`))
+	}))
+	defer server.Close()
+	defer func(orig string) { githubAPI = orig }(githubAPI)
+	githubAPI = server.URL
+	t.Setenv("GITHUB_TOKEN", "secret")
+
+	url, err = OpenPullRequest("/repo", pr)
+	if err != nil || url != "https://github.com/dfowler/flock/pull/8" {
+		t.Errorf("expected the API to open pull request 8, got %q, %v", url, err)
+	}
+}
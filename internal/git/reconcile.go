@@ -0,0 +1,180 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WorktreeRecord is a task's persisted view of its worktree
+type WorktreeRecord struct {
+	TaskID       string
+	WorktreePath string
+	GitBranch    string
+	RepoRoot     string
+}
+
+// RecordFix is a correction to a task's worktree fields
+type RecordFix struct {
+	TaskID       string
+	WorktreePath string // new worktree path ("" clears it)
+	GitBranch    string // new branch ("" clears it)
+	Reason       string
+}
+
+// OrphanWorktree is a flock worktree on disk that no task references
+type OrphanWorktree struct {
+	RepoRoot string
+	Worktree Worktree
+}
+
+// Reconciliation describes how task records and worktrees on disk disagree
+type Reconciliation struct {
+	Fixes   []RecordFix
+	Orphans []OrphanWorktree
+}
+
+// Reconcile compares task worktree records with the worktrees git knows about.
+// extraRepos are repositories to scan for orphans even if no task references them.
+// Stale git worktree metadata is pruned as a side effect.
+func Reconcile(records []WorktreeRecord, extraRepos []string) *Reconciliation {
+	result := &Reconciliation{}
+
+	// Group records by repo, and collect every repo worth scanning
+	byRepo := make(map[string][]WorktreeRecord)
+	var repos []string
+	addRepo := func(repoRoot string) {
+		if _, ok := byRepo[repoRoot]; !ok {
+			byRepo[repoRoot] = nil
+			repos = append(repos, repoRoot)
+		}
+	}
+	for _, r := range records {
+		if r.RepoRoot == "" {
+			continue
+		}
+		addRepo(r.RepoRoot)
+		byRepo[r.RepoRoot] = append(byRepo[r.RepoRoot], r)
+	}
+	for _, repoRoot := range extraRepos {
+		if repoRoot != "" {
+			addRepo(repoRoot)
+		}
+	}
+
+	for _, repoRoot := range repos {
+		if _, err := os.Stat(repoRoot); err != nil {
+			// The repository itself is gone; nothing on disk to reconcile against
+			for _, r := range byRepo[repoRoot] {
+				if r.WorktreePath != "" || r.GitBranch != "" {
+					result.Fixes = append(result.Fixes, RecordFix{
+						TaskID: r.TaskID,
+						Reason: fmt.Sprintf("repository %s no longer exists", repoRoot),
+					})
+				}
+			}
+			continue
+		}
+
+		// Drop metadata for worktree directories deleted outside of git
+		_ = PruneWorktrees(repoRoot)
+
+		worktrees, err := ListWorktrees(repoRoot)
+		if err != nil {
+			continue
+		}
+		fixes, orphans := reconcileRepo(repoRoot, byRepo[repoRoot], worktrees)
+		result.Fixes = append(result.Fixes, fixes...)
+		result.Orphans = append(result.Orphans, orphans...)
+	}
+
+	return result
+}
+
+// reconcileRepo reconciles one repository's task records against its worktree list
+func reconcileRepo(repoRoot string, records []WorktreeRecord, worktrees []Worktree) ([]RecordFix, []OrphanWorktree) {
+	byPath := make(map[string]Worktree)
+	byBranch := make(map[string]Worktree)
+	for _, wt := range worktrees {
+		byPath[wt.Path] = wt
+		if wt.Branch != "" {
+			byBranch[wt.Branch] = wt
+		}
+	}
+
+	var fixes []RecordFix
+	referenced := make(map[string]bool)
+	for _, r := range records {
+		if r.WorktreePath != "" {
+			if _, ok := byPath[r.WorktreePath]; ok {
+				referenced[r.WorktreePath] = true
+				continue
+			}
+
+			// Worktree missing: follow the branch if it was checked out elsewhere
+			if wt, ok := byBranch[r.GitBranch]; ok && r.GitBranch != "" && IsFlockWorktree(wt.Path) {
+				referenced[wt.Path] = true
+				fixes = append(fixes, RecordFix{
+					TaskID:       r.TaskID,
+					WorktreePath: wt.Path,
+					GitBranch:    r.GitBranch,
+					Reason:       fmt.Sprintf("worktree moved to %s", wt.Path),
+				})
+				continue
+			}
+
+			if r.GitBranch != "" && BranchExists(repoRoot, r.GitBranch) {
+				fixes = append(fixes, RecordFix{
+					TaskID:    r.TaskID,
+					GitBranch: r.GitBranch,
+					Reason:    fmt.Sprintf("worktree %s is missing; branch %s kept", r.WorktreePath, r.GitBranch),
+				})
+				continue
+			}
+
+			fixes = append(fixes, RecordFix{
+				TaskID: r.TaskID,
+				Reason: fmt.Sprintf("worktree %s and its branch are missing", r.WorktreePath),
+			})
+			continue
+		}
+
+		// No worktree recorded, but the task's branch is checked out in a flock worktree
+		if r.GitBranch != "" {
+			if wt, ok := byBranch[r.GitBranch]; ok && IsFlockWorktree(wt.Path) && !referenced[wt.Path] {
+				referenced[wt.Path] = true
+				fixes = append(fixes, RecordFix{
+					TaskID:       r.TaskID,
+					WorktreePath: wt.Path,
+					GitBranch:    r.GitBranch,
+					Reason:       fmt.Sprintf("re-attached worktree %s", wt.Path),
+				})
+			}
+		}
+	}
+
+	// Task worktrees (not spares) that no task references are orphans
+	var orphans []OrphanWorktree
+	for _, wt := range worktrees {
+		if IsFlockWorktree(wt.Path) && !IsSpareWorktree(wt.Path) && !referenced[wt.Path] {
+			orphans = append(orphans, OrphanWorktree{RepoRoot: repoRoot, Worktree: wt})
+		}
+	}
+
+	return fixes, orphans
+}
+
+// PruneWorktrees removes git's metadata for worktrees whose directories no longer exist
+func PruneWorktrees(repoRoot string) error {
+	cmd := gitCommand("-C", repoRoot, "worktree", "prune")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to prune worktrees: %s: %w", string(output), err)
+	}
+	return nil
+}
+
+// DisplayName returns a short "repo/worktree" name for an orphaned worktree
+func (o OrphanWorktree) DisplayName() string {
+	return filepath.Base(o.RepoRoot) + "/" + filepath.Base(o.Worktree.Path)
+}
@@ -0,0 +1,35 @@
+package git
+
+import "testing"
+
+func TestReconcileRepo(t *testing.T) {
+	repoRoot := "/home/user/project"
+	worktrees := []Worktree{
+		{Path: repoRoot, Branch: "main"},
+		{Path: repoRoot + "/.flock-worktrees/flock-001", Branch: "flock-001"},
+		{Path: repoRoot + "/.flock-worktrees/flock-002-moved", Branch: "flock-002"},
+		{Path: repoRoot + "/.flock-worktrees/flock-003", Branch: "flock-003"},
+		{Path: repoRoot + "/.flock-worktrees/flock-spare-001", Branch: "flock-spare-001"},
+	}
+	records := []WorktreeRecord{
+		{TaskID: "001", WorktreePath: repoRoot + "/.flock-worktrees/flock-001", GitBranch: "flock-001", RepoRoot: repoRoot},
+		{TaskID: "002", WorktreePath: repoRoot + "/.flock-worktrees/flock-002", GitBranch: "flock-002", RepoRoot: repoRoot},
+		{TaskID: "004", WorktreePath: repoRoot + "/.flock-worktrees/flock-004", GitBranch: "flock-004", RepoRoot: repoRoot},
+	}
+
+	fixes, orphans := reconcileRepo(repoRoot, records, worktrees)
+
+	if len(fixes) != 2 {
+		t.Fatalf("expected 2 fixes, got %d: %+v", len(fixes), fixes)
+	}
+	if fixes[0].TaskID != "002" || fixes[0].WorktreePath != repoRoot+"/.flock-worktrees/flock-002-moved" {
+		t.Errorf("expected task 002 to follow its moved worktree, got %+v", fixes[0])
+	}
+	if fixes[1].TaskID != "004" || fixes[1].WorktreePath != "" || fixes[1].GitBranch != "" {
+		t.Errorf("expected task 004 worktree fields to be cleared, got %+v", fixes[1])
+	}
+
+	if len(orphans) != 1 || orphans[0].Worktree.Branch != "flock-003" {
+		t.Errorf("expected flock-003 as the only orphan, got %+v", orphans)
+	}
+}
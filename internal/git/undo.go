@@ -0,0 +1,141 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UndoMethod is how a merge onto the default branch is undone
+type UndoMethod string
+
+const (
+	// UndoReset moves the default branch back to where it was before the merge
+	UndoReset UndoMethod = "reset"
+	// UndoRevert adds a commit reverting the merge, keeping history intact
+	UndoRevert UndoMethod = "revert"
+)
+
+// PlanUndo picks how to undo the merge that moved the default branch from preMergeHead to
+// mergeCommit: a reset while the merge is still the branch's tip and no remote branch has
+// it, since nothing else would be lost; otherwise a revert. Fails if the default branch no
+// longer contains the merge.
+func PlanUndo(repoRoot, preMergeHead, mergeCommit string) (UndoMethod, error) {
+	if preMergeHead == "" || mergeCommit == "" {
+		return "", fmt.Errorf("no pre-merge savepoint was recorded")
+	}
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	if err := gitCommand("-C", repoRoot, "merge-base", "--is-ancestor", mergeCommit, defaultBranch).Run(); err != nil {
+		return "", fmt.Errorf("%s no longer contains the merge (%s)", defaultBranch, shortHash(mergeCommit))
+	}
+	head, err := RevParse(repoRoot, defaultBranch)
+	if err != nil {
+		return "", err
+	}
+	if head != mergeCommit {
+		return UndoRevert, nil
+	}
+	output, err := gitCommand("-C", repoRoot, "branch", "-r", "--contains", mergeCommit).Output()
+	if err != nil || strings.TrimSpace(string(output)) != "" {
+		// Already pushed (or unknown): rewriting history would break other clones
+		return UndoRevert, nil
+	}
+	return UndoReset, nil
+}
+
+// UndoMerge undoes the merge of branch that moved the default branch from preMergeHead to
+// mergeCommit. A reset keeps uncommitted changes in the main checkout (and refuses if they
+// touch the merged files); a revert reverts every commit the merge added in one commit and
+// is aborted if it conflicts.
+func UndoMerge(repoRoot, branch, preMergeHead, mergeCommit string, method UndoMethod) (string, error) {
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	if output, err := gitCommand("-C", repoRoot, "checkout", defaultBranch).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to checkout %s: %s", defaultBranch, strings.TrimSpace(string(output)))
+	}
+
+	if method == UndoReset {
+		if output, err := gitCommand("-C", repoRoot, "reset", "--keep", preMergeHead).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("failed to reset %s: %s", defaultBranch, strings.TrimSpace(string(output)))
+		}
+	} else if err := revertMerge(repoRoot, branch, preMergeHead, mergeCommit); err != nil {
+		return "", err
+	}
+	return undoNote(defaultBranch, branch, preMergeHead, method), nil
+}
+
+// UndoMergeIsolated undoes a merge like UndoMerge, but never checks anything out in the
+// main checkout: the reset or revert is made in the integration worktree and the default
+// branch is then moved to it with PublishIntegration. A checkout of the default branch is
+// moved along with it and keeps its uncommitted changes; when they are in the way, the
+// undo fails and nothing changes.
+func UndoMergeIsolated(repoRoot, branch, preMergeHead, mergeCommit string, method UndoMethod) (string, error) {
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+	expected, err := RevParse(repoRoot, "refs/heads/"+defaultBranch)
+	if err != nil {
+		return "", err
+	}
+	dir, err := prepareIntegration(repoRoot, defaultBranch)
+	if err != nil {
+		return "", err
+	}
+
+	if method == UndoReset {
+		if output, err := gitCommand("-C", dir, "checkout", "-q", "--detach", preMergeHead).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("failed to reset %s: %s", defaultBranch, strings.TrimSpace(string(output)))
+		}
+	} else if err := revertMerge(dir, branch, preMergeHead, mergeCommit); err != nil {
+		return "", err
+	}
+	if err := PublishIntegration(repoRoot, dir, expected); err != nil {
+		return "", err
+	}
+	return undoNote(defaultBranch, branch, preMergeHead, method), nil
+}
+
+// revertMerge commits, in dir, one revert of every commit the merge added
+func revertMerge(dir, branch, preMergeHead, mergeCommit string) error {
+	// Newest first along the default branch, so a merge commit is reverted as a whole
+	output, err := gitCommand("-C", dir, "rev-list", "--first-parent", preMergeHead+".."+mergeCommit).Output()
+	if err != nil {
+		return fmt.Errorf("failed to list merged commits: %w", err)
+	}
+	commits := strings.Fields(string(output))
+	if len(commits) == 0 {
+		return fmt.Errorf("the merge of %s added no commits", branch)
+	}
+	args := append([]string{"-C", dir, "revert", "--no-commit", "-m", "1"}, commits...)
+	if output, err := gitCommand(args...).CombinedOutput(); err != nil {
+		gitCommand("-C", dir, "revert", "--abort").Run()
+		return fmt.Errorf("reverting the merge of %s conflicts with later changes; the revert was aborted: %s", branch, strings.TrimSpace(string(output)))
+	}
+	message := fmt.Sprintf("Revert merge of %s\n\nThis reverts %s..%s.", branch, shortHash(preMergeHead), shortHash(mergeCommit))
+	if output, err := gitCommand("-C", dir, "commit", "--quiet", "-m", message).CombinedOutput(); err != nil {
+		gitCommand("-C", dir, "revert", "--abort").Run()
+		return fmt.Errorf("failed to commit the revert: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// undoNote describes an undone merge for the status panel
+func undoNote(defaultBranch, branch, preMergeHead string, method UndoMethod) string {
+	if method == UndoReset {
+		return fmt.Sprintf("Reset %s to %s, before %s was merged", defaultBranch, shortHash(preMergeHead), branch)
+	}
+	return fmt.Sprintf("Reverted the merge of %s on %s", branch, defaultBranch)
+}
+
+// shortHash abbreviates a commit hash for messages
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
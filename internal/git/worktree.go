@@ -2,10 +2,16 @@ package git
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"os/exec"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
 )
 
 const (
@@ -24,7 +30,7 @@ type Worktree struct {
 
 // IsGitRepo checks if the given path is inside a git repository
 func IsGitRepo(path string) bool {
-	cmd := exec.Command("git", "-C", path, "rev-parse", "--is-inside-work-tree")
+	cmd := gitCommand("-C", path, "rev-parse", "--is-inside-work-tree")
 	output, err := cmd.Output()
 	if err != nil {
 		return false
@@ -34,7 +40,7 @@ func IsGitRepo(path string) bool {
 
 // GetRepoRoot returns the root directory of the git repository containing the given path
 func GetRepoRoot(path string) (string, error) {
-	cmd := exec.Command("git", "-C", path, "rev-parse", "--show-toplevel")
+	cmd := gitCommand("-C", path, "rev-parse", "--show-toplevel")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("not a git repository: %w", err)
@@ -42,9 +48,31 @@ func GetRepoRoot(path string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetMainRepoRoot returns the root of the main checkout for a path, even when the path is inside a linked worktree
+func GetMainRepoRoot(path string) (string, error) {
+	cmd := gitCommand("-C", path, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return filepath.Dir(strings.TrimSpace(string(output))), nil
+}
+
+// ProjectRoot returns the project a directory belongs to: its main repo root,
+// or the absolute directory itself outside git
+func ProjectRoot(dir string) string {
+	if root, err := GetMainRepoRoot(dir); err == nil {
+		return root
+	}
+	if absDir, err := filepath.Abs(dir); err == nil {
+		return absDir
+	}
+	return dir
+}
+
 // GetCurrentBranch returns the current branch name for the given path
 func GetCurrentBranch(path string) (string, error) {
-	cmd := exec.Command("git", "-C", path, "rev-parse", "--abbrev-ref", "HEAD")
+	cmd := gitCommand("-C", path, "rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
@@ -55,7 +83,7 @@ func GetCurrentBranch(path string) (string, error) {
 // GetDefaultBranch returns the default branch name (main or master)
 func GetDefaultBranch(repoRoot string) (string, error) {
 	// Try to get the default branch from remote
-	cmd := exec.Command("git", "-C", repoRoot, "symbolic-ref", "refs/remotes/origin/HEAD")
+	cmd := gitCommand("-C", repoRoot, "symbolic-ref", "refs/remotes/origin/HEAD")
 	output, err := cmd.Output()
 	if err == nil {
 		// refs/remotes/origin/main -> main
@@ -67,13 +95,13 @@ func GetDefaultBranch(repoRoot string) (string, error) {
 	}
 
 	// Fallback: check if main exists
-	cmd = exec.Command("git", "-C", repoRoot, "show-ref", "--verify", "--quiet", "refs/heads/main")
+	cmd = gitCommand("-C", repoRoot, "show-ref", "--verify", "--quiet", "refs/heads/main")
 	if err := cmd.Run(); err == nil {
 		return "main", nil
 	}
 
 	// Fallback: check if master exists
-	cmd = exec.Command("git", "-C", repoRoot, "show-ref", "--verify", "--quiet", "refs/heads/master")
+	cmd = gitCommand("-C", repoRoot, "show-ref", "--verify", "--quiet", "refs/heads/master")
 	if err := cmd.Run(); err == nil {
 		return "master", nil
 	}
@@ -83,7 +111,7 @@ func GetDefaultBranch(repoRoot string) (string, error) {
 
 // ListWorktrees returns all worktrees for the given repository
 func ListWorktrees(repoRoot string) ([]Worktree, error) {
-	cmd := exec.Command("git", "-C", repoRoot, "worktree", "list", "--porcelain")
+	cmd := gitCommand("-C", repoRoot, "worktree", "list", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
@@ -130,8 +158,24 @@ func CreateWorktree(repoRoot, worktreePath, branch string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get default branch: %w", err)
 	}
+	return CreateWorktreeFrom(repoRoot, worktreePath, branch, defaultBranch)
+}
+
+// CreateWorktreeFrom creates a new worktree with a new branch starting at base
+func CreateWorktreeFrom(repoRoot, worktreePath, branch, base string) error {
+	// --no-track keeps a branch started from origin/main from pushing to main
+	cmd := gitCommand("-C", repoRoot, "worktree", "add", "--no-track", "-b", branch, worktreePath, base)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create worktree: %s: %w", string(output), err)
+	}
 
-	cmd := exec.Command("git", "-C", repoRoot, "worktree", "add", "-b", branch, worktreePath, defaultBranch)
+	return nil
+}
+
+// CreateWorktreeForBranch creates a new worktree that checks out an existing branch
+func CreateWorktreeForBranch(repoRoot, worktreePath, branch string) error {
+	cmd := gitCommand("-C", repoRoot, "worktree", "add", worktreePath, branch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to create worktree: %s: %w", string(output), err)
@@ -140,6 +184,53 @@ func CreateWorktree(repoRoot, worktreePath, branch string) error {
 	return nil
 }
 
+// BranchExists checks if a local branch exists in the repository
+func BranchExists(repoRoot, branch string) bool {
+	cmd := gitCommand("-C", repoRoot, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return cmd.Run() == nil
+}
+
+// UniqueBranchName returns branch, or branch with the lowest numeric suffix
+// (e.g. flock-007-2) that doesn't exist yet
+func UniqueBranchName(repoRoot, branch string) string {
+	if !BranchExists(repoRoot, branch) {
+		return branch
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", branch, n)
+		if !BranchExists(repoRoot, candidate) {
+			return candidate
+		}
+	}
+}
+
+// uniqueWorktreePath returns path, or path with a numeric suffix if something is already there
+func uniqueWorktreePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", path, n)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// CommitsAhead returns how many commits branch has that the default branch doesn't
+func CommitsAhead(repoRoot, branch string) (int, error) {
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return 0, err
+	}
+	cmd := gitCommand("-C", repoRoot, "rev-list", "--count", defaultBranch+".."+branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
 // RemoveWorktree removes a worktree and optionally its branch
 func RemoveWorktree(repoRoot, worktreePath string, deleteBranch bool) error {
 	// Get the branch name before removing
@@ -157,30 +248,51 @@ func RemoveWorktree(repoRoot, worktreePath string, deleteBranch bool) error {
 	}
 
 	// Remove the worktree
-	cmd := exec.Command("git", "-C", repoRoot, "worktree", "remove", "--force", worktreePath)
+	cmd := gitCommand("-C", repoRoot, "worktree", "remove", "--force", worktreePath)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to remove worktree: %s: %w", string(output), err)
 	}
 
-	// Delete the branch if requested and it's a flock branch
-	if deleteBranch && branch != "" && strings.HasPrefix(branch, FlockWorktreePrefix) {
-		cmd = exec.Command("git", "-C", repoRoot, "branch", "-D", branch)
+	// Delete the branch if requested and it belongs to flock: either named
+	// with the default prefix or checked out in a flock-managed worktree
+	if deleteBranch && branch != "" && (strings.HasPrefix(branch, FlockWorktreePrefix) || IsFlockWorktree(worktreePath)) {
+		cmd = gitCommand("-C", repoRoot, "branch", "-D", branch)
 		// Ignore errors - branch may already be deleted
 		_ = cmd.Run()
 	}
 
+	// Under the worktree root, the repo's directory goes with its last worktree
+	// (os.Remove leaves a directory that isn't empty)
+	if parent := filepath.Dir(worktreePath); worktreeRoot != "" && filepath.Dir(parent) == filepath.Clean(worktreeRoot) {
+		os.Remove(parent)
+	}
+
 	return nil
 }
 
-// WorktreeDirPath returns the path to the flock worktrees directory for a repo
+// worktreeRoot holds every repo's worktrees outside the repos when set (see SetWorktreeRoot)
+var worktreeRoot string
+
+// SetWorktreeRoot makes new worktrees go under dir, in a directory per repo, instead of
+// in each repo's .flock-worktrees. Empty restores the default.
+func SetWorktreeRoot(dir string) {
+	worktreeRoot = dir
+}
+
+// WorktreeDirPath returns the path to the flock worktrees directory for a repo: its
+// .flock-worktrees, or <root>/<repo name>-<hash of its path> under the worktree root
 func WorktreeDirPath(repoRoot string) string {
-	return filepath.Join(repoRoot, FlockWorktreeDir)
+	if worktreeRoot == "" {
+		return filepath.Join(repoRoot, FlockWorktreeDir)
+	}
+	sum := sha256.Sum256([]byte(repoRoot))
+	return filepath.Join(worktreeRoot, filepath.Base(repoRoot)+"-"+hex.EncodeToString(sum[:])[:8])
 }
 
 // WorktreePath returns the full path for a worktree with the given ID
 func WorktreePath(repoRoot, worktreeID string) string {
-	return filepath.Join(repoRoot, FlockWorktreeDir, FlockWorktreePrefix+worktreeID)
+	return filepath.Join(WorktreeDirPath(repoRoot), FlockWorktreePrefix+worktreeID)
 }
 
 // BranchName returns the branch name for a worktree with the given ID
@@ -188,23 +300,144 @@ func BranchName(worktreeID string) string {
 	return FlockWorktreePrefix + worktreeID
 }
 
-// IsFlockWorktree checks if the given worktree path is a flock-managed worktree
+// IsFlockWorktree checks if the given worktree path is a flock-managed worktree: named
+// flock-*, in a repo's .flock-worktrees or a repo directory under the worktree root. A
+// repo that merely has a flock- name doesn't count.
 func IsFlockWorktree(path string) bool {
-	base := filepath.Base(path)
-	return strings.HasPrefix(base, FlockWorktreePrefix)
+	if !strings.HasPrefix(filepath.Base(path), FlockWorktreePrefix) {
+		return false
+	}
+	parent := filepath.Dir(path)
+	return filepath.Base(parent) == FlockWorktreeDir || (worktreeRoot != "" && filepath.Dir(parent) == filepath.Clean(worktreeRoot))
+}
+
+// IsSpareWorktree checks if the given worktree path is a flock spare (pre-created, never named for a task)
+func IsSpareWorktree(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), FlockWorktreePrefix+"spare-")
+}
+
+// isPristineWorktree reports whether a worktree has no uncommitted changes and no
+// commits beyond the default branch, i.e. removing it cannot lose any work
+func isPristineWorktree(repoRoot, worktreePath string) bool {
+	if IsDirty(worktreePath) {
+		return false
+	}
+
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return false
+	}
+	cmd := gitCommand("-C", worktreePath, "rev-list", "--count", defaultBranch+"..HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == "0"
+}
+
+// IsDirty reports whether a worktree has uncommitted changes or untracked files that git
+// doesn't ignore. A worktree git can't read counts as dirty, so it is never treated as
+// safe to remove.
+func IsDirty(worktreePath string) bool {
+	output, err := gitCommand("-C", worktreePath, "--no-optional-locks", "status", "--porcelain").Output()
+	return err != nil || strings.TrimSpace(string(output)) != ""
+}
+
+// ChangedFiles lists a worktree's uncommitted changes and untracked files, one path each
+func ChangedFiles(worktreePath string) ([]string, error) {
+	// Without optional locks, status doesn't refresh the index, which LastActivity reads
+	output, err := gitCommand("-C", worktreePath, "--no-optional-locks", "status", "--porcelain").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worktree status: %w", err)
+	}
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if len(line) > 3 {
+			files = append(files, line[3:])
+		}
+	}
+	return files, nil
+}
+
+// StashChanges stashes a worktree's uncommitted changes and untracked files under
+// message, so a reset can't destroy them. It reports whether there was anything to stash.
+// Stashes are shared by all of a repository's worktrees, so `git stash list` anywhere in
+// the repository shows it.
+func StashChanges(worktreePath, message string) (bool, error) {
+	if !IsDirty(worktreePath) {
+		return false, nil
+	}
+	output, err := gitCommand("-C", worktreePath, "stash", "push", "--include-untracked", "-m", message).CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("failed to stash changes: %s", firstLine(string(output), err))
+	}
+	return true, nil
+}
+
+// StashMessage names the stash saving a worktree's changes before flock resets it
+func StashMessage(branch, reason string) string {
+	return fmt.Sprintf("flock: %s before %s (%s)", branch, reason, time.Now().Format("2006-01-02 15:04"))
+}
+
+// LastActivity returns when a worktree was last used: its latest commit, or the last time
+// git wrote its index (checkouts, resets, staging), whichever is newer
+func LastActivity(worktreePath string) time.Time {
+	var last time.Time
+	if output, err := gitCommand("-C", worktreePath, "log", "-1", "--format=%ct").Output(); err == nil {
+		if seconds, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
+			last = time.Unix(seconds, 0)
+		}
+	}
+	if output, err := gitCommand("-C", worktreePath, "rev-parse", "--git-path", "index").Output(); err == nil {
+		index := strings.TrimSpace(string(output))
+		if !filepath.IsAbs(index) {
+			index = filepath.Join(worktreePath, index)
+		}
+		if info, err := os.Stat(index); err == nil && info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last
+}
+
+// DiskUsage returns the total size in bytes of the files under path
+func DiskUsage(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries rather than failing the whole walk
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// LastCommit returns a one-line summary of the latest commit in a worktree
+// Example: "a1b2c3d Fix flaky test (2 hours ago)"
+func LastCommit(worktreePath string) (string, error) {
+	cmd := gitCommand("-C", worktreePath, "log", "-1", "--format=%h %s (%cr)")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get last commit: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
 }
 
 // IsPathInWorktree checks if the given path is inside a worktree (not the main repo)
 func IsPathInWorktree(path string) bool {
-	cmd := exec.Command("git", "-C", path, "rev-parse", "--is-inside-work-tree")
+	cmd := gitCommand("-C", path, "rev-parse", "--is-inside-work-tree")
 	if err := cmd.Run(); err != nil {
 		return false
 	}
 
 	// Check if this is a worktree by looking for .git file (worktrees have a .git file, not directory)
-	gitPath := filepath.Join(path, ".git")
-	cmd = exec.Command("test", "-f", gitPath)
-	return cmd.Run() == nil
+	info, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil && !info.IsDir()
 }
 
 // MergeResult contains the result of a merge operation
@@ -212,17 +445,33 @@ type MergeResult struct {
 	Success      bool
 	Message      string
 	HasConflicts bool
+	Conflicts    []string // conflicted files, when known
+	PreMergeHead string   // default branch HEAD before the merge
+	MergeCommit  string   // default branch HEAD after a successful merge
+	Dir          string   // Integration worktree an isolated merge was made in ("" for the main checkout)
 }
 
-// MergeBranch merges the given branch into the default branch
-func MergeBranch(repoRoot, branch string) (*MergeResult, error) {
+// MergeBranch merges the given branch into the default branch. With StrategyRebase the
+// branch is first rebased onto the default branch and then fast-forwarded; with
+// StrategySquash its changes become a single commit. Conflicting merges and rebases are
+// aborted so the repository is left as it was. Message is the merge or squash commit's
+// message ("" keeps git's). Trailers ("Key: value") are added to the merge commit, which
+// is then always created, or with StrategyRebase to every rebased commit.
+func MergeBranch(repoRoot, branch string, strategy MergeStrategy, message string, trailers []string) (*MergeResult, error) {
 	defaultBranch, err := GetDefaultBranch(repoRoot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get default branch: %w", err)
 	}
 
+	if strategy == StrategyRebase {
+		result, err := rebaseOntoDefault(repoRoot, repoRoot, branch, defaultBranch, trailers)
+		if err != nil || result != nil {
+			return result, err
+		}
+	}
+
 	// First, checkout the default branch in the main repo
-	cmd := exec.Command("git", "-C", repoRoot, "checkout", defaultBranch)
+	cmd := gitCommand("-C", repoRoot, "checkout", defaultBranch)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return &MergeResult{
@@ -230,19 +479,44 @@ func MergeBranch(repoRoot, branch string) (*MergeResult, error) {
 			Message: fmt.Sprintf("Failed to checkout %s: %s", defaultBranch, strings.TrimSpace(string(output))),
 		}, nil
 	}
+	return mergeInto(repoRoot, branch, defaultBranch, strategy, message, trailers)
+}
+
+// mergeInto merges branch into whatever dir has checked out, which is the default branch
+func mergeInto(dir, branch, defaultBranch string, strategy MergeStrategy, message string, trailers []string) (*MergeResult, error) {
+	if strategy == StrategySquash {
+		return squashInto(dir, branch, defaultBranch, message, trailers)
+	}
+
+	// Record where the default branch was, so the merge can be traced later
+	preMergeHead, _ := RevParse(dir, "HEAD")
 
-	// Perform the merge
-	cmd = exec.Command("git", "-C", repoRoot, "merge", branch, "--no-edit")
-	output, err = cmd.CombinedOutput()
+	// Perform the merge (a rebased branch must fast-forward)
+	var cmd *runner.Proc
+	args := []string{"-C", dir, "merge", branch, "--no-edit"}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+	if strategy == StrategyRebase {
+		cmd = gitCommand("-C", dir, "merge", "--ff-only", branch)
+	} else if len(trailers) > 0 {
+		cmd = gitCommand(append(args, "--no-ff")...)
+	} else {
+		cmd = gitCommand(args...)
+	}
+	output, err := cmd.CombinedOutput()
 	outputStr := strings.TrimSpace(string(output))
 
 	if err != nil {
 		// Check if it's a merge conflict
 		if strings.Contains(outputStr, "CONFLICT") || strings.Contains(outputStr, "Automatic merge failed") {
+			conflicts, _ := conflictedFiles(dir)
+			gitCommand("-C", dir, "merge", "--abort").Run()
 			return &MergeResult{
 				Success:      false,
 				HasConflicts: true,
-				Message:      fmt.Sprintf("Merge conflicts detected. Resolve conflicts in %s", repoRoot),
+				Conflicts:    conflicts,
+				Message:      fmt.Sprintf("Merging %s into %s conflicts; the merge was aborted", branch, defaultBranch),
 			}, nil
 		}
 		return &MergeResult{
@@ -251,22 +525,90 @@ func MergeBranch(repoRoot, branch string) (*MergeResult, error) {
 		}, nil
 	}
 
+	mergeCommit, _ := RevParse(dir, "HEAD")
+	if len(trailers) > 0 && strategy != StrategyRebase && mergeCommit != preMergeHead && isMergeCommit(dir, "HEAD") {
+		args := append([]string{"-C", dir, "commit", "--amend", "--no-edit", "--quiet"}, trailerArgs(trailers)...)
+		// The merge stands either way; a git without --trailer (before 2.32) just leaves them out
+		if err := gitCommand(args...).Run(); err == nil {
+			mergeCommit, _ = RevParse(dir, "HEAD")
+		}
+	}
+
+	if strategy == StrategyRebase {
+		return &MergeResult{
+			Success:      true,
+			Message:      fmt.Sprintf("Rebased %s onto %s and fast-forwarded", branch, defaultBranch),
+			PreMergeHead: preMergeHead,
+			MergeCommit:  mergeCommit,
+		}, nil
+	}
+
 	// Check if it was a fast-forward or actual merge
 	if strings.Contains(outputStr, "Fast-forward") {
 		return &MergeResult{
-			Success: true,
-			Message: fmt.Sprintf("Fast-forward merged %s into %s", branch, defaultBranch),
+			Success:      true,
+			Message:      fmt.Sprintf("Fast-forward merged %s into %s", branch, defaultBranch),
+			PreMergeHead: preMergeHead,
+			MergeCommit:  mergeCommit,
 		}, nil
 	}
 
 	return &MergeResult{
-		Success: true,
-		Message: fmt.Sprintf("Merged %s into %s", branch, defaultBranch),
+		Success:      true,
+		Message:      fmt.Sprintf("Merged %s into %s", branch, defaultBranch),
+		PreMergeHead: preMergeHead,
+		MergeCommit:  mergeCommit,
 	}, nil
 }
 
+// RevParse resolves a revision to a full commit hash
+func RevParse(repoRoot, rev string) (string, error) {
+	cmd := gitCommand("-C", repoRoot, "rev-parse", "--verify", rev)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Commit is a commit summary as returned by Log
+type Commit struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// Log returns the commits reachable from `to` but not from `from`, newest first
+func Log(repoRoot, from, to string) ([]Commit, error) {
+	// Fields are separated by NUL and records by the ASCII record separator
+	cmd := gitCommand("-C", repoRoot, "log", "--format=%H%x00%s%x00%b%x1e", from+".."+to)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log %s..%s: %w", from, to, err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x00", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		c := Commit{Hash: fields[0], Subject: fields[1]}
+		if len(fields) == 3 {
+			c.Body = strings.TrimSpace(fields[2])
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
 // ResetWorktreeBranch resets a worktree's branch to the current default branch HEAD
-// This ensures a reused worktree starts fresh with the latest code
+// This ensures a reused worktree starts fresh with the latest code. Uncommitted changes
+// are discarded; stash them first with StashChanges to keep them.
 func ResetWorktreeBranch(worktreePath string) error {
 	// Get the repo root for this worktree
 	repoRoot, err := GetRepoRoot(worktreePath)
@@ -280,9 +622,12 @@ func ResetWorktreeBranch(worktreePath string) error {
 		return fmt.Errorf("failed to get default branch: %w", err)
 	}
 
-	// Reset the worktree's branch to the default branch HEAD
-	// This is equivalent to: git reset --hard origin/main (but using local default branch)
-	cmd := exec.Command("git", "-C", worktreePath, "reset", "--hard", defaultBranch)
+	return ResetWorktreeTo(worktreePath, defaultBranch)
+}
+
+// ResetWorktreeTo resets a worktree's branch to base, such as main or origin/main
+func ResetWorktreeTo(worktreePath, base string) error {
+	cmd := gitCommand("-C", worktreePath, "reset", "--hard", base)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to reset branch: %s: %w", string(output), err)
@@ -291,6 +636,29 @@ func ResetWorktreeBranch(worktreePath string) error {
 	return nil
 }
 
+// switchWorktreeBranch moves a reused worktree from its old branch to a task's branch.
+// A new name renames the old branch; an existing branch is checked out and the old one deleted.
+func switchWorktreeBranch(worktreePath, repoRoot, oldBranch, newBranch string, existing bool) error {
+	var args []string
+	switch {
+	case existing:
+		args = []string{"checkout", newBranch}
+	case oldBranch == "":
+		args = []string{"checkout", "-b", newBranch}
+	default:
+		args = []string{"branch", "-m", newBranch}
+	}
+	cmd := gitCommand(append([]string{"-C", worktreePath}, args...)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to switch worktree to branch %s: %s: %w", newBranch, strings.TrimSpace(string(output)), err)
+	}
+	if existing && oldBranch != "" {
+		// Ignore errors - the old branch is only a leftover
+		_ = gitCommand("-C", repoRoot, "branch", "-D", oldBranch).Run()
+	}
+	return nil
+}
+
 // GetBranchDiff returns a summary of changes between the branch and default branch
 func GetBranchDiff(repoRoot, branch string) (string, error) {
 	defaultBranch, err := GetDefaultBranch(repoRoot)
@@ -299,7 +667,7 @@ func GetBranchDiff(repoRoot, branch string) (string, error) {
 	}
 
 	// Get commit count
-	cmd := exec.Command("git", "-C", repoRoot, "rev-list", "--count", fmt.Sprintf("%s..%s", defaultBranch, branch))
+	cmd := gitCommand("-C", repoRoot, "rev-list", "--count", fmt.Sprintf("%s..%s", defaultBranch, branch))
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -307,7 +675,7 @@ func GetBranchDiff(repoRoot, branch string) (string, error) {
 	commitCount := strings.TrimSpace(string(output))
 
 	// Get diffstat
-	cmd = exec.Command("git", "-C", repoRoot, "diff", "--stat", fmt.Sprintf("%s..%s", defaultBranch, branch))
+	cmd = gitCommand("-C", repoRoot, "diff", "--stat", fmt.Sprintf("%s..%s", defaultBranch, branch))
 	output, err = cmd.Output()
 	if err != nil {
 		return "", err
@@ -320,3 +688,46 @@ func GetBranchDiff(repoRoot, branch string) (string, error) {
 
 	return fmt.Sprintf("%s commit(s)\n%s", commitCount, diffStat), nil
 }
+
+// BranchDiff returns the full diff of what merging branch would bring into the default branch
+func BranchDiff(repoRoot, branch string) (string, error) {
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	output, err := gitCommand("-C", repoRoot, "diff", fmt.Sprintf("%s...%s", defaultBranch, branch)).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s against %s: %w", branch, defaultBranch, err)
+	}
+	return string(output), nil
+}
+
+// DiffShortStat summarizes the changes in a revision range, e.g.
+// "3 files changed, 40 insertions(+), 2 deletions(-)" ("" if nothing changed)
+func DiffShortStat(repoRoot, revRange string) (string, error) {
+	output, err := gitCommand("-C", repoRoot, "diff", "--shortstat", revRange).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get diffstat for %s: %w", revRange, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// DiffFromDefault returns the unified diff of dir's working tree against the commit where
+// its branch left the default branch, so uncommitted work is included
+func DiffFromDefault(repoRoot, dir string) (string, error) {
+	defaultBranch, err := GetDefaultBranch(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	output, err := gitCommand("-C", dir, "merge-base", defaultBranch, "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find where HEAD left %s: %w", defaultBranch, err)
+	}
+	base := strings.TrimSpace(string(output))
+
+	output, err = gitCommand("-C", dir, "diff", base).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff against %s: %w", defaultBranch, err)
+	}
+	return string(output), nil
+}
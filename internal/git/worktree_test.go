@@ -3,7 +3,11 @@ package git
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/gittest"
 )
 
 func TestIsGitRepo(t *testing.T) {
@@ -83,6 +87,7 @@ func TestIsFlockWorktree(t *testing.T) {
 		{"/home/user/project/.flock-worktrees/flock-spare-001", true},
 		{"/home/user/project", false},
 		{"/home/user/project/.git/worktrees/some-worktree", false},
+		{"/home/user/flock-api", false},
 	}
 
 	for _, tt := range tests {
@@ -93,6 +98,28 @@ func TestIsFlockWorktree(t *testing.T) {
 	}
 }
 
+func TestWorktreeRoot(t *testing.T) {
+	SetWorktreeRoot("/home/user/.flock/worktrees")
+	defer SetWorktreeRoot("")
+
+	path := WorktreePath("/home/user/project", "001")
+	if !strings.HasPrefix(path, "/home/user/.flock/worktrees/project-") || filepath.Base(path) != "flock-001" {
+		t.Errorf("expected the worktree under the root, got %s", path)
+	}
+	if other := WorktreePath("/srv/project", "001"); other == path {
+		t.Errorf("expected repos with the same name to get different directories, both got %s", path)
+	}
+	for p, expected := range map[string]bool{
+		path: true,
+		"/home/user/project/.flock-worktrees/flock-002": true, // From before the root was set
+		"/home/user/.flock/worktrees/flock-003":         false,
+	} {
+		if IsFlockWorktree(p) != expected {
+			t.Errorf("IsFlockWorktree(%s) = %v, expected %v", p, !expected, expected)
+		}
+	}
+}
+
 func TestBranchName(t *testing.T) {
 	result := BranchName("001")
 	expected := "flock-001"
@@ -108,3 +135,90 @@ func TestWorktreePath(t *testing.T) {
 		t.Errorf("WorktreePath result = %s, expected %s", result, expected)
 	}
 }
+
+func TestIsSpareWorktree(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/home/user/project/.flock-worktrees/flock-spare-001", true},
+		{"/home/user/project/.flock-worktrees/flock-001", false},
+		{"/home/user/project", false},
+	}
+
+	for _, tt := range tests {
+		result := IsSpareWorktree(tt.path)
+		if result != tt.expected {
+			t.Errorf("IsSpareWorktree(%s) = %v, expected %v", tt.path, result, tt.expected)
+		}
+	}
+}
+
+func TestNextSpareID(t *testing.T) {
+	worktrees := []Worktree{
+		{Path: "/home/user/project"},
+		{Path: "/home/user/project/.flock-worktrees/flock-001"},
+		{Path: "/home/user/project/.flock-worktrees/flock-spare-001"},
+		{Path: "/home/user/project/.flock-worktrees/flock-spare-003"},
+	}
+	creating := map[string]bool{
+		"/home/user/project/.flock-worktrees/flock-spare-002": true,
+	}
+
+	result := nextSpareID(worktrees, creating)
+	expected := "spare-004"
+	if result != expected {
+		t.Errorf("nextSpareID result = %s, expected %s", result, expected)
+	}
+}
+
+func TestDiffFromDefault(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	gittest.Run(t, repo, "checkout", "-q", "-b", "flock-001")
+	gittest.CommitFile(t, repo, "b.txt", "committed\n")
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("uncommitted\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gittest.Run(t, repo, "checkout", "-q", "main")
+	gittest.CommitFile(t, repo, "c.txt", "on main\n")
+	gittest.Run(t, repo, "checkout", "-q", "flock-001")
+
+	diff, err := DiffFromDefault(repo, repo)
+	if err != nil {
+		t.Fatalf("DiffFromDefault failed: %v", err)
+	}
+	for _, expected := range []string{"+committed", "+uncommitted"} {
+		if !strings.Contains(diff, expected) {
+			t.Errorf("expected the diff to contain %q, got:\n%s", expected, diff)
+		}
+	}
+	if strings.Contains(diff, "on main") {
+		t.Errorf("expected changes made on main since the branch point to be left out")
+	}
+}
+
+func TestWorktreeActivity(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	t.Setenv("GIT_COMMITTER_DATE", "2020-01-01T00:00:00Z")
+	gittest.CommitFile(t, repo, "a.txt", "one\n")
+	if IsDirty(repo) {
+		t.Errorf("expected a freshly committed repo to be clean")
+	}
+
+	// The commit is old, but writing the index just now counts as activity
+	if last := LastActivity(repo); time.Since(last) > time.Minute {
+		t.Errorf("expected recent activity from the index, got %v", last)
+	}
+	os.Chtimes(filepath.Join(repo, ".git", "index"), time.Time{}, time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	if last := LastActivity(repo); last.Year() != 2020 {
+		t.Errorf("expected the 2020 commit as the last activity, got %v", last)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "b.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !IsDirty(repo) {
+		t.Errorf("expected an untracked file to make the repo dirty")
+	}
+}
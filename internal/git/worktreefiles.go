@@ -0,0 +1,131 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CopyWorktreeFiles brings files git doesn't track, such as .env or local certificates,
+// from the main checkout into a worktree. Patterns are filepath.Match globs relative to
+// the repository root, and a matched directory is brought over whole. Files matching
+// copyPatterns are copied, replacing earlier copies; files matching linkPatterns are
+// symlinked, so edits in the main checkout show up everywhere. Tracked files are left
+// to git. Returns the paths brought over, relative to the repository root.
+func CopyWorktreeFiles(repoRoot, worktreePath string, copyPatterns, linkPatterns []string) ([]string, error) {
+	if len(copyPatterns) == 0 && len(linkPatterns) == 0 {
+		return nil, nil
+	}
+	output, err := gitCommand("-C", repoRoot, "ls-files").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %w", err)
+	}
+	tracked := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			tracked[filepath.FromSlash(line)] = true
+		}
+	}
+
+	var brought []string
+	for _, set := range []struct {
+		patterns []string
+		link     bool
+	}{{copyPatterns, false}, {linkPatterns, true}} {
+		for _, pattern := range set.patterns {
+			matches, err := filepath.Glob(filepath.Join(repoRoot, pattern))
+			if err != nil {
+				return brought, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			for _, src := range matches {
+				rel, err := filepath.Rel(repoRoot, src)
+				if err != nil || tracked[rel] || isGitPath(rel) {
+					continue
+				}
+				dst := filepath.Join(worktreePath, rel)
+				if set.link {
+					err = linkFile(src, dst)
+				} else {
+					err = copyTree(src, dst, rel, tracked)
+				}
+				if err != nil {
+					return brought, fmt.Errorf("failed to bring %s into the worktree: %w", rel, err)
+				}
+				brought = append(brought, rel)
+			}
+		}
+	}
+	return brought, nil
+}
+
+// isGitPath reports whether a path relative to the repository root is git's or flock's own
+func isGitPath(rel string) bool {
+	first := strings.Split(filepath.ToSlash(rel), "/")[0]
+	return first == ".git" || first == FlockWorktreeDir
+}
+
+// linkFile replaces dst with a symlink to src
+func linkFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return os.Symlink(src, dst)
+}
+
+// copyTree copies a file, or a directory's untracked files, from src to dst
+func copyTree(src, dst, rel string, tracked map[string]bool) error {
+	// Writing through a symlink left by link_files would overwrite the main checkout's files
+	if info, err := os.Lstat(dst); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		sub, _ := filepath.Rel(src, path)
+		if d.IsDir() {
+			if isGitPath(filepath.Join(rel, sub)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if tracked[filepath.Join(rel, sub)] || !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(path, filepath.Join(dst, sub))
+	})
+}
+
+// copyFile copies a regular file, keeping its permissions
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dfowler/flock/internal/gittest"
+)
+
+func TestCopyWorktreeFiles(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	gittest.CommitFile(t, repo, "config.env", "tracked\n")
+	for name, content := range map[string]string{
+		".env":            "SECRET=1\n",
+		"certs/local.pem": "cert\n",
+		"local.db":        "data\n",
+		"config.env":      "changed\n",
+	} {
+		path := filepath.Join(repo, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	worktree := t.TempDir()
+	os.WriteFile(filepath.Join(worktree, "config.env"), []byte("tracked\n"), 0644)
+	brought, err := CopyWorktreeFiles(repo, worktree, []string{"*.env", "certs"}, []string{"local.db"})
+	if err != nil {
+		t.Fatalf("CopyWorktreeFiles failed: %v", err)
+	}
+	if len(brought) != 3 {
+		t.Errorf("expected .env, certs and local.db, got %v", brought)
+	}
+
+	for name, want := range map[string]string{
+		".env":            "SECRET=1\n",
+		"certs/local.pem": "cert\n",
+		"config.env":      "tracked\n", // Tracked files are git's business
+	} {
+		if data, _ := os.ReadFile(filepath.Join(worktree, name)); string(data) != want {
+			t.Errorf("expected %s to hold %q, got %q", name, want, data)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(worktree, "local.db")); err != nil || target != filepath.Join(repo, "local.db") {
+		t.Errorf("expected local.db linked to the main checkout, got %q (%v)", target, err)
+	}
+
+	// Copying local.db after it was linked replaces the link rather than writing through it
+	if _, err := CopyWorktreeFiles(repo, worktree, []string{"local.db"}, nil); err != nil {
+		t.Fatalf("CopyWorktreeFiles failed: %v", err)
+	}
+	if info, err := os.Lstat(filepath.Join(worktree, "local.db")); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("expected local.db copied over the link")
+	}
+}
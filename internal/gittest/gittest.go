@@ -0,0 +1,47 @@
+// Package gittest sets up git repositories for tests
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// NewRepo creates an empty repository on branch main in a temp dir, with a git identity
+// set for the rest of the test so commits work without a user config
+func NewRepo(t *testing.T) string {
+	t.Helper()
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+	repo := t.TempDir()
+	Run(t, repo, "init", "-q", "-b", "main")
+	return repo
+}
+
+// Run runs git in dir, failing the test if it fails
+func Run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+	}
+}
+
+// CommitFile writes a file in dir and commits it
+func CommitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Run(t, dir, "add", name)
+	Run(t, dir, "commit", "-q", "-m", "update "+name)
+}
@@ -0,0 +1,119 @@
+// Package instance keeps two flock processes from managing the same tasks at once. The
+// dashboard and the daemon hold an exclusive lock on ~/.flock/flock.lock while they run,
+// with a note of who they are in the file for the one that has to back off.
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dfowler/flock/internal/filelock"
+)
+
+// Roles of the processes that take the lock
+const (
+	RoleDashboard = "dashboard"
+	RoleDaemon    = "daemon"
+)
+
+// Holder describes the process holding the lock
+type Holder struct {
+	PID     int       `json:"pid"`
+	Role    string    `json:"role"`              // RoleDashboard or RoleDaemon
+	Session string    `json:"session,omitempty"` // zellij session the process runs in
+	Started time.Time `json:"started"`
+}
+
+// String describes the holder, e.g. "the dashboard (pid 4242, zellij session work)"
+func (h Holder) String() string {
+	role := h.Role
+	if role == "" {
+		role = "another flock"
+	}
+	desc := fmt.Sprintf("the %s (pid %d", role, h.PID)
+	if h.Session != "" {
+		desc += ", zellij session " + h.Session
+	}
+	return desc + ")"
+}
+
+// HeldError is returned when another process holds the lock
+type HeldError struct {
+	Holder Holder
+}
+
+func (e *HeldError) Error() string {
+	if e.Holder.PID == 0 {
+		return "flock is already running"
+	}
+	return fmt.Sprintf("flock is already running: %s", e.Holder)
+}
+
+// Lock is a held instance lock
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes the lock at path without waiting, recording holder in it. If another
+// process holds the lock, it returns a *HeldError describing that process. The lock
+// goes away with the process, so a crash never leaves it behind.
+func Acquire(path string, holder Holder) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock: %w", err)
+	}
+	if err := filelock.Lock(file, true, false); err != nil {
+		file.Close()
+		if errors.Is(err, filelock.ErrLocked) {
+			other, _ := readHolder(path)
+			return nil, &HeldError{Holder: other}
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	data, err := json.Marshal(holder)
+	if err == nil {
+		if err = file.Truncate(0); err == nil {
+			_, err = file.WriteAt(data, 0)
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write lock: %w", err)
+	}
+	return &Lock{file: file}, nil
+}
+
+// Release clears the holder and releases the lock
+func (l *Lock) Release() error {
+	l.file.Truncate(0)
+	return l.file.Close()
+}
+
+// Running returns the process holding the lock at path, if any
+func Running(path string) (Holder, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Holder{}, false
+	}
+	defer file.Close()
+	if err := filelock.Lock(file, false, false); !errors.Is(err, filelock.ErrLocked) {
+		return Holder{}, false
+	}
+	holder, _ := readHolder(path)
+	return holder, true
+}
+
+// readHolder reads the holder recorded in the lock at path
+func readHolder(path string) (Holder, error) {
+	var holder Holder
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return holder, err
+	}
+	err = json.Unmarshal(data, &holder)
+	return holder, err
+}
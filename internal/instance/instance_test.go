@@ -0,0 +1,62 @@
+package instance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flock.lock")
+	if _, running := Running(path); running {
+		t.Errorf("expected no holder before the lock exists")
+	}
+
+	dashboard := Holder{PID: os.Getpid(), Role: RoleDashboard, Session: "work", Started: time.Now()}
+	lock, err := Acquire(path, dashboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// flock(2) locks belong to the open file, so a second open conflicts even in-process
+	_, err = Acquire(path, Holder{PID: 1, Role: RoleDaemon})
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("expected a HeldError, got %v", err)
+	}
+	if held.Holder.PID != dashboard.PID || held.Holder.Role != RoleDashboard || held.Holder.Session != "work" {
+		t.Errorf("expected the dashboard as holder, got %+v", held.Holder)
+	}
+	if holder, running := Running(path); !running || holder.PID != dashboard.PID {
+		t.Errorf("expected the dashboard to be running, got %+v, %v", holder, running)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, running := Running(path); running {
+		t.Errorf("expected no holder after release")
+	}
+	lock, err = Acquire(path, Holder{PID: 1, Role: RoleDaemon})
+	if err != nil {
+		t.Fatalf("expected the lock to be free after release, got %v", err)
+	}
+	lock.Release()
+}
+
+func TestHolderString(t *testing.T) {
+	tests := []struct {
+		holder Holder
+		want   string
+	}{
+		{Holder{PID: 42, Role: RoleDaemon}, "the daemon (pid 42)"},
+		{Holder{PID: 42, Role: RoleDashboard, Session: "work"}, "the dashboard (pid 42, zellij session work)"},
+	}
+	for _, tt := range tests {
+		if got := tt.holder.String(); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
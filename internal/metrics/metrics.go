@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dfowler/flock/internal/git"
+)
+
+// unknownTemplate labels tasks created before templates were recorded
+const unknownTemplate = "(unknown)"
+
+// revertPattern matches the message git writes for `git revert`
+var revertPattern = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,40})`)
+
+// MergedTask identifies a merged task's work in its repository
+type MergedTask struct {
+	TaskID       string
+	Template     string
+	RepoRoot     string
+	Branch       string
+	PreMergeHead string
+	MergeCommit  string
+}
+
+// Outcome describes what happened to a merged task's work afterwards
+type Outcome struct {
+	TaskID   string
+	Template string
+	Reverted bool // a later commit on the default branch reverted the task's work
+	Fixups   int  // later commits that fix up or reference the task's work
+}
+
+// TemplateStats aggregates merge outcomes for one prompt template
+type TemplateStats struct {
+	Template    string
+	Merged      int
+	Reverted    int
+	NeedsFixups int
+}
+
+// CleanRate returns the fraction of merges that were neither reverted nor fixed up
+func (s TemplateStats) CleanRate() float64 {
+	if s.Merged == 0 {
+		return 0
+	}
+	clean := 0
+	if n := s.Merged - s.Reverted - s.NeedsFixups; n > 0 {
+		clean = n
+	}
+	return float64(clean) / float64(s.Merged)
+}
+
+// Analyze inspects the default branch history after a task's merge
+func Analyze(mt MergedTask) (Outcome, error) {
+	if mt.PreMergeHead == "" || mt.MergeCommit == "" {
+		return Outcome{}, fmt.Errorf("task %s has no recorded merge", mt.TaskID)
+	}
+
+	// Commits the merge brought into the default branch
+	merged, err := git.Log(mt.RepoRoot, mt.PreMergeHead, mt.MergeCommit)
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	// Commits that landed on the default branch since
+	defaultBranch, err := git.GetDefaultBranch(mt.RepoRoot)
+	if err != nil {
+		return Outcome{}, err
+	}
+	later, err := git.Log(mt.RepoRoot, mt.MergeCommit, defaultBranch)
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	return classify(mt, merged, later), nil
+}
+
+// classify decides whether later commits reverted or fixed up the merged commits
+func classify(mt MergedTask, merged, later []git.Commit) Outcome {
+	outcome := Outcome{TaskID: mt.TaskID, Template: mt.Template}
+
+	subjects := make(map[string]bool)
+	for _, c := range merged {
+		subjects[c.Subject] = true
+	}
+	isMergedHash := func(prefix string) bool {
+		for _, c := range merged {
+			if strings.HasPrefix(c.Hash, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, c := range later {
+		message := c.Subject + "\n" + c.Body
+
+		// `git revert` records the reverted hash; a plain "Revert" subject is the fallback
+		reverted := false
+		for _, match := range revertPattern.FindAllStringSubmatch(message, -1) {
+			if isMergedHash(match[1]) {
+				reverted = true
+			}
+		}
+		if strings.HasPrefix(c.Subject, `Revert "`) && subjects[strings.TrimSuffix(strings.TrimPrefix(c.Subject, `Revert "`), `"`)] {
+			reverted = true
+		}
+		if reverted {
+			outcome.Reverted = true
+			continue
+		}
+
+		if isFixupOf(c.Subject, subjects) || referencesTask(message, mt) {
+			outcome.Fixups++
+		}
+	}
+
+	return outcome
+}
+
+// isFixupOf reports whether a subject is an autosquash fixup of one of the given subjects
+func isFixupOf(subject string, subjects map[string]bool) bool {
+	for _, prefix := range []string{"fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject, prefix) && subjects[strings.TrimPrefix(subject, prefix)] {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesTask reports whether a commit message mentions the task's branch or ID
+func referencesTask(message string, mt MergedTask) bool {
+	if mt.Branch != "" && strings.Contains(message, mt.Branch) {
+		return true
+	}
+	return mt.TaskID != "" && strings.Contains(message, "Flock-Task: "+mt.TaskID)
+}
+
+// Summarize groups outcomes by template, sorted by template name
+func Summarize(outcomes []Outcome) []TemplateStats {
+	byTemplate := make(map[string]*TemplateStats)
+	for _, o := range outcomes {
+		name := o.Template
+		if name == "" {
+			name = unknownTemplate
+		}
+		stats, ok := byTemplate[name]
+		if !ok {
+			stats = &TemplateStats{Template: name}
+			byTemplate[name] = stats
+		}
+		stats.Merged++
+		if o.Reverted {
+			stats.Reverted++
+		} else if o.Fixups > 0 {
+			stats.NeedsFixups++
+		}
+	}
+
+	result := make([]TemplateStats, 0, len(byTemplate))
+	for _, stats := range byTemplate {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Template < result[j].Template
+	})
+	return result
+}
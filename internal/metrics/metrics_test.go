@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/dfowler/flock/internal/git"
+)
+
+func TestClassify(t *testing.T) {
+	mt := MergedTask{TaskID: "007", Template: "bugfix.md", Branch: "flock-007"}
+	merged := []git.Commit{
+		{Hash: "1111111aaaaaaa", Subject: "Fix login redirect"},
+		{Hash: "2222222bbbbbbb", Subject: "Add login tests"},
+	}
+
+	tests := []struct {
+		name     string
+		later    []git.Commit
+		reverted bool
+		fixups   int
+	}{
+		{"untouched", []git.Commit{{Hash: "3", Subject: "Unrelated change"}}, false, 0},
+		{"git revert", []git.Commit{{Hash: "3", Subject: "Revert something", Body: "This reverts commit 1111111aaaaaaa."}}, true, 0},
+		{"revert subject", []git.Commit{{Hash: "3", Subject: `Revert "Add login tests"`}}, true, 0},
+		{"fixup", []git.Commit{{Hash: "3", Subject: "fixup! Fix login redirect"}}, false, 1},
+		{"branch reference", []git.Commit{{Hash: "3", Subject: "Follow-up to flock-007"}}, false, 1},
+		{"trailer reference", []git.Commit{{Hash: "3", Subject: "Tweak", Body: "Flock-Task: 007"}}, false, 1},
+	}
+
+	for _, tt := range tests {
+		outcome := classify(mt, merged, tt.later)
+		if outcome.Reverted != tt.reverted || outcome.Fixups != tt.fixups {
+			t.Errorf("%s: got reverted=%v fixups=%d, expected reverted=%v fixups=%d",
+				tt.name, outcome.Reverted, outcome.Fixups, tt.reverted, tt.fixups)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	stats := Summarize([]Outcome{
+		{Template: "feature.md"},
+		{Template: "feature.md", Reverted: true},
+		{Template: "feature.md", Fixups: 2},
+		{Template: ""},
+	})
+
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 templates, got %d", len(stats))
+	}
+	if stats[0].Template != unknownTemplate || stats[0].Merged != 1 {
+		t.Errorf("unexpected stats for unknown template: %+v", stats[0])
+	}
+	feature := stats[1]
+	if feature.Merged != 3 || feature.Reverted != 1 || feature.NeedsFixups != 1 {
+		t.Errorf("unexpected stats for feature.md: %+v", feature)
+	}
+	if rate := feature.CleanRate(); rate < 0.33 || rate > 0.34 {
+		t.Errorf("expected clean rate of 1/3, got %f", rate)
+	}
+}
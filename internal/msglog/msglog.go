@@ -0,0 +1,138 @@
+// Package msglog keeps the dashboard's status messages in a fixed-size ring buffer
+// with levels, folding repeats of the same message into one entry.
+package msglog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Level is a message's severity
+type Level int
+
+const (
+	LevelInfo Level = iota
+	LevelWarn
+	LevelError
+)
+
+// DefaultSize is how many messages a log keeps when no size is configured
+const DefaultSize = 50
+
+// String returns the level's config name
+func (l Level) String() string {
+	switch l {
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// ParseLevel parses a level name; empty means info
+func ParseLevel(s string) (Level, error) {
+	switch s {
+	case "", "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown message level %q (expected info, warn or error)", s)
+}
+
+// Entry is a message in the log
+type Entry struct {
+	Text      string
+	Level     Level
+	Timestamp time.Time // When the message was last logged
+	Count     int       // How many times it was logged in a row
+}
+
+// Display returns the entry's text with its repeat count, e.g. "Worktree warning ×4"
+func (e Entry) Display() string {
+	if e.Count > 1 {
+		return fmt.Sprintf("%s ×%d", e.Text, e.Count)
+	}
+	return e.Text
+}
+
+// Log is a ring buffer of messages, safe for concurrent use
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int  // Where the next entry is written
+	full    bool // Whether the buffer has wrapped
+}
+
+// New creates a log keeping the last size messages (DefaultSize if size <= 0)
+func New(size int) *Log {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Log{entries: make([]Entry, size)}
+}
+
+// Add logs a message. A message identical to the latest one bumps its count instead.
+func (l *Log) Add(level Level, text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if last := l.latest(); last != nil && last.Text == text && last.Level == level {
+		last.Count++
+		last.Timestamp = now
+		return
+	}
+	l.entries[l.next] = Entry{Text: text, Level: level, Timestamp: now, Count: 1}
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Recent returns up to n of the newest messages at or above min, oldest first
+func (l *Log) Recent(n int, min Level) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var recent []Entry
+	for i := 1; i <= l.len() && len(recent) < n; i++ {
+		entry := l.entries[(l.next-i+len(l.entries))%len(l.entries)]
+		if entry.Level >= min {
+			recent = append(recent, entry)
+		}
+	}
+	// Collected newest first
+	for i, j := 0, len(recent)-1; i < j; i, j = i+1, j-1 {
+		recent[i], recent[j] = recent[j], recent[i]
+	}
+	return recent
+}
+
+// Len returns how many messages the log holds
+func (l *Log) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.len()
+}
+
+// len returns the number of entries; the caller holds the lock
+func (l *Log) len() int {
+	if l.full {
+		return len(l.entries)
+	}
+	return l.next
+}
+
+// latest returns the newest entry, or nil if the log is empty; the caller holds the lock
+func (l *Log) latest() *Entry {
+	if l.len() == 0 {
+		return nil
+	}
+	return &l.entries[(l.next-1+len(l.entries))%len(l.entries)]
+}
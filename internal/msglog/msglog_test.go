@@ -0,0 +1,49 @@
+package msglog
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	log := New(3)
+	log.Add(LevelInfo, "Created task: a")
+	log.Add(LevelWarn, "Worktree warning")
+	log.Add(LevelWarn, "Worktree warning")
+	log.Add(LevelError, "Merge error")
+	log.Add(LevelInfo, "Created task: b")
+	log.Add(LevelWarn, "Worktree warning")
+
+	if log.Len() != 3 {
+		t.Fatalf("expected the log to keep 3 messages, got %d", log.Len())
+	}
+
+	tests := []struct {
+		n        int
+		min      Level
+		expected []string
+	}{
+		{10, LevelInfo, []string{"Merge error", "Created task: b", "Worktree warning"}},
+		{2, LevelInfo, []string{"Created task: b", "Worktree warning"}},
+		{10, LevelWarn, []string{"Merge error", "Worktree warning"}},
+		{10, LevelError, []string{"Merge error"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range log.Recent(tt.n, tt.min) {
+			got = append(got, e.Text)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("Recent(%d, %s): expected %v, got %v", tt.n, tt.min, tt.expected, got)
+		}
+	}
+
+	// Repeats in a row are folded
+	repeated := New(5)
+	for i := 0; i < 4; i++ {
+		repeated.Add(LevelWarn, "Worktree warning")
+	}
+	if entries := repeated.Recent(5, LevelInfo); len(entries) != 1 || entries[0].Display() != "Worktree warning ×4" {
+		t.Errorf("expected one folded entry, got %+v", entries)
+	}
+}
@@ -0,0 +1,325 @@
+package multiplexer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// DefaultStatusDir is where agent status hooks wrote <task-id>.status files before they
+// were kept per user and session; StatusRoot adds the user to it
+const DefaultStatusDir = "/tmp/flock"
+
+// maxSessionLen keeps session directories short enough for the unix sockets inside them
+const maxSessionLen = 48
+
+// StatusRoot holds a status directory for each session, under one of the user's own so two
+// users on a machine never share status files (/tmp/flock-<uid>). Windows has no uids, but
+// each user has a temp directory of their own, so there it is %TEMP%\flock.
+func StatusRoot() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.TempDir(), "flock")
+	}
+	return fmt.Sprintf("%s-%d", DefaultStatusDir, os.Getuid())
+}
+
+// SessionStatusDir returns the status directory of the zellij or tmux session named
+// session (/tmp/flock-<uid>/<session>), so flock in one session never reads or cleans up
+// another's status files. Characters other than letters, digits, '.', '_' and '-' become
+// '-'; an unknown session uses "default".
+func SessionStatusDir(session string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-') {
+			return r
+		}
+		return '-'
+	}, session)
+	if len(name) > maxSessionLen {
+		name = name[:maxSessionLen]
+	}
+	if strings.Trim(name, ".") == "" {
+		name = "default"
+	}
+	return filepath.Join(StatusRoot(), name)
+}
+
+// ErrTimeout is returned when the multiplexer doesn't answer a command in time, e.g.
+// because its server is busy
+var ErrTimeout = errors.New("multiplexer did not respond")
+
+// Backend drives the terminal multiplexer that hosts agent sessions.
+// A "tab" is a zellij tab or a tmux window.
+type Backend interface {
+	// Name returns the backend's config name (e.g. "zellij", "tmux")
+	Name() string
+	// NewTab opens a tab for a task and starts its agent in it
+	NewTab(l Launch) error
+	// GoToTab switches to the specified tab
+	GoToTab(tabName string) error
+	// GoToController switches back to the flock dashboard tab
+	GoToController() error
+	// CloseTab closes the specified tab (missing tabs are ignored)
+	CloseTab(tabName string) error
+	// DumpTab saves the full scrollback of the tab's agent pane to path
+	DumpTab(tabName, path string) error
+	// WriteChars types text into the tab's agent pane
+	WriteChars(tabName, text string) error
+	// SendKey presses a special key in the tab's agent pane
+	SendKey(tabName string, key Key) error
+	// TabExists checks if a tab with the given name exists
+	TabExists(tabName string) bool
+	// TabNames returns the names of all tabs in the session, in tab order
+	TabNames() ([]string, error)
+	// CurrentTabName returns the name of the focused tab
+	CurrentTabName() (string, error)
+	// ControllerFocused reports whether the dashboard tab is focused
+	ControllerFocused() bool
+	// RenameCurrentTab renames the current tab
+	RenameCurrentTab(name string) error
+	// SetControllerTab sets the name of the dashboard tab
+	SetControllerTab(name string)
+	// StatusDir returns the status directory path
+	StatusDir() string
+	// SetStatusDir changes where agents write their status files
+	SetStatusDir(dir string)
+	// DeleteStatusFile removes the status file for a task
+	DeleteStatusFile(taskID string) error
+}
+
+// Key is a special key that can be sent to an agent pane
+type Key string
+
+const (
+	KeyEnter  Key = "enter"
+	KeyEscape Key = "escape"
+	KeyCtrlC  Key = "ctrl-c"
+)
+
+// Launch describes an agent session to start in a new tab
+type Launch struct {
+	TaskID       string
+	TaskName     string
+	TabName      string
+	Cwd          string
+	PromptOrFile string // Path to the prompt file if IsFile, otherwise inline prompt text
+	IsFile       bool
+	Agent        config.AgentConfig
+	Permission   string                    // Permission mode; adds the agent's flags for it
+	LogPath      string                    // Record the agent's terminal output here (empty disables capture)
+	StatusServer config.StatusServerConfig // Where hooks POST status updates, if enabled
+	StatusEvents bool                      // Hooks send updates to flock's events socket instead of writing files
+	Setup        string                    // Shell command run before the agent; the agent only starts if it succeeds
+	SetupLog     string                    // Save the setup command's output here (empty shows it in the tab only)
+}
+
+// EventSetup is the status event reported when a task's setup command fails
+const EventSetup = "Setup"
+
+// NewLaunch describes how to start a task with the given agent, capturing output to logPath if set
+func NewLaunch(t *task.Task, agent config.AgentConfig, logPath string) Launch {
+	return Launch{
+		TaskID:       t.ID,
+		TaskName:     t.Name,
+		TabName:      t.TabName,
+		Cwd:          t.WorkDir(),
+		PromptOrFile: t.GetPromptOrFile(),
+		IsFile:       t.PromptFile != "",
+		Agent:        agent,
+		Permission:   string(t.Permission),
+		LogPath:      logPath,
+		Setup:        t.Setup,
+	}
+}
+
+// Prompt returns the instruction handed to the agent at launch
+func (l Launch) Prompt() string {
+	if l.IsFile {
+		// Point the agent at the prompt file (@ syntax lets Claude read it directly)
+		return fmt.Sprintf("Review and complete the task described in @%s", l.PromptOrFile)
+	}
+	// Legacy: use inline prompt directly
+	return l.PromptOrFile
+}
+
+// ResumeMessage expands a resume message template for a launch; {{prompt}} becomes the launch prompt
+func ResumeMessage(message string, l Launch) string {
+	return strings.ReplaceAll(message, "{{prompt}}", l.Prompt())
+}
+
+// SendMessage types text into a tab's agent pane and presses Enter, as if the user replied
+func SendMessage(b Backend, tabName, text string) error {
+	if err := b.WriteChars(tabName, text); err != nil {
+		return err
+	}
+	return b.SendKey(tabName, KeyEnter)
+}
+
+// AgentCommand builds the shell command that starts the agent for a launch.
+// Env vars are exported so hook subprocesses see them; the global hooks at
+// ~/.flock/hooks/ check for FLOCK_TASK_ID.
+func AgentCommand(l Launch, statusDir string) string {
+	prompt, promptFile := l.Prompt(), ""
+	if l.IsFile {
+		promptFile = l.PromptOrFile
+	}
+
+	// Single quotes keep the shell from expanding $VAR, $(...) or backticks in the prompt
+	agentCmd := strings.ReplaceAll(l.Agent.Command, "{{prompt}}", shellQuote(prompt))
+	agentCmd = strings.ReplaceAll(agentCmd, "{{prompt_file}}", shellQuote(promptFile))
+	if flags := l.Agent.PermissionFlags[l.Permission]; flags != "" {
+		name, args, _ := strings.Cut(agentCmd, " ")
+		agentCmd = strings.TrimSpace(name + " " + flags + " " + args)
+	}
+
+	env := fmt.Sprintf("FLOCK_TASK_ID=%s FLOCK_TASK_NAME=%s FLOCK_TAB_NAME=%s FLOCK_STATUS_DIR=%s",
+		shellQuote(l.TaskID), shellQuote(l.TaskName), shellQuote(l.TabName), shellQuote(statusDir))
+	if l.StatusServer.Enabled {
+		url, socket := l.StatusServer.URL(statusDir)
+		env += " FLOCK_STATUS_URL=" + shellQuote(url)
+		if socket != "" {
+			env += " FLOCK_STATUS_SOCKET=" + shellQuote(socket)
+		}
+		if l.StatusServer.Token != "" {
+			env += " FLOCK_STATUS_TOKEN=" + shellQuote(l.StatusServer.Token)
+		}
+	}
+	if l.StatusEvents {
+		// Hooks deliver events with `flock status-event`, so they need this binary's path
+		if exe, err := os.Executable(); err == nil {
+			env += fmt.Sprintf(" FLOCK_STATUS_EVENTS=%s FLOCK_BIN=%s", shellQuote(config.EventsSocketPath(statusDir)), shellQuote(exe))
+		}
+	}
+	keys := make([]string, 0, len(l.Agent.Env))
+	for key := range l.Agent.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env += fmt.Sprintf(" %s=%s", key, shellQuote(l.Agent.Env[key]))
+	}
+
+	if l.LogPath != "" {
+		agentCmd = captureCommand(agentCmd, l.LogPath)
+	}
+
+	// Agents without Claude's hooks get their status from the process lifetime,
+	// with a heartbeat keeping a long run from being reported as stalled
+	if l.Agent.StatusHook == config.StatusHookProcess {
+		agentCmd = writeStatusCommand("WORKING", "Launch", "") + " && { " + heartbeatCommand() + " & hb=$!; " +
+			agentCmd + "; kill $hb 2>/dev/null; " + writeStatusCommand("DONE", "Exit", "") + "; }"
+	}
+
+	if strings.TrimSpace(l.Setup) != "" {
+		agentCmd = setupCommand(l.Setup, l.SetupLog) + " && " + agentCmd
+	}
+
+	// Record the pane's shell, so flock can find the agent's processes under it
+	pidFile := `echo $$ > "$FLOCK_STATUS_DIR/$FLOCK_TASK_ID.pid"`
+
+	return fmt.Sprintf("cd %s && export %s && %s && %s", shellQuote(l.Cwd), env, pidFile, agentCmd)
+}
+
+// PIDFilePath returns the file holding the process ID of the shell a task's agent runs in
+func PIDFilePath(statusDir, taskID string) string {
+	return filepath.Join(statusDir, taskID+".pid")
+}
+
+// captureCommand wraps a command in script(1) so its terminal output is recorded
+// to logPath while it keeps running interactively in a pty. The log is appended to,
+// so it can be rotated while the agent runs and a relaunch keeps earlier output.
+func captureCommand(cmd, logPath string) string {
+	quoted := shellQuote(cmd)
+	if runtime.GOOS == "darwin" {
+		return fmt.Sprintf("script -q -a -F %s sh -c %s", shellQuote(logPath), quoted)
+	}
+	return fmt.Sprintf("script -q -a -f -c %s %s", quoted, shellQuote(logPath))
+}
+
+// setupCommand runs a task's setup command in the tab, saving its output to logPath if set.
+// A failure writes a WAITING status with the exit code and stops the agent from starting;
+// the exit code goes through a file because a pipe into tee would hide it.
+func setupCommand(setup, logPath string) string {
+	failed := "{ " + writeStatusCommand("WAITING", EventSetup, `"Setup failed (exit $code)"`) + "; false; }"
+	if logPath == "" {
+		return fmt.Sprintf("{ sh -c %s || { code=$?; %s; }; }", shellQuote(setup), failed)
+	}
+	exitFile, logFile := shellQuote(logPath+".exit"), shellQuote(logPath)
+	return fmt.Sprintf(`{ { sh -c %s 2>&1; echo $? > %s; } | tee %s; code=$(cat %s); rm -f %s; [ "$code" = 0 ] || %s; }`,
+		shellQuote(setup), exitFile, logFile, exitFile, exitFile, failed)
+}
+
+// shellQuote quotes s as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// writeStatusCommand returns a shell command writing a version 2 status file, as the hook script does
+// The task name is the only value that may need escaping for JSON. message, if set, is a shell
+// word for the status's message and must not need escaping either.
+func writeStatusCommand(status, event, message string) string {
+	format, args := `{"version":2,"status":"%s","task_id":"%s","task_name":"%s","updated":%s,"tab_name":"%s","event":"%s"`, ""
+	if message != "" {
+		format, args = format+`,"message":"%s"`, " "+message
+	}
+	return `printf '` + format + `}\n' ` + status +
+		` "$FLOCK_TASK_ID" "$(printf %s "$FLOCK_TASK_NAME" | sed 's/[\\"]/\\&/g')" "$(date +%s)" "$FLOCK_TAB_NAME" ` + event + args +
+		` > "$FLOCK_STATUS_DIR/$FLOCK_TASK_ID.status"`
+}
+
+// heartbeatCommand returns a background loop that refreshes the status file every minute
+// while the launching shell is alive and the task is WORKING (not paused or waiting)
+func heartbeatCommand() string {
+	return `(while sleep 60 && kill -0 $$ 2>/dev/null; do grep -qE '"status":"WORKING"|^status=WORKING' "$FLOCK_STATUS_DIR/$FLOCK_TASK_ID.status" && ` +
+		writeStatusCommand("WORKING", "Heartbeat", "") + `; done)`
+}
+
+// statusFile is the version 2 status file format (see status.Status, which reads it)
+type statusFile struct {
+	Version  int    `json:"version"`
+	Status   string `json:"status"`
+	TaskID   string `json:"task_id"`
+	TaskName string `json:"task_name,omitempty"`
+	Updated  int64  `json:"updated"`
+	TabName  string `json:"tab_name,omitempty"`
+	Event    string `json:"event,omitempty"`
+}
+
+// WriteStatusFile writes a task's status file in statusDir, recording that flock itself set the status
+func WriteStatusFile(statusDir string, t *task.Task, status task.Status) error {
+	content, err := json.Marshal(statusFile{
+		Version:  2,
+		Status:   string(status),
+		TaskID:   t.ID,
+		TaskName: t.Name,
+		Updated:  time.Now().Unix(),
+		TabName:  t.TabName,
+		Event:    "Flock",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write status file: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(statusDir, t.ID+".status"), append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write status file: %w", err)
+	}
+	return nil
+}
+
+// DeleteStatusFile removes a task's status file from statusDir
+func DeleteStatusFile(statusDir, taskID string) error {
+	statusFile := filepath.Join(statusDir, taskID+".status")
+	if err := os.Remove(statusFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete status file: %w", err)
+	}
+	os.Remove(PIDFilePath(statusDir, taskID))
+	return nil
+}
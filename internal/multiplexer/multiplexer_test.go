@@ -0,0 +1,181 @@
+package multiplexer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/config"
+)
+
+func TestSessionStatusDir(t *testing.T) {
+	tests := []struct {
+		session string
+		want    string
+	}{
+		{"fluffy-tiger", "fluffy-tiger"},
+		{"work 2/api", "work-2-api"},
+		{"", "default"},
+		{"..", "default"},
+		{strings.Repeat("a", 60), strings.Repeat("a", maxSessionLen)},
+	}
+	for _, tt := range tests {
+		want := filepath.Join(StatusRoot(), tt.want)
+		if got := SessionStatusDir(tt.session); got != want {
+			t.Errorf("SessionStatusDir(%q): expected %q, got %q", tt.session, want, got)
+		}
+	}
+}
+
+func TestEnsureStatusDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no unix permissions")
+	}
+	// mkdir sets the mode exactly; Mkdir's is masked by the umask
+	mkdir := func(mode os.FileMode) func(string) error {
+		return func(dir string) error {
+			if err := os.Mkdir(dir, mode); err != nil {
+				return err
+			}
+			return os.Chmod(dir, mode)
+		}
+	}
+	tests := []struct {
+		name    string
+		setup   func(dir string) error
+		wantErr string
+	}{
+		{name: "new"},
+		{name: "readable by others", setup: mkdir(0755)},
+		{name: "writable by others", setup: mkdir(0777), wantErr: "can be written by other users"},
+		{name: "file", setup: func(dir string) error { return os.WriteFile(dir, nil, 0600) }, wantErr: "failed to create"},
+		{name: "symlink", setup: func(dir string) error { return os.Symlink(os.TempDir(), dir) }, wantErr: "not a directory"},
+	}
+	for _, tt := range tests {
+		dir := filepath.Join(t.TempDir(), "session")
+		if tt.setup != nil {
+			if err := tt.setup(dir); err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := EnsureStatusDir(dir)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if info, err := os.Stat(dir); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if perm := info.Mode().Perm(); perm != 0700 {
+			t.Errorf("%s: expected mode 0700, got %04o", tt.name, perm)
+		}
+	}
+}
+
+func TestAgentCommand(t *testing.T) {
+	base := Launch{
+		TaskID:       "007",
+		TaskName:     "fix tests",
+		TabName:      "agent-007-fixTests",
+		Cwd:          "/src/app",
+		PromptOrFile: "/home/me/.flock/prompts/007.md",
+		IsFile:       true,
+	}
+
+	tests := []struct {
+		name     string
+		agent    config.AgentConfig
+		logPath  string
+		mode     string
+		setup    string
+		prompt   string // inline prompt instead of the prompt file
+		contains []string
+		excludes []string
+	}{
+		{
+			name:  "claude uses hooks",
+			agent: config.AgentConfig{Command: "claude {{prompt}}", StatusHook: config.StatusHookClaude},
+			contains: []string{
+				`cd '/src/app' && export FLOCK_TASK_ID='007' FLOCK_TASK_NAME='fix tests'`,
+				`echo $$ > "$FLOCK_STATUS_DIR/$FLOCK_TASK_ID.pid" && claude 'Review and complete the task described in @/home/me/.flock/prompts/007.md'`,
+			},
+			excludes: []string{`"status":"%s"`},
+		},
+		{
+			name:  "process status and env",
+			agent: config.AgentConfig{Command: "aider --message-file {{prompt_file}}", Env: map[string]string{"AIDER_MODEL": "gpt-4o"}, StatusHook: config.StatusHookProcess},
+			contains: []string{
+				`AIDER_MODEL='gpt-4o'`,
+				`aider --message-file '/home/me/.flock/prompts/007.md'`,
+				`' WORKING "$FLOCK_TASK_ID"`,
+				`(while sleep 60 && kill -0 $$`,
+				`kill $hb 2>/dev/null; printf '{"version":2,"status":"%s"`,
+				`' DONE "$FLOCK_TASK_ID"`,
+			},
+		},
+		{
+			name:    "output capture",
+			agent:   config.AgentConfig{Command: "codex {{prompt}}", StatusHook: config.StatusHookNone},
+			logPath: "/home/me/.flock/logs/007.log",
+			contains: []string{
+				`script -q`,
+				`'codex '\''Review and complete the task described in @/home/me/.flock/prompts/007.md'\'''`,
+				`'/home/me/.flock/logs/007.log'`,
+			},
+		},
+		{
+			name:     "permission mode",
+			agent:    config.AgentConfig{Command: "claude {{prompt}}", PermissionFlags: map[string]string{"plan": "--permission-mode plan"}},
+			mode:     "plan",
+			contains: []string{`claude --permission-mode plan 'Review and complete`},
+		},
+		{
+			name:  "setup runs first",
+			agent: config.AgentConfig{Command: "claude {{prompt}}"},
+			setup: "cp .env.example .env && npm install",
+			contains: []string{
+				`sh -c 'cp .env.example .env && npm install' || { code=$?;`,
+				`' WAITING "$FLOCK_TASK_ID"`,
+				`Setup "Setup failed (exit $code)"`,
+				`; false; }; }; } && claude 'Review and complete`,
+			},
+		},
+		{
+			name:   "prompt is not expanded",
+			agent:  config.AgentConfig{Command: "claude {{prompt}}"},
+			prompt: "Don't run `make clean` or touch $HOME",
+			contains: []string{
+				`claude 'Don'\''t run ` + "`make clean`" + ` or touch $HOME'`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := base
+		l.Agent = tt.agent
+		l.LogPath = tt.logPath
+		l.Permission = tt.mode
+		l.Setup = tt.setup
+		if tt.prompt != "" {
+			l.PromptOrFile, l.IsFile = tt.prompt, false
+		}
+		cmd := AgentCommand(l, "/tmp/flock")
+		for _, want := range tt.contains {
+			if !strings.Contains(cmd, want) {
+				t.Errorf("%s: expected command to contain %q, got %q", tt.name, want, cmd)
+			}
+		}
+		for _, unwanted := range tt.excludes {
+			if strings.Contains(cmd, unwanted) {
+				t.Errorf("%s: expected command not to contain %q, got %q", tt.name, unwanted, cmd)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package multiplexer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnsureStatusDir creates a status directory readable only by the user. Under StatusRoot,
+// whose /tmp path anyone can predict, the root is checked first: one that belongs to
+// another user, or that others could write to, is refused rather than trusted.
+// Directories flock made before they were private are tightened to 0700.
+func EnsureStatusDir(dir string) error {
+	root := StatusRoot()
+	if rel, err := filepath.Rel(root, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		if err := ensurePrivateDir(root); err != nil {
+			return err
+		}
+	}
+	return ensurePrivateDir(dir)
+}
+
+// ensurePrivateDir creates dir with mode 0700 if needed, then checks that it is a real
+// directory owned by the user that no one else can write to
+func ensurePrivateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create status directory: %w", err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check status directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("status directory %s is not a directory", dir)
+	}
+	if err := checkOwner(dir, info); err != nil {
+		return err
+	}
+	perm := info.Mode().Perm()
+	if perm&0022 != 0 {
+		return fmt.Errorf("status directory %s can be written by other users (mode %04o); remove it so flock can create it again", dir, perm)
+	}
+	if perm&0077 != 0 {
+		if err := os.Chmod(dir, 0700); err != nil {
+			return fmt.Errorf("failed to make %s private: %w", dir, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package multiplexer
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkOwner refuses a status directory that belongs to another user
+func checkOwner(dir string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if uid := os.Getuid(); int(stat.Uid) != uid {
+		return fmt.Errorf("status directory %s belongs to uid %d, not you (uid %d); remove it so flock can create its own", dir, stat.Uid, uid)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package multiplexer
+
+import "os"
+
+// checkOwner accepts any status directory: on Windows it lives in the user's own temp directory
+func checkOwner(dir string, info os.FileInfo) error {
+	return nil
+}
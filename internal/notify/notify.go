@@ -0,0 +1,149 @@
+// Package notify sends desktop notifications through the platform's notifier:
+// notify-send on Linux, terminal-notifier or osascript on macOS.
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// Backend names accepted in the notifications config
+const (
+	BackendAuto             = "auto"
+	BackendNotifySend       = "notify-send"
+	BackendTerminalNotifier = "terminal-notifier"
+	BackendOSAScript        = "osascript"
+	BackendNone             = "none"
+)
+
+// Urgency levels, following notify-send
+const (
+	UrgencyLow      = "low"
+	UrgencyNormal   = "normal"
+	UrgencyCritical = "critical"
+)
+
+// notifyTimeout is how long a notifier may take; a notification isn't worth waiting longer for
+const notifyTimeout = 10 * time.Second
+
+// Notification is a desktop notification
+type Notification struct {
+	Title   string
+	Body    string
+	Urgency string
+	Icon    string // Path to an icon, used where the backend supports one
+}
+
+// Notifier shows desktop notifications
+type Notifier interface {
+	// Name returns the backend's config name
+	Name() string
+	// Notify shows a notification
+	Notify(n Notification) error
+}
+
+// New returns the notifier for a backend name, detecting one for the platform
+// when the name is "auto" or empty. Commands go through r.
+func New(backend string, r runner.Runner) (Notifier, error) {
+	switch backend {
+	case "", BackendAuto:
+		return detect(r), nil
+	case BackendNotifySend:
+		return NotifySend{commands: r}, nil
+	case BackendTerminalNotifier:
+		return TerminalNotifier{commands: r}, nil
+	case BackendOSAScript:
+		return OSAScript{commands: r}, nil
+	case BackendNone:
+		return Nop{}, nil
+	}
+	return nil, fmt.Errorf("unknown notification backend %q (use auto, notify-send, terminal-notifier, osascript or none)", backend)
+}
+
+// detect picks the notifier for the current platform, or Nop if none is installed
+func detect(r runner.Runner) Notifier {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("terminal-notifier"); err == nil {
+			return TerminalNotifier{commands: r}
+		}
+		return OSAScript{commands: r}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if _, err := exec.LookPath("notify-send"); err == nil {
+			return NotifySend{commands: r}
+		}
+	}
+	return Nop{}
+}
+
+// NotifySend notifies through notify-send (libnotify)
+type NotifySend struct {
+	commands runner.Runner
+}
+
+// Name returns "notify-send"
+func (NotifySend) Name() string { return BackendNotifySend }
+
+// Notify runs notify-send with the notification's urgency and icon
+func (s NotifySend) Notify(n Notification) error {
+	args := []string{"-u", n.Urgency}
+	if n.Icon != "" {
+		args = append(args, "-i", n.Icon)
+	}
+	args = append(args, n.Title, n.Body)
+	return runner.Bind(s.commands, "notify-send", args...).WithTimeout(notifyTimeout).Run()
+}
+
+// TerminalNotifier notifies through terminal-notifier on macOS
+type TerminalNotifier struct {
+	commands runner.Runner
+}
+
+// Name returns "terminal-notifier"
+func (TerminalNotifier) Name() string { return BackendTerminalNotifier }
+
+// Notify runs terminal-notifier, grouping flock's notifications and playing a sound for critical ones
+func (s TerminalNotifier) Notify(n Notification) error {
+	args := []string{"-title", n.Title, "-message", n.Body, "-group", "flock"}
+	if n.Urgency == UrgencyCritical {
+		args = append(args, "-sound", "default")
+	}
+	return runner.Bind(s.commands, "terminal-notifier", args...).WithTimeout(notifyTimeout).Run()
+}
+
+// OSAScript notifies through AppleScript's display notification, available on every Mac
+type OSAScript struct {
+	commands runner.Runner
+}
+
+// Name returns "osascript"
+func (OSAScript) Name() string { return BackendOSAScript }
+
+// Notify runs osascript, playing a sound for critical notifications
+func (s OSAScript) Notify(n Notification) error {
+	script := fmt.Sprintf("display notification %s with title %s", appleString(n.Body), appleString(n.Title))
+	if n.Urgency == UrgencyCritical {
+		script += ` sound name "default"`
+	}
+	return runner.Bind(s.commands, "osascript", "-e", script).WithTimeout(notifyTimeout).Run()
+}
+
+// appleString quotes s as an AppleScript string literal
+func appleString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// Nop discards notifications, for platforms without a notifier or when they are turned off
+type Nop struct{}
+
+// Name returns "none"
+func (Nop) Name() string { return BackendNone }
+
+// Notify does nothing
+func (Nop) Notify(Notification) error { return nil }
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+func TestNotify(t *testing.T) {
+	n := Notification{Title: "Flock: Agent Stalled", Body: `"fix-tests" is stuck`, Urgency: UrgencyCritical}
+
+	tests := []struct {
+		backend  string
+		expected string
+	}{
+		{BackendNotifySend, `notify-send -u critical Flock: Agent Stalled "fix-tests" is stuck`},
+		{BackendTerminalNotifier, `terminal-notifier -title Flock: Agent Stalled -message "fix-tests" is stuck -group flock -sound default`},
+		{BackendOSAScript, `osascript -e display notification "\"fix-tests\" is stuck" with title "Flock: Agent Stalled" sound name "default"`},
+		{BackendNone, ""},
+	}
+	for _, tt := range tests {
+		fake := runner.NewFake()
+		notifier, err := New(tt.backend, fake)
+		if err != nil {
+			t.Fatalf("New(%q) failed: %v", tt.backend, err)
+		}
+		if notifier.Name() != tt.backend {
+			t.Errorf("expected backend %s, got %s", tt.backend, notifier.Name())
+		}
+		if err := notifier.Notify(n); err != nil {
+			t.Errorf("%s: Notify failed: %v", tt.backend, err)
+		}
+		got := ""
+		if cmds := fake.Commands(); len(cmds) == 1 {
+			got = cmds[0]
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.backend, tt.expected, got)
+		}
+	}
+
+	if _, err := New("growl", runner.NewFake()); err == nil {
+		t.Errorf("expected an unknown backend to be rejected")
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	event := Event{TaskID: "014", TaskName: "fix-tests", Status: "WAITING", Message: "fix-tests is waiting for input", Branch: "flock-014", Duration: 12 * time.Minute}
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{FormatSlack, `{"text":"fix-tests is waiting for input (flock-014, 12m)"}`},
+		{FormatDiscord, `{"content":"fix-tests is waiting for input (flock-014, 12m)"}`},
+		{"", `{"task_id":"014","task_name":"fix-tests","status":"WAITING","branch":"flock-014","duration_seconds":720,"text":"fix-tests is waiting for input (flock-014, 12m)"}`},
+	}
+	for _, tt := range tests {
+		received = nil
+		if err := PostWebhook(server.URL+"/hook", tt.format, event); err != nil {
+			t.Fatalf("%q: PostWebhook failed: %v", tt.format, err)
+		}
+		if len(received) != 1 || received[0] != tt.expected {
+			t.Errorf("%q: expected %s, got %v", tt.format, tt.expected, received)
+		}
+	}
+
+	if err := PostWebhook(server.URL+"/broken", FormatGeneric, event); err == nil {
+		t.Errorf("expected an error status to be reported")
+	}
+	if format := WebhookFormat("", "https://hooks.slack.com/services/T0/B0/x"); format != FormatSlack {
+		t.Errorf("expected a Slack URL to use the slack format, got %s", format)
+	}
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook payload formats
+const (
+	FormatSlack   = "slack"
+	FormatDiscord = "discord"
+	FormatGeneric = "generic"
+)
+
+// webhookTimeout bounds how long a webhook POST may take
+const webhookTimeout = 10 * time.Second
+
+// Event is a task status change reported to webhooks
+type Event struct {
+	TaskID   string
+	TaskName string
+	Status   string
+	Message  string // Human-readable description, e.g. "fix-tests is waiting for input"
+	Branch   string
+	Duration time.Duration // Time since the task was created
+}
+
+// Text returns the event as a one-line chat message
+// Example: "fix-tests is waiting for input (flock-014, 12m)"
+func (e Event) Text() string {
+	var details []string
+	if e.Branch != "" {
+		details = append(details, e.Branch)
+	}
+	if e.Duration > 0 {
+		details = append(details, shortDuration(e.Duration))
+	}
+	if len(details) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, strings.Join(details, ", "))
+}
+
+// shortDuration formats a duration in minutes or hours, like "12m" or "2h05m"
+func shortDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
+// WebhookFormat returns format, or the format detected from a Slack or Discord URL when empty
+func WebhookFormat(format, endpoint string) string {
+	if format != "" {
+		return format
+	}
+	switch {
+	case strings.Contains(endpoint, "hooks.slack.com"):
+		return FormatSlack
+	case strings.Contains(endpoint, "discord.com/api/webhooks"), strings.Contains(endpoint, "discordapp.com/api/webhooks"):
+		return FormatDiscord
+	}
+	return FormatGeneric
+}
+
+// WebhookPayload builds the JSON body for a webhook format
+func WebhookPayload(format string, e Event) ([]byte, error) {
+	switch format {
+	case FormatSlack:
+		return json.Marshal(map[string]string{"text": e.Text()})
+	case FormatDiscord:
+		return json.Marshal(map[string]string{"content": e.Text()})
+	case FormatGeneric:
+		return json.Marshal(struct {
+			TaskID          string `json:"task_id"`
+			TaskName        string `json:"task_name"`
+			Status          string `json:"status"`
+			Branch          string `json:"branch,omitempty"`
+			DurationSeconds int    `json:"duration_seconds"`
+			Text            string `json:"text"`
+		}{e.TaskID, e.TaskName, e.Status, e.Branch, int(e.Duration.Seconds()), e.Text()})
+	}
+	return nil, fmt.Errorf("unknown webhook format %q (use slack, discord or generic)", format)
+}
+
+// PostWebhook sends an event to a webhook URL in the given format ("" detects it from the URL).
+// Errors leave the URL out, since chat webhook URLs are secrets.
+func PostWebhook(endpoint, format string, e Event) error {
+	body, err := WebhookPayload(WebhookFormat(format, endpoint), e)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Package pathfmt shortens file paths for display in narrow columns.
+package pathfmt
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ellipsis marks the part of a path left out
+const Ellipsis = "…"
+
+// Home replaces the user's home directory at the start of path with "~"
+func Home(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	return homeRelative(path, home)
+}
+
+// homeRelative replaces home at the start of path with "~". Windows paths compare
+// without regard to case.
+func homeRelative(path, home string) string {
+	home = strings.TrimRight(home, `/\`)
+	if len(path) < len(home) || home == "" {
+		return path
+	}
+	prefix := path[:len(home)]
+	if prefix != home && !(runtime.GOOS == "windows" && strings.EqualFold(prefix, home)) {
+		return path
+	}
+	rest := path[len(home):]
+	if rest == "" {
+		return "~"
+	}
+	if !isSeparator(rest[0]) {
+		return path // e.g. /home/bobby under /home/bob
+	}
+	return "~" + rest
+}
+
+// Shorten fits path into width characters for display: the home directory becomes "~",
+// and if that is still too long, leading directories are replaced by "…", keeping as
+// many trailing ones as fit, e.g. ~/p/foo/.flock-worktrees/flock-012 → …/flock-012.
+// Without room for "…/" the last element shows alone, and one that is too long itself
+// keeps its end.
+func Shorten(path string, width int) string {
+	return shorten(Home(path), width)
+}
+
+// shorten is Shorten without the home directory replacement
+func shorten(path string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(path) <= width {
+		return path
+	}
+	parts := strings.FieldsFunc(path, func(r rune) bool { return r < utf8.RuneSelf && isSeparator(byte(r)) })
+	if len(parts) == 0 {
+		return path
+	}
+	sep := separatorIn(path)
+
+	tail := parts[len(parts)-1]
+	for i := len(parts) - 2; i >= 0; i-- {
+		longer := parts[i] + sep + tail
+		if utf8.RuneCountInString(Ellipsis+sep+longer) > width {
+			break
+		}
+		tail = longer
+	}
+	if short := Ellipsis + sep + tail; utf8.RuneCountInString(short) <= width {
+		return short
+	}
+	if utf8.RuneCountInString(tail) <= width {
+		return tail // No room for the ellipsis; the last element alone still says the most
+	}
+
+	// Only the end of the last element fits
+	if width == 1 {
+		return Ellipsis
+	}
+	runes := []rune(tail)
+	return Ellipsis + string(runes[len(runes)-(width-1):])
+}
+
+// isSeparator reports whether c separates path elements; Windows accepts both slashes
+func isSeparator(c byte) bool {
+	return c == '/' || c == filepath.Separator
+}
+
+// separatorIn returns the separator path uses, so a shortened path reads like the original
+func separatorIn(path string) string {
+	if filepath.Separator != '/' && strings.ContainsRune(path, filepath.Separator) {
+		return string(filepath.Separator)
+	}
+	return "/"
+}
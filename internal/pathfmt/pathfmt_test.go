@@ -0,0 +1,40 @@
+package pathfmt
+
+import "testing"
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		path  string
+		width int
+		want  string
+	}{
+		{"~/src/flock", 20, "~/src/flock"},
+		{"~/p/foo/.flock-worktrees/flock-012", 20, "…/flock-012"},
+		{"~/p/foo/.flock-worktrees/flock-012", 30, "…/.flock-worktrees/flock-012"},
+		{"/var/lib/projects/api/.flock-worktrees/flock-007", 34, "…/api/.flock-worktrees/flock-007"},
+		{"/tmp/demo/project", 8, "project"},
+		{"/srv/a-very-long-directory-name", 12, "…ectory-name"},
+		{"/srv/x", 0, "/srv/x"},
+	}
+	for _, tt := range tests {
+		if got := shorten(tt.path, tt.width); got != tt.want {
+			t.Errorf("shorten(%q, %d): expected %q, got %q", tt.path, tt.width, tt.want, got)
+		}
+	}
+}
+
+func TestHomeRelative(t *testing.T) {
+	tests := []struct {
+		path, home, want string
+	}{
+		{"/home/bob/src/flock", "/home/bob", "~/src/flock"},
+		{"/home/bob", "/home/bob/", "~"},
+		{"/home/bobby/src", "/home/bob", "/home/bobby/src"},
+		{"/tmp/flock", "/home/bob", "/tmp/flock"},
+	}
+	for _, tt := range tests {
+		if got := homeRelative(tt.path, tt.home); got != tt.want {
+			t.Errorf("homeRelative(%q, %q): expected %q, got %q", tt.path, tt.home, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package proc reads the resource use of running processes from ps(1), which reports the
+// same columns on Linux and macOS, to watch the process tree an agent runs in.
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// Process is one running process
+type Process struct {
+	PID     int
+	PPID    int
+	CPU     float64 // Percent of one core
+	RSS     int64   // Resident memory in bytes
+	Command string
+}
+
+// Table is a snapshot of the running processes, by PID
+type Table map[int]Process
+
+// Snapshot lists the running processes
+func Snapshot(r runner.Runner) (Table, error) {
+	output, err := r.Output(context.Background(), runner.Command("ps", "-A", "-o", "pid=,ppid=,pcpu=,rss=,comm="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	return parse(string(output)), nil
+}
+
+// parse reads ps output with pid, ppid, pcpu, rss (KB) and command columns
+func parse(output string) Table {
+	table := make(Table)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		cpu, err3 := strconv.ParseFloat(fields[2], 64)
+		rss, err4 := strconv.ParseInt(fields[3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+		// macOS reports the full path, which may contain spaces
+		command := filepath.Base(strings.Join(fields[4:], " "))
+		table[pid] = Process{PID: pid, PPID: ppid, CPU: cpu, RSS: rss * 1024, Command: command}
+	}
+	return table
+}
+
+// Alive reports whether pid is running
+func (t Table) Alive(pid int) bool {
+	_, ok := t[pid]
+	return ok
+}
+
+// Descendants returns the processes started under pid, directly or not, in PID order
+func (t Table) Descendants(pid int) []Process {
+	children := make(map[int][]int)
+	for _, p := range t {
+		if p.PID != p.PPID {
+			children[p.PPID] = append(children[p.PPID], p.PID)
+		}
+	}
+	var found []Process
+	queue := children[pid]
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		found = append(found, t[next])
+		queue = append(queue, children[next]...)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].PID < found[j].PID })
+	return found
+}
+
+// Usage is the combined resource use of a process tree
+type Usage struct {
+	Processes int
+	CPU       float64 // Percent of one core, summed over the processes
+	RSS       int64   // Resident memory in bytes, summed
+	Command   string  // The process using the most memory, usually the agent itself
+}
+
+// Usage totals what the processes under pid use. The shell at pid itself is left out;
+// it only waits for the agent.
+func (t Table) Usage(pid int) Usage {
+	var u Usage
+	var top int64 = -1
+	for _, p := range t.Descendants(pid) {
+		u.Processes++
+		u.CPU += p.CPU
+		u.RSS += p.RSS
+		if p.RSS > top {
+			top, u.Command = p.RSS, p.Command
+		}
+	}
+	return u
+}
+
+// ReadPID reads a process ID written to a file, e.g. by `echo $$ > file`
+func ReadPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid process ID in %s", path)
+	}
+	return pid, nil
+}
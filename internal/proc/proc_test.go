@@ -0,0 +1,31 @@
+package proc
+
+import "testing"
+
+func TestUsage(t *testing.T) {
+	table := parse(`    1     0   0.0  1024 init
+  100     1   0.0  2048 zsh
+  200   100   1.5 10240 script
+  201   200  40.0 204800 node
+  202   201   2.5 51200 /Applications/Some App.app/helper
+  300     1   9.0  4096 other
+garbage line
+`)
+	if len(table) != 6 {
+		t.Fatalf("expected 6 processes, got %d", len(table))
+	}
+
+	u := table.Usage(100)
+	if u.Processes != 3 || u.CPU != 44 || u.RSS != (10240+204800+51200)*1024 || u.Command != "node" {
+		t.Errorf("expected the 3 processes under the shell, got %+v", u)
+	}
+	if p := table[202]; p.Command != "helper" {
+		t.Errorf("expected the command's base name, got %q", p.Command)
+	}
+	if u := table.Usage(300); u.Processes != 0 {
+		t.Errorf("expected nothing under a process without children, got %+v", u)
+	}
+	if !table.Alive(100) || table.Alive(999) {
+		t.Errorf("expected only running processes to be alive")
+	}
+}
@@ -0,0 +1,74 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Markers around the flock-managed attachment list in a prompt's Context section
+const (
+	attachmentsStart = "<!-- flock:attachments -->"
+	attachmentsEnd   = "<!-- /flock:attachments -->"
+)
+
+// IsURL reports whether an attachment is a link rather than a file path
+func IsURL(attachment string) bool {
+	return strings.HasPrefix(attachment, "http://") || strings.HasPrefix(attachment, "https://")
+}
+
+// ApplyAttachments rewrites the attachment list in a prompt file's Context section
+func ApplyAttachments(promptFile string, attachments []string) error {
+	content, err := os.ReadFile(promptFile)
+	if err != nil {
+		return fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	updated := insertAttachments(string(content), attachments)
+	if err := os.WriteFile(promptFile, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	return nil
+}
+
+// insertAttachments replaces the attachment block in content, placing it at the
+// top of the Context section (which is appended if the prompt has none)
+func insertAttachments(content string, attachments []string) string {
+	// Drop the previous block
+	if start := strings.Index(content, attachmentsStart); start != -1 {
+		if end := strings.Index(content[start:], attachmentsEnd); end != -1 {
+			end += start + len(attachmentsEnd)
+			for i := 0; i < 2 && end < len(content) && content[end] == '\n'; i++ {
+				end++
+			}
+			content = content[:start] + content[end:]
+		}
+	}
+	if len(attachments) == 0 {
+		return content
+	}
+
+	var block strings.Builder
+	block.WriteString(attachmentsStart + "\n")
+	for _, attachment := range attachments {
+		if IsURL(attachment) {
+			block.WriteString("- " + attachment + "\n")
+		} else {
+			block.WriteString("- `" + attachment + "`\n")
+		}
+	}
+	block.WriteString(attachmentsEnd + "\n\n")
+
+	const header = "## Context\n"
+	if idx := strings.Index(content, header); idx != -1 {
+		pos := idx + len(header)
+		if pos < len(content) && content[pos] == '\n' {
+			pos++
+		}
+		return content[:pos] + block.String() + content[pos:]
+	}
+
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + "\n" + header + "\n" + strings.TrimSuffix(block.String(), "\n")
+}
@@ -0,0 +1,45 @@
+package prompt
+
+import "testing"
+
+func TestInsertAttachments(t *testing.T) {
+	template := "# Task: x\n\n## Goal\n\nDo it\n\n## Context\n\n\n## Constraints\n\n"
+
+	tests := []struct {
+		name        string
+		content     string
+		attachments []string
+		expected    string
+	}{
+		{
+			name:        "insert into context section",
+			content:     template,
+			attachments: []string{"docs/design.md", "https://example.com/spec"},
+			expected:    "# Task: x\n\n## Goal\n\nDo it\n\n## Context\n\n<!-- flock:attachments -->\n- `docs/design.md`\n- https://example.com/spec\n<!-- /flock:attachments -->\n\n\n## Constraints\n\n",
+		},
+		{
+			name:        "remove all attachments restores the template",
+			content:     "# Task: x\n\n## Goal\n\nDo it\n\n## Context\n\n<!-- flock:attachments -->\n- `a.md`\n<!-- /flock:attachments -->\n\n\n## Constraints\n\n",
+			attachments: nil,
+			expected:    template,
+		},
+		{
+			name:        "append context section when missing",
+			content:     "Fix the bug",
+			attachments: []string{"main.go"},
+			expected:    "Fix the bug\n\n## Context\n\n<!-- flock:attachments -->\n- `main.go`\n<!-- /flock:attachments -->\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := insertAttachments(tt.content, tt.attachments); got != tt.expected {
+			t.Errorf("%s: got %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+
+	// Re-applying the same list is stable
+	once := insertAttachments(template, []string{"a.md"})
+	if twice := insertAttachments(once, []string{"a.md"}); twice != once {
+		t.Errorf("expected reapplying attachments to be stable, got %q", twice)
+	}
+}
@@ -0,0 +1,77 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Markers around the flock-managed handoff section at the end of a prompt
+const (
+	handoffStart = "<!-- flock:handoff -->"
+	handoffEnd   = "<!-- /flock:handoff -->"
+)
+
+// Handoff is what a finished task passes on to the task that continues its work
+type Handoff struct {
+	TaskID       string
+	Name         string
+	Branch       string // The task's branch ("" without one)
+	Diff         string // Commit count and diffstat of the task's changes
+	FinalMessage string // What the agent said when it finished ("" if not reported)
+}
+
+// ApplyHandoff rewrites the handoff section of a prompt file
+func ApplyHandoff(promptFile string, handoffs []Handoff) error {
+	content, err := os.ReadFile(promptFile)
+	if err != nil {
+		return fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	updated := insertHandoff(string(content), handoffs)
+	if err := os.WriteFile(promptFile, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	return nil
+}
+
+// insertHandoff replaces the handoff section in content, appending it to the end
+func insertHandoff(content string, handoffs []Handoff) string {
+	// Drop the previous section
+	if start := strings.Index(content, handoffStart); start != -1 {
+		if end := strings.Index(content[start:], handoffEnd); end != -1 {
+			end += start + len(handoffEnd)
+			for end < len(content) && content[end] == '\n' {
+				end++
+			}
+			content = strings.TrimRight(content[:start], "\n") + "\n" + content[end:]
+		}
+	}
+	if len(handoffs) == 0 {
+		return content
+	}
+
+	var b strings.Builder
+	b.WriteString(handoffStart + "\n")
+	b.WriteString("## Handoff\n\n")
+	b.WriteString("This task continues the work of the tasks below. Review what they did before you start.\n")
+	for _, h := range handoffs {
+		b.WriteString(fmt.Sprintf("\n### %s: %s\n\n", h.TaskID, h.Name))
+		if h.Branch != "" {
+			b.WriteString(fmt.Sprintf("Branch: `%s`\n\n", h.Branch))
+		}
+		if diff := strings.TrimSpace(h.Diff); diff != "" {
+			b.WriteString("Changes:\n\n```\n" + diff + "\n```\n\n")
+		}
+		if message := strings.TrimSpace(h.FinalMessage); message != "" {
+			b.WriteString("Final message:\n\n> " + strings.ReplaceAll(message, "\n", "\n> ") + "\n")
+		} else {
+			b.WriteString("No final message was recorded.\n")
+		}
+	}
+	b.WriteString(handoffEnd + "\n")
+
+	if content = strings.TrimRight(content, "\n"); content != "" {
+		content += "\n\n"
+	}
+	return content + b.String()
+}
@@ -0,0 +1,41 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInsertHandoff(t *testing.T) {
+	content := "# Task: review\n\n## Goal\n\nReview the changes\n"
+	handoffs := []Handoff{{
+		TaskID:       "003",
+		Name:         "add-login",
+		Branch:       "flock-003-add-login",
+		Diff:         "1 commit(s)\n login.go | 12 ++++++++++++\n",
+		FinalMessage: "Added the login endpoint.\nTests pass.",
+	}}
+
+	got := insertHandoff(content, handoffs)
+	for _, want := range []string{
+		content + "\n" + handoffStart + "\n## Handoff\n",
+		"### 003: add-login\n\nBranch: `flock-003-add-login`\n",
+		"```\n1 commit(s)\n login.go | 12 ++++++++++++\n```\n",
+		"> Added the login endpoint.\n> Tests pass.\n" + handoffEnd + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+
+	// Re-applying replaces the section, and an empty list removes it
+	if again := insertHandoff(got, handoffs); again != got {
+		t.Errorf("expected reapplying the handoff to be stable, got %q", again)
+	}
+	if removed := insertHandoff(got, nil); removed != content {
+		t.Errorf("expected the handoff to be removed, got %q", removed)
+	}
+
+	if got := insertHandoff(content, []Handoff{{TaskID: "004", Name: "docs"}}); !strings.Contains(got, "No final message was recorded.") {
+		t.Errorf("expected a note about the missing final message, got %q", got)
+	}
+}
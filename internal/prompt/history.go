@@ -0,0 +1,119 @@
+package prompt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+const (
+	historyDir       = "history"
+	historyIndexFile = "index.json"
+)
+
+// Snapshot labels recorded for a task's prompt file
+const (
+	SnapshotCreated = "created"
+	SnapshotEdited  = "edited"
+	SnapshotLaunch  = "launch"
+)
+
+// Version is one recorded state of a task's prompt file.
+// Versions whose content did not change share the same snapshot file.
+type Version struct {
+	Number int       `json:"number"`
+	Label  string    `json:"label"`
+	Time   time.Time `json:"time"`
+	File   string    `json:"file"` // Snapshot file name within the task's history dir
+}
+
+// historyPath returns the directory holding a task's prompt snapshots
+func (m *Manager) historyPath(taskID string) string {
+	return filepath.Join(m.config.PromptsDir, historyDir, taskID)
+}
+
+// Versions returns the recorded versions of a task's prompt, oldest first
+func (m *Manager) Versions(taskID string) ([]Version, error) {
+	data, err := os.ReadFile(filepath.Join(m.historyPath(taskID), historyIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read prompt history: %w", err)
+	}
+
+	var versions []Version
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt history: %w", err)
+	}
+	return versions, nil
+}
+
+// Snapshot records the current prompt file as a new version.
+// The content is only copied when it differs from the latest snapshot.
+func (m *Manager) Snapshot(taskID, promptFile, label string) error {
+	content, err := m.config.Vault().ReadFile(promptFile)
+	if err != nil {
+		return fmt.Errorf("failed to read prompt file: %w", err)
+	}
+
+	versions, err := m.Versions(taskID)
+	if err != nil {
+		return err
+	}
+
+	dir := m.historyPath(taskID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create prompt history dir: %w", err)
+	}
+
+	version := Version{Number: len(versions) + 1, Label: label, Time: time.Now()}
+	if n := len(versions); n > 0 {
+		last, err := m.config.Vault().ReadFile(filepath.Join(dir, versions[n-1].File))
+		if err == nil && bytes.Equal(last, content) {
+			version.File = versions[n-1].File
+		}
+	}
+	if version.File == "" {
+		version.File = fmt.Sprintf("v%d.md", version.Number)
+		if err := os.WriteFile(filepath.Join(dir, version.File), content, 0644); err != nil {
+			return fmt.Errorf("failed to write prompt snapshot: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(append(versions, version), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, historyIndexFile), data, 0644)
+}
+
+// VersionPath returns the snapshot file backing a version
+func (m *Manager) VersionPath(taskID string, v Version) string {
+	return filepath.Join(m.historyPath(taskID), v.File)
+}
+
+// DiffVersion returns a unified diff between a recorded version and the current prompt file
+// An empty string means the prompt is unchanged since that version.
+func (m *Manager) DiffVersion(taskID string, v Version, promptFile string) (string, error) {
+	cmd := runner.Bind(m.commands, "git", "diff", "--no-index", "--no-color", "--",
+		m.VersionPath(taskID, v), promptFile)
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 just means the files differ
+		if runner.ExitCode(err) != 1 {
+			return "", fmt.Errorf("failed to diff prompt versions: %w", err)
+		}
+	}
+	return string(output), nil
+}
+
+// DeleteHistory removes all recorded versions of a task's prompt
+func (m *Manager) DeleteHistory(taskID string) error {
+	return os.RemoveAll(m.historyPath(taskID))
+}
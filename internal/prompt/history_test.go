@@ -0,0 +1,55 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dfowler/flock/internal/config"
+)
+
+func TestSnapshotSharesUnchangedContent(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(&config.Config{PromptsDir: dir})
+	promptFile := filepath.Join(dir, "001.md")
+
+	steps := []struct {
+		content string
+		label   string
+		file    string
+	}{
+		{"first", SnapshotCreated, "v1.md"},
+		{"first", SnapshotLaunch, "v1.md"}, // unchanged content reuses the snapshot
+		{"second", SnapshotEdited, "v3.md"},
+	}
+
+	for _, step := range steps {
+		if err := os.WriteFile(promptFile, []byte(step.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Snapshot("001", promptFile, step.label); err != nil {
+			t.Fatalf("Snapshot(%s) failed: %v", step.label, err)
+		}
+	}
+
+	versions, err := m.Versions("001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != len(steps) {
+		t.Fatalf("expected %d versions, got %d", len(steps), len(versions))
+	}
+	for i, step := range steps {
+		if versions[i].Label != step.label || versions[i].File != step.file {
+			t.Errorf("version %d: got %s/%s, expected %s/%s", i+1, versions[i].Label, versions[i].File, step.label, step.file)
+		}
+	}
+
+	diff, err := m.DiffVersion("001", versions[1], promptFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff == "" {
+		t.Errorf("expected a diff between launch and current prompt")
+	}
+}
@@ -5,11 +5,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/runner"
 )
 
+// DefaultTemplateName is the template file used for new tasks
+const DefaultTemplateName = "default.md"
+
 const defaultTemplateContent = `# Task: {{name}}
 # Working Directory: {{working_dir}}
 
@@ -21,16 +26,25 @@ const defaultTemplateContent = `# Task: {{name}}
 
 ## Constraints
 
+
+## Progress Notes
+- Keep short working notes in .flock/notes.md (create it; don't commit it): what you did, what's next, anything blocking. Update it as you go; flock shows it live.
 `
 
 // Manager handles prompt file operations
 type Manager struct {
-	config *config.Config
+	config   *config.Config
+	commands runner.Runner // runs the editor and prompt diffs
 }
 
 // NewManager creates a new prompt manager
 func NewManager(cfg *config.Config) *Manager {
-	return &Manager{config: cfg}
+	return &Manager{config: cfg, commands: runner.Exec{}}
+}
+
+// SetRunner replaces the runner used for the editor and prompt diffs
+func (m *Manager) SetRunner(r runner.Runner) {
+	m.commands = r
 }
 
 // EnsureProjectTemplate creates the project-specific template in .claude/flock/templates/
@@ -45,20 +59,26 @@ func (m *Manager) EnsureProjectTemplate(projectDir string) (string, error) {
 	}
 
 	// Create .claude/flock/templates directory if needed
-	templatesDir := filepath.Join(projectDir, ".claude", "flock", "templates")
+	templatesDir := TemplatesDir(projectDir)
 	if err := os.MkdirAll(templatesDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create templates directory: %w", err)
 	}
 
-	templatePath := filepath.Join(templatesDir, "default.md")
+	templatePath := filepath.Join(templatesDir, DefaultTemplateName)
 
 	// Check if template already exists
 	if _, err := os.Stat(templatePath); err == nil {
 		return templatePath, nil
 	}
 
-	// Create the default template
-	if err := os.WriteFile(templatePath, []byte(defaultTemplateContent), 0644); err != nil {
+	// Create the default template, from the team's shared default if there is one
+	content := []byte(defaultTemplateContent)
+	if shared := m.sharedTemplatePath(DefaultTemplateName); shared != "" {
+		if data, err := os.ReadFile(shared); err == nil {
+			content = data
+		}
+	}
+	if err := os.WriteFile(templatePath, content, 0644); err != nil {
 		return "", fmt.Errorf("failed to write template: %w", err)
 	}
 
@@ -72,33 +92,53 @@ func (m *Manager) CreatePromptFile(taskID, taskName, workingDir string) (string,
 
 // CreatePromptFileWithGoal creates a new prompt file from the template with an optional goal
 func (m *Manager) CreatePromptFileWithGoal(taskID, taskName, workingDir, goal string) (string, error) {
+	return m.CreatePromptFileFromTemplate(DefaultTemplateName, m.TaskVars(taskID, taskName, workingDir, goal, false))
+}
+
+// TemplateFileName normalizes a template name ("bugfix" or "bugfix.md") to its file name
+// An empty name selects the default template
+func TemplateFileName(name string) string {
+	if name == "" {
+		return DefaultTemplateName
+	}
+	name = filepath.Base(name)
+	if filepath.Ext(name) != ".md" {
+		name += ".md"
+	}
+	return name
+}
+
+// CreatePromptFileFromTemplate creates a new prompt file from a named project template
+// (e.g. "bugfix.md" in .claude/flock/templates/), filling in the task's variables
+func (m *Manager) CreatePromptFileFromTemplate(template string, vars Vars) (string, error) {
 	// Ensure project template exists and get its path
-	templatePath, err := m.EnsureProjectTemplate(workingDir)
+	templatePath, err := m.EnsureProjectTemplate(vars.WorkingDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to ensure template: %w", err)
 	}
+	if template = TemplateFileName(template); template != DefaultTemplateName {
+		templatePath, _ = m.TemplatePath(vars.WorkingDir, template)
+	}
 
-	// Read template
+	// Read template, falling back to the built-in review template
 	templateContent, err := os.ReadFile(templatePath)
+	if os.IsNotExist(err) && template == ReviewTemplateName {
+		templateContent, err = []byte(reviewTemplateContent), nil
+	}
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("template %s not found in %s", template, filepath.Dir(templatePath))
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to read template: %w", err)
 	}
 
-	// Replace placeholders
-	content := string(templateContent)
-	content = strings.ReplaceAll(content, "{{name}}", taskName)
-	content = strings.ReplaceAll(content, "{{working_dir}}", workingDir)
-
-	// If goal is provided, insert it into the Goal section
-	if goal != "" {
-		// Find the Goal section and insert the goal text after it
-		goalSection := "## Goal\n\n"
-		goalInsert := "## Goal\n\n" + goal + "\n\n"
-		content = strings.Replace(content, goalSection, goalInsert, 1)
+	content, err := Render(string(templateContent), vars)
+	if err != nil {
+		return "", fmt.Errorf("template %s: %w", template, err)
 	}
 
 	// Write prompt file
-	promptPath := m.config.PromptFilePath(taskID)
+	promptPath := m.config.PromptFilePath(vars.TaskID)
 	if err := os.WriteFile(promptPath, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("failed to write prompt file: %w", err)
 	}
@@ -106,11 +146,48 @@ func (m *Manager) CreatePromptFileWithGoal(taskID, taskName, workingDir, goal st
 	return promptPath, nil
 }
 
+// Summary returns the first line of a prompt's Goal section, or of the prompt itself
+// when it has no Goal section (inline prompts)
+func Summary(content string) string {
+	inGoal, hasGoal := false, strings.Contains(content, "## Goal")
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			inGoal = line == "## Goal"
+			continue
+		}
+		if line != "" && (inGoal || !hasGoal) {
+			return line
+		}
+	}
+	return ""
+}
+
+// Goal returns a prompt's whole Goal section, or the whole prompt when it has no Goal
+// section (inline prompts)
+func Goal(content string) string {
+	if !strings.Contains(content, "## Goal") {
+		return strings.TrimSpace(content)
+	}
+	var lines []string
+	inGoal := false
+	for _, line := range strings.Split(content, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "#") {
+			inGoal = trimmed == "## Goal"
+			continue
+		}
+		if inGoal {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
 // OpenInEditor opens the prompt file in the user's editor and blocks until closed
 func (m *Manager) OpenInEditor(promptPath string) error {
 	editor := getEditor()
 
-	cmd := exec.Command(editor, promptPath)
+	cmd := m.commands.Interactive(runner.Command(editor, promptPath))
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -156,22 +233,76 @@ func (m *Manager) DeletePromptFile(taskID string) error {
 	return nil
 }
 
-// ListTemplates returns available template files for a given project directory
+// TemplatesDir returns the directory holding a project's prompt templates
+func TemplatesDir(projectDir string) string {
+	return filepath.Join(projectDir, ".claude", "flock", "templates")
+}
+
+// ListTemplates returns available template files for a given project directory,
+// followed by the shared templates the project doesn't override
 func (m *Manager) ListTemplates(projectDir string) ([]string, error) {
-	templatesDir := filepath.Join(projectDir, ".claude", "flock", "templates")
-	entries, err := os.ReadDir(templatesDir)
+	templates, err := listTemplateFiles(TemplatesDir(projectDir))
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No templates directory yet
-		}
 		return nil, err
 	}
-
-	var templates []string
-	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".md" {
-			templates = append(templates, entry.Name())
+	if dir := m.SharedTemplatesDir(); dir != "" {
+		shared, err := listTemplateFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range shared {
+			if !slices.Contains(templates, name) {
+				templates = append(templates, name)
+			}
 		}
 	}
 	return templates, nil
 }
+
+// CreateTemplate adds a template to a project, starting from a copy of the shared template
+// of that name, the project's default template, or the built-in review template for
+// review.md. Returns the new template's path.
+func (m *Manager) CreateTemplate(projectDir, name string) (string, error) {
+	name = TemplateFileName(name)
+	defaultPath, err := m.EnsureProjectTemplate(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to ensure template: %w", err)
+	}
+	path := filepath.Join(filepath.Dir(defaultPath), name)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("template %s already exists", name)
+	}
+
+	// A shared template of the same name is copied, so the project can customize it
+	source := defaultPath
+	if shared := m.sharedTemplatePath(name); shared != "" {
+		source = shared
+	}
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+	if name == ReviewTemplateName && source == defaultPath {
+		content = []byte(reviewTemplateContent)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write template: %w", err)
+	}
+	return path, nil
+}
+
+// DeleteTemplate removes one of a project's templates. The default template can't be
+// deleted, since new tasks fall back to it.
+func (m *Manager) DeleteTemplate(projectDir, name string) error {
+	name = TemplateFileName(name)
+	if name == DefaultTemplateName {
+		return fmt.Errorf("the default template can't be deleted")
+	}
+	if _, shared := m.TemplatePath(projectDir, name); shared {
+		return fmt.Errorf("%s is a shared template; remove it from %s", name, m.config.Templates.Repo)
+	}
+	if err := os.Remove(filepath.Join(TemplatesDir(projectDir), name)); err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
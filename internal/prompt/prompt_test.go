@@ -0,0 +1,77 @@
+package prompt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"goal section", "# Task: x\n# Working Directory: /src\n\n## Goal\n\nMake the tests pass\nand keep them fast\n\n## Context\n\nCI is red\n", "Make the tests pass"},
+		{"empty goal", "# Task: x\n\n## Goal\n\n\n## Context\n\nCI is red\n", ""},
+		{"inline prompt", "Fix the login bug\n", "Fix the login bug"},
+	}
+	for _, tt := range tests {
+		if got := Summary(tt.content); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestGoal(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"goal section", "# Task: x\n\n## Goal\n\nMake the tests pass\nand keep them fast\n\n## Context\n\nCI is red\n", "Make the tests pass\nand keep them fast"},
+		{"empty goal", "# Task: x\n\n## Goal\n\n\n## Context\n\nCI is red\n", ""},
+		{"inline prompt", "Fix the login bug\n\nIt redirects twice\n", "Fix the login bug\n\nIt redirects twice"},
+	}
+	for _, tt := range tests {
+		if got := Goal(tt.content); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	vars := Vars{
+		TaskID:     "007",
+		Name:       "fix-login",
+		WorkingDir: "/src/app",
+		RepoRoot:   "/src/app",
+		Branch:     "flock-007",
+		Goal:       "Fix the redirect",
+		Date:       time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name     string
+		content  string
+		vars     Vars
+		expected string
+	}{
+		{"variables", "# {{task_id}} {{name}} in {{working_dir}} ({{repo_root}}, {{branch}}) on {{date}}\n", vars, "# 007 fix-login in /src/app (/src/app, flock-007) on 2025-03-02\n"},
+		{"fields", "{{.Name}} on {{.Branch}}\n", vars, "fix-login on flock-007\n"},
+		{"goal variable", "## Goal\n\n{{goal}}\n", vars, "## Goal\n\nFix the redirect\n"},
+		{"goal inserted under heading", "## Goal\n\n## Context\n", vars, "## Goal\n\nFix the redirect\n\n## Context\n"},
+		{"optional section kept", "{{if branch}}Branch: {{branch}}\n{{end}}done\n", vars, "Branch: flock-007\ndone\n"},
+		{"optional section dropped", "{{if branch}}Branch: {{branch}}\n{{end}}done\n", Vars{}, "done\n"},
+	}
+	for _, tt := range tests {
+		got, err := Render(tt.content, tt.vars)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		} else if got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+
+	if _, err := Render("{{unknown}}", vars); err == nil {
+		t.Errorf("expected an error for an unknown variable")
+	}
+}
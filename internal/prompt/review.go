@@ -0,0 +1,48 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReviewTemplateName is the template reviewer tasks are created from. Projects can
+// replace the built-in one with their own review.md.
+const ReviewTemplateName = "review.md"
+
+// maxReviewDiffBytes caps the diff put in a review prompt, so a huge change doesn't
+// crowd out the instructions
+const maxReviewDiffBytes = 100 * 1024
+
+const reviewTemplateContent = `# Review: {{name}}
+# Working Directory: {{working_dir}}
+
+## Goal
+
+Review the changes on {{if branch}}` + "`{{branch}}`" + `{{else}}this branch{{end}} below as a careful senior reviewer would: look for bugs, missing tests, unclear code and anything that doesn't fit the surrounding code.
+
+## Constraints
+- Don't edit, commit or revert anything: this directory belongs to the agent that made the changes
+- Read any file you need and run the tests if that helps
+- Finish with your findings as a list, most important first, each with its file and line. Say so plainly if the changes look good.
+
+## Changes
+
+` + "````diff" + `
+{{diff}}
+` + "````" + `
+`
+
+// ReviewDiff trims a diff for a review prompt to a size an agent can take in at once,
+// cutting at a line and saying how much was left out
+func ReviewDiff(diff string) string {
+	diff = strings.TrimRight(diff, "\n")
+	if len(diff) <= maxReviewDiffBytes {
+		return diff
+	}
+	cut := strings.LastIndex(diff[:maxReviewDiffBytes], "\n")
+	if cut < 0 {
+		cut = maxReviewDiffBytes
+	}
+	omitted := strings.Count(diff[cut:], "\n")
+	return diff[:cut] + fmt.Sprintf("\n... %d more lines; run git diff for the rest", omitted)
+}
@@ -0,0 +1,88 @@
+package prompt
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dfowler/flock/internal/vault"
+)
+
+// Match is a prompt line containing a search query
+type Match struct {
+	TaskID   string
+	Path     string
+	Line     int    // 1-based line number
+	Text     string // The matching line, trimmed
+	Snapshot bool   // Match is in an older prompt version rather than the current file
+}
+
+// Search finds prompt lines containing query (case-insensitive) across all
+// prompt files and their history snapshots, grouped by task ID.
+// Snapshot lines that also appear in the task's current prompt are skipped.
+func (m *Manager) Search(query string) ([]Match, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, nil
+	}
+
+	current, err := filepath.Glob(filepath.Join(m.config.PromptsDir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	seen := make(map[string]bool) // taskID + line text already reported
+	for _, path := range current {
+		taskID := strings.TrimSuffix(filepath.Base(path), ".md")
+		for _, match := range searchFile(m.config.Vault(), path, query) {
+			match.TaskID = taskID
+			seen[taskID+"\x00"+match.Text] = true
+			matches = append(matches, match)
+		}
+	}
+
+	snapshots, err := filepath.Glob(filepath.Join(m.config.PromptsDir, historyDir, "*", "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range snapshots {
+		taskID := filepath.Base(filepath.Dir(path))
+		for _, match := range searchFile(m.config.Vault(), path, query) {
+			key := taskID + "\x00" + match.Text
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			match.TaskID = taskID
+			match.Snapshot = true
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].TaskID < matches[j].TaskID
+	})
+	return matches, nil
+}
+
+// searchFile returns the lines of a file containing the lowercased query,
+// decrypting the file through v if it is sealed
+func searchFile(v *vault.Vault, path, query string) []Match {
+	data, err := v.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var matches []Match
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.Contains(strings.ToLower(text), query) {
+			matches = append(matches, Match{Path: path, Line: line, Text: strings.TrimSpace(text)})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,119 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// SharedTemplatesDir returns the directory shared templates are read from: templates.path
+// inside the checkout of templates.repo, or "" if no repository is configured
+func (m *Manager) SharedTemplatesDir() string {
+	if strings.TrimSpace(m.config.Templates.Repo) == "" {
+		return ""
+	}
+	return filepath.Join(m.config.SharedTemplatesCheckout(), filepath.FromSlash(m.config.Templates.Path))
+}
+
+// SyncSharedTemplates clones templates.repo, or updates the existing checkout to the
+// latest commit of its branch. The checkout is flock's own copy, so local changes to it
+// are discarded. Returns a summary such as "12 shared templates at 1a2b3c4".
+func (m *Manager) SyncSharedTemplates() (string, error) {
+	repo := strings.TrimSpace(m.config.Templates.Repo)
+	if repo == "" {
+		return "", fmt.Errorf("no shared template repository configured (templates.repo)")
+	}
+	checkout := m.config.SharedTemplatesCheckout()
+	branch := m.config.Templates.Branch
+
+	// A checkout of a different repository is replaced
+	if output, err := m.git("-C", checkout, "remote", "get-url", "origin"); err != nil || strings.TrimSpace(output) != repo {
+		if err := os.RemoveAll(checkout); err != nil {
+			return "", fmt.Errorf("failed to remove old shared templates: %w", err)
+		}
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if branch != "" {
+			args = append(args, "--branch", branch)
+		}
+		if output, err := m.git(append(args, repo, checkout)...); err != nil {
+			return "", fmt.Errorf("failed to clone %s: %s", repo, output)
+		}
+	} else {
+		ref := "HEAD"
+		if branch != "" {
+			ref = branch
+		}
+		if output, err := m.git("-C", checkout, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+			return "", fmt.Errorf("failed to fetch %s: %s", repo, output)
+		}
+		if output, err := m.git("-C", checkout, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+			return "", fmt.Errorf("failed to update shared templates: %s", output)
+		}
+	}
+
+	names, err := listTemplateFiles(m.SharedTemplatesDir())
+	if err != nil {
+		return "", fmt.Errorf("failed to list shared templates: %w", err)
+	}
+	head, _ := m.git("-C", checkout, "rev-parse", "--short", "HEAD")
+	noun := "templates"
+	if len(names) == 1 {
+		noun = "template"
+	}
+	return fmt.Sprintf("%d shared %s at %s", len(names), noun, head), nil
+}
+
+// git runs a git command, returning its trimmed combined output
+func (m *Manager) git(args ...string) (string, error) {
+	output, err := m.commands.CombinedOutput(context.Background(), runner.Command("git", args...))
+	return strings.TrimSpace(string(output)), err
+}
+
+// sharedTemplatePath returns the path of a shared template, or "" if there is none by that name
+func (m *Manager) sharedTemplatePath(name string) string {
+	dir := m.SharedTemplatesDir()
+	if dir == "" {
+		return ""
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// TemplatePath returns the file a project's template is read from: the project's own
+// copy, or else the shared template of that name. shared reports the latter.
+func (m *Manager) TemplatePath(projectDir, name string) (path string, shared bool) {
+	name = TemplateFileName(name)
+	path = filepath.Join(TemplatesDir(projectDir), name)
+	if _, err := os.Stat(path); err == nil {
+		return path, false
+	}
+	if sharedPath := m.sharedTemplatePath(name); sharedPath != "" {
+		return sharedPath, true
+	}
+	return path, false
+}
+
+// listTemplateFiles returns the names of the .md files in dir
+func listTemplateFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".md" {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
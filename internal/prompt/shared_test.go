@@ -0,0 +1,79 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/gittest"
+)
+
+// commitTemplate writes a template into the library repository and commits it
+func commitTemplate(t *testing.T, repo, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(repo, "prompts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "prompts", name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gittest.Run(t, repo, "add", ".")
+	gittest.Run(t, repo, "commit", "-q", "-m", "Update "+name)
+}
+
+func TestSharedTemplates(t *testing.T) {
+	library := gittest.NewRepo(t)
+	commitTemplate(t, library, "security.md", "# {{name}}\nCheck the auth module.\n")
+
+	cfg := &config.Config{Templates: config.TemplatesConfig{Repo: library, Path: "prompts"}}
+	if err := cfg.UseDir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(cfg)
+	project := t.TempDir()
+
+	if summary, err := m.SyncSharedTemplates(); err != nil {
+		t.Fatalf("SyncSharedTemplates: %v", err)
+	} else if !strings.HasPrefix(summary, "1 shared template at ") {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if _, err := m.CreateTemplate(project, "bugfix"); err != nil {
+		t.Fatal(err)
+	}
+	templates, err := m.ListTemplates(project)
+	if err != nil || !reflect.DeepEqual(templates, []string{"bugfix.md", "default.md", "security.md"}) {
+		t.Errorf("expected the project's templates then the shared one, got %v (%v)", templates, err)
+	}
+
+	promptPath, err := m.CreatePromptFileFromTemplate("security", Vars{TaskID: "001", Name: "audit", WorkingDir: project})
+	if err != nil {
+		t.Fatalf("CreatePromptFileFromTemplate: %v", err)
+	}
+	if data, _ := os.ReadFile(promptPath); string(data) != "# audit\nCheck the auth module.\n" {
+		t.Errorf("expected the shared template rendered, got %q", data)
+	}
+	if err := m.DeleteTemplate(project, "security"); err == nil {
+		t.Errorf("expected an error deleting a shared template")
+	}
+
+	// Syncing again picks up new commits
+	commitTemplate(t, library, "security.md", "Updated\n")
+	if _, err := m.SyncSharedTemplates(); err != nil {
+		t.Fatalf("SyncSharedTemplates after an update: %v", err)
+	}
+	path, shared := m.TemplatePath(project, "security")
+	if data, _ := os.ReadFile(path); !shared || string(data) != "Updated\n" {
+		t.Errorf("expected the updated shared template, got %q (shared %v)", data, shared)
+	}
+
+	// Creating a project template of the same name starts from the shared one and overrides it
+	if _, err := m.CreateTemplate(project, "security"); err != nil {
+		t.Fatal(err)
+	}
+	if path, shared := m.TemplatePath(project, "security"); shared || path != filepath.Join(TemplatesDir(project), "security.md") {
+		t.Errorf("expected the project's copy to override the shared template, got %s", path)
+	}
+}
@@ -0,0 +1,78 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/config"
+)
+
+func TestCreateAndDeleteTemplate(t *testing.T) {
+	project := t.TempDir()
+	m := NewManager(&config.Config{PromptsDir: t.TempDir()})
+
+	path, err := m.CreateTemplate(project, "bugfix")
+	if err != nil {
+		t.Fatalf("CreateTemplate: %v", err)
+	}
+	if want := filepath.Join(TemplatesDir(project), "bugfix.md"); path != want {
+		t.Errorf("expected %s, got %s", want, path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != defaultTemplateContent {
+		t.Errorf("expected a copy of the default template, got %q (%v)", data, err)
+	}
+	if _, err := m.CreateTemplate(project, "bugfix.md"); err == nil {
+		t.Errorf("expected an error creating a template that exists")
+	}
+
+	templates, err := m.ListTemplates(project)
+	if err != nil || !reflect.DeepEqual(templates, []string{"bugfix.md", "default.md"}) {
+		t.Errorf("expected [bugfix.md default.md], got %v (%v)", templates, err)
+	}
+
+	if err := m.DeleteTemplate(project, "default"); err == nil {
+		t.Errorf("expected an error deleting the default template")
+	}
+	if err := m.DeleteTemplate(project, "bugfix"); err != nil {
+		t.Errorf("DeleteTemplate: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be deleted", path)
+	}
+}
+
+func TestReviewTemplate(t *testing.T) {
+	project := t.TempDir()
+	m := NewManager(&config.Config{PromptsDir: t.TempDir()})
+	vars := Vars{TaskID: "004", Name: "fix-login-review", WorkingDir: project, Branch: "flock-003-fix-login", Diff: "+func Login() {}"}
+
+	// Without a project review.md the built-in one is used
+	path, err := m.CreatePromptFileFromTemplate(ReviewTemplateName, vars)
+	if err != nil {
+		t.Fatalf("CreatePromptFileFromTemplate: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Review: fix-login-review", "`flock-003-fix-login`", "````diff\n+func Login() {}\n````"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in the review prompt, got:\n%s", want, data)
+		}
+	}
+
+	if _, err := m.CreateTemplate(project, "review"); err != nil {
+		t.Fatalf("CreateTemplate: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(TemplatesDir(project), ReviewTemplateName)); err != nil || string(data) != reviewTemplateContent {
+		t.Errorf("expected a copy of the built-in review template, got %q (%v)", data, err)
+	}
+
+	long := strings.Repeat("+line\n", maxReviewDiffBytes/6+10)
+	if diff := ReviewDiff(long); len(diff) > maxReviewDiffBytes+100 || !strings.HasSuffix(diff, "more lines; run git diff for the rest") {
+		t.Errorf("expected a long diff to be cut, got %d bytes ending %q", len(diff), diff[len(diff)-40:])
+	}
+}
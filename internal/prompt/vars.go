@@ -0,0 +1,75 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dfowler/flock/internal/git"
+)
+
+// Vars are the values a prompt template can use, each as a {{variable}} of the same name
+// in lower case with underscores (e.g. {{task_id}}), or as a field (e.g. {{.TaskID}})
+type Vars struct {
+	TaskID     string    // {{task_id}}: e.g. 007
+	Name       string    // {{name}}: the task name
+	WorkingDir string    // {{working_dir}}: the directory the agent starts in
+	RepoRoot   string    // {{repo_root}}: main repository of the working directory ("" outside git)
+	Branch     string    // {{branch}}: the task's branch, or the working directory's current branch
+	Goal       string    // {{goal}}: the goal typed in the new task form (may be empty)
+	Date       time.Time // {{date}}: the day the task was created, as 2006-01-02
+	Diff       string    // {{diff}}: the changes a reviewer task reviews (empty for other tasks)
+}
+
+// TaskVars collects the template variables for a new task. With a worktree, the branch is
+// the one the task's branch template gives; flock may add a suffix if that branch is taken.
+func (m *Manager) TaskVars(taskID, taskName, workingDir, goal string, useWorktree bool) Vars {
+	vars := Vars{
+		TaskID:     taskID,
+		Name:       taskName,
+		WorkingDir: workingDir,
+		Goal:       goal,
+		Date:       time.Now(),
+	}
+	if repoRoot, err := git.GetMainRepoRoot(workingDir); err == nil {
+		vars.RepoRoot = repoRoot
+		if useWorktree {
+			vars.Branch = git.FormatBranchName(m.config.Worktrees.BranchTemplate, taskID, taskName)
+		} else if branch, err := git.GetCurrentBranch(workingDir); err == nil {
+			vars.Branch = branch
+		}
+	}
+	return vars
+}
+
+// Render fills in a template's variables. Sections can be made optional with
+// {{if branch}}...{{end}}, which leaves them out when the variable is empty.
+// Templates that don't use {{goal}} get the goal under their "## Goal" heading instead.
+func Render(content string, vars Vars) (string, error) {
+	goalUsed := false
+	funcs := template.FuncMap{
+		"task_id":     func() string { return vars.TaskID },
+		"name":        func() string { return vars.Name },
+		"working_dir": func() string { return vars.WorkingDir },
+		"repo_root":   func() string { return vars.RepoRoot },
+		"branch":      func() string { return vars.Branch },
+		"goal":        func() string { goalUsed = true; return vars.Goal },
+		"date":        func() string { return vars.Date.Format("2006-01-02") },
+		"diff":        func() string { return vars.Diff },
+	}
+	tmpl, err := template.New("prompt").Funcs(funcs).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("failed to fill in template: %w", err)
+	}
+
+	rendered := b.String()
+	if vars.Goal != "" && !goalUsed && !strings.Contains(content, ".Goal") {
+		rendered = strings.Replace(rendered, "## Goal\n\n", "## Goal\n\n"+vars.Goal+"\n\n", 1)
+	}
+	return rendered, nil
+}
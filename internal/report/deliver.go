@@ -0,0 +1,156 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/notify"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// sendTimeout bounds how long posting or mailing a report may take
+const sendTimeout = 30 * time.Second
+
+// discordLimit is the longest message Discord accepts
+const discordLimit = 2000
+
+// Save writes the report to dir as <period>-<date>.md and returns its path
+func Save(dir string, r Report) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create reports directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.md", r.Period, r.To.Format("2006-01-02")))
+	if err := os.WriteFile(path, []byte(r.Markdown()), 0644); err != nil {
+		return "", fmt.Errorf("failed to save report: %w", err)
+	}
+	return path, nil
+}
+
+// Deliver saves the report and sends it to the configured email recipients and webhook,
+// returning the saved path. Every destination is tried even when an earlier one fails.
+func Deliver(cfg *config.Config, r Report, commands runner.Runner) (string, error) {
+	path, err := Save(cfg.ReportsDir(), r)
+	var errs []error
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(cfg.Reports.Email.To) > 0 {
+		if err := Email(cfg.Reports.Email, r, commands); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.Reports.Webhook != "" {
+		if err := PostWebhook(cfg.Reports.Webhook, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return path, errors.Join(errs...)
+}
+
+// Email sends the report as a plain-text email, through SMTP when a server is set and
+// the local sendmail otherwise
+func Email(cfg config.ReportEmailConfig, r Report, commands runner.Runner) error {
+	from := cfg.From
+	if from == "" {
+		host, _ := os.Hostname()
+		from = "flock@" + host
+	}
+	message := emailMessage(from, cfg.To, r)
+
+	if cfg.SMTP == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		// -t takes the recipients from the headers; -i keeps a lone "." line from ending the message
+		if out, err := commands.CombinedOutput(ctx, runner.Command("sendmail", "-t", "-i").WithInput(message)); err != nil {
+			return fmt.Errorf("failed to send report with sendmail: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(cfg.SMTP)
+		auth = smtp.PlainAuth("", cfg.Username, os.Getenv(cfg.PasswordEnv), host)
+	}
+	if err := smtp.SendMail(cfg.SMTP, auth, from, cfg.To, []byte(message)); err != nil {
+		return fmt.Errorf("failed to email report: %w", err)
+	}
+	return nil
+}
+
+// emailMessage builds the email for a report, with the Markdown as its plain-text body
+func emailMessage(from string, to []string, r Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", r.Title())
+	fmt.Fprintf(&b, "Date: %s\r\n", r.To.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(r.Markdown(), "\n", "\r\n"))
+	return b.String()
+}
+
+// webhookPayload builds the JSON body for a report in a webhook format
+func webhookPayload(format string, r Report) ([]byte, error) {
+	switch format {
+	case notify.FormatSlack:
+		return json.Marshal(map[string]string{"text": r.Markdown()})
+	case notify.FormatDiscord:
+		text := r.Markdown()
+		if runes := []rune(text); len(runes) > discordLimit {
+			text = string(runes[:discordLimit-1]) + "…"
+		}
+		return json.Marshal(map[string]string{"content": text})
+	}
+	return json.Marshal(struct {
+		Title     string    `json:"title"`
+		Period    string    `json:"period"`
+		From      time.Time `json:"from"`
+		To        time.Time `json:"to"`
+		Created   int       `json:"created"`
+		Active    int       `json:"active"`
+		Completed int       `json:"completed"`
+		Merged    int       `json:"merged"`
+		Working   int       `json:"working_seconds"`
+		Waiting   int       `json:"waiting_seconds"`
+		Markdown  string    `json:"markdown"`
+	}{r.Title(), r.Period, r.From, r.To, r.Created, r.Active, r.Completed, r.Merged,
+		int(r.Working.Seconds()), int(r.Waiting.Seconds()), r.Markdown()})
+}
+
+// PostWebhook sends the report to a webhook URL, as a chat message for Slack and Discord
+// and as JSON otherwise. Errors leave the URL out, since chat webhook URLs are secrets.
+func PostWebhook(endpoint string, r Report) error {
+	body, err := webhookPayload(notify.WebhookFormat("", endpoint), r)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: sendTimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to post report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("report webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/task"
+)
+
+// Report periods: the span of activity a report covers
+const (
+	PeriodDaily  = "daily"
+	PeriodWeekly = "weekly"
+)
+
+// noProject labels tasks that are not in a project
+const noProject = "(none)"
+
+// Span returns how far back a report for period looks ("" means weekly)
+func Span(period string) (time.Duration, error) {
+	switch period {
+	case PeriodDaily:
+		return 24 * time.Hour, nil
+	case PeriodWeekly, "":
+		return 7 * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("unknown report period %q (use daily or weekly)", period)
+}
+
+// Report summarizes task activity between From and To
+type Report struct {
+	Period    string
+	From, To  time.Time
+	Created   int
+	Active    int // Tasks whose status changed in the period
+	Completed int
+	Merged    int
+	Working   time.Duration // Time agents spent working, summed over tasks
+	Waiting   time.Duration // Time agents spent waiting for input
+	Projects  []ProjectStats
+	Finished  []Finished // Tasks completed in the period, oldest first
+}
+
+// ProjectStats counts one project's activity in the period
+type ProjectStats struct {
+	Project   string
+	Active    int
+	Completed int
+	Merged    int
+	Working   time.Duration
+}
+
+// Finished is a task completed in the period
+type Finished struct {
+	ID       string
+	Name     string
+	Project  string
+	Branch   string
+	Duration time.Duration // From creation to completion
+	Merged   bool
+	PRURL    string
+}
+
+// Build summarizes the tasks (live and archived) that were active between from and to
+// for a period ("" means weekly).
+// Each task's status history is the audit trail: time in WORKING and WAITING is taken from it.
+func Build(period string, tasks []*task.Task, archived []*task.ArchivedTask, from, to time.Time) Report {
+	if period == "" {
+		period = PeriodWeekly
+	}
+	r := Report{Period: period, From: from, To: to}
+
+	all := append([]*task.Task(nil), tasks...)
+	seen := make(map[string]bool)
+	for _, t := range tasks {
+		seen[t.ID] = true
+	}
+	for _, a := range archived {
+		if !seen[a.Task.ID] {
+			t := a.Task
+			all = append(all, &t)
+		}
+	}
+
+	in := func(at *time.Time) bool {
+		return at != nil && !at.Before(from) && at.Before(to)
+	}
+	projects := make(map[string]*ProjectStats)
+	for _, t := range all {
+		working, waiting, changed := timeInStatus(t, from, to)
+		created := in(&t.CreatedAt)
+		completed, merged := in(t.CompletedAt), in(t.MergedAt)
+		if !created && !changed && !completed && !merged {
+			continue
+		}
+
+		name := t.Project
+		if name == "" {
+			name = noProject
+		}
+		p, ok := projects[name]
+		if !ok {
+			p = &ProjectStats{Project: name}
+			projects[name] = p
+		}
+
+		if created {
+			r.Created++
+		}
+		if changed {
+			r.Active++
+			p.Active++
+		}
+		r.Working += working
+		r.Waiting += waiting
+		p.Working += working
+		if completed {
+			r.Completed++
+			p.Completed++
+			r.Finished = append(r.Finished, Finished{
+				ID:       t.ID,
+				Name:     t.Name,
+				Project:  t.Project,
+				Branch:   t.GitBranch,
+				Duration: t.CompletedAt.Sub(t.CreatedAt),
+				Merged:   t.MergedAt != nil,
+				PRURL:    t.PRURL,
+			})
+		}
+		if merged {
+			r.Merged++
+			p.Merged++
+		}
+	}
+
+	for _, p := range projects {
+		r.Projects = append(r.Projects, *p)
+	}
+	sort.Slice(r.Projects, func(i, j int) bool {
+		return r.Projects[i].Project < r.Projects[j].Project
+	})
+	sort.Slice(r.Finished, func(i, j int) bool {
+		return r.Finished[i].ID < r.Finished[j].ID
+	})
+	return r
+}
+
+// timeInStatus adds up the time a task spent WORKING and WAITING between from and to,
+// and reports whether its status changed in that window
+func timeInStatus(t *task.Task, from, to time.Time) (working, waiting time.Duration, changed bool) {
+	for i, change := range t.History {
+		if !change.At.Before(from) && change.At.Before(to) {
+			changed = true
+		}
+		start, end := change.At, to
+		if i < len(t.History)-1 {
+			end = t.History[i+1].At
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+		switch change.Status {
+		case task.StatusWorking:
+			working += end.Sub(start)
+		case task.StatusWaiting:
+			waiting += end.Sub(start)
+		}
+	}
+	return working, waiting, changed
+}
+
+// Title names the report, e.g. "Flock weekly report: Oct 9 - Oct 16, 2026"
+func (r Report) Title() string {
+	return fmt.Sprintf("Flock %s report: %s - %s", r.Period, r.From.Format("Jan 2"), r.To.Format("Jan 2, 2006"))
+}
+
+// Markdown renders the report as a Markdown document
+func (r Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", r.Title())
+
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Tasks created | %d |\n", r.Created)
+	fmt.Fprintf(&b, "| Tasks active | %d |\n", r.Active)
+	fmt.Fprintf(&b, "| Tasks completed | %d |\n", r.Completed)
+	fmt.Fprintf(&b, "| Tasks merged | %d |\n", r.Merged)
+	fmt.Fprintf(&b, "| Agent time working | %s |\n", formatHours(r.Working))
+	fmt.Fprintf(&b, "| Agent time waiting for input | %s |\n", formatHours(r.Waiting))
+
+	if len(r.Projects) > 0 {
+		b.WriteString("\n## Projects\n\n")
+		b.WriteString("| Project | Active | Completed | Merged | Working |\n|---|---|---|---|---|\n")
+		for _, p := range r.Projects {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %s |\n", p.Project, p.Active, p.Completed, p.Merged, formatHours(p.Working))
+		}
+	}
+
+	b.WriteString("\n## Completed\n\n")
+	if len(r.Finished) == 0 {
+		b.WriteString("No tasks completed.\n")
+	}
+	for _, f := range r.Finished {
+		details := []string{formatHours(f.Duration)}
+		if f.Branch != "" {
+			details = append(details, "`"+f.Branch+"`")
+		}
+		if f.Merged {
+			details = append(details, "merged")
+		}
+		if f.PRURL != "" {
+			details = append(details, f.PRURL)
+		}
+		fmt.Fprintf(&b, "- %s %s (%s)\n", f.ID, f.Name, strings.Join(details, ", "))
+	}
+	return b.String()
+}
+
+// formatHours formats a duration in hours and minutes, like "45m" or "12h05m"
+func formatHours(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+func TestBuild(t *testing.T) {
+	to := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+	at := func(hours float64) time.Time {
+		return to.Add(time.Duration(hours * float64(time.Hour)))
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tasks := []*task.Task{
+		{
+			// Started before the window, finished and merged inside it
+			ID: "001", Name: "fix tests", Project: "api", GitBranch: "flock-001",
+			CreatedAt:   at(-30),
+			CompletedAt: ptr(at(-10)), MergedAt: ptr(at(-9)),
+			History: []task.StatusChange{
+				{Status: task.StatusWorking, At: at(-26)},
+				{Status: task.StatusWaiting, At: at(-12)},
+				{Status: task.StatusDone, At: at(-10)},
+			},
+		},
+		{
+			// Created in the window and still working
+			ID: "002", Name: "docs", CreatedAt: at(-3),
+			History: []task.StatusChange{{Status: task.StatusWorking, At: at(-2)}},
+		},
+		{
+			// Nothing happened in the window
+			ID: "003", Name: "old", Project: "api", CreatedAt: at(-100),
+			History: []task.StatusChange{{Status: task.StatusDone, At: at(-90)}},
+		},
+	}
+	archived := []*task.ArchivedTask{
+		{Task: *tasks[0]}, // already in the live list
+		{Task: task.Task{ID: "004", Name: "spike", Project: "api", CreatedAt: at(-20), CompletedAt: ptr(at(-19)),
+			History: []task.StatusChange{{Status: task.StatusWorking, At: at(-20)}, {Status: task.StatusDone, At: at(-19)}}}},
+	}
+
+	r := Build("", tasks, archived, from, to)
+	if r.Period != PeriodWeekly {
+		t.Errorf("expected an empty period to mean weekly, got %q", r.Period)
+	}
+	if r.Created != 2 || r.Active != 3 || r.Completed != 2 || r.Merged != 1 {
+		t.Errorf("expected 2 created, 3 active, 2 completed, 1 merged, got %d, %d, %d, %d", r.Created, r.Active, r.Completed, r.Merged)
+	}
+	// 001 works 12h of the window, 004 works 1h and 002 works 2h
+	if r.Working != 15*time.Hour {
+		t.Errorf("expected 15h working, got %s", r.Working)
+	}
+	if r.Waiting != 2*time.Hour {
+		t.Errorf("expected 2h waiting, got %s", r.Waiting)
+	}
+	if len(r.Projects) != 2 || r.Projects[0].Project != noProject || r.Projects[1].Completed != 2 {
+		t.Errorf("expected (none) and api with 2 completed, got %+v", r.Projects)
+	}
+	if len(r.Finished) != 2 || r.Finished[0].ID != "001" || !r.Finished[0].Merged {
+		t.Errorf("expected 001 (merged) and 004 finished, got %+v", r.Finished)
+	}
+
+	md := r.Markdown()
+	for _, want := range []string{"# Flock weekly report: Oct 15 - Oct 16, 2026", "| Agent time working | 15h00m |", "- 001 fix tests (20h00m, `flock-001`, merged)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, md)
+		}
+	}
+}
+
+func TestEmailSendmail(t *testing.T) {
+	fake := runner.NewFake()
+	r := Report{Period: PeriodDaily, From: time.Unix(0, 0), To: time.Unix(86400, 0)}
+	cfg := config.ReportEmailConfig{To: []string{"a@example.com", "b@example.com"}, From: "flock@example.com"}
+	if err := Email(cfg, r, fake); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0].String() != "sendmail -t -i" {
+		t.Fatalf("expected sendmail -t -i, got %v", fake.Commands())
+	}
+	for _, want := range []string{"To: a@example.com, b@example.com\r\n", "Subject: Flock daily report", "\r\n\r\n# Flock daily report"} {
+		if !strings.Contains(calls[0].Stdin, want) {
+			t.Errorf("expected message to contain %q, got:\n%s", want, calls[0].Stdin)
+		}
+	}
+}
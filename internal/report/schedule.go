@@ -0,0 +1,80 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/schedule"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// state is the report state file: when the scheduled report last ran
+type state struct {
+	LastRun time.Time `json:"last_run"`
+}
+
+// loadState reads the report state file; a missing file has never run
+func loadState(path string) state {
+	var s state
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &s)
+	}
+	return s
+}
+
+// saveState writes the report state file
+func saveState(path string, s state) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Due reports whether the scheduled report should run at now: its schedule has come around
+// since the last run. The first check only records now, so turning reports on doesn't send one at once.
+func Due(cfg *config.Config, now time.Time) (bool, error) {
+	if cfg.Reports.Schedule == "" {
+		return false, nil
+	}
+	cron, err := schedule.Parse(cfg.Reports.Schedule)
+	if err != nil {
+		return false, fmt.Errorf("invalid reports.schedule: %w", err)
+	}
+
+	path := cfg.ReportStatePath()
+	s := loadState(path)
+	if s.LastRun.IsZero() {
+		return false, saveState(path, state{LastRun: now})
+	}
+	next := cron.Next(s.LastRun)
+	return !next.IsZero() && !next.After(now), nil
+}
+
+// RunDue builds and delivers the scheduled report when it is due, returning a note for the
+// TUI or daemon log ("" when nothing ran)
+func RunDue(cfg *config.Config, tasks []*task.Task, archived []*task.ArchivedTask, commands runner.Runner, now time.Time) (string, error) {
+	due, err := Due(cfg, now)
+	if err != nil || !due {
+		return "", err
+	}
+	span, err := Span(cfg.Reports.Period)
+	if err != nil {
+		return "", err
+	}
+	// Record the run first, so a failing destination isn't retried every check
+	if err := saveState(cfg.ReportStatePath(), state{LastRun: now}); err != nil {
+		return "", fmt.Errorf("failed to save report state: %w", err)
+	}
+
+	r := Build(cfg.Reports.Period, tasks, archived, now.Add(-span), now)
+	path, err := Deliver(cfg, r, commands)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Saved %s report to %s", r.Period, path), nil
+}
@@ -0,0 +1,124 @@
+// Package retention finds and purges data older than the configured retention
+// period: archived tasks and agent transcripts.
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/task"
+)
+
+// Kinds of data a cleanup removes
+const (
+	KindArchived   = "archived task"
+	KindTranscript = "transcript"
+)
+
+// Item is something old enough to be purged
+type Item struct {
+	Kind    string
+	Name    string    // Task ID and name for archived tasks, file name for transcripts
+	Path    string    // File to remove ("" for archive entries)
+	Size    int64     // Bytes reclaimed
+	ModTime time.Time // When the task was archived or the file last written
+}
+
+// Plan lists what a cleanup would remove
+type Plan struct {
+	Cutoff time.Time
+	Items  []Item
+}
+
+// Bytes returns the disk space the plan would reclaim
+func (p Plan) Bytes() int64 {
+	var total int64
+	for _, item := range p.Items {
+		total += item.Size
+	}
+	return total
+}
+
+// Find lists archived tasks archived before cutoff and transcripts under logsDir
+// last written before it. Transcripts of tasks for which inUse returns true
+// (their tab is still open) are kept.
+func Find(archive *task.Archive, logsDir string, cutoff time.Time, inUse func(taskID string) bool) (Plan, error) {
+	plan := Plan{Cutoff: cutoff}
+
+	for _, entry := range archive.ArchivedBefore(cutoff) {
+		data, _ := json.Marshal(entry)
+		plan.Items = append(plan.Items, Item{
+			Kind:    KindArchived,
+			Name:    entry.Task.ID + " " + entry.Task.Name,
+			Size:    int64(len(data)),
+			ModTime: entry.ArchivedAt,
+		})
+	}
+
+	err := filepath.WalkDir(logsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if inUse != nil && inUse(transcriptTaskID(d.Name())) {
+			return nil
+		}
+		plan.Items = append(plan.Items, Item{
+			Kind:    KindTranscript,
+			Name:    d.Name(),
+			Path:    path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return plan, fmt.Errorf("failed to scan %s: %w", logsDir, err)
+	}
+
+	sort.SliceStable(plan.Items, func(i, j int) bool {
+		return plan.Items[i].ModTime.Before(plan.Items[j].ModTime)
+	})
+	return plan, nil
+}
+
+// Apply removes everything in the plan, returning how many items were removed
+func Apply(plan Plan, archive *task.Archive) (int, error) {
+	removed, err := archive.Prune(plan.Cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune archive: %w", err)
+	}
+	for _, item := range plan.Items {
+		if item.Path == "" {
+			continue
+		}
+		if err := os.Remove(item.Path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", item.Path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// transcriptTaskID returns the task a transcript belongs to ("007.log", "007.log.1" -> "007")
+func transcriptTaskID(name string) string {
+	if idx := strings.Index(name, "."); idx != -1 {
+		return name[:idx]
+	}
+	return name
+}
@@ -0,0 +1,72 @@
+package retention
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/task"
+)
+
+func TestFindAndApply(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	cutoff := now.Add(-90 * 24 * time.Hour)
+
+	archive, err := task.LoadArchive(filepath.Join(dir, "archive.json"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		id  string
+		age time.Duration
+	}{{"001", 100 * 24 * time.Hour}, {"002", time.Hour}} {
+		entry := task.NewArchivedTask(task.NewTask(tc.id, "task "+tc.id, "", "/src"), "", now.Add(-tc.age))
+		if err := archive.Add(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logs := filepath.Join(dir, "logs")
+	os.MkdirAll(logs, 0755)
+	for name, age := range map[string]time.Duration{
+		"003.log": 120 * 24 * time.Hour, // old
+		"004.log": 120 * 24 * time.Hour, // old, but the tab is still open
+		"005.log": time.Hour,            // recent
+	} {
+		path := filepath.Join(logs, name)
+		os.WriteFile(path, []byte("output"), 0644)
+		os.Chtimes(path, now.Add(-age), now.Add(-age))
+	}
+
+	plan, err := Find(archive, logs, cutoff, func(id string) bool { return id == "004" })
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(plan.Items) != 2 || plan.Items[0].Name != "003.log" || plan.Items[1].Name != "001 task 001" {
+		t.Fatalf("expected 003.log and archived 001, got %+v", plan.Items)
+	}
+	if plan.Bytes() <= int64(len("output")) {
+		t.Errorf("expected the plan to count the archive entry's size, got %d", plan.Bytes())
+	}
+
+	removed, err := Apply(plan, archive)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 items removed, got %d", removed)
+	}
+	if _, err := os.Stat(filepath.Join(logs, "003.log")); !os.IsNotExist(err) {
+		t.Errorf("expected 003.log to be removed")
+	}
+	for _, kept := range []string{"004.log", "005.log"} {
+		if _, err := os.Stat(filepath.Join(logs, kept)); err != nil {
+			t.Errorf("expected %s to be kept: %v", kept, err)
+		}
+	}
+	if remaining := archive.Search(""); len(remaining) != 1 || remaining[0].Task.ID != "002" {
+		t.Errorf("expected only 002 left in the archive, got %d entries", len(remaining))
+	}
+}
@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Response is a Fake's canned answer to a command
+type Response struct {
+	Output   string
+	ExitCode int   // Non-zero fails the command with an *ExitError
+	Err      error // Fails the command with this error instead
+}
+
+// Fake is a Runner for tests. It records every command and answers from canned
+// responses; commands without a response succeed with no output.
+type Fake struct {
+	mu        sync.Mutex
+	calls     []Cmd
+	responses []fakeResponse
+}
+
+type fakeResponse struct {
+	prefix string
+	resp   Response
+}
+
+var _ Runner = (*Fake)(nil)
+
+// NewFake creates a Fake with no canned responses
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+// On answers commands whose command line starts with prefix (e.g. "git -C /repo merge").
+// Later responses take precedence over earlier ones.
+func (f *Fake) On(prefix string, resp Response) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses = append(f.responses, fakeResponse{prefix: prefix, resp: resp})
+}
+
+// Calls returns the commands run so far
+func (f *Fake) Calls() []Cmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Cmd(nil), f.calls...)
+}
+
+// Commands returns the command lines run so far
+func (f *Fake) Commands() []string {
+	var lines []string
+	for _, c := range f.Calls() {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
+
+// Ran reports whether a command starting with prefix was run
+func (f *Fake) Ran(prefix string) bool {
+	for _, line := range f.Commands() {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Output records the command and returns its canned output
+func (f *Fake) Output(ctx context.Context, cmd Cmd) ([]byte, error) {
+	return f.run(cmd)
+}
+
+// CombinedOutput records the command and returns its canned output
+func (f *Fake) CombinedOutput(ctx context.Context, cmd Cmd) ([]byte, error) {
+	return f.run(cmd)
+}
+
+// Interactive records the command and returns one that exits successfully without doing anything
+func (f *Fake) Interactive(cmd Cmd) *exec.Cmd {
+	f.run(cmd)
+	return exec.Command("true")
+}
+
+// run records cmd and looks up its response
+func (f *Fake) run(cmd Cmd) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, cmd)
+
+	line := cmd.String()
+	for i := len(f.responses) - 1; i >= 0; i-- {
+		r := f.responses[i]
+		if !strings.HasPrefix(line, r.prefix) {
+			continue
+		}
+		switch {
+		case r.resp.Err != nil:
+			return []byte(r.resp.Output), r.resp.Err
+		case r.resp.ExitCode != 0:
+			return []byte(r.resp.Output), &ExitError{Code: r.resp.ExitCode}
+		}
+		return []byte(r.resp.Output), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,245 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is how long a command may run when its caller sets no deadline
+const DefaultTimeout = 2 * time.Minute
+
+// limits bound every command Exec runs
+var limits = struct {
+	sync.RWMutex
+	timeout time.Duration   // For commands whose context has no deadline
+	ctx     context.Context // Once done, running commands are killed and new ones fail
+}{timeout: DefaultTimeout, ctx: context.Background()}
+
+// SetTimeout changes how long a command may run when its caller sets no deadline
+// (DefaultTimeout if d <= 0), so a hung git or tmux can't block flock forever
+func SetTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultTimeout
+	}
+	limits.Lock()
+	defer limits.Unlock()
+	limits.timeout = d
+}
+
+// SetContext ties every command to ctx: once it is done, running commands are killed
+// and new ones fail straight away. flock passes the context canceled on Ctrl+C or SIGTERM.
+func SetContext(ctx context.Context) {
+	limits.Lock()
+	defer limits.Unlock()
+	limits.ctx = ctx
+}
+
+// Cmd describes an external command
+type Cmd struct {
+	Name  string
+	Args  []string
+	Dir   string   // Working directory ("" for the current one)
+	Stdin string   // Piped to the command's standard input ("" for none)
+	Env   []string // KEY=value pairs set on top of flock's own environment
+}
+
+// Command returns a Cmd for name and args
+func Command(name string, args ...string) Cmd {
+	return Cmd{Name: name, Args: args}
+}
+
+// In returns a copy of the command that runs in dir
+func (c Cmd) In(dir string) Cmd {
+	c.Dir = dir
+	return c
+}
+
+// WithInput returns a copy of the command that reads input on its standard input
+func (c Cmd) WithInput(input string) Cmd {
+	c.Stdin = input
+	return c
+}
+
+// WithEnv returns a copy of the command that runs with vars (KEY=value) added to the
+// environment, replacing flock's own values for the same keys
+func (c Cmd) WithEnv(vars ...string) Cmd {
+	c.Env = append(slices.Clip(c.Env), vars...)
+	return c
+}
+
+// String returns the command line, e.g. "git -C /repo status"
+func (c Cmd) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Runner runs external commands (git, zellij, tmux, notify-send, fzf, editors).
+// Packages that shell out go through a Runner so tests can substitute a Fake
+// and backends can run commands elsewhere, e.g. over SSH.
+type Runner interface {
+	// Output runs the command and returns its standard output
+	Output(ctx context.Context, cmd Cmd) ([]byte, error)
+	// CombinedOutput runs the command and returns its standard output and error together
+	CombinedOutput(ctx context.Context, cmd Cmd) ([]byte, error)
+	// Interactive prepares a command that takes over the terminal, such as an
+	// editor or fzf, for the caller to run or hand to tea.ExecProcess
+	Interactive(cmd Cmd) *exec.Cmd
+}
+
+// Exec is the Runner that runs commands on the local machine
+type Exec struct{}
+
+var _ Runner = Exec{}
+
+// Output runs the command with os/exec and returns its standard output
+func (Exec) Output(ctx context.Context, cmd Cmd) ([]byte, error) {
+	return run(ctx, cmd, (*exec.Cmd).Output)
+}
+
+// CombinedOutput runs the command with os/exec and returns its standard output and error together
+func (Exec) CombinedOutput(ctx context.Context, cmd Cmd) ([]byte, error) {
+	return run(ctx, cmd, (*exec.Cmd).CombinedOutput)
+}
+
+// Interactive returns the *exec.Cmd for the command. It has no timeout, since the
+// user decides when an editor or fzf is done.
+func (Exec) Interactive(cmd Cmd) *exec.Cmd {
+	c := exec.Command(cmd.Name, cmd.Args...)
+	c.Dir = cmd.Dir
+	return c
+}
+
+// run runs cmd within the package limits: the default timeout unless ctx has a deadline,
+// and the context set with SetContext. Hitting either is reported in the error; a
+// caller's own deadline is left for the caller to report.
+func run(ctx context.Context, cmd Cmd, output func(*exec.Cmd) ([]byte, error)) ([]byte, error) {
+	limits.RLock()
+	timeout, parent := limits.timeout, limits.ctx
+	limits.RUnlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(parent, cancel)
+	defer stop()
+
+	limited := false
+	if _, ok := ctx.Deadline(); !ok {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+		limited = true
+	}
+
+	out, err := output(command(ctx, cmd))
+	switch {
+	case err == nil:
+	case parent.Err() != nil:
+		err = fmt.Errorf("%s was stopped: %w", cmd.Name, parent.Err())
+	case limited && ctx.Err() == context.DeadlineExceeded:
+		err = timedOut(cmd, timeout)
+		slog.Warn("command timed out", "cmd", cmd.String(), "dir", cmd.Dir, "timeout", timeout)
+	default:
+		// Many failures are expected, e.g. probing for a branch, so they are only for debugging
+		slog.Debug("command failed", "cmd", cmd.String(), "dir", cmd.Dir, "err", err)
+	}
+	return out, err
+}
+
+// timedOut is the error for a command killed after running for d
+func timedOut(cmd Cmd, d time.Duration) error {
+	return fmt.Errorf("%s did not finish within %s: %w", cmd.String(), d, context.DeadlineExceeded)
+}
+
+// command builds the *exec.Cmd for cmd, killed when ctx is done
+func command(ctx context.Context, cmd Cmd) *exec.Cmd {
+	c := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	c.Dir = cmd.Dir
+	if len(cmd.Env) > 0 {
+		c.Env = append(os.Environ(), cmd.Env...)
+	}
+	if cmd.Stdin != "" {
+		c.Stdin = strings.NewReader(cmd.Stdin)
+	}
+	return c
+}
+
+// Proc is a command bound to the Runner that will run it, used like an *exec.Cmd
+type Proc struct {
+	Cmd
+	runner  Runner
+	timeout time.Duration // 0 uses the package timeout
+}
+
+// Bind returns a command for name and args that runs through r
+func Bind(r Runner, name string, args ...string) *Proc {
+	return &Proc{Cmd: Command(name, args...), runner: r}
+}
+
+// WithTimeout limits the command to d instead of the package timeout
+func (p *Proc) WithTimeout(d time.Duration) *Proc {
+	p.timeout = d
+	return p
+}
+
+// Output runs the command and returns its standard output
+func (p *Proc) Output() ([]byte, error) {
+	return p.do(p.runner.Output)
+}
+
+// CombinedOutput runs the command and returns its standard output and error together
+func (p *Proc) CombinedOutput() ([]byte, error) {
+	return p.do(p.runner.CombinedOutput)
+}
+
+// Run runs the command, discarding its output
+func (p *Proc) Run() error {
+	_, err := p.do(p.runner.Output)
+	return err
+}
+
+// do runs the command with the Proc's own timeout, if it has one
+func (p *Proc) do(output func(context.Context, Cmd) ([]byte, error)) ([]byte, error) {
+	if p.timeout <= 0 {
+		return output(context.Background(), p.Cmd)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	out, err := output(ctx, p.Cmd)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		err = timedOut(p.Cmd, p.timeout)
+	}
+	return out, err
+}
+
+// ExitError is a non-zero exit reported by a Fake
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of a failed command: 0 for nil, -1 if the
+// command did not run to completion
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	var fakeErr *ExitError
+	if errors.As(err, &fakeErr) {
+		return fakeErr.Code
+	}
+	return -1
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExecLimits(t *testing.T) {
+	defer SetTimeout(DefaultTimeout)
+	defer SetContext(context.Background())
+
+	stopped, stop := context.WithCancel(context.Background())
+	stop()
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		ctx     context.Context // Set with SetContext
+		run     func() error
+		want    error
+	}{
+		{
+			name:    "package timeout",
+			timeout: 100 * time.Millisecond,
+			ctx:     context.Background(),
+			run: func() error {
+				_, err := Exec{}.Output(context.Background(), Command("sleep", "5"))
+				return err
+			},
+			want: context.DeadlineExceeded,
+		},
+		{
+			name:    "proc timeout overrides the package one",
+			timeout: time.Minute,
+			ctx:     context.Background(),
+			run: func() error {
+				return Bind(Exec{}, "sleep", "5").WithTimeout(100 * time.Millisecond).Run()
+			},
+			want: context.DeadlineExceeded,
+		},
+		{
+			name:    "stopped",
+			timeout: time.Minute,
+			ctx:     stopped,
+			run: func() error {
+				return Bind(Exec{}, "sleep", "5").Run()
+			},
+			want: context.Canceled,
+		},
+		{
+			name:    "finishes in time",
+			timeout: time.Minute,
+			ctx:     context.Background(),
+			run: func() error {
+				return Bind(Exec{}, "true").Run()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTimeout(tt.timeout)
+			SetContext(tt.ctx)
+			start := time.Now()
+			err := tt.run()
+			if tt.want == nil && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("expected the command to be killed, ran for %s", elapsed)
+			}
+		})
+	}
+}
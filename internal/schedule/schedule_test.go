@@ -0,0 +1,66 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2025, 3, 5, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"0 2 * * *", time.Date(2025, 3, 6, 2, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2025, 3, 5, 14, 40, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2025, 3, 6, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,15 * *", time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1", time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)}, // either day field matches
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		cron, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.expr, err)
+		}
+		if got := cron.Next(from); !got.Equal(tt.expected) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.expected, got)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "@nightly"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestParseAt(t *testing.T) {
+	now := time.Date(2025, 3, 5, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"18:00", time.Date(2025, 3, 5, 18, 0, 0, 0, time.UTC)},
+		{"09:00", time.Date(2025, 3, 6, 9, 0, 0, 0, time.UTC)},
+		{"2025-04-01 08:15", time.Date(2025, 4, 1, 8, 15, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseAt(tt.value, now)
+		if err != nil {
+			t.Fatalf("ParseAt(%q) failed: %v", tt.value, err)
+		}
+		if !got.Equal(tt.expected) {
+			t.Errorf("%q: expected %v, got %v", tt.value, tt.expected, got)
+		}
+	}
+	if _, err := ParseAt("tomorrow", now); err == nil {
+		t.Errorf("expected an unparseable time to be rejected")
+	}
+}
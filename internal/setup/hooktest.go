@@ -0,0 +1,222 @@
+package setup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/status"
+)
+
+// hookTestTimeout bounds one simulated hook invocation
+const hookTestTimeout = 10 * time.Second
+
+// Values the simulated hooks run with, as flock would set them for a task
+const (
+	testTaskID   = "hooktest"
+	testTaskName = `hook "test"` // quotes check that the hook escapes its JSON
+	testTabName  = "hook-test"
+)
+
+// HookCase is a simulated Claude Code hook invocation and the status file it should produce
+type HookCase struct {
+	Name    string // Label for the case, usually the hook event
+	Input   string // JSON Claude Code passes to the hook on stdin
+	NoTask  bool   // Run without FLOCK_TASK_ID, like a Claude session outside flock
+	Status  string // Expected status ("" when the hook should write nothing)
+	Event   string
+	Tool    string
+	Message string
+	// Expected notification_type, for Notification events
+	NotificationType string
+}
+
+// HookCases simulates every event flock registers the hook for, plus the ones it must ignore
+var HookCases = []HookCase{
+	{
+		Name:    "UserPromptSubmit",
+		Input:   `{"session_id":"hook-test","hook_event_name":"UserPromptSubmit","prompt":"Fix the \"login\" test"}`,
+		Status:  "WORKING",
+		Event:   "UserPromptSubmit",
+		Message: `Fix the "login" test`,
+	},
+	{
+		Name:   "PreToolUse",
+		Input:  `{"session_id":"hook-test","hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"go test ./..."}}`,
+		Status: "WORKING",
+		Event:  "PreToolUse",
+		Tool:   "Bash",
+	},
+	{
+		Name:   "PreToolUse question",
+		Input:  `{"session_id":"hook-test","hook_event_name":"PreToolUse","tool_name":"AskUserQuestion","tool_input":{"questions":[]}}`,
+		Status: "WAITING",
+		Event:  "PreToolUse",
+		Tool:   "AskUserQuestion",
+	},
+	{
+		Name:             "Notification",
+		Input:            `{"session_id":"hook-test","hook_event_name":"Notification","message":"Claude needs your permission to use Bash","notification_type":"permission_prompt"}`,
+		Status:           "WAITING",
+		Event:            "Notification",
+		Message:          "Claude needs your permission to use Bash",
+		NotificationType: "permission_prompt",
+	},
+	{
+		Name:    "Stop",
+		Input:   `{"session_id":"hook-test","hook_event_name":"Stop","stop_hook_active":false,"last_assistant_message":"Added the test.\nAll tests pass."}`,
+		Status:  "DONE",
+		Event:   "Stop",
+		Message: "Added the test. All tests pass.",
+	},
+	{
+		Name:  "SubagentStop",
+		Input: `{"session_id":"hook-test","hook_event_name":"SubagentStop"}`,
+	},
+	{
+		Name:   "outside flock",
+		Input:  `{"session_id":"hook-test","hook_event_name":"Stop"}`,
+		NoTask: true,
+	},
+}
+
+// HookResult is the outcome of one simulated hook invocation
+type HookResult struct {
+	Case   HookCase
+	Status *status.Status // The status file the hook wrote, if any
+	Output string         // What the hook printed
+	Err    error          // Why the case failed (nil when it passed)
+}
+
+// TestHook runs the hook (the script, or `flock hook`) once per case, each with its own
+// scratch status directory and none of the status server or events socket variables, and
+// checks the status file it leaves behind
+func TestHook(hook runner.Cmd, cases []HookCase, commands runner.Runner) []HookResult {
+	results := make([]HookResult, 0, len(cases))
+	for _, c := range cases {
+		results = append(results, runHookCase(hook, c, commands))
+	}
+	return results
+}
+
+// CheckHook runs the hook with a sample prompt, the quick health check the dashboard makes
+// on start, and returns why it didn't write the status file expected
+func CheckHook(hook runner.Cmd, commands runner.Runner) error {
+	return runHookCase(hook, HookCases[0], commands).Err
+}
+
+// VerifyHook runs CheckHook on the installed hook
+func (c *Checker) VerifyHook(commands runner.Runner) error {
+	return CheckHook(c.HookCommand(), commands)
+}
+
+// runHookCase runs one simulated hook invocation and checks its result
+func runHookCase(hook runner.Cmd, c HookCase, commands runner.Runner) HookResult {
+	result := HookResult{Case: c}
+	dir, err := os.MkdirTemp("", "flock-hooktest")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer os.RemoveAll(dir)
+
+	taskID := testTaskID
+	if c.NoTask {
+		taskID = ""
+	}
+	// Clearing the transports keeps a running flock from receiving the simulated updates
+	cmd := hook.WithInput(c.Input).WithEnv(
+		"FLOCK_STATUS_URL=", "FLOCK_STATUS_SOCKET=", "FLOCK_STATUS_TOKEN=",
+		"FLOCK_STATUS_EVENTS=", "FLOCK_BIN=", "CLAUDE_HOOK_EVENT_NAME=",
+		"FLOCK_TASK_ID="+taskID, "FLOCK_TASK_NAME="+testTaskName, "FLOCK_TAB_NAME="+testTabName,
+		"FLOCK_STATUS_DIR="+dir,
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), hookTestTimeout)
+	defer cancel()
+	out, err := commands.CombinedOutput(ctx, cmd)
+	result.Output = strings.TrimSpace(string(out))
+	if err != nil {
+		result.Err = fmt.Errorf("hook failed: %w", err)
+		return result
+	}
+
+	statusPath := filepath.Join(dir, testTaskID+".status")
+	if c.Status == "" {
+		if _, err := os.Stat(statusPath); err == nil {
+			result.Err = errors.New("expected no status file, but the hook wrote one")
+		}
+		return result
+	}
+	if result.Status, err = status.ParseStatusFile(statusPath); err != nil {
+		result.Err = fmt.Errorf("no valid status file: %w", err)
+		return result
+	}
+	result.Err = checkHookStatus(c, result.Status)
+	return result
+}
+
+// checkHookStatus compares a status file with what the case expects
+func checkHookStatus(c HookCase, s *status.Status) error {
+	fields := []struct{ name, want, got string }{
+		{"version", fmt.Sprint(status.Version), fmt.Sprint(s.Version)},
+		{"status", c.Status, s.Status},
+		{"task_id", testTaskID, s.TaskID},
+		{"task_name", testTaskName, s.TaskName},
+		{"tab_name", testTabName, s.TabName},
+		{"event", c.Event, s.Event},
+		{"tool", c.Tool, s.Tool},
+		{"message", c.Message, s.Message},
+		{"notification_type", c.NotificationType, s.NotificationType},
+	}
+	var problems []string
+	for _, f := range fields {
+		if f.want != f.got {
+			problems = append(problems, fmt.Sprintf("%s is %q, expected %q", f.name, f.got, f.want))
+		}
+	}
+	if s.Updated == 0 {
+		problems = append(problems, "updated is not set")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// RegisteredEvents reports, for each event flock needs, whether Claude's settings run the flock hook for it
+func (c *Checker) RegisteredEvents() (map[string]bool, error) {
+	registered := make(map[string]bool)
+	data, err := os.ReadFile(c.settingsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var settings struct {
+		Hooks map[string][]struct {
+			Hooks []struct {
+				Command string `json:"command"`
+			} `json:"hooks"`
+		} `json:"hooks"`
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", c.settingsPath, err)
+		}
+	}
+	for _, event := range HookEvents {
+		registered[event] = false
+		for _, matcher := range settings.Hooks[event] {
+			for _, hook := range matcher.Hooks {
+				if strings.Contains(hook.Command, c.scriptRef()) {
+					registered[event] = true
+				}
+			}
+		}
+	}
+	return registered, nil
+}
@@ -0,0 +1,63 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/runner"
+)
+
+func TestTestHook(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "update_status.sh")
+	if err := os.WriteFile(script, []byte(hookScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range TestHook(runner.Command(script), HookCases, runner.Exec{}) {
+		if result.Err != nil {
+			t.Errorf("%s: %v (output: %q)", result.Case.Name, result.Err, result.Output)
+		}
+	}
+
+	// A version 1 script writes key=value lines and knows nothing of events
+	old := "#!/bin/bash\n[ -z \"$FLOCK_TASK_ID\" ] && exit 0\n" +
+		"printf 'status=DONE\\ntask_id=%s\\n' \"$FLOCK_TASK_ID\" > \"$FLOCK_STATUS_DIR/$FLOCK_TASK_ID.status\"\n"
+	if err := os.WriteFile(script, []byte(old), 0755); err != nil {
+		t.Fatal(err)
+	}
+	results := TestHook(runner.Command(script), HookCases[3:4], runner.Exec{})
+	if results[0].Err == nil || !strings.Contains(results[0].Err.Error(), `version is "1"`) {
+		t.Errorf("expected an old script to fail on its version, got %v", results[0].Err)
+	}
+	if err := CheckHook(runner.Command(script), runner.Exec{}); err == nil {
+		t.Errorf("expected the health check to fail for an old script")
+	}
+}
+
+func TestUpgradeHookScript(t *testing.T) {
+	dir := t.TempDir()
+	checker := &Checker{hookPath: filepath.Join(dir, "update_status.sh")}
+	edited := hookScript + "# my change\n"
+	if err := os.WriteFile(checker.hookPath, []byte(edited), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !checker.HookOutdated() {
+		t.Errorf("expected an edited script to count as outdated")
+	}
+
+	old, err := checker.UpgradeHookScript()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checker.HookOutdated() {
+		t.Errorf("expected the script to be current after the upgrade")
+	}
+	if data, _ := os.ReadFile(old); string(data) != edited {
+		t.Errorf("expected the edited script to be kept in %s", old)
+	}
+	if err := CheckHook(checker.HookCommand(), runner.Exec{}); err != nil {
+		t.Errorf("expected the upgraded script to pass the health check, got %v", err)
+	}
+}
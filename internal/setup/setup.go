@@ -1,16 +1,25 @@
 package setup
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/dfowler/flock/internal/runner"
 )
 
 const hookScript = `#!/bin/bash
 # Flock status update hook for Claude Code
 # This script updates the status file for a task based on the hook event
 # Installed by flock - safe to run globally (no-op if not in flock context)
+# Status format: version 2 (one JSON object)
 
 # Read input from stdin (JSON from Claude Code)
 INPUT=$(cat)
@@ -32,30 +41,58 @@ if [ -z "$TASK_ID" ]; then
     exit 0
 fi
 
+# json_field prints a string field of the input JSON, with escaped newlines as spaces
+json_field() {
+    printf '%s' "$INPUT" | sed -nE "s/.*\"$1\"[[:space:]]*:[[:space:]]*\"(([^\"\\\\]|\\\\.)*)\".*/\1/p" | head -n 1 |
+        sed -E 's/\\[nrt]/ /g; s/\\(.)/\1/g'
+}
+
+# json_escape makes a value safe inside a JSON string
+json_escape() {
+    printf '%s' "$1" | tr -d '\000-\037' | sed 's/[\\"]/\\&/g'
+}
+
 # Extract hook event name from input JSON
-HOOK_EVENT=$(echo "$INPUT" | sed -n 's/.*"hook_event_name"[[:space:]]*:[[:space:]]*"\([^"]*\)".*/\1/p')
+HOOK_EVENT=$(json_field hook_event_name)
 
 # Fallback to environment variable
 if [ -z "$HOOK_EVENT" ]; then
     HOOK_EVENT="${CLAUDE_HOOK_EVENT_NAME:-}"
 fi
 
-# Map hook event to status
+# Map hook event to status, picking up the tool or message behind it
+TOOL=""
+MESSAGE=""
+NOTIFICATION_TYPE=""
+MESSAGE_LIMIT=200
 case "$HOOK_EVENT" in
     "UserPromptSubmit")
         STATUS="WORKING"
+        MESSAGE=$(json_field prompt)
         ;;
     "PreToolUse")
         STATUS="WORKING"
+        TOOL=$(json_field tool_name)
+        # These tools stop for the user: a question, or approval of a plan
+        case "$TOOL" in
+            "AskUserQuestion"|"ExitPlanMode")
+                STATUS="WAITING"
+                ;;
+        esac
         ;;
     "PostToolUse")
         STATUS="WORKING"
+        TOOL=$(json_field tool_name)
         ;;
     "Notification")
         STATUS="WAITING"
+        MESSAGE=$(json_field message)
+        NOTIFICATION_TYPE=$(json_field notification_type)
         ;;
     "Stop")
         STATUS="DONE"
+        MESSAGE=$(json_field last_assistant_message)
+        MESSAGE_LIMIT=4000
         ;;
     "SubagentStop")
         exit 0
@@ -65,27 +102,52 @@ case "$HOOK_EVENT" in
         ;;
 esac
 
+BODY=$(printf '{"version":2,"status":"%s","task_id":"%s","task_name":"%s","updated":%s,"tab_name":"%s","event":"%s","tool":"%s","message":"%s","notification_type":"%s","session_id":"%s"}' \
+    "$STATUS" "$(json_escape "$TASK_ID")" "$(json_escape "$TASK_NAME")" "$(date +%s)" "$(json_escape "$TAB_NAME")" \
+    "$(json_escape "$HOOK_EVENT")" "$(json_escape "$TOOL")" "$(json_escape "$(printf '%s' "$MESSAGE" | cut -c1-"$MESSAGE_LIMIT")")" \
+    "$(json_escape "$NOTIFICATION_TYPE")" "$(json_escape "$(json_field session_id)")")
+
+# Send the event over flock's events socket when that transport is on; flock acknowledges
+# each event, so only a failed delivery falls through to the status server or file
+if [ -n "${FLOCK_STATUS_EVENTS:-}" ] && [ -x "${FLOCK_BIN:-}" ]; then
+    if printf '%s\n' "$BODY" | "$FLOCK_BIN" status-event "$FLOCK_STATUS_EVENTS" >/dev/null 2>&1; then
+        exit 0
+    fi
+fi
+
+# POST to flock's status server when it is enabled, falling back to the status file
+if [ -n "${FLOCK_STATUS_URL:-}" ] && command -v curl >/dev/null 2>&1; then
+    CURL_ARGS=(-fsS -m 2 -X POST --data-binary @-)
+    if [ -n "${FLOCK_STATUS_SOCKET:-}" ]; then
+        CURL_ARGS+=(--unix-socket "$FLOCK_STATUS_SOCKET")
+    fi
+    if [ -n "${FLOCK_STATUS_TOKEN:-}" ]; then
+        CURL_ARGS+=(-H "Authorization: Bearer $FLOCK_STATUS_TOKEN")
+    fi
+    if printf '%s\n' "$BODY" | curl "${CURL_ARGS[@]}" "$FLOCK_STATUS_URL" >/dev/null 2>&1; then
+        exit 0
+    fi
+fi
+
 # Ensure status directory exists
 mkdir -p "$STATUS_DIR"
 
 # Write status file
 STATUS_FILE="$STATUS_DIR/$TASK_ID.status"
-cat > "$STATUS_FILE" << EOF
-status=$STATUS
-task_id=$TASK_ID
-task_name=$TASK_NAME
-updated=$(date +%s)
-tab_name=$TAB_NAME
-EOF
+printf '%s\n' "$BODY" > "$STATUS_FILE"
 
 exit 0
 `
 
+// HookEvents are the Claude Code hook events flock registers its hook script for
+var HookEvents = []string{"UserPromptSubmit", "PreToolUse", "Notification", "Stop"}
+
 // Result represents the outcome of the setup check
 type Result struct {
 	HooksInstalled   bool
 	SettingsUpdated  bool
 	NeedsUserConsent bool
+	ScriptOutdated   bool // Installed hook script differs from this flock version's script
 	Message          string
 }
 
@@ -95,6 +157,10 @@ type Checker struct {
 	claudeDir    string
 	hookPath     string
 	settingsPath string
+	home         string
+	projectScope bool   // Hooks go in each project's settings as its tasks start (hook_scope "project")
+	projectDir   string // Set on a checker for one project's settings (see ForProject)
+	exe          string // flock's binary, when Claude Code runs `flock hook` instead of the script
 }
 
 // NewChecker creates a new setup checker
@@ -112,9 +178,61 @@ func NewChecker() (*Checker, error) {
 		claudeDir:    claudeDir,
 		hookPath:     filepath.Join(flockDir, "hooks", "update_status.sh"),
 		settingsPath: filepath.Join(claudeDir, "settings.json"),
+		home:         home,
 	}, nil
 }
 
+// SetBuiltinHook has Claude Code run `exe hook` instead of the bash script, so the hook
+// needs no shell (hook_type "builtin"). There is then no script to install or upgrade.
+func (c *Checker) SetBuiltinHook(exe string) {
+	c.exe = exe
+}
+
+// HookCommand returns the command Claude Code runs for the hook, for tests to run it too
+func (c *Checker) HookCommand() runner.Cmd {
+	if c.exe != "" {
+		return runner.Command(c.exe, "hook")
+	}
+	return runner.Command(c.hookPath)
+}
+
+// SetProjectScope registers flock's hook in the .claude/settings.json of each task's
+// directory as the task starts, rather than in the global settings. Check and Install then
+// cover the hook script only.
+func (c *Checker) SetProjectScope(on bool) {
+	c.projectScope = on
+}
+
+// ProjectScope reports whether hooks go in project settings
+func (c *Checker) ProjectScope() bool {
+	return c.projectScope
+}
+
+// ForProject returns a checker for the project settings in dir, .claude/settings.json
+func (c *Checker) ForProject(dir string) *Checker {
+	project := *c
+	project.claudeDir = filepath.Join(dir, ".claude")
+	project.settingsPath = filepath.Join(project.claudeDir, "settings.json")
+	project.projectDir = dir
+	return &project
+}
+
+// InstallProjectHooks registers flock's hook in the project settings in dir, unless every
+// event already runs it, and reports whether the file changed
+func (c *Checker) InstallProjectHooks(dir string) (bool, error) {
+	project := c.ForProject(dir)
+	registered, err := project.RegisteredEvents()
+	if err != nil {
+		return false, err
+	}
+	for _, ok := range registered {
+		if !ok {
+			return true, project.UpdateClaudeSettings()
+		}
+	}
+	return false, nil
+}
+
 // Check verifies if flock hooks are properly configured
 func (c *Checker) Check() (*Result, error) {
 	result := &Result{}
@@ -122,6 +240,19 @@ func (c *Checker) Check() (*Result, error) {
 	// Check if hook script exists
 	hookExists := c.hookScriptExists()
 
+	// Project settings get the hooks as tasks start, so only the script is needed up front
+	if c.projectScope && c.projectDir == "" {
+		if !hookExists {
+			result.NeedsUserConsent = true
+			result.Message = "Hook script needs to be installed"
+			return result, nil
+		}
+		result.HooksInstalled = true
+		result.ScriptOutdated = c.hookScriptOutdated()
+		result.Message = "Flock hook script is installed; projects get its hooks as tasks start"
+		return result, nil
+	}
+
 	// Check if Claude settings has flock hooks
 	hasFlockHooks, err := c.hasFlockHooks()
 	if err != nil {
@@ -130,6 +261,7 @@ func (c *Checker) Check() (*Result, error) {
 
 	if hookExists && hasFlockHooks {
 		result.HooksInstalled = true
+		result.ScriptOutdated = c.hookScriptOutdated()
 		result.Message = "Flock hooks are properly configured"
 		return result, nil
 	}
@@ -160,99 +292,241 @@ func (c *Checker) InstallHookScript() error {
 	return nil
 }
 
-// UpdateClaudeSettings updates the global Claude settings with flock hooks
+// UpdateClaudeSettings adds flock's hook to the Claude settings for each event in
+// HookEvents. Hooks of the user's own for those events are kept; flock's entry is added
+// after them, replacing any flock entry from before, so running it again changes nothing.
 func (c *Checker) UpdateClaudeSettings() error {
 	// Ensure claude directory exists
 	if err := os.MkdirAll(c.claudeDir, 0755); err != nil {
 		return fmt.Errorf("failed to create claude directory: %w", err)
 	}
 
-	// Read existing settings or create empty
-	settings := make(map[string]interface{})
-	data, err := os.ReadFile(c.settingsPath)
-	if err == nil {
-		if err := json.Unmarshal(data, &settings); err != nil {
-			return fmt.Errorf("failed to parse existing settings: %w", err)
-		}
+	settings, err := c.readSettings()
+	if err != nil {
+		return err
+	}
+	hooks, _ := settings["hooks"].(map[string]interface{})
+	if hooks == nil {
+		hooks = make(map[string]interface{})
 	}
 
-	// Create the hook command pointing to our installed script
-	hookCommand := fmt.Sprintf("%q 2>/dev/null || true", c.hookPath)
-
-	// Define the hooks we need
-	flockHooks := map[string]interface{}{
-		"UserPromptSubmit": []interface{}{
-			map[string]interface{}{
-				"hooks": []interface{}{
-					map[string]interface{}{
-						"type":    "command",
-						"command": hookCommand,
-					},
-				},
-			},
-		},
-		"PreToolUse": []interface{}{
-			map[string]interface{}{
-				"matcher": "*",
-				"hooks": []interface{}{
-					map[string]interface{}{
-						"type":    "command",
-						"command": hookCommand,
-					},
+	hookCommand := c.settingsCommand()
+	for _, event := range HookEvents {
+		entry := map[string]interface{}{
+			"hooks": []interface{}{
+				map[string]interface{}{
+					"type":    "command",
+					"command": hookCommand,
 				},
 			},
-		},
-		"Notification": []interface{}{
-			map[string]interface{}{
-				"hooks": []interface{}{
-					map[string]interface{}{
-						"type":    "command",
-						"command": hookCommand,
-					},
-				},
-			},
-		},
-		"Stop": []interface{}{
-			map[string]interface{}{
-				"hooks": []interface{}{
-					map[string]interface{}{
-						"type":    "command",
-						"command": hookCommand,
-					},
-				},
-			},
-		},
+		}
+		if event == "PreToolUse" {
+			entry["matcher"] = "*"
+		}
+		groups, _ := hooks[event].([]interface{})
+		hooks[event] = append(c.withoutFlockHooks(groups), entry)
 	}
+	settings["hooks"] = hooks
+	return c.writeSettings(settings)
+}
 
-	// Merge with existing hooks or set new
-	existingHooks, ok := settings["hooks"].(map[string]interface{})
+// RemoveClaudeSettings removes flock's hooks from the Claude settings, leaving
+// every other hook and setting as it was. Events left without hooks are dropped.
+func (c *Checker) RemoveClaudeSettings() error {
+	settings, err := c.readSettings()
+	if err != nil {
+		return err
+	}
+	hooks, _ := settings["hooks"].(map[string]interface{})
+	if hooks == nil {
+		return nil
+	}
+	for event, value := range hooks {
+		groups, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		if groups = c.withoutFlockHooks(groups); len(groups) > 0 {
+			hooks[event] = groups
+		} else {
+			delete(hooks, event)
+		}
+	}
+	if len(hooks) == 0 {
+		delete(settings, "hooks")
+	}
+	return c.writeSettings(settings)
+}
+
+// withoutFlockHooks returns an event's hook groups without flock's hook commands,
+// dropping groups that had nothing else
+func (c *Checker) withoutFlockHooks(groups []interface{}) []interface{} {
+	var kept []interface{}
+	for _, g := range groups {
+		group, ok := g.(map[string]interface{})
+		if !ok {
+			kept = append(kept, g)
+			continue
+		}
+		commands, _ := group["hooks"].([]interface{})
+		var others []interface{}
+		for _, h := range commands {
+			if hook, ok := h.(map[string]interface{}); ok && c.isFlockCommand(hook["command"]) {
+				continue
+			}
+			others = append(others, h)
+		}
+		if len(others) == 0 && len(commands) > 0 {
+			continue
+		}
+		if len(others) < len(commands) {
+			// Copied, so the settings read in stay untouched
+			copied := make(map[string]interface{}, len(group))
+			for k, v := range group {
+				copied[k] = v
+			}
+			copied["hooks"] = others
+			group = copied
+		}
+		kept = append(kept, group)
+	}
+	return kept
+}
+
+// legacyProjectCommand is the hook command flock's project settings used before the script
+// moved to ~/.flock/hooks
+const legacyProjectCommand = `"${FLOCK_PROJECT_DIR:-$CLAUDE_PROJECT_DIR}"/.claude/hooks/update_status.sh`
+
+// isFlockCommand reports whether a hook command is one flock registered: its hook script,
+// at its current path or as older versions registered it, or flock's built-in hook. Only
+// the exact commands flock writes match, so a user's hook that merely mentions flock is kept.
+func (c *Checker) isFlockCommand(command interface{}) bool {
+	s, _ := command.(string)
+	if s == legacyProjectCommand {
+		return true
+	}
+	target, builtin, ok := parseHookCommand(s)
 	if !ok {
-		existingHooks = make(map[string]interface{})
+		return false
+	}
+	if builtin {
+		name := path.Base(strings.ReplaceAll(target, `\`, "/"))
+		return target == c.exe || name == "flock" || name == "flock.exe"
+	}
+	return target == c.hookPath || strings.HasSuffix(filepath.ToSlash(target), "/.flock/hooks/update_status.sh")
+}
+
+// parseHookCommand splits a command in the form settingsCommand writes into the quoted path
+// it runs and whether it runs that path's built-in hook
+func parseHookCommand(command string) (target string, builtin bool, ok bool) {
+	command = strings.TrimSuffix(command, " 2>/dev/null || true")
+	command, builtin = strings.CutSuffix(command, " hook")
+	if len(command) < 2 || command[0] != '"' || command[len(command)-1] != '"' {
+		return "", false, false
 	}
+	target, err := strconv.Unquote(command)
+	if err != nil {
+		// Windows commands quote the path without escaping its backslashes
+		target = command[1 : len(command)-1]
+		if strings.Contains(target, `"`) {
+			return "", false, false
+		}
+	}
+	return target, builtin, target != ""
+}
 
-	// Add our hooks (this will override existing hooks for these events)
-	for event, hook := range flockHooks {
-		existingHooks[event] = hook
+// settingsCommand returns the hook command flock registers in Claude's settings
+func (c *Checker) settingsCommand() string {
+	if c.exe != "" && runtime.GOOS == "windows" {
+		// Not run by a POSIX shell: no redirect, and the backslashes left as they are
+		return `"` + c.exe + `" hook`
 	}
-	settings["hooks"] = existingHooks
+	command := fmt.Sprintf("%q", c.scriptRef())
+	if c.exe != "" {
+		command += " hook"
+	}
+	return command + " 2>/dev/null || true"
+}
 
-	// Write back with nice formatting
-	output, err := json.MarshalIndent(settings, "", "  ")
+// scriptRef returns how hook commands refer to what they run. Project settings are often
+// committed and shared, so there the script is written relative to $HOME rather than this home.
+func (c *Checker) scriptRef() string {
+	if c.exe != "" {
+		return c.exe
+	}
+	if c.projectDir != "" && c.home != "" {
+		if rel, ok := strings.CutPrefix(c.hookPath, c.home+string(filepath.Separator)); ok {
+			return "$HOME/" + filepath.ToSlash(rel)
+		}
+	}
+	return c.hookPath
+}
+
+// readSettings reads the Claude settings, empty if there are none yet
+func (c *Checker) readSettings() (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	data, err := os.ReadFile(c.settingsPath)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to marshal settings: %w", err)
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse existing settings: %w", err)
 	}
+	return settings, nil
+}
 
-	if err := os.WriteFile(c.settingsPath, output, 0644); err != nil {
+// writeSettings writes the Claude settings back with nice formatting. The hook command's
+// redirect is kept as written rather than escaped, since project settings get reviewed.
+func (c *Checker) writeSettings(settings map[string]interface{}) error {
+	var output bytes.Buffer
+	encoder := json.NewEncoder(&output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(settings); err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if err := os.WriteFile(c.settingsPath, output.Bytes(), 0644); err != nil {
 		return fmt.Errorf("failed to write settings: %w", err)
 	}
-
 	return nil
 }
 
-// Install performs the full installation
+// HookOutdated reports whether the installed hook script differs from the one this flock
+// ships, because another version wrote it or it was edited since
+func (c *Checker) HookOutdated() bool {
+	return c.hookScriptOutdated()
+}
+
+// UpgradeHookScript replaces the installed hook script with this version's in place,
+// keeping the old one as update_status.sh.old, and returns where it was kept
+func (c *Checker) UpgradeHookScript() (string, error) {
+	if c.exe != "" {
+		return "", errors.New("the built-in hook has no script to upgrade")
+	}
+	data, err := os.ReadFile(c.hookPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read hook script: %w", err)
+	}
+	old := c.hookPath + ".old"
+	if err := os.WriteFile(old, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to keep the old hook script: %w", err)
+	}
+	return old, c.InstallHookScript()
+}
+
+// Install performs the full installation. With project scope that is the script alone,
+// and the built-in hook has no script.
 func (c *Checker) Install() error {
-	if err := c.InstallHookScript(); err != nil {
-		return err
+	if c.exe == "" {
+		if err := c.InstallHookScript(); err != nil {
+			return err
+		}
+	}
+	if c.projectScope && c.projectDir == "" {
+		return nil
 	}
 	if err := c.UpdateClaudeSettings(); err != nil {
 		return err
@@ -260,8 +534,11 @@ func (c *Checker) Install() error {
 	return nil
 }
 
-// hookScriptExists checks if our hook script is installed
+// hookScriptExists checks if our hook script is installed (the built-in hook needs none)
 func (c *Checker) hookScriptExists() bool {
+	if c.exe != "" {
+		return true
+	}
 	info, err := os.Stat(c.hookPath)
 	if err != nil {
 		return false
@@ -269,6 +546,15 @@ func (c *Checker) hookScriptExists() bool {
 	return !info.IsDir()
 }
 
+// hookScriptOutdated checks if the installed hook script was written by another flock version
+func (c *Checker) hookScriptOutdated() bool {
+	if c.exe != "" {
+		return false
+	}
+	data, err := os.ReadFile(c.hookPath)
+	return err == nil && string(data) != hookScript
+}
+
 // hasFlockHooks checks if Claude settings has flock hooks configured
 func (c *Checker) hasFlockHooks() (bool, error) {
 	data, err := os.ReadFile(c.settingsPath)
@@ -289,6 +575,20 @@ func (c *Checker) hasFlockHooks() (bool, error) {
 		return false, nil
 	}
 
+	// The built-in hook must run this binary, so entries for the script don't count
+	if c.exe != "" {
+		registered, err := c.RegisteredEvents()
+		if err != nil {
+			return false, err
+		}
+		for _, ok := range registered {
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
 	// Check if any hook references our flock hook path
 	hookJSON, _ := json.Marshal(hooks)
 	hookStr := string(hookJSON)
@@ -303,8 +603,12 @@ func (c *Checker) GetSettingsPath() string {
 	return c.settingsPath
 }
 
-// GetHookPath returns the path where hooks will be installed
+// GetHookPath returns the path where hooks will be installed, or flock's own binary with
+// the built-in hook
 func (c *Checker) GetHookPath() string {
+	if c.exe != "" {
+		return c.exe
+	}
 	return c.hookPath
 }
 
@@ -0,0 +1,220 @@
+package setup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestClaudeSettingsMerge(t *testing.T) {
+	dir := t.TempDir()
+	checker := &Checker{
+		claudeDir:    dir,
+		hookPath:     "/home/me/.flock/hooks/update_status.sh",
+		settingsPath: filepath.Join(dir, "settings.json"),
+	}
+	existing := `{
+  "model": "opus",
+  "hooks": {
+    "PreToolUse": [
+      {"matcher": "Bash", "hooks": [{"type": "command", "command": "~/bin/audit.sh"}, {"type": "command", "command": "echo \"$FLOCK_PROJECT_DIR\" >> ~/flock-tools.log"}]},
+      {"matcher": "*", "hooks": [{"type": "command", "command": "\"/old/.flock/hooks/update_status.sh\" 2>/dev/null || true"}]}
+    ],
+    "SessionStart": [{"hooks": [{"type": "command", "command": "~/bin/greet.sh"}]}]
+  }
+}`
+	if err := os.WriteFile(checker.settingsPath, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Installing twice must leave one flock entry per event
+	for i := 0; i < 2; i++ {
+		if err := checker.UpdateClaudeSettings(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settings := readTestSettings(t, checker.settingsPath)
+	tests := []struct {
+		event string
+		want  []string
+	}{
+		{"PreToolUse", []string{"~/bin/audit.sh", `echo "$FLOCK_PROJECT_DIR" >> ~/flock-tools.log`, `"/home/me/.flock/hooks/update_status.sh" 2>/dev/null || true`}},
+		{"Stop", []string{`"/home/me/.flock/hooks/update_status.sh" 2>/dev/null || true`}},
+		{"SessionStart", []string{"~/bin/greet.sh"}},
+	}
+	for _, tt := range tests {
+		if got := settings.commands(tt.event); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %q, got %q", tt.event, tt.want, got)
+		}
+	}
+	if settings.Model != "opus" {
+		t.Errorf("expected other settings to be kept, got model %q", settings.Model)
+	}
+	registered, err := checker.RegisteredEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range HookEvents {
+		if !registered[event] {
+			t.Errorf("expected %s to be registered", event)
+		}
+	}
+
+	if err := checker.RemoveClaudeSettings(); err != nil {
+		t.Fatal(err)
+	}
+	settings = readTestSettings(t, checker.settingsPath)
+	if got := settings.commands("PreToolUse"); !slices.Equal(got, []string{"~/bin/audit.sh", `echo "$FLOCK_PROJECT_DIR" >> ~/flock-tools.log`}) {
+		t.Errorf("expected only the user's PreToolUse hook after uninstall, got %q", got)
+	}
+	if got := settings.commands("SessionStart"); len(got) != 1 {
+		t.Errorf("expected the user's SessionStart hook to be kept, got %q", got)
+	}
+	if _, ok := settings.Hooks["Stop"]; ok {
+		t.Errorf("expected Stop, left without hooks, to be removed")
+	}
+}
+
+func TestIsFlockCommand(t *testing.T) {
+	checker := &Checker{hookPath: "/home/me/.flock/hooks/update_status.sh", exe: "/opt/flock/bin/flock"}
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{`"/home/me/.flock/hooks/update_status.sh" 2>/dev/null || true`, true},
+		{`"$HOME/.flock/hooks/update_status.sh" 2>/dev/null || true`, true},
+		{`"/old/home/.flock/hooks/update_status.sh" 2>/dev/null || true`, true},
+		{`"${FLOCK_PROJECT_DIR:-$CLAUDE_PROJECT_DIR}"/.claude/hooks/update_status.sh`, true},
+		{`"/opt/flock/bin/flock" hook 2>/dev/null || true`, true},
+		{`"/usr/local/bin/flock" hook 2>/dev/null || true`, true},
+		{`"C:\Users\me\bin\flock.exe" hook`, true},
+		{`echo "$FLOCK_PROJECT_DIR" >> ~/flock.log`, false},
+		{`/opt/flock/bin/flock notify "build done"`, false},
+		{`"/opt/flock/bin/flock" status 2>/dev/null || true`, false},
+		{`~/bin/flock-audit.sh`, false},
+		{`cat /home/me/.flock/hooks/update_status.sh`, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := checker.isFlockCommand(tt.command); got != tt.want {
+			t.Errorf("isFlockCommand(%q): expected %v, got %v", tt.command, tt.want, got)
+		}
+	}
+}
+
+func TestProjectHooks(t *testing.T) {
+	home := t.TempDir()
+	checker := &Checker{
+		claudeDir:    filepath.Join(home, ".claude"),
+		hookPath:     filepath.Join(home, ".flock", "hooks", "update_status.sh"),
+		settingsPath: filepath.Join(home, ".claude", "settings.json"),
+		home:         home,
+	}
+	checker.SetProjectScope(true)
+
+	// Only the script is installed up front
+	if result, err := checker.Check(); err != nil || result.HooksInstalled {
+		t.Fatalf("expected the missing script to need installing, got %+v, %v", result, err)
+	}
+	if err := checker.Install(); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := checker.Check(); err != nil || !result.HooksInstalled {
+		t.Errorf("expected the script alone to count as installed, got %+v, %v", result, err)
+	}
+	if _, err := os.Stat(checker.settingsPath); !os.IsNotExist(err) {
+		t.Errorf("expected the global settings to be left alone, got %v", err)
+	}
+
+	project := t.TempDir()
+	for i, want := range []bool{true, false} {
+		changed, err := checker.InstallProjectHooks(project)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != want {
+			t.Errorf("install %d: expected changed=%v, got %v", i+1, want, changed)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(project, ".claude", "settings.json"))
+	if err != nil || !strings.Contains(string(data), "2>/dev/null") {
+		t.Errorf("expected the redirect to be written unescaped, got %s", data)
+	}
+	settings := readTestSettings(t, filepath.Join(project, ".claude", "settings.json"))
+	want := []string{`"$HOME/.flock/hooks/update_status.sh" 2>/dev/null || true`}
+	for _, event := range HookEvents {
+		if got := settings.commands(event); !slices.Equal(got, want) {
+			t.Errorf("%s: expected %q, got %q", event, want, got)
+		}
+	}
+}
+
+func TestBuiltinHook(t *testing.T) {
+	dir := t.TempDir()
+	checker := &Checker{
+		claudeDir:    dir,
+		hookPath:     filepath.Join(dir, "hooks", "update_status.sh"),
+		settingsPath: filepath.Join(dir, "settings.json"),
+	}
+	// Registered for the script, which the built-in hook replaces
+	if err := checker.Install(); err != nil {
+		t.Fatal(err)
+	}
+	checker.SetBuiltinHook("/opt/flock/bin/flock")
+	if result, err := checker.Check(); err != nil || result.HooksInstalled {
+		t.Errorf("expected the script's entries not to count for the built-in hook, got %+v, %v", result, err)
+	}
+
+	if err := checker.Install(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := checker.Check()
+	if err != nil || !result.HooksInstalled || result.ScriptOutdated {
+		t.Errorf("expected the built-in hook to be installed and current, got %+v, %v", result, err)
+	}
+	settings := readTestSettings(t, checker.settingsPath)
+	want := []string{`"/opt/flock/bin/flock" hook 2>/dev/null || true`}
+	if got := settings.commands("Stop"); !slices.Equal(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := checker.HookCommand().String(); got != "/opt/flock/bin/flock hook" {
+		t.Errorf("expected the hook to run flock hook, got %q", got)
+	}
+}
+
+// testSettings is the part of Claude's settings the tests look at
+type testSettings struct {
+	Model string `json:"model"`
+	Hooks map[string][]struct {
+		Hooks []struct {
+			Command string `json:"command"`
+		} `json:"hooks"`
+	} `json:"hooks"`
+}
+
+// commands lists an event's hook commands in order
+func (s testSettings) commands(event string) []string {
+	var commands []string
+	for _, group := range s.Hooks[event] {
+		for _, hook := range group.Hooks {
+			commands = append(commands, hook.Command)
+		}
+	}
+	return commands
+}
+
+func readTestSettings(t *testing.T, path string) testSettings {
+	t.Helper()
+	var settings testSettings
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	return settings
+}
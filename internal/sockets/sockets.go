@@ -0,0 +1,34 @@
+// Package sockets opens the listeners flock serves status updates and its API on
+package sockets
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// Listen listens on network and address. A unix socket left at address by a previous run
+// is replaced and the new one is made private to the user; anything else at the path is
+// left alone and the listen fails, so a mistyped address can't delete a file.
+func Listen(network, address string) (net.Listener, error) {
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+	if info, err := os.Lstat(address); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", address)
+		}
+		if err := os.Remove(address); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict %s: %w", address, err)
+	}
+	return listener, nil
+}
@@ -0,0 +1,40 @@
+package sockets
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "status.sock")
+
+	// A socket from a previous run is replaced
+	for i := 0; i < 2; i++ {
+		listener, err := Listen("unix", path)
+		if err != nil {
+			t.Fatalf("Listen failed: %v", err)
+		}
+		info, err := os.Lstat(path)
+		if err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("expected a socket only the user can use, got %v (%v)", info.Mode(), err)
+		}
+		// Leave the socket file behind, like a crashed run
+		listener.(*net.UnixListener).SetUnlinkOnClose(false)
+		listener.Close()
+	}
+
+	// Anything else is left alone
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen("unix", file); err == nil {
+		t.Errorf("expected Listen to refuse a path that isn't a socket")
+	}
+	if data, err := os.ReadFile(file); err != nil || string(data) != "keep me" {
+		t.Errorf("expected the file to be kept, got %q (%v)", data, err)
+	}
+}
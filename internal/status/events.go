@@ -0,0 +1,110 @@
+package status
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/sockets"
+)
+
+// eventTimeout bounds how long a hook waits for flock to acknowledge a status event
+const eventTimeout = 2 * time.Second
+
+// listenEvents starts the events socket: hooks connect and send status updates as JSON
+// lines, and each line is applied in arrival order and acknowledged with "ok" before the
+// next is read, so a hook knows its update arrived. It shuts down when the watcher stops.
+func (w *Watcher) listenEvents(path string) error {
+	listener, err := sockets.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to listen for status events on %s: %w", path, err)
+	}
+
+	w.spawn(func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					slog.Error("failed to accept status event", "err", err)
+				}
+				return
+			}
+			w.spawn(func() { w.handleEvents(conn) })
+		}
+	})
+	w.spawn(func() {
+		<-w.ctx.Done()
+		listener.Close()
+		os.Remove(path)
+	})
+	return nil
+}
+
+// handleEvents applies the status updates sent on one connection, replying to each line
+func (w *Watcher) handleEvents(conn net.Conn) {
+	defer conn.Close()
+	// A client that keeps its connection open mustn't hold up Stop
+	defer context.AfterFunc(w.ctx, func() { conn.Close() })()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxStatusBody)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		reply := "ok"
+		status, err := ParseStatus(strings.NewReader(line))
+		switch {
+		case err != nil:
+			reply = "error: " + err.Error()
+		case status.Status == "":
+			reply = "error: missing status"
+		default:
+			if status.Updated == 0 {
+				status.Updated = time.Now().Unix()
+			}
+			w.applyEvent(status)
+		}
+		if _, err := fmt.Fprintln(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// applyEvent applies socket events one at a time, so updates keep the order they arrived in
+func (w *Watcher) applyEvent(status *Status) {
+	w.eventsMu.Lock()
+	defer w.eventsMu.Unlock()
+	w.apply(status)
+}
+
+// SendEvent delivers one status update over the events socket and waits for flock to
+// acknowledge it. Hook scripts run `flock status-event` for this and fall back to
+// writing a status file when it fails.
+func SendEvent(socketPath string, data []byte) error {
+	conn, err := net.DialTimeout("unix", socketPath, eventTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", socketPath, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(eventTimeout))
+
+	line := strings.ReplaceAll(strings.TrimSpace(string(data)), "\n", " ")
+	if _, err := fmt.Fprintln(conn, line); err != nil {
+		return fmt.Errorf("failed to send status event: %w", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("no acknowledgement for status event: %w", err)
+	}
+	if reply = strings.TrimSpace(reply); reply != "ok" {
+		return fmt.Errorf("status event rejected: %s", strings.TrimPrefix(reply, "error: "))
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dfowler/flock/internal/multiplexer"
+)
+
+// Longest messages a hook status carries, as the hook script keeps them: the start of a
+// prompt or notification, and most of the final message on Stop
+const (
+	hookMessageLimit      = 200
+	hookFinalMessageLimit = 4000
+)
+
+// hookPostTimeout bounds a POST to the status server, like the script's curl -m 2
+const hookPostTimeout = 2 * time.Second
+
+// hookInput is the part of the JSON Claude Code passes its hooks that flock reads
+type hookInput struct {
+	HookEventName        string `json:"hook_event_name"`
+	SessionID            string `json:"session_id"`
+	Prompt               string `json:"prompt"`
+	ToolName             string `json:"tool_name"`
+	Message              string `json:"message"`
+	NotificationType     string `json:"notification_type"`
+	LastAssistantMessage string `json:"last_assistant_message"`
+}
+
+// FromHook builds the status a Claude Code hook event reports, as the hook script does,
+// from the event's JSON and the variables flock starts the agent with. It returns false
+// outside a flock task (no FLOCK_TASK_ID) and for events that don't change the status.
+func FromHook(input []byte, getenv func(string) string, now time.Time) (*Status, bool) {
+	taskID := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, getenv("FLOCK_TASK_ID"))
+	if taskID == "" {
+		return nil, false
+	}
+
+	// Like the script, make do with whatever fields parse
+	var in hookInput
+	json.Unmarshal(input, &in)
+	if in.HookEventName == "" {
+		in.HookEventName = getenv("CLAUDE_HOOK_EVENT_NAME")
+	}
+
+	s := &Status{
+		Version:   Version,
+		TaskID:    taskID,
+		TaskName:  getenv("FLOCK_TASK_NAME"),
+		Updated:   now.Unix(),
+		TabName:   getenv("FLOCK_TAB_NAME"),
+		SessionID: in.SessionID,
+		Event:     in.HookEventName,
+	}
+	switch in.HookEventName {
+	case "UserPromptSubmit":
+		s.Status = "WORKING"
+		s.Message = truncateMessage(in.Prompt, hookMessageLimit)
+	case "PreToolUse":
+		s.Status = "WORKING"
+		s.Tool = in.ToolName
+		// These tools stop for the user: a question, or approval of a plan
+		if s.Tool == "AskUserQuestion" || s.Tool == "ExitPlanMode" {
+			s.Status = "WAITING"
+		}
+	case "PostToolUse":
+		s.Status = "WORKING"
+		s.Tool = in.ToolName
+	case "Notification":
+		s.Status = "WAITING"
+		s.Message = truncateMessage(in.Message, hookMessageLimit)
+		s.NotificationType = in.NotificationType
+	case "Stop":
+		s.Status = "DONE"
+		s.Message = truncateMessage(in.LastAssistantMessage, hookFinalMessageLimit)
+	default:
+		return nil, false
+	}
+	return s, true
+}
+
+// DeliverHook hands a hook's status to flock the way the agent's environment asks: over
+// the events socket, else to the status server, else as a status file in FLOCK_STATUS_DIR.
+// Like the script, a failed delivery falls through to the next way.
+func DeliverHook(s *Status, getenv func(string) string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if socket := getenv("FLOCK_STATUS_EVENTS"); socket != "" {
+		if SendEvent(socket, data) == nil {
+			return nil
+		}
+	}
+	if url := getenv("FLOCK_STATUS_URL"); url != "" {
+		if postStatus(url, getenv("FLOCK_STATUS_SOCKET"), getenv("FLOCK_STATUS_TOKEN"), data) == nil {
+			return nil
+		}
+	}
+
+	dir := getenv("FLOCK_STATUS_DIR")
+	if dir == "" {
+		return errors.New("FLOCK_STATUS_DIR is not set")
+	}
+	if err := multiplexer.EnsureStatusDir(dir); err != nil {
+		return err
+	}
+	return WriteStatusFile(filepath.Join(dir, s.TaskID+".status"), s)
+}
+
+// postStatus POSTs a status update to the status server, over socket when it listens on one
+func postStatus(url, socket, token string, data []byte) error {
+	client := &http.Client{Timeout: hookPostTimeout}
+	if socket != "" {
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		}
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status server replied %s", resp.Status)
+	}
+	return nil
+}
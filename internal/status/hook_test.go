@@ -0,0 +1,93 @@
+package status
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromHook(t *testing.T) {
+	vars := map[string]string{"FLOCK_TASK_ID": " 007\n", "FLOCK_TASK_NAME": "fix tests", "FLOCK_TAB_NAME": "fix-tests"}
+	getenv := func(key string) string { return vars[key] }
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name  string
+		input string
+		want  *Status // nil when the event reports nothing
+	}{
+		{
+			name:  "prompt",
+			input: `{"session_id":"s1","hook_event_name":"UserPromptSubmit","prompt":"Fix the \"login\" test"}`,
+			want:  &Status{Status: "WORKING", Event: "UserPromptSubmit", SessionID: "s1", Message: `Fix the "login" test`},
+		},
+		{
+			name:  "tool",
+			input: `{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"go test ./..."}}`,
+			want:  &Status{Status: "WORKING", Event: "PreToolUse", Tool: "Bash"},
+		},
+		{
+			name:  "question",
+			input: `{"hook_event_name":"PreToolUse","tool_name":"AskUserQuestion"}`,
+			want:  &Status{Status: "WAITING", Event: "PreToolUse", Tool: "AskUserQuestion"},
+		},
+		{
+			name:  "notification",
+			input: `{"hook_event_name":"Notification","message":"Claude needs your permission to use Bash","notification_type":"permission_prompt"}`,
+			want:  &Status{Status: "WAITING", Event: "Notification", Message: "Claude needs your permission to use Bash", NotificationType: "permission_prompt"},
+		},
+		{
+			name:  "stop",
+			input: `{"hook_event_name":"Stop","last_assistant_message":"Added the test.\nAll tests pass."}`,
+			want:  &Status{Status: "DONE", Event: "Stop", Message: "Added the test. All tests pass."},
+		},
+		{name: "subagent", input: `{"hook_event_name":"SubagentStop"}`},
+		{name: "unparsable", input: `{"hook_event_name":`},
+	}
+	for _, tt := range tests {
+		got, ok := FromHook([]byte(tt.input), getenv, now)
+		if tt.want == nil {
+			if ok {
+				t.Errorf("%s: expected no status, got %+v", tt.name, got)
+			}
+			continue
+		}
+		want := *tt.want
+		want.Version, want.TaskID, want.TaskName, want.TabName, want.Updated = Version, "007", "fix tests", "fix-tests", now.Unix()
+		if !ok || *got != want {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, want, got)
+		}
+	}
+
+	if _, ok := FromHook([]byte(`{"hook_event_name":"Stop"}`), func(string) string { return "" }, now); ok {
+		t.Errorf("expected no status outside a flock task")
+	}
+}
+
+func TestDeliverHook(t *testing.T) {
+	dir := t.TempDir()
+	vars := map[string]string{
+		"FLOCK_STATUS_DIR": dir,
+		// Neither is listening, so the update falls through to the status file
+		"FLOCK_STATUS_EVENTS": filepath.Join(dir, "events.sock"),
+		"FLOCK_STATUS_URL":    "http://localhost/status",
+		"FLOCK_STATUS_SOCKET": filepath.Join(dir, "status.sock"),
+	}
+	s := &Status{Version: Version, Status: "WAITING", TaskID: "007", Event: "Notification", Message: "Permission needed"}
+	if err := DeliverHook(s, func(key string) string { return vars[key] }); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseStatusFile(filepath.Join(dir, "007.status"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *s {
+		t.Errorf("expected %+v, got %+v", s, got)
+	}
+
+	delete(vars, "FLOCK_STATUS_DIR")
+	if err := DeliverHook(s, func(key string) string { return vars[key] }); err == nil || !strings.Contains(err.Error(), "FLOCK_STATUS_DIR") {
+		t.Errorf("expected an error without a status directory, got %v", err)
+	}
+}
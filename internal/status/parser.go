@@ -2,20 +2,33 @@ package status
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 )
 
+// Version is the status file format flock writes. Version 2 is a JSON object carrying the
+// hook event behind the status; version 1 files of key=value lines are still read.
+const Version = 2
+
 // Status represents parsed status file data
 type Status struct {
-	Status    string
-	TaskID    string
-	TaskName  string
-	Updated   int64
-	TabName   string
-	SessionID string
+	Version   int    `json:"version"`
+	Status    string `json:"status"`
+	TaskID    string `json:"task_id"`
+	TaskName  string `json:"task_name,omitempty"`
+	Updated   int64  `json:"updated"`
+	TabName   string `json:"tab_name,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	Event     string `json:"event,omitempty"`   // Hook event that reported the status, e.g. PreToolUse (v2)
+	Tool      string `json:"tool,omitempty"`    // Tool the agent is running, for tool events (v2)
+	Message   string `json:"message,omitempty"` // Start of the notification, prompt or final message behind the status (v2)
+	// Claude Code's kind of notification, e.g. permission_prompt, for Notification events (v2)
+	NotificationType string `json:"notification_type,omitempty"`
 }
 
 // ParseStatusFile parses a status file
@@ -25,9 +38,53 @@ func ParseStatusFile(path string) (*Status, error) {
 		return nil, err
 	}
 	defer file.Close()
+	return ParseStatus(file)
+}
+
+// ParseStatus parses status data in either status file format, as written by the hook
+// script or POSTed to the status server
+func ParseStatus(r io.Reader) (*Status, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var status *Status
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) {
+		status, err = parseJSON(trimmed)
+	} else {
+		status, err = parseLines(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status.TaskID == "" {
+		return nil, fmt.Errorf("missing task_id in status file")
+	}
 
+	return status, nil
+}
+
+// parseJSON parses a version 2 status file
+func parseJSON(data []byte) (*Status, error) {
 	status := &Status{}
-	scanner := bufio.NewScanner(file)
+	if err := json.Unmarshal(data, status); err != nil {
+		return nil, fmt.Errorf("invalid status file: %w", err)
+	}
+	if status.Version > Version {
+		return nil, fmt.Errorf("unsupported status file version %d", status.Version)
+	}
+	if status.Version == 0 {
+		status.Version = Version
+	}
+	return status, nil
+}
+
+// parseLines parses a version 1 status file of key=value lines
+func parseLines(data []byte) (*Status, error) {
+	status := &Status{Version: 1}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -64,40 +121,16 @@ func ParseStatusFile(path string) (*Status, error) {
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
-
-	if status.TaskID == "" {
-		return nil, fmt.Errorf("missing task_id in status file")
-	}
-
 	return status, nil
 }
 
-// WriteStatusFile writes a status file
+// WriteStatusFile writes a status file in the current format
 func WriteStatusFile(path string, status *Status) error {
-	file, err := os.Create(path)
+	v2 := *status
+	v2.Version = Version
+	data, err := json.Marshal(&v2)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	lines := []string{
-		fmt.Sprintf("status=%s", status.Status),
-		fmt.Sprintf("task_id=%s", status.TaskID),
-		fmt.Sprintf("updated=%d", status.Updated),
-	}
-
-	if status.TabName != "" {
-		lines = append(lines, fmt.Sprintf("tab_name=%s", status.TabName))
-	}
-	if status.SessionID != "" {
-		lines = append(lines, fmt.Sprintf("session_id=%s", status.SessionID))
-	}
-
-	for _, line := range lines {
-		if _, err := file.WriteString(line + "\n"); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return os.WriteFile(path, append(data, '\n'), 0644)
 }
@@ -0,0 +1,88 @@
+package status
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfowler/flock/internal/task"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Status
+		wantErr  bool
+	}{
+		{
+			name:     "version 1",
+			input:    "status=WAITING\ntask_id=001\ntask_name=fix tests\nupdated=1700000000\ntab_name=fix-tests\n",
+			expected: Status{Version: 1, Status: "WAITING", TaskID: "001", TaskName: "fix tests", Updated: 1700000000, TabName: "fix-tests"},
+		},
+		{
+			name: "version 2",
+			input: `{"version":2,"status":"WORKING","task_id":"002","updated":1700000000,"event":"PreToolUse",` +
+				`"tool":"Bash","message":"","session_id":"abc"}`,
+			expected: Status{Version: 2, Status: "WORKING", TaskID: "002", Updated: 1700000000, Event: "PreToolUse", Tool: "Bash", SessionID: "abc"},
+		},
+		{
+			name:     "version 2 without a version",
+			input:    `  {"status":"DONE","task_id":"003"}` + "\n",
+			expected: Status{Version: 2, Status: "DONE", TaskID: "003"},
+		},
+		{name: "newer version", input: `{"version":3,"status":"DONE","task_id":"003"}`, wantErr: true},
+		{name: "broken JSON", input: `{"status":"DONE",`, wantErr: true},
+		{name: "missing task ID", input: "status=DONE\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStatus(strings.NewReader(tt.input))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, expected error: %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && *got != tt.expected {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected, *got)
+		}
+	}
+}
+
+func TestWriteStatusFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "004.status")
+	written := Status{Status: "WAITING", TaskID: "004", TaskName: `say "hi"`, Updated: 1700000000, Message: "Proceed?"}
+	if err := WriteStatusFile(path, &written); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseStatusFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written.Version = Version
+	if *got != written {
+		t.Errorf("expected %+v, got %+v", written, *got)
+	}
+}
+
+func TestWaitReason(t *testing.T) {
+	tests := []struct {
+		status   Status
+		expected task.WaitReason
+	}{
+		{Status{Status: "WAITING", Event: "Notification", Message: "Claude needs your permission to use Bash", NotificationType: "permission_prompt"}, task.WaitPermission},
+		{Status{Status: "WAITING", Event: "Notification", Message: "Claude needs your permission to use Bash"}, task.WaitPermission},
+		{Status{Status: "WAITING", Event: "Notification", Message: "Claude needs your permission to use ExitPlanMode"}, task.WaitPlan},
+		{Status{Status: "WAITING", Event: "PreToolUse", Tool: "ExitPlanMode"}, task.WaitPlan},
+		{Status{Status: "WAITING", Event: "PreToolUse", Tool: "AskUserQuestion"}, task.WaitQuestion},
+		{Status{Status: "WAITING", Event: "Notification", Message: "Claude Code needs your attention", NotificationType: "elicitation_dialog"}, task.WaitQuestion},
+		{Status{Status: "WAITING", Event: "Notification", Message: "Claude is waiting for your input", NotificationType: "idle_prompt"}, task.WaitInput},
+		{Status{Status: "WAITING"}, task.WaitInput},
+		{Status{Status: "WORKING", Tool: "AskUserQuestion"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.WaitReason(); got != tt.expected {
+			t.Errorf("WaitReason(%+v) = %q, expected %q", tt.status, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package status
+
+import (
+	"strings"
+
+	"github.com/dfowler/flock/internal/task"
+)
+
+// Claude Code's notification types, sent with the Notification hook
+const (
+	notificationPermission  = "permission_prompt"
+	notificationElicitation = "elicitation_dialog"
+)
+
+// Tools with which Claude Code stops for the user
+const (
+	toolAskUserQuestion = "AskUserQuestion"
+	toolExitPlanMode    = "ExitPlanMode"
+)
+
+// WaitReason works out why a WAITING agent stopped from the hook payload: a question or plan
+// approval by the tool it uses (or asks permission for), a permission prompt by the
+// notification, and otherwise it is waiting for its next instruction. Other statuses have none.
+func (s *Status) WaitReason() task.WaitReason {
+	if s.Status != string(task.StatusWaiting) {
+		return ""
+	}
+	switch {
+	case s.Tool == toolExitPlanMode || strings.Contains(s.Message, toolExitPlanMode):
+		return task.WaitPlan
+	case s.Tool == toolAskUserQuestion || strings.Contains(s.Message, toolAskUserQuestion) || s.NotificationType == notificationElicitation:
+		return task.WaitQuestion
+	case s.NotificationType == notificationPermission || strings.Contains(s.Message, "permission"):
+		return task.WaitPermission
+	}
+	return task.WaitInput
+}
@@ -0,0 +1,142 @@
+package status
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/task"
+)
+
+// Counts tallies tasks by status for shell prompts and scripts
+type Counts struct {
+	Pending int `json:"pending"`
+	Working int `json:"working"`
+	Waiting int `json:"waiting"`
+	Stalled int `json:"stalled"`
+	Paused  int `json:"paused"`
+	Done    int `json:"done"`
+}
+
+// ReadDir parses every status file in dir, keyed by task ID. A missing directory has no files.
+func ReadDir(dir string) (map[string]*Status, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return map[string]*Status{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status directory: %w", err)
+	}
+
+	files := make(map[string]*Status)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".status") {
+			continue
+		}
+		status, err := ParseStatusFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue // not written by flock's hooks
+		}
+		files[status.TaskID] = status
+	}
+	return files, nil
+}
+
+// ReadSessions parses the status files in each session's directory under root, keyed by
+// task ID. A task with files in more than one session gets its newest.
+func ReadSessions(root string) (map[string]*Status, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return map[string]*Status{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status directory: %w", err)
+	}
+
+	files := make(map[string]*Status)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		session, err := ReadDir(filepath.Join(root, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for id, status := range session {
+			if prev, ok := files[id]; !ok || status.Updated > prev.Updated {
+				files[id] = status
+			}
+		}
+	}
+	return files, nil
+}
+
+// Count tallies tasks by their latest status. Status files win over the store, since the
+// hooks write them before flock records the change, except for tasks the user paused.
+// tasks may be nil when the store can't be read; the status files are counted alone then.
+// WORKING tasks whose file is older than stallAfter count as stalled (0 disables this).
+func Count(tasks []*task.Task, files map[string]*Status, now time.Time, stallAfter time.Duration) Counts {
+	latest := make(map[string]task.Status)
+	if tasks == nil {
+		for id, file := range files {
+			latest[id] = fileStatus(file, now, stallAfter)
+		}
+	}
+	for _, t := range tasks {
+		latest[t.ID] = t.Status
+		if file, ok := files[t.ID]; ok && t.Status != task.StatusPaused {
+			latest[t.ID] = fileStatus(file, now, stallAfter)
+		}
+	}
+
+	var c Counts
+	for _, status := range latest {
+		switch status {
+		case task.StatusPending:
+			c.Pending++
+		case task.StatusWorking:
+			c.Working++
+		case task.StatusWaiting:
+			c.Waiting++
+		case task.StatusStalled:
+			c.Stalled++
+		case task.StatusPaused:
+			c.Paused++
+		case task.StatusDone:
+			c.Done++
+		}
+	}
+	return c
+}
+
+// fileStatus returns a status file's status, or STALLED for a WORKING file that went quiet
+func fileStatus(file *Status, now time.Time, stallAfter time.Duration) task.Status {
+	status := task.Status(file.Status)
+	if status == task.StatusWorking && stallAfter > 0 && file.Updated > 0 && now.Sub(time.Unix(file.Updated, 0)) >= stallAfter {
+		return task.StatusStalled
+	}
+	return status
+}
+
+// Segment renders the counts that need attention as a short prompt segment, e.g. "W2 ⏳1".
+// Empty when no agent is running. ascii swaps the symbols for "?" (waiting) and "!" (stalled).
+func (c Counts) Segment(ascii bool) string {
+	waiting, stalled := "⏳", "⚠"
+	if ascii {
+		waiting, stalled = "?", "!"
+	}
+
+	var parts []string
+	if c.Working > 0 {
+		parts = append(parts, fmt.Sprintf("W%d", c.Working))
+	}
+	if c.Waiting > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", waiting, c.Waiting))
+	}
+	if c.Stalled > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", stalled, c.Stalled))
+	}
+	return strings.Join(parts, " ")
+}
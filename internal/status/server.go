@@ -0,0 +1,72 @@
+package status
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/sockets"
+)
+
+// maxStatusBody caps the size of a POSTed status update
+const maxStatusBody = 64 << 10
+
+// listen starts the status server, which applies updates POSTed by hook scripts
+// exactly like status file writes. It shuts down when the watcher stops.
+// On TCP anyone who can reach the port could change statuses, so it needs a token.
+func (w *Watcher) listen(cfg config.StatusServerConfig) error {
+	network, address := cfg.Listener(w.dir)
+	if network == "tcp" && cfg.Token == "" {
+		return fmt.Errorf("status server on %s needs a token: set status_server.token in config.json", address)
+	}
+	listener, err := sockets.Listen(network, address)
+	if err != nil {
+		return fmt.Errorf("failed to start status server on %s: %w", address, err)
+	}
+
+	server := &http.Server{
+		Handler:           w.statusHandler(cfg.Token),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	w.spawn(func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("status server failed", "err", err)
+		}
+	})
+	w.spawn(func() {
+		<-w.ctx.Done()
+		server.Close()
+	})
+	return nil
+}
+
+// statusHandler accepts POST /status with a body in the status file format
+func (w *Watcher) statusHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		status, err := ParseStatus(io.LimitReader(r.Body, maxStatusBody))
+		if err != nil || status.Status == "" {
+			http.Error(rw, "invalid status update", http.StatusBadRequest)
+			return
+		}
+		if status.Updated == 0 {
+			status.Updated = time.Now().Unix()
+		}
+		w.apply(status)
+		rw.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
@@ -0,0 +1,330 @@
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// EventTranscript is the status event reported for statuses read from a session transcript
+const EventTranscript = "Transcript"
+
+const (
+	// transcriptPollInterval is how often session transcripts are checked for changes
+	transcriptPollInterval = 2 * time.Second
+	// transcriptSettle is how long a transcript ending in text must stay unchanged before
+	// the turn counts as finished; Claude Code writes a reply's blocks one line at a time
+	transcriptSettle = 3 * time.Second
+	// transcriptTail is how much of the end of a transcript is read to find its last entry
+	transcriptTail = 1 << 20
+	// Longest messages kept, matching the hook script
+	transcriptMessageLimit = 200
+	transcriptFinalLimit   = 4000
+)
+
+// transcriptState is what the watcher last read from a task's session transcript
+type transcriptState struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// transcriptEntry is the part of a Claude Code transcript line that status is inferred from
+type transcriptEntry struct {
+	Type    string `json:"type"`
+	IsMeta  bool   `json:"isMeta"`
+	Message struct {
+		Content    json.RawMessage `json:"content"`
+		StopReason string          `json:"stop_reason"`
+	} `json:"message"`
+}
+
+// contentBlock is one block of a transcript message's content
+type contentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	Name string `json:"name"` // Tool name, for tool_use blocks
+}
+
+// blocks returns the entry's content blocks; plain string content becomes one text block
+func (e transcriptEntry) blocks() []contentBlock {
+	var text string
+	if err := json.Unmarshal(e.Message.Content, &text); err == nil {
+		return []contentBlock{{Type: "text", Text: text}}
+	}
+	var blocks []contentBlock
+	json.Unmarshal(e.Message.Content, &blocks)
+	return blocks
+}
+
+// waitingTools are the tools with which Claude Code stops to ask the user something
+var waitingTools = map[string]bool{toolAskUserQuestion: true, toolExitPlanMode: true}
+
+// Inference is the status read from the end of a session transcript
+type Inference struct {
+	Status  task.Status
+	Message string
+	Tool    string // Tool a WAITING agent stopped with, e.g. AskUserQuestion
+	Settled bool   // False for a reply that may still be getting written
+}
+
+// InferStatus reads a Claude Code session transcript (JSON lines) and infers the agent's
+// status from its last entry: WORKING after a prompt or tool call, WAITING when it asks
+// the user something or was interrupted, DONE once it replied. A permission prompt can't
+// be told apart from a running tool, so it reads as WORKING. Reports false if the
+// transcript has no entry to go by.
+func InferStatus(r io.Reader) (Inference, bool) {
+	var last *transcriptEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), transcriptTail)
+	for scanner.Scan() {
+		var entry transcriptEntry
+		if err := json.Unmarshal(bytes.TrimSpace(scanner.Bytes()), &entry); err != nil {
+			continue // e.g. the partial first line of a tail
+		}
+		if (entry.Type == "user" || entry.Type == "assistant") && !entry.IsMeta {
+			last = &entry
+		}
+	}
+	if last == nil {
+		return Inference{}, false
+	}
+
+	blocks := last.blocks()
+	if last.Type == "user" {
+		for _, b := range blocks {
+			if strings.HasPrefix(b.Text, "[Request interrupted by user") {
+				return Inference{Status: task.StatusWaiting, Message: "Interrupted", Settled: true}, true
+			}
+		}
+		return Inference{Status: task.StatusWorking, Message: truncateMessage(firstText(blocks), transcriptMessageLimit), Settled: true}, true
+	}
+
+	for _, b := range blocks {
+		if b.Type == "tool_use" {
+			if waitingTools[b.Name] {
+				return Inference{Status: task.StatusWaiting, Message: "Claude needs your input", Tool: b.Name, Settled: true}, true
+			}
+			return Inference{Status: task.StatusWorking, Settled: true}, true
+		}
+	}
+	text := firstText(blocks)
+	if text == "" {
+		return Inference{Status: task.StatusWorking, Settled: true}, true // e.g. thinking
+	}
+	return Inference{
+		Status:  task.StatusDone,
+		Message: truncateMessage(text, transcriptFinalLimit),
+		Settled: last.Message.StopReason == "end_turn",
+	}, true
+}
+
+// firstText returns the text of the first text block
+func firstText(blocks []contentBlock) string {
+	for _, b := range blocks {
+		if b.Type == "text" && strings.TrimSpace(b.Text) != "" {
+			return b.Text
+		}
+	}
+	return ""
+}
+
+// truncateMessage flattens text to one line of at most limit characters, like the hook script
+func truncateMessage(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > limit {
+		text = string(runes[:limit])
+	}
+	return text
+}
+
+// ClaudeProjectsDir returns where Claude Code keeps session transcripts, one directory per
+// working directory
+func ClaudeProjectsDir() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "projects")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".claude", "projects")
+}
+
+// ClaudeProjectDir returns the transcript directory for sessions started in dir. Claude
+// Code names it after the path with every character but letters and digits replaced by "-".
+func ClaudeProjectDir(projectsDir, dir string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, dir)
+	return filepath.Join(projectsDir, name)
+}
+
+// SetTaskList lets the watcher find the tasks whose status it reads from session
+// transcripts, e.g. with a Manager's List
+func (w *Watcher) SetTaskList(list func() []*task.Task) {
+	w.tasks = list
+}
+
+// SetProjectsDir changes where session transcripts are looked for
+func (w *Watcher) SetProjectsDir(dir string) {
+	w.projectsDir = dir
+}
+
+// watchTranscripts polls the session transcripts of agents using transcript status
+func (w *Watcher) watchTranscripts() {
+	ticker := time.NewTicker(transcriptPollInterval)
+	defer ticker.Stop()
+	for {
+		w.scanTranscripts(time.Now())
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// transcriptTask is a task whose status comes from a session transcript
+type transcriptTask struct {
+	id, name string
+	dir      string
+	started  time.Time
+}
+
+// transcriptTasks returns the launched tasks whose agent uses transcript status, most
+// recently started first
+func (w *Watcher) transcriptTasks() []transcriptTask {
+	var tasks []transcriptTask
+	for _, t := range w.tasks() {
+		if t.Status == task.StatusPending || len(t.History) < 2 {
+			continue
+		}
+		agent, err := w.config.Agent(t.Agent)
+		if err != nil || agent.StatusHook != config.StatusHookTranscript {
+			continue
+		}
+		// The first change after PENDING is the launch
+		tasks = append(tasks, transcriptTask{id: t.ID, name: t.Name, dir: t.WorkDir(), started: t.History[1].At})
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].started.After(tasks[j].started) })
+	return tasks
+}
+
+// scanTranscripts applies the status of every transcript that changed since the last scan
+func (w *Watcher) scanTranscripts(now time.Time) {
+	claimed := make(map[string]bool)
+	for _, t := range w.transcriptTasks() {
+		path, info := w.sessionFor(t, claimed)
+		if path == "" {
+			continue
+		}
+		claimed[path] = true
+
+		last := w.transcripts[t.id]
+		if last.path == path && last.modTime.Equal(info.ModTime()) && last.size == info.Size() {
+			continue
+		}
+		inference, ok := readTranscript(path, info.Size())
+		if !ok || !inference.Settled && now.Sub(info.ModTime()) < transcriptSettle {
+			continue // Look again once the reply is complete
+		}
+		w.transcripts[t.id] = transcriptState{path: path, modTime: info.ModTime(), size: info.Size()}
+		w.apply(&Status{
+			Version:   Version,
+			Status:    string(inference.Status),
+			TaskID:    t.id,
+			TaskName:  t.name,
+			Updated:   info.ModTime().Unix(),
+			SessionID: strings.TrimSuffix(filepath.Base(path), ".jsonl"),
+			Event:     EventTranscript,
+			Tool:      inference.Tool,
+			Message:   inference.Message,
+		})
+	}
+}
+
+// sessionFor returns a task's session transcript: the newest one in its directory that
+// began after the task was launched and isn't claimed by a task launched later
+func (w *Watcher) sessionFor(t transcriptTask, claimed map[string]bool) (string, os.FileInfo) {
+	dir := ClaudeProjectDir(w.projectsDir, t.dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil
+	}
+	var newest string
+	var newestInfo os.FileInfo
+	var newestStart time.Time
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if e.IsDir() || filepath.Ext(path) != ".jsonl" || claimed[path] {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().Before(t.started) {
+			continue
+		}
+		start := w.sessionStart(path, info)
+		// Allow for the launch time and the first entry being rounded differently
+		if start.Before(t.started.Add(-time.Second)) {
+			continue
+		}
+		if newest == "" || start.After(newestStart) {
+			newest, newestInfo, newestStart = path, info, start
+		}
+	}
+	return newest, newestInfo
+}
+
+// sessionStart returns when a transcript's session began: the timestamp of its first
+// entry, or the file's modification time without one
+func (w *Watcher) sessionStart(path string, info os.FileInfo) time.Time {
+	if start, ok := w.sessionStarts[path]; ok {
+		return start
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return info.ModTime()
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), transcriptTail)
+	for scanner.Scan() {
+		var entry struct {
+			Timestamp time.Time `json:"timestamp"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil && !entry.Timestamp.IsZero() {
+			w.sessionStarts[path] = entry.Timestamp
+			return entry.Timestamp
+		}
+	}
+	return info.ModTime() // Not cached: the first entry may not be written yet
+}
+
+// readTranscript infers the status from the end of a transcript of the given size
+func readTranscript(path string, size int64) (Inference, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Inference{}, false
+	}
+	defer file.Close()
+	if size > transcriptTail {
+		if _, err := file.Seek(size-transcriptTail, io.SeekStart); err != nil {
+			return Inference{}, false
+		}
+	}
+	return InferStatus(io.LimitReader(file, transcriptTail))
+}
@@ -0,0 +1,104 @@
+package status
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+func TestInferStatus(t *testing.T) {
+	prompt := `{"type":"user","message":{"role":"user","content":"Fix the tests"},"timestamp":"2026-01-02T10:00:00Z"}`
+	tests := []struct {
+		name     string
+		lines    []string
+		status   task.Status
+		message  string
+		settled  bool
+		inferred bool
+	}{
+		{"empty", nil, "", "", false, false},
+		{"prompt", []string{prompt}, task.StatusWorking, "Fix the tests", true, true},
+		{"tool call", []string{prompt, `{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Bash"}],"stop_reason":"tool_use"}}`}, task.StatusWorking, "", true, true},
+		{"tool result", []string{prompt, `{"type":"user","message":{"content":[{"type":"tool_result","content":"ok"}]}}`}, task.StatusWorking, "", true, true},
+		{"question", []string{prompt, `{"type":"assistant","message":{"content":[{"type":"tool_use","name":"AskUserQuestion"}]}}`}, task.StatusWaiting, "Claude needs your input", true, true},
+		{"interrupted", []string{prompt, `{"type":"user","message":{"content":[{"type":"text","text":"[Request interrupted by user]"}]}}`}, task.StatusWaiting, "Interrupted", true, true},
+		{"reply", []string{prompt, `{"type":"assistant","message":{"content":[{"type":"text","text":"All tests\npass."}],"stop_reason":"end_turn"}}`}, task.StatusDone, "All tests pass.", true, true},
+		{"reply being written", []string{prompt, `{"type":"assistant","message":{"content":[{"type":"text","text":"Let me look."}],"stop_reason":null}}`}, task.StatusDone, "Let me look.", false, true},
+		{"meta and summary skipped", []string{prompt, `{"type":"user","isMeta":true,"message":{"content":"caveat"}}`, `{"type":"summary","summary":"Tests"}`, `not json`}, task.StatusWorking, "Fix the tests", true, true},
+	}
+
+	for _, tt := range tests {
+		inference, ok := InferStatus(strings.NewReader(strings.Join(tt.lines, "\n")))
+		if ok != tt.inferred {
+			t.Errorf("%s: expected inferred %v, got %v", tt.name, tt.inferred, ok)
+			continue
+		}
+		if inference.Status != tt.status || inference.Message != tt.message || inference.Settled != tt.settled {
+			t.Errorf("%s: expected %s %q settled=%v, got %s %q settled=%v", tt.name, tt.status, tt.message, tt.settled, inference.Status, inference.Message, inference.Settled)
+		}
+	}
+}
+
+func TestClaudeProjectDir(t *testing.T) {
+	if got := ClaudeProjectDir("/p", "/home/me/src/my_app.git"); got != "/p/-home-me-src-my-app-git" {
+		t.Errorf("expected /p/-home-me-src-my-app-git, got %s", got)
+	}
+}
+
+func TestScanTranscripts(t *testing.T) {
+	cfg := &config.Config{Agents: map[string]config.AgentConfig{
+		"claude": {Command: "claude {{prompt}}", StatusHook: config.StatusHookTranscript},
+	}}
+	updates := make(chan Update, 10)
+	w := NewWatcher(t.TempDir(), updates, cfg)
+	w.SetRunner(runner.NewFake())
+	projects := t.TempDir()
+	w.SetProjectsDir(projects)
+
+	// Two tasks in the same directory, e.g. a task and its reviewer
+	launched := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	newTask := func(id string, started time.Time) *task.Task {
+		return &task.Task{ID: id, Name: "task " + id, Cwd: "/work/app", Status: task.StatusWorking,
+			History: []task.StatusChange{{Status: task.StatusPending}, {Status: task.StatusWorking, At: started}}}
+	}
+	tasks := []*task.Task{newTask("001", launched), newTask("002", launched.Add(time.Hour))}
+	w.SetTaskList(func() []*task.Task { return tasks })
+
+	dir := ClaudeProjectDir(projects, "/work/app")
+	os.MkdirAll(dir, 0755)
+	write := func(name, timestamp, reply string) {
+		lines := `{"type":"user","message":{"content":"go"},"timestamp":"` + timestamp + `"}` + "\n"
+		if reply != "" {
+			lines += `{"type":"assistant","message":{"content":[{"type":"text","text":"` + reply + `"}],"stop_reason":"end_turn"}}` + "\n"
+		}
+		os.WriteFile(filepath.Join(dir, name), []byte(lines), 0644)
+	}
+	write("older.jsonl", "2026-01-02T09:00:00Z", "") // Before either task
+	write("first.jsonl", "2026-01-02T10:00:01Z", "Done first.")
+	write("second.jsonl", "2026-01-02T11:00:01Z", "")
+
+	w.scanTranscripts(time.Now())
+	got := make(map[string]Update)
+	for len(updates) > 0 {
+		u := <-updates
+		got[u.TaskID] = u
+	}
+	if u := got["001"]; u.Status != task.StatusDone || u.Message != "Done first." {
+		t.Errorf("expected 001 DONE from its own session, got %s %q", u.Status, u.Message)
+	}
+	if u := got["002"]; u.Status != task.StatusWorking {
+		t.Errorf("expected 002 WORKING from the newer session, got %s", u.Status)
+	}
+
+	// Unchanged transcripts aren't applied again
+	w.scanTranscripts(time.Now())
+	if len(updates) != 0 {
+		t.Errorf("expected no updates for unchanged transcripts, got %d", len(updates))
+	}
+}
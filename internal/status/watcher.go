@@ -1,44 +1,107 @@
 package status
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/notify"
+	"github.com/dfowler/flock/internal/runner"
 	"github.com/dfowler/flock/internal/task"
-	"github.com/dfowler/flock/internal/tui"
 	"github.com/fsnotify/fsnotify"
 )
 
+// stallCheckInterval is how often the watcher looks for WORKING tasks that stopped reporting
+const stallCheckInterval = 30 * time.Second
+
+// Update represents a status change from the watcher
+type Update struct {
+	TaskID  string
+	Status  task.Status
+	Reason  task.WaitReason // Why a WAITING agent stopped
+	Message string          // What the agent asked, was told or finished with, when the hook reported it
+	Event   string          // Hook event behind the update, e.g. Setup for a failed setup command
+}
+
 // Watcher watches the status directory for changes
 type Watcher struct {
 	dir          string
-	updates      chan tui.StatusUpdate
-	done         chan struct{}
-	lastStatus   map[string]string // tracks last known status per task
-	initializing bool              // true during initial file load (skip notifications)
+	updates      chan Update
+	ctx          context.Context // Canceled when the watcher stops
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup // Goroutines Stop waits for
+	mu           sync.Mutex
+	lastStatus   map[string]string  // tracks last known status per task
+	files        map[string]*Status // last parsed status file per task
+	initializing bool               // true during initial file load (skip notifications)
 	config       *config.Config
+	notifier     notify.Notifier
+	lookup       func(taskID string) (*task.Task, bool) // finds a task's branch and age for webhooks
+	eventsMu     sync.Mutex                             // serializes updates from the events socket
+
+	// Session transcripts, for agents using transcript status (only used by the poller)
+	tasks         func() []*task.Task
+	projectsDir   string
+	transcripts   map[string]transcriptState // last transcript read per task
+	sessionStarts map[string]time.Time       // when each transcript's session began
 }
 
 // NewWatcher creates a new status watcher
-func NewWatcher(dir string, updates chan tui.StatusUpdate, cfg *config.Config) *Watcher {
+func NewWatcher(dir string, updates chan Update, cfg *config.Config) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Watcher{
 		dir:        dir,
 		updates:    updates,
-		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
 		lastStatus: make(map[string]string),
+		files:      make(map[string]*Status),
 		config:     cfg,
+		notifier:   newNotifier(cfg, runner.Exec{}),
+
+		projectsDir:   ClaudeProjectsDir(),
+		transcripts:   make(map[string]transcriptState),
+		sessionStarts: make(map[string]time.Time),
 	}
 }
 
-// Start starts watching the status directory
-func (w *Watcher) Start() error {
-	// Ensure directory exists
-	if err := os.MkdirAll(w.dir, 0755); err != nil {
+// newNotifier returns the configured desktop notifier, falling back to detection
+// when the configured backend is unknown
+func newNotifier(cfg *config.Config, r runner.Runner) notify.Notifier {
+	backend := ""
+	if cfg != nil {
+		backend = cfg.Notifications.Backend
+	}
+	notifier, err := notify.New(backend, r)
+	if err != nil {
+		slog.Warn("falling back to detected notifier", "err", err)
+		notifier, _ = notify.New(notify.BackendAuto, r)
+	}
+	return notifier
+}
+
+// SetTaskLookup lets webhooks include a task's branch and duration, e.g. with a Manager's Get
+func (w *Watcher) SetTaskLookup(lookup func(taskID string) (*task.Task, bool)) {
+	w.lookup = lookup
+}
+
+// SetRunner replaces the runner used for desktop notifications
+func (w *Watcher) SetRunner(r runner.Runner) {
+	w.notifier = newNotifier(w.config, r)
+}
+
+// Start starts watching the status directory until ctx is canceled or Stop is called
+func (w *Watcher) Start(ctx context.Context) error {
+	context.AfterFunc(ctx, w.cancel)
+
+	if err := multiplexer.EnsureStatusDir(w.dir); err != nil {
 		return err
 	}
 
@@ -47,11 +110,11 @@ func (w *Watcher) Start() error {
 		return err
 	}
 
-	go func() {
+	w.spawn(func() {
 		defer watcher.Close()
 		for {
 			select {
-			case <-w.done:
+			case <-w.ctx.Done():
 				return
 			case event, ok := <-watcher.Events:
 				if !ok {
@@ -59,15 +122,17 @@ func (w *Watcher) Start() error {
 				}
 				if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
 					w.handleFile(event.Name)
+				} else if event.Op&fsnotify.Remove != 0 {
+					w.forget(event.Name)
 				}
 			case err, ok := <-watcher.Errors:
 				if !ok {
 					return
 				}
-				log.Printf("watcher error: %v", err)
+				slog.Error("status watcher error", "err", err)
 			}
 		}
-	}()
+	})
 
 	if err := watcher.Add(w.dir); err != nil {
 		return err
@@ -83,14 +148,58 @@ func (w *Watcher) Start() error {
 			}
 		}
 	}
+	readTranscripts := w.tasks != nil && w.config != nil && w.config.UsesStatusHook(config.StatusHookTranscript)
+	if readTranscripts {
+		w.scanTranscripts(time.Now())
+	}
 	w.initializing = false
 
+	if w.config != nil && w.config.StatusServer.Enabled {
+		if err := w.listen(w.config.StatusServer); err != nil {
+			return err
+		}
+	}
+	if w.config != nil && w.config.StatusTransport == config.StatusTransportSocket {
+		if err := w.listenEvents(config.EventsSocketPath(w.dir)); err != nil {
+			return err
+		}
+	}
+
+	if readTranscripts {
+		w.spawn(w.watchTranscripts)
+	}
+
+	if w.config != nil && w.config.StallThreshold() > 0 {
+		threshold := w.config.StallThreshold()
+		w.spawn(func() { w.monitor(threshold) })
+	}
+
 	return nil
 }
 
-// Stop stops the watcher
+// Stop stops the watcher and waits for its goroutines, including webhooks being sent,
+// so no status update is delivered after it returns
 func (w *Watcher) Stop() {
-	close(w.done)
+	w.cancel()
+	w.wg.Wait()
+}
+
+// spawn runs fn in a goroutine that Stop waits for
+func (w *Watcher) spawn(fn func()) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		fn()
+	}()
+}
+
+// send forwards an update to the TUI, giving up once the watcher stops so a reader
+// that has gone away can't block shutdown
+func (w *Watcher) send(update Update) {
+	select {
+	case w.updates <- update:
+	case <-w.ctx.Done():
+	}
 }
 
 // handleFile processes a status file change
@@ -101,34 +210,175 @@ func (w *Watcher) handleFile(path string) {
 
 	status, err := ParseStatusFile(path)
 	if err != nil {
-		// Silently skip invalid status files (e.g., from non-flock Claude Code sessions)
+		// Skip invalid status files (e.g., from non-flock Claude Code sessions)
 		// These are expected when Claude Code runs outside of flock context
+		slog.Debug("skipping status file", "path", path, "err", err)
 		return
 	}
+	w.apply(status)
+}
 
+// apply records a task's reported status, notifies on changes, and forwards it to the TUI
+func (w *Watcher) apply(status *Status) {
 	// Check if status changed and send notification (skip during initial load)
+	reason := status.WaitReason()
+	w.mu.Lock()
+	last, hadFile := w.files[status.TaskID]
+	if hadFile && status.Updated > 0 && status.Updated < last.Updated {
+		// An update that arrived late, e.g. a fallback file written after a newer event
+		w.mu.Unlock()
+		slog.Debug("ignoring late status update", "task", status.TaskID, "status", status.Status)
+		return
+	}
+	w.files[status.TaskID] = status
 	lastStatus, exists := w.lastStatus[status.TaskID]
-	if !exists || lastStatus != status.Status {
+	// A WAITING agent that starts waiting for something else is announced again
+	changed := !exists || lastStatus != status.Status || hadFile && reason != last.WaitReason()
+	if changed {
 		w.lastStatus[status.TaskID] = status.Status
-		// Only send notifications for real-time changes, not initial file load
-		if !w.initializing {
-			w.sendNotification(status.TaskID, status.TaskName, status.Status)
+	}
+	w.mu.Unlock()
+	slog.Debug("status update", "task", status.TaskID, "status", status.Status, "event", status.Event, "changed", changed)
+
+	// Only send notifications for real-time changes, not initial file load
+	if changed && !w.initializing {
+		w.announce(status.TaskID, status.TaskName, status.Status, reason)
+	}
+
+	w.send(Update{
+		TaskID:  status.TaskID,
+		Status:  task.Status(status.Status),
+		Reason:  reason,
+		Message: status.Message,
+		Event:   status.Event,
+	})
+}
+
+// forget drops a deleted status file's task from stall tracking
+func (w *Watcher) forget(path string) {
+	if !strings.HasSuffix(path, ".status") {
+		return
+	}
+	taskID := strings.TrimSuffix(filepath.Base(path), ".status")
+	w.mu.Lock()
+	delete(w.files, taskID)
+	delete(w.lastStatus, taskID)
+	w.mu.Unlock()
+}
+
+// monitor periodically marks WORKING tasks whose status file is older than threshold as STALLED.
+// Tasks already stale at startup (e.g. the agent crashed while flock was closed) are marked
+// right away without a notification.
+func (w *Watcher) monitor(threshold time.Duration) {
+	w.markStalled(time.Now(), threshold, false)
+
+	ticker := time.NewTicker(stallCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.markStalled(now, threshold, true)
 		}
 	}
+}
 
-	w.updates <- tui.StatusUpdate{
-		TaskID: status.TaskID,
-		Status: task.Status(status.Status),
+// markStalled sends a STALLED update for every task that stopped reporting
+func (w *Watcher) markStalled(now time.Time, threshold time.Duration, notify bool) {
+	for _, status := range w.stalledTasks(now, threshold) {
+		if notify {
+			w.announce(status.TaskID, status.TaskName, string(task.StatusStalled), "")
+		}
+		w.send(Update{
+			TaskID: status.TaskID,
+			Status: task.StatusStalled,
+		})
 	}
 }
 
+// stalledTasks returns the WORKING tasks whose status file was last updated more than
+// threshold before now, recording them as STALLED so each is reported once.
+// The next hook write moves a stalled task back to its real status.
+func (w *Watcher) stalledTasks(now time.Time, threshold time.Duration) []*Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var stalled []*Status
+	for taskID, status := range w.files {
+		if w.lastStatus[taskID] != string(task.StatusWorking) || status.Updated == 0 {
+			continue
+		}
+		if now.Sub(time.Unix(status.Updated, 0)) >= threshold {
+			w.lastStatus[taskID] = string(task.StatusStalled)
+			stalled = append(stalled, status)
+		}
+	}
+	return stalled
+}
+
+// announce reports a status change with a desktop notification and to webhooks
+func (w *Watcher) announce(taskID, taskName, status string, reason task.WaitReason) {
+	w.sendNotification(taskID, taskName, status, reason)
+	w.sendWebhooks(taskID, taskName, status, reason)
+}
+
 // sendNotification sends a desktop notification for status changes
-func (w *Watcher) sendNotification(taskID, taskName, status string) {
-	// Check if notifications are enabled
-	if w.config != nil && !w.config.NotificationsEnabled {
+func (w *Watcher) sendNotification(taskID, taskName, status string, reason task.WaitReason) {
+	// Check if notifications are enabled, overall and for this status
+	if w.config != nil && (!w.config.NotificationsEnabled || !w.config.Notifications.Notifies(status)) {
+		return
+	}
+
+	n, ok := notificationFor(taskID, taskName, status, reason)
+	if !ok {
+		return
+	}
+	if w.config != nil && reason != "" {
+		if urgency := w.config.Notifications.WaitingUrgency(string(reason)); urgency != "" {
+			n.Urgency = urgency
+		}
+	}
+	// Try to find the icon in common installation locations
+	n.Icon = findIcon()
+	if err := w.notifier.Notify(n); err != nil {
+		slog.Warn("failed to send notification", "task", taskID, "err", err)
+	}
+}
+
+// sendWebhooks POSTs a status change to the configured webhooks that fire on it, in the
+// background so a slow endpoint can't hold up status updates
+func (w *Watcher) sendWebhooks(taskID, taskName, status string, reason task.WaitReason) {
+	if w.config == nil || len(w.config.Notifications.Webhooks) == 0 {
+		return
+	}
+	n, ok := notificationFor(taskID, taskName, status, reason)
+	if !ok {
 		return
 	}
 
+	event := notify.Event{TaskID: taskID, TaskName: taskName, Status: status, Message: n.Body}
+	if w.lookup != nil {
+		if t, ok := w.lookup(taskID); ok {
+			event.Branch = t.GitBranch
+			event.Duration = t.Age()
+		}
+	}
+	for _, hook := range w.config.Notifications.Webhooks {
+		if !hook.Fires(status) {
+			continue
+		}
+		w.spawn(func() {
+			if err := notify.PostWebhook(hook.URL, hook.Format, event); err != nil {
+				slog.Warn("failed to send webhook", "url", hook.URL, "err", err)
+			}
+		})
+	}
+}
+
+// notificationFor describes a status change, or reports false for statuses that aren't announced.
+// A WAITING agent's reason picks the wording.
+func notificationFor(taskID, taskName, status string, reason task.WaitReason) (notify.Notification, bool) {
 	var title, body, urgency string
 
 	// Use task name if available, otherwise fall back to task ID
@@ -140,32 +390,33 @@ func (w *Watcher) sendNotification(taskID, taskName, status string) {
 	switch status {
 	case "WAITING":
 		title = "Flock: Agent Needs Attention"
-		body = fmt.Sprintf("%s is waiting for input", displayName)
-		urgency = "critical"
+		switch reason {
+		case task.WaitPermission:
+			body = fmt.Sprintf("%s is asking for permission", displayName)
+		case task.WaitQuestion:
+			body = fmt.Sprintf("%s has a question for you", displayName)
+		case task.WaitPlan:
+			body = fmt.Sprintf("%s wants its plan approved", displayName)
+		default:
+			body = fmt.Sprintf("%s is waiting for input", displayName)
+		}
+		urgency = notify.UrgencyCritical
 	case "WORKING":
 		title = "Flock: Agent Working"
 		body = fmt.Sprintf("%s is now working", displayName)
-		urgency = "low"
+		urgency = notify.UrgencyLow
 	case "DONE":
 		title = "Flock: Agent Complete"
 		body = fmt.Sprintf("%s has finished", displayName)
-		urgency = "normal"
+		urgency = notify.UrgencyNormal
+	case "STALLED":
+		title = "Flock: Agent Stalled"
+		body = fmt.Sprintf("%s has not reported progress in a while", displayName)
+		urgency = notify.UrgencyCritical
 	default:
-		return
-	}
-
-	// Use notify-send for desktop notifications
-	// Try to find the icon in common installation locations
-	iconPath := findIcon()
-	var cmd *exec.Cmd
-	if iconPath != "" {
-		cmd = exec.Command("notify-send", "-u", urgency, "-i", iconPath, title, body)
-	} else {
-		cmd = exec.Command("notify-send", "-u", urgency, title, body)
-	}
-	if err := cmd.Run(); err != nil {
-		log.Printf("failed to send notification: %v", err)
+		return notify.Notification{}, false
 	}
+	return notify.Notification{Title: title, Body: body, Urgency: urgency}, true
 }
 
 // findIcon looks for the flock icon in common locations
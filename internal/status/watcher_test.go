@@ -0,0 +1,190 @@
+package status
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+func TestStalledTasks(t *testing.T) {
+	now := time.Unix(10000, 0)
+	w := NewWatcher(t.TempDir(), nil, nil)
+	w.files = map[string]*Status{
+		"001": {TaskID: "001", Status: "WORKING", Updated: now.Add(-time.Hour).Unix()},
+		"002": {TaskID: "002", Status: "WORKING", Updated: now.Add(-time.Minute).Unix()},
+		"003": {TaskID: "003", Status: "WAITING", Updated: now.Add(-time.Hour).Unix()},
+	}
+	for id, s := range w.files {
+		w.lastStatus[id] = s.Status
+	}
+
+	stalled := w.stalledTasks(now, 30*time.Minute)
+	if len(stalled) != 1 || stalled[0].TaskID != "001" {
+		t.Fatalf("expected only 001 to be stalled, got %v", stalled)
+	}
+	if w.lastStatus["001"] != "STALLED" {
+		t.Errorf("expected 001 recorded as STALLED, got %s", w.lastStatus["001"])
+	}
+	if again := w.stalledTasks(now, 30*time.Minute); len(again) != 0 {
+		t.Errorf("expected a stalled task to be reported once, got %d", len(again))
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	updates := make(chan Update, 1)
+	w := NewWatcher(t.TempDir(), updates, nil)
+	w.SetRunner(runner.NewFake())
+	handler := w.statusHandler("secret")
+
+	tests := []struct {
+		method string
+		token  string
+		body   string
+		code   int
+	}{
+		{http.MethodPost, "secret", "status=WAITING\ntask_id=001\n", http.StatusNoContent},
+		{http.MethodPost, "wrong", "status=WAITING\ntask_id=001\n", http.StatusUnauthorized},
+		{http.MethodPost, "secret", "task_id=001\n", http.StatusBadRequest},
+		{http.MethodGet, "secret", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/status", strings.NewReader(tt.body))
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.code {
+			t.Errorf("%s with token %q and body %q: expected %d, got %d", tt.method, tt.token, tt.body, tt.code, rec.Code)
+		}
+	}
+
+	select {
+	case update := <-updates:
+		if update.TaskID != "001" || update.Status != task.StatusWaiting {
+			t.Errorf("expected 001 WAITING, got %s %s", update.TaskID, update.Status)
+		}
+	default:
+		t.Errorf("expected an update for the accepted POST")
+	}
+	if len(updates) != 0 {
+		t.Errorf("expected rejected POSTs to send no updates")
+	}
+}
+
+func TestCount(t *testing.T) {
+	now := time.Unix(10000, 0)
+	tasks := []*task.Task{
+		{ID: "001", Status: task.StatusWorking},
+		{ID: "002", Status: task.StatusWorking},
+		{ID: "003", Status: task.StatusWorking},
+		{ID: "004", Status: task.StatusPaused},
+		{ID: "005", Status: task.StatusPending},
+	}
+	files := map[string]*Status{
+		"001": {TaskID: "001", Status: "WAITING", Updated: now.Unix()},
+		"002": {TaskID: "002", Status: "WORKING", Updated: now.Add(-time.Hour).Unix()},
+		"004": {TaskID: "004", Status: "WORKING", Updated: now.Unix()},
+	}
+
+	counts := Count(tasks, files, now, 30*time.Minute)
+	expected := Counts{Pending: 1, Working: 1, Waiting: 1, Stalled: 1, Paused: 1}
+	if counts != expected {
+		t.Errorf("expected %+v, got %+v", expected, counts)
+	}
+	if segment := counts.Segment(false); segment != "W1 ⏳1 ⚠1" {
+		t.Errorf("expected segment %q, got %q", "W1 ⏳1 ⚠1", segment)
+	}
+	if segment := counts.Segment(true); segment != "W1 ?1 !1" {
+		t.Errorf("expected ascii segment %q, got %q", "W1 ?1 !1", segment)
+	}
+
+	// Without the store, only the status files are counted
+	if counts := Count(nil, files, now, 0); counts.Working != 2 || counts.Waiting != 1 {
+		t.Errorf("expected 2 working and 1 waiting from status files, got %+v", counts)
+	}
+	if segment := (Counts{Pending: 3, Done: 2}).Segment(false); segment != "" {
+		t.Errorf("expected an empty segment with no agents running, got %q", segment)
+	}
+}
+
+func TestEventsSocket(t *testing.T) {
+	// Unix socket paths are limited to ~100 bytes, which t.TempDir can exceed
+	dir, err := os.MkdirTemp("", "flock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	updates := make(chan Update, 4)
+	w := NewWatcher(dir, updates, nil)
+	w.SetRunner(runner.NewFake())
+	socket := filepath.Join(dir, "events.sock")
+	if err := w.listenEvents(socket); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	events := []struct {
+		body    string
+		wantErr bool
+	}{
+		{`{"version":2,"status":"WORKING","task_id":"001","updated":100}`, false},
+		{`{"version":2,"status":"WAITING","task_id":"001","updated":200,"message":"Allow Bash?"}`, false},
+		{`{"version":2,"status":"WORKING","task_id":"001","updated":150}`, false}, // late, dropped
+		{`{"version":2,"task_id":"001"}`, true},
+		{`{"version":9,"status":"DONE","task_id":"001"}`, true},
+	}
+	for _, e := range events {
+		if err := SendEvent(socket, []byte(e.body)); (err != nil) != e.wantErr {
+			t.Errorf("%s: expected error %v, got %v", e.body, e.wantErr, err)
+		}
+	}
+
+	want := []task.Status{task.StatusWorking, task.StatusWaiting}
+	if len(updates) != len(want) {
+		t.Fatalf("expected %d updates, got %d", len(want), len(updates))
+	}
+	for _, status := range want {
+		if update := <-updates; update.Status != status {
+			t.Errorf("expected %s, got %s", status, update.Status)
+		}
+	}
+}
+
+func TestStatusServer(t *testing.T) {
+	w := NewWatcher(t.TempDir(), make(chan Update, 4), nil)
+	w.SetRunner(runner.NewFake())
+	defer w.Stop()
+
+	if err := w.listen(config.StatusServerConfig{Enabled: true, Address: "127.0.0.1:0"}); err == nil {
+		t.Errorf("expected a TCP status server without a token to be refused")
+	}
+
+	handler := w.statusHandler("secret")
+	body := `{"version":2,"status":"WAITING","task_id":"001"}`
+	for _, tt := range []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusNoContent},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/status", strings.NewReader(body))
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("Authorization %q: expected %d, got %d", tt.auth, tt.want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dfowler/flock/internal/vault"
+)
+
+// ArchivedTask is a finished task moved out of the task list, kept for the history view
+type ArchivedTask struct {
+	Task       Task          `json:"task"`
+	Prompt     string        `json:"prompt,omitempty"`    // Prompt file content when the task was archived
+	Branch     string        `json:"branch,omitempty"`    // Final branch the task worked on
+	DiffStat   string        `json:"diff_stat,omitempty"` // e.g. "3 files changed, 40 insertions(+), 2 deletions(-)"
+	Duration   time.Duration `json:"duration"`            // From creation until the task reached DONE
+	ArchivedAt time.Time     `json:"archived_at"`
+}
+
+// NewArchivedTask records a task with its prompt content as of now
+func NewArchivedTask(t *Task, prompt string, now time.Time) *ArchivedTask {
+	finished := now
+	if t.CompletedAt != nil {
+		finished = *t.CompletedAt
+	}
+	return &ArchivedTask{
+		Task:       *t,
+		Prompt:     prompt,
+		Branch:     t.GitBranch,
+		Duration:   finished.Sub(t.CreatedAt),
+		ArchivedAt: now,
+	}
+}
+
+// Matches reports whether the archived task's ID, name, branch, directory or prompt
+// contains query, ignoring case
+func (a *ArchivedTask) Matches(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+	for _, field := range []string{a.Task.ID, a.Task.Name, a.Branch, a.Task.Cwd, a.Prompt} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// Archive stores archived tasks in a JSON file (~/.flock/archive.json)
+type Archive struct {
+	path    string
+	vault   *vault.Vault // Encrypts the file at rest (nil for plaintext)
+	entries []*ArchivedTask
+}
+
+// LoadArchive reads the archive through v (nil for plaintext); a missing file yields an empty archive
+func LoadArchive(path string, v *vault.Vault) (*Archive, error) {
+	a := &Archive{path: path, vault: v}
+	data, err := v.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return a, nil
+		}
+		return a, err
+	}
+	if err := json.Unmarshal(data, &a.entries); err != nil {
+		return a, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return a, nil
+}
+
+// Add appends a task to the archive and saves it
+func (a *Archive) Add(entry *ArchivedTask) error {
+	a.entries = append(a.entries, entry)
+	return a.save()
+}
+
+// ArchivedBefore returns the tasks archived before cutoff
+func (a *Archive) ArchivedBefore(cutoff time.Time) []*ArchivedTask {
+	var old []*ArchivedTask
+	for _, entry := range a.entries {
+		if entry.ArchivedAt.Before(cutoff) {
+			old = append(old, entry)
+		}
+	}
+	return old
+}
+
+// Prune removes the tasks archived before cutoff and saves the archive, returning how many were removed
+func (a *Archive) Prune(cutoff time.Time) (int, error) {
+	kept := a.entries[:0]
+	for _, entry := range a.entries {
+		if !entry.ArchivedAt.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	removed := len(a.entries) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	a.entries = kept
+	return removed, a.save()
+}
+
+// save writes the archive file
+func (a *Archive) save() error {
+	data, err := json.MarshalIndent(a.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.vault.WriteFile(a.path, data, 0644)
+}
+
+// Search returns the archived tasks matching query, most recently archived first
+func (a *Archive) Search(query string) []*ArchivedTask {
+	var matches []*ArchivedTask
+	for _, entry := range a.entries {
+		if entry.Matches(query) {
+			matches = append(matches, entry)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].ArchivedAt.After(matches[j].ArchivedAt)
+	})
+	return matches
+}
+
+// Count returns the number of archived tasks
+func (a *Archive) Count() int {
+	return len(a.entries)
+}
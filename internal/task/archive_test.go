@@ -0,0 +1,66 @@
+package task
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.json")
+	archive, err := LoadArchive(path, nil)
+	if err != nil {
+		t.Fatalf("expected missing file to load empty, got %v", err)
+	}
+
+	now := time.Unix(100000, 0)
+	completed := now.Add(-time.Hour)
+	first := NewTask("001", "fix login", "", "/src/app")
+	first.CreatedAt = now.Add(-3 * time.Hour)
+	first.CompletedAt = &completed
+	first.GitBranch = "flock-001"
+	second := NewTask("002", "update docs", "", "/src/app")
+	second.CreatedAt = now.Add(-time.Hour)
+
+	entry := NewArchivedTask(first, "Fix the OAuth redirect", now.Add(-time.Minute))
+	if entry.Duration != 2*time.Hour || entry.Branch != "flock-001" {
+		t.Errorf("expected 2h on flock-001, got %s on %s", entry.Duration, entry.Branch)
+	}
+	if err := archive.Add(entry); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := archive.Add(NewArchivedTask(second, "Document the API", now)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	reloaded, err := LoadArchive(path, nil)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"002", "001"}},
+		{"LOGIN", []string{"001"}},
+		{"oauth", []string{"001"}},
+		{"flock-001", []string{"001"}},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, match := range reloaded.Search(tt.query) {
+			got = append(got, match.Task.ID)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q): expected %v, got %v", tt.query, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%q): expected %v, got %v", tt.query, tt.want, got)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetDependencies sets the tasks a task waits for and how it is chained after them.
+// Unknown IDs, self-dependencies and cycles are rejected.
+func (m *Manager) SetDependencies(id string, deps []string, mode ChainMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return fmt.Errorf("task %s not found", id)
+	}
+
+	var unique []string
+	for _, dep := range deps {
+		if dep == id {
+			return fmt.Errorf("task %s cannot depend on itself", id)
+		}
+		if _, ok := m.tasks[dep]; !ok {
+			return fmt.Errorf("task %s not found", dep)
+		}
+		if !containsID(unique, dep) {
+			unique = append(unique, dep)
+		}
+	}
+
+	// Adding id -> dep creates a cycle if id is already reachable from dep
+	for _, dep := range unique {
+		if path := m.dependencyPath(dep, id, nil); path != nil {
+			return fmt.Errorf("dependency cycle: %s -> %s", id, strings.Join(path, " -> "))
+		}
+	}
+
+	task.DependsOn = unique
+	task.ChainMode = mode
+	task.UpdatedAt = time.Now()
+
+	return m.saveLocked()
+}
+
+// dependencyPath returns the chain of IDs leading from one task to another
+// through DependsOn edges, or nil if target is unreachable. Caller holds the lock.
+func (m *Manager) dependencyPath(from, target string, visited map[string]bool) []string {
+	if from == target {
+		return []string{from}
+	}
+	if visited == nil {
+		visited = make(map[string]bool)
+	}
+	if visited[from] {
+		return nil
+	}
+	visited[from] = true
+
+	t, ok := m.tasks[from]
+	if !ok {
+		return nil
+	}
+	for _, dep := range t.DependsOn {
+		if path := m.dependencyPath(dep, target, visited); path != nil {
+			return append([]string{from}, path...)
+		}
+	}
+	return nil
+}
+
+// PendingDependencies returns the IDs of a task's dependencies that are not DONE yet
+func (m *Manager) PendingDependencies(id string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, ok := m.tasks[id]
+	if !ok {
+		return nil
+	}
+	var pending []string
+	for _, dep := range t.DependsOn {
+		if d, ok := m.tasks[dep]; ok && d.Status != StatusDone {
+			pending = append(pending, dep)
+		}
+	}
+	return pending
+}
+
+// ReadyDependents returns pending tasks with dependencies that are now all DONE
+func (m *Manager) ReadyDependents() []*Task {
+	var ready []*Task
+	for _, t := range m.List() {
+		if t.Status == StatusPending && len(t.DependsOn) > 0 && len(m.PendingDependencies(t.ID)) == 0 {
+			ready = append(ready, t)
+		}
+	}
+	return ready
+}
+
+// RecordMerge stores the result of merging a task's branch
+func (m *Manager) RecordMerge(id, preMergeHead, mergeCommit string) error {
+	return m.Update(id, func(t *Task) {
+		now := time.Now()
+		t.MergedAt = &now
+		t.PreMergeHead = preMergeHead
+		t.MergeCommit = mergeCommit
+	})
+}
+
+// ClearMerge forgets a task's merge once it has been undone, so its branch can be merged again
+func (m *Manager) ClearMerge(id string) error {
+	return m.Update(id, func(t *Task) {
+		t.MergedAt = nil
+		t.PreMergeHead = ""
+		t.MergeCommit = ""
+	})
+}
+
+// LastMerge returns the most recently merged task with a recorded savepoint, if any
+func (m *Manager) LastMerge() (*Task, bool) {
+	var last *Task
+	for _, t := range m.List() {
+		if t.MergedAt == nil || t.PreMergeHead == "" || t.MergeCommit == "" {
+			continue
+		}
+		if last == nil || t.MergedAt.After(*last.MergedAt) {
+			last = t
+		}
+	}
+	return last, last != nil
+}
+
+// containsID reports whether ids contains id
+func containsID(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// removeID returns ids without id (nil if nothing remains)
+func removeID(ids []string, id string) []string {
+	var result []string
+	for _, existing := range ids {
+		if existing != id {
+			result = append(result, existing)
+		}
+	}
+	return result
+}
+
+// ParseChainMode converts a user-facing chain mode name ("start", "worktree", "merge") to a ChainMode
+func ParseChainMode(value string) (ChainMode, error) {
+	switch value {
+	case "", "start":
+		return ChainStart, nil
+	case string(ChainWorktree), string(ChainMerge):
+		return ChainMode(value), nil
+	default:
+		return "", fmt.Errorf("unknown chain mode %q", value)
+	}
+}
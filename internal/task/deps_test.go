@@ -0,0 +1,57 @@
+package task
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSetDependencies(t *testing.T) {
+	store, err := NewStoreWithPath(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(store)
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := m.Create(name, "", "."); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		deps    []string
+		wantErr bool
+	}{
+		{"chain b after a", "001", []string{"000"}, false},
+		{"chain c after b", "002", []string{"001", "001"}, false},
+		{"self dependency", "000", []string{"000"}, true},
+		{"unknown task", "000", []string{"999"}, true},
+		{"cycle through b and c", "000", []string{"002"}, true},
+	}
+
+	for _, tt := range tests {
+		err := m.SetDependencies(tt.id, tt.deps, ChainStart)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, expected error: %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if c, _ := m.Get("002"); len(c.DependsOn) != 1 {
+		t.Errorf("expected duplicate dependencies to be collapsed, got %v", c.DependsOn)
+	}
+
+	if ready := m.ReadyDependents(); len(ready) != 0 {
+		t.Errorf("expected no ready tasks, got %d", len(ready))
+	}
+	m.UpdateStatus("000", StatusDone)
+	ready := m.ReadyDependents()
+	if len(ready) != 1 || ready[0].ID != "001" {
+		t.Errorf("expected task 001 to be ready, got %v", ready)
+	}
+
+	m.Delete("001")
+	if c, _ := m.Get("002"); len(c.DependsOn) != 0 {
+		t.Errorf("expected deleted task to be removed from dependencies, got %v", c.DependsOn)
+	}
+}
@@ -1,10 +1,15 @@
 package task
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
+// ErrClosed is returned by changes made after the manager was closed
+var ErrClosed = errors.New("task manager is closed")
+
 // Manager handles task CRUD operations
 type Manager struct {
 	tasks   map[string]*Task
@@ -12,6 +17,7 @@ type Manager struct {
 	store   *Store
 	mu      sync.RWMutex
 	counter int
+	closed  bool // Set by Close; the store is no longer written
 }
 
 // NewManager creates a new task manager with the given store
@@ -37,6 +43,7 @@ func (m *Manager) Load() error {
 	m.order = make([]string, 0, len(tasks))
 
 	for _, t := range tasks {
+		t.backfillTimes()
 		m.tasks[t.ID] = t
 		m.order = append(m.order, t.ID)
 		// Update counter to be higher than any existing ID
@@ -51,12 +58,39 @@ func (m *Manager) Load() error {
 
 // Save persists tasks to the store
 func (m *Manager) Save() error {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.saveLocked()
+}
 
+// Close saves the tasks one last time and stops writing the store, so work still
+// finishing in the background during shutdown gets ErrClosed instead of racing with
+// whatever runs after flock's last save (e.g. encrypting files at rest)
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	err := m.saveLocked()
+	m.closed = true
+	return err
+}
+
+// Recovered returns the backup the tasks were loaded from because the task file was
+// damaged, or "" if the file loaded normally
+func (m *Manager) Recovered() string {
+	return m.store.Recovered()
+}
+
+// saveLocked persists tasks in order. Caller holds the lock.
+func (m *Manager) saveLocked() error {
+	if m.closed {
+		return ErrClosed
+	}
 	tasks := make([]*Task, 0, len(m.order))
-	for _, id := range m.order {
-		tasks = append(tasks, m.tasks[id])
+	for _, oid := range m.order {
+		tasks = append(tasks, m.tasks[oid])
 	}
 	return m.store.Save(tasks)
 }
@@ -67,6 +101,10 @@ type CreateOptions struct {
 	WorktreePath string
 	GitBranch    string
 	RepoRoot     string
+	Project      string
+	Template     string
+	Agent        string
+	Permission   PermissionMode
 }
 
 // Create creates a new task (simple version without worktree)
@@ -90,17 +128,17 @@ func (m *Manager) CreateWithOptions(name, promptFile, cwd string, opts *CreateOp
 		task.WorktreePath = opts.WorktreePath
 		task.GitBranch = opts.GitBranch
 		task.RepoRoot = opts.RepoRoot
+		task.Project = opts.Project
+		task.Template = opts.Template
+		task.Agent = opts.Agent
+		task.Permission = opts.Permission
 	}
 
 	m.tasks[id] = task
 	m.order = append(m.order, id)
 
 	// Save after creation
-	tasks := make([]*Task, 0, len(m.order))
-	for _, oid := range m.order {
-		tasks = append(tasks, m.tasks[oid])
-	}
-	if err := m.store.Save(tasks); err != nil {
+	if err := m.saveLocked(); err != nil {
 		return nil, err
 	}
 
@@ -136,17 +174,27 @@ func (m *Manager) Update(id string, fn func(*Task)) error {
 	fn(task)
 
 	// Save after update
-	tasks := make([]*Task, 0, len(m.order))
-	for _, oid := range m.order {
-		tasks = append(tasks, m.tasks[oid])
-	}
-	return m.store.Save(tasks)
+	return m.saveLocked()
 }
 
-// UpdateStatus updates a task's status
+// UpdateStatus updates a task's status, recording when it completes
 func (m *Manager) UpdateStatus(id string, status Status) error {
+	return m.UpdateWaiting(id, status, "")
+}
+
+// UpdateWaiting updates a task's status like UpdateStatus, recording why it is WAITING
+func (m *Manager) UpdateWaiting(id string, status Status, reason WaitReason) error {
 	return m.Update(id, func(t *Task) {
-		t.Status = status
+		if status == StatusWaiting {
+			t.WaitReason = reason
+		}
+		if status == StatusDone && t.Status != StatusDone {
+			now := time.Now()
+			t.CompletedAt = &now
+		} else if status != StatusDone {
+			t.CompletedAt = nil
+		}
+		t.setStatus(status)
 	})
 }
 
@@ -161,6 +209,11 @@ func (m *Manager) Delete(id string) error {
 
 	delete(m.tasks, id)
 
+	// Forget the deleted task as a dependency
+	for _, t := range m.tasks {
+		t.DependsOn = removeID(t.DependsOn, id)
+	}
+
 	// Remove from order
 	newOrder := make([]string, 0, len(m.order)-1)
 	for _, oid := range m.order {
@@ -171,11 +224,7 @@ func (m *Manager) Delete(id string) error {
 	m.order = newOrder
 
 	// Save after deletion
-	tasks := make([]*Task, 0, len(m.order))
-	for _, oid := range m.order {
-		tasks = append(tasks, m.tasks[oid])
-	}
-	return m.store.Save(tasks)
+	return m.saveLocked()
 }
 
 // List returns all tasks in order
@@ -190,6 +239,61 @@ func (m *Manager) List() []*Task {
 	return tasks
 }
 
+// BackfillProjects sets the project of tasks created before tasks were keyed by project
+// and saves if any changed. Returns the number of tasks updated.
+func (m *Manager) BackfillProjects(resolve func(*Task) string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updated := 0
+	for _, id := range m.order {
+		t := m.tasks[id]
+		if t.Project != "" {
+			continue
+		}
+		if t.Project = resolve(t); t.Project != "" {
+			updated++
+		}
+	}
+	if updated == 0 {
+		return 0, nil
+	}
+
+	return updated, m.saveLocked()
+}
+
+// Reviews returns the reviewer tasks linked to a task, in order
+func (m *Manager) Reviews(id string) []*Task {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var reviews []*Task
+	for _, taskID := range m.order {
+		if t := m.tasks[taskID]; t.ReviewOf == id {
+			reviews = append(reviews, t)
+		}
+	}
+	return reviews
+}
+
+// WorktreeShared reports whether another task works in the same worktree as a task,
+// e.g. its reviewer, so the worktree must outlive it
+func (m *Manager) WorktreeShared(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, ok := m.tasks[id]
+	if !ok || t.WorktreePath == "" {
+		return false
+	}
+	for _, other := range m.tasks {
+		if other.ID != id && other.WorktreePath == t.WorktreePath {
+			return true
+		}
+	}
+	return false
+}
+
 // FindByTabName finds a task by its tab name
 func (m *Manager) FindByTabName(tabName string) (*Task, bool) {
 	m.mu.RLock()
@@ -203,6 +307,44 @@ func (m *Manager) FindByTabName(tabName string) (*Task, bool) {
 	return nil, false
 }
 
+// CycleTabs returns the started task adjacent to fromID in task order,
+// stepping forward (step > 0) or backward (step < 0) and wrapping around.
+// Only tasks that have a tab are considered, so unrelated tabs are skipped.
+// If fromID is not a started task, the first (or last) one is returned.
+func (m *Manager) CycleTabs(fromID string, step int) (*Task, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var started []*Task
+	for _, id := range m.order {
+		t := m.tasks[id]
+		if t.HasTab() {
+			started = append(started, t)
+		}
+	}
+	if len(started) == 0 {
+		return nil, false
+	}
+
+	current := -1
+	for i, t := range started {
+		if t.ID == fromID {
+			current = i
+			break
+		}
+	}
+
+	if current == -1 {
+		if step < 0 {
+			return started[len(started)-1], true
+		}
+		return started[0], true
+	}
+
+	next := ((current+step)%len(started) + len(started)) % len(started)
+	return started[next], true
+}
+
 // Count returns the number of tasks
 func (m *Manager) Count() int {
 	m.mu.RLock()
@@ -237,3 +379,17 @@ func (m *Manager) WaitingCount() int {
 	}
 	return count
 }
+
+// StalledCount returns the number of tasks whose agent stopped reporting
+func (m *Manager) StalledCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, task := range m.tasks {
+		if task.IsStalled() {
+			count++
+		}
+	}
+	return count
+}
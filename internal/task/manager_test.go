@@ -0,0 +1,79 @@
+package task
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, err := NewStoreWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(store)
+	task, err := m.Create("a", "", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.UpdateStatus(task.ID, StatusWorking); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := m.UpdateStatus(task.ID, StatusDone); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+	if _, err := m.Create("b", "", "."); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed creating a task after Close, got %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("expected closing twice to succeed, got %v", err)
+	}
+
+	// The store keeps the state from when it was closed
+	reloaded := NewManager(store)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	tasks := reloaded.List()
+	if len(tasks) != 1 || tasks[0].Status != StatusWorking {
+		t.Errorf("expected one WORKING task saved, got %d tasks", len(tasks))
+	}
+}
+
+func TestReviews(t *testing.T) {
+	store, err := NewStoreWithPath(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(store)
+	parent, err := m.CreateWithOptions("login", "", ".", &CreateOptions{WorktreePath: "/wt/001", GitBranch: "flock-001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := m.CreateWithOptions("docs", "", ".", &CreateOptions{WorktreePath: "/wt/002", GitBranch: "flock-002"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.WorktreeShared(parent.ID) {
+		t.Errorf("expected a worktree of its own not to be shared")
+	}
+
+	review, err := m.CreateWithOptions("login-review", "", ".", &CreateOptions{WorktreePath: "/wt/001", GitBranch: "flock-001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Update(review.ID, func(t *Task) { t.ReviewOf = parent.ID }); err != nil {
+		t.Fatal(err)
+	}
+	if reviews := m.Reviews(parent.ID); len(reviews) != 1 || reviews[0].ID != review.ID {
+		t.Errorf("expected %s to be the only review, got %v", review.ID, reviews)
+	}
+	if !m.WorktreeShared(parent.ID) || !m.WorktreeShared(review.ID) || m.WorktreeShared(other.ID) {
+		t.Errorf("expected only the reviewed worktree to be shared")
+	}
+}
@@ -0,0 +1,45 @@
+package task
+
+import (
+	"fmt"
+	"time"
+)
+
+// StatusSince returns when the task entered its current status
+func (t *Task) StatusSince() time.Time {
+	if n := len(t.History); n > 0 && t.History[n-1].Status == t.Status {
+		return t.History[n-1].At
+	}
+	return t.UpdatedAt
+}
+
+// NudgeDue reports whether a WAITING task has waited at least after and should be nudged.
+// Each stretch of waiting gets one nudge, and no task gets more than max (0 means no limit).
+// all nudges tasks that did not turn auto-nudge on.
+func (t *Task) NudgeDue(now time.Time, after time.Duration, max int, all bool) bool {
+	if t.Status != StatusWaiting || after <= 0 || !(t.AutoNudge || all) || !t.HasTab() {
+		return false
+	}
+	if max > 0 && t.Nudges >= max {
+		return false
+	}
+	since := t.StatusSince()
+	if t.NudgedAt != nil && !t.NudgedAt.Before(since) {
+		return false // already nudged while waiting this time
+	}
+	return now.Sub(since) >= after
+}
+
+// RecordNudge counts an auto-nudge sent to the task at now
+func (m *Manager) RecordNudge(id string, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return fmt.Errorf("task %s not found", id)
+	}
+	task.Nudges++
+	task.NudgedAt = &now
+	return m.saveLocked()
+}
@@ -0,0 +1,45 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNudgeDue(t *testing.T) {
+	waitingSince := time.Date(2025, 3, 5, 14, 0, 0, 0, time.UTC)
+	before := waitingSince.Add(-time.Minute)
+	after := waitingSince.Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		modify   func(*Task)
+		elapsed  time.Duration
+		all      bool
+		expected bool
+	}{
+		{"waited long enough", nil, 10 * time.Minute, false, true},
+		{"not long enough", nil, 9 * time.Minute, false, false},
+		{"not opted in", func(t *Task) { t.AutoNudge = false }, time.Hour, false, false},
+		{"all tasks", func(t *Task) { t.AutoNudge = false }, time.Hour, true, true},
+		{"working", func(t *Task) { t.Status = StatusWorking }, time.Hour, false, false},
+		{"no tab", func(t *Task) { t.TabName = "" }, time.Hour, false, false},
+		{"nudged while waiting", func(t *Task) { t.Nudges, t.NudgedAt = 1, &after }, time.Hour, false, false},
+		{"nudged before waiting", func(t *Task) { t.Nudges, t.NudgedAt = 1, &before }, time.Hour, false, true},
+		{"out of nudges", func(t *Task) { t.Nudges, t.NudgedAt = 3, &before }, time.Hour, false, false},
+	}
+
+	for _, tt := range tests {
+		task := &Task{
+			Status:    StatusWaiting,
+			TabName:   "task-1",
+			AutoNudge: true,
+			History:   []StatusChange{{Status: StatusWorking, At: before}, {Status: StatusWaiting, At: waitingSince}},
+		}
+		if tt.modify != nil {
+			tt.modify(task)
+		}
+		if got := task.NudgeDue(waitingSince.Add(tt.elapsed), 10*time.Minute, 3, tt.all); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
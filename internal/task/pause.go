@@ -0,0 +1,50 @@
+package task
+
+import (
+	"fmt"
+	"time"
+)
+
+// CanPause returns true if the task's agent is running in a tab and can be interrupted
+func (t *Task) CanPause() bool {
+	if !t.HasTab() {
+		return false
+	}
+	return t.Status == StatusWorking || t.Status == StatusWaiting || t.Status == StatusStalled
+}
+
+// Pause marks a running task PAUSED. The caller interrupts the agent in its tab.
+func (m *Manager) Pause(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return fmt.Errorf("task %s not found", id)
+	}
+	if !task.CanPause() {
+		return fmt.Errorf("task %s is %s, only running tasks can be paused", id, task.Status)
+	}
+
+	task.setStatus(StatusPaused)
+	task.UpdatedAt = time.Now()
+	return m.saveLocked()
+}
+
+// Resume marks a paused task WORKING again. The caller sends the continuation message to its tab.
+func (m *Manager) Resume(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return fmt.Errorf("task %s not found", id)
+	}
+	if task.Status != StatusPaused {
+		return fmt.Errorf("task %s is not paused", id)
+	}
+
+	task.setStatus(StatusWorking)
+	task.UpdatedAt = time.Now()
+	return m.saveLocked()
+}
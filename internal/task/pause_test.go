@@ -0,0 +1,50 @@
+package task
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestPauseResume(t *testing.T) {
+	store, err := NewStoreWithPath(filepath.Join(t.TempDir(), "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(store)
+	task, err := m.Create("a", "", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Pause(task.ID); err == nil {
+		t.Errorf("expected pausing a pending task to fail")
+	}
+
+	m.UpdateStatus(task.ID, StatusWorking)
+	if err := m.Pause(task.ID); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	if task.Status != StatusPaused {
+		t.Errorf("expected PAUSED, got %s", task.Status)
+	}
+
+	if err := m.Resume(task.ID); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if task.Status != StatusWorking {
+		t.Errorf("expected WORKING after resume, got %s", task.Status)
+	}
+	if err := m.Resume(task.ID); err == nil {
+		t.Errorf("expected resuming a running task to fail")
+	}
+
+	var statuses []Status
+	for _, change := range task.History {
+		statuses = append(statuses, change.Status)
+	}
+	expected := []Status{StatusPending, StatusWorking, StatusPaused, StatusWorking}
+	if fmt.Sprint(statuses) != fmt.Sprint(expected) {
+		t.Errorf("expected history %v, got %v", expected, statuses)
+	}
+}
@@ -0,0 +1,93 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dfowler/flock/internal/schedule"
+)
+
+// IsRecurring reports whether the task is a cron template that starts copies of itself
+func (t *Task) IsRecurring() bool {
+	return t.Schedule != ""
+}
+
+// SetSchedule makes a pending task start on its own: every time cron matches (starting a
+// copy each time, so the task itself stays pending), or once at the given time.
+// Passing neither clears the schedule.
+func (m *Manager) SetSchedule(id, cron string, at *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return fmt.Errorf("task %s not found", id)
+	}
+	if task.Status != StatusPending && (cron != "" || at != nil) {
+		return fmt.Errorf("task %s is %s, only pending tasks can be scheduled", id, task.Status)
+	}
+
+	var next *time.Time
+	switch {
+	case cron != "":
+		if at != nil {
+			return fmt.Errorf("a task runs on a cron schedule or at a time, not both")
+		}
+		parsed, err := schedule.Parse(cron)
+		if err != nil {
+			return err
+		}
+		n := parsed.Next(time.Now())
+		if n.IsZero() {
+			return fmt.Errorf("cron expression %q never matches", cron)
+		}
+		next = &n
+	case at != nil:
+		n := *at
+		next = &n
+	}
+
+	task.Schedule = cron
+	task.NextRun = next
+	task.UpdatedAt = time.Now()
+	return m.saveLocked()
+}
+
+// DueTasks returns pending tasks whose scheduled run is at or before now, including runs
+// missed while flock was not running (each fires once, however many were missed)
+func (m *Manager) DueTasks(now time.Time) []*Task {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var due []*Task
+	for _, id := range m.order {
+		t := m.tasks[id]
+		if t.Status == StatusPending && t.NextRun != nil && !t.NextRun.After(now) {
+			due = append(due, t)
+		}
+	}
+	return due
+}
+
+// AdvanceSchedule records that a scheduled run happened at now: recurring tasks move on
+// to their next match, one-off tasks lose their run time
+func (m *Manager) AdvanceSchedule(id string, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return fmt.Errorf("task %s not found", id)
+	}
+
+	task.NextRun = nil
+	if task.Schedule != "" {
+		if cron, err := schedule.Parse(task.Schedule); err == nil {
+			if next := cron.Next(now); !next.IsZero() {
+				task.NextRun = &next
+			}
+		}
+	}
+	task.UpdatedAt = time.Now()
+	return m.saveLocked()
+}
@@ -0,0 +1,74 @@
+package task
+
+import (
+	"sort"
+	"strings"
+)
+
+// SortOrder is how the dashboard orders the task list
+type SortOrder string
+
+const (
+	SortCreated SortOrder = ""       // Creation order
+	SortStatus  SortOrder = "status" // Tasks needing attention first, finished ones last
+	SortAge     SortOrder = "age"    // Newest first
+	SortName    SortOrder = "name"   // Alphabetical
+)
+
+// sortOrders lists the orders in the order the dashboard cycles through them
+var sortOrders = []SortOrder{SortCreated, SortStatus, SortAge, SortName}
+
+// statusRank orders statuses for SortStatus
+var statusRank = map[Status]int{
+	StatusWaiting: 0,
+	StatusStalled: 1,
+	StatusWorking: 2,
+	StatusPaused:  3,
+	StatusPending: 4,
+	StatusDone:    5,
+}
+
+// Next returns the order after o, wrapping back to creation order
+func (o SortOrder) Next() SortOrder {
+	for i, order := range sortOrders {
+		if order == o {
+			return sortOrders[(i+1)%len(sortOrders)]
+		}
+	}
+	return SortCreated
+}
+
+// Label describes the order for the dashboard, e.g. "by status"
+func (o SortOrder) Label() string {
+	if o == SortCreated {
+		return "by creation"
+	}
+	return "by " + string(o)
+}
+
+// Sort orders tasks in place. Ties keep their creation order.
+func Sort(tasks []*Task, order SortOrder) {
+	var less func(a, b *Task) bool
+	switch order {
+	case SortStatus:
+		less = func(a, b *Task) bool { return statusRank[a.Status] < statusRank[b.Status] }
+	case SortAge:
+		less = func(a, b *Task) bool { return a.CreatedAt.After(b.CreatedAt) }
+	case SortName:
+		less = func(a, b *Task) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	default:
+		return
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return less(tasks[i], tasks[j]) })
+}
+
+// Matches reports whether the task's name, branch or directory contains query, ignoring case
+func (t *Task) Matches(query string) bool {
+	query = strings.ToLower(query)
+	for _, field := range []string{t.Name, t.GitBranch, t.Cwd, t.WorktreePath} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSort(t *testing.T) {
+	start := time.Date(2025, 3, 5, 14, 0, 0, 0, time.UTC)
+	newTasks := func() []*Task {
+		return []*Task{
+			{ID: "000", Name: "login", Status: StatusDone, CreatedAt: start},
+			{ID: "001", Name: "Api", Status: StatusWorking, CreatedAt: start.Add(time.Hour)},
+			{ID: "002", Name: "docs", Status: StatusWaiting, CreatedAt: start.Add(2 * time.Hour)},
+			{ID: "003", Name: "cache", Status: StatusWorking, CreatedAt: start.Add(3 * time.Hour)},
+		}
+	}
+
+	tests := []struct {
+		order SortOrder
+		want  string
+	}{
+		{SortCreated, "000 001 002 003"},
+		{SortStatus, "002 001 003 000"},
+		{SortAge, "003 002 001 000"},
+		{SortName, "001 003 002 000"},
+	}
+	for _, tt := range tests {
+		tasks := newTasks()
+		Sort(tasks, tt.order)
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		if got := strings.Join(ids, " "); got != tt.want {
+			t.Errorf("sort %q: expected %s, got %s", tt.order, tt.want, got)
+		}
+	}
+}
+
+func TestSortOrderNext(t *testing.T) {
+	order := SortCreated
+	for _, want := range []SortOrder{SortStatus, SortAge, SortName, SortCreated} {
+		order = order.Next()
+		if order != want {
+			t.Errorf("expected %q, got %q", want, order)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	task := &Task{Name: "add-login-form", GitBranch: "flock/auth", Cwd: "/home/bob/src/Web"}
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"login", true},
+		{"LOGIN", true},
+		{"auth", true},
+		{"src/web", true},
+		{"billing", false},
+	}
+	for _, tt := range tests {
+		if got := task.Matches(tt.query); got != tt.expected {
+			t.Errorf("Matches(%q): expected %v, got %v", tt.query, tt.expected, got)
+		}
+	}
+}
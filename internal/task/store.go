@@ -2,8 +2,15 @@ package task
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/dfowler/flock/internal/filelock"
+	"github.com/dfowler/flock/internal/vault"
 )
 
 const (
@@ -11,9 +18,22 @@ const (
 	tasksFile        = "tasks.json"
 )
 
+// DefaultBackups is how many earlier versions of the task file are kept
+const DefaultBackups = 5
+
+// backupInterval is the least time between backups, so they reach further back than the
+// last few saves
+const backupInterval = 10 * time.Minute
+
+// ErrCorrupt is returned for a task file that exists but can't be parsed
+var ErrCorrupt = errors.New("task file is damaged")
+
 // Store handles task persistence to JSON files
 type Store struct {
-	path string
+	path      string
+	vault     *vault.Vault // Encrypts the file at rest (nil for plaintext)
+	backups   int          // Earlier versions kept as tasks.json.1 (newest) to tasks.json.N
+	recovered string       // Backup loaded because the file was damaged ("" if none)
 }
 
 // NewStore creates a new store at the default location (~/.flock/tasks.json)
@@ -29,7 +49,8 @@ func NewStore() (*Store, error) {
 	}
 
 	return &Store{
-		path: filepath.Join(configDir, tasksFile),
+		path:    filepath.Join(configDir, tasksFile),
+		backups: DefaultBackups,
 	}, nil
 }
 
@@ -39,35 +60,131 @@ func NewStoreWithPath(path string) (*Store, error) {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, err
 	}
-	return &Store{path: path}, nil
+	return &Store{path: path, backups: DefaultBackups}, nil
+}
+
+// SetVault sets the vault that encrypts the task file
+func (s *Store) SetVault(v *vault.Vault) {
+	s.vault = v
+}
+
+// SetBackups changes how many earlier versions of the task file are kept (DefaultBackups if n <= 0)
+func (s *Store) SetBackups(n int) {
+	if n <= 0 {
+		n = DefaultBackups
+	}
+	s.backups = n
 }
 
-// Load loads tasks from the JSON file
+// Load loads tasks from the JSON file. A damaged file is moved aside and the newest
+// backup that can be read is loaded instead; Recovered then names it.
 func (s *Store) Load() ([]*Task, error) {
-	data, err := os.ReadFile(s.path)
+	unlock, err := s.lock()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return []*Task{}, nil
-		}
 		return nil, err
 	}
+	defer unlock()
+
+	tasks, err := s.load(s.path)
+	if os.IsNotExist(err) {
+		return []*Task{}, nil
+	}
+	if !errors.Is(err, ErrCorrupt) {
+		return tasks, err
+	}
+
+	// Keep the damaged file for inspection, where saving can't overwrite it
+	aside := fmt.Sprintf("%s.damaged-%s", s.path, time.Now().Format("20060102-150405"))
+	if renameErr := os.Rename(s.path, aside); renameErr != nil {
+		return nil, fmt.Errorf("%w (and failed to move it aside: %v)", err, renameErr)
+	}
+	for n := 1; n <= s.backups; n++ {
+		backup := s.backupPath(n)
+		if tasks, backupErr := s.load(backup); backupErr == nil {
+			s.recovered = backup
+			slog.Warn("task file was damaged; loaded a backup", "damaged", aside, "backup", backup, "err", err)
+			return tasks, nil
+		}
+	}
+	return nil, fmt.Errorf("%w; it was moved to %s and no backup could be read", err, aside)
+}
 
+// load reads and parses a task file
+func (s *Store) load(path string) ([]*Task, error) {
+	data, err := s.vault.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
 	var tasks []*Task
 	if err := json.Unmarshal(data, &tasks); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, path, err)
 	}
-
 	return tasks, nil
 }
 
-// Save saves tasks to the JSON file
+// Recovered returns the backup loaded in place of a damaged task file, or "" if none was
+func (s *Store) Recovered() string {
+	return s.recovered
+}
+
+// Save saves tasks to the JSON file, replacing it atomically after backing it up
 func (s *Store) Save(tasks []*Task) error {
 	data, err := json.MarshalIndent(tasks, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(s.path, data, 0644)
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := s.backup(); err != nil {
+		slog.Warn("failed to back up tasks", "err", err)
+	}
+	return s.vault.WriteFile(s.path, data, 0644)
+}
+
+// backup copies the task file to tasks.json.1, shifting older backups up and dropping
+// the oldest. It does nothing while the newest backup is recent.
+func (s *Store) backup() error {
+	if info, err := os.Stat(s.backupPath(1)); err == nil && time.Since(info.ModTime()) < backupInterval {
+		return nil
+	}
+	// Copied as is, so backups stay encrypted when the file is
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for n := s.backups - 1; n >= 1; n-- {
+		if err := os.Rename(s.backupPath(n), s.backupPath(n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.WriteFile(s.backupPath(1), data, 0644)
+}
+
+// lock waits for other flock processes to finish reading or writing the task file, e.g.
+// `flock tab next` loading it while the dashboard saves, and returns a function that
+// lets them go on
+func (s *Store) lock() (func(), error) {
+	file, err := os.OpenFile(s.path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open task lock: %w", err)
+	}
+	if err := filelock.Lock(file, true, true); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock tasks: %w", err)
+	}
+	return func() { file.Close() }, nil
+}
+
+// backupPath returns the path of the nth newest backup
+func (s *Store) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
 }
 
 // Path returns the store file path
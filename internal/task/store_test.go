@@ -0,0 +1,134 @@
+package task
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/filelock"
+)
+
+func TestStoreRecoversFromBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, err := NewStoreWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save([]*Task{{ID: "001", Name: "first"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Backs up the first version before writing the second
+	if err := store.Save([]*Task{{ID: "001", Name: "first"}, {ID: "002", Name: "second"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash mid-write, before writes were atomic
+	if err := os.WriteFile(path, []byte(`[{"id": "001", "na`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := store.Load()
+	if err != nil {
+		t.Fatalf("expected the backup to load, got %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Name != "first" {
+		t.Errorf("expected the backed-up task, got %+v", tasks)
+	}
+	if store.Recovered() != path+".1" {
+		t.Errorf("expected recovery from %s.1, got %q", path, store.Recovered())
+	}
+	damaged, _ := filepath.Glob(path + ".damaged-*")
+	if len(damaged) != 1 {
+		t.Errorf("expected the damaged file to be kept, got %v", damaged)
+	}
+
+	// Without a readable backup the damage is reported
+	os.Remove(path + ".1")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestStoreSaveWaitsForLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, err := NewStoreWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process in the middle of a save
+	other, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := filelock.Lock(other, true, true); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := make(chan error)
+	go func() { saved <- store.Save([]*Task{{ID: "001"}}) }()
+	select {
+	case err := <-saved:
+		t.Fatalf("expected Save to wait for the lock, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	other.Close()
+	select {
+	case err := <-saved:
+		if err != nil {
+			t.Errorf("expected Save to succeed once the lock was released, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Save to finish once the lock was released")
+	}
+}
+
+func TestStoreBackupRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	store, err := NewStoreWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SetBackups(2)
+
+	old := time.Now().Add(-time.Hour)
+	for i, name := range []string{"a", "b", "c", "d"} {
+		if err := store.Save([]*Task{{ID: "001", Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			// A recent backup is kept rather than replaced on every save
+			if err := store.Save([]*Task{{ID: "001", Name: "b2"}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		os.Chtimes(path+".1", old, old)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path, "d"},
+		{path + ".1", "c"},
+		{path + ".2", "b2"},
+	}
+	for _, tt := range tests {
+		tasks, err := store.load(tt.path)
+		if err != nil {
+			t.Errorf("failed to load %s: %v", tt.path, err)
+			continue
+		}
+		if len(tasks) != 1 || tasks[0].Name != tt.want {
+			t.Errorf("expected %s to hold %q, got %+v", filepath.Base(tt.path), tt.want, tasks)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups, got %s.3", path)
+	}
+}
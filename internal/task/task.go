@@ -2,6 +2,8 @@ package task
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -13,23 +15,138 @@ const (
 	StatusWorking Status = "WORKING" // Claude is actively working
 	StatusWaiting Status = "WAITING" // Claude needs user input
 	StatusDone    Status = "DONE"    // Task completed
+	StatusStalled Status = "STALLED" // WORKING, but the status file has not been refreshed in a while
+	StatusPaused  Status = "PAUSED"  // Agent interrupted by the user, waiting to be resumed
 )
 
+// WaitReason says why a WAITING agent stopped
+type WaitReason string
+
+const (
+	WaitPermission WaitReason = "permission" // Asking to use a tool
+	WaitQuestion   WaitReason = "question"   // Asked the user a question
+	WaitPlan       WaitReason = "plan"       // Wants its plan approved
+	WaitInput      WaitReason = "input"      // Idle, waiting for the next instruction
+)
+
+// WaitReasons lists every reason, in the order they are shown
+var WaitReasons = []WaitReason{WaitPermission, WaitQuestion, WaitPlan, WaitInput}
+
+// Badge returns the short label shown next to WAITING for this reason
+func (r WaitReason) Badge() string {
+	switch r {
+	case WaitPermission:
+		return "perm"
+	case WaitQuestion:
+		return "ask"
+	case WaitPlan:
+		return "plan"
+	case WaitInput:
+		return "idle"
+	}
+	return ""
+}
+
+// maxHistory caps the status changes kept per task
+const maxHistory = 100
+
+// StatusChange records when a task entered a status
+type StatusChange struct {
+	Status Status    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// ChainMode controls how a task is prepared when its dependencies finish
+type ChainMode string
+
+const (
+	ChainStart    ChainMode = ""         // Start the task as configured
+	ChainWorktree ChainMode = "worktree" // Start the task in its first dependency's worktree
+	ChainMerge    ChainMode = "merge"    // Merge the dependency branches, then start the task
+)
+
+// PermissionMode controls how much an agent may do without asking (Claude Code's permission modes)
+type PermissionMode string
+
+const (
+	PermissionDefault     PermissionMode = ""                  // The agent's own default: ask before acting
+	PermissionPlan        PermissionMode = "plan"              // Plan only, without editing files or running commands
+	PermissionAcceptEdits PermissionMode = "acceptEdits"       // Edit files without asking
+	PermissionBypass      PermissionMode = "bypassPermissions" // Do anything without asking
+)
+
+// PermissionModes lists the permission modes in order of increasing autonomy
+var PermissionModes = []PermissionMode{PermissionDefault, PermissionPlan, PermissionAcceptEdits, PermissionBypass}
+
+// ParsePermissionMode converts a permission mode name to a PermissionMode ("" or "default" is the default)
+func ParsePermissionMode(value string) (PermissionMode, error) {
+	if value == "default" {
+		return PermissionDefault, nil
+	}
+	for _, mode := range PermissionModes {
+		if string(mode) == value {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown permission mode %q (use default, plan, acceptEdits or bypassPermissions)", value)
+}
+
+// Badge returns the short label shown next to tasks in this mode ("" for the default)
+func (p PermissionMode) Badge() string {
+	switch p {
+	case PermissionPlan:
+		return "plan"
+	case PermissionAcceptEdits:
+		return "auto-edit"
+	case PermissionBypass:
+		return "bypass"
+	}
+	return ""
+}
+
 // Task represents an AI agent task
 type Task struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	PromptFile   string    `json:"prompt_file,omitempty"` // Path to the markdown prompt file (new format)
-	Prompt       string    `json:"prompt,omitempty"`      // Legacy: inline prompt text (for backward compatibility)
-	Cwd          string    `json:"cwd"`
-	Status       Status    `json:"status"`
-	TabName      string    `json:"tab_name"`
-	UseWorktree  bool      `json:"use_worktree"`
-	WorktreePath string    `json:"worktree_path,omitempty"` // Absolute path to git worktree
-	GitBranch    string    `json:"git_branch,omitempty"`    // Branch name in worktree
-	RepoRoot     string    `json:"repo_root,omitempty"`     // Path to main git repository
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	PromptFile   string         `json:"prompt_file,omitempty"` // Path to the markdown prompt file (new format)
+	Prompt       string         `json:"prompt,omitempty"`      // Legacy: inline prompt text (for backward compatibility)
+	Cwd          string         `json:"cwd"`
+	Status       Status         `json:"status"`
+	TabName      string         `json:"tab_name"`
+	UseWorktree  bool           `json:"use_worktree"`
+	WorktreePath string         `json:"worktree_path,omitempty"`   // Absolute path to git worktree
+	GitBranch    string         `json:"git_branch,omitempty"`      // Branch name in worktree
+	RepoRoot     string         `json:"repo_root,omitempty"`       // Path to main git repository
+	Project      string         `json:"project,omitempty"`         // Main repo root (or directory outside git) the task belongs to
+	TabClosed    bool           `json:"tab_closed,omitempty"`      // Tab was closed after completion
+	Template     string         `json:"template,omitempty"`        // Prompt template the task was created from
+	Agent        string         `json:"agent,omitempty"`           // Agent to launch (empty means the configured default)
+	Permission   PermissionMode `json:"permission_mode,omitempty"` // Agent permission mode (empty means the agent's default)
+	DependsOn    []string       `json:"depends_on,omitempty"`      // Task IDs that must be DONE before this task auto-starts
+	ChainMode    ChainMode      `json:"chain_mode,omitempty"`      // How to prepare the task once its dependencies are DONE
+	Attachments  []string       `json:"attachments,omitempty"`     // File paths and URLs listed in the prompt's Context section
+	MergedAt     *time.Time     `json:"merged_at,omitempty"`       // When the task's branch was merged
+	PreMergeHead string         `json:"pre_merge_head,omitempty"`  // Default branch HEAD before the merge
+	MergeCommit  string         `json:"merge_commit,omitempty"`    // Default branch HEAD after the merge
+	PRURL        string         `json:"pr_url,omitempty"`          // Pull request opened for the task's branch
+	Schedule     string         `json:"schedule,omitempty"`        // Cron expression; each run starts a copy of the task
+	NextRun      *time.Time     `json:"next_run,omitempty"`        // When the task (or its next copy) starts on its own
+	AutoNudge    bool           `json:"auto_nudge,omitempty"`      // Type the nudge message when the agent waits too long
+	Nudges       int            `json:"nudges,omitempty"`          // Auto-nudges sent so far
+	NudgedAt     *time.Time     `json:"nudged_at,omitempty"`       // When the last auto-nudge was sent
+	Setup        string         `json:"setup,omitempty"`           // Shell command run in the task's directory before the agent starts
+	Teardown     string         `json:"teardown,omitempty"`        // Shell command run in the task's directory once it reaches DONE
+	HookError    string         `json:"hook_error,omitempty"`      // Why the last setup or teardown command failed
+	Handoff      bool           `json:"handoff,omitempty"`         // Add the dependencies' changes and final messages to the prompt before starting
+	FinalMessage string         `json:"final_message,omitempty"`   // What the agent said when it last finished
+	ReviewOf     string         `json:"review_of,omitempty"`       // Task whose changes this reviewer task reviews
+	WaitReason   WaitReason     `json:"wait_reason,omitempty"`     // Why the agent is WAITING ("" when it isn't or didn't say)
+	WorkingTime  time.Duration  `json:"working_time,omitempty"`    // Time spent WORKING before the current status (see TimeSpent)
+	WaitingTime  time.Duration  `json:"waiting_time,omitempty"`    // Time spent WAITING before the current status
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	CompletedAt  *time.Time     `json:"completed_at,omitempty"` // When the task last reached DONE
+	History      []StatusChange `json:"history,omitempty"`      // Status changes, oldest first
 }
 
 // GetPromptOrFile returns the prompt file path, or legacy prompt if no file exists
@@ -60,6 +177,24 @@ func NewTask(id, name, promptFile, cwd string) *Task {
 		TabName:    tabName,
 		CreatedAt:  now,
 		UpdatedAt:  now,
+		History:    []StatusChange{{Status: StatusPending, At: now}},
+	}
+}
+
+// setStatus changes the task's status and records the change in its history
+func (t *Task) setStatus(status Status) {
+	if status != StatusWaiting {
+		t.WaitReason = ""
+	}
+	if status == t.Status && len(t.History) > 0 {
+		return
+	}
+	now := time.Now()
+	t.closeStretch(now)
+	t.Status = status
+	t.History = append(t.History, StatusChange{Status: status, At: now})
+	if len(t.History) > maxHistory {
+		t.History = t.History[len(t.History)-maxHistory:]
 	}
 }
 
@@ -101,11 +236,21 @@ func (t *Task) IsActive() bool {
 	return t.Status != StatusPending && t.Status != StatusDone
 }
 
+// HasTab returns true if the task was started and its tab has not been closed
+func (t *Task) HasTab() bool {
+	return t.Status != StatusPending && t.TabName != "" && !t.TabClosed
+}
+
 // NeedsAttention returns true if the task needs user input
 func (t *Task) NeedsAttention() bool {
 	return t.Status == StatusWaiting
 }
 
+// IsStalled returns true if the task's agent stopped reporting while working
+func (t *Task) IsStalled() bool {
+	return t.Status == StatusStalled
+}
+
 // GetID returns the task ID (implements git.TaskWorktreeInfo)
 func (t *Task) GetID() string {
 	return t.ID
@@ -128,3 +273,23 @@ func (t *Task) EffectiveCwd() string {
 	}
 	return t.Cwd
 }
+
+// NotesFile is where agents keep their working notes, relative to the task's directory
+const NotesFile = ".flock/notes.md"
+
+// NotesPath returns the task's working notes file, which the agent writes and the dashboard shows
+func (t *Task) NotesPath() string {
+	return filepath.Join(t.WorkDir(), NotesFile)
+}
+
+// WorkDir returns the directory the task's agent runs in: EffectiveCwd, or the home
+// directory for a task that never recorded one, rather than wherever flock was started
+func (t *Task) WorkDir() string {
+	if dir := t.EffectiveCwd(); dir != "" {
+		return dir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return "/"
+}
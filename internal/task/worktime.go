@@ -0,0 +1,71 @@
+package task
+
+import (
+	"fmt"
+	"time"
+)
+
+// closeStretch adds the time since the last status change to the running totals, before
+// the status changes again. History is capped, so the totals are what keeps earlier time.
+func (t *Task) closeStretch(now time.Time) {
+	if len(t.History) == 0 {
+		return
+	}
+	last := t.History[len(t.History)-1]
+	if spent := now.Sub(last.At); spent > 0 {
+		t.addTime(last.Status, spent)
+	}
+}
+
+// addTime counts d toward the total for status
+func (t *Task) addTime(status Status, d time.Duration) {
+	switch status {
+	case StatusWorking:
+		t.WorkingTime += d
+	case StatusWaiting:
+		t.WaitingTime += d
+	}
+}
+
+// TimeSpent returns how long the task has spent WORKING and WAITING in total, as of now,
+// including the current stretch
+func (t *Task) TimeSpent(now time.Time) (working, waiting time.Duration) {
+	working, waiting = t.WorkingTime, t.WaitingTime
+	if len(t.History) > 0 {
+		last := t.History[len(t.History)-1]
+		if spent := now.Sub(last.At); spent > 0 {
+			switch last.Status {
+			case StatusWorking:
+				working += spent
+			case StatusWaiting:
+				waiting += spent
+			}
+		}
+	}
+	return working, waiting
+}
+
+// backfillTimes fills in the totals of a task saved before they were kept, from its history
+func (t *Task) backfillTimes() {
+	if t.WorkingTime != 0 || t.WaitingTime != 0 {
+		return
+	}
+	for i := 0; i+1 < len(t.History); i++ {
+		t.addTime(t.History[i].Status, t.History[i+1].At.Sub(t.History[i].At))
+	}
+}
+
+// FormatSpent formats a total time compactly for a narrow column, e.g. "45s", "12m", "3h05"
+// or "2d4h"
+func FormatSpent(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02d", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours()/24), int(d.Hours())%24)
+	}
+}
@@ -0,0 +1,51 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeSpent(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	task := &Task{
+		Status: StatusWaiting,
+		History: []StatusChange{
+			{Status: StatusPending, At: start},
+			{Status: StatusWorking, At: start.Add(10 * time.Minute)},
+			{Status: StatusWaiting, At: start.Add(40 * time.Minute)},
+		},
+	}
+	task.backfillTimes()
+	if task.WorkingTime != 30*time.Minute || task.WaitingTime != 0 {
+		t.Errorf("expected 30m working from history, got %s working, %s waiting", task.WorkingTime, task.WaitingTime)
+	}
+
+	now := start.Add(50 * time.Minute)
+	if working, waiting := task.TimeSpent(now); working != 30*time.Minute || waiting != 10*time.Minute {
+		t.Errorf("expected 30m working and 10m waiting so far, got %s and %s", working, waiting)
+	}
+
+	// Totals outlive the history they came from
+	task.setStatus(StatusWorking)
+	task.History = task.History[len(task.History)-1:]
+	if working, waiting := task.TimeSpent(time.Now()); working < 30*time.Minute || waiting < 20*time.Minute {
+		t.Errorf("expected the totals to be kept, got %s working and %s waiting", working, waiting)
+	}
+}
+
+func TestFormatSpent(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{45 * time.Second, "45s"},
+		{12 * time.Minute, "12m"},
+		{3*time.Hour + 5*time.Minute, "3h05"},
+		{52 * time.Hour, "2d4h"},
+	}
+	for _, tt := range tests {
+		if got := FormatSpent(tt.d); got != tt.want {
+			t.Errorf("FormatSpent(%s): expected %q, got %q", tt.d, tt.want, got)
+		}
+	}
+}
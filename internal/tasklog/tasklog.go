@@ -0,0 +1,128 @@
+// Package tasklog rotates the per-task agent output logs kept under ~/.flock/logs/tasks.
+// Agents record to them with script(1) in append mode, so a log can be copied aside
+// and truncated while the agent is still writing.
+package tasklog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// rotateInterval is how often a Rotator checks log sizes
+const rotateInterval = time.Minute
+
+// Rotate copies the log at path to path.1, shifting older copies up to path.<keep>
+// and dropping the oldest, then truncates the log. With keep 0 the log is only truncated.
+func Rotate(path string, keep int) error {
+	if keep > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", path, keep))
+		for n := keep - 1; n >= 1; n-- {
+			from := fmt.Sprintf("%s.%d", path, n)
+			if err := os.Rename(from, fmt.Sprintf("%s.%d", path, n+1)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to rotate %s: %w", from, err)
+			}
+		}
+		if err := copyFile(path, path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate %s: %w", path, err)
+		}
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", path, err)
+	}
+	return nil
+}
+
+// RotateLarge rotates every log in dir larger than maxBytes, returning how many were rotated
+func RotateLarge(dir string, maxBytes int64, keep int) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	rotated := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".log") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() <= maxBytes {
+			continue
+		}
+		if err := Rotate(filepath.Join(dir, entry.Name()), keep); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+	return rotated, nil
+}
+
+// Rotator periodically rotates the logs in a directory that outgrow a size limit
+type Rotator struct {
+	dir      string
+	maxBytes int64
+	keep     int
+	done     chan struct{}
+}
+
+// NewRotator creates a rotator for dir; a maxBytes of 0 disables rotation
+func NewRotator(dir string, maxBytes int64, keep int) *Rotator {
+	return &Rotator{
+		dir:      dir,
+		maxBytes: maxBytes,
+		keep:     keep,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start checks the logs once a minute until Stop is called
+func (r *Rotator) Start() {
+	if r.maxBytes <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(rotateInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := RotateLarge(r.dir, r.maxBytes, r.keep); err != nil {
+					slog.Warn("log rotation failed", "err", err)
+				}
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the rotator
+func (r *Rotator) Stop() {
+	close(r.done)
+}
+
+// copyFile copies src to dst, replacing dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,51 @@
+package tasklog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotateLarge(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "<missing>"
+		}
+		return string(data)
+	}
+
+	write("001.log", "current output")
+	write("001.log.1", "previous")
+	write("001.log.2", "oldest")
+	write("002.log", "small")
+
+	rotated, err := RotateLarge(dir, 8, 2)
+	if err != nil {
+		t.Fatalf("RotateLarge failed: %v", err)
+	}
+	if rotated != 1 {
+		t.Errorf("expected 1 log rotated, got %d", rotated)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"001.log", ""},
+		{"001.log.1", "current output"},
+		{"001.log.2", "previous"},
+		{"002.log", "small"},
+	}
+	for _, tt := range tests {
+		if got := read(tt.name); got != tt.expected {
+			t.Errorf("expected %s to hold %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
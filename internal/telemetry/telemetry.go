@@ -0,0 +1,227 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/prompt"
+	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/update"
+)
+
+// Endpoint receives usage reports, set at build time with
+// -ldflags "-X github.com/dfowler/flock/internal/telemetry.Endpoint=https://..."
+// Builds without an endpoint (and no telemetry.endpoint in config) never send anything.
+var Endpoint = ""
+
+// sendInterval is how often an opted-in install sends a report
+const sendInterval = 7 * 24 * time.Hour
+
+// Report is everything telemetry sends: counts and settings, never names, paths, prompts or branches
+type Report struct {
+	InstallID string         `json:"install_id"` // Random ID generated on this machine, not derived from user data
+	Version   string         `json:"version"`
+	OS        string         `json:"os"`
+	Arch      string         `json:"arch"`
+	Backend   string         `json:"backend"` // zellij or tmux
+	Tasks     TaskCounts     `json:"tasks"`
+	Agents    map[string]int `json:"agents"` // Tasks per built-in agent; custom agents are counted as "custom"
+	Settings  Settings       `json:"settings"`
+}
+
+// TaskCounts counts tasks by state and by the features they use
+type TaskCounts struct {
+	Total           int `json:"total"`
+	Pending         int `json:"pending"`
+	Active          int `json:"active"`
+	Done            int `json:"done"`
+	Merged          int `json:"merged"`
+	Worktree        int `json:"worktree"`
+	Dependencies    int `json:"dependencies"`
+	Attachments     int `json:"attachments"`
+	CustomTemplates int `json:"custom_templates"`
+}
+
+// Settings records which optional behaviours are turned on
+type Settings struct {
+	AutoStart     bool `json:"auto_start"`
+	UseWorktree   bool `json:"use_worktree"`
+	SpareCount    int  `json:"spare_count"`
+	AutoCloseTabs bool `json:"auto_close_tabs"`
+	CaptureOutput bool `json:"capture_output"`
+	ProjectOnly   bool `json:"project_only"`
+	Notifications bool `json:"notifications"`
+}
+
+// Build assembles the report for the current tasks and config
+func Build(cfg *config.Config, tasks []*task.Task, backend, installID string) Report {
+	r := Report{
+		InstallID: installID,
+		Version:   update.Current(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Backend:   backend,
+		Agents:    make(map[string]int),
+		Settings: Settings{
+			AutoStart:     cfg.AutoStartTasks,
+			UseWorktree:   cfg.UseWorktree,
+			SpareCount:    cfg.Worktrees.SpareCount,
+			AutoCloseTabs: cfg.Tabs.AutoCloseDoneMinutes > 0,
+			CaptureOutput: cfg.Tabs.CaptureOutput,
+			ProjectOnly:   cfg.ProjectOnly,
+			Notifications: cfg.NotificationsEnabled,
+		},
+	}
+
+	for _, t := range tasks {
+		r.Tasks.Total++
+		switch {
+		case t.Status == task.StatusPending:
+			r.Tasks.Pending++
+		case t.Status == task.StatusDone:
+			r.Tasks.Done++
+		default:
+			r.Tasks.Active++
+		}
+		if t.MergedAt != nil {
+			r.Tasks.Merged++
+		}
+		if t.UseWorktree {
+			r.Tasks.Worktree++
+		}
+		if len(t.DependsOn) > 0 {
+			r.Tasks.Dependencies++
+		}
+		if len(t.Attachments) > 0 {
+			r.Tasks.Attachments++
+		}
+		if t.Template != "" && t.Template != prompt.DefaultTemplateName {
+			r.Tasks.CustomTemplates++
+		}
+
+		agent := t.Agent
+		if agent == "" {
+			agent = cfg.DefaultAgent
+		}
+		if agent == "" {
+			agent = config.DefaultAgentName
+		}
+		if !config.IsBuiltinAgent(agent) {
+			agent = "custom"
+		}
+		r.Agents[agent]++
+	}
+	return r
+}
+
+// state is the telemetry file: the install ID and when a report was last sent
+type state struct {
+	InstallID string    `json:"install_id"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// loadState reads the telemetry file, generating and saving an install ID on first use
+func loadState(path string) (state, error) {
+	var s state
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &s)
+	}
+	if s.InstallID != "" {
+		return s, nil
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return s, fmt.Errorf("failed to generate install ID: %w", err)
+	}
+	s.InstallID = hex.EncodeToString(id)
+	return s, saveState(path, s)
+}
+
+// saveState writes the telemetry file
+func saveState(path string, s state) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// InstallID returns this machine's random telemetry ID, creating it on first use
+func InstallID(statePath string) (string, error) {
+	s, err := loadState(statePath)
+	return s.InstallID, err
+}
+
+// LastSent returns when a report was last sent (zero if never)
+func LastSent(statePath string) time.Time {
+	var s state
+	if data, err := os.ReadFile(statePath); err == nil {
+		json.Unmarshal(data, &s)
+	}
+	return s.SentAt
+}
+
+// EndpointFor returns where reports go: the config override, or the built-in endpoint
+func EndpointFor(cfg *config.Config) string {
+	if cfg.Telemetry.Endpoint != "" {
+		return cfg.Telemetry.Endpoint
+	}
+	return Endpoint
+}
+
+// Send posts a report to endpoint
+func Send(ctx context.Context, endpoint string, r Report) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send usage report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send usage report: %s", resp.Status)
+	}
+	return nil
+}
+
+// SendCached sends the report built by build at most once a week, when telemetry is
+// enabled and an endpoint is configured
+func SendCached(ctx context.Context, cfg *config.Config, build func(installID string) Report) error {
+	endpoint := EndpointFor(cfg)
+	if !cfg.Telemetry.Enabled || endpoint == "" {
+		return nil
+	}
+
+	path := cfg.TelemetryStatePath()
+	s, err := loadState(path)
+	if err != nil {
+		return err
+	}
+	if time.Since(s.SentAt) < sendInterval {
+		return nil
+	}
+
+	if err := Send(ctx, endpoint, build(s.InstallID)); err != nil {
+		return err
+	}
+	s.SentAt = time.Now()
+	return saveState(path, s)
+}
@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/task"
+)
+
+func TestBuild(t *testing.T) {
+	now := time.Now()
+	tasks := []*task.Task{
+		{Name: "secret-project", Cwd: "/home/me/secret", Status: task.StatusDone, MergedAt: &now, UseWorktree: true},
+		{Name: "b", Status: task.StatusWorking, Agent: "aider", DependsOn: []string{"000"}},
+		{Name: "c", Status: task.StatusPending, Agent: "my-agent", Template: "bugfix.md"},
+	}
+
+	r := Build(&config.Config{}, tasks, "tmux", "abc")
+	want := TaskCounts{Total: 3, Pending: 1, Active: 1, Done: 1, Merged: 1, Worktree: 1, Dependencies: 1, CustomTemplates: 1}
+	if r.Tasks != want {
+		t.Errorf("expected counts %+v, got %+v", want, r.Tasks)
+	}
+	if r.Agents["claude"] != 1 || r.Agents["aider"] != 1 || r.Agents["custom"] != 1 {
+		t.Errorf("expected custom agents anonymized, got %v", r.Agents)
+	}
+
+	data, _ := json.Marshal(r)
+	if strings.Contains(string(data), "secret") || strings.Contains(string(data), "my-agent") {
+		t.Errorf("expected no names or paths in the report, got %s", data)
+	}
+}
+
+func TestSend(t *testing.T) {
+	var got Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	if err := Send(context.Background(), server.URL, Report{InstallID: "abc", Backend: "zellij"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if got.InstallID != "abc" || got.Backend != "zellij" {
+		t.Errorf("expected the report to arrive, got %+v", got)
+	}
+}
@@ -0,0 +1,50 @@
+// Package timefmt formats timestamps for display: relative times ("2m ago") where they are
+// read at a glance, and absolute times in the local time zone or, for scripts, ISO 8601.
+package timefmt
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timestamp formats, set with time_format in the config
+const (
+	Local = "local" // 2006-01-02 15:04:05 in the local time zone (the default)
+	ISO   = "iso"   // ISO 8601 / RFC 3339 with the zone offset, for machine consumers
+)
+
+// Format formats t in the local time zone, as ISO 8601 if format is ISO. Stored times keep
+// the zone they were recorded in, so they are converted first.
+func Format(t time.Time, format string) string {
+	if format == ISO {
+		return t.Local().Format(time.RFC3339)
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// Relative describes how long before now t was, e.g. "just now", "45s ago", "2m ago",
+// "3h ago" or "2d ago"; times after now read "in 5m"
+func Relative(t, now time.Time) string {
+	d := now.Sub(t)
+	if d < 0 {
+		return "in " + short(-d)
+	}
+	if d < 10*time.Second {
+		return "just now"
+	}
+	return short(d) + " ago"
+}
+
+// short rounds a duration down to its largest unit
+func short(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
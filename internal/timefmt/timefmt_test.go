@@ -0,0 +1,48 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelative(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{9 * time.Second, "just now"},
+		{45 * time.Second, "45s ago"},
+		{2*time.Minute + 30*time.Second, "2m ago"},
+		{3*time.Hour + 59*time.Minute, "3h ago"},
+		{50 * time.Hour, "2d ago"},
+		{-5 * time.Minute, "in 5m"},
+	}
+	for _, tt := range tests {
+		if got := Relative(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("Relative(%v ago): expected %q, got %q", tt.ago, tt.want, got)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	at := time.Date(2026, 3, 10, 14, 30, 5, 0, zone)
+	local := at.Local()
+
+	if got, want := Format(at, Local), local.Format("2006-01-02 15:04:05"); got != want {
+		t.Errorf("expected local time %q, got %q", want, got)
+	}
+	if got, want := Format(at, ""), Format(at, Local); got != want {
+		t.Errorf("expected the default format to be local, got %q", got)
+	}
+	iso := Format(at, ISO)
+	parsed, err := time.Parse(time.RFC3339, iso)
+	if err != nil {
+		t.Fatalf("expected RFC 3339, got %q: %v", iso, err)
+	}
+	if !parsed.Equal(at) {
+		t.Errorf("expected %q to be the same instant as %v", iso, at)
+	}
+}
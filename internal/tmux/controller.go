@@ -0,0 +1,231 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+var _ multiplexer.Backend = (*Controller)(nil)
+
+// Controller manages tmux windows for AI agent sessions
+// Each task gets its own window in the current session; tmux windows play the role of zellij tabs
+type Controller struct {
+	statusDir     string
+	controllerTab string
+	commands      runner.Runner
+}
+
+// NewController creates a new tmux controller
+func NewController() *Controller {
+	return &Controller{
+		statusDir:     multiplexer.SessionStatusDir(""),
+		controllerTab: "flock",
+		commands:      runner.Exec{},
+	}
+}
+
+// SetRunner replaces the runner used for tmux commands
+func (c *Controller) SetRunner(r runner.Runner) {
+	c.commands = r
+}
+
+// Name returns the backend name used in config
+func (c *Controller) Name() string {
+	return "tmux"
+}
+
+// windowTarget returns a target matching the window name exactly in the current session
+func windowTarget(tabName string) string {
+	return ":=" + tabName
+}
+
+// run executes a tmux command and returns its trimmed output
+func (c *Controller) run(args ...string) (string, error) {
+	output, err := runner.Bind(c.commands, "tmux", args...).Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// NewTab creates a new tmux window for a task in the background and starts its agent in it
+func (c *Controller) NewTab(l multiplexer.Launch) error {
+	if err := multiplexer.EnsureStatusDir(c.statusDir); err != nil {
+		return fmt.Errorf("failed to create status dir: %w", err)
+	}
+
+	// -d keeps the dashboard window focused
+	if _, err := c.run("new-window", "-d", "-n", l.TabName, "-c", l.Cwd); err != nil {
+		return fmt.Errorf("failed to create window: %w", err)
+	}
+
+	agentCmd := multiplexer.AgentCommand(l, c.statusDir)
+	if err := c.sendKeys(l.TabName, agentCmd); err != nil {
+		return fmt.Errorf("failed to write command: %w", err)
+	}
+	return nil
+}
+
+// sendKeys types text literally into a window's active pane and presses enter
+func (c *Controller) sendKeys(tabName, text string) error {
+	if _, err := c.run("send-keys", "-t", windowTarget(tabName), "-l", text); err != nil {
+		return err
+	}
+	_, err := c.run("send-keys", "-t", windowTarget(tabName), "Enter")
+	return err
+}
+
+// GoToTab switches to the specified window
+func (c *Controller) GoToTab(tabName string) error {
+	if _, err := c.run("select-window", "-t", windowTarget(tabName)); err != nil {
+		return fmt.Errorf("failed to go to window %s: %w", tabName, err)
+	}
+	return nil
+}
+
+// GoToController switches back to the controller window
+func (c *Controller) GoToController() error {
+	return c.GoToTab(c.controllerTab)
+}
+
+// CloseTab closes the specified window without switching to it
+func (c *Controller) CloseTab(tabName string) error {
+	if !c.TabExists(tabName) {
+		return nil
+	}
+
+	if _, err := c.run("kill-window", "-t", windowTarget(tabName)); err != nil {
+		return fmt.Errorf("failed to close window %s: %w", tabName, err)
+	}
+	return nil
+}
+
+// DumpTab saves the full scrollback of the window's active pane to path
+func (c *Controller) DumpTab(tabName, path string) error {
+	output, err := runner.Bind(c.commands, "tmux", "capture-pane", "-p", "-J", "-S", "-", "-t", windowTarget(tabName)).Output()
+	if err != nil {
+		return fmt.Errorf("failed to dump window %s: %w", tabName, err)
+	}
+	return os.WriteFile(path, output, 0644)
+}
+
+// WriteChars types text into the window's active pane without pressing enter
+func (c *Controller) WriteChars(tabName, text string) error {
+	if _, err := c.run("send-keys", "-t", windowTarget(tabName), "-l", text); err != nil {
+		return fmt.Errorf("failed to write to window %s: %w", tabName, err)
+	}
+	return nil
+}
+
+// keyNames are the tmux key names for each special key
+var keyNames = map[multiplexer.Key]string{
+	multiplexer.KeyEnter:  "Enter",
+	multiplexer.KeyEscape: "Escape",
+	multiplexer.KeyCtrlC:  "C-c",
+}
+
+// SendKey presses a special key in the window's active pane
+func (c *Controller) SendKey(tabName string, key multiplexer.Key) error {
+	name, ok := keyNames[key]
+	if !ok {
+		return fmt.Errorf("unsupported key %q", key)
+	}
+	if _, err := c.run("send-keys", "-t", windowTarget(tabName), name); err != nil {
+		return fmt.Errorf("failed to send %s to window %s: %w", key, tabName, err)
+	}
+	return nil
+}
+
+// TabExists checks if a window with the given name exists
+func (c *Controller) TabExists(tabName string) bool {
+	names, err := c.TabNames()
+	if err != nil {
+		return false
+	}
+
+	for _, name := range names {
+		if name == tabName {
+			return true
+		}
+	}
+	return false
+}
+
+// TabNames returns the names of all windows in the current session, in window order
+func (c *Controller) TabNames() ([]string, error) {
+	output, err := c.run("list-windows", "-F", "#{window_name}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list windows: %w", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// CurrentTabName returns the name of the focused window
+func (c *Controller) CurrentTabName() (string, error) {
+	name, err := c.run("display-message", "-p", "#{window_name}")
+	if err != nil {
+		return "", fmt.Errorf("failed to query current window: %w", err)
+	}
+	return name, nil
+}
+
+// ControllerFocused reports whether the controller window is focused
+func (c *Controller) ControllerFocused() bool {
+	name, err := c.CurrentTabName()
+	return err == nil && name == c.controllerTab
+}
+
+// RenameCurrentTab renames the window flock is running in
+func (c *Controller) RenameCurrentTab(name string) error {
+	args := []string{"rename-window"}
+	// Target our own pane's window, not whichever window happens to be active
+	if pane := os.Getenv("TMUX_PANE"); pane != "" {
+		args = append(args, "-t", pane)
+	}
+	if _, err := c.run(append(args, name)...); err != nil {
+		return fmt.Errorf("failed to rename window: %w", err)
+	}
+	return nil
+}
+
+// SetStatusDir changes where agents write their status files
+func (c *Controller) SetStatusDir(dir string) {
+	c.statusDir = dir
+}
+
+// SetControllerTab sets the name of the controller window
+func (c *Controller) SetControllerTab(name string) {
+	c.controllerTab = name
+}
+
+// StatusDir returns the status directory path
+func (c *Controller) StatusDir() string {
+	return c.statusDir
+}
+
+// DeleteStatusFile removes the status file for a task
+func (c *Controller) DeleteStatusFile(taskID string) error {
+	return multiplexer.DeleteStatusFile(c.statusDir, taskID)
+}
+
+// SessionName returns the name of the tmux session flock runs in ("" if tmux doesn't say)
+func (c *Controller) SessionName() string {
+	name, err := c.run("display-message", "-p", "#S")
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// IsInTmux checks if we're running inside a tmux session
+func IsInTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
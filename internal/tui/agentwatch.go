@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/capacity"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/proc"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// agentCheckInterval is how often WORKING tasks are checked for an agent that has exited
+const agentCheckInterval = 30 * time.Second
+
+// agentTickMsg triggers a check of the WORKING tasks' agent processes
+type agentTickMsg struct{}
+
+// agentCheckedMsg lists the WORKING tasks whose agent process is gone, and the resources
+// the running agents use together
+type agentCheckedMsg struct {
+	exited []string
+	sample capacity.Sample
+}
+
+// scheduleAgentCheck schedules the next agent process check
+func scheduleAgentCheck() tea.Cmd {
+	return tea.Tick(agentCheckInterval, func(t time.Time) tea.Msg {
+		return agentTickMsg{}
+	})
+}
+
+// checkAgents looks in the background for WORKING tasks whose agent is no longer running
+// in the shell flock launched it from, and sums the CPU and memory of those still running
+// for the capacity planner. Tasks started before flock recorded the shell's PID, and
+// systems without ps, are skipped.
+func (m *Model) checkAgents() tea.Cmd {
+	pidFiles := make(map[string]string)
+	for _, t := range m.tasks.List() {
+		if t.Status == task.StatusWorking {
+			pidFiles[t.ID] = multiplexer.PIDFilePath(m.mux.StatusDir(), t.ID)
+		}
+	}
+	r := m.commands
+	return func() tea.Msg {
+		if len(pidFiles) == 0 {
+			return agentCheckedMsg{}
+		}
+		table, err := proc.Snapshot(r)
+		if err != nil {
+			return agentCheckedMsg{}
+		}
+		var exited []string
+		sample := capacity.Sample{At: time.Now()}
+		for id, path := range pidFiles {
+			pid, err := proc.ReadPID(path)
+			if err != nil {
+				continue
+			}
+			usage := table.Usage(pid)
+			if !table.Alive(pid) || usage.Processes == 0 {
+				exited = append(exited, id)
+				continue
+			}
+			sample.Agents++
+			sample.CPU += usage.CPU
+			sample.RSS += usage.RSS
+		}
+		if sample.Agents > 0 {
+			sample.Load = capacity.LoadAverage(r)
+		}
+		return agentCheckedMsg{exited: exited, sample: sample}
+	}
+}
+
+// handleAgentsChecked warns about agents that exited while their task still says WORKING.
+// A task has to be seen that way twice in a row, so an agent that just quit has time to
+// report its status, and is warned about once until it's seen running again.
+func (m *Model) handleAgentsChecked(msg agentCheckedMsg) {
+	gone := make(map[string]int, len(msg.exited))
+	for _, id := range msg.exited {
+		t, ok := m.tasks.Get(id)
+		if !ok || t.Status != task.StatusWorking {
+			continue
+		}
+		gone[id] = m.agentGone[id] + 1
+		if gone[id] == 2 {
+			m.addMessage(fmt.Sprintf("%s: the agent exited but the task still says WORKING", t.Name), true)
+		}
+	}
+	m.agentGone = gone
+	m.recordCapacity(msg.sample)
+}
+
+// detailAgentProcess describes the CPU and memory the task's agent is using
+func (m Model) detailAgentProcess(t *task.Task) string {
+	if t.Status != task.StatusWorking && t.Status != task.StatusWaiting {
+		return ""
+	}
+	pid, err := proc.ReadPID(multiplexer.PIDFilePath(m.mux.StatusDir(), t.ID))
+	if err != nil {
+		return ""
+	}
+	table, err := proc.Snapshot(m.commands)
+	if err != nil {
+		return ""
+	}
+	if !table.Alive(pid) {
+		return "not running (its shell has closed)"
+	}
+	usage := table.Usage(pid)
+	if usage.Processes == 0 {
+		return "exited"
+	}
+	text := fmt.Sprintf("%s, CPU %.1f%%, memory %s", usage.Command, usage.CPU, formatBytes(usage.RSS))
+	if usage.Processes > 1 {
+		text += fmt.Sprintf(" (%d processes)", usage.Processes)
+	}
+	return text
+}
@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// maxQuickAnswers is how many answers can be picked with a number key
+const maxQuickAnswers = 9
+
+// openAnswers opens the canned answer picker for the marked WAITING tasks, or the selected task
+func (m *Model) openAnswers(selected *task.Task) {
+	targets := m.markedTasks()
+	if len(targets) == 0 && selected != nil {
+		targets = []*task.Task{selected}
+	}
+
+	m.answerTaskIDs = nil
+	for _, t := range targets {
+		if t.Status == task.StatusWaiting && t.HasTab() {
+			m.answerTaskIDs = append(m.answerTaskIDs, t.ID)
+		}
+	}
+	if len(m.answerTaskIDs) == 0 {
+		m.addMessage("Answers go to waiting agents; no selected task is WAITING", true)
+		return
+	}
+	m.mode = viewAnswers
+	m.answerSelected = 0
+	m.answerTyping = false
+	m.answerInput.Reset()
+}
+
+// sendAnswer types an answer into each target task's tab and marks the task WORKING
+func (m *Model) sendAnswer(answer string) {
+	m.mode = viewDashboard
+	sent := 0
+	for _, id := range m.answerTaskIDs {
+		t, ok := m.tasks.Get(id)
+		if !ok {
+			continue
+		}
+		if err := multiplexer.SendMessage(m.mux, t.TabName, answer); err != nil {
+			m.addMessage(fmt.Sprintf("Failed to answer %s: %v", t.Name, err), true)
+			continue
+		}
+		if err := m.tasks.UpdateStatus(t.ID, task.StatusWorking); err != nil {
+			m.addMessage(err.Error(), true)
+		}
+		// Keep the watcher from reporting the task as WAITING until the agent's hooks fire
+		if err := multiplexer.WriteStatusFile(m.mux.StatusDir(), t, task.StatusWorking); err != nil {
+			m.addMessage(err.Error(), true)
+		}
+		delete(m.marked, t.ID)
+		sent++
+	}
+	if sent == 1 {
+		m.addMessage(fmt.Sprintf("Answered %q", answer), false)
+	} else if sent > 1 {
+		m.addMessage(fmt.Sprintf("Answered %d tasks: %q", sent, answer), false)
+	}
+	m.answerTaskIDs = nil
+}
+
+// setAnswers replaces the answer library and saves it to the config
+func (m *Model) setAnswers(answers []string) {
+	m.config.Answers = answers
+	if err := m.config.Save(); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to save answers: %v", err), true)
+	}
+}
+
+// updateAnswers handles answer picker input
+func (m Model) updateAnswers(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	answers := m.config.Answers
+
+	// Typing a new answer
+	if m.answerTyping {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.answerTyping = false
+			m.answerInput.Blur()
+			m.answerInput.Reset()
+			return m, nil
+		case "enter":
+			answer := strings.TrimSpace(m.answerInput.Value())
+			m.answerTyping = false
+			m.answerInput.Blur()
+			m.answerInput.Reset()
+			if answer == "" {
+				return m, nil
+			}
+			// Keep it for next time unless it's already in the library
+			known := false
+			for _, existing := range answers {
+				known = known || existing == answer
+			}
+			if !known {
+				m.setAnswers(append(append([]string{}, answers...), answer))
+			}
+			m.sendAnswer(answer)
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.answerInput, cmd = m.answerInput.Update(msg)
+		return m, cmd
+	}
+
+	switch key := msg.String(); key {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q", "y":
+		m.mode = viewDashboard
+		m.answerTaskIDs = nil
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if i := int(key[0] - '1'); i < len(answers) {
+			m.sendAnswer(answers[i])
+		}
+
+	case "enter":
+		if m.answerSelected < len(answers) {
+			m.sendAnswer(answers[m.answerSelected])
+		}
+
+	case "j", "down":
+		if m.answerSelected < len(answers)-1 {
+			m.answerSelected++
+		}
+
+	case "k", "up":
+		if m.answerSelected > 0 {
+			m.answerSelected--
+		}
+
+	case "a":
+		m.answerTyping = true
+		m.answerInput.Focus()
+		return m, textinput.Blink
+
+	case "d":
+		if m.answerSelected < len(answers) {
+			var remaining []string
+			for i, answer := range answers {
+				if i != m.answerSelected {
+					remaining = append(remaining, answer)
+				}
+			}
+			m.setAnswers(remaining)
+			if m.answerSelected > 0 && m.answerSelected >= len(remaining) {
+				m.answerSelected--
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// viewAnswers renders the answer picker
+func (m Model) viewAnswers() string {
+	var b strings.Builder
+
+	title := fmt.Sprintf("Answer %d waiting tasks", len(m.answerTaskIDs))
+	if len(m.answerTaskIDs) == 1 {
+		if t, ok := m.tasks.Get(m.answerTaskIDs[0]); ok {
+			title = fmt.Sprintf("Answer: %s", t.Name)
+		}
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	if len(m.config.Answers) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("No saved answers yet; press a to write one."))
+		b.WriteString("\n")
+	}
+	for i, answer := range m.config.Answers {
+		key := " "
+		if i < maxQuickAnswers {
+			key = fmt.Sprintf("%d", i+1)
+		}
+		line := fmt.Sprintf("%s  %s", key, answer)
+		if i == m.answerSelected {
+			line = selectedRowStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	if m.answerTyping {
+		b.WriteString("\n")
+		b.WriteString(inputLabelStyle.Render("Answer:"))
+		b.WriteString("\n")
+		b.WriteString(m.answerInput.View())
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	help := helpStyle.Render("[1-9]send  [enter]send selected  [a]dd  [d]elete  [j/k]navigate  [esc]cancel")
+	if m.answerTyping {
+		help = helpStyle.Render("[enter]send and save  [esc]cancel")
+	}
+	b.WriteString(help)
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
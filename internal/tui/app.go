@@ -1,7 +1,9 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,11 +15,21 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/capacity"
+	"github.com/dfowler/flock/internal/chain"
 	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/flocklog"
+	"github.com/dfowler/flock/internal/gate"
 	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/msglog"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/pathfmt"
 	"github.com/dfowler/flock/internal/prompt"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/status"
 	"github.com/dfowler/flock/internal/task"
-	"github.com/dfowler/flock/internal/zellij"
+	"github.com/dfowler/flock/internal/timefmt"
 	"golang.org/x/term"
 )
 
@@ -32,35 +44,61 @@ const (
 	viewConfirmWorktreeDelete
 	viewConfirmMerge
 	viewSettings
+	viewConfirmBranch
+	viewConfirmOrphans
+	viewWorktrees
+	viewPromptHistory
+	viewSearch
+	viewDependencies
+	viewAttachments
+	viewImport
+	viewTaskFilter
+	viewArchive
+	viewConfirmClone
+	viewConfirmBulk
+	viewDetail
+	viewDiff
+	viewLog
+	viewSchedule
+	viewAnswers
+	viewHooks
+	viewTemplates
+	viewHandoff
+	viewConfirmUndoMerge
+	viewCapacity
+	viewConfirmHookUpgrade
 )
 
-// Message represents a status message to display in the TUI
-type Message struct {
-	Text      string
-	IsError   bool
-	Timestamp time.Time
-}
-
 // Model is the main TUI model
 type Model struct {
 	tasks         *task.Manager
-	zellij        *zellij.Controller
+	mux           multiplexer.Backend
 	config        *config.Config
 	promptMgr     *prompt.Manager
 	gitAssigner   *git.Assigner
+	commands      runner.Runner  // runs git, editors and fzf
+	hookScript    HookScript     // nil when hooks can't be checked
+	server        *daemon.Server // handles task requests, shared with the control API
 	selected      int
+	project       string // Project the filter shows: the selected task's when turned on, or the one flock started in
 	mode          viewMode
 	width         int
 	height        int
-	statusUpdates chan StatusUpdate
+	statusUpdates chan status.Update
 	err           error
 
 	// New task form (name, cwd, and optional goal - full prompt can be edited in external editor)
-	nameInput      textinput.Model
-	cwdInput       textinput.Model
-	goalInput      textinput.Model
-	useWorktree    bool // Per-task worktree toggle (defaults to config value)
-	focusIndex     int
+	nameInput   textinput.Model
+	cwdInput    textinput.Model
+	goalInput   textinput.Model
+	useWorktree bool // Per-task worktree toggle (defaults to config value)
+	agentIndex  int  // Selected agent in config.AgentNames() (0 is the default agent)
+	permission  task.PermissionMode
+	template    string
+	focusIndex  int
+
+	// New task form values last used per repository, used to pre-fill the form
+	repoDefaults *config.RepoDefaultsStore
 
 	// Edit task tracking
 	editingTaskID string
@@ -68,18 +106,161 @@ type Model struct {
 	// Delete confirmation tracking
 	deletingTaskID string
 
+	// Re-run dialog tracking
+	cloningTaskID string
+
+	// Tasks selected with space for bulk start/delete/merge, and the action being confirmed
+	marked     map[string]bool
+	bulkAction string
+
 	// Merge confirmation tracking
-	mergingTaskID string
-	mergeDiffInfo string
+	mergingTaskID    string
+	mergeDiffInfo    string
+	mergeCheck       *git.MergeCheck // Dry-run result (nil if the check failed)
+	mergeGate        *gate.Result    // merge.require_command result (nil while running or when not configured)
+	mergeGateRunning bool
+	mergeStrategy    git.MergeStrategy // Merge, squash or rebase, cycled in the dialog
+	mergeLoading     bool              // The diff summary and dry run are still being read
+	mergeRunning     bool              // A merge (or bulk merge) is running in the background
+
+	// Undo merge dialog tracking
+	undoTaskID  string
+	undoMethod  git.UndoMethod // Reset or revert, toggled in the dialog
+	undoRunning bool           // An undo is running in the background
+
+	// Whether each DONE task's branch is ready to merge, from the background check
+	readiness map[string]gate.Readiness
+
+	// WORKING tasks whose agent process was found gone, by how many checks in a row
+	agentGone map[string]int
+
+	// Agent resource samples for the capacity planner, and rate limits reported since the last one
+	capacityHistory *capacity.History
+	rateLimits      int
+
+	// Guided hints for `flock tutorial`
+	tutorial       bool
+	tutorialStep   int  // Index in tutorialSteps
+	tutorialHinted bool // The current step's hint has been shown
 
 	// Settings popup tracking
 	settingsSelected int
 
+	// Leftover branch confirmation tracking (task creation is paused until answered)
+	pendingTask    *editorFinishedMsg
+	branchConflict *git.BranchExistsError
+
+	// Name of the task whose worktree is being made in the background ("" when none is)
+	creatingWorktree string
+
+	// Worktrees found on disk at startup that no task references
+	orphans []git.OrphanWorktree
+
+	// Worktrees view tracking
+	worktreeRows     []worktreeRow
+	worktreeSelected int
+	worktreesLoading bool
+	worktreeConfirm  string // pending "delete" or "reset" awaiting y/N
+
+	// Prompt history view tracking
+	historyTaskID   string
+	historyVersions []prompt.Version
+	historySelected int
+	historyDiff     string
+	historyScroll   int
+
+	// Task detail view tracking
+	detailTaskID string
+	detailTab    int
+	detailLines  []string
+	detailScroll int
+	detailDiff   *diffPager // Highlights the diff tab
+
+	// Log view state
+	logRecords []flocklog.Record
+	logScroll  int
+	logLevel   slog.Level // Lowest level shown
+
+	// Hook check that found an outdated script, while the upgrade dialog is open
+	hookUpgrade *hookCheckedMsg
+
+	// Diff viewer tracking
+	diffTaskID string
+	diffPager  *diffPager
+
+	// Dependencies form tracking
+	depsTaskID string
+	depsInput  textinput.Model
+	depsMode   task.ChainMode
+
+	// Answer picker tracking
+	answerTaskIDs  []string // WAITING tasks the answer goes to
+	answerSelected int
+	answerInput    textinput.Model
+	answerTyping   bool
+
+	// Schedule form tracking
+	scheduleTaskID string
+	scheduleInput  textinput.Model
+
+	// Setup and teardown form tracking
+	hooksTaskID   string
+	setupInput    textinput.Model
+	teardownInput textinput.Model
+
+	// Attachments view tracking
+	attachTaskID   string
+	attachSelected int
+	attachInput    textinput.Model
+	attachTyping   bool
+
+	// Batch import form
+	importInput textinput.Model
+
+	// Handoff form tracking
+	handoffTaskID       string
+	handoffNameInput    textinput.Model
+	handoffGoalInput    textinput.Model
+	handoffSameWorktree bool // Continue in the finished task's worktree
+
+	// Template picker tracking
+	templateDir      string          // Project directory whose templates are shown
+	templateInput    textinput.Model // Filters the list and names new templates
+	templateNames    []string
+	templateSelected int
+	templatePicking  bool   // Opened from the new task form to choose its template
+	templateDeleting string // Template waiting for a second ctrl+d
+
+	// Prompt search view tracking
+	searchInput    textinput.Model
+	filterInput    textinput.Model // Task list filter prompt
+	taskFilter     string          // Text the task list is narrowed to
+	searchResults  []prompt.Match
+	searchSelected int
+
+	// History view tracking
+	archive         *task.Archive
+	archiveInput    textinput.Model
+	archiveResults  []*task.ArchivedTask
+	archiveSelected int
+
+	// Output panel tracking (replaces the prompt panel while shown)
+	showOutput   bool
+	outputGen    int
+	outputTaskID string
+	outputLines  []string
+	outputErr    error
+	outputScroll int // Lines scrolled up from the bottom; 0 follows new output
+
+	// Notes panel (replaces the prompt panel while shown)
+	showNotes bool
+
 	// Spinner for working status
 	spinner spinner.Model
 
-	// Status messages for the messages panel
-	messages []Message
+	// Status messages for the messages panel, and the lowest level shown
+	messages     *msglog.Log
+	messageLevel msglog.Level
 
 	// Glamour renderer for markdown (cached to avoid recreation on every render)
 	glamourRenderer      *glamour.TermRenderer
@@ -87,16 +268,23 @@ type Model struct {
 
 	// Git status (cached and updated periodically)
 	gitStatus *GitStatus
-}
 
-// StatusUpdate represents a status change from the watcher
-type StatusUpdate struct {
-	TaskID string
-	Status task.Status
+	// Custom column values, indexed like config.Columns and keyed by task ID
+	columnValues []map[string]string
+
+	// Slow git work running in the background
+	operations      []operation
+	nextOperationID int
+
+	// Each task's ahead/behind status for the Git column, keyed by task ID
+	branchStatuses map[string]git.BranchStatus
+
+	// Newer flock release, if one was found
+	updateVersion string
 }
 
 // StatusMsg is sent when a status update is received
-type StatusMsg StatusUpdate
+type StatusMsg status.Update
 
 // editorFinishedMsg is sent when the external editor closes for new task
 type editorFinishedMsg struct {
@@ -104,12 +292,16 @@ type editorFinishedMsg struct {
 	promptFile  string
 	cwd         string
 	useWorktree bool
+	agent       string
+	permission  task.PermissionMode
+	template    string
 	err         error
 }
 
 // editFinishedMsg is sent when editing an existing task's prompt file completes
 type editFinishedMsg struct {
-	err error
+	taskID string
+	err    error
 }
 
 // fzfFinishedMsg is sent when fzf directory selection completes
@@ -124,7 +316,7 @@ type gitStatusMsg struct {
 }
 
 // NewModel creates a new TUI model
-func NewModel(tasks *task.Manager, zj *zellij.Controller, cfg *config.Config, gitAssigner *git.Assigner, statusChan chan StatusUpdate) Model {
+func NewModel(tasks *task.Manager, mux multiplexer.Backend, cfg *config.Config, gitAssigner *git.Assigner, statusChan chan status.Update) Model {
 	// Name input
 	nameInput := textinput.New()
 	nameInput.Placeholder = "Task name"
@@ -143,6 +335,79 @@ func NewModel(tasks *task.Manager, zj *zellij.Controller, cfg *config.Config, gi
 	goalInput.CharLimit = 500
 	goalInput.Width = 60
 
+	// Dependencies input (comma-separated task IDs)
+	depsInput := textinput.New()
+	depsInput.Placeholder = "Task IDs, e.g. 002, 003"
+	depsInput.CharLimit = 100
+	depsInput.Width = 40
+
+	// Schedule input (cron expression or run time)
+	scheduleInput := textinput.New()
+	scheduleInput.Placeholder = "0 2 * * *  or  at 18:30"
+	scheduleInput.CharLimit = 100
+	scheduleInput.Width = 40
+
+	// Setup and teardown command inputs
+	setupInput := textinput.New()
+	setupInput.Placeholder = "npm install"
+	setupInput.CharLimit = 500
+	setupInput.Width = 60
+	teardownInput := textinput.New()
+	teardownInput.Placeholder = "npm test"
+	teardownInput.CharLimit = 500
+	teardownInput.Width = 60
+
+	// Canned answer input
+	answerInput := textinput.New()
+	answerInput.Placeholder = "Yes, but keep the public API unchanged."
+	answerInput.CharLimit = 500
+	answerInput.Width = 60
+
+	// Attachment input (path or URL)
+	// Batch import file path input
+	importInput := textinput.New()
+	importInput.Placeholder = "~/tasks.yaml"
+	importInput.CharLimit = 500
+	importInput.Width = 60
+
+	attachInput := textinput.New()
+	attachInput.Placeholder = "docs/design.md or https://..."
+	attachInput.CharLimit = 500
+	attachInput.Width = 60
+
+	// Handoff task name and goal inputs
+	handoffNameInput := textinput.New()
+	handoffNameInput.Placeholder = "review"
+	handoffNameInput.CharLimit = 100
+	handoffNameInput.Width = 60
+	handoffGoalInput := textinput.New()
+	handoffGoalInput.Placeholder = defaultHandoffGoal
+	handoffGoalInput.CharLimit = 500
+	handoffGoalInput.Width = 60
+
+	// Prompt search input
+	searchInput := textinput.New()
+	searchInput.Placeholder = "Search prompts"
+	searchInput.CharLimit = 200
+	searchInput.Width = 60
+
+	filterInput := textinput.New()
+	filterInput.Placeholder = "name, branch or directory"
+	filterInput.CharLimit = 100
+	filterInput.Width = 40
+
+	// Template filter input
+	templateInput := textinput.New()
+	templateInput.Placeholder = "Filter, or name a new template"
+	templateInput.CharLimit = 100
+	templateInput.Width = 40
+
+	// History search input
+	archiveInput := textinput.New()
+	archiveInput.Placeholder = "Search history"
+	archiveInput.CharLimit = 200
+	archiveInput.Width = 60
+
 	// Spinner for working status
 	s := spinner.New()
 	s.Spinner = spinner.Spinner{
@@ -172,37 +437,272 @@ func NewModel(tasks *task.Manager, zj *zellij.Controller, cfg *config.Config, gi
 		glamour.WithWordWrap(promptContentWidth),
 	)
 
-	return Model{
+	// A missing or unreadable file only means the form isn't pre-filled
+	repoDefaults, _ := config.LoadRepoDefaults(cfg.RepoDefaultsPath())
+	// Likewise an unreadable archive only starts the history empty
+	archive, _ := task.LoadArchive(cfg.ArchivePath(), cfg.Vault())
+	// An unknown level shows everything
+	messageLevel, _ := msglog.ParseLevel(cfg.Messages.Level)
+	// And an unreadable capacity history only means the planner starts over
+	capacityHistory := capacity.Load(cfg.CapacityPath())
+
+	m := Model{
 		tasks:                tasks,
-		zellij:               zj,
+		mux:                  mux,
 		config:               cfg,
 		promptMgr:            prompt.NewManager(cfg),
 		gitAssigner:          gitAssigner,
+		server:               daemon.NewServer(tasks, mux, cfg, gitAssigner),
+		commands:             runner.Exec{},
+		project:              git.ProjectRoot("."),
 		statusUpdates:        statusChan,
 		nameInput:            nameInput,
 		cwdInput:             cwdInput,
 		goalInput:            goalInput,
+		searchInput:          searchInput,
+		filterInput:          filterInput,
+		templateInput:        templateInput,
+		archive:              archive,
+		archiveInput:         archiveInput,
+		depsInput:            depsInput,
+		scheduleInput:        scheduleInput,
+		setupInput:           setupInput,
+		teardownInput:        teardownInput,
+		attachInput:          attachInput,
+		answerInput:          answerInput,
+		importInput:          importInput,
+		handoffNameInput:     handoffNameInput,
+		handoffGoalInput:     handoffGoalInput,
+		repoDefaults:         repoDefaults,
 		spinner:              s,
 		width:                width,
 		height:               height,
 		glamourRenderer:      glamourRenderer,
 		glamourRendererWidth: promptContentWidth,
+		columnValues:         make([]map[string]string, len(cfg.Columns)),
+		messages:             msglog.New(cfg.Messages.Size),
+		messageLevel:         messageLevel,
+		capacityHistory:      capacityHistory,
 	}
+	if backup := tasks.Recovered(); backup != "" {
+		m.addWarning(fmt.Sprintf("tasks.json was damaged; restored the tasks from %s and kept the damaged file beside it (press l for details)", filepath.Base(backup)))
+	}
+	return m
+}
+
+// SetHookScript sets the hook script the dashboard checks on start and from settings
+func (m *Model) SetHookScript(h HookScript) {
+	m.hookScript = h
+}
+
+// SetServer replaces the server handling task requests, so the dashboard and the control
+// API it serves take turns on the same one
+func (m *Model) SetServer(s *daemon.Server) {
+	m.server = s
+}
+
+// SetRunner replaces the runner used for git, editors and fzf, in the model and its prompt manager
+func (m *Model) SetRunner(r runner.Runner) {
+	m.commands = r
+	m.promptMgr.SetRunner(r)
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		waitForStatus(m.statusUpdates),
 		m.spinner.Tick,
-		refreshGitStatus(),
-	)
+		refreshGitStatus(m.commands),
+		scheduleTabReap(),
+		func() tea.Msg { return scheduleTickMsg{} }, // Catch up on runs missed while flock was closed
+		func() tea.Msg { return readyTickMsg{} },
+		scheduleAgentCheck(),
+	}
+	if m.gitAssigner != nil {
+		cmds = append(cmds, waitForWorktreeEvent(m.gitAssigner.Events()))
+	}
+	if m.config.CheckForUpdates {
+		cmds = append(cmds, checkForUpdate(m.config.UpdateStatePath()))
+	}
+	if m.config.Telemetry.Enabled {
+		cmds = append(cmds, m.sendTelemetry())
+	}
+	if m.tutorial {
+		cmds = append(cmds, func() tea.Msg { return tutorialTickMsg{} })
+	} else {
+		cmds = append(cmds, m.checkHooks(0, false))
+	}
+	cmds = append(cmds, m.reconcileWorktrees(), m.refreshColumns(), m.refreshBranchStatuses())
+	return tea.Batch(cmds...)
+}
+
+// reconcileMsg carries the result of the startup worktree reconciliation
+type reconcileMsg struct {
+	result *git.Reconciliation
+}
+
+// reconcileWorktrees returns a command that compares task worktree records with
+// the worktrees on disk, in every repo referenced by a task or used for one before
+func (m Model) reconcileWorktrees() tea.Cmd {
+	var records []git.WorktreeRecord
+	for _, t := range m.tasks.List() {
+		records = append(records, git.WorktreeRecord{
+			TaskID:       t.ID,
+			WorktreePath: t.WorktreePath,
+			GitBranch:    t.GitBranch,
+			RepoRoot:     t.RepoRoot,
+		})
+	}
+	extraRepos := m.repoDefaults.Repos()
+	return func() tea.Msg {
+		return reconcileMsg{result: git.Reconcile(records, extraRepos)}
+	}
+}
+
+// applyReconciliation fixes task records that point at missing worktrees and
+// offers cleanup of worktrees that no task references
+func (m *Model) applyReconciliation(result *git.Reconciliation) {
+	for _, fix := range result.Fixes {
+		fix := fix
+		t, ok := m.tasks.Get(fix.TaskID)
+		if !ok {
+			continue
+		}
+		if err := m.tasks.Update(fix.TaskID, func(t *task.Task) {
+			t.WorktreePath = fix.WorktreePath
+			t.GitBranch = fix.GitBranch
+		}); err != nil {
+			m.addMessage(fmt.Sprintf("Failed to repair %s: %v", t.Name, err), true)
+			continue
+		}
+		m.addMessage(fmt.Sprintf("Repaired %s: %s", t.Name, fix.Reason), false)
+	}
+
+	// Skip worktrees claimed by tasks created while the scan was running
+	inUse := make(map[string]bool)
+	for _, t := range m.tasks.List() {
+		inUse[t.WorktreePath] = true
+	}
+	var orphans []git.OrphanWorktree
+	for _, o := range result.Orphans {
+		if !inUse[o.Worktree.Path] {
+			orphans = append(orphans, o)
+		}
+	}
+
+	if len(orphans) > 0 && m.mode == viewDashboard {
+		m.orphans = orphans
+		m.mode = viewConfirmOrphans
+	}
+}
+
+// updateConfirmOrphans handles the orphaned worktree cleanup dialog input
+func (m Model) updateConfirmOrphans(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		// Remove every orphaned worktree and its branch
+		for _, o := range m.orphans {
+			var err error
+			if m.gitAssigner != nil {
+				err = m.gitAssigner.ReleaseWorktree(o.Worktree.Path, o.RepoRoot)
+			} else {
+				err = git.RemoveWorktree(o.RepoRoot, o.Worktree.Path, true)
+			}
+			if err != nil {
+				m.addMessage(fmt.Sprintf("Failed to remove %s: %v", o.DisplayName(), err), true)
+			} else {
+				m.addMessage(fmt.Sprintf("Removed orphaned worktree: %s", o.DisplayName()), false)
+			}
+		}
+		m.orphans = nil
+		m.mode = viewDashboard
+
+	case "n", "N", "esc", "enter":
+		// Keep them; they stay available for reuse by new tasks
+		m.addMessage(fmt.Sprintf("Kept %d orphaned worktree(s)", len(m.orphans)), false)
+		m.orphans = nil
+		m.mode = viewDashboard
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// worktreeEventMsg is sent when the assigner reports a background worktree event
+type worktreeEventMsg git.Event
+
+// waitForWorktreeEvent waits for background worktree events from the assigner
+func waitForWorktreeEvent(ch <-chan git.Event) tea.Cmd {
+	return func() tea.Msg {
+		return worktreeEventMsg(<-ch)
+	}
+}
+
+// tabReapTickMsg triggers a check for DONE tabs past their grace period
+type tabReapTickMsg struct{}
+
+// scheduleTabReap schedules the next check for finished tabs to close
+func scheduleTabReap() tea.Cmd {
+	return tea.Tick(30*time.Second, func(t time.Time) tea.Msg {
+		return tabReapTickMsg{}
+	})
+}
+
+// closeFinishedTabs closes tabs of tasks that have been DONE longer than the
+// configured grace period, saving each tab's transcript first.
+// Task records are kept; only the tab is reclaimed.
+func (m *Model) closeFinishedTabs() {
+	grace := time.Duration(m.config.Tabs.AutoCloseDoneMinutes) * time.Minute
+	if grace <= 0 {
+		return
+	}
+
+	var expired []*task.Task
+	for _, t := range m.tasks.List() {
+		if t.Status == task.StatusDone && t.HasTab() && t.CompletedAt != nil && time.Since(*t.CompletedAt) >= grace {
+			expired = append(expired, t)
+		}
+	}
+	if len(expired) == 0 {
+		return
+	}
+
+	// Closing a tab requires focusing it, so wait until the user is back on the dashboard
+	if !m.mux.ControllerFocused() {
+		return
+	}
+
+	for _, t := range expired {
+		if m.mux.TabExists(t.TabName) {
+			// A live capture already holds the full output
+			if _, err := os.Stat(m.config.TranscriptPath(t.ID)); err != nil {
+				if err := m.mux.DumpTab(t.TabName, m.config.TranscriptPath(t.ID)); err != nil {
+					m.addMessage(fmt.Sprintf("Failed to save transcript for %s: %v", t.Name, err), true)
+					continue
+				}
+			}
+			if err := m.mux.CloseTab(t.TabName); err != nil {
+				m.addMessage(fmt.Sprintf("Failed to close tab for %s: %v", t.Name, err), true)
+				continue
+			}
+		}
+		if err := m.tasks.Update(t.ID, func(t *task.Task) {
+			t.TabClosed = true
+		}); err != nil {
+			m.err = err
+			continue
+		}
+		m.addMessage(fmt.Sprintf("Closed tab for finished task: %s", t.Name), false)
+	}
+	m.mux.GoToController()
 }
 
 // refreshGitStatus returns a command that fetches git status
-func refreshGitStatus() tea.Cmd {
+func refreshGitStatus(r runner.Runner) tea.Cmd {
 	return func() tea.Msg {
-		return gitStatusMsg{status: GetGitStatus()}
+		return gitStatusMsg{status: GetGitStatus(r)}
 	}
 }
 
@@ -216,22 +716,29 @@ func scheduleGitStatusRefresh() tea.Cmd {
 	})
 }
 
-// addMessage adds a message to the messages panel (keeps last 5 messages)
+// addMessage adds an info or error message to the messages panel
 func (m *Model) addMessage(text string, isError bool) {
-	msg := Message{
-		Text:      text,
-		IsError:   isError,
-		Timestamp: time.Now(),
-	}
-	m.messages = append(m.messages, msg)
-	// Keep only last 5 messages
-	if len(m.messages) > 5 {
-		m.messages = m.messages[len(m.messages)-5:]
+	level := msglog.LevelInfo
+	if isError {
+		level = msglog.LevelError
+		slog.Error(text)
 	}
+	m.messages.Add(level, text)
+}
+
+// addWarning adds a warning to the messages panel
+func (m *Model) addWarning(text string) {
+	slog.Warn(text)
+	m.messages.Add(msglog.LevelWarn, text)
+}
+
+// cycleMessageLevel switches the messages panel between all messages, warnings and errors, and errors only
+func (m *Model) cycleMessageLevel() {
+	m.messageLevel = (m.messageLevel + 1) % (msglog.LevelError + 1)
 }
 
 // waitForStatus waits for status updates from the watcher
-func waitForStatus(ch chan StatusUpdate) tea.Cmd {
+func waitForStatus(ch chan status.Update) tea.Cmd {
 	return func() tea.Msg {
 		return StatusMsg(<-ch)
 	}
@@ -265,6 +772,63 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case mergeGateMsg:
+		if msg.taskID == m.mergingTaskID && m.mode == viewConfirmMerge {
+			m.mergeGate = &msg.result
+			m.mergeGateRunning = false
+		}
+		return m, nil
+
+	case bulkGateMsg:
+		return m, m.mergeMarked(msg.results)
+
+	case mergeInfoMsg:
+		// The dialog may have been closed, or opened for another task, meanwhile
+		if m.mode == viewConfirmMerge && m.mergingTaskID == msg.taskID {
+			m.mergeDiffInfo, m.mergeCheck = msg.diffInfo, msg.check
+			m.mergeLoading = false
+		}
+		return m, nil
+
+	case mergeDoneMsg:
+		m.finishOperation(msg.op)
+		m.mergeRunning = false
+		m.addNotices(msg.notices)
+		open := m.mode == viewConfirmMerge && m.mergingTaskID == msg.taskID
+		switch {
+		case msg.err != nil:
+			m.addMessage(fmt.Sprintf("Merge error: %v", msg.err), true)
+		case msg.result.HasConflicts && open && m.mergeCheck != nil:
+			// The dry run missed it (e.g. a rebase conflicting commit by commit); offer the assistant
+			m.mergeCheck.Conflicts = msg.result.Conflicts
+			if len(m.mergeCheck.Conflicts) == 0 {
+				m.mergeCheck.Conflicts = []string{"(unknown files)"}
+			}
+			m.addMessage(msg.result.Message, true)
+			return m, nil
+		case !msg.result.Success:
+			m.addMessage(msg.result.Message, true)
+		}
+		if open {
+			m.closeMergeDialog()
+		}
+		return m, nil
+
+	case bulkMergeDoneMsg:
+		m.finishOperation(msg.op)
+		m.mergeRunning = false
+		for _, id := range msg.merged {
+			delete(m.marked, id)
+		}
+		m.addNotices(msg.notices)
+		return m, nil
+
+	case teardownDoneMsg:
+		if msg.note != "" {
+			m.addMessage(msg.note, msg.failed)
+		}
+		return m, nil
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -275,86 +839,213 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, scheduleGitStatusRefresh()
 
 	case gitStatusTickMsg:
-		return m, refreshGitStatus()
+		return m, refreshGitStatus(m.commands)
+
+	case columnTickMsg:
+		return m, m.refreshColumn(msg.index)
+
+	case updateAvailableMsg:
+		m.updateVersion = msg.version
+		m.addMessage(fmt.Sprintf("flock %s is available; run `flock self-update`", msg.version), false)
+		return m, nil
+
+	case outputTickMsg:
+		if msg.gen != m.outputGen || !m.showOutput {
+			return m, nil
+		}
+		return m, m.loadOutput()
+
+	case outputLoadedMsg:
+		if msg.gen != m.outputGen {
+			return m, nil
+		}
+		if msg.taskID != m.outputTaskID {
+			m.outputScroll = 0
+		}
+		m.outputTaskID = msg.taskID
+		m.outputLines = msg.lines
+		m.outputErr = msg.err
+		m.scrollOutput(0)
+		return m, scheduleOutputRefresh(msg.gen)
+
+	case branchStatusMsg:
+		// Like custom columns, the next refresh is scheduled once this one finished
+		m.branchStatuses = msg.statuses
+		return m, scheduleBranchStatusRefresh()
+
+	case branchStatusTickMsg:
+		return m, m.refreshBranchStatuses()
+
+	case columnResultMsg:
+		// Schedule the next run only once this one finished, so slow commands never overlap
+		m.columnValues[msg.index] = msg.values
+		return m, scheduleColumnRefresh(msg.index, m.config.Columns[msg.index].Interval())
+
+	case worktreesLoadedMsg:
+		m.worktreeRows = msg.rows
+		m.worktreesLoading = false
+		if m.worktreeSelected >= len(m.worktreeRows) {
+			m.worktreeSelected = len(m.worktreeRows) - 1
+		}
+		if m.worktreeSelected < 0 {
+			m.worktreeSelected = 0
+		}
+		return m, nil
+
+	case reconcileMsg:
+		m.applyReconciliation(msg.result)
+		return m, nil
+
+	case pullRequestMsg:
+		m.finishPullRequest(msg)
+		return m, nil
+
+	case worktreeEventMsg:
+		m.addMessage(msg.Message, msg.Err != nil)
+		return m, waitForWorktreeEvent(m.gitAssigner.Events())
+
+	case tabReapTickMsg:
+		m.closeFinishedTabs()
+		return m, scheduleTabReap()
+
+	case scheduleTickMsg:
+		m.runDueTasks()
+		return m, scheduleRunCheck()
+
+	case tutorialTickMsg:
+		m.advanceTutorial()
+		if m.tutorial {
+			return m, scheduleTutorialCheck()
+		}
+		return m, nil
+
+	case readyTickMsg:
+		return m, m.checkReadiness()
+
+	case readyCheckedMsg:
+		// The next check is scheduled once this one is done, so slow check commands don't pile up
+		m.readiness = msg.results
+		return m, scheduleReadyCheck()
+
+	case agentTickMsg:
+		return m, m.checkAgents()
+
+	case agentCheckedMsg:
+		m.handleAgentsChecked(msg)
+		return m, scheduleAgentCheck()
 
 	case StatusMsg:
-		// Update task status (silently ignore if task doesn't exist)
+		// Update task status (ignore it if the task doesn't exist)
 		if t, exists := m.tasks.Get(msg.TaskID); exists {
 			oldStatus := t.Status
-			if err := m.tasks.UpdateStatus(msg.TaskID, msg.Status); err != nil {
+			oldReason := t.WaitReason
+			if err := m.tasks.UpdateWaiting(msg.TaskID, msg.Status, msg.Reason); err != nil {
 				m.err = err
 				m.addMessage(fmt.Sprintf("Error updating %s: %v", t.Name, err), true)
-			} else if oldStatus != msg.Status && m.config.NotificationsEnabled {
-				m.addMessage(fmt.Sprintf("%s → %s", t.Name, msg.Status), false)
+			} else if (oldStatus != msg.Status || oldReason != msg.Reason) && m.config.NotificationsEnabled {
+				label := string(msg.Status)
+				if msg.Status == task.StatusWaiting && msg.Reason != "" {
+					label += fmt.Sprintf(" (%s)", msg.Reason)
+				}
+				if msg.Status == task.StatusWaiting && msg.Message != "" {
+					m.addMessage(fmt.Sprintf("%s → %s: %s", t.Name, label, msg.Message), false)
+				} else {
+					m.addMessage(fmt.Sprintf("%s → %s", t.Name, label), false)
+				}
+			}
+			m.server.PublishStatus(msg.TaskID, msg.Message)
+			if msg.Event == multiplexer.EventSetup {
+				m.recordSetupFailure(t, msg.Message)
+			}
+			if capacity.IsRateLimit(msg.Message) {
+				m.rateLimits++
 			}
+			if msg.Status == task.StatusDone && msg.Message != "" {
+				// Kept for tasks this one hands off to
+				if err := m.tasks.Update(t.ID, func(t *task.Task) { t.FinalMessage = msg.Message }); err != nil {
+					m.addMessage(fmt.Sprintf("Failed to save %s's final message: %v", t.Name, err), true)
+				}
+			}
+			if msg.Status == task.StatusDone && oldStatus != task.StatusDone && t.ReviewOf != "" {
+				m.addMessage(fmt.Sprintf("%s finished reviewing %s; its findings are in its tab and info view", t.Name, t.ReviewOf), false)
+			}
+			if msg.Status == task.StatusDone {
+				m.startReadyDependents()
+				if oldStatus != task.StatusDone {
+					return m, tea.Batch(waitForStatus(m.statusUpdates), m.runTeardown(t))
+				}
+			}
+		} else {
+			slog.Debug("status update for unknown task", "task", msg.TaskID, "status", msg.Status)
 		}
 		// Continue listening for updates
 		return m, waitForStatus(m.statusUpdates)
 
 	case editorFinishedMsg:
 		// Editor closed - create the task
+		m.mode = viewDashboard
 		if msg.err != nil {
 			m.err = msg.err
 			m.addMessage(fmt.Sprintf("Editor error: %v", msg.err), true)
 		} else {
-			// Try to assign a worktree if enabled
-			createOpts := &task.CreateOptions{
-				UseWorktree: msg.useWorktree,
-			}
-			if msg.useWorktree && m.gitAssigner != nil {
-				taskID := m.tasks.NextID()
-				cwd := msg.cwd
-				if cwd == "" {
-					cwd = "."
-				}
-				// Convert to absolute path for worktree assignment
-				if !filepath.IsAbs(cwd) {
-					if absCwd, err := filepath.Abs(cwd); err == nil {
-						cwd = absCwd
-					}
-				}
-				// Get active tasks for worktree assignment
-				activeTasks := m.getTaskWorktreeInfos()
-				if assignment, err := m.gitAssigner.AssignWorktree(taskID, cwd, activeTasks); err != nil {
-					m.addMessage(fmt.Sprintf("Worktree warning: %v", err), true)
-				} else if assignment != nil {
-					createOpts.WorktreePath = assignment.WorktreePath
-					createOpts.GitBranch = assignment.GitBranch
-					createOpts.RepoRoot = assignment.RepoRoot
-				}
-			}
+			return m, m.createTask(msg, git.BranchCollisionFail)
+		}
+		return m, nil
 
-			// Create the task with the prompt file and optional worktree
-			t, err := m.tasks.CreateWithOptions(msg.taskName, msg.promptFile, msg.cwd, createOpts)
-			if err != nil {
-				m.err = err
-				m.addMessage(fmt.Sprintf("Failed to create task: %v", err), true)
-			} else {
-				if t.GitBranch != "" {
-					m.addMessage(fmt.Sprintf("Created task: %s (branch: %s)", msg.taskName, t.GitBranch), false)
-				} else {
-					m.addMessage(fmt.Sprintf("Created task: %s", msg.taskName), false)
-				}
-				m.selected = m.tasks.Count() - 1
+	case diffLoadedMsg:
+		m.showDiff(msg)
+		return m, nil
 
-				// Auto-start if enabled
-				if m.config.AutoStartTasks {
-					cwd := t.EffectiveCwd()
-					if cwd == "" {
-						cwd = "."
-					}
-					promptOrFile := t.GetPromptOrFile()
-					isFile := t.PromptFile != ""
-					if err := m.zellij.NewTab(t.ID, t.Name, t.TabName, promptOrFile, cwd, isFile); err != nil {
-						m.err = err
-						m.addMessage(fmt.Sprintf("Failed to auto-start: %v", err), true)
-					} else {
-						m.tasks.UpdateStatus(t.ID, task.StatusWorking)
-					}
-				}
-			}
+	case worktreeActionMsg:
+		m.finishOperation(msg.op)
+		m.addNotices(msg.notices)
+		m.worktreesLoading = true
+		return m, m.loadWorktrees()
+
+	case archiveStatMsg:
+		m.archiveStatDone(msg)
+		return m, nil
+
+	case conflictsPreparedMsg:
+		m.conflictsPrepared(msg)
+		return m, nil
+
+	case undoPlannedMsg:
+		m.undoPlanned(msg)
+		return m, nil
+
+	case undoDoneMsg:
+		m.undoDone(msg)
+		return m, nil
+
+	case hookCheckedMsg:
+		m.hookChecked(msg)
+		return m, nil
+
+	case detailDiffMsg:
+		// Ignore a diff for a tab that has since been left
+		if m.mode == viewDetail && m.detailTab == detailDiff && m.detailTaskID == msg.taskID {
+			m.detailLines = msg.lines
+			m.detailDiff = newDiffPager(strings.Join(msg.lines, "\n"))
 		}
-		m.mode = viewDashboard
+		return m, nil
+
+	case worktreeAssignedMsg:
+		m.finishOperation(msg.op)
+		m.creatingWorktree = ""
+		var branchErr *git.BranchExistsError
+		if errors.As(msg.err, &branchErr) {
+			// Ask whether to reuse the old branch or pick a new name
+			pending := msg.form
+			m.pendingTask = &pending
+			m.branchConflict = branchErr
+			m.mode = viewConfirmBranch
+			return m, nil
+		}
+		if msg.err != nil {
+			m.addWarning(fmt.Sprintf("Worktree warning: %v", msg.err))
+		}
+		m.finishCreateTask(msg.form, msg.assignment)
 		return m, nil
 
 	case editFinishedMsg:
@@ -363,17 +1054,52 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.err = msg.err
 			m.addMessage(fmt.Sprintf("Editor error: %v", msg.err), true)
 		} else {
+			if t, ok := m.tasks.Get(msg.taskID); ok {
+				m.snapshotPrompt(t, prompt.SnapshotEdited)
+			}
 			m.addMessage("Task updated", false)
 		}
 		m.mode = viewDashboard
 		return m, nil
 
+	case templateEditedMsg:
+		if msg.err != nil {
+			m.addMessage(fmt.Sprintf("Editor error: %v", msg.err), true)
+		} else {
+			m.addMessage(fmt.Sprintf("Saved template %s", msg.name), false)
+		}
+		m.loadTemplates()
+		return m, nil
+
+	case templatesSyncedMsg:
+		if msg.err != nil {
+			m.addMessage(fmt.Sprintf("Failed to pull shared templates: %v", msg.err), true)
+		} else {
+			m.addMessage(fmt.Sprintf("Pulled %s", msg.summary), false)
+		}
+		if m.mode == viewTemplates {
+			m.loadTemplates()
+		}
+		return m, nil
+
 	case fzfFinishedMsg:
 		// fzf directory selection completed
 		if msg.err != nil {
 			m.addMessage(fmt.Sprintf("fzf error: %v", msg.err), true)
 		} else if msg.dir != "" {
 			m.cwdInput.SetValue(msg.dir)
+			if m.mode == viewNewTask {
+				m.applyRepoDefaults(msg.dir, false)
+			}
+		}
+		return m, nil
+
+	case fzfFileFinishedMsg:
+		// Attachment file selection completed
+		if msg.err != nil {
+			m.addMessage(fmt.Sprintf("fzf error: %v", msg.err), true)
+		} else if msg.path != "" {
+			m.addAttachment(msg.path)
 		}
 		return m, nil
 
@@ -393,28 +1119,312 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateConfirmMerge(msg)
 		case viewSettings:
 			return m.updateSettings(msg)
+		case viewConfirmBranch:
+			return m.updateConfirmBranch(msg)
+		case viewConfirmOrphans:
+			return m.updateConfirmOrphans(msg)
+		case viewWorktrees:
+			return m.updateWorktrees(msg)
+		case viewPromptHistory:
+			return m.updatePromptHistory(msg)
+		case viewSearch:
+			return m.updateSearch(msg)
+		case viewDependencies:
+			return m.updateDependencies(msg)
+		case viewSchedule:
+			return m.updateSchedule(msg)
+		case viewHooks:
+			return m.updateHooks(msg)
+		case viewHandoff:
+			return m.updateHandoff(msg)
+		case viewConfirmUndoMerge:
+			return m.updateConfirmUndoMerge(msg)
+		case viewTemplates:
+			return m.updateTemplates(msg)
+		case viewAnswers:
+			return m.updateAnswers(msg)
+		case viewAttachments:
+			return m.updateAttachments(msg)
+		case viewImport:
+			return m.updateImport(msg)
+		case viewTaskFilter:
+			return m.updateTaskFilter(msg)
+		case viewCapacity:
+			return m.updateCapacity(msg)
+		case viewArchive:
+			return m.updateArchive(msg)
+		case viewConfirmClone:
+			return m.updateConfirmClone(msg)
+		case viewConfirmBulk:
+			return m.updateConfirmBulk(msg)
+		case viewDetail:
+			return m.updateDetail(msg)
+		case viewDiff:
+			return m.updateDiffViewer(msg)
+		case viewLog:
+			return m.updateLogView(msg)
+		case viewConfirmHookUpgrade:
+			return m.updateConfirmHookUpgrade(msg)
 		}
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// worktreeAssignedMsg is sent when a new task's worktree has been made (or failed)
+type worktreeAssignedMsg struct {
+	op         int
+	form       editorFinishedMsg
+	assignment *git.WorktreeAssignment
+	err        error
+}
+
+// createTask creates a task from a finished new-task form, assigning a worktree if requested.
+// The worktree is made in the background and the task created once it is ready. If the
+// task's branch is left over from a previous session, the branch confirmation dialog is
+// shown instead and creation resumes from there.
+func (m *Model) createTask(msg editorFinishedMsg, collision git.BranchCollision) tea.Cmd {
+	// Record an absolute directory, so the task doesn't depend on where flock was started
+	if msg.cwd == "" {
+		msg.cwd = "."
+	}
+	if absCwd, err := filepath.Abs(msg.cwd); err == nil {
+		msg.cwd = absCwd
+	}
+
+	if !msg.useWorktree || m.gitAssigner == nil {
+		m.finishCreateTask(msg, nil)
+		return nil
+	}
+	m.creatingWorktree = msg.taskName
+	op := m.startOperation(fmt.Sprintf("Creating worktree for %s", msg.taskName))
+	assigner, taskID, activeTasks := m.gitAssigner, m.tasks.NextID(), m.getTaskWorktreeInfos()
+	return func() tea.Msg {
+		assignment, err := assigner.AssignWorktreeWithCollision(taskID, msg.taskName, msg.cwd, activeTasks, collision)
+		return worktreeAssignedMsg{op: op, form: msg, assignment: assignment, err: err}
+	}
+}
+
+// finishCreateTask creates the task once its worktree, if any, is assigned
+func (m *Model) finishCreateTask(msg editorFinishedMsg, assignment *git.WorktreeAssignment) {
+	cwd := msg.cwd
+	createOpts := &task.CreateOptions{
+		UseWorktree: msg.useWorktree,
+		Template:    prompt.TemplateFileName(msg.template),
+		Agent:       msg.agent,
+		Permission:  msg.permission,
+		Project:     git.ProjectRoot(cwd),
+	}
+	if assignment != nil {
+		createOpts.WorktreePath = assignment.WorktreePath
+		createOpts.GitBranch = assignment.GitBranch
+		createOpts.RepoRoot = assignment.RepoRoot
+	}
+
+	// Create the task with the prompt file and optional worktree
+	t, err := m.tasks.CreateWithOptions(msg.taskName, msg.promptFile, cwd, createOpts)
+	if err != nil {
+		m.err = err
+		m.addMessage(fmt.Sprintf("Failed to create task: %v", err), true)
+		return
+	}
+
+	if t.GitBranch != "" {
+		m.addMessage(fmt.Sprintf("Created task: %s (branch: %s)", msg.taskName, t.GitBranch), false)
+	} else {
+		m.addMessage(fmt.Sprintf("Created task: %s", msg.taskName), false)
+	}
+	m.selectTask(t.ID)
+	m.snapshotPrompt(t, prompt.SnapshotCreated)
+	m.rememberRepoDefaults(t, msg)
+
+	// Auto-start if enabled
+	if m.config.AutoStartTasks {
+		if err := m.launchTask(t); err != nil {
+			m.err = err
+			m.addMessage(fmt.Sprintf("Failed to auto-start: %v", err), true)
+		}
+	}
+}
+
+// launchTask opens a tab for a task running its agent and marks it WORKING
+func (m *Model) launchTask(t *task.Task) error {
+	agent, err := m.config.Agent(t.Agent)
+	if err != nil {
+		return err
+	}
+	launch := multiplexer.NewLaunch(t, agent, m.config.CaptureLogPath(t.ID))
+	launch.StatusServer = m.config.StatusServer
+	launch.StatusEvents = m.config.StatusTransport == config.StatusTransportSocket
+	launch.SetupLog = m.config.HookLogPath(t.ID, "setup")
+	m.warnOverCapacity(t)
+	slog.Info("opening tab", "task", t.ID, "command", agent.Command, "dir", t.WorkDir(), "backend", m.mux.Name())
+	if err := m.mux.NewTab(launch); err != nil {
+		slog.Error("failed to open tab", "task", t.ID, "backend", m.mux.Name(), "err", err)
+		return err
+	}
+	m.tasks.Update(t.ID, func(t *task.Task) { t.HookError = "" })
+	m.tasks.UpdateStatus(t.ID, task.StatusWorking)
+	m.snapshotPrompt(t, prompt.SnapshotLaunch)
+	return nil
+}
+
+// selectedAgent returns the agent chosen in the new task form
+func (m Model) selectedAgent() string {
+	names := m.config.AgentNames()
+	return names[m.agentIndex%len(names)]
+}
+
+// agentSupportsPermission reports whether the agent chosen in the new task form has flags for a permission mode
+func (m Model) agentSupportsPermission(mode task.PermissionMode) bool {
+	agent, err := m.config.Agent(m.selectedAgent())
+	return err == nil && agent.SupportsPermission(string(mode))
+}
+
+// nextPermission returns the next permission mode the selected agent supports, wrapping to the default
+func (m Model) nextPermission() task.PermissionMode {
+	modes := task.PermissionModes
+	current := 0
+	for i, mode := range modes {
+		if mode == m.permission {
+			current = i
+		}
+	}
+	for i := 1; i < len(modes); i++ {
+		if mode := modes[(current+i)%len(modes)]; m.agentSupportsPermission(mode) {
+			return mode
+		}
+	}
+	return task.PermissionDefault
+}
+
+// updateConfirmBranch handles the leftover-branch confirmation input
+func (m Model) updateConfirmBranch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pending := m.pendingTask
+	switch msg.String() {
+	case "r", "R":
+		// Reuse the existing branch (keeps its commits)
+		m.pendingTask, m.branchConflict = nil, nil
+		m.mode = viewDashboard
+		return m, m.createTask(*pending, git.BranchCollisionReuse)
+
+	case "u", "U", "enter":
+		// Create a fresh branch with a unique suffix
+		m.pendingTask, m.branchConflict = nil, nil
+		m.mode = viewDashboard
+		return m, m.createTask(*pending, git.BranchCollisionSuffix)
+
+	case "esc":
+		// Cancel task creation and discard its prompt file
+		m.pendingTask, m.branchConflict = nil, nil
+		m.mode = viewDashboard
+		if err := os.Remove(pending.promptFile); err != nil && !os.IsNotExist(err) {
+			m.addMessage(fmt.Sprintf("Failed to remove prompt file: %v", err), true)
+		}
+		m.addMessage(fmt.Sprintf("Cancelled task: %s", pending.taskName), false)
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
 // updateDashboard handles dashboard view input
 func (m Model) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	tasks := m.tasks.List()
+	tasks := m.visibleTasks()
+
+	if m.config.BoardView {
+		// Move between the board's cards; every other key works as in the table
+		switch msg.String() {
+		case "j", "down":
+			m.moveBoardSelection(0, 1)
+			return m, m.reloadOutput()
+		case "k", "up":
+			m.moveBoardSelection(0, -1)
+			return m, m.reloadOutput()
+		case "right", "tab":
+			m.moveBoardSelection(1, 0)
+			return m, m.reloadOutput()
+		case "left", "shift+tab":
+			m.moveBoardSelection(-1, 0)
+			return m, m.reloadOutput()
+		case "shift+right", ">":
+			m.moveBoardTask(1)
+			return m, m.reloadOutput()
+		case "shift+left", "<":
+			m.moveBoardTask(-1)
+			return m, m.reloadOutput()
+		}
+	}
 
 	switch msg.String() {
 	case "q", "ctrl+c":
 		return m, tea.Quit
 
+	case "B":
+		// Switch between the task table and the board of status columns
+		m.toggleBoard()
+
 	case "j", "down":
 		if m.selected < len(tasks)-1 {
 			m.selected++
+			return m, m.reloadOutput()
 		}
 
 	case "k", "up":
 		if m.selected > 0 {
 			m.selected--
+			return m, m.reloadOutput()
+		}
+
+	case "o":
+		// Swap the prompt panel for the agent's captured output
+		return m, m.toggleOutput()
+
+	case "w":
+		// Swap the prompt panel for the agent's working notes
+		m.toggleNotes()
+
+	case " ":
+		// Select the task for a bulk action and move on to the next one
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			m.toggleMark(tasks[m.selected])
+			if m.selected < len(tasks)-1 {
+				m.selected++
+				return m, m.reloadOutput()
+			}
+		}
+
+	case "esc":
+		// Clear the bulk selection, then the task filter
+		if len(m.marked) > 0 {
+			m.marked = nil
+		} else if m.taskFilter != "" {
+			m.setTaskFilter("")
+			return m, m.reloadOutput()
+		}
+
+	case "f":
+		// Narrow the task list by name, branch or directory
+		return m, m.openTaskFilter()
+
+	case "O":
+		// Sort the task list by creation, status, age or name
+		m.cycleSortOrder()
+
+	case "C":
+		// How many agents this machine and API tier can run at once
+		m.openCapacity()
+
+	case "ctrl+u", "pgup":
+		if m.showOutput {
+			m.scrollOutput(10)
+		}
+
+	case "ctrl+d", "pgdown":
+		if m.showOutput {
+			m.scrollOutput(-10)
 		}
 
 	case "n":
@@ -422,87 +1432,240 @@ func (m Model) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.nameInput.Focus()
 		m.focusIndex = 0
 		m.useWorktree = m.config.UseWorktree // Initialize from config default
+		m.agentIndex = 0                     // Default agent
+		m.permission = task.PermissionDefault
+		m.template = prompt.DefaultTemplateName
+		m.applyRepoDefaults(".", true)
 		return m, textinput.Blink
 
-	case "e":
-		// Edit selected task (only if PENDING)
+	case "e":
+		// Edit selected task (only if PENDING)
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			t := tasks[m.selected]
+			if t.Status == task.StatusPending {
+				m.mode = viewEditTask
+				m.editingTaskID = t.ID
+				m.nameInput.SetValue(t.Name)
+				m.cwdInput.SetValue(t.Cwd)
+				m.nameInput.Focus()
+				m.focusIndex = 0
+				return m, textinput.Blink
+			}
+		}
+
+	case "s":
+		// Start the marked tasks, or the selected one
+		if len(m.markedTasks()) > 0 {
+			m.startMarked()
+		} else if len(tasks) > 0 && m.selected < len(tasks) {
+			t := tasks[m.selected]
+			if t.Status == task.StatusPending {
+				if err := m.launchTask(t); err != nil {
+					m.err = err
+				}
+			}
+		}
+
+	case "enter":
+		// Jump to task tab
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			t := tasks[m.selected]
+			if t.HasTab() {
+				if err := m.mux.GoToTab(t.TabName); err != nil {
+					m.err = err
+				}
+			}
+		}
+
+	case "]", "[":
+		// Cycle through agent tabs in task order, skipping unrelated tabs
+		step := 1
+		if msg.String() == "[" {
+			step = -1
+		}
+		fromID := ""
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			fromID = tasks[m.selected].ID
+		}
+		if t, ok := m.tasks.CycleTabs(fromID, step); ok {
+			for i, candidate := range tasks {
+				if candidate.ID == t.ID {
+					m.selected = i
+					break
+				}
+			}
+			if err := m.mux.GoToTab(t.TabName); err != nil {
+				m.err = err
+			}
+		}
+
+	case "d":
+		// Delete task (with or without confirmation based on settings)
+		if len(m.markedTasks()) > 0 {
+			m.openBulkConfirm(bulkDelete)
+		} else if len(tasks) > 0 && m.selected < len(tasks) {
+			t := tasks[m.selected]
+			if m.config.ConfirmBeforeDelete {
+				m.deletingTaskID = t.ID
+				m.mode = viewConfirmDelete
+			} else {
+				// Delete immediately without confirmation
+				m.deleteTask(t.ID)
+			}
+		}
+
+	case "m":
+		// Merge task branch into main (only for tasks with worktrees)
+		if len(m.markedTasks()) > 0 {
+			m.openBulkConfirm(bulkMerge)
+		} else if len(tasks) > 0 && m.selected < len(tasks) {
+			return m, m.openMergeConfirm(tasks[m.selected])
+		}
+
+	case "c":
+		// Review the changes the task's branch would merge
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			return m, m.openDiffViewer(tasks[m.selected])
+		}
+
+	case "R":
+		// Push the task branch and open a pull request for it
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			cmd := m.openPullRequest(tasks[m.selected])
+			return m, cmd
+		}
+
+	case "r":
+		// Retry a task from scratch with a copy of its prompt
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			m.openClone(tasks[m.selected])
+		}
+
+	case "p":
+		// Interrupt the selected task's agent, or resume it
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			m.togglePause(tasks[m.selected])
+		}
+
+	case "S":
+		// Open settings popup
+		m.mode = viewSettings
+		m.settingsSelected = 0
+
+	case "W":
+		// Open worktrees management view
+		m.mode = viewWorktrees
+		m.worktreeConfirm = ""
+		m.worktreesLoading = true
+		return m, m.loadWorktrees()
+
+	case "i":
+		// Show everything about the task in a full-screen view
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			return m, m.openDetail(tasks[m.selected])
+		}
+
+	case "v":
+		// Show prompt versions and what changed since launch
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			m.openPromptHistory(tasks[m.selected])
+		}
+
+	case "a":
+		// Move a finished task to the archive
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			return m, m.archiveTask(tasks[m.selected])
+		}
+
+	case "h":
+		// Browse, search and re-run archived tasks
+		return m, m.openArchive()
+
+	case "P":
+		// Show only the current project's tasks, or all of them
+		m.toggleProjectFilter()
+
+	case "L":
+		// Filter the messages panel by level
+		m.cycleMessageLevel()
+
+	case "l":
+		// Show flock's own log, e.g. to find out why a tab didn't open
+		m.openLogView()
+
+	case "I":
+		// Create many tasks from a YAML/JSON file
+		return m, m.openImport()
+
+	case "/":
+		// Search across all prompts
+		return m, m.openSearch()
+
+	case "A":
+		// Attach files and links to the task's prompt
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			m.openAttachments(tasks[m.selected])
+		}
+
+	case "D":
+		// Set the tasks a pending task waits for
 		if len(tasks) > 0 && m.selected < len(tasks) {
 			t := tasks[m.selected]
 			if t.Status == task.StatusPending {
-				m.mode = viewEditTask
-				m.editingTaskID = t.ID
-				m.nameInput.SetValue(t.Name)
-				m.cwdInput.SetValue(t.Cwd)
-				m.nameInput.Focus()
-				m.focusIndex = 0
-				return m, textinput.Blink
+				return m, m.openDependencies(t)
 			}
+			m.addMessage("Dependencies can only be set on pending tasks", true)
 		}
 
-	case "s":
-		// Start selected task
+	case "y":
+		// Reply to waiting agents with a canned answer
+		var selected *task.Task
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			selected = tasks[m.selected]
+		}
+		m.openAnswers(selected)
+
+	case "N":
+		// Let flock answer for the agent when it waits too long
+		if len(tasks) > 0 && m.selected < len(tasks) {
+			m.toggleAutoNudge(tasks[m.selected])
+		}
+
+	case "t":
+		// Start a pending task later, or on a recurring schedule
 		if len(tasks) > 0 && m.selected < len(tasks) {
 			t := tasks[m.selected]
 			if t.Status == task.StatusPending {
-				cwd := t.EffectiveCwd()
-				if cwd == "" {
-					cwd = "."
-				}
-				// Use PromptFile if available, otherwise fall back to legacy Prompt
-				promptOrFile := t.GetPromptOrFile()
-				isFile := t.PromptFile != ""
-				if err := m.zellij.NewTab(t.ID, t.Name, t.TabName, promptOrFile, cwd, isFile); err != nil {
-					m.err = err
-				} else {
-					m.tasks.UpdateStatus(t.ID, task.StatusWorking)
-				}
+				return m, m.openSchedule(t)
 			}
+			m.addMessage("Only pending tasks can be scheduled; press r to re-run this one", true)
 		}
 
-	case "enter":
-		// Jump to task tab
+	case "T":
+		// Set the commands run before the agent starts and once the task is DONE
 		if len(tasks) > 0 && m.selected < len(tasks) {
-			t := tasks[m.selected]
-			if t.Status != task.StatusPending && t.TabName != "" {
-				if err := m.zellij.GoToTab(t.TabName); err != nil {
-					m.err = err
-				}
-			}
+			return m, m.openHooks(tasks[m.selected])
 		}
 
-	case "d":
-		// Delete task (with or without confirmation based on settings)
+	case "M":
+		// Create and edit the prompt templates of the project flock runs in
+		return m, m.openTemplates(".", false)
+
+	case "H":
+		// Have another task pick up where this one leaves off, e.g. a reviewer
 		if len(tasks) > 0 && m.selected < len(tasks) {
-			t := tasks[m.selected]
-			if m.config.ConfirmBeforeDelete {
-				m.deletingTaskID = t.ID
-				m.mode = viewConfirmDelete
-			} else {
-				// Delete immediately without confirmation
-				m.deleteTask(t.ID)
-			}
+			return m, m.openHandoff(tasks[m.selected])
 		}
 
-	case "m":
-		// Merge task branch into main (only for tasks with worktrees)
+	case "V":
+		// Start a second agent reviewing the task's changes in its worktree
 		if len(tasks) > 0 && m.selected < len(tasks) {
-			t := tasks[m.selected]
-			if t.GitBranch != "" && t.RepoRoot != "" {
-				m.mergingTaskID = t.ID
-				// Get diff info for display
-				if diffInfo, err := git.GetBranchDiff(t.RepoRoot, t.GitBranch); err == nil {
-					m.mergeDiffInfo = diffInfo
-				} else {
-					m.mergeDiffInfo = "Unable to get diff info"
-				}
-				m.mode = viewConfirmMerge
-			}
+			m.requestReview(tasks[m.selected])
 		}
 
-	case "S":
-		// Open settings popup
-		m.mode = viewSettings
-		m.settingsSelected = 0
+	case "U":
+		// Take back the most recent merge flock made
+		return m, m.openUndoMerge()
 	}
 
 	return m, nil
@@ -526,6 +1689,23 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.useWorktree = !m.useWorktree
 		return m, nil
 
+	case "ctrl+a":
+		// Cycle through available agents
+		m.agentIndex = (m.agentIndex + 1) % len(m.config.AgentNames())
+		if !m.agentSupportsPermission(m.permission) {
+			m.permission = task.PermissionDefault
+		}
+		return m, nil
+
+	case "ctrl+p":
+		// Cycle through the permission modes the agent supports
+		m.permission = m.nextPermission()
+		return m, nil
+
+	case "ctrl+t":
+		// Choose among the prompt templates of the form's working directory
+		return m, m.openTemplates(m.cwdInput.Value(), true)
+
 	case "tab", "shift+tab", "down", "up":
 		// Cycle focus between name, cwd, and goal (3 fields)
 		if msg.String() == "shift+tab" || msg.String() == "up" {
@@ -540,6 +1720,12 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.cwdInput.Focused() {
+			// A typed directory may belong to another repo with its own defaults
+			if cwd := strings.TrimSpace(m.cwdInput.Value()); cwd != "" {
+				m.applyRepoDefaults(cwd, false)
+			}
+		}
 		m.nameInput.Blur()
 		m.cwdInput.Blur()
 		m.goalInput.Blur()
@@ -565,8 +1751,18 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		cwd := strings.TrimSpace(m.cwdInput.Value())
 		goal := strings.TrimSpace(m.goalInput.Value())
 		useWorktree := m.useWorktree
+		agent := m.selectedAgent()
+		permission := m.permission
+		template := m.template
 
 		if name != "" {
+			// The task ID is predicted from the next free one, which the task whose worktree
+			// is still being made is about to take
+			if m.creatingWorktree != "" {
+				m.addMessage(fmt.Sprintf("Wait for %s's worktree before adding another task", m.creatingWorktree), true)
+				return m, nil
+			}
+
 			// Reset inputs now
 			m.nameInput.Reset()
 			m.cwdInput.Reset()
@@ -579,7 +1775,7 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 
 			// Create prompt file from template with goal
-			promptFile, err := m.promptMgr.CreatePromptFileWithGoal(taskID, name, cwd, goal)
+			promptFile, err := m.promptMgr.CreatePromptFileFromTemplate(template, m.promptMgr.TaskVars(taskID, name, cwd, goal, useWorktree && m.gitAssigner != nil))
 			if err != nil {
 				m.err = err
 				m.addMessage(fmt.Sprintf("Failed to create prompt file: %v", err), true)
@@ -588,7 +1784,7 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 
 			// Open editor - this suspends the TUI
-			return m, m.openEditor(name, promptFile, cwd, useWorktree)
+			return m, m.openEditor(editorFinishedMsg{taskName: name, promptFile: promptFile, cwd: cwd, useWorktree: useWorktree, agent: agent, permission: permission, template: template})
 		}
 		return m, nil
 
@@ -598,8 +1794,18 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		cwd := strings.TrimSpace(m.cwdInput.Value())
 		goal := strings.TrimSpace(m.goalInput.Value())
 		useWorktree := m.useWorktree
+		agent := m.selectedAgent()
+		permission := m.permission
+		template := m.template
 
 		if name != "" {
+			// The task ID is predicted from the next free one, which the task whose worktree
+			// is still being made is about to take
+			if m.creatingWorktree != "" {
+				m.addMessage(fmt.Sprintf("Wait for %s's worktree before adding another task", m.creatingWorktree), true)
+				return m, nil
+			}
+
 			// Reset inputs now
 			m.nameInput.Reset()
 			m.cwdInput.Reset()
@@ -612,7 +1818,7 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 
 			// Create prompt file from template with goal
-			promptFile, err := m.promptMgr.CreatePromptFileWithGoal(taskID, name, cwd, goal)
+			promptFile, err := m.promptMgr.CreatePromptFileFromTemplate(template, m.promptMgr.TaskVars(taskID, name, cwd, goal, useWorktree && m.gitAssigner != nil))
 			if err != nil {
 				m.err = err
 				m.addMessage(fmt.Sprintf("Failed to create prompt file: %v", err), true)
@@ -622,7 +1828,7 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 			if goal == "" {
 				// No goal provided - open editor
-				return m, m.openEditor(name, promptFile, cwd, useWorktree)
+				return m, m.openEditor(editorFinishedMsg{taskName: name, promptFile: promptFile, cwd: cwd, useWorktree: useWorktree, agent: agent, permission: permission, template: template})
 			}
 
 			// Goal provided - create task directly without opening editor
@@ -632,6 +1838,9 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					promptFile:  promptFile,
 					cwd:         cwd,
 					useWorktree: useWorktree,
+					agent:       agent,
+					permission:  permission,
+					template:    template,
 					err:         nil,
 				}
 			}
@@ -653,44 +1862,26 @@ func (m Model) updateNewTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// openEditor returns a command that opens the editor and sends editorFinishedMsg when done
-func (m Model) openEditor(taskName, promptFile, cwd string, useWorktree bool) tea.Cmd {
+// openEditor returns a command that opens the editor on the new task's prompt file and
+// sends the given editorFinishedMsg (with any editor error) when done
+func (m Model) openEditor(done editorFinishedMsg) tea.Cmd {
 	editor := getEditor()
 
 	// For GUI editors, start the process without blocking and return immediately
 	if isGUIEditor(editor) {
 		return func() tea.Msg {
-			c := exec.Command(editor, promptFile)
-			if err := c.Start(); err != nil {
-				return editorFinishedMsg{
-					taskName:    taskName,
-					promptFile:  promptFile,
-					cwd:         cwd,
-					useWorktree: useWorktree,
-					err:         err,
-				}
-			}
+			c := m.commands.Interactive(runner.Command(editor, done.promptFile))
 			// Don't wait for GUI editor to close - return success immediately
-			return editorFinishedMsg{
-				taskName:    taskName,
-				promptFile:  promptFile,
-				cwd:         cwd,
-				useWorktree: useWorktree,
-				err:         nil,
-			}
+			done.err = c.Start()
+			return done
 		}
 	}
 
 	// For terminal editors, block until the editor closes
-	c := exec.Command(editor, promptFile)
+	c := m.commands.Interactive(runner.Command(editor, done.promptFile))
 	return tea.ExecProcess(c, func(err error) tea.Msg {
-		return editorFinishedMsg{
-			taskName:    taskName,
-			promptFile:  promptFile,
-			cwd:         cwd,
-			useWorktree: useWorktree,
-			err:         err,
-		}
+		done.err = err
+		return done
 	})
 }
 
@@ -810,7 +2001,7 @@ func (m Model) updateEditTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.editingTaskID = ""
 
 			// Open editor for the prompt file
-			return m, m.openEditorForEdit(t.PromptFile)
+			return m, m.openEditorForEdit(t.ID, t.PromptFile)
 		}
 		return m, nil
 	}
@@ -828,25 +2019,25 @@ func (m Model) updateEditTask(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 // openEditorForEdit opens the editor for an existing prompt file
-func (m Model) openEditorForEdit(promptFile string) tea.Cmd {
+func (m Model) openEditorForEdit(taskID, promptFile string) tea.Cmd {
 	editor := getEditor()
 
 	// For GUI editors, start the process without blocking and return immediately
 	if isGUIEditor(editor) {
 		return func() tea.Msg {
-			c := exec.Command(editor, promptFile)
+			c := m.commands.Interactive(runner.Command(editor, promptFile))
 			if err := c.Start(); err != nil {
-				return editFinishedMsg{err: err}
+				return editFinishedMsg{taskID: taskID, err: err}
 			}
 			// Don't wait for GUI editor to close
-			return editFinishedMsg{err: nil}
+			return editFinishedMsg{taskID: taskID, err: nil}
 		}
 	}
 
 	// For terminal editors, block until the editor closes
-	c := exec.Command(editor, promptFile)
+	c := m.commands.Interactive(runner.Command(editor, promptFile))
 	return tea.ExecProcess(c, func(err error) tea.Msg {
-		return editFinishedMsg{err: err}
+		return editFinishedMsg{taskID: taskID, err: err}
 	})
 }
 
@@ -881,13 +2072,13 @@ func (m Model) openFzfDirSelector() tea.Cmd {
 	tmpFile.Close()
 
 	// Pipe to fzf and write output to temp file
-	c := exec.Command("bash", "-c", listCmd+" | fzf --prompt='Select directory: ' > "+tmpPath)
+	c := m.commands.Interactive(runner.Command("bash", "-c", listCmd+" | fzf --prompt='Select directory: ' > "+tmpPath))
 	return tea.ExecProcess(c, func(err error) tea.Msg {
 		defer os.Remove(tmpPath)
 
 		if err != nil {
 			// fzf returns exit code 130 when cancelled (Ctrl+C or Esc)
-			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 130 {
+			if runner.ExitCode(err) == 130 {
 				return fzfFinishedMsg{dir: "", err: nil}
 			}
 			return fzfFinishedMsg{dir: "", err: err}
@@ -909,7 +2100,7 @@ func (m Model) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "y", "Y", "enter":
 		// Confirm deletion - check if we need to ask about worktree
-		if t, ok := m.tasks.Get(m.deletingTaskID); ok && t.WorktreePath != "" {
+		if t, ok := m.tasks.Get(m.deletingTaskID); ok && t.WorktreePath != "" && !m.tasks.WorktreeShared(t.ID) {
 			if m.config.Worktrees.Cleanup == config.WorktreeCleanupAsk {
 				// Show worktree deletion confirmation
 				m.mode = viewConfirmWorktreeDelete
@@ -964,28 +2155,64 @@ func (m Model) updateConfirmWorktreeDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 
 // updateConfirmMerge handles merge confirmation input
 func (m Model) updateConfirmMerge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// While a merge runs, the dialog can only be closed; the result is reported in the
+	// status panel either way
+	if m.mergeRunning && msg.String() != "esc" && msg.String() != "ctrl+c" {
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "y", "Y", "enter":
-		// Perform the merge
+		if m.mergeLoading {
+			m.addMessage("Still checking the branch; merge once the conflict check is done", true)
+			return m, nil
+		}
+		if m.mergeConflicts() {
+			m.addMessage("The merge would conflict; press c to start a task resolving it", true)
+			return m, nil
+		}
+		if reason := m.mergeGateBlocks(); reason != "" {
+			m.addMessage(reason, true)
+			return m, nil
+		}
+		if m.undoRunning {
+			m.addMessage("An undo is still running; merge once it is done", true)
+			return m, nil
+		}
+		// Merge in the background; the dialog stays open to show conflicts the dry run missed
 		if t, ok := m.tasks.Get(m.mergingTaskID); ok && t.GitBranch != "" && t.RepoRoot != "" {
-			result, err := git.MergeBranch(t.RepoRoot, t.GitBranch)
-			if err != nil {
-				m.addMessage(fmt.Sprintf("Merge error: %v", err), true)
-			} else if result.Success {
-				m.addMessage(result.Message, false)
-			} else {
-				m.addMessage(result.Message, true)
-			}
+			m.mergeRunning = true
+			op := m.startOperation(fmt.Sprintf("Merging %s", t.GitBranch))
+			return m, m.mergeCmd(t, m.mergeStrategy, op)
+		}
+		m.closeMergeDialog()
+
+	case "r":
+		// Cycle between a merge commit, a squash and rebasing onto the default branch
+		m.mergeStrategy = m.mergeStrategy.Next()
+
+	case "d":
+		// Review the full diff first; [m] in the viewer comes back here
+		if t, ok := m.tasks.Get(m.mergingTaskID); ok {
+			m.closeMergeDialog()
+			return m, m.openDiffViewer(t)
 		}
-		m.mergingTaskID = ""
-		m.mergeDiffInfo = ""
-		m.mode = viewDashboard
+
+	case "c":
+		// Hand the conflicts to a new agent task working in the branch's worktree
+		if !m.mergeConflicts() {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		if t, ok := m.tasks.Get(m.mergingTaskID); ok {
+			cmd = m.resolveConflicts(t)
+		}
+		m.closeMergeDialog()
+		return m, cmd
 
 	case "n", "N", "esc":
 		// Cancel merge
-		m.mergingTaskID = ""
-		m.mergeDiffInfo = ""
-		m.mode = viewDashboard
+		m.closeMergeDialog()
 
 	case "ctrl+c":
 		return m, tea.Quit
@@ -994,9 +2221,84 @@ func (m Model) updateConfirmMerge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// openMergeConfirm shows the merge dialog for a task with a branch, starting the
+// merge check if one is configured
+func (m *Model) openMergeConfirm(t *task.Task) tea.Cmd {
+	if t.GitBranch == "" || t.RepoRoot == "" {
+		return nil
+	}
+	m.mergingTaskID = t.ID
+	m.mergeDiffInfo, m.mergeCheck = "", nil
+	m.mergeLoading = true
+	if strategy, err := git.ParseMergeStrategy(m.config.Worktrees.MergeStrategy); err == nil {
+		m.mergeStrategy = strategy
+	} else {
+		m.mergeStrategy = git.StrategyMerge
+	}
+	m.mode = viewConfirmMerge
+	return tea.Batch(loadMergeInfo(t), m.startMergeGate(t))
+}
+
+// mergeInfoMsg carries the merge dialog's diff summary and dry-run result
+type mergeInfoMsg struct {
+	taskID   string
+	diffInfo string
+	check    *git.MergeCheck // nil if the check failed
+}
+
+// loadMergeInfo reads the diff summary and does the dry-run merge in the background, so
+// conflicts show up before anything is touched without holding up the dialog
+func loadMergeInfo(t *task.Task) tea.Cmd {
+	id, repoRoot, branch := t.ID, t.RepoRoot, t.GitBranch
+	return func() tea.Msg {
+		diffInfo, err := git.GetBranchDiff(repoRoot, branch)
+		if err != nil {
+			diffInfo = "Unable to get diff info"
+		}
+		check, _ := git.CheckMerge(repoRoot, branch)
+		return mergeInfoMsg{taskID: id, diffInfo: diffInfo, check: check}
+	}
+}
+
+// mergeDoneMsg is sent when a merge started from the merge dialog finishes
+type mergeDoneMsg struct {
+	op      int
+	taskID  string
+	result  *git.MergeResult
+	err     error
+	notices []notice
+}
+
+// mergeCmd merges a task's branch in the background
+func (m Model) mergeCmd(t *task.Task, strategy git.MergeStrategy, op int) tea.Cmd {
+	tasks, worktrees, snapshot := m.tasks, m.config.Worktrees, *t
+	return func() tea.Msg {
+		result, notices, err := mergeTask(tasks, worktrees, &snapshot, strategy)
+		return mergeDoneMsg{op: op, taskID: snapshot.ID, result: result, err: err, notices: notices}
+	}
+}
+
+// mergeTask merges a task's branch into the default branch and, on success, records
+// the merge so later reverts/fixups can be traced back to it. It runs off the UI
+// goroutine, so what it has to say comes back as notices.
+func mergeTask(tasks *task.Manager, worktrees config.WorktreeConfig, t *task.Task, strategy git.MergeStrategy) (*git.MergeResult, []notice, error) {
+	result, err := chain.Merge(t, strategy, worktrees)
+	if err != nil || !result.Success {
+		return result, nil, err
+	}
+	notices := []notice{{text: result.Message}}
+	note, err := chain.RecordMerge(tasks, t, result, worktrees.ChangelogFile)
+	if err != nil {
+		notices = append(notices, notice{text: fmt.Sprintf("Failed to record merge: %v", err), isErr: true})
+	} else if note != "" {
+		notices = append(notices, notice{text: note, isErr: true})
+	}
+	return result, notices, nil
+}
+
 // updateSettings handles settings popup input
 func (m Model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	settingsCount := 5
+	settingsCount := 9
 
 	switch msg.String() {
 	case "ctrl+c":
@@ -1039,6 +2341,29 @@ func (m Model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			default:
 				m.config.Worktrees.Cleanup = config.WorktreeCleanupAsk
 			}
+		case 5:
+			// Cycle through auto-close grace periods: off -> 5 -> 15 -> 60 -> off
+			next := 0
+			for i, minutes := range autoCloseOptions {
+				if minutes == m.config.Tabs.AutoCloseDoneMinutes {
+					next = (i + 1) % len(autoCloseOptions)
+					break
+				}
+			}
+			m.config.Tabs.AutoCloseDoneMinutes = autoCloseOptions[next]
+		case 6:
+			// Cycle through spare worktree counts: 1 -> 2 -> 3 -> off -> 1
+			m.config.Worktrees.SpareCount = (m.config.Worktrees.SpareCount + 1) % (maxSpareOption + 1)
+			if m.gitAssigner != nil {
+				m.gitAssigner.SetSpareCount(m.config.Worktrees.SpareCount)
+				m.trimAllSpareWorktrees()
+			}
+		case 7:
+			m.config.Telemetry.Enabled = !m.config.Telemetry.Enabled
+		case 8:
+			// An action rather than a setting: nothing to save
+			m.mode = viewDashboard
+			return m, m.verifyHooks()
 		}
 		if err := m.config.Save(); err != nil {
 			m.addMessage(fmt.Sprintf("Failed to save settings: %v", err), true)
@@ -1048,6 +2373,12 @@ func (m Model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// autoCloseOptions are the selectable grace periods (in minutes) for closing DONE tabs
+var autoCloseOptions = []int{0, 5, 15, 60}
+
+// maxSpareOption is the largest spare worktree count selectable in settings
+const maxSpareOption = 3
+
 // deleteTask handles the actual deletion of a task (legacy wrapper)
 func (m *Model) deleteTask(taskID string) {
 	// For non-confirmation deletes, check cleanup setting
@@ -1062,33 +2393,60 @@ func (m *Model) deleteTask(taskID string) {
 // deleteTaskWithWorktreeOption handles deletion with explicit worktree cleanup option
 func (m *Model) deleteTaskWithWorktreeOption(taskID string, deleteWorktree bool) {
 	if t, ok := m.tasks.Get(taskID); ok {
-		// Close the zellij tab if task was started
-		if t.Status != task.StatusPending && t.TabName != "" {
-			if err := m.zellij.CloseTab(t.TabName); err != nil {
+		// Close the agent tab if task was started
+		if t.HasTab() {
+			if err := m.mux.CloseTab(t.TabName); err != nil {
 				m.err = err
 			}
-			m.zellij.GoToController()
+			m.mux.GoToController()
 		}
 		// Delete the status file to prevent stale updates
-		m.zellij.DeleteStatusFile(taskID)
-		// Delete the prompt file
+		m.mux.DeleteStatusFile(taskID)
+		// Delete the prompt file and its history
 		m.promptMgr.DeletePromptFile(taskID)
-		// Release the worktree if assigned and deletion requested
-		if deleteWorktree && m.gitAssigner != nil && t.WorktreePath != "" {
+		m.promptMgr.DeleteHistory(taskID)
+		// Release the worktree if assigned and deletion requested, unless a reviewer or
+		// chained task still works in it
+		shared := m.tasks.WorktreeShared(taskID)
+		if deleteWorktree && m.gitAssigner != nil && t.WorktreePath != "" && !shared {
 			if err := m.gitAssigner.ReleaseWorktree(t.WorktreePath, t.RepoRoot); err != nil {
-				m.addMessage(fmt.Sprintf("Worktree cleanup warning: %v", err), true)
+				m.addWarning(fmt.Sprintf("Worktree cleanup warning: %v", err))
 			} else {
 				m.addMessage(fmt.Sprintf("Deleted worktree: %s", t.GitBranch), false)
 			}
+		} else if t.WorktreePath != "" && shared {
+			m.addMessage(fmt.Sprintf("Kept worktree: %s (another task works in it)", t.WorktreePath), false)
 		} else if t.WorktreePath != "" && !deleteWorktree {
 			m.addMessage(fmt.Sprintf("Kept worktree: %s", t.WorktreePath), false)
 		}
 		if err := m.tasks.Delete(taskID); err != nil {
 			m.err = err
 		}
-		if m.selected >= len(m.tasks.List()) && m.selected > 0 {
+		if m.selected >= len(m.visibleTasks()) && m.selected > 0 {
 			m.selected--
 		}
+		// Fewer tasks may leave more spare worktrees than needed
+		m.trimSpareWorktrees(t.RepoRoot)
+	}
+}
+
+// trimSpareWorktrees removes surplus spare worktrees for a repo
+func (m *Model) trimSpareWorktrees(repoRoot string) {
+	if m.gitAssigner == nil || repoRoot == "" {
+		return
+	}
+	removed, err := m.gitAssigner.TrimSpares(repoRoot, m.getTaskWorktreeInfos())
+	if err != nil {
+		m.addWarning(fmt.Sprintf("Spare worktree cleanup warning: %v", err))
+	} else if removed > 0 {
+		m.addMessage(fmt.Sprintf("Removed %d spare worktree(s)", removed), false)
+	}
+}
+
+// trimAllSpareWorktrees trims spare worktrees in every known repo
+func (m *Model) trimAllSpareWorktrees() {
+	for _, repoRoot := range m.knownRepos() {
+		m.trimSpareWorktrees(repoRoot)
 	}
 }
 
@@ -1107,6 +2465,52 @@ func (m Model) View() string {
 		return m.viewConfirmMerge()
 	case viewSettings:
 		return m.viewSettings()
+	case viewConfirmBranch:
+		return m.viewConfirmBranch()
+	case viewConfirmOrphans:
+		return m.viewConfirmOrphans()
+	case viewWorktrees:
+		return m.viewWorktrees()
+	case viewPromptHistory:
+		return m.viewPromptHistory()
+	case viewSearch:
+		return m.viewSearch()
+	case viewArchive:
+		return m.viewArchive()
+	case viewConfirmClone:
+		return m.viewConfirmClone()
+	case viewConfirmBulk:
+		return m.viewConfirmBulk()
+	case viewDetail:
+		return m.viewDetail()
+	case viewDiff:
+		return m.viewDiffViewer()
+	case viewLog:
+		return m.viewLogView()
+	case viewConfirmHookUpgrade:
+		return m.viewConfirmHookUpgrade()
+	case viewDependencies:
+		return m.viewDependencies()
+	case viewSchedule:
+		return m.viewSchedule()
+	case viewHooks:
+		return m.viewHooks()
+	case viewHandoff:
+		return m.viewHandoff()
+	case viewConfirmUndoMerge:
+		return m.viewConfirmUndoMerge()
+	case viewTemplates:
+		return m.viewTemplates()
+	case viewAnswers:
+		return m.viewAnswers()
+	case viewAttachments:
+		return m.viewAttachments()
+	case viewImport:
+		return m.viewImport()
+	case viewTaskFilter:
+		return m.viewDashboard()
+	case viewCapacity:
+		return m.viewCapacity()
 	default:
 		return m.viewDashboard()
 	}
@@ -1154,16 +2558,43 @@ func (m Model) viewDashboard() string {
 
 	// Render panels
 	// Width passed is total panel width (renderPanel handles borders internally)
+	if m.config.BoardView {
+		// The board takes the whole top row; the prompt panel would leave its columns too narrow
+		topRow := m.renderBoard(availableWidth, topRowHeight)
+		statusPanel := m.renderStatusPanel(availableWidth, statusPanelHeight)
+		helpBar := helpStyle.Render(truncate("[←/→/tab]column  [j/k]card  [B]table  [enter]jump  [s]tart  [i]nfo  [m]erge  [c]hanges  [n]ew  [f]ilter  [O]rder  [d]elete  [q]uit", availableWidth-2))
+		if m.mode == viewTaskFilter {
+			helpBar = " Filter: " + m.filterInput.View() + "  [enter]keep  [esc]clear"
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, topRow, statusPanel, helpBar)
+	}
+
 	tasksPanel := m.renderTasksPanel(leftWidth, topRowHeight)
-	promptPanel := m.renderPromptPanel(rightWidth, topRowHeight)
+	var promptPanel string
+	if m.showOutput {
+		promptPanel = m.renderOutputPanel(rightWidth, topRowHeight)
+	} else if m.showNotes {
+		promptPanel = m.renderNotesPanel(rightWidth, topRowHeight)
+	} else {
+		promptPanel = m.renderPromptPanel(rightWidth, topRowHeight)
+	}
 	statusPanel := m.renderStatusPanel(availableWidth, statusPanelHeight)
 
 	// Help bar - truncate if needed
-	helpText := "[n]ew  [e]dit  [s]tart  [m]erge  [S]ettings  [j/k]navigate  [enter]jump  [d]elete  [q]uit"
-	if len(helpText) > availableWidth-2 {
-		helpText = "[n]ew [e]dit [s]tart [m]erge [S]et [j/k]nav [enter]jump [d]el [q]uit"
+	helpText := "[n]ew  [e]dit  [s]tart  [r]e-run  [p]ause  [m]erge  [c]hanges  [R]equest PR  [i]nfo  [a]rchive  [h]istory  [W]orktrees  [v]ersions  [/]search  [f]ilter  [O]rder  [B]oard  [D]eps  [H]andoff  [V] review  [U]ndo merge  [t]imer  [T] setup  [M] templates  [N]udge  [y] answer  [A]ttach  [I]mport  [P]roject  [o]utput  [w] notes  [L]evel  [l]og  [S]ettings  [j/k]navigate  [enter]jump  [[/]]cycle  [d]elete  [q]uit"
+	if m.mode == viewTaskFilter {
+		helpText = "Filter: " + m.filterInput.View() + "  [enter]keep  [esc]clear  [↑/↓]navigate"
+	} else if marked := len(m.markedTasks()); marked > 0 {
+		helpText = fmt.Sprintf("%d selected:  [s]tart all  [d]elete all  [m]erge all in order  [y] answer all  [space]select  [esc]clear  [j/k]navigate  [q]uit", marked)
+	} else if len(helpText) > availableWidth-2 {
+		helpText = "[n]ew [e]dit [s]tart [r]erun [p]ause [m]erge [c]hg [R]PR [i]nfo [a]rch [h]ist [W]t [v]er [/]find [f]ilt [O]rd [B]rd [C]ap [D]eps [H]off [V]rev [U]ndo [t]mr [T]stp [M]tpl [N]dg [y]ans [A]tt [I]mp [P]rj [o]ut [w]nts [L]vl [l]og [S]et [j/k]nav [enter]jump [[/]]cycle [d]el [q]uit"
+		// A wrapped help bar would push the panels up a line
+		helpText = truncate(helpText, availableWidth-2)
 	}
 	helpBar := helpStyle.Render(helpText)
+	if m.mode == viewTaskFilter {
+		helpBar = " " + helpText
+	}
 
 	// Compose layout: top row (tasks | prompt), then status, then help
 	topRow := lipgloss.JoinHorizontal(lipgloss.Top, tasksPanel, promptPanel)
@@ -1201,12 +2632,22 @@ func (m Model) viewNewTask() string {
 		worktreeStatus = "[x]"
 	}
 	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("%s Use worktree", worktreeStatus)))
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("Agent: %s", m.selectedAgent())))
+	b.WriteString("\n")
+	permission := "default (ask before acting)"
+	if m.permission != task.PermissionDefault {
+		permission = string(m.permission)
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("Permissions: %s", permission)))
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("Template: %s", m.template)))
 	b.WriteString("\n\n")
 
 	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("Enter with prompt: create task | Enter without: open editor"))
 	b.WriteString("\n")
 
-	help := helpStyle.Render("[tab]next  [ctrl+f]fzf  [ctrl+w]worktree  [ctrl+e]editor  [enter]create  [esc]cancel")
+	help := helpStyle.Render("[tab]next  [ctrl+f]fzf  [ctrl+w]worktree  [ctrl+a]agent  [ctrl+p]permissions  [ctrl+t]template  [ctrl+e]editor  [enter]create  [esc]cancel")
 	b.WriteString(help)
 
 	return m.centerContent(modalStyle.Render(b.String()))
@@ -1290,7 +2731,7 @@ func (m Model) viewConfirmWorktreeDelete() string {
 
 	b.WriteString(fmt.Sprintf("Task '%s' has an associated worktree:\n", t.Name))
 	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("  Branch: %s\n", t.GitBranch)))
-	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("  Path: %s\n", t.WorktreePath)))
+	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("  Path: %s\n", pathfmt.Shorten(t.WorktreePath, 60))))
 	b.WriteString("\n")
 	b.WriteString("Do you want to delete the worktree and its branch?\n")
 
@@ -1317,7 +2758,24 @@ func (m Model) viewConfirmMerge() string {
 	b.WriteString(title)
 	b.WriteString("\n\n")
 
-	b.WriteString(fmt.Sprintf("Merge branch '%s' into main?\n\n", t.GitBranch))
+	defaultBranch := "main"
+	if m.mergeCheck != nil {
+		defaultBranch = m.mergeCheck.DefaultBranch
+	}
+	switch m.mergeStrategy {
+	case git.StrategyRebase:
+		b.WriteString(fmt.Sprintf("Rebase branch '%s' onto %s and fast-forward?\n", t.GitBranch, defaultBranch))
+	case git.StrategySquash:
+		b.WriteString(fmt.Sprintf("Squash branch '%s' into one commit on %s?\n", t.GitBranch, defaultBranch))
+	default:
+		b.WriteString(fmt.Sprintf("Merge branch '%s' into %s?\n", t.GitBranch, defaultBranch))
+	}
+	b.WriteString(renderStrategyChoice(m.mergeStrategy))
+	b.WriteString("\n\n")
+	if m.mergeStrategy != git.StrategyRebase {
+		b.WriteString(renderMergeMessage(chain.MergeMessage(t, m.config.Worktrees.MergeMessage)))
+		b.WriteString("\n")
+	}
 
 	// Show diff info
 	if m.mergeDiffInfo != "" {
@@ -1327,18 +2785,141 @@ func (m Model) viewConfirmMerge() string {
 		maxLines := 8
 		if len(lines) > maxLines {
 			for i := 0; i < maxLines-1; i++ {
-				b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("  " + lines[i] + "\n"))
+				b.WriteString(renderStatLine("  "+lines[i]) + "\n")
 			}
-			b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("  ... and %d more lines\n", len(lines)-maxLines+1)))
+			b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("  ... and %d more lines ([d] shows the diff)\n", len(lines)-maxLines+1)))
 		} else {
 			for _, line := range lines {
-				b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("  " + line + "\n"))
+				b.WriteString(renderStatLine("  "+line) + "\n")
+			}
+		}
+	}
+
+	// Show the dry-run result
+	b.WriteString("\n")
+	switch {
+	case m.mergeLoading:
+		b.WriteString(m.spinner.View() + lipgloss.NewStyle().Foreground(colorSecondary).Render(" Checking for conflicts...\n"))
+	case m.mergeCheck == nil:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("Conflict check unavailable (needs git 2.38+)\n"))
+	case len(m.mergeCheck.Conflicts) > 0:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorWarning).Render(fmt.Sprintf("Conflicts in %d files:\n", len(m.mergeCheck.Conflicts))))
+		for i, file := range m.mergeCheck.Conflicts {
+			if i == 6 {
+				b.WriteString(lipgloss.NewStyle().Foreground(colorWarning).Render(fmt.Sprintf("  ... and %d more\n", len(m.mergeCheck.Conflicts)-i)))
+				break
 			}
+			b.WriteString(lipgloss.NewStyle().Foreground(colorWarning).Render("  " + file + "\n"))
+		}
+	default:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSuccess).Render("No conflicts\n"))
+	}
+	if gate := m.viewMergeGate(); gate != "" {
+		b.WriteString("\n")
+		b.WriteString(gate)
+	}
+
+	b.WriteString("\n")
+	help := helpStyle.Render("[y/enter]merge  [d]iff  [r]merge/squash/rebase  [n]o  [esc]cancel")
+	if m.mergeRunning {
+		help = m.spinner.View() + helpStyle.Render(" Merging...  [esc]close, the merge carries on")
+	} else if m.mergeConflicts() {
+		help = helpStyle.Render("[c]reate resolve task  [d]iff  [r]merge/squash/rebase  [esc]cancel")
+	} else if m.mergeGate != nil && !m.mergeGate.Passed {
+		help = helpStyle.Render("[r]merge/squash/rebase  [esc]cancel")
+	}
+	b.WriteString(help)
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
+
+// renderStrategyChoice lists the merge strategies with the chosen one highlighted
+func renderStrategyChoice(current git.MergeStrategy) string {
+	muted := lipgloss.NewStyle().Foreground(colorSecondary)
+	chosen := lipgloss.NewStyle().Bold(true).Foreground(colorPrimary)
+	parts := []string{muted.Render("Strategy:")}
+	for _, s := range []git.MergeStrategy{git.StrategyMerge, git.StrategySquash, git.StrategyRebase} {
+		if s == current {
+			parts = append(parts, chosen.Render("["+string(s)+"]"))
+		} else {
+			parts = append(parts, muted.Render(" "+string(s)+" "))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// renderMergeMessage shows the start of the commit message a merge or squash will use
+func renderMergeMessage(message string) string {
+	const maxLines = 6
+	muted := lipgloss.NewStyle().Foreground(colorSecondary)
+	lines := strings.Split(message, "\n")
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines-1], "...")
+	}
+	var b strings.Builder
+	b.WriteString(muted.Render("Commit message:") + "\n")
+	for _, line := range lines {
+		b.WriteString(muted.Render("│ "+truncate(line, 60)) + "\n")
+	}
+	return b.String()
+}
+
+// viewConfirmBranch renders the leftover-branch confirmation dialog
+func (m Model) viewConfirmBranch() string {
+	var b strings.Builder
+
+	if m.pendingTask == nil || m.branchConflict == nil {
+		return m.viewDashboard()
+	}
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(colorWarning).
+		Render("Branch Already Exists")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	b.WriteString(fmt.Sprintf("Task '%s' would use branch '%s',\n", m.pendingTask.taskName, m.branchConflict.Branch))
+	b.WriteString("but it is left over from a previous session.\n")
+	if m.branchConflict.Ahead > 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(
+			fmt.Sprintf("  It has %d commit(s) not in the default branch.\n", m.branchConflict.Ahead)))
+	}
+	b.WriteString("\n")
+	b.WriteString("Reuse it (keeping its commits) or create a new branch?\n")
+
+	b.WriteString("\n")
+	help := helpStyle.Render("[r]euse branch  [u/enter]new branch  [esc]cancel task")
+	b.WriteString(help)
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
+
+// viewConfirmOrphans renders the orphaned worktree cleanup dialog
+func (m Model) viewConfirmOrphans() string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(colorWarning).
+		Render("Orphaned Worktrees")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	b.WriteString(fmt.Sprintf("Found %d flock worktree(s) not used by any task:\n", len(m.orphans)))
+	maxLines := 8
+	for i, o := range m.orphans {
+		if i == maxLines {
+			b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("  ... and %d more\n", len(m.orphans)-maxLines)))
+			break
 		}
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("  %s (%s)\n", o.DisplayName(), o.Worktree.Branch)))
 	}
+	b.WriteString("\n")
+	b.WriteString("Delete them and their branches?\n")
 
 	b.WriteString("\n")
-	help := helpStyle.Render("[y/enter]merge  [n]o  [esc]cancel")
+	help := helpStyle.Render("[y]es delete  [n/enter]keep  [esc]cancel")
 	b.WriteString(help)
 
 	return m.centerContent(modalStyle.Render(b.String()))
@@ -1416,7 +2997,45 @@ func (m Model) viewSettings() string {
 	}
 	renderMultiOption(4, "Worktree cleanup", "How to handle worktrees when deleting tasks", cleanupOptions, cleanupIdx)
 
-	help := helpStyle.Render("[j/k]navigate  [enter/space]toggle  [esc/S]close")
+	// Setting 5: Auto-close DONE tabs
+	autoCloseLabels := make([]string, len(autoCloseOptions))
+	autoCloseIdx := 0
+	for i, minutes := range autoCloseOptions {
+		if minutes == 0 {
+			autoCloseLabels[i] = "Off"
+		} else {
+			autoCloseLabels[i] = fmt.Sprintf("%dm", minutes)
+		}
+		if minutes == m.config.Tabs.AutoCloseDoneMinutes {
+			autoCloseIdx = i
+		}
+	}
+	renderMultiOption(5, "Close DONE tabs", "Close a finished task's tab after a grace period (transcript saved to ~/.flock/logs)", autoCloseLabels, autoCloseIdx)
+
+	// Setting 6: Spare worktrees
+	spareLabels := []string{"Off"}
+	for n := 1; n <= maxSpareOption; n++ {
+		spareLabels = append(spareLabels, fmt.Sprintf("%d", n))
+	}
+	spareIdx := m.config.Worktrees.SpareCount
+	if spareIdx > maxSpareOption {
+		spareIdx = maxSpareOption
+	}
+	renderMultiOption(6, "Spare worktrees", "Worktrees kept ready for new tasks; surplus spares are removed", spareLabels, spareIdx)
+
+	// Setting 7: Anonymous usage stats
+	renderSetting(7, m.config.Telemetry.Enabled, "Anonymous usage stats", "Weekly task counts, no names or paths; see `flock telemetry preview`")
+
+	// 8: Verify hooks, an action
+	verifyLabel := "Verify hooks"
+	if m.settingsSelected == 8 {
+		verifyLabel = selectedRowStyle.Render(verifyLabel)
+	}
+	b.WriteString(verifyLabel + "\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("    Run the hook script with a test prompt, and upgrade it if it is outdated"))
+	b.WriteString("\n\n")
+
+	help := helpStyle.Render("[j/k]navigate  [enter/space]toggle or run  [esc/S]close")
 	b.WriteString(help)
 
 	return m.centerContent(modalStyle.Render(b.String()))
@@ -1561,7 +3180,8 @@ func (m Model) renderPanel(title, content string, width, height int, active bool
 func (m Model) renderTasksPanel(width, height int) string {
 	var b strings.Builder
 
-	tasks := m.tasks.List()
+	tasks := m.visibleTasks()
+	now := time.Now()
 
 	// Calculate content width (subtract borders 2 + horizontal padding 4 = 6)
 	contentWidth := width - 6
@@ -1570,9 +3190,12 @@ func (m Model) renderTasksPanel(width, height int) string {
 	}
 
 	// Calculate dynamic column widths based on available content width
-	// Fixed columns: ID (4), Status (12 with spinner), Branch (12), Git (8), Age (6) = 42 fixed
+	// Fixed columns: ID (4), Status (14 with spinner and waiting reason), Branch (12), Git (8), Work (6), Age (6) = 50 fixed
 	// Variable columns: Name, Directory share remaining space
-	fixedWidth := 4 + 12 + 12 + 8 + 6 + 5 // +5 for spacing between columns
+	fixedWidth := 4 + 14 + 12 + 8 + 6 + 6 + 6 // +6 for spacing between columns
+	for _, col := range m.config.Columns {
+		fixedWidth += col.ColumnWidth() + 1
+	}
 	variableWidth := contentWidth - fixedWidth
 	if variableWidth < 20 {
 		variableWidth = 20
@@ -1583,11 +3206,20 @@ func (m Model) renderTasksPanel(width, height int) string {
 	gitWidth := 8
 
 	if len(tasks) == 0 {
-		b.WriteString("No tasks yet. Press 'n' to create one.\n")
+		if m.taskFilter != "" && m.tasks.Count() > 0 {
+			b.WriteString(fmt.Sprintf("No tasks match %q. Press Esc to clear the filter.\n", m.taskFilter))
+		} else if m.config.ProjectOnly && m.tasks.Count() > 0 {
+			b.WriteString("No tasks in this project. Press 'P' to show all projects.\n")
+		} else {
+			b.WriteString("No tasks yet. Press 'n' to create one.\n")
+		}
 	} else {
 		// Header with dynamic widths
-		headerFmt := fmt.Sprintf("%%-%ds %%-%ds %%-%ds %%-%ds %%-%ds %%-%ds %%-%ds", 4, nameWidth, 12, branchWidth, gitWidth, dirWidth, 6)
-		header := fmt.Sprintf(headerFmt, "#", "Task", "Status", "Branch", "Git", "Directory", "Age")
+		headerFmt := fmt.Sprintf("%%-%ds %%-%ds %%-%ds %%-%ds %%-%ds %%-%ds %%-%ds %%-%ds", 4, nameWidth, 14, branchWidth, gitWidth, dirWidth, 6, 6)
+		header := fmt.Sprintf(headerFmt, "#", "Task", "Status", "Branch", "Git", "Directory", "Work", "Age")
+		for _, col := range m.config.Columns {
+			header += fmt.Sprintf(" %-*s", col.ColumnWidth(), truncate(col.Name, col.ColumnWidth()))
+		}
 		b.WriteString(tableHeaderStyle.Render(header))
 		b.WriteString("\n")
 
@@ -1621,41 +3253,60 @@ func (m Model) renderTasksPanel(width, height int) string {
 		// Rows
 		for i := startIdx; i < endIdx; i++ {
 			t := tasks[i]
-			// Show spinner next to WORKING status
-			statusWidth := 12
+			// Show spinner next to WORKING status, and why a WAITING agent stopped
+			statusWidth := 14
 			var statusDisplay string
 			if t.Status == task.StatusWorking {
 				statusDisplay = m.spinner.View() + " " + StatusStyle(string(t.Status)).Render(string(t.Status))
 			} else {
 				statusDisplay = "  " + StatusStyle(string(t.Status)).Render(string(t.Status))
 			}
+			if badge := t.WaitReason.Badge(); badge != "" && t.Status == task.StatusWaiting {
+				statusDisplay += " " + StatusStyle(string(t.Status)).Render(badge)
+			}
+			if m.isReady(t) {
+				statusDisplay += " " + readyBadge
+			}
 			// Pad status to fixed width based on visual width (ANSI codes don't count)
 			statusVisualWidth := lipgloss.Width(statusDisplay)
 			if statusVisualWidth < statusWidth {
 				statusDisplay = statusDisplay + strings.Repeat(" ", statusWidth-statusVisualWidth)
 			}
 
-			// Show directory (use basename for brevity)
-			dir := t.Cwd
-			if dir == "" {
-				dir = "."
-			} else {
-				dir = filepath.Base(dir)
+			// Show the directory, shortened from the left to fit
+			dir := "~"
+			if t.Cwd != "" {
+				dir = pathfmt.Shorten(t.Cwd, dirWidth)
 			}
 
-			// Get git branch status for this task's working directory
-			// Use WorktreePath if available (for worktree-based tasks), otherwise fall back to Cwd
-			gitDir := t.Cwd
-			if t.WorktreePath != "" {
-				gitDir = t.WorktreePath
+			// Git branch status, read in the background; a new task shows its branch until then
+			branchDisplay, gitDisplay := t.GitBranch, "…"
+			if gitStatus, ok := m.branchStatus(t.ID); ok {
+				branchDisplay = gitStatus.Branch
+				gitDisplay = FormatGitStatus(gitStatus.Ahead, gitStatus.Behind, gitStatus.IsMain, gitStatus.Error != nil)
 			}
-			gitStatus := git.GetBranchStatus(gitDir)
-			branchDisplay := gitStatus.Branch
-			gitDisplay := FormatGitStatus(gitStatus.Ahead, gitStatus.Behind, gitStatus.IsMain, gitStatus.Error != nil)
 
 			// Build row with fixed-width columns using proper padding
 			idCol := fmt.Sprintf("%-4s", t.ID)
-			nameCol := fmt.Sprintf("%-*s", nameWidth, truncate(t.Name, nameWidth))
+			if m.marked[t.ID] {
+				idCol = fmt.Sprintf("%-4s", "*"+t.ID)
+			}
+			name := t.Name
+			if t.Status == task.StatusPending && len(t.DependsOn) > 0 {
+				name += fmt.Sprintf(" (after %s)", strings.Join(t.DependsOn, ","))
+			}
+			if t.Status == task.StatusPending && t.NextRun != nil {
+				name += fmt.Sprintf(" (next %s)", formatNextRun(*t.NextRun))
+			}
+			name += hookBadge(t) + m.reviewBadge(t)
+			nameCol := fmt.Sprintf("%-*s", nameWidth, truncate(name, nameWidth))
+			if badge := t.Permission.Badge(); badge != "" && nameWidth > len(badge)+6 {
+				// Show how much the agent may do unsupervised, e.g. "[plan]"
+				nameCol = truncate(name, nameWidth-len(badge)-3) + " " + PermissionStyle(t.Permission).Render("["+badge+"]")
+				if w := lipgloss.Width(nameCol); w < nameWidth {
+					nameCol += strings.Repeat(" ", nameWidth-w)
+				}
+			}
 			branchCol := fmt.Sprintf("%-*s", branchWidth, truncate(branchDisplay, branchWidth))
 			// gitDisplay contains ANSI codes, so pad based on visual width
 			gitVisualWidth := lipgloss.Width(gitDisplay)
@@ -1663,10 +3314,19 @@ func (m Model) renderTasksPanel(width, height int) string {
 				gitDisplay = gitDisplay + strings.Repeat(" ", gitWidth-gitVisualWidth)
 			}
 			gitCol := gitDisplay
-			dirCol := fmt.Sprintf("%-*s", dirWidth, truncate(dir, dirWidth))
+			dirCol := fmt.Sprintf("%-*s", dirWidth, dir)
+			// Time the agent spent working, which says more about effort than age
+			work := "-"
+			if working, _ := t.TimeSpent(now); working > 0 {
+				work = task.FormatSpent(working)
+			}
+			workCol := fmt.Sprintf("%-6s", work)
 			ageCol := fmt.Sprintf("%-6s", t.AgeString())
 
-			row := idCol + " " + nameCol + " " + statusDisplay + " " + branchCol + " " + gitCol + " " + dirCol + " " + ageCol
+			row := idCol + " " + nameCol + " " + statusDisplay + " " + branchCol + " " + gitCol + " " + dirCol + " " + workCol + " " + ageCol
+			for c, col := range m.config.Columns {
+				row += fmt.Sprintf(" %-*s", col.ColumnWidth(), truncate(m.columnValue(c, t.ID), col.ColumnWidth()))
+			}
 
 			if i == m.selected {
 				row = selectedRowStyle.Render(row)
@@ -1684,14 +3344,46 @@ func (m Model) renderTasksPanel(width, height int) string {
 	}
 
 	// Stats
-	stats := fmt.Sprintf("Tasks: %d | Active: %d | Waiting: %d",
-		m.tasks.Count(),
+	count := fmt.Sprintf("%d", m.tasks.Count())
+	if m.config.ProjectOnly {
+		count = fmt.Sprintf("%d of %d", len(tasks), m.tasks.Count())
+	}
+	stats := fmt.Sprintf("Tasks: %s | Active: %d | Waiting: %d",
+		count,
 		m.tasks.ActiveCount(),
 		m.tasks.WaitingCount(),
 	)
+	if stalled := m.tasks.StalledCount(); stalled > 0 {
+		stats += fmt.Sprintf(" | Stalled: %d", stalled)
+	}
+	var worked, waited time.Duration
+	for _, t := range tasks {
+		working, waiting := t.TimeSpent(now)
+		worked, waited = worked+working, waited+waiting
+	}
+	if worked > 0 || waited > 0 {
+		stats += fmt.Sprintf(" | Worked: %s | Waited: %s", task.FormatSpent(worked), task.FormatSpent(waited))
+	}
+	if m.updateVersion != "" {
+		stats += fmt.Sprintf(" | Update: %s", m.updateVersion)
+	}
+	if ready := m.readyCount(); ready > 0 {
+		stats += fmt.Sprintf(" | Ready to merge: %d", ready)
+	}
+	if marked := len(m.markedTasks()); marked > 0 {
+		stats += fmt.Sprintf(" | Selected: %d", marked)
+	}
 	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(stats))
 
-	return m.renderPanel("Task", b.String(), width, height, true)
+	title := "Task"
+	qualifiers := m.taskListQualifiers()
+	if m.config.ProjectOnly {
+		qualifiers = append([]string{filepath.Base(m.project)}, qualifiers...)
+	}
+	if len(qualifiers) > 0 {
+		title = fmt.Sprintf("Task (%s)", strings.Join(qualifiers, ", "))
+	}
+	return m.renderPanel(title, b.String(), width, height, true)
 }
 
 // renderStatusPanel renders the status panel
@@ -1709,10 +3401,27 @@ func (m Model) renderStatusPanel(width, height int) string {
 		availableLines = 1
 	}
 
-	if len(m.messages) == 0 && m.err == nil {
+	messageLines := availableLines
+	if m.err != nil {
+		messageLines--
+	}
+	operations := m.operationLines(contentWidth)
+	messageLines -= len(operations)
+	messages := m.messages.Recent(max(messageLines, 0), m.messageLevel)
+
+	if len(messages) == 0 && m.err == nil && len(operations) == 0 {
 		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("No recent status updates"))
 	} else {
 		lineCount := 0
+		// Git work still running in the background comes first
+		for _, line := range operations {
+			if lineCount >= availableLines {
+				break
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+			lineCount++
+		}
 		// Show error if present
 		if m.err != nil && lineCount < availableLines {
 			errText := fmt.Sprintf("Error: %v", m.err)
@@ -1724,20 +3433,24 @@ func (m Model) renderStatusPanel(width, height int) string {
 			b.WriteString("\n")
 			lineCount++
 		}
-		// Show recent messages (limit to available lines)
-		for _, msg := range m.messages {
+		// Show the newest messages that fit, oldest first, with times that count up as they age
+		now := time.Now()
+		for _, msg := range messages {
 			if lineCount >= availableLines {
 				break
 			}
-			timestamp := msg.Timestamp.Format("15:04:05")
-			msgText := fmt.Sprintf("[%s] %s", timestamp, msg.Text)
+			timestamp := timefmt.Relative(msg.Timestamp, now)
+			msgText := fmt.Sprintf("[%s] %s", timestamp, msg.Display())
 			if len(msgText) > contentWidth {
 				msgText = msgText[:contentWidth-3] + "..."
 			}
 			var line string
-			if msg.IsError {
+			switch msg.Level {
+			case msglog.LevelError:
 				line = lipgloss.NewStyle().Foreground(colorError).Render(msgText)
-			} else {
+			case msglog.LevelWarn:
+				line = lipgloss.NewStyle().Foreground(colorWarning).Render(msgText)
+			default:
 				line = lipgloss.NewStyle().Foreground(colorSecondary).Render(msgText)
 			}
 			b.WriteString(line)
@@ -1746,7 +3459,14 @@ func (m Model) renderStatusPanel(width, height int) string {
 		}
 	}
 
-	return m.renderPanel("Status", b.String(), width, height, false)
+	title := "Status"
+	switch m.messageLevel {
+	case msglog.LevelWarn:
+		title = "Status (warnings and errors)"
+	case msglog.LevelError:
+		title = "Status (errors)"
+	}
+	return m.renderPanel(title, b.String(), width, height, false)
 }
 
 // renderPromptPanel renders the prompt panel showing the selected task's .md file content
@@ -1764,7 +3484,7 @@ func (m Model) renderPromptPanel(width, height int) string {
 		availableLines = 1
 	}
 
-	tasks := m.tasks.List()
+	tasks := m.visibleTasks()
 	if len(tasks) == 0 || m.selected >= len(tasks) {
 		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("No task selected"))
 		return m.renderPanel("Prompt", b.String(), width, height, false)
@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// archiveStatMsg carries the diffstat archiveTask reads in the background
+type archiveStatMsg struct {
+	op       int
+	taskID   string
+	diffStat string
+}
+
+// archiveTask reads a DONE task's diffstat in the background; archiveStatDone then
+// moves it to the archive
+func (m *Model) archiveTask(t *task.Task) tea.Cmd {
+	if t.Status != task.StatusDone {
+		m.addMessage("Only DONE tasks can be archived", true)
+		return nil
+	}
+
+	op := m.startOperation("Archiving " + t.Name)
+	snapshot := *t
+	return func() tea.Msg {
+		msg := archiveStatMsg{op: op, taskID: snapshot.ID}
+		if revRange := taskRevRange(&snapshot); revRange != "" {
+			if stat, err := git.DiffShortStat(snapshot.RepoRoot, revRange); err == nil {
+				msg.diffStat = stat
+			}
+		}
+		return msg
+	}
+}
+
+// archiveStatDone moves a task to the archive with its prompt, branch, diffstat and
+// duration, then removes it like a delete (honoring the worktree cleanup setting)
+func (m *Model) archiveStatDone(msg archiveStatMsg) {
+	m.finishOperation(msg.op)
+	// The task may have been deleted, archived or restarted meanwhile
+	t, ok := m.tasks.Get(msg.taskID)
+	if !ok || t.Status != task.StatusDone {
+		return
+	}
+
+	entry := task.NewArchivedTask(t, m.readPrompt(t), time.Now())
+	entry.DiffStat = msg.diffStat
+	if err := m.archive.Add(entry); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to archive %s: %v", t.Name, err), true)
+		return
+	}
+	m.deleteTask(t.ID)
+	m.addMessage(fmt.Sprintf("Archived %s", t.Name), false)
+}
+
+// readPrompt returns a task's prompt text, from its prompt file when it has one
+func (m *Model) readPrompt(t *task.Task) string {
+	if t.PromptFile == "" {
+		return t.Prompt
+	}
+	content, err := os.ReadFile(t.PromptFile)
+	if err != nil {
+		return t.Prompt
+	}
+	return string(content)
+}
+
+// taskRevRange is the revision range holding a task's changes: the merge if the
+// branch was merged, otherwise the branch since it left the default branch
+func taskRevRange(t *task.Task) string {
+	if t.RepoRoot == "" {
+		return ""
+	}
+	if t.PreMergeHead != "" && t.MergeCommit != "" {
+		return t.PreMergeHead + ".." + t.MergeCommit
+	}
+	if t.GitBranch == "" {
+		return ""
+	}
+	defaultBranch, err := git.GetDefaultBranch(t.RepoRoot)
+	if err != nil {
+		return ""
+	}
+	return defaultBranch + "..." + t.GitBranch
+}
+
+// openArchive switches to the history view of archived tasks
+func (m *Model) openArchive() tea.Cmd {
+	m.mode = viewArchive
+	m.archiveInput.Reset()
+	m.archiveInput.Focus()
+	m.archiveResults = m.archive.Search("")
+	m.archiveSelected = 0
+	return textinput.Blink
+}
+
+// updateArchive handles history view input
+// Typing filters the list; entries are navigated with the arrow keys so j/k can be typed
+func (m Model) updateArchive(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.mode = viewDashboard
+		m.archiveInput.Blur()
+		return m, nil
+
+	case "down", "ctrl+n":
+		if m.archiveSelected < len(m.archiveResults)-1 {
+			m.archiveSelected++
+		}
+		return m, nil
+
+	case "up", "ctrl+p":
+		if m.archiveSelected > 0 {
+			m.archiveSelected--
+		}
+		return m, nil
+
+	case "enter":
+		// Re-run the archived task as a new pending task
+		if m.archiveSelected >= len(m.archiveResults) {
+			return m, nil
+		}
+		m.rerunArchived(m.archiveResults[m.archiveSelected])
+		m.mode = viewDashboard
+		m.archiveInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	previous := m.archiveInput.Value()
+	m.archiveInput, cmd = m.archiveInput.Update(msg)
+	if m.archiveInput.Value() != previous {
+		m.archiveResults = m.archive.Search(m.archiveInput.Value())
+		m.archiveSelected = 0
+	}
+	return m, cmd
+}
+
+// rerunArchived creates a pending task with an archived task's name, directory,
+// agent and prompt
+func (m *Model) rerunArchived(entry *task.ArchivedTask) {
+	created, err := m.addClone(cloneRequest(&entry.Task, entry.Prompt))
+	if err != nil {
+		m.addMessage(fmt.Sprintf("Failed to re-run %s: %v", entry.Task.Name, err), true)
+		return
+	}
+	m.addMessage(fmt.Sprintf("Created %s from history; press s to start it", created.Name), false)
+}
+
+// viewArchive renders the history view
+func (m Model) viewArchive() string {
+	var b strings.Builder
+
+	b.WriteString(m.archiveInput.View())
+	b.WriteString("\n\n")
+
+	switch {
+	case m.archive.Count() == 0:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("No archived tasks. Press a on a DONE task to archive it."))
+	case len(m.archiveResults) == 0:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("No matches."))
+	default:
+		contentWidth := m.width - 6
+		if contentWidth < 60 {
+			contentWidth = 60
+		}
+		nameWidth := 28
+		branchWidth := 20
+
+		// Keep the selected entry visible
+		available := m.height - 10
+		if available < 3 {
+			available = 3
+		}
+		start := 0
+		if m.archiveSelected >= available {
+			start = m.archiveSelected - available + 1
+		}
+		end := start + available
+		if end > len(m.archiveResults) {
+			end = len(m.archiveResults)
+		}
+
+		for i := start; i < end; i++ {
+			entry := m.archiveResults[i]
+			label := entry.Task.ID + " " + entry.Task.Name
+			branch := entry.Branch
+			if branch == "" {
+				branch = "-"
+			}
+			stat := entry.DiffStat
+			if stat == "" {
+				stat = "no changes"
+			}
+			line := fmt.Sprintf("%s  %-*s %-7s %-*s %s",
+				entry.ArchivedAt.Format("2006-01-02"),
+				nameWidth, truncate(label, nameWidth),
+				formatDuration(entry.Duration),
+				branchWidth, truncate(branch, branchWidth),
+				stat)
+			line = truncate(line, contentWidth)
+			if i == m.archiveSelected {
+				line = selectedRowStyle.Render(line)
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	panel := m.renderPanel("History", b.String(), m.width, m.height-1, true)
+	help := helpStyle.Render("[↑/↓]select  [enter]re-run  [esc]back")
+	return lipgloss.JoinVertical(lipgloss.Left, panel, help)
+}
+
+// formatDuration returns a short human-readable duration like "45m" or "2h05m"
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%dh", int(d.Hours()/24), int(d.Hours())%24)
+}
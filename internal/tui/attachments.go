@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/prompt"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// fzfFileFinishedMsg is sent when the attachment file picker completes
+type fzfFileFinishedMsg struct {
+	path string
+	err  error
+}
+
+// openAttachments switches to the attachments view for a task
+func (m *Model) openAttachments(t *task.Task) {
+	if t.PromptFile == "" {
+		m.addMessage("Attachments need a prompt file", true)
+		return
+	}
+	m.mode = viewAttachments
+	m.attachTaskID = t.ID
+	m.attachSelected = 0
+	m.attachTyping = false
+	m.attachInput.Reset()
+}
+
+// setAttachments stores a task's attachments and rewrites its prompt's Context section
+func (m *Model) setAttachments(attachments []string) {
+	t, ok := m.tasks.Get(m.attachTaskID)
+	if !ok {
+		return
+	}
+	if err := m.tasks.Update(t.ID, func(t *task.Task) {
+		t.Attachments = attachments
+	}); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to save attachments: %v", err), true)
+		return
+	}
+	if err := prompt.ApplyAttachments(t.PromptFile, attachments); err != nil {
+		m.addMessage(err.Error(), true)
+		return
+	}
+	m.snapshotPrompt(t, prompt.SnapshotEdited)
+}
+
+// addAttachment appends an attachment unless the task already has it
+func (m *Model) addAttachment(attachment string) {
+	attachment = strings.TrimSpace(attachment)
+	t, ok := m.tasks.Get(m.attachTaskID)
+	if !ok || attachment == "" {
+		return
+	}
+	for _, existing := range t.Attachments {
+		if existing == attachment {
+			return
+		}
+	}
+	m.setAttachments(append(append([]string{}, t.Attachments...), attachment))
+	m.attachSelected = len(t.Attachments) - 1
+}
+
+// openFzfFileSelector opens fzf over the files in a task's working directory
+// Paths are relative to that directory, which is where the agent runs
+func (m Model) openFzfFileSelector(t *task.Task) tea.Cmd {
+	dir := t.WorkDir()
+
+	// Prefer tracked files in a repo, then fd, then find
+	listCmd := "git ls-files 2>/dev/null || "
+	if _, err := exec.LookPath("fd"); err == nil {
+		listCmd += "fd --type f --hidden --exclude .git"
+	} else {
+		listCmd += "find . -name .git -prune -o -type f -print"
+	}
+
+	tmpFile, err := os.CreateTemp("", "flock-fzf-*.txt")
+	if err != nil {
+		return func() tea.Msg {
+			return fzfFileFinishedMsg{err: err}
+		}
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+
+	c := m.commands.Interactive(runner.Command("bash", "-c", "("+listCmd+") | fzf --prompt='Attach file: ' > "+tmpPath).In(dir))
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		defer os.Remove(tmpPath)
+
+		if err != nil {
+			// fzf returns exit code 130 when cancelled (Ctrl+C or Esc)
+			if runner.ExitCode(err) == 130 {
+				return fzfFileFinishedMsg{}
+			}
+			return fzfFileFinishedMsg{err: err}
+		}
+
+		content, readErr := os.ReadFile(tmpPath)
+		if readErr != nil {
+			return fzfFileFinishedMsg{err: readErr}
+		}
+		return fzfFileFinishedMsg{path: strings.TrimSpace(string(content))}
+	})
+}
+
+// updateAttachments handles attachments view input
+func (m Model) updateAttachments(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t, ok := m.tasks.Get(m.attachTaskID)
+	if !ok {
+		m.mode = viewDashboard
+		return m, nil
+	}
+
+	// Typing a path or URL
+	if m.attachTyping {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.attachTyping = false
+			m.attachInput.Blur()
+			m.attachInput.Reset()
+			return m, nil
+		case "enter":
+			m.addAttachment(m.attachInput.Value())
+			m.attachTyping = false
+			m.attachInput.Blur()
+			m.attachInput.Reset()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.attachInput, cmd = m.attachInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q", "A":
+		m.mode = viewDashboard
+
+	case "j", "down":
+		if m.attachSelected < len(t.Attachments)-1 {
+			m.attachSelected++
+		}
+
+	case "k", "up":
+		if m.attachSelected > 0 {
+			m.attachSelected--
+		}
+
+	case "f":
+		return m, m.openFzfFileSelector(t)
+
+	case "u":
+		m.attachTyping = true
+		m.attachInput.Focus()
+		return m, textinput.Blink
+
+	case "d":
+		if m.attachSelected < len(t.Attachments) {
+			var remaining []string
+			for i, attachment := range t.Attachments {
+				if i != m.attachSelected {
+					remaining = append(remaining, attachment)
+				}
+			}
+			m.setAttachments(remaining)
+			if m.attachSelected > 0 && m.attachSelected >= len(remaining) {
+				m.attachSelected--
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// viewAttachments renders the attachments view
+func (m Model) viewAttachments() string {
+	var b strings.Builder
+
+	t, ok := m.tasks.Get(m.attachTaskID)
+	if !ok {
+		return ""
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Attachments: %s", t.Name)))
+	b.WriteString("\n\n")
+
+	if len(t.Attachments) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("No attachments yet."))
+		b.WriteString("\n")
+	}
+	for i, attachment := range t.Attachments {
+		kind := "file"
+		if prompt.IsURL(attachment) {
+			kind = "link"
+		}
+		line := fmt.Sprintf("%-4s %s", kind, attachment)
+		if i == m.attachSelected {
+			line = selectedRowStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	if m.attachTyping {
+		b.WriteString("\n")
+		b.WriteString(inputLabelStyle.Render("Path or URL:"))
+		b.WriteString("\n")
+		b.WriteString(m.attachInput.View())
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("Listed under ## Context in the task's prompt"))
+	b.WriteString("\n")
+
+	help := helpStyle.Render("[f]ile picker  [u]rl/path  [d]elete  [j/k]navigate  [esc]back")
+	if m.attachTyping {
+		help = helpStyle.Render("[enter]add  [esc]cancel")
+	}
+	b.WriteString(help)
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// operation is slow git work running in a tea.Cmd, shown in the status panel with the
+// spinner until it finishes
+type operation struct {
+	id      int
+	label   string
+	started time.Time
+}
+
+// notice is a status message produced off the UI goroutine, added once its work is done
+type notice struct {
+	text  string
+	isErr bool
+}
+
+// startOperation shows label as running and returns the ID to finish it with
+func (m *Model) startOperation(label string) int {
+	m.nextOperationID++
+	m.operations = append(m.operations, operation{id: m.nextOperationID, label: label, started: time.Now()})
+	return m.nextOperationID
+}
+
+// finishOperation stops showing an operation
+func (m *Model) finishOperation(id int) {
+	for i, op := range m.operations {
+		if op.id == id {
+			m.operations = append(m.operations[:i:i], m.operations[i+1:]...)
+			return
+		}
+	}
+}
+
+// addNotices adds messages collected by background work, in order
+func (m *Model) addNotices(notices []notice) {
+	for _, n := range notices {
+		m.addMessage(n.text, n.isErr)
+	}
+}
+
+// operationLines renders the running operations, oldest first, with how long each has run
+func (m Model) operationLines(width int) []string {
+	var lines []string
+	style := lipgloss.NewStyle().Foreground(colorPrimary)
+	for _, op := range m.operations {
+		text := fmt.Sprintf("%s… (%s)", op.label, time.Since(op.started).Round(time.Second))
+		lines = append(lines, m.spinner.View()+" "+style.Render(truncate(text, width-2)))
+	}
+	return lines
+}
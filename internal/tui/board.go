@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// boardColumn is a column of the board layout and the statuses it collects
+type boardColumn struct {
+	title    string
+	statuses []task.Status
+}
+
+// boardColumns are the board's columns, left to right. Stalled tasks stay with the
+// working ones and paused tasks wait on the user like waiting ones.
+var boardColumns = []boardColumn{
+	{"PENDING", []task.Status{task.StatusPending}},
+	{"WORKING", []task.Status{task.StatusWorking, task.StatusStalled}},
+	{"WAITING", []task.Status{task.StatusWaiting, task.StatusPaused}},
+	{"DONE", []task.Status{task.StatusDone}},
+}
+
+// boardLayout sorts tasks into the board's columns, as indexes into tasks
+func boardLayout(tasks []*task.Task) [][]int {
+	layout := make([][]int, len(boardColumns))
+	for i, t := range tasks {
+		for c, column := range boardColumns {
+			for _, status := range column.statuses {
+				if t.Status == status {
+					layout[c] = append(layout[c], i)
+				}
+			}
+		}
+	}
+	return layout
+}
+
+// boardPosition finds the column and row of the task at index selected
+func boardPosition(layout [][]int, selected int) (col, row int) {
+	for c, column := range layout {
+		for r, i := range column {
+			if i == selected {
+				return c, r
+			}
+		}
+	}
+	return 0, 0
+}
+
+// toggleBoard switches the dashboard between the task table and the board
+func (m *Model) toggleBoard() {
+	m.config.BoardView = !m.config.BoardView
+	if err := m.config.Save(); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to save settings: %v", err), true)
+	}
+}
+
+// moveBoardSelection moves the selection dx columns across, skipping empty columns and
+// keeping the row where it can, or dy cards up or down its column
+func (m *Model) moveBoardSelection(dx, dy int) {
+	layout := boardLayout(m.visibleTasks())
+	col, row := boardPosition(layout, m.selected)
+	if dy != 0 {
+		row += dy
+		if row >= 0 && row < len(layout[col]) {
+			m.selected = layout[col][row]
+		}
+		return
+	}
+	for c := col + dx; c >= 0 && c < len(layout); c += dx {
+		if len(layout[c]) == 0 {
+			continue
+		}
+		if row >= len(layout[c]) {
+			row = len(layout[c]) - 1
+		}
+		m.selected = layout[c][row]
+		return
+	}
+}
+
+// moveBoardTask moves the selected task one column across by doing what the move means:
+// right starts a pending task, pauses a working one and marks a waiting one done; left
+// resumes a paused task. Other moves can't be made from the dashboard and say why.
+func (m *Model) moveBoardTask(dx int) {
+	tasks := m.visibleTasks()
+	if m.selected >= len(tasks) {
+		return
+	}
+	t := tasks[m.selected]
+	col, _ := boardPosition(boardLayout(tasks), m.selected)
+	target := col + dx
+	if target < 0 || target >= len(boardColumns) {
+		return
+	}
+
+	from, to := boardColumns[col].title, boardColumns[target].title
+	switch {
+	case from == "PENDING" && to == "WORKING":
+		if err := m.launchTask(t); err != nil {
+			m.err = err
+		}
+	case from == "WORKING" && to == "WAITING":
+		m.togglePause(t)
+	case t.Status == task.StatusPaused && to == "WORKING":
+		m.resumeTask(t)
+	case from == "WAITING" && to == "DONE":
+		// Report DONE as the agent would, so dependents and teardown run as usual
+		if err := multiplexer.WriteStatusFile(m.mux.StatusDir(), t, task.StatusDone); err != nil {
+			m.addMessage(fmt.Sprintf("Failed to mark %s done: %v", t.Name, err), true)
+			return
+		}
+		m.addMessage(fmt.Sprintf("Marked %s done", t.Name), false)
+	case from == "WAITING" && to == "WORKING":
+		m.addMessage(fmt.Sprintf("%s is waiting for an answer: reply in its tab (enter) or with y", t.Name), true)
+	default:
+		m.addMessage(fmt.Sprintf("%s can't move from %s to %s", t.Name, from, to), true)
+		return
+	}
+	// Follow the task into its new column
+	m.selectTask(t.ID)
+}
+
+// renderBoard renders the visible tasks as cards in a column per status
+func (m Model) renderBoard(width, height int) string {
+	tasks := m.visibleTasks()
+	layout := boardLayout(tasks)
+	selectedCol, _ := boardPosition(layout, m.selected)
+	now := time.Now()
+
+	columnWidth := width / len(boardColumns)
+	cardWidth := columnWidth - 6 // Borders and padding
+	if cardWidth < 10 {
+		cardWidth = 10
+	}
+	// Each card takes two lines
+	capacity := (height - 5) / 2
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	var columns []string
+	for c, column := range boardColumns {
+		var b strings.Builder
+		cards := layout[c]
+		start := 0
+		for r, i := range cards {
+			if i == m.selected && r >= capacity {
+				start = r - capacity + 1
+			}
+		}
+		end := start + capacity
+		if end > len(cards) {
+			end = len(cards)
+		}
+		for _, i := range cards[start:end] {
+			t := tasks[i]
+			title := fmt.Sprintf("%-*s", cardWidth, truncate(t.ID+" "+t.Name+hookBadge(t), cardWidth))
+			if i == m.selected && len(tasks) > 0 {
+				title = selectedRowStyle.Render(title)
+			} else if m.marked[t.ID] {
+				title = lipgloss.NewStyle().Bold(true).Render(title)
+			}
+			b.WriteString(title)
+			b.WriteString("\n")
+			detail := m.boardCardDetail(t, now)
+			if t.Status == task.StatusWorking {
+				b.WriteString(m.spinner.View() + " ")
+				detail = truncate(detail, cardWidth-2)
+			} else {
+				detail = truncate(detail, cardWidth)
+			}
+			b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(detail))
+			b.WriteString("\n")
+		}
+		if hidden := len(cards) - (end - start); hidden > 0 {
+			b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("(%d more)", hidden)))
+		}
+
+		title := fmt.Sprintf("%s (%d)", column.title, len(cards))
+		w := columnWidth
+		if c == len(boardColumns)-1 {
+			w = width - columnWidth*(len(boardColumns)-1)
+		}
+		columns = append(columns, m.renderPanel(title, b.String(), w, height, c == selectedCol && len(tasks) > 0))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// boardCardDetail is the second line of a task's card: what it's doing or waiting on
+func (m Model) boardCardDetail(t *task.Task, now time.Time) string {
+	var parts []string
+	if m.marked[t.ID] {
+		parts = append(parts, "*")
+	}
+	switch t.Status {
+	case task.StatusStalled, task.StatusPaused:
+		parts = append(parts, string(t.Status))
+	case task.StatusWaiting:
+		if badge := t.WaitReason.Badge(); badge != "" {
+			parts = append(parts, badge)
+		}
+	case task.StatusPending:
+		if len(t.DependsOn) > 0 {
+			parts = append(parts, "after "+strings.Join(t.DependsOn, ","))
+		}
+		if t.NextRun != nil {
+			parts = append(parts, "next "+formatNextRun(*t.NextRun))
+		}
+	case task.StatusDone:
+		if t.MergedAt != nil {
+			parts = append(parts, "merged")
+		} else if m.isReady(t) {
+			parts = append(parts, readyBadge)
+		}
+	}
+	if working, _ := t.TimeSpent(now); working > 0 {
+		parts = append(parts, task.FormatSpent(working))
+	}
+	if t.GitBranch != "" {
+		parts = append(parts, t.GitBranch)
+	}
+	parts = append(parts, t.AgeString()+" old")
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// branchStatusInterval is how often the task table's Git column is refreshed. The git
+// package caches each directory's status for longer, so most refreshes are cheap.
+const branchStatusInterval = 5 * time.Second
+
+// branchStatusTickMsg triggers a refresh of the task table's Git column
+type branchStatusTickMsg struct{}
+
+// branchStatusMsg carries every task's ahead/behind status, keyed by task ID
+type branchStatusMsg struct {
+	statuses map[string]git.BranchStatus
+}
+
+// scheduleBranchStatusRefresh schedules the next refresh of the Git column
+func scheduleBranchStatusRefresh() tea.Cmd {
+	return tea.Tick(branchStatusInterval, func(t time.Time) tea.Msg {
+		return branchStatusTickMsg{}
+	})
+}
+
+// refreshBranchStatuses returns a command that reads each task's branch status off the
+// UI goroutine, so rendering never waits on git
+func (m Model) refreshBranchStatuses() tea.Cmd {
+	// Collect directories up front; git runs in the command
+	dirs := make(map[string]string)
+	for _, t := range m.tasks.List() {
+		dirs[t.ID] = taskGitDir(t)
+	}
+	return func() tea.Msg {
+		statuses := make(map[string]git.BranchStatus, len(dirs))
+		for id, dir := range dirs {
+			statuses[id] = git.GetBranchStatus(dir)
+		}
+		return branchStatusMsg{statuses: statuses}
+	}
+}
+
+// taskGitDir is the directory a task's branch status is read from: its worktree, or
+// its working directory without one
+func taskGitDir(t *task.Task) string {
+	if t.WorktreePath != "" {
+		return t.WorktreePath
+	}
+	return t.Cwd
+}
+
+// branchStatus returns the last branch status read for a task. A task added since the
+// last refresh has none yet.
+func (m Model) branchStatus(taskID string) (git.BranchStatus, bool) {
+	status, ok := m.branchStatuses[taskID]
+	return status, ok
+}
@@ -0,0 +1,281 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/gate"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// Bulk actions that ask for confirmation
+const (
+	bulkDelete = "delete"
+	bulkMerge  = "merge"
+)
+
+// toggleMark adds a task to, or removes it from, the tasks bulk actions apply to
+func (m *Model) toggleMark(t *task.Task) {
+	if m.marked == nil {
+		m.marked = make(map[string]bool)
+	}
+	if m.marked[t.ID] {
+		delete(m.marked, t.ID)
+	} else {
+		m.marked[t.ID] = true
+	}
+}
+
+// markedTasks returns the marked tasks in dashboard order
+// Marked tasks hidden by the project filter are left out
+func (m Model) markedTasks() []*task.Task {
+	var marked []*task.Task
+	for _, t := range m.visibleTasks() {
+		if m.marked[t.ID] {
+			marked = append(marked, t)
+		}
+	}
+	return marked
+}
+
+// startMarked starts every marked pending task
+func (m *Model) startMarked() {
+	started, skipped := 0, 0
+	for _, t := range m.markedTasks() {
+		if t.Status != task.StatusPending {
+			skipped++
+			continue
+		}
+		if err := m.launchTask(t); err != nil {
+			m.addMessage(fmt.Sprintf("Failed to start %s: %v", t.Name, err), true)
+			continue
+		}
+		delete(m.marked, t.ID)
+		started++
+	}
+	msg := fmt.Sprintf("Started %d tasks", started)
+	if skipped > 0 {
+		msg += fmt.Sprintf(" (%d already started)", skipped)
+	}
+	m.addMessage(msg, false)
+}
+
+// openBulkConfirm asks before deleting or merging the marked tasks
+func (m *Model) openBulkConfirm(action string) {
+	if action == bulkDelete && !m.config.ConfirmBeforeDelete {
+		m.deleteMarked(false)
+		return
+	}
+	if action == bulkMerge {
+		if len(m.mergeableMarked()) == 0 {
+			m.addMessage("None of the selected tasks has a branch to merge", true)
+			return
+		}
+		if strategy, err := git.ParseMergeStrategy(m.config.Worktrees.MergeStrategy); err == nil {
+			m.mergeStrategy = strategy
+		} else {
+			m.mergeStrategy = git.StrategyMerge
+		}
+	}
+	m.bulkAction = action
+	m.mode = viewConfirmBulk
+}
+
+// mergeableMarked returns the marked tasks that have a branch to merge
+func (m Model) mergeableMarked() []*task.Task {
+	var tasks []*task.Task
+	for _, t := range m.markedTasks() {
+		if t.GitBranch != "" && t.RepoRoot != "" {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+// deleteMarked deletes the marked tasks. Worktrees are released when asked to or
+// when the cleanup setting says so.
+func (m *Model) deleteMarked(withWorktrees bool) {
+	tasks := m.markedTasks()
+	for _, t := range tasks {
+		if withWorktrees {
+			m.deleteTaskWithWorktreeOption(t.ID, true)
+		} else {
+			m.deleteTask(t.ID)
+		}
+		delete(m.marked, t.ID)
+	}
+	m.addMessage(fmt.Sprintf("Deleted %d tasks", len(tasks)), false)
+}
+
+// bulkMergeDoneMsg is sent when merging the marked tasks finishes or stops
+type bulkMergeDoneMsg struct {
+	op      int
+	merged  []string // IDs of the tasks whose branches were merged
+	notices []notice
+}
+
+// mergeMarked merges the marked tasks' branches one after another in dashboard order,
+// in the background, stopping at the first merge that fails so later branches don't land
+// without it. With merge.require_command set, checks holds the result for each task, and
+// a task whose check failed (or was not run) stops the merging too.
+func (m *Model) mergeMarked(checks map[string]gate.Result) tea.Cmd {
+	if m.mergeRunning || m.undoRunning {
+		m.addMessage("Another merge or undo is still running; merge the selected tasks once it is done", true)
+		return nil
+	}
+	var tasks []task.Task
+	for _, t := range m.mergeableMarked() {
+		tasks = append(tasks, *t)
+	}
+	m.mergeRunning = true
+	op := m.startOperation(fmt.Sprintf("Merging %d branches", len(tasks)))
+	manager, worktrees, strategy, require := m.tasks, m.config.Worktrees, m.mergeStrategy, m.config.Merge.RequireCommand
+	return func() tea.Msg {
+		return mergeTasks(manager, worktrees, strategy, require, tasks, checks, op)
+	}
+}
+
+// mergeTasks does the merging for mergeMarked, off the UI goroutine
+func mergeTasks(manager *task.Manager, worktrees config.WorktreeConfig, strategy git.MergeStrategy, require string, tasks []task.Task, checks map[string]gate.Result, op int) bulkMergeDoneMsg {
+	done := bulkMergeDoneMsg{op: op}
+	for i := range tasks {
+		t := &tasks[i]
+		var err error
+		if require != "" {
+			if check, ok := checks[t.ID]; !ok {
+				err = fmt.Errorf("%s was not run", require)
+			} else if !check.Passed {
+				err = fmt.Errorf("%s", check.Summary())
+				if check.LogPath != "" {
+					err = fmt.Errorf("%s, see %s", check.Summary(), check.LogPath)
+				}
+			}
+		}
+		if err == nil {
+			var result *git.MergeResult
+			var notices []notice
+			result, notices, err = mergeTask(manager, worktrees, t, strategy)
+			done.notices = append(done.notices, notices...)
+			if err == nil && !result.Success {
+				err = fmt.Errorf("%s", result.Message)
+			}
+		}
+		if err != nil {
+			done.notices = append(done.notices, notice{text: fmt.Sprintf("Stopped merging at %s: %v", t.Name, err), isErr: true})
+			if remaining := len(tasks) - i - 1; remaining > 0 {
+				done.notices = append(done.notices, notice{text: fmt.Sprintf("%d selected tasks were not merged", remaining), isErr: true})
+			}
+			return done
+		}
+		done.merged = append(done.merged, t.ID)
+	}
+	done.notices = append(done.notices, notice{text: fmt.Sprintf("Merged %d tasks", len(tasks))})
+	return done
+}
+
+// updateConfirmBulk handles the bulk delete and merge dialogs
+func (m Model) updateConfirmBulk(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		if m.bulkAction == bulkMerge && m.config.Merge.RequireCommand != "" {
+			// Check every branch first; the merges follow once the checks are done
+			m.bulkAction = ""
+			m.mode = viewDashboard
+			return m, m.checkMarked()
+		}
+		var cmd tea.Cmd
+		if m.bulkAction == bulkMerge {
+			cmd = m.mergeMarked(nil)
+		} else {
+			m.deleteMarked(false)
+		}
+		m.bulkAction = ""
+		m.mode = viewDashboard
+		return m, cmd
+
+	case "w":
+		// Delete the tasks and their worktrees
+		if m.bulkAction == bulkDelete {
+			m.deleteMarked(true)
+			m.bulkAction = ""
+			m.mode = viewDashboard
+		}
+
+	case "r":
+		// Cycle between a merge commit, a squash and rebasing onto the default branch
+		if m.bulkAction == bulkMerge {
+			m.mergeStrategy = m.mergeStrategy.Next()
+		}
+
+	case "n", "N", "esc":
+		m.bulkAction = ""
+		m.mode = viewDashboard
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// viewConfirmBulk renders the bulk delete and merge dialogs
+func (m Model) viewConfirmBulk() string {
+	var b strings.Builder
+	muted := lipgloss.NewStyle().Foreground(colorSecondary)
+
+	if m.bulkAction == bulkMerge {
+		tasks := m.mergeableMarked()
+		b.WriteString(titleStyle.Render(fmt.Sprintf("Merge %d Tasks?", len(tasks))))
+		b.WriteString("\n\n")
+		action := "Merge"
+		switch m.mergeStrategy {
+		case git.StrategyRebase:
+			action = "Rebase and fast-forward"
+		case git.StrategySquash:
+			action = "Squash"
+		}
+		b.WriteString(action + " these branches into the default branch, in order:\n\n")
+		for i, t := range tasks {
+			b.WriteString(fmt.Sprintf("  %d. %s %s (%s)\n", i+1, t.ID, t.Name, t.GitBranch))
+		}
+		if skipped := len(m.markedTasks()) - len(tasks); skipped > 0 {
+			b.WriteString(muted.Render(fmt.Sprintf("\n%d selected tasks have no branch and are skipped.", skipped)))
+			b.WriteString("\n")
+		}
+		if m.config.Merge.RequireCommand != "" {
+			b.WriteString(muted.Render(fmt.Sprintf("\nEach branch must pass %s first.", m.config.Merge.RequireCommand)))
+		}
+		b.WriteString(muted.Render("\nMerging stops at the first conflict or failure."))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("[y/enter]merge all  [r]merge/squash/rebase  [esc]cancel"))
+		return m.centerContent(modalStyle.Render(b.String()))
+	}
+
+	tasks := m.markedTasks()
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Delete %d Tasks?", len(tasks))))
+	b.WriteString("\n\n")
+	worktrees := 0
+	for _, t := range tasks {
+		b.WriteString(fmt.Sprintf("  %s %s\n", t.ID, t.Name))
+		if t.WorktreePath != "" {
+			worktrees++
+		}
+	}
+	b.WriteString("\n")
+	help := "[y/enter]delete  [esc]cancel"
+	if worktrees > 0 {
+		if m.config.Worktrees.Cleanup == config.WorktreeCleanupDelete {
+			b.WriteString(muted.Render(fmt.Sprintf("%d worktrees will be deleted too.", worktrees)))
+		} else {
+			b.WriteString(muted.Render(fmt.Sprintf("%d worktrees are kept unless you press w.", worktrees)))
+			help = "[y/enter]delete  [w]delete with worktrees  [esc]cancel"
+		}
+		b.WriteString("\n\n")
+	}
+	b.WriteString(helpStyle.Render(help))
+	return m.centerContent(modalStyle.Render(b.String()))
+}
@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/capacity"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// recordCapacity adds a sample of the running agents to the capacity history, with the rate
+// limits reported since the last one. Samples without agents say nothing about capacity.
+func (m *Model) recordCapacity(sample capacity.Sample) {
+	if sample.Agents == 0 {
+		return
+	}
+	sample.RateLimits = m.rateLimits
+	m.rateLimits = 0
+	m.capacityHistory.Add(sample)
+	// Failing to save only loses the samples since the last save, so it isn't worth a message every check
+	m.capacityHistory.Save(m.config.CapacityPath())
+}
+
+// capacityPlan estimates how many agents can run at once from the recorded samples
+func (m Model) capacityPlan() capacity.Plan {
+	return capacity.NewPlan(m.capacityHistory, capacity.DetectMachine(m.commands))
+}
+
+// concurrencyLimit returns how many agents should run at once and where the number comes
+// from: max_concurrent_tasks, or the planner's suggestion once it has enough samples
+func (m Model) concurrencyLimit() (int, string) {
+	if m.config.MaxConcurrentTasks > 0 {
+		return m.config.MaxConcurrentTasks, "max_concurrent_tasks"
+	}
+	return m.capacityPlan().Suggested, "the capacity planner's suggestion"
+}
+
+// workingAgents counts the tasks with an agent at work, other than the one with ID except
+func (m Model) workingAgents(except string) int {
+	n := 0
+	for _, other := range m.tasks.List() {
+		if other.ID != except && (other.Status == task.StatusWorking || other.Status == task.StatusStalled) {
+			n++
+		}
+	}
+	return n
+}
+
+// warnOverCapacity warns when starting t would run more agents than the limit. The task
+// still starts; the limit is a guide, not a queue.
+func (m *Model) warnOverCapacity(t *task.Task) {
+	limit, source := m.concurrencyLimit()
+	if limit == 0 {
+		return
+	}
+	if running := m.workingAgents(t.ID); running >= limit {
+		m.addMessage(fmt.Sprintf("Starting %s with %d agents already working, over the limit of %d from %s; they may all slow down", t.Name, running, limit, source), true)
+	}
+}
+
+// openCapacity shows the capacity planner
+func (m *Model) openCapacity() {
+	m.mode = viewCapacity
+}
+
+// updateCapacity handles the capacity planner's keys
+func (m Model) updateCapacity(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q", "C":
+		m.mode = viewDashboard
+
+	case "a":
+		// Keep the current suggestion even as more samples change it
+		plan := m.capacityPlan()
+		if plan.Suggested == 0 {
+			m.addMessage("Not enough samples for a suggestion yet", true)
+			return m, nil
+		}
+		m.setMaxConcurrentTasks(plan.Suggested)
+
+	case "x":
+		// Go back to following the suggestion
+		m.setMaxConcurrentTasks(0)
+	}
+	return m, nil
+}
+
+// setMaxConcurrentTasks saves max_concurrent_tasks (0 follows the planner)
+func (m *Model) setMaxConcurrentTasks(n int) {
+	m.config.MaxConcurrentTasks = n
+	if err := m.config.Save(); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to save settings: %v", err), true)
+		return
+	}
+	if n == 0 {
+		m.addMessage("Cleared max_concurrent_tasks; the planner's suggestion applies", false)
+	} else {
+		m.addMessage(fmt.Sprintf("Set max_concurrent_tasks to %d", n), false)
+	}
+}
+
+// viewCapacity renders what the running agents have used and how many the planner suggests
+func (m Model) viewCapacity() string {
+	var b strings.Builder
+	muted := lipgloss.NewStyle().Foreground(colorSecondary)
+	machine := capacity.DetectMachine(m.commands)
+	plan := capacity.NewPlan(m.capacityHistory, machine)
+
+	row := func(label, value string) {
+		b.WriteString(inputLabelStyle.Render(fmt.Sprintf("%-14s", label)))
+		b.WriteString(value)
+		b.WriteString("\n")
+	}
+	limit := func(n int, none string) string {
+		if n == 0 {
+			return muted.Render(none)
+		}
+		return fmt.Sprintf("%d agents", n)
+	}
+
+	b.WriteString(titleStyle.Render("Capacity planner"))
+	b.WriteString("\n\n")
+
+	memory := "unknown memory"
+	if machine.Memory > 0 {
+		memory = formatBytes(machine.Memory) + " memory"
+	}
+	row("Machine", fmt.Sprintf("%d CPUs, %s", machine.CPUs, memory))
+	row("Working now", fmt.Sprintf("%d agents", m.workingAgents("")))
+	row("Samples", fmt.Sprintf("%d with agents running (%d needed)", plan.Samples, capacity.MinSamples))
+	if plan.Samples >= capacity.MinSamples {
+		row("Per agent", fmt.Sprintf("CPU %.1f%%, memory %s", plan.AgentCPU, formatBytes(plan.AgentRSS)))
+		row("Peak", fmt.Sprintf("%d agents at once, load average %.2f", plan.PeakAgents, plan.PeakLoad))
+		b.WriteString("\n")
+		row("CPU fits", limit(plan.CPULimit, "no CPU use seen"))
+		row("Memory fits", limit(plan.MemoryLimit, "unknown"))
+		row("Rate limits", limit(plan.RateLimit, "none reported"))
+	}
+	b.WriteString("\n")
+
+	suggested := muted.Render("not enough samples yet; keep some agents working")
+	if plan.Suggested > 0 {
+		suggested = lipgloss.NewStyle().Foreground(colorSuccess).Bold(true).Render(fmt.Sprintf("%d agents at once", plan.Suggested))
+	}
+	row("Suggested", suggested)
+	configured := muted.Render("not set (the suggestion applies)")
+	if m.config.MaxConcurrentTasks > 0 {
+		configured = fmt.Sprintf("%d agents", m.config.MaxConcurrentTasks)
+	}
+	row("Configured", configured)
+	b.WriteString("\n")
+
+	b.WriteString(muted.Render("Agents share 80% of the CPUs and 70% of memory; starting one past the limit warns"))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("[a]apply suggestion  [x]clear limit  [esc]back"))
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
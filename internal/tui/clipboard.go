@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/muesli/termenv"
+)
+
+// clipboardCommands returns the commands that can set the system clipboard here, best first
+func clipboardCommands() []runner.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return []runner.Cmd{runner.Command("pbcopy")}
+	case "windows":
+		return []runner.Cmd{runner.Command("clip.exe")}
+	}
+	var cmds []runner.Cmd
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, runner.Command("wl-copy"))
+	}
+	return append(cmds,
+		runner.Command("xclip", "-selection", "clipboard"),
+		runner.Command("xsel", "--clipboard", "--input"),
+		runner.Command("clip.exe"), // WSL
+	)
+}
+
+// copyToClipboard puts text on the clipboard with the first clipboard tool installed, or
+// otherwise asks the terminal to with an OSC 52 escape sequence (which also works over SSH)
+func (m Model) copyToClipboard(text string) error {
+	for _, cmd := range clipboardCommands() {
+		if _, err := exec.LookPath(cmd.Name); err != nil {
+			continue
+		}
+		if _, err := m.commands.CombinedOutput(context.Background(), cmd.WithInput(text)); err != nil {
+			return fmt.Errorf("%s failed: %w", cmd.Name, err)
+		}
+		return nil
+	}
+	termenv.DefaultOutput().Copy(text)
+	return nil
+}
+
+// yankPath copies a full path to the clipboard, reporting it in the status panel
+func (m *Model) yankPath(path string) {
+	if err := m.copyToClipboard(path); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to copy %s: %v", path, err), true)
+		return
+	}
+	m.addMessage(fmt.Sprintf("Copied %s", path), false)
+}
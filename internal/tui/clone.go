@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// cloneRequest asks for a new pending task with t's name, directory, agent and prompt.
+// A task that had a worktree gets a fresh one rather than sharing the old branch.
+func cloneRequest(t *task.Task, promptText string) daemon.Request {
+	return daemon.Request{
+		Action:      daemon.ActionAdd,
+		Name:        t.Name,
+		Cwd:         t.Cwd,
+		Template:    t.Template,
+		PromptText:  promptText,
+		Agent:       t.Agent,
+		Permission:  t.Permission,
+		Attachments: t.Attachments,
+		AutoNudge:   t.AutoNudge,
+		Setup:       t.Setup,
+		Teardown:    t.Teardown,
+		UseWorktree: t.UseWorktree || t.WorktreePath != "",
+	}
+}
+
+// addClone creates the task described by req the same way the daemon does, selecting it
+func (m *Model) addClone(req daemon.Request) (*task.Task, error) {
+	created, err := m.server.Handle(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(created) == 0 {
+		return nil, fmt.Errorf("no task was created")
+	}
+	if !m.selectTask(created[0].ID) {
+		// The clone is in another project; show all tasks to reveal it
+		m.config.ProjectOnly = false
+		m.selectTask(created[0].ID)
+	}
+	return created[0], nil
+}
+
+// openClone asks whether a started task's clone should start right away or be edited first
+func (m *Model) openClone(t *task.Task) {
+	if t.Status == task.StatusPending {
+		m.addMessage("Only started tasks can be re-run; press s to start this one", true)
+		return
+	}
+	m.cloningTaskID = t.ID
+	m.mode = viewConfirmClone
+}
+
+// updateConfirmClone handles the re-run dialog input
+func (m Model) updateConfirmClone(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "s", "enter", "e":
+		t, ok := m.tasks.Get(m.cloningTaskID)
+		m.cloningTaskID = ""
+		m.mode = viewDashboard
+		if !ok {
+			return m, nil
+		}
+
+		req := cloneRequest(t, m.readPrompt(t))
+		edit := msg.String() == "e"
+		req.Start = !edit
+		clone, err := m.addClone(req)
+		if err != nil {
+			m.addMessage(fmt.Sprintf("Failed to re-run %s: %v", t.Name, err), true)
+			return m, nil
+		}
+		if !edit {
+			m.addMessage(fmt.Sprintf("Re-running %s as %s", t.Name, clone.ID), false)
+			return m, nil
+		}
+		m.addMessage(fmt.Sprintf("Cloned %s as %s; press s to start it after editing", t.Name, clone.ID), false)
+		return m, m.openEditorForEdit(clone.ID, clone.PromptFile)
+
+	case "n", "N", "esc":
+		m.cloningTaskID = ""
+		m.mode = viewDashboard
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// viewConfirmClone renders the re-run dialog
+func (m Model) viewConfirmClone() string {
+	var b strings.Builder
+
+	t, ok := m.tasks.Get(m.cloningTaskID)
+	if !ok {
+		return m.viewDashboard()
+	}
+
+	b.WriteString(titleStyle.Render("Re-run Task?"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("Clone '%s' into a new task with the same prompt", t.Name))
+	if t.UseWorktree || t.WorktreePath != "" {
+		b.WriteString("\nand a fresh worktree")
+	}
+	b.WriteString(".\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("The original task and its branch are kept."))
+	b.WriteString("\n\n")
+
+	help := helpStyle.Render("[s/enter]start now  [e]dit prompt first  [esc]cancel")
+	b.WriteString(help)
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
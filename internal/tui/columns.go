@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// columnTimeout bounds how long a single custom column command may run
+const columnTimeout = 30 * time.Second
+
+// columnTickMsg triggers a refresh of one custom column
+type columnTickMsg struct {
+	index int
+}
+
+// columnResultMsg carries freshly computed values for one custom column, keyed by task ID
+type columnResultMsg struct {
+	index  int
+	values map[string]string
+}
+
+// columnTarget is a task directory a custom column command runs in
+type columnTarget struct {
+	taskID string
+	dir    string
+}
+
+// scheduleColumnRefresh schedules the next refresh of a custom column
+func scheduleColumnRefresh(index int, interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return columnTickMsg{index: index}
+	})
+}
+
+// refreshColumns returns commands that compute every custom column immediately
+func (m Model) refreshColumns() tea.Cmd {
+	var cmds []tea.Cmd
+	for i := range m.config.Columns {
+		cmds = append(cmds, m.refreshColumn(i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshColumn returns a command that runs a custom column's command for every task
+func (m Model) refreshColumn(index int) tea.Cmd {
+	col := m.config.Columns[index]
+	commands := m.commands
+
+	// Collect directories up front; the command runs off the UI goroutine
+	var targets []columnTarget
+	for _, t := range m.tasks.List() {
+		dir := t.Cwd
+		if t.WorktreePath != "" {
+			dir = t.WorktreePath
+		}
+		targets = append(targets, columnTarget{taskID: t.ID, dir: dir})
+	}
+
+	return func() tea.Msg {
+		values := make(map[string]string, len(targets))
+		for _, target := range targets {
+			values[target.taskID] = runColumnCommand(commands, col, target.dir)
+		}
+		return columnResultMsg{index: index, values: values}
+	}
+}
+
+// runColumnCommand runs a column command in dir and returns the last line of its output.
+// A failing command with no output shows as "fail".
+func runColumnCommand(r runner.Runner, col config.ColumnConfig, dir string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), columnTimeout)
+	defer cancel()
+
+	stdout, err := r.Output(ctx, runner.Command("sh", "-c", col.Command).In(dir))
+
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+	value := strings.TrimSpace(lines[len(lines)-1])
+	if value == "" && err != nil {
+		if ctx.Err() != nil {
+			return "timeout"
+		}
+		return "fail"
+	}
+	return value
+}
+
+// columnValue returns the last computed value of a custom column for a task
+func (m Model) columnValue(index int, taskID string) string {
+	if index >= len(m.columnValues) || m.columnValues[index] == nil {
+		return "-"
+	}
+	value, ok := m.columnValues[index][taskID]
+	if !ok {
+		return "-"
+	}
+	return value
+}
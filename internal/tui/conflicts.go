@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// mergeConflicts reports whether the merge dialog's dry run found conflicts
+func (m Model) mergeConflicts() bool {
+	return m.mergeCheck != nil && len(m.mergeCheck.Conflicts) > 0
+}
+
+// closeMergeDialog returns to the dashboard and forgets the merge in progress
+func (m *Model) closeMergeDialog() {
+	m.mergingTaskID = ""
+	m.mergeDiffInfo = ""
+	m.mergeCheck = nil
+	m.mergeGate = nil
+	m.mergeGateRunning = false
+	m.mode = viewDashboard
+}
+
+// conflictsPreparedMsg carries the files left conflicting in a task's worktree by
+// git.PrepareConflicts
+type conflictsPreparedMsg struct {
+	op            int
+	taskID        string
+	defaultBranch string
+	strategy      git.MergeStrategy
+	files         []string
+	err           error
+}
+
+// resolveConflicts starts merging (or rebasing onto) the default branch in a task's
+// worktree in the background; conflictsPrepared then creates a new task whose agent
+// resolves the resulting conflicts there
+func (m *Model) resolveConflicts(t *task.Task) tea.Cmd {
+	if t.WorktreePath == "" {
+		m.addMessage(fmt.Sprintf("%s has no worktree to resolve conflicts in", t.Name), true)
+		return nil
+	}
+	if t.IsActive() {
+		m.addMessage(fmt.Sprintf("Wait for %s to finish before resolving conflicts in its worktree", t.Name), true)
+		return nil
+	}
+
+	op := m.startOperation(fmt.Sprintf("Taking %s into %s", m.mergeCheck.DefaultBranch, t.GitBranch))
+	msg := conflictsPreparedMsg{op: op, taskID: t.ID, defaultBranch: m.mergeCheck.DefaultBranch, strategy: m.mergeStrategy}
+	worktree := t.WorktreePath
+	return func() tea.Msg {
+		msg.files, msg.err = git.PrepareConflicts(worktree, msg.defaultBranch, msg.strategy)
+		return msg
+	}
+}
+
+// conflictsPrepared starts the task resolving the conflicts resolveConflicts left
+func (m *Model) conflictsPrepared(msg conflictsPreparedMsg) {
+	m.finishOperation(msg.op)
+	t, ok := m.tasks.Get(msg.taskID)
+	if !ok {
+		return
+	}
+	if msg.err != nil {
+		m.addMessage(msg.err.Error(), true)
+		return
+	}
+	if len(msg.files) == 0 {
+		m.addMessage(fmt.Sprintf("%s took in %s without conflicts; merge it again", t.GitBranch, msg.defaultBranch), false)
+		return
+	}
+
+	created, err := m.server.Handle(daemon.Request{
+		Action: daemon.ActionAdd,
+		Name:   "resolve " + t.Name,
+		Cwd:    t.WorktreePath,
+		Prompt: conflictGoal(t.GitBranch, msg.defaultBranch, msg.files, msg.strategy),
+		Agent:  t.Agent,
+		Start:  true,
+	})
+	if err != nil {
+		m.addMessage(fmt.Sprintf("Failed to create conflict task: %v", err), true)
+	}
+	if len(created) > 0 {
+		m.addMessage(fmt.Sprintf("Started %s for %d conflicting files", created[0].Name, len(msg.files)), false)
+		m.selectTask(created[0].ID)
+	}
+}
+
+// conflictGoal is the goal of a conflict resolution task
+func conflictGoal(branch, defaultBranch string, files []string, strategy git.MergeStrategy) string {
+	var b strings.Builder
+	if strategy == git.StrategyRebase {
+		fmt.Fprintf(&b, "Resolve the conflicts from rebasing branch `%s` onto `%s`. The rebase is in progress in this worktree and these files conflict:\n\n", branch, defaultBranch)
+	} else {
+		fmt.Fprintf(&b, "Resolve the merge conflicts between branch `%s` and `%s`. `%s` has been merged into this worktree and these files conflict:\n\n", branch, defaultBranch, defaultBranch)
+	}
+	for _, file := range files {
+		fmt.Fprintf(&b, "- %s\n", file)
+	}
+	b.WriteString("\nKeep the intent of both sides, make sure the project still builds and its tests pass, then ")
+	if strategy == git.StrategyRebase {
+		b.WriteString("`git add` the resolved files and `git rebase --continue` until the rebase finishes.")
+	} else {
+		b.WriteString("`git add` the resolved files and `git commit --no-edit` to conclude the merge.")
+	}
+	return b.String()
+}
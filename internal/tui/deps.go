@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/chain"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// chainModes lists the chain modes in the order the form cycles through them
+var chainModes = []task.ChainMode{task.ChainStart, task.ChainWorktree, task.ChainMerge}
+
+// chainModeLabel describes a chain mode for the dependencies form
+func chainModeLabel(mode task.ChainMode) string {
+	switch mode {
+	case task.ChainWorktree:
+		return "Start in the first dependency's worktree"
+	case task.ChainMerge:
+		return "Merge dependency branches, then start"
+	default:
+		return "Start as configured"
+	}
+}
+
+// startReadyDependents auto-starts pending tasks whose dependencies are all DONE
+func (m *Model) startReadyDependents() {
+	for _, t := range m.tasks.ReadyDependents() {
+		notes, err := chain.Prepare(m.tasks, t, m.config.Worktrees)
+		for _, note := range notes {
+			m.addMessage(note, false)
+		}
+		if err != nil {
+			m.addMessage(fmt.Sprintf("Not starting %s: %v", t.Name, err), true)
+			continue
+		}
+		if err := m.launchTask(t); err != nil {
+			m.addMessage(fmt.Sprintf("Failed to start %s: %v", t.Name, err), true)
+			continue
+		}
+		m.addMessage(fmt.Sprintf("Started %s (dependencies done)", t.Name), false)
+	}
+}
+
+// openDependencies opens the dependencies form for a pending task
+func (m *Model) openDependencies(t *task.Task) tea.Cmd {
+	m.mode = viewDependencies
+	m.depsTaskID = t.ID
+	m.depsMode = t.ChainMode
+	m.depsInput.SetValue(strings.Join(t.DependsOn, ", "))
+	m.depsInput.CursorEnd()
+	m.depsInput.Focus()
+	return textinput.Blink
+}
+
+// updateDependencies handles dependencies form input
+func (m Model) updateDependencies(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.mode = viewDashboard
+		m.depsInput.Blur()
+		return m, nil
+
+	case "tab":
+		// Cycle what happens once dependencies are DONE
+		for i, mode := range chainModes {
+			if mode == m.depsMode {
+				m.depsMode = chainModes[(i+1)%len(chainModes)]
+				break
+			}
+		}
+		return m, nil
+
+	case "enter":
+		deps := strings.FieldsFunc(m.depsInput.Value(), func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+		if err := m.tasks.SetDependencies(m.depsTaskID, deps, m.depsMode); err != nil {
+			// Keep the form open so the IDs can be fixed
+			m.addMessage(err.Error(), true)
+			return m, nil
+		}
+		m.mode = viewDashboard
+		m.depsInput.Blur()
+		if t, ok := m.tasks.Get(m.depsTaskID); ok {
+			if len(deps) == 0 {
+				m.addMessage(fmt.Sprintf("Cleared dependencies of %s", t.Name), false)
+			} else {
+				m.addMessage(fmt.Sprintf("%s will start after %s", t.Name, strings.Join(t.DependsOn, ", ")), false)
+			}
+		}
+		// Dependencies may already be done
+		m.startReadyDependents()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.depsInput, cmd = m.depsInput.Update(msg)
+	return m, cmd
+}
+
+// viewDependencies renders the dependencies form
+func (m Model) viewDependencies() string {
+	var b strings.Builder
+
+	name := m.depsTaskID
+	if t, ok := m.tasks.Get(m.depsTaskID); ok {
+		name = t.Name
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Dependencies: %s", name)))
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("Start after tasks:"))
+	b.WriteString("\n")
+	b.WriteString(m.depsInput.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("When they are done:"))
+	b.WriteString("\n")
+	for _, mode := range chainModes {
+		marker := "( )"
+		if mode == m.depsMode {
+			marker = "(•)"
+		}
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("%s %s", marker, chainModeLabel(mode))))
+		b.WriteString("\n")
+	}
+
+	// Show the other tasks for reference
+	b.WriteString("\n")
+	for _, t := range m.tasks.List() {
+		if t.ID == m.depsTaskID {
+			continue
+		}
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("%s  %-24s %s", t.ID, truncate(t.Name, 24), t.Status)))
+		b.WriteString("\n")
+	}
+
+	help := helpStyle.Render("[tab]mode  [enter]save  [esc]cancel  (empty clears)")
+	b.WriteString(help)
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
@@ -0,0 +1,455 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/timefmt"
+)
+
+// Detail view tabs, in the order they are shown
+const (
+	detailPrompt = iota
+	detailDiff
+	detailOutput
+	detailNotes
+	detailHistory
+	detailInfo
+	detailTabCount
+)
+
+// detailTabNames labels the detail view tabs
+var detailTabNames = [detailTabCount]string{"Prompt", "Diff", "Output", "Notes", "History", "Info"}
+
+// openDetail switches to the full-screen detail view for a task
+func (m *Model) openDetail(t *task.Task) tea.Cmd {
+	m.detailTaskID = t.ID
+	m.detailTab = detailPrompt
+	m.mode = viewDetail
+	return m.loadDetail()
+}
+
+// switchDetailTab shows another tab, wrapping around at either end
+func (m *Model) switchDetailTab(tab int) tea.Cmd {
+	m.detailTab = (tab + detailTabCount) % detailTabCount
+	return m.loadDetail()
+}
+
+// detailDiffMsg carries the diff tab's lines, read in the background
+type detailDiffMsg struct {
+	taskID string
+	lines  []string
+}
+
+// loadDetail builds the lines of the current tab
+// The output tab starts at the bottom, where the latest output is. The diff tab
+// is read by the returned command and shows a placeholder until it arrives.
+func (m *Model) loadDetail() tea.Cmd {
+	m.detailScroll = 0
+	t, ok := m.tasks.Get(m.detailTaskID)
+	if !ok {
+		m.detailLines = []string{"Task no longer exists"}
+		return nil
+	}
+
+	switch m.detailTab {
+	case detailPrompt:
+		m.detailLines = m.detailPromptLines(t)
+	case detailDiff:
+		m.detailLines = []string{"Loading diff..."}
+		m.detailDiff = newDiffPager(m.detailLines[0])
+		snapshot := *t
+		return func() tea.Msg {
+			return detailDiffMsg{taskID: snapshot.ID, lines: detailDiffLines(&snapshot)}
+		}
+	case detailOutput:
+		m.detailLines = m.detailOutputLines(t)
+		m.detailScroll = m.maxDetailScroll()
+	case detailNotes:
+		m.detailLines = m.detailNotesLines(t)
+		m.detailScroll = m.maxDetailScroll()
+	case detailHistory:
+		m.detailLines = detailHistoryLines(t, m.config.TimeFormat)
+	case detailInfo:
+		m.detailLines = m.detailInfoLines(t)
+	}
+	return nil
+}
+
+// detailPromptLines renders the task's prompt as markdown at the view's width
+func (m Model) detailPromptLines(t *task.Task) []string {
+	content := t.Prompt
+	if t.PromptFile != "" {
+		data, err := os.ReadFile(t.PromptFile)
+		if err != nil {
+			return []string{fmt.Sprintf("Error reading prompt: %v", err)}
+		}
+		content = string(data)
+	}
+	if strings.TrimSpace(content) == "" {
+		return []string{"No prompt"}
+	}
+	return m.renderMarkdownLines(content)
+}
+
+// renderMarkdownLines renders markdown at the view's width, falling back to wrapped text
+func (m Model) renderMarkdownLines(content string) []string {
+	width := m.detailWidth()
+	renderer, err := glamour.NewTermRenderer(glamour.WithAutoStyle(), glamour.WithWordWrap(width))
+	if err == nil {
+		if rendered, err := renderer.Render(content); err == nil {
+			return strings.Split(strings.TrimRight(rendered, "\n "), "\n")
+		}
+	}
+	return wrapText(content, width)
+}
+
+// detailDiffLines diffs the task's worktree, or its directory, against the default branch
+func detailDiffLines(t *task.Task) []string {
+	dir := t.WorktreePath
+	if dir == "" {
+		dir = t.Cwd
+	}
+	if dir == "" || !git.IsGitRepo(dir) {
+		return []string{"Task is not in a git repository"}
+	}
+	repoRoot := t.RepoRoot
+	if repoRoot == "" {
+		root, err := git.GetMainRepoRoot(dir)
+		if err != nil {
+			return []string{err.Error()}
+		}
+		repoRoot = root
+	}
+
+	diff, err := git.DiffFromDefault(repoRoot, dir)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if diff == "" {
+		return []string{"No changes against the default branch"}
+	}
+	return strings.Split(strings.TrimRight(diff, "\n"), "\n")
+}
+
+// detailOutputLines returns the cleaned tail of the task's transcript
+func (m Model) detailOutputLines(t *task.Task) []string {
+	data, err := readTail(m.config.TranscriptPath(t.ID), outputTailBytes)
+	if os.IsNotExist(err) {
+		return []string{"No output captured yet"}
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("Error reading output: %v", err)}
+	}
+	lines := cleanTranscript(data)
+	if len(lines) == 0 {
+		return []string{"No output captured yet"}
+	}
+	return lines
+}
+
+// detailHistoryLines lists the task's status changes and how long each lasted
+func detailHistoryLines(t *task.Task, timeFormat string) []string {
+	if len(t.History) == 0 {
+		return []string{"No status changes recorded"}
+	}
+	lines := make([]string, 0, len(t.History))
+	for i, change := range t.History {
+		var lasted string
+		switch {
+		case i < len(t.History)-1:
+			lasted = formatDuration(t.History[i+1].At.Sub(change.At))
+		case change.Status != task.StatusDone:
+			lasted = formatDuration(time.Since(change.At)) + " so far"
+		}
+		lines = append(lines, fmt.Sprintf("%s  %-8s %s", timefmt.Format(change.At, timeFormat), change.Status, lasted))
+	}
+	return lines
+}
+
+// detailInfoLines lists the task's metadata, skipping fields that are not set
+func (m Model) detailInfoLines(t *task.Task) []string {
+	agent := t.Agent
+	if agent == "" {
+		agent = m.config.DefaultAgent
+		if agent == "" {
+			agent = config.DefaultAgentName
+		}
+		agent += " (default)"
+	}
+	fields := [][2]string{
+		{"ID", t.ID},
+		{"Name", t.Name},
+		{"Status", string(t.Status)},
+		{"Waiting for", string(t.WaitReason)},
+		{"Time spent", detailTimeSpent(t)},
+		{"Agent", agent},
+		{"Agent process", m.detailAgentProcess(t)},
+		{"Permissions", string(t.Permission)},
+		{"Template", t.Template},
+		{"Directory", t.Cwd},
+		{"Project", t.Project},
+		{"Worktree", t.WorktreePath},
+		{"Branch", t.GitBranch},
+		{"Repository", t.RepoRoot},
+		{"Tab", t.TabName},
+		{"Prompt file", t.PromptFile},
+		{"Transcript", m.config.TranscriptPath(t.ID)},
+		{"Notes", t.NotesPath()},
+		{"Depends on", strings.Join(t.DependsOn, ", ")},
+		{"Chain mode", string(t.ChainMode)},
+		{"Handoff", detailHandoff(t)},
+		{"Review of", t.ReviewOf},
+		{"Reviews", m.detailReviews(t)},
+		{"Final message", truncate(t.FinalMessage, m.detailWidth()-14)},
+		{"Schedule", t.Schedule},
+		{"Auto-nudge", detailNudge(t)},
+		{"Setup", t.Setup},
+		{"Teardown", t.Teardown},
+		{"Hook error", t.HookError},
+		{"Attachments", strings.Join(t.Attachments, ", ")},
+		{"Pull request", t.PRURL},
+		{"Merge", m.detailReadiness(t)},
+		{"Created", m.formatTime(t.CreatedAt)},
+		{"Updated", m.formatTime(t.UpdatedAt)},
+	}
+	if t.NextRun != nil {
+		fields = append(fields, [2]string{"Next run", m.formatTime(*t.NextRun)})
+	}
+	if t.CompletedAt != nil {
+		fields = append(fields, [2]string{"Completed", m.formatTime(*t.CompletedAt)})
+	}
+	if t.MergedAt != nil {
+		fields = append(fields, [2]string{"Merged", m.formatTime(*t.MergedAt)})
+		fields = append(fields, [2]string{"Merge commit", t.MergeCommit})
+	}
+
+	// Long values such as paths wrap under the value column rather than being cut off
+	valueWidth := m.detailWidth() - 14
+	var lines []string
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		for i, part := range splitRunes(field[1], valueWidth) {
+			label := field[0]
+			if i > 0 {
+				label = ""
+			}
+			lines = append(lines, fmt.Sprintf("%-13s %s", label, part))
+		}
+	}
+	return lines
+}
+
+// formatTime formats a timestamp in the local time zone, or as ISO 8601 if configured
+func (m Model) formatTime(at time.Time) string {
+	return timefmt.Format(at, m.config.TimeFormat)
+}
+
+// splitRunes cuts s into pieces of at most width runes
+func splitRunes(s string, width int) []string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return []string{s}
+	}
+	var parts []string
+	for len(runes) > width {
+		parts = append(parts, string(runes[:width]))
+		runes = runes[width:]
+	}
+	return append(parts, string(runes))
+}
+
+// detailTimeSpent totals how long the agent has worked and waited for input
+func detailTimeSpent(t *task.Task) string {
+	working, waiting := t.TimeSpent(time.Now())
+	if working == 0 && waiting == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s working, %s waiting", task.FormatSpent(working), task.FormatSpent(waiting))
+}
+
+// detailHandoff says whether the task gets its dependencies' work handed over
+func detailHandoff(t *task.Task) string {
+	if !t.Handoff {
+		return ""
+	}
+	return "from " + strings.Join(t.DependsOn, ", ")
+}
+
+// detailReviews lists a task's reviewer tasks with their status
+func (m Model) detailReviews(t *task.Task) string {
+	var reviews []string
+	for _, r := range m.tasks.Reviews(t.ID) {
+		reviews = append(reviews, fmt.Sprintf("%s (%s)", r.ID, r.Status))
+	}
+	return strings.Join(reviews, ", ")
+}
+
+// detailNudge describes a task's auto-nudge setting and how many nudges were sent
+func detailNudge(t *task.Task) string {
+	switch {
+	case t.AutoNudge && t.Nudges > 0:
+		return fmt.Sprintf("on (%d sent)", t.Nudges)
+	case t.AutoNudge:
+		return "on"
+	case t.Nudges > 0:
+		return fmt.Sprintf("off (%d sent)", t.Nudges)
+	}
+	return ""
+}
+
+// detailWidth is the width available for the detail view's content
+func (m Model) detailWidth() int {
+	width := m.width - 6
+	if width < 20 {
+		width = 20
+	}
+	return width
+}
+
+// detailPageSize is how many content lines fit below the tab bar
+func (m Model) detailPageSize() int {
+	size := m.height - 9
+	if size < 3 {
+		size = 3
+	}
+	return size
+}
+
+// maxDetailScroll is the scroll offset that shows the last page
+func (m Model) maxDetailScroll() int {
+	if max := len(m.detailLines) - m.detailPageSize(); max > 0 {
+		return max
+	}
+	return 0
+}
+
+// scrollDetail moves the detail view by delta lines
+func (m *Model) scrollDetail(delta int) {
+	m.detailScroll += delta
+	if max := m.maxDetailScroll(); m.detailScroll > max {
+		m.detailScroll = max
+	}
+	if m.detailScroll < 0 {
+		m.detailScroll = 0
+	}
+}
+
+// updateDetail handles detail view input
+func (m Model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q", "i":
+		m.mode = viewDashboard
+		m.detailLines = nil
+		m.detailDiff = nil
+
+	case "tab", "l", "right":
+		return m, m.switchDetailTab(m.detailTab + 1)
+
+	case "shift+tab", "h", "left":
+		return m, m.switchDetailTab(m.detailTab - 1)
+
+	case "1", "2", "3", "4", "5", "6":
+		return m, m.switchDetailTab(int(msg.String()[0] - '1'))
+
+	case "r":
+		// Reload, e.g. to pick up new output or changes
+		return m, m.loadDetail()
+
+	case "y":
+		// Copy the full path of the task's working directory, which the table shortens
+		if t, ok := m.tasks.Get(m.detailTaskID); ok {
+			m.yankPath(t.WorkDir())
+		}
+
+	case "j", "down":
+		m.scrollDetail(1)
+
+	case "k", "up":
+		m.scrollDetail(-1)
+
+	case "ctrl+d", "pgdown":
+		m.scrollDetail(m.detailPageSize() / 2)
+
+	case "ctrl+u", "pgup":
+		m.scrollDetail(-m.detailPageSize() / 2)
+
+	case "g", "home":
+		m.detailScroll = 0
+
+	case "G", "end":
+		m.detailScroll = m.maxDetailScroll()
+	}
+
+	return m, nil
+}
+
+// viewDetail renders the tab bar and the current tab of the detail view
+func (m Model) viewDetail() string {
+	var b strings.Builder
+
+	title := "Task"
+	if t, ok := m.tasks.Get(m.detailTaskID); ok {
+		title = fmt.Sprintf("Task %s: %s", t.ID, t.Name)
+	}
+
+	tabs := make([]string, 0, detailTabCount)
+	for i, name := range detailTabNames {
+		label := fmt.Sprintf(" %d %s ", i+1, name)
+		if i == m.detailTab {
+			tabs = append(tabs, selectedRowStyle.Bold(true).Render(label))
+		} else {
+			tabs = append(tabs, lipgloss.NewStyle().Foreground(colorSecondary).Render(label))
+		}
+	}
+	b.WriteString(strings.Join(tabs, " "))
+	b.WriteString("\n\n")
+
+	width := m.detailWidth()
+	end := m.detailScroll + m.detailPageSize()
+	if end > len(m.detailLines) {
+		end = len(m.detailLines)
+	}
+	for i := m.detailScroll; i < end; i++ {
+		line := m.detailLines[i]
+		switch m.detailTab {
+		case detailPrompt, detailNotes:
+			// Glamour output is already wrapped and styled
+		case detailDiff:
+			line = m.detailDiff.renderLine(i, width)
+		default:
+			line = truncateRunes(line, width)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	if len(m.detailLines) > m.detailPageSize() {
+		title = fmt.Sprintf("%s (%d-%d of %d)", title, m.detailScroll+1, end, len(m.detailLines))
+	}
+	panel := m.renderPanel(title, b.String(), m.width, m.height-1, true)
+	help := helpStyle.Render("[tab/h/l]switch tab  [1-6]jump to tab  [j/k]scroll  [ctrl+d/u]page  [g/G]top/bottom  [r]eload  [y]ank path  [esc]back")
+	return lipgloss.JoinVertical(lipgloss.Left, panel, help)
+}
+
+// truncateRunes cuts s to at most max runes, keeping multi-byte characters intact
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,343 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// diffPager holds a unified diff for scrolling, with code lines highlighted by file type
+type diffPager struct {
+	lines  []string
+	lexers []chroma.Lexer // Lexer of the file each line belongs to (nil for headers and unknown types)
+	files  []int          // Index of each file's "diff --git" line
+	style  *chroma.Style
+	scroll int
+
+	// Lines already colored by an external renderer (diff.pager or diff.external)
+	rendered bool
+
+	// Rendered lines for the current width; highlighting every frame would be wasteful
+	cache      map[int]string
+	cacheWidth int
+}
+
+// newDiffPager splits a diff into lines and picks a lexer for each file in it
+func newDiffPager(diff string) *diffPager {
+	p := &diffPager{style: styles.Get("monokai"), cache: make(map[int]string)}
+	if !lipgloss.HasDarkBackground() {
+		p.style = styles.Get("monokailight")
+	}
+	diff = strings.TrimRight(diff, "\n")
+	if diff == "" {
+		return p
+	}
+
+	p.lines = strings.Split(diff, "\n")
+	p.lexers = make([]chroma.Lexer, len(p.lines))
+	var lexer chroma.Lexer
+	for i, line := range p.lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			p.files = append(p.files, i)
+			lexer = nil
+			if fields := strings.Fields(line); len(fields) == 4 {
+				if l := lexers.Match(strings.TrimPrefix(fields[3], "b/")); l != nil {
+					lexer = chroma.Coalesce(l)
+				}
+			}
+			continue
+		}
+		p.lexers[i] = lexer
+	}
+	return p
+}
+
+// newRenderedDiffPager holds a diff colored by an external renderer, shown as is. Its file
+// headers vary by tool, so jumping between files isn't available.
+func newRenderedDiffPager(output string) *diffPager {
+	p := &diffPager{rendered: true, cache: make(map[int]string)}
+	if output = strings.TrimRight(output, "\n"); output != "" {
+		p.lines = strings.Split(output, "\n")
+	}
+	return p
+}
+
+// empty reports whether the diff has no changes
+func (p *diffPager) empty() bool {
+	return len(p.lines) == 0
+}
+
+// scrollBy moves the view by delta lines, keeping a page of lines in view
+func (p *diffPager) scrollBy(delta, height int) {
+	p.scroll += delta
+	if max := len(p.lines) - height; p.scroll > max {
+		p.scroll = max
+	}
+	if p.scroll < 0 {
+		p.scroll = 0
+	}
+}
+
+// jumpFile scrolls to the next (step 1) or previous (step -1) file's header
+func (p *diffPager) jumpFile(step, height int) {
+	if step > 0 {
+		for _, start := range p.files {
+			if start > p.scroll {
+				p.scrollBy(start-p.scroll, height)
+				return
+			}
+		}
+		return
+	}
+	for i := len(p.files) - 1; i >= 0; i-- {
+		if p.files[i] < p.scroll {
+			p.scrollBy(p.files[i]-p.scroll, height)
+			return
+		}
+	}
+}
+
+// currentFile returns the 1-based number of the file at the top of the view
+func (p *diffPager) currentFile() int {
+	current := 0
+	for i, start := range p.files {
+		if start <= p.scroll {
+			current = i + 1
+		}
+	}
+	return current
+}
+
+// render returns height lines starting at the scroll position, cut to width
+func (p *diffPager) render(width, height int) string {
+	if p.cacheWidth != width {
+		p.cache = make(map[int]string)
+		p.cacheWidth = width
+	}
+	end := p.scroll + height
+	if end > len(p.lines) {
+		end = len(p.lines)
+	}
+
+	var b strings.Builder
+	for i := p.scroll; i < end; i++ {
+		line, ok := p.cache[i]
+		if !ok {
+			line = p.renderLine(i, width)
+			p.cache[i] = line
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderLine colors one diff line: headers as in plain diffs, code by its file's syntax
+func (p *diffPager) renderLine(i, width int) string {
+	if p.rendered {
+		// Cut by visible width, keeping the renderer's escape sequences intact
+		return lipgloss.NewStyle().MaxWidth(width).Render(strings.ReplaceAll(p.lines[i], "\t", "    "))
+	}
+	line := truncateRunes(strings.ReplaceAll(p.lines[i], "\t", "    "), width)
+	lexer := p.lexers[i]
+	if lexer == nil || line == "" || strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+		return renderDiffLine(line)
+	}
+
+	marker, code := line[:1], line[1:]
+	var markerStyle lipgloss.Style
+	switch marker {
+	case "+":
+		markerStyle = lipgloss.NewStyle().Foreground(colorSuccess).Bold(true)
+	case "-":
+		markerStyle = lipgloss.NewStyle().Foreground(colorError).Bold(true)
+	case " ":
+		markerStyle = lipgloss.NewStyle()
+	default:
+		// "\ No newline at end of file" and other notes
+		return renderDiffLine(line)
+	}
+	return markerStyle.Render(marker) + p.highlight(lexer, code)
+}
+
+// highlight colors a line of code with the pager's style
+// Lines are highlighted one at a time, so constructs spanning lines (block comments,
+// multi-line strings) may be colored as code.
+func (p *diffPager) highlight(lexer chroma.Lexer, code string) string {
+	tokens, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return code
+	}
+	var b strings.Builder
+	for _, token := range tokens.Tokens() {
+		text := strings.TrimSuffix(token.Value, "\n")
+		entry := p.style.Get(token.Type)
+		style := lipgloss.NewStyle()
+		if entry.Colour.IsSet() {
+			style = style.Foreground(lipgloss.Color(entry.Colour.String()))
+		}
+		if entry.Bold == chroma.Yes {
+			style = style.Bold(true)
+		}
+		b.WriteString(style.Render(text))
+	}
+	return b.String()
+}
+
+// diffLoadedMsg carries a task's branch diff, read in the background
+type diffLoadedMsg struct {
+	op       int
+	taskID   string
+	diff     string
+	rendered bool // Output of the external diff tool rather than a plain diff
+	notices  []notice
+	err      error
+}
+
+// openDiffViewer shows what merging the task's branch would bring into the default branch,
+// rendered by the configured external diff tool if there is one. The diff is read in the
+// background and the viewer opens once it arrives.
+func (m *Model) openDiffViewer(t *task.Task) tea.Cmd {
+	if t.GitBranch == "" || t.RepoRoot == "" {
+		m.addMessage(fmt.Sprintf("%s has no branch to diff", t.Name), true)
+		return nil
+	}
+	op := m.startOperation(fmt.Sprintf("Reading diff of %s", t.GitBranch))
+	taskID, repoRoot, branch := t.ID, t.RepoRoot, t.GitBranch
+	diffConfig, width := m.config.Diff, m.width-6
+	return func() tea.Msg {
+		msg := diffLoadedMsg{op: op, taskID: taskID}
+		if diffConfig.Enabled() {
+			output, err := git.RenderBranchDiff(repoRoot, branch, diffConfig.Pager, diffConfig.External, width)
+			if err == nil {
+				msg.diff, msg.rendered = output, true
+				return msg
+			}
+			msg.notices = append(msg.notices, notice{fmt.Sprintf("Diff renderer failed, using built-in colors: %v", err), true})
+		}
+		msg.diff, msg.err = git.BranchDiff(repoRoot, branch)
+		return msg
+	}
+}
+
+// showDiff opens the viewer on a diff read by openDiffViewer, unless another view has
+// been opened while it was read
+func (m *Model) showDiff(msg diffLoadedMsg) {
+	m.finishOperation(msg.op)
+	m.addNotices(msg.notices)
+	if msg.err != nil {
+		m.addMessage(msg.err.Error(), true)
+		return
+	}
+	if m.mode != viewDashboard {
+		return
+	}
+	m.diffTaskID = msg.taskID
+	if msg.rendered {
+		m.diffPager = newRenderedDiffPager(msg.diff)
+	} else {
+		m.diffPager = newDiffPager(msg.diff)
+	}
+	m.mode = viewDiff
+}
+
+// renderStatLine colors the +/- bar of a `git diff --stat` line
+func renderStatLine(line string) string {
+	secondary := lipgloss.NewStyle().Foreground(colorSecondary)
+	i := strings.LastIndex(line, "|")
+	if i < 0 {
+		return secondary.Render(line)
+	}
+	head, bar := line[:i+1], line[i+1:]
+	counts := strings.TrimRight(bar, "+-")
+	plus := strings.Count(bar[len(counts):], "+")
+	minus := len(bar) - len(counts) - plus
+	return secondary.Render(head+counts) +
+		lipgloss.NewStyle().Foreground(colorSuccess).Render(strings.Repeat("+", plus)) +
+		lipgloss.NewStyle().Foreground(colorError).Render(strings.Repeat("-", minus))
+}
+
+// diffPageSize is how many diff lines fit in the viewer
+func (m Model) diffPageSize() int {
+	size := m.height - 7
+	if size < 3 {
+		size = 3
+	}
+	return size
+}
+
+// updateDiffViewer handles diff viewer input
+func (m Model) updateDiffViewer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := m.diffPageSize()
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q", "c":
+		m.mode = viewDashboard
+		m.diffPager = nil
+
+	case "j", "down":
+		m.diffPager.scrollBy(1, page)
+
+	case "k", "up":
+		m.diffPager.scrollBy(-1, page)
+
+	case "ctrl+d", "pgdown", " ":
+		m.diffPager.scrollBy(page/2, page)
+
+	case "ctrl+u", "pgup":
+		m.diffPager.scrollBy(-page/2, page)
+
+	case "g", "home":
+		m.diffPager.scroll = 0
+
+	case "G", "end":
+		m.diffPager.scrollBy(len(m.diffPager.lines), page)
+
+	case "n", "]":
+		m.diffPager.jumpFile(1, page)
+
+	case "N", "[":
+		m.diffPager.jumpFile(-1, page)
+
+	case "m":
+		// Done reviewing; go straight to the merge dialog
+		if t, ok := m.tasks.Get(m.diffTaskID); ok {
+			m.diffPager = nil
+			m.mode = viewDashboard
+			return m, m.openMergeConfirm(t)
+		}
+	}
+
+	return m, nil
+}
+
+// viewDiffViewer renders the diff pager
+func (m Model) viewDiffViewer() string {
+	title := "Changes"
+	if t, ok := m.tasks.Get(m.diffTaskID); ok {
+		title = fmt.Sprintf("Changes: %s (%s)", t.Name, t.GitBranch)
+	}
+
+	var content string
+	if m.diffPager.empty() {
+		content = lipgloss.NewStyle().Foreground(colorSecondary).Render("No changes to merge")
+	} else {
+		if files := len(m.diffPager.files); files > 0 {
+			title = fmt.Sprintf("%s - file %d of %d", title, m.diffPager.currentFile(), files)
+		}
+		content = m.diffPager.render(m.width-6, m.diffPageSize())
+	}
+
+	panel := m.renderPanel(title, content, m.width, m.height-1, true)
+	help := helpStyle.Render("[j/k]scroll  [ctrl+d/u]page  [n/N]next/prev file  [g/G]top/bottom  [m]erge  [esc]back")
+	return lipgloss.JoinVertical(lipgloss.Left, panel, help)
+}
@@ -1,9 +1,10 @@
 package tui
 
 import (
-	"os/exec"
 	"strconv"
 	"strings"
+
+	"github.com/dfowler/flock/internal/runner"
 )
 
 // GitStatus holds the current git repository status
@@ -17,11 +18,11 @@ type GitStatus struct {
 }
 
 // GetGitStatus returns the current git status for the working directory
-func GetGitStatus() *GitStatus {
+func GetGitStatus(r runner.Runner) *GitStatus {
 	status := &GitStatus{}
 
 	// Get current branch name
-	branch, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	branch, err := runner.Bind(r, "git", "rev-parse", "--abbrev-ref", "HEAD").Output()
 	if err != nil {
 		return nil // Not a git repo or git not available
 	}
@@ -29,13 +30,13 @@ func GetGitStatus() *GitStatus {
 
 	// Check for uncommitted changes (both staged and unstaged)
 	// git status --porcelain returns empty if clean
-	porcelain, _ := exec.Command("git", "status", "--porcelain").Output()
+	porcelain, _ := runner.Bind(r, "git", "status", "--porcelain").Output()
 	status.HasUncommitted = len(strings.TrimSpace(string(porcelain))) > 0
 
 	// Check ahead/behind status relative to upstream
 	// git rev-list --left-right --count @{upstream}...HEAD
 	// Returns "behind\tahead" (tab-separated)
-	revList, err := exec.Command("git", "rev-list", "--left-right", "--count", "@{upstream}...HEAD").Output()
+	revList, err := runner.Bind(r, "git", "rev-list", "--left-right", "--count", "@{upstream}...HEAD").Output()
 	if err == nil {
 		parts := strings.Fields(string(revList))
 		if len(parts) == 2 {
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// defaultHandoffGoal is the goal offered for the task a handoff creates
+const defaultHandoffGoal = "Review the changes, fix any problems you find and make sure the tests pass"
+
+// handoffRequest asks for a task that continues t's work once t is DONE, with t's changes and
+// final message in its prompt. In the same worktree it works on t's branch; otherwise it gets
+// a fresh worktree if t had one.
+func handoffRequest(t *task.Task, name, goal string, sameWorktree bool) daemon.Request {
+	req := daemon.Request{
+		Action:    daemon.ActionAdd,
+		Name:      name,
+		Cwd:       t.Cwd,
+		Prompt:    goal,
+		Agent:     t.Agent,
+		DependsOn: []string{t.ID},
+		Handoff:   true,
+	}
+	if sameWorktree && t.WorktreePath != "" {
+		req.ChainMode = task.ChainWorktree
+	} else {
+		req.UseWorktree = t.UseWorktree || t.WorktreePath != ""
+	}
+	return req
+}
+
+// openHandoff opens the handoff form for a task
+func (m *Model) openHandoff(t *task.Task) tea.Cmd {
+	m.mode = viewHandoff
+	m.handoffTaskID = t.ID
+	m.handoffSameWorktree = t.WorktreePath != ""
+	m.handoffNameInput.SetValue(t.Name + "-review")
+	m.handoffNameInput.CursorEnd()
+	m.handoffGoalInput.SetValue(defaultHandoffGoal)
+	m.handoffGoalInput.CursorEnd()
+	m.handoffNameInput.Blur()
+	m.handoffGoalInput.Focus()
+	return textinput.Blink
+}
+
+// closeHandoff returns to the dashboard
+func (m *Model) closeHandoff() {
+	m.mode = viewDashboard
+	m.handoffNameInput.Blur()
+	m.handoffGoalInput.Blur()
+}
+
+// updateHandoff handles handoff form input
+func (m Model) updateHandoff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.closeHandoff()
+		return m, nil
+
+	case "tab", "shift+tab", "up", "down":
+		if m.handoffNameInput.Focused() {
+			m.handoffNameInput.Blur()
+			m.handoffGoalInput.Focus()
+		} else {
+			m.handoffGoalInput.Blur()
+			m.handoffNameInput.Focus()
+		}
+		return m, textinput.Blink
+
+	case "ctrl+w":
+		m.handoffSameWorktree = !m.handoffSameWorktree
+		return m, nil
+
+	case "enter":
+		t, ok := m.tasks.Get(m.handoffTaskID)
+		if !ok {
+			m.closeHandoff()
+			return m, nil
+		}
+		name := strings.TrimSpace(m.handoffNameInput.Value())
+		if name == "" {
+			m.addMessage("The new task needs a name", true)
+			return m, nil
+		}
+		req := handoffRequest(t, name, strings.TrimSpace(m.handoffGoalInput.Value()), m.handoffSameWorktree)
+		next, err := m.addClone(req)
+		if err != nil {
+			// Keep the form open so the name can be fixed
+			m.addMessage(fmt.Sprintf("Failed to hand off %s: %v", t.Name, err), true)
+			return m, nil
+		}
+		m.closeHandoff()
+		if next.Status == task.StatusPending {
+			m.addMessage(fmt.Sprintf("%s will take over from %s once it is DONE", next.Name, t.Name), false)
+		} else {
+			m.addMessage(fmt.Sprintf("Handed %s over to %s", t.Name, next.Name), false)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if m.handoffNameInput.Focused() {
+		m.handoffNameInput, cmd = m.handoffNameInput.Update(msg)
+	} else {
+		m.handoffGoalInput, cmd = m.handoffGoalInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// viewHandoff renders the handoff form
+func (m Model) viewHandoff() string {
+	var b strings.Builder
+
+	t, ok := m.tasks.Get(m.handoffTaskID)
+	if !ok {
+		return m.viewDashboard()
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Hand off: %s", t.Name)))
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("Next task:"))
+	b.WriteString("\n")
+	b.WriteString(m.handoffNameInput.View())
+	b.WriteString("\n\n")
+	b.WriteString(inputLabelStyle.Render("Goal:"))
+	b.WriteString("\n")
+	b.WriteString(m.handoffGoalInput.View())
+	b.WriteString("\n\n")
+
+	secondary := lipgloss.NewStyle().Foreground(colorSecondary)
+	worktree := "[ ]"
+	if m.handoffSameWorktree {
+		worktree = "[x]"
+	}
+	b.WriteString(secondary.Render(fmt.Sprintf("%s Work in %s's worktree", worktree, t.Name)))
+	if t.WorktreePath == "" {
+		b.WriteString(secondary.Render(" (it has none)"))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(secondary.Render("Starts once " + t.Name + " is DONE, with its changes and final message in the prompt"))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("[tab]switch  [ctrl+w]worktree  [enter]create  [esc]cancel"))
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/prompt"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// snapshotPrompt records a version of a task's prompt file
+func (m *Model) snapshotPrompt(t *task.Task, label string) {
+	if t.PromptFile == "" {
+		return // legacy inline prompts have no file to version
+	}
+	if err := m.promptMgr.Snapshot(t.ID, t.PromptFile, label); err != nil {
+		m.addWarning(fmt.Sprintf("Prompt history warning: %v", err))
+	}
+}
+
+// openPromptHistory switches to the prompt history view for a task
+// The launch version is preselected, since "launch vs now" is the usual question
+func (m *Model) openPromptHistory(t *task.Task) {
+	versions, err := m.promptMgr.Versions(t.ID)
+	if err != nil {
+		m.addMessage(err.Error(), true)
+		return
+	}
+	if len(versions) == 0 {
+		m.addMessage(fmt.Sprintf("No prompt history for %s", t.Name), false)
+		return
+	}
+
+	m.historyTaskID = t.ID
+	m.historyVersions = versions
+	m.historySelected = len(versions) - 1
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Label == prompt.SnapshotLaunch {
+			m.historySelected = i
+			break
+		}
+	}
+	m.loadHistoryDiff()
+	m.mode = viewPromptHistory
+}
+
+// loadHistoryDiff diffs the selected version against the current prompt file
+func (m *Model) loadHistoryDiff() {
+	m.historyScroll = 0
+	t, ok := m.tasks.Get(m.historyTaskID)
+	if !ok {
+		m.historyDiff = ""
+		return
+	}
+	diff, err := m.promptMgr.DiffVersion(t.ID, m.historyVersions[m.historySelected], t.PromptFile)
+	if err != nil {
+		m.historyDiff = err.Error()
+		return
+	}
+	m.historyDiff = diff
+}
+
+// updatePromptHistory handles prompt history view input
+func (m Model) updatePromptHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q", "v":
+		m.mode = viewDashboard
+		m.historyVersions = nil
+		m.historyDiff = ""
+
+	case "j", "down":
+		if m.historySelected < len(m.historyVersions)-1 {
+			m.historySelected++
+			m.loadHistoryDiff()
+		}
+
+	case "k", "up":
+		if m.historySelected > 0 {
+			m.historySelected--
+			m.loadHistoryDiff()
+		}
+
+	case "ctrl+d", "pgdown":
+		m.historyScroll += 10
+		if max := strings.Count(m.historyDiff, "\n"); m.historyScroll > max {
+			m.historyScroll = max
+		}
+
+	case "ctrl+u", "pgup":
+		m.historyScroll -= 10
+		if m.historyScroll < 0 {
+			m.historyScroll = 0
+		}
+	}
+
+	return m, nil
+}
+
+// viewPromptHistory renders the list of prompt versions and the diff against the current prompt
+func (m Model) viewPromptHistory() string {
+	var b strings.Builder
+
+	title := "Prompt History"
+	if t, ok := m.tasks.Get(m.historyTaskID); ok {
+		title = fmt.Sprintf("Prompt History: %s", t.Name)
+	}
+
+	for i, v := range m.historyVersions {
+		line := fmt.Sprintf("v%-3d %-8s %s", v.Number, v.Label, m.formatTime(v.Time))
+		if i == m.historySelected {
+			line = selectedRowStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	selected := m.historyVersions[m.historySelected]
+	b.WriteString(tableHeaderStyle.Render(fmt.Sprintf("Changes since v%d (%s)", selected.Number, selected.Label)))
+	b.WriteString("\n")
+
+	if m.historyDiff == "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("Prompt is unchanged since this version."))
+	} else {
+		// Leave room for borders, the version list, headers and the help line
+		available := m.height - len(m.historyVersions) - 10
+		if available < 5 {
+			available = 5
+		}
+		lines := strings.Split(strings.TrimRight(m.historyDiff, "\n"), "\n")
+		end := m.historyScroll + available
+		if end > len(lines) {
+			end = len(lines)
+		}
+		for _, line := range lines[m.historyScroll:end] {
+			b.WriteString(renderDiffLine(line))
+			b.WriteString("\n")
+		}
+	}
+
+	panel := m.renderPanel(title, b.String(), m.width, m.height-1, true)
+	help := helpStyle.Render("[j/k]select version  [ctrl+d/u]scroll  [esc]back")
+	return lipgloss.JoinVertical(lipgloss.Left, panel, help)
+}
+
+// renderDiffLine colors a unified diff line
+func renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+		return lipgloss.NewStyle().Foreground(colorSecondary).Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return lipgloss.NewStyle().Foreground(colorPrimary).Render(line)
+	case strings.HasPrefix(line, "+"):
+		return lipgloss.NewStyle().Foreground(colorSuccess).Render(line)
+	case strings.HasPrefix(line, "-"):
+		return lipgloss.NewStyle().Foreground(colorError).Render(line)
+	default:
+		return line
+	}
+}
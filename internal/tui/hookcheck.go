@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/pathfmt"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// HookScript is the installed status hook script (a *setup.Checker, set by main since
+// setup depends on this package)
+type HookScript interface {
+	GetHookPath() string
+	// HookOutdated reports whether the script differs from the one this flock ships
+	HookOutdated() bool
+	// VerifyHook runs the script with a sample prompt and checks the status file it writes
+	VerifyHook(commands runner.Runner) error
+	// UpgradeHookScript installs this flock's script, returning where the old one was kept
+	UpgradeHookScript() (string, error)
+}
+
+// hookCheckedMsg carries the result of running the hook script with a sample prompt
+type hookCheckedMsg struct {
+	op       int
+	manual   bool // Asked for from settings rather than made on start
+	path     string
+	outdated bool // The script differs from the one this flock ships
+	elapsed  time.Duration
+	err      error
+}
+
+// checkHooks returns a command that runs the installed hook script with a sample prompt
+// and checks it writes a status file, and whether it is the script this flock ships.
+// Without agents that report through the Claude hook there is nothing to check.
+func (m Model) checkHooks(op int, manual bool) tea.Cmd {
+	if m.hookScript == nil || !m.config.UsesStatusHook(config.StatusHookClaude) {
+		return nil
+	}
+	r, hooks := m.commands, m.hookScript
+	return func() tea.Msg {
+		msg := hookCheckedMsg{op: op, manual: manual, path: hooks.GetHookPath()}
+		if _, err := os.Stat(msg.path); err != nil {
+			msg.err = errors.New("the script is not installed; restart flock to install it")
+			return msg
+		}
+		msg.outdated = hooks.HookOutdated()
+		start := time.Now()
+		msg.err = hooks.VerifyHook(r)
+		msg.elapsed = time.Since(start)
+		return msg
+	}
+}
+
+// verifyHooks checks the hook script from settings, showing the check as running
+func (m *Model) verifyHooks() tea.Cmd {
+	if m.hookScript == nil || !m.config.UsesStatusHook(config.StatusHookClaude) {
+		m.addMessage("No agent reports status through the Claude Code hook", false)
+		return nil
+	}
+	op := m.startOperation("Checking the hook script")
+	return m.checkHooks(op, true)
+}
+
+// hookChecked reports a hook check, offering to upgrade a script that differs from this
+// flock's. The offer waits for the dashboard if another view is open.
+func (m *Model) hookChecked(msg hookCheckedMsg) {
+	m.finishOperation(msg.op)
+	switch {
+	case msg.err != nil:
+		m.addMessage(fmt.Sprintf("Hook script check failed: %v (run `flock hooks test -v` for details)", msg.err), true)
+	case msg.manual && !msg.outdated:
+		m.addMessage(fmt.Sprintf("Hook script works: it wrote a status file in %s", msg.elapsed.Round(time.Millisecond)), false)
+	}
+	if !msg.outdated {
+		return
+	}
+	if m.mode != viewDashboard && m.mode != viewSettings {
+		m.addWarning("The hook script differs from this flock's; press S and choose Verify hooks to upgrade it")
+		return
+	}
+	m.hookUpgrade = &msg
+	m.mode = viewConfirmHookUpgrade
+}
+
+// updateConfirmHookUpgrade handles the hook upgrade dialog
+func (m Model) updateConfirmHookUpgrade(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m.hookUpgrade = nil
+		m.mode = viewDashboard
+		old, err := m.hookScript.UpgradeHookScript()
+		if err != nil {
+			m.addMessage(fmt.Sprintf("Failed to upgrade the hook script: %v", err), true)
+			return m, nil
+		}
+		m.addMessage(fmt.Sprintf("Upgraded the hook script; the old one is in %s", pathfmt.Shorten(old, 50)), false)
+		// Make sure the new script works too
+		return m, m.verifyHooks()
+
+	case "n", "N", "esc":
+		m.hookUpgrade = nil
+		m.mode = viewDashboard
+		m.addMessage("Kept the hook script as is; press S and choose Verify hooks to upgrade it later", false)
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// viewConfirmHookUpgrade renders the hook upgrade dialog
+func (m Model) viewConfirmHookUpgrade() string {
+	if m.hookUpgrade == nil {
+		return m.viewDashboard()
+	}
+	var b strings.Builder
+	muted := lipgloss.NewStyle().Foreground(colorSecondary)
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorWarning).Render("Hook Script Outdated"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("%s is not the hook script\n", pathfmt.Shorten(m.hookUpgrade.path, 50)))
+	b.WriteString("this version of flock ships: another version wrote it,\n")
+	b.WriteString("or it was edited since.\n")
+	if m.hookUpgrade.err != nil {
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(colorError).Render(truncate("It failed the check: "+m.hookUpgrade.err.Error(), 70)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString("Upgrade it in place?\n")
+	b.WriteString(muted.Render(fmt.Sprintf("  The old script is kept as %s.old\n", filepath.Base(m.hookUpgrade.path))))
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("[y/enter]upgrade  [n/esc]keep"))
+	return m.centerContent(modalStyle.Render(b.String()))
+}
@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// teardownDoneMsg is sent when a task's teardown command has finished
+type teardownDoneMsg struct {
+	note   string
+	failed bool
+}
+
+// recordSetupFailure flags a task whose setup command failed, so the dashboard shows it
+func (m *Model) recordSetupFailure(t *task.Task, message string) {
+	if err := m.server.RecordSetupFailure(t.ID, message); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to record setup failure of %s: %v", t.Name, err), true)
+	}
+}
+
+// runTeardown runs a task's teardown command in the background, if it has one
+func (m *Model) runTeardown(t *task.Task) tea.Cmd {
+	if strings.TrimSpace(t.Teardown) == "" {
+		return nil
+	}
+	server := m.server
+	id := t.ID
+	m.addMessage(fmt.Sprintf("Running teardown of %s", t.Name), false)
+	return func() tea.Msg {
+		note, failed := server.RunTeardown(id)
+		return teardownDoneMsg{note: note, failed: failed}
+	}
+}
+
+// hookBadge marks a task whose setup or teardown command failed in the task list
+func hookBadge(t *task.Task) string {
+	switch {
+	case t.HookError == "":
+		return ""
+	case strings.HasPrefix(t.HookError, "Teardown"):
+		return " (teardown failed)"
+	default:
+		return " (setup failed)"
+	}
+}
+
+// openHooks opens the setup and teardown form for a task
+func (m *Model) openHooks(t *task.Task) tea.Cmd {
+	m.mode = viewHooks
+	m.hooksTaskID = t.ID
+	m.setupInput.SetValue(t.Setup)
+	m.setupInput.CursorEnd()
+	m.teardownInput.SetValue(t.Teardown)
+	m.teardownInput.CursorEnd()
+	m.setupInput.Focus()
+	m.teardownInput.Blur()
+	return textinput.Blink
+}
+
+// updateHooks handles setup and teardown form input
+func (m Model) updateHooks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.mode = viewDashboard
+		m.setupInput.Blur()
+		m.teardownInput.Blur()
+		return m, nil
+
+	case "tab", "shift+tab", "up", "down":
+		if m.setupInput.Focused() {
+			m.setupInput.Blur()
+			m.teardownInput.Focus()
+		} else {
+			m.teardownInput.Blur()
+			m.setupInput.Focus()
+		}
+		return m, textinput.Blink
+
+	case "enter":
+		setup := strings.TrimSpace(m.setupInput.Value())
+		teardown := strings.TrimSpace(m.teardownInput.Value())
+		err := m.tasks.Update(m.hooksTaskID, func(t *task.Task) {
+			t.Setup = setup
+			t.Teardown = teardown
+		})
+		m.mode = viewDashboard
+		m.setupInput.Blur()
+		m.teardownInput.Blur()
+		if err != nil {
+			m.addMessage(err.Error(), true)
+			return m, nil
+		}
+		if t, ok := m.tasks.Get(m.hooksTaskID); ok {
+			note := fmt.Sprintf("Saved setup and teardown of %s", t.Name)
+			if t.Status != task.StatusPending && setup != "" {
+				note += "; setup runs the next time it starts"
+			}
+			m.addMessage(note, false)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if m.setupInput.Focused() {
+		m.setupInput, cmd = m.setupInput.Update(msg)
+	} else {
+		m.teardownInput, cmd = m.teardownInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// viewHooks renders the setup and teardown form
+func (m Model) viewHooks() string {
+	var b strings.Builder
+
+	name := m.hooksTaskID
+	if t, ok := m.tasks.Get(m.hooksTaskID); ok {
+		name = t.Name
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Setup and teardown: %s", name)))
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("Before the agent starts:"))
+	b.WriteString("\n")
+	b.WriteString(m.setupInput.View())
+	b.WriteString("\n\n")
+	b.WriteString(inputLabelStyle.Render("Once the task is DONE:"))
+	b.WriteString("\n")
+	b.WriteString(m.teardownInput.View())
+	b.WriteString("\n\n")
+
+	secondary := lipgloss.NewStyle().Foreground(colorSecondary)
+	b.WriteString(secondary.Render("Commands run with sh in the task's worktree; output goes to ~/.flock/logs/tasks"))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("[tab]switch  [enter]save  [esc]cancel  (empty clears)"))
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
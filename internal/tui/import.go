@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/batch"
+	"github.com/dfowler/flock/internal/daemon"
+)
+
+// openImport opens the batch import form
+func (m *Model) openImport() tea.Cmd {
+	m.mode = viewImport
+	m.importInput.Focus()
+	return textinput.Blink
+}
+
+// updateImport handles batch import form input
+func (m Model) updateImport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.mode = viewDashboard
+		m.importInput.Blur()
+		return m, nil
+
+	case "enter":
+		path := strings.TrimSpace(m.importInput.Value())
+		if path == "" {
+			return m, nil
+		}
+		entries, err := batch.Load(path)
+		if err == nil {
+			var requests []daemon.Request
+			if requests, err = batch.Requests(entries, m.config.UseWorktree, m.config.AutoStartTasks); err == nil {
+				m.importTasks(requests)
+			}
+		}
+		if err != nil {
+			// Keep the form open so the path or file can be fixed
+			m.addMessage(err.Error(), true)
+			return m, nil
+		}
+		m.mode = viewDashboard
+		m.importInput.Blur()
+		m.importInput.Reset()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.importInput, cmd = m.importInput.Update(msg)
+	return m, cmd
+}
+
+// importTasks creates the tasks of an import file the same way the daemon does
+func (m *Model) importTasks(requests []daemon.Request) {
+	created, err := batch.Import(requests, m.server.Handle)
+	if err != nil {
+		m.addMessage(err.Error(), true)
+	}
+	if len(created) > 0 {
+		m.addMessage(fmt.Sprintf("Imported %d of %d tasks", len(created), len(requests)), false)
+		m.selectTask(created[len(created)-1].ID)
+	}
+}
+
+// viewImport renders the batch import form
+func (m Model) viewImport() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Import Tasks"))
+	b.WriteString("\n\n")
+	b.WriteString(inputLabelStyle.Render("YAML or JSON file:"))
+	b.WriteString("\n")
+	b.WriteString(m.importInput.View())
+	b.WriteString("\n\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("Each task may set name, cwd, prompt, template, agent, depends_on and chain."))
+	b.WriteString("\n\n")
+
+	help := helpStyle.Render("[enter]import  [esc]cancel")
+	b.WriteString(help)
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
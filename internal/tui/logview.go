@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/flocklog"
+	"github.com/dfowler/flock/internal/pathfmt"
+)
+
+// logLevels are the levels the log view's filter cycles through
+var logLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// openLogView shows flock's recent log records, newest at the bottom
+func (m *Model) openLogView() {
+	m.mode = viewLog
+	m.loadLogView()
+}
+
+// loadLogView reads the records at or above the view's level and scrolls to the newest
+func (m *Model) loadLogView() {
+	m.logRecords = flocklog.Recent(m.logLevel)
+	m.logScroll = m.maxLogScroll()
+}
+
+// cycleLogLevel shows only records at the next level up, wrapping around to everything
+func (m *Model) cycleLogLevel() {
+	for i, level := range logLevels {
+		if level == m.logLevel {
+			m.logLevel = logLevels[(i+1)%len(logLevels)]
+			return
+		}
+	}
+	m.logLevel = logLevels[0]
+}
+
+// logPageSize is how many records fit in the log view
+func (m Model) logPageSize() int {
+	size := m.height - 7
+	if size < 3 {
+		size = 3
+	}
+	return size
+}
+
+// maxLogScroll is the scroll offset that shows the newest records
+func (m Model) maxLogScroll() int {
+	return max(len(m.logRecords)-m.logPageSize(), 0)
+}
+
+// scrollLog moves the log view by delta records, staying within the records
+func (m *Model) scrollLog(delta int) {
+	m.logScroll = min(max(m.logScroll+delta, 0), m.maxLogScroll())
+}
+
+// updateLogView handles log view input
+func (m Model) updateLogView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q", "l":
+		m.mode = viewDashboard
+		m.logRecords = nil
+
+	case "f":
+		m.cycleLogLevel()
+		m.loadLogView()
+
+	case "r":
+		// Pick up records logged since the view was opened
+		m.loadLogView()
+
+	case "y":
+		m.yankPath(m.config.LogPath())
+
+	case "j", "down":
+		m.scrollLog(1)
+
+	case "k", "up":
+		m.scrollLog(-1)
+
+	case "ctrl+d", "pgdown":
+		m.scrollLog(m.logPageSize() / 2)
+
+	case "ctrl+u", "pgup":
+		m.scrollLog(-m.logPageSize() / 2)
+
+	case "g", "home":
+		m.logScroll = 0
+
+	case "G", "end":
+		m.logScroll = m.maxLogScroll()
+	}
+	return m, nil
+}
+
+// viewLogView renders the recent log records, one per line
+func (m Model) viewLogView() string {
+	var b strings.Builder
+	width := m.width - 6
+	end := min(m.logScroll+m.logPageSize(), len(m.logRecords))
+	if len(m.logRecords) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("Nothing logged at this level since flock started"))
+		b.WriteString("\n")
+	}
+	for _, r := range m.logRecords[m.logScroll:end] {
+		b.WriteString(renderLogRecord(r, width))
+		b.WriteString("\n")
+	}
+
+	title := fmt.Sprintf("Log: %s (%s and above)", pathfmt.Shorten(m.config.LogPath(), 40), strings.ToLower(m.logLevel.String()))
+	if len(m.logRecords) > m.logPageSize() {
+		title = fmt.Sprintf("%s (%d-%d of %d)", title, m.logScroll+1, end, len(m.logRecords))
+	}
+	panel := m.renderPanel(title, b.String(), m.width, m.height-1, true)
+	help := helpStyle.Render("[j/k]scroll  [ctrl+d/u]page  [g/G]top/bottom  [f]ilter level  [r]eload  [y]ank path  [esc]back")
+	return lipgloss.JoinVertical(lipgloss.Left, panel, help)
+}
+
+// renderLogRecord renders a record as time, level, message and attributes, colored by level
+func renderLogRecord(r flocklog.Record, width int) string {
+	levelStyle := lipgloss.NewStyle()
+	switch {
+	case r.Level >= slog.LevelError:
+		levelStyle = levelStyle.Foreground(colorError)
+	case r.Level >= slog.LevelWarn:
+		levelStyle = levelStyle.Foreground(colorWarning)
+	case r.Level < slog.LevelInfo:
+		levelStyle = levelStyle.Foreground(colorSecondary)
+	}
+	secondary := lipgloss.NewStyle().Foreground(colorSecondary)
+
+	head := fmt.Sprintf("%s %-5s ", r.Time.Format("15:04:05"), r.Level)
+	text := r.Message
+	if r.Attrs != "" {
+		text += " " + r.Attrs
+	}
+	text = truncateRunes(text, max(width-len(head), 0))
+	message, attrs := text, ""
+	if len(text) > len(r.Message) {
+		message, attrs = text[:len(r.Message)], text[len(r.Message):]
+	}
+	return secondary.Render(head[:9]) + levelStyle.Render(head[9:]+message) + secondary.Render(attrs)
+}
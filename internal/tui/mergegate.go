@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/gate"
+	"github.com/dfowler/flock/internal/runner"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// mergeGateTailLines is how much of a failed check's output the merge dialog shows
+const mergeGateTailLines = 8
+
+// mergeGateMsg is sent when the merge check of the task in the merge dialog has finished
+type mergeGateMsg struct {
+	taskID string
+	result gate.Result
+}
+
+// bulkGateMsg is sent when the merge checks of the marked tasks have finished
+type bulkGateMsg struct {
+	results map[string]gate.Result // By task ID
+}
+
+// mergeGateFunc returns a function running merge.require_command in a task's worktree,
+// saving the output to the task's log directory. It only captures values, so it is safe to
+// call from a tea.Cmd.
+func (m *Model) mergeGateFunc(t *task.Task) func() gate.Result {
+	command, dir, timeout := m.config.Merge.RequireCommand, t.WorkDir(), m.config.MergeCheckTimeout()
+	logPath := m.config.HookLogPath(t.ID, "merge-check")
+	return func() gate.Result {
+		return gate.Run(runner.Exec{}, command, dir, logPath, timeout)
+	}
+}
+
+// startMergeGate runs the merge check for the merge dialog in the background, if one is configured
+func (m *Model) startMergeGate(t *task.Task) tea.Cmd {
+	m.mergeGate = nil
+	m.mergeGateRunning = false
+	if strings.TrimSpace(m.config.Merge.RequireCommand) == "" {
+		return nil
+	}
+	m.mergeGateRunning = true
+	id, run := t.ID, m.mergeGateFunc(t)
+	return func() tea.Msg {
+		return mergeGateMsg{taskID: id, result: run()}
+	}
+}
+
+// mergeGateBlocks explains why the merge dialog can't merge yet, or returns "" if it can
+func (m Model) mergeGateBlocks() string {
+	switch {
+	case m.mergeGateRunning:
+		return fmt.Sprintf("Wait for %s to finish before merging", m.config.Merge.RequireCommand)
+	case m.mergeGate != nil && !m.mergeGate.Passed:
+		return fmt.Sprintf("Merge blocked: %s", m.mergeGate.Summary())
+	}
+	return ""
+}
+
+// checkMarked runs the merge check on each marked task with a branch, in the background
+func (m *Model) checkMarked() tea.Cmd {
+	tasks := m.mergeableMarked()
+	runs := make(map[string]func() gate.Result, len(tasks))
+	for _, t := range tasks {
+		runs[t.ID] = m.mergeGateFunc(t)
+	}
+	m.addMessage(fmt.Sprintf("Running %s for %d tasks before merging", m.config.Merge.RequireCommand, len(tasks)), false)
+	return func() tea.Msg {
+		results := make(map[string]gate.Result, len(runs))
+		for id, run := range runs {
+			results[id] = run()
+		}
+		return bulkGateMsg{results: results}
+	}
+}
+
+// viewMergeGate renders the merge check's progress or result for the merge dialog
+func (m Model) viewMergeGate() string {
+	var b strings.Builder
+	switch {
+	case m.mergeGateRunning:
+		b.WriteString(fmt.Sprintf("%s Running %s...\n", m.spinner.View(), m.config.Merge.RequireCommand))
+	case m.mergeGate == nil:
+		return ""
+	case m.mergeGate.Passed:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSuccess).Render(m.mergeGate.Summary()))
+		b.WriteString("\n")
+	default:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorError).Render(m.mergeGate.Summary()))
+		b.WriteString("\n")
+		muted := lipgloss.NewStyle().Foreground(colorSecondary)
+		for _, line := range m.mergeGate.Tail(mergeGateTailLines) {
+			b.WriteString(muted.Render("  " + truncateRunes(line, 70)))
+			b.WriteString("\n")
+		}
+		if m.mergeGate.LogPath != "" {
+			b.WriteString(muted.Render("Full output: " + m.mergeGate.LogPath))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// toggleNotes switches the right panel between the prompt and the agent's working notes
+func (m *Model) toggleNotes() {
+	m.showNotes = !m.showNotes
+	if m.showNotes && m.showOutput {
+		m.showOutput = false
+		m.outputGen++ // stop the output refresh loop
+	}
+}
+
+// readNotes returns a task's working notes and when they were last written
+func readNotes(t *task.Task) (string, time.Time, error) {
+	path := t.NotesPath()
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return string(data), info.ModTime(), nil
+}
+
+// notesTitle labels the notes panel with how long ago the agent last wrote them
+func notesTitle(modified time.Time) string {
+	return fmt.Sprintf("Notes (%s ago)", formatDuration(time.Since(modified)))
+}
+
+// renderNotesPanel renders the end of the selected task's working notes, read on every
+// render like the prompt so the panel follows the agent as it writes
+func (m Model) renderNotesPanel(width, height int) string {
+	contentWidth := width - 6
+	if contentWidth < 10 {
+		contentWidth = 10
+	}
+	availableLines := height - 4
+	if availableLines < 1 {
+		availableLines = 1
+	}
+	muted := lipgloss.NewStyle().Foreground(colorSecondary)
+
+	tasks := m.visibleTasks()
+	if len(tasks) == 0 || m.selected >= len(tasks) {
+		return m.renderPanel("Notes", muted.Render("No task selected"), width, height, false)
+	}
+
+	content, modified, err := readNotes(tasks[m.selected])
+	if os.IsNotExist(err) || (err == nil && strings.TrimSpace(content) == "") {
+		msg := fmt.Sprintf("No notes yet. The agent writes them to %s in the task's directory.", task.NotesFile)
+		return m.renderPanel("Notes", muted.Render(strings.Join(wrapText(msg, contentWidth), "\n")), width, height, false)
+	}
+	if err != nil {
+		return m.renderPanel("Notes", lipgloss.NewStyle().Foreground(colorError).Render(fmt.Sprintf("Error reading notes: %v", err)), width, height, false)
+	}
+
+	lines := wrapText(content, contentWidth)
+	if m.glamourRenderer != nil {
+		if rendered, err := m.glamourRenderer.Render(content); err == nil {
+			lines = strings.Split(strings.TrimRight(rendered, "\n "), "\n")
+		}
+	}
+
+	// Agents add to the end, so keep the latest notes in view
+	if len(lines) > availableLines {
+		lines = append([]string{muted.Render("... (earlier notes above)")}, lines[len(lines)-availableLines+1:]...)
+	}
+	return m.renderPanel(notesTitle(modified), strings.Join(lines, "\n"), width, height, false)
+}
+
+// detailNotesLines renders the task's working notes as markdown at the view's width
+func (m Model) detailNotesLines(t *task.Task) []string {
+	content, _, err := readNotes(t)
+	if os.IsNotExist(err) {
+		return []string{fmt.Sprintf("No notes yet. The agent writes them to %s", t.NotesPath())}
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("Error reading notes: %v", err)}
+	}
+	return m.renderMarkdownLines(content)
+}
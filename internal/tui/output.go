@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// outputTailBytes is how much of the end of a transcript the output panel reads
+const outputTailBytes = 64 * 1024
+
+// outputRefreshInterval is how often the output panel re-reads the transcript while visible
+const outputRefreshInterval = 2 * time.Second
+
+// ansiPattern matches terminal escape sequences (CSI, OSC, and two-byte escapes)
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// outputTickMsg triggers a re-read of the selected task's transcript
+// gen ties the tick to one opening of the panel so toggling never starts a second refresh loop
+type outputTickMsg struct {
+	gen int
+}
+
+// outputLoadedMsg carries the cleaned tail of a task's transcript
+type outputLoadedMsg struct {
+	gen    int
+	taskID string
+	lines  []string
+	err    error
+}
+
+// toggleOutput switches the right panel between the prompt and the agent's output
+func (m *Model) toggleOutput() tea.Cmd {
+	m.showOutput = !m.showOutput
+	m.showNotes = false
+	m.outputGen++
+	m.outputScroll = 0
+	if !m.showOutput {
+		return nil
+	}
+	return m.loadOutput()
+}
+
+// reloadOutput restarts the refresh loop for a newly selected task so the panel never lags a tick behind
+func (m *Model) reloadOutput() tea.Cmd {
+	if !m.showOutput {
+		return nil
+	}
+	m.outputGen++
+	return m.loadOutput()
+}
+
+// scheduleOutputRefresh schedules the next transcript re-read
+func scheduleOutputRefresh(gen int) tea.Cmd {
+	return tea.Tick(outputRefreshInterval, func(t time.Time) tea.Msg {
+		return outputTickMsg{gen: gen}
+	})
+}
+
+// loadOutput returns a command that reads the selected task's transcript
+func (m Model) loadOutput() tea.Cmd {
+	tasks := m.visibleTasks()
+	if len(tasks) == 0 || m.selected >= len(tasks) {
+		gen := m.outputGen
+		return func() tea.Msg { return outputLoadedMsg{gen: gen} }
+	}
+
+	gen := m.outputGen
+	taskID := tasks[m.selected].ID
+	path := m.config.TranscriptPath(taskID)
+	return func() tea.Msg {
+		data, err := readTail(path, outputTailBytes)
+		if err != nil {
+			return outputLoadedMsg{gen: gen, taskID: taskID, err: err}
+		}
+		return outputLoadedMsg{gen: gen, taskID: taskID, lines: cleanTranscript(data)}
+	}
+}
+
+// readTail returns up to maxBytes from the end of a file
+func readTail(path string, maxBytes int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - maxBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(file)
+}
+
+// cleanTranscript turns raw terminal output into printable lines
+// Escape sequences are dropped and carriage returns keep only the last redraw of a line
+func cleanTranscript(data []byte) []string {
+	text := ansiPattern.ReplaceAllString(string(data), "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		line = strings.Map(func(r rune) rune {
+			if r == '\t' {
+				return ' '
+			}
+			if r < ' ' || r == 0x7f {
+				return -1
+			}
+			return r
+		}, line)
+		line = strings.TrimRight(line, " ")
+
+		// Collapse runs of blank lines left behind by screen redraws
+		if line == "" && len(lines) > 0 && lines[len(lines)-1] == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// scrollOutput moves the output panel by delta lines; positive scrolls back in time
+func (m *Model) scrollOutput(delta int) {
+	m.outputScroll += delta
+	if limit := len(m.outputLines) - 1; m.outputScroll > limit {
+		m.outputScroll = limit
+	}
+	if m.outputScroll < 0 {
+		m.outputScroll = 0
+	}
+}
+
+// renderOutputPanel renders the tail of the selected task's transcript
+func (m Model) renderOutputPanel(width, height int) string {
+	contentWidth := width - 6
+	if contentWidth < 10 {
+		contentWidth = 10
+	}
+	availableLines := height - 4
+	if availableLines < 1 {
+		availableLines = 1
+	}
+
+	tasks := m.visibleTasks()
+	if len(tasks) == 0 || m.selected >= len(tasks) {
+		return m.renderPanel("Output", lipgloss.NewStyle().Foreground(colorSecondary).Render("No task selected"), width, height, false)
+	}
+	t := tasks[m.selected]
+
+	if m.outputTaskID != t.ID {
+		return m.renderPanel("Output", lipgloss.NewStyle().Foreground(colorSecondary).Render("Loading..."), width, height, false)
+	}
+	if m.outputErr != nil {
+		msg := "No output captured yet"
+		if !os.IsNotExist(m.outputErr) {
+			msg = fmt.Sprintf("Error reading output: %v", m.outputErr)
+		}
+		return m.renderPanel("Output", lipgloss.NewStyle().Foreground(colorSecondary).Render(msg), width, height, false)
+	}
+
+	// outputScroll counts lines up from the bottom; 0 follows new output
+	end := len(m.outputLines) - m.outputScroll
+	start := end - availableLines
+	if start < 0 {
+		start = 0
+	}
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		// Transcripts are full of box-drawing characters, so cut on runes rather than bytes
+		line := []rune(m.outputLines[i])
+		if len(line) > contentWidth {
+			line = line[:contentWidth]
+		}
+		b.WriteString(string(line))
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	title := "Output"
+	if m.outputScroll > 0 {
+		title = fmt.Sprintf("Output (-%d)", m.outputScroll)
+	}
+	return m.renderPanel(title, b.String(), width, height, false)
+}
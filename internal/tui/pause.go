@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// togglePause interrupts a running task's agent, or resumes it if the task is paused
+func (m *Model) togglePause(t *task.Task) {
+	if t.Status == task.StatusPaused {
+		m.resumeTask(t)
+		return
+	}
+	if !t.CanPause() {
+		m.addMessage("Only running tasks can be paused", true)
+		return
+	}
+
+	agent, err := m.config.Agent(t.Agent)
+	if err != nil {
+		m.addMessage(fmt.Sprintf("Failed to pause %s: %v", t.Name, err), true)
+		return
+	}
+	if err := m.mux.SendKey(t.TabName, multiplexer.Key(agent.InterruptKey)); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to pause %s: %v", t.Name, err), true)
+		return
+	}
+	if err := m.tasks.Pause(t.ID); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to pause %s: %v", t.Name, err), true)
+		return
+	}
+	// Keep the watcher and process heartbeat from reporting the task as WORKING
+	if err := multiplexer.WriteStatusFile(m.mux.StatusDir(), t, task.StatusPaused); err != nil {
+		m.addMessage(err.Error(), true)
+	}
+	m.addMessage(fmt.Sprintf("Paused %s", t.Name), false)
+}
+
+// toggleAutoNudge turns auto-nudging on or off for a task, resetting its nudge count
+func (m *Model) toggleAutoNudge(t *task.Task) {
+	on := !t.AutoNudge
+	if err := m.tasks.Update(t.ID, func(t *task.Task) {
+		t.AutoNudge = on
+		t.Nudges = 0
+	}); err != nil {
+		m.addMessage(err.Error(), true)
+		return
+	}
+	switch {
+	case !on:
+		m.addMessage(fmt.Sprintf("Auto-nudge off for %s", t.Name), false)
+	case m.config.NudgeThreshold() == 0:
+		m.addMessage(fmt.Sprintf("Auto-nudge on for %s, but nudge.after_minutes is 0 in the config", t.Name), true)
+	default:
+		m.addMessage(fmt.Sprintf("Auto-nudge on for %s: nudging after %d minutes waiting", t.Name, m.config.Nudge.AfterMinutes), false)
+	}
+}
+
+// resumeTask types the resume message into a paused task's tab and marks it WORKING
+func (m *Model) resumeTask(t *task.Task) {
+	agent, err := m.config.Agent(t.Agent)
+	if err != nil {
+		m.addMessage(fmt.Sprintf("Failed to resume %s: %v", t.Name, err), true)
+		return
+	}
+	message := m.config.ResumeMessage
+	if message == "" {
+		message = config.DefaultResumeMessage
+	}
+	text := multiplexer.ResumeMessage(message, multiplexer.NewLaunch(t, agent, ""))
+	if err := multiplexer.SendMessage(m.mux, t.TabName, text); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to resume %s: %v", t.Name, err), true)
+		return
+	}
+	if err := m.tasks.Resume(t.ID); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to resume %s: %v", t.Name, err), true)
+		return
+	}
+	if err := multiplexer.WriteStatusFile(m.mux.StatusDir(), t, task.StatusWorking); err != nil {
+		m.addMessage(err.Error(), true)
+	}
+	m.addMessage(fmt.Sprintf("Resumed %s", t.Name), false)
+}
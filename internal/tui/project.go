@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/dfowler/flock/internal/task"
+)
+
+// visibleTasks returns the tasks shown in the dashboard in the chosen order, limited to
+// the current project when the project filter is on and to those matching the filter text
+func (m Model) visibleTasks() []*task.Task {
+	tasks := m.tasks.List()
+	visible := tasks
+	if m.config.ProjectOnly || m.taskFilter != "" {
+		visible = make([]*task.Task, 0, len(tasks))
+		for _, t := range tasks {
+			if m.config.ProjectOnly && t.Project != m.project {
+				continue
+			}
+			if m.taskFilter != "" && !t.Matches(m.taskFilter) {
+				continue
+			}
+			visible = append(visible, t)
+		}
+	}
+	task.Sort(visible, task.SortOrder(m.config.TaskSort))
+	return visible
+}
+
+// selectTask moves the selection to a task, reporting whether it is visible
+func (m *Model) selectTask(id string) bool {
+	for i, t := range m.visibleTasks() {
+		if t.ID == id {
+			m.selected = i
+			return true
+		}
+	}
+	return false
+}
+
+// toggleProjectFilter switches between all tasks and the selected task's project,
+// keeping the selected task selected. With no task selected, the project is the
+// one flock was started in.
+func (m *Model) toggleProjectFilter() {
+	selectedID := ""
+	if tasks := m.visibleTasks(); m.selected < len(tasks) {
+		selectedID = tasks[m.selected].ID
+		if !m.config.ProjectOnly && tasks[m.selected].Project != "" {
+			m.project = tasks[m.selected].Project
+		}
+	}
+
+	m.config.ProjectOnly = !m.config.ProjectOnly
+	if err := m.config.Save(); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to save settings: %v", err), true)
+	}
+	if !m.selectTask(selectedID) {
+		m.selected = 0
+	}
+
+	if m.config.ProjectOnly {
+		m.addMessage(fmt.Sprintf("Showing tasks in %s", filepath.Base(m.project)), false)
+	} else {
+		m.addMessage("Showing tasks in all projects", false)
+	}
+}
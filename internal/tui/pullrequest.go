@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// pullRequestMsg reports the outcome of pushing a task's branch and opening its pull request
+type pullRequestMsg struct {
+	taskID string
+	url    string
+	opened bool // false if the task already had a pull request and only the push happened
+	err    error
+}
+
+// openPullRequest pushes the task's branch to origin and opens a pull request for it
+// in the background, titled after the task with the prompt file as the description
+func (m *Model) openPullRequest(t *task.Task) tea.Cmd {
+	if t.GitBranch == "" || t.RepoRoot == "" {
+		m.addMessage(fmt.Sprintf("%s has no branch to push", t.Name), true)
+		return nil
+	}
+
+	pr := git.PullRequest{
+		Branch: t.GitBranch,
+		Title:  t.Name,
+		Body:   strings.TrimSpace(m.readPrompt(t)),
+	}
+	taskID, repoRoot, existing := t.ID, t.RepoRoot, t.PRURL
+
+	m.addMessage(fmt.Sprintf("Pushing %s...", t.GitBranch), false)
+	return func() tea.Msg {
+		if err := git.PushBranch(repoRoot, pr.Branch); err != nil {
+			return pullRequestMsg{taskID: taskID, err: err}
+		}
+		if existing != "" {
+			return pullRequestMsg{taskID: taskID, url: existing}
+		}
+		url, err := git.OpenPullRequest(repoRoot, pr)
+		return pullRequestMsg{taskID: taskID, url: url, opened: true, err: err}
+	}
+}
+
+// finishPullRequest records a new pull request on its task and reports the URL
+func (m *Model) finishPullRequest(msg pullRequestMsg) {
+	t, ok := m.tasks.Get(msg.taskID)
+	if !ok {
+		return
+	}
+	if msg.err != nil {
+		m.addMessage(fmt.Sprintf("Pull request for %s failed: %v", t.Name, msg.err), true)
+		return
+	}
+	if !msg.opened {
+		m.addMessage(fmt.Sprintf("Pushed %s: %s", t.GitBranch, msg.url), false)
+		return
+	}
+	if err := m.tasks.Update(t.ID, func(t *task.Task) { t.PRURL = msg.url }); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to save pull request for %s: %v", t.Name, err), true)
+	}
+	m.addMessage(fmt.Sprintf("Opened %s", msg.url), false)
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/gate"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// readyCheckInterval is how often DONE tasks are checked for being ready to merge
+const readyCheckInterval = time.Minute
+
+// readyBadge marks DONE tasks whose branch is ready to merge in the task list
+const readyBadge = "✅"
+
+// readyTickMsg triggers a readiness check of the DONE tasks
+type readyTickMsg struct{}
+
+// readyCheckedMsg carries the readiness of each checked task, by task ID
+type readyCheckedMsg struct {
+	results map[string]gate.Readiness
+}
+
+// scheduleReadyCheck schedules the next readiness check
+func scheduleReadyCheck() tea.Cmd {
+	return tea.Tick(readyCheckInterval, func(t time.Time) tea.Msg {
+		return readyTickMsg{}
+	})
+}
+
+// checkReadiness checks in the background whether each unmerged DONE task's branch has
+// commits, merges cleanly and passes merge.require_command. Previous results are passed
+// along so branches that haven't moved aren't checked again.
+func (m *Model) checkReadiness() tea.Cmd {
+	type job struct {
+		repo, branch string
+		check        func() gate.Result
+		prev         *gate.Readiness
+	}
+	jobs := make(map[string]job)
+	for _, t := range m.tasks.List() {
+		if t.Status != task.StatusDone || t.MergedAt != nil || t.GitBranch == "" || t.RepoRoot == "" {
+			continue
+		}
+		j := job{repo: t.RepoRoot, branch: t.GitBranch}
+		if strings.TrimSpace(m.config.Merge.RequireCommand) != "" {
+			j.check = m.mergeGateFunc(t)
+		}
+		if prev, ok := m.readiness[t.ID]; ok {
+			j.prev = &prev
+		}
+		jobs[t.ID] = j
+	}
+	return func() tea.Msg {
+		results := make(map[string]gate.Readiness, len(jobs))
+		for id, j := range jobs {
+			results[id] = gate.CheckReady(j.repo, j.branch, j.check, j.prev)
+		}
+		return readyCheckedMsg{results: results}
+	}
+}
+
+// isReady reports whether the task was last found ready to merge
+func (m Model) isReady(t *task.Task) bool {
+	r, ok := m.readiness[t.ID]
+	return ok && r.Ready && t.Status == task.StatusDone && t.MergedAt == nil
+}
+
+// readyCount returns how many tasks are ready to merge
+func (m Model) readyCount() int {
+	count := 0
+	for _, t := range m.tasks.List() {
+		if m.isReady(t) {
+			count++
+		}
+	}
+	return count
+}
+
+// detailReadiness describes a DONE task's merge readiness for the detail view
+func (m Model) detailReadiness(t *task.Task) string {
+	r, ok := m.readiness[t.ID]
+	switch {
+	case !ok || t.Status != task.StatusDone || t.MergedAt != nil:
+		return ""
+	case m.isReady(t):
+		return "Ready to merge"
+	}
+	return "Not ready: " + r.Reason
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dfowler/flock/internal/config"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/prompt"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// applyRepoDefaults pre-fills the new task form with the values last used in dir's repository
+// The working directory is only filled in when withCwd is set, so a directory the user picked is kept
+func (m *Model) applyRepoDefaults(dir string, withCwd bool) {
+	if m.repoDefaults == nil {
+		return
+	}
+	repoRoot, err := git.GetMainRepoRoot(dir)
+	if err != nil {
+		return
+	}
+	d, ok := m.repoDefaults.Get(repoRoot)
+	if !ok {
+		return
+	}
+
+	if withCwd && d.Cwd != "" {
+		// Skip directories that have since been removed (e.g. a cleaned-up worktree)
+		if info, err := os.Stat(d.Cwd); err == nil && info.IsDir() {
+			m.cwdInput.SetValue(d.Cwd)
+			m.cwdInput.CursorEnd()
+		}
+	}
+	for i, name := range m.config.AgentNames() {
+		if name == d.Agent {
+			m.agentIndex = i
+		}
+	}
+	if d.Template != "" {
+		m.template = d.Template
+	}
+	m.useWorktree = d.UseWorktree
+}
+
+// rememberRepoDefaults records the form values used for a new task under its repository
+func (m *Model) rememberRepoDefaults(t *task.Task, msg editorFinishedMsg) {
+	if m.repoDefaults == nil {
+		return
+	}
+	repoRoot := t.RepoRoot
+	if repoRoot == "" {
+		var err error
+		if repoRoot, err = git.GetMainRepoRoot(t.Cwd); err != nil {
+			return // Not a git repo; nothing to key the defaults by
+		}
+	}
+
+	cwd := msg.cwd
+	if absCwd, err := filepath.Abs(cwd); err == nil {
+		cwd = absCwd
+	}
+	d := config.RepoDefaults{
+		Cwd:         cwd,
+		Template:    prompt.TemplateFileName(msg.template),
+		Agent:       msg.agent,
+		UseWorktree: msg.useWorktree,
+	}
+	if err := m.repoDefaults.Remember(repoRoot, d); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to save repo defaults: %v", err), true)
+	}
+}
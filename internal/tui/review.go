@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/prompt"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// requestReview starts a reviewer agent on a task's changes. The reviewer works in the same
+// worktree, gets the diff in its prompt (from the project's review.md or the built-in
+// review template) and is linked to the task as its review.
+func (m *Model) requestReview(t *task.Task) {
+	if t.Status == task.StatusPending {
+		m.addMessage("Start the task first; there are no changes to review yet", true)
+		return
+	}
+	if t.ReviewOf != "" {
+		m.addMessage(fmt.Sprintf("%s is already a review of %s", t.Name, t.ReviewOf), true)
+		return
+	}
+	dir := t.WorkDir()
+	repoRoot := t.RepoRoot
+	if repoRoot == "" {
+		var err error
+		if repoRoot, err = git.GetMainRepoRoot(dir); err != nil {
+			m.addMessage(fmt.Sprintf("Can't review %s: not in a git repository", t.Name), true)
+			return
+		}
+	}
+	diff, err := git.DiffFromDefault(repoRoot, dir)
+	if err != nil {
+		m.addMessage(fmt.Sprintf("Can't review %s: %v", t.Name, err), true)
+		return
+	}
+	if strings.TrimSpace(diff) == "" {
+		m.addMessage(fmt.Sprintf("%s has no changes to review", t.Name), true)
+		return
+	}
+
+	review, err := m.addClone(reviewRequest(t, prompt.ReviewDiff(diff)))
+	if err != nil {
+		m.addMessage(fmt.Sprintf("Failed to start a review of %s: %v", t.Name, err), true)
+		return
+	}
+	m.addMessage(fmt.Sprintf("Started %s to review %s", review.Name, t.Name), false)
+}
+
+// reviewRequest asks for a started reviewer task in t's worktree. Without a worktree the
+// reviewer works in t's directory.
+func reviewRequest(t *task.Task, diff string) daemon.Request {
+	return daemon.Request{
+		Action:   daemon.ActionAdd,
+		Name:     t.Name + "-review",
+		Cwd:      t.Cwd,
+		Template: prompt.ReviewTemplateName,
+		Agent:    t.Agent,
+		ReviewOf: t.ID,
+		Diff:     diff,
+		Start:    true,
+	}
+}
+
+// reviewBadge links reviews and reviewed tasks in the task list
+func (m Model) reviewBadge(t *task.Task) string {
+	if t.ReviewOf != "" {
+		return fmt.Sprintf(" (reviews %s)", t.ReviewOf)
+	}
+	if reviews := m.tasks.Reviews(t.ID); len(reviews) > 0 {
+		ids := make([]string, len(reviews))
+		for i, r := range reviews {
+			ids[i] = r.ID
+		}
+		return fmt.Sprintf(" (reviewed by %s)", strings.Join(ids, ","))
+	}
+	return ""
+}
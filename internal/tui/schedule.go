@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/daemon"
+	"github.com/dfowler/flock/internal/schedule"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// scheduleTickMsg triggers a check for scheduled tasks that are due
+type scheduleTickMsg struct{}
+
+// scheduleRunCheck schedules the next check for due tasks
+func scheduleRunCheck() tea.Cmd {
+	return tea.Tick(daemon.ScheduleInterval, func(t time.Time) tea.Msg {
+		return scheduleTickMsg{}
+	})
+}
+
+// runDueTasks starts scheduled tasks whose time has come, nudges agents left waiting
+// and sends the scheduled report, the same way the daemon does
+func (m *Model) runDueTasks() {
+	server := m.server
+	now := time.Now()
+	notes := append(server.RunDue(now), server.NudgeWaiting(now)...)
+	for _, note := range append(notes, server.SendDueReport(now)...) {
+		m.addMessage(note, strings.HasPrefix(note, "Failed"))
+	}
+}
+
+// formatNextRun describes when a scheduled task runs next, e.g. "Mon 02:00"
+// Runs more than a week away include the date instead of the weekday.
+func formatNextRun(next time.Time) string {
+	if time.Until(next) > 6*24*time.Hour {
+		return next.Format("Jan 2 15:04")
+	}
+	return next.Format("Mon 15:04")
+}
+
+// parseScheduleInput reads the schedule form: "at" followed by a time for a single run,
+// a bare time or date, or a cron expression. Empty clears the schedule.
+func parseScheduleInput(value string, now time.Time) (string, *time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil, nil
+	}
+	if rest, ok := strings.CutPrefix(value, "at "); ok {
+		at, err := schedule.ParseAt(rest, now)
+		if err != nil {
+			return "", nil, err
+		}
+		return "", &at, nil
+	}
+	if at, err := schedule.ParseAt(value, now); err == nil {
+		return "", &at, nil
+	}
+	if _, err := schedule.Parse(value); err != nil {
+		return "", nil, err
+	}
+	return value, nil, nil
+}
+
+// openSchedule opens the schedule form for a pending task
+func (m *Model) openSchedule(t *task.Task) tea.Cmd {
+	m.mode = viewSchedule
+	m.scheduleTaskID = t.ID
+	value := t.Schedule
+	if value == "" && t.NextRun != nil {
+		value = "at " + t.NextRun.Format("2006-01-02 15:04")
+	}
+	m.scheduleInput.SetValue(value)
+	m.scheduleInput.CursorEnd()
+	m.scheduleInput.Focus()
+	return textinput.Blink
+}
+
+// updateSchedule handles schedule form input
+func (m Model) updateSchedule(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.mode = viewDashboard
+		m.scheduleInput.Blur()
+		return m, nil
+
+	case "enter":
+		cron, at, err := parseScheduleInput(m.scheduleInput.Value(), time.Now())
+		if err == nil {
+			err = m.tasks.SetSchedule(m.scheduleTaskID, cron, at)
+		}
+		if err != nil {
+			// Keep the form open so the schedule can be fixed
+			m.addMessage(err.Error(), true)
+			return m, nil
+		}
+		m.mode = viewDashboard
+		m.scheduleInput.Blur()
+		if t, ok := m.tasks.Get(m.scheduleTaskID); ok {
+			switch {
+			case t.NextRun == nil:
+				m.addMessage(fmt.Sprintf("Cleared the schedule of %s", t.Name), false)
+			case t.IsRecurring():
+				m.addMessage(fmt.Sprintf("%s will run on %q, next %s", t.Name, t.Schedule, formatNextRun(*t.NextRun)), false)
+			default:
+				m.addMessage(fmt.Sprintf("%s will start %s", t.Name, formatNextRun(*t.NextRun)), false)
+			}
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.scheduleInput, cmd = m.scheduleInput.Update(msg)
+	return m, cmd
+}
+
+// viewSchedule renders the schedule form, previewing the next runs of what has been typed
+func (m Model) viewSchedule() string {
+	var b strings.Builder
+
+	name := m.scheduleTaskID
+	if t, ok := m.tasks.Get(m.scheduleTaskID); ok {
+		name = t.Name
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Schedule: %s", name)))
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("Run at a time, or on a cron schedule:"))
+	b.WriteString("\n")
+	b.WriteString(m.scheduleInput.View())
+	b.WriteString("\n\n")
+
+	secondary := lipgloss.NewStyle().Foreground(colorSecondary)
+	now := time.Now()
+	cron, at, err := parseScheduleInput(m.scheduleInput.Value(), now)
+	switch {
+	case err != nil:
+		b.WriteString(secondary.Render(err.Error()))
+	case at != nil:
+		b.WriteString(secondary.Render("Starts once, " + at.Format("Mon Jan 2 15:04")))
+	case cron != "":
+		parsed, _ := schedule.Parse(cron)
+		b.WriteString(secondary.Render("Starts a copy of the task at:"))
+		next := now
+		for i := 0; i < 3; i++ {
+			if next = parsed.Next(next); next.IsZero() {
+				break
+			}
+			b.WriteString("\n")
+			b.WriteString(secondary.Render("  " + next.Format("Mon Jan 2 15:04")))
+		}
+	default:
+		b.WriteString(secondary.Render("Not scheduled"))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(secondary.Render("e.g. 18:30, at 2025-06-01 09:00, 0 2 * * *, @daily"))
+	b.WriteString("\n")
+	help := helpStyle.Render("[enter]save  [esc]cancel  (empty clears)")
+	b.WriteString(help)
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
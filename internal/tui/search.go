@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openSearch switches to the prompt search view
+func (m *Model) openSearch() tea.Cmd {
+	m.mode = viewSearch
+	m.searchInput.Reset()
+	m.searchInput.Focus()
+	m.searchResults = nil
+	m.searchSelected = 0
+	return textinput.Blink
+}
+
+// updateSearch handles prompt search input
+// Typing refines the query; results are navigated with the arrow keys so j/k can be typed
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.mode = viewDashboard
+		m.searchInput.Blur()
+		return m, nil
+
+	case "down", "ctrl+n":
+		if m.searchSelected < len(m.searchResults)-1 {
+			m.searchSelected++
+		}
+		return m, nil
+
+	case "up", "ctrl+p":
+		if m.searchSelected > 0 {
+			m.searchSelected--
+		}
+		return m, nil
+
+	case "enter":
+		// Jump to the matching task in the dashboard
+		if m.searchSelected >= len(m.searchResults) {
+			return m, nil
+		}
+		match := m.searchResults[m.searchSelected]
+		if _, ok := m.tasks.Get(match.TaskID); !ok {
+			m.addMessage(fmt.Sprintf("Task %s no longer exists", match.TaskID), true)
+			return m, nil
+		}
+		if !m.selectTask(match.TaskID) {
+			// The match is in another project or filtered out; show all tasks to reveal it
+			m.config.ProjectOnly = false
+			m.taskFilter = ""
+			m.selectTask(match.TaskID)
+		}
+		m.mode = viewDashboard
+		m.searchInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	previous := m.searchInput.Value()
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if m.searchInput.Value() != previous {
+		results, err := m.promptMgr.Search(m.searchInput.Value())
+		if err != nil {
+			m.addMessage(fmt.Sprintf("Search error: %v", err), true)
+		}
+		m.searchResults = results
+		m.searchSelected = 0
+	}
+	return m, cmd
+}
+
+// viewSearch renders the prompt search view
+func (m Model) viewSearch() string {
+	var b strings.Builder
+
+	b.WriteString(m.searchInput.View())
+	b.WriteString("\n\n")
+
+	query := strings.TrimSpace(m.searchInput.Value())
+	switch {
+	case query == "":
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("Type to search all task prompts, including older versions."))
+	case len(m.searchResults) == 0:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("No matches."))
+	default:
+		contentWidth := m.width - 6
+		if contentWidth < 40 {
+			contentWidth = 40
+		}
+		taskWidth := 24
+
+		// Keep the selected result visible
+		available := m.height - 10
+		if available < 3 {
+			available = 3
+		}
+		start := 0
+		if m.searchSelected >= available {
+			start = m.searchSelected - available + 1
+		}
+		end := start + available
+		if end > len(m.searchResults) {
+			end = len(m.searchResults)
+		}
+
+		for i := start; i < end; i++ {
+			match := m.searchResults[i]
+			label := match.TaskID + " (deleted)"
+			if t, ok := m.tasks.Get(match.TaskID); ok {
+				label = match.TaskID + " " + t.Name
+			}
+			text := match.Text
+			if match.Snapshot {
+				text = "(older version) " + text
+			}
+			line := fmt.Sprintf("%-*s %s", taskWidth, truncate(label, taskWidth), truncate(text, contentWidth-taskWidth-1))
+			if i == m.searchSelected {
+				line = selectedRowStyle.Render(line)
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	panel := m.renderPanel("Search Prompts", b.String(), m.width, m.height-1, true)
+	help := helpStyle.Render("[↑/↓]select  [enter]jump to task  [esc]back")
+	return lipgloss.JoinVertical(lipgloss.Left, panel, help)
+}
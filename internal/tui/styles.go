@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/task"
 )
 
 var (
@@ -20,6 +21,8 @@ var (
 		"WORKING": lipgloss.Color("39"),  // blue
 		"WAITING": lipgloss.Color("220"), // yellow
 		"DONE":    lipgloss.Color("42"),  // green
+		"STALLED": lipgloss.Color("196"), // red
+		"PAUSED":  lipgloss.Color("141"), // purple
 	}
 
 	// Base styles
@@ -114,6 +117,17 @@ func StatusStyle(status string) lipgloss.Style {
 	return statusStyle.Foreground(color)
 }
 
+// PermissionStyle returns the badge style for a permission mode, warmer for more autonomy
+func PermissionStyle(mode task.PermissionMode) lipgloss.Style {
+	switch mode {
+	case task.PermissionAcceptEdits:
+		return lipgloss.NewStyle().Foreground(colorWarning)
+	case task.PermissionBypass:
+		return lipgloss.NewStyle().Foreground(colorError).Bold(true)
+	}
+	return lipgloss.NewStyle().Foreground(colorPrimary)
+}
+
 // Git status styles
 var (
 	gitAheadStyle  = lipgloss.NewStyle().Foreground(colorSuccess) // green
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// selectedTaskID returns the ID of the selected task, or "" if the list is empty
+func (m Model) selectedTaskID() string {
+	if tasks := m.visibleTasks(); m.selected < len(tasks) {
+		return tasks[m.selected].ID
+	}
+	return ""
+}
+
+// openTaskFilter prompts for text to narrow the task list by
+func (m *Model) openTaskFilter() tea.Cmd {
+	m.mode = viewTaskFilter
+	m.filterInput.SetValue(m.taskFilter)
+	m.filterInput.CursorEnd()
+	m.filterInput.Focus()
+	return textinput.Blink
+}
+
+// updateTaskFilter narrows the task list as the filter is typed. Enter keeps the filter
+// and returns to the dashboard; Esc clears it.
+func (m Model) updateTaskFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "enter":
+		m.mode = viewDashboard
+		m.filterInput.Blur()
+		return m, nil
+
+	case "esc":
+		m.mode = viewDashboard
+		m.filterInput.Blur()
+		m.setTaskFilter("")
+		return m, m.reloadOutput()
+
+	case "down", "ctrl+n":
+		if m.selected < len(m.visibleTasks())-1 {
+			m.selected++
+		}
+		return m, m.reloadOutput()
+
+	case "up", "ctrl+p":
+		if m.selected > 0 {
+			m.selected--
+		}
+		return m, m.reloadOutput()
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	m.setTaskFilter(m.filterInput.Value())
+	return m, tea.Batch(cmd, m.reloadOutput())
+}
+
+// setTaskFilter narrows the task list to tasks matching query, keeping the selected task
+// selected while it still matches
+func (m *Model) setTaskFilter(query string) {
+	selectedID := m.selectedTaskID()
+	m.taskFilter = strings.TrimSpace(query)
+	if !m.selectTask(selectedID) {
+		m.selected = 0
+	}
+}
+
+// cycleSortOrder switches to the next task list order and saves it, keeping the selected
+// task selected
+func (m *Model) cycleSortOrder() {
+	selectedID := m.selectedTaskID()
+	order := task.SortOrder(m.config.TaskSort).Next()
+	m.config.TaskSort = string(order)
+	if err := m.config.Save(); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to save settings: %v", err), true)
+	}
+	if !m.selectTask(selectedID) {
+		m.selected = 0
+	}
+	m.addMessage(fmt.Sprintf("Sorting tasks %s", order.Label()), false)
+}
+
+// taskListQualifiers describes how the task list is narrowed and ordered, for its title
+func (m Model) taskListQualifiers() []string {
+	var qualifiers []string
+	if m.taskFilter != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("%q", m.taskFilter))
+	}
+	if order := task.SortOrder(m.config.TaskSort); order != task.SortCreated {
+		qualifiers = append(qualifiers, order.Label())
+	}
+	return qualifiers
+}
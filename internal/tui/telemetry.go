@@ -0,0 +1,27 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/telemetry"
+)
+
+// telemetryTimeout keeps a slow network from holding the report open
+const telemetryTimeout = 10 * time.Second
+
+// sendTelemetry returns a command that sends the weekly anonymous usage report when the
+// user has opted in. Failures are ignored; the report is never retried in the foreground.
+func (m Model) sendTelemetry() tea.Cmd {
+	cfg, tasks, backend := m.config, m.tasks.List(), m.mux.Name()
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetryTimeout)
+		defer cancel()
+
+		telemetry.SendCached(ctx, cfg, func(installID string) telemetry.Report {
+			return telemetry.Build(cfg, tasks, backend, installID)
+		})
+		return nil
+	}
+}
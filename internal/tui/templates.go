@@ -0,0 +1,348 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/prompt"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+// templatePreviewLines is how much of the selected template the picker shows
+const templatePreviewLines = 12
+
+// templateEditedMsg is sent when the editor opened on a template closes
+type templateEditedMsg struct {
+	name string
+	err  error
+}
+
+// templatesSyncedMsg is sent when pulling the shared template repository finishes
+type templatesSyncedMsg struct {
+	summary string
+	err     error
+}
+
+// syncSharedTemplates pulls the shared template repository in the background
+func (m Model) syncSharedTemplates() tea.Cmd {
+	promptMgr := m.promptMgr
+	return func() tea.Msg {
+		summary, err := promptMgr.SyncSharedTemplates()
+		return templatesSyncedMsg{summary: summary, err: err}
+	}
+}
+
+// openTemplates shows the prompt templates of dir's project. When picking, enter chooses
+// the new task form's template; otherwise it opens the template in the editor.
+func (m *Model) openTemplates(dir string, picking bool) tea.Cmd {
+	if dir == "" {
+		dir = "."
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	m.mode = viewTemplates
+	m.templateDir = dir
+	m.templatePicking = picking
+	m.templateDeleting = ""
+	m.templateInput.Reset()
+	m.templateInput.Focus()
+	m.loadTemplates()
+
+	// Start on the form's template, so enter keeps it
+	m.templateSelected = 0
+	for i, name := range m.templateNames {
+		if picking && name == m.template {
+			m.templateSelected = i
+		}
+	}
+	return textinput.Blink
+}
+
+// loadTemplates lists the project's templates; default.md is always offered, since
+// it is created on first use
+func (m *Model) loadTemplates() {
+	names, err := m.promptMgr.ListTemplates(m.templateDir)
+	if err != nil {
+		m.addMessage(fmt.Sprintf("Failed to list templates: %v", err), true)
+	}
+	hasDefault := false
+	for _, name := range names {
+		hasDefault = hasDefault || name == prompt.DefaultTemplateName
+	}
+	if !hasDefault {
+		names = append([]string{prompt.DefaultTemplateName}, names...)
+	}
+	m.templateNames = names
+}
+
+// filteredTemplates returns the templates matching the filter, in order
+func (m Model) filteredTemplates() []string {
+	query := strings.TrimSpace(m.templateInput.Value())
+	var names []string
+	for _, name := range m.templateNames {
+		if fuzzyMatch(name, query) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// fuzzyMatch reports whether query's characters appear in s in order, ignoring case
+func fuzzyMatch(s, query string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(query) {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
+
+// closeTemplates returns to the view the templates were opened from
+func (m *Model) closeTemplates() {
+	m.templateInput.Blur()
+	m.templateDeleting = ""
+	if m.templatePicking {
+		m.mode = viewNewTask
+	} else {
+		m.mode = viewDashboard
+	}
+}
+
+// updateTemplates handles the template picker. Typing filters the list, so actions use
+// the arrow keys and ctrl combinations.
+func (m Model) updateTemplates(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	names := m.filteredTemplates()
+	var selected string
+	if m.templateSelected < len(names) {
+		selected = names[m.templateSelected]
+	}
+	if msg.String() != "ctrl+d" {
+		m.templateDeleting = ""
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.closeTemplates()
+		return m, nil
+
+	case "down":
+		if m.templateSelected < len(names)-1 {
+			m.templateSelected++
+		}
+		return m, nil
+
+	case "up":
+		if m.templateSelected > 0 {
+			m.templateSelected--
+		}
+		return m, nil
+
+	case "enter":
+		if selected == "" {
+			return m, nil
+		}
+		if m.templatePicking {
+			m.template = selected
+			m.closeTemplates()
+			return m, nil
+		}
+		return m, m.editTemplate(selected)
+
+	case "ctrl+e":
+		if selected != "" {
+			return m, m.editTemplate(selected)
+		}
+		return m, nil
+
+	case "ctrl+n":
+		// Create a template named after the filter text and open it in the editor
+		name := strings.TrimSpace(m.templateInput.Value())
+		if name == "" {
+			m.addMessage("Type a name for the new template first", true)
+			return m, nil
+		}
+		if _, err := m.promptMgr.CreateTemplate(m.templateDir, name); err != nil {
+			m.addMessage(fmt.Sprintf("Failed to create template: %v", err), true)
+			return m, nil
+		}
+		m.templateInput.Reset()
+		m.loadTemplates()
+		name = prompt.TemplateFileName(name)
+		for i, n := range m.templateNames {
+			if n == name {
+				m.templateSelected = i
+			}
+		}
+		return m, m.editTemplate(name)
+
+	case "ctrl+r":
+		// Pull the latest shared templates
+		if m.promptMgr.SharedTemplatesDir() == "" {
+			m.addMessage("No shared template repository configured (templates.repo)", true)
+			return m, nil
+		}
+		m.addMessage("Pulling shared templates...", false)
+		return m, m.syncSharedTemplates()
+
+	case "ctrl+d":
+		// Deleting takes a second press, since the file is gone for good
+		if selected == "" {
+			return m, nil
+		}
+		if selected == prompt.DefaultTemplateName {
+			m.addMessage("The default template can't be deleted", true)
+			return m, nil
+		}
+		if m.templateDeleting != selected {
+			m.templateDeleting = selected
+			return m, nil
+		}
+		m.templateDeleting = ""
+		if err := m.promptMgr.DeleteTemplate(m.templateDir, selected); err != nil {
+			m.addMessage(fmt.Sprintf("Failed to delete %s: %v", selected, err), true)
+			return m, nil
+		}
+		if m.template == selected {
+			m.template = prompt.DefaultTemplateName
+		}
+		m.addMessage(fmt.Sprintf("Deleted template %s", selected), false)
+		m.loadTemplates()
+		if m.templateSelected >= len(m.filteredTemplates()) && m.templateSelected > 0 {
+			m.templateSelected--
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	previous := m.templateInput.Value()
+	m.templateInput, cmd = m.templateInput.Update(msg)
+	if m.templateInput.Value() != previous {
+		m.templateSelected = 0
+	}
+	return m, cmd
+}
+
+// editTemplate opens a template in the editor, creating the default template first if
+// needed. A shared template is copied into the project first, since the shared checkout is
+// replaced on every pull.
+func (m Model) editTemplate(name string) tea.Cmd {
+	path, shared := m.promptMgr.TemplatePath(m.templateDir, name)
+	if shared {
+		copied, err := m.promptMgr.CreateTemplate(m.templateDir, name)
+		if err != nil {
+			return func() tea.Msg { return templateEditedMsg{name: name, err: err} }
+		}
+		path = copied
+	}
+	if name == prompt.DefaultTemplateName {
+		if _, err := m.promptMgr.EnsureProjectTemplate(m.templateDir); err != nil {
+			return func() tea.Msg { return templateEditedMsg{name: name, err: err} }
+		}
+	}
+
+	editor := getEditor()
+	if isGUIEditor(editor) {
+		return func() tea.Msg {
+			c := m.commands.Interactive(runner.Command(editor, path))
+			return templateEditedMsg{name: name, err: c.Start()}
+		}
+	}
+	c := m.commands.Interactive(runner.Command(editor, path))
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return templateEditedMsg{name: name, err: err}
+	})
+}
+
+// viewTemplates renders the template picker with a preview of the selected template
+func (m Model) viewTemplates() string {
+	var b strings.Builder
+	muted := lipgloss.NewStyle().Foreground(colorSecondary)
+
+	b.WriteString(muted.Render(prompt.TemplatesDir(m.templateDir)))
+	if shared := m.promptMgr.SharedTemplatesDir(); shared != "" {
+		b.WriteString(muted.Render(fmt.Sprintf("\nShared: %s (%s)", m.config.Templates.Repo, shared)))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(m.templateInput.View())
+	b.WriteString("\n\n")
+
+	names := m.filteredTemplates()
+	if len(names) == 0 {
+		b.WriteString(muted.Render("No matching templates. Press ctrl+n to create one with this name."))
+		b.WriteString("\n")
+	}
+	for i, name := range names {
+		line := "  " + name
+		if _, shared := m.promptMgr.TemplatePath(m.templateDir, name); shared {
+			line += " (shared)"
+		}
+		if m.templatePicking && name == m.template {
+			line += " (current)"
+		}
+		if i == m.templateSelected {
+			line = selectedRowStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	if m.templateSelected < len(names) {
+		selected := names[m.templateSelected]
+		b.WriteString("\n")
+		if m.templateDeleting == selected {
+			b.WriteString(lipgloss.NewStyle().Foreground(colorError).Render(fmt.Sprintf("Press ctrl+d again to delete %s", selected)))
+			b.WriteString("\n\n")
+		}
+		b.WriteString(m.templatePreview(selected))
+	}
+
+	title := "Templates"
+	help := "[↑/↓]select  [enter]edit  [ctrl+n]new  [ctrl+d]delete  [esc]back"
+	if m.templatePicking {
+		title = "Choose Template"
+		help = "[↑/↓]select  [enter]use  [ctrl+e]edit  [ctrl+n]new  [ctrl+d]delete  [esc]back"
+	}
+	if m.promptMgr.SharedTemplatesDir() != "" {
+		help = strings.Replace(help, "  [esc]back", "  [ctrl+r]pull shared  [esc]back", 1)
+	}
+	panel := m.renderPanel(title, b.String(), m.width, m.height-1, true)
+	return lipgloss.JoinVertical(lipgloss.Left, panel, helpStyle.Render(help))
+}
+
+// templatePreview renders the first lines of a template
+func (m Model) templatePreview(name string) string {
+	muted := lipgloss.NewStyle().Foreground(colorSecondary)
+	path, _ := m.promptMgr.TemplatePath(m.templateDir, name)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && name == prompt.DefaultTemplateName {
+		return muted.Render("Created from the built-in template on first use")
+	}
+	if err != nil {
+		return lipgloss.NewStyle().Foreground(colorError).Render(fmt.Sprintf("Error reading template: %v", err))
+	}
+
+	width := m.width - 8
+	if width < 20 {
+		width = 20
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > templatePreviewLines {
+		lines = append(lines[:templatePreviewLines], "...")
+	}
+	for i, line := range lines {
+		lines[i] = muted.Render("│ " + truncate(line, width))
+	}
+	return strings.Join(lines, "\n")
+}
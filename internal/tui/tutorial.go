@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// tutorialCheckInterval is how often the tutorial checks whether its current step is done
+const tutorialCheckInterval = time.Second
+
+// tutorialStep is one step of `flock tutorial`: a hint shown in the messages panel, and
+// how to tell the user has done it
+type tutorialStep struct {
+	hint string
+	done func(m Model) bool
+}
+
+// tutorialSteps walk through the life of a task: start, status, diff, merge
+var tutorialSteps = []tutorialStep{
+	{
+		hint: "Press s to start add-greeting. A fake agent opens in a new tab and works on its own branch.",
+		done: func(m Model) bool {
+			return m.anyTask(func(t *task.Task) bool { return t.Status != task.StatusPending })
+		},
+	},
+	{
+		hint: "WORKING shows a spinner; WAITING means the agent has a question. Press enter to jump to its tab and answer.",
+		done: func(m Model) bool { return m.anyTask(func(t *task.Task) bool { return t.Status == task.StatusDone }) },
+	},
+	{
+		hint: "add-greeting is DONE with a commit on its branch. Press c to review the changes (esc closes them).",
+		done: func(m Model) bool { return m.mode == viewDiff },
+	},
+	{
+		hint: "Press m to merge the branch into main, then y to confirm.",
+		done: func(m Model) bool { return m.anyTask(func(t *task.Task) bool { return t.MergedAt != nil }) },
+	},
+}
+
+// tutorialTickMsg triggers a check of the tutorial's progress
+type tutorialTickMsg struct{}
+
+// StartTutorial guides the user through the tasks created by `flock tutorial` with hints
+// in the messages panel. Call it before the program starts.
+func (m *Model) StartTutorial() {
+	m.tutorial = true
+	m.tutorialStep = 0
+	m.tutorialHinted = false
+}
+
+// scheduleTutorialCheck schedules the next tutorial progress check
+func scheduleTutorialCheck() tea.Cmd {
+	return tea.Tick(tutorialCheckInterval, func(t time.Time) tea.Msg {
+		return tutorialTickMsg{}
+	})
+}
+
+// advanceTutorial moves past the steps the user has done and shows the next hint
+func (m *Model) advanceTutorial() {
+	if !m.tutorial {
+		return
+	}
+	for m.tutorialStep < len(tutorialSteps) && tutorialSteps[m.tutorialStep].done(*m) {
+		m.tutorialStep++
+		m.tutorialHinted = false
+	}
+	if m.tutorialStep == len(tutorialSteps) {
+		m.addMessage("Tutorial done: the change is on main. Try fix-typo yourself, or press q (the sample project is deleted) and run flock in your repo.", false)
+		m.tutorial = false
+		return
+	}
+	if !m.tutorialHinted {
+		m.addMessage(fmt.Sprintf("Tutorial %d/%d: %s", m.tutorialStep+1, len(tutorialSteps), tutorialSteps[m.tutorialStep].hint), false)
+		m.tutorialHinted = true
+	}
+}
+
+// anyTask reports whether any task matches
+func (m Model) anyTask(match func(t *task.Task) bool) bool {
+	for _, t := range m.tasks.List() {
+		if match(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/chain"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/task"
+)
+
+// undoPlannedMsg carries how the merge of a task can be undone, from git.PlanUndo
+type undoPlannedMsg struct {
+	op      int
+	taskID  string
+	opening bool // Open the dialog, rather than update the method of an open one
+	method  git.UndoMethod
+	err     error
+}
+
+// undoDoneMsg is sent when an undo started from the dialog finishes
+type undoDoneMsg struct {
+	op     int
+	taskID string
+	note   string
+	err    error
+}
+
+// openUndoMerge offers to undo the most recent merge flock made, from the savepoint
+// recorded before it. How is worked out in the background; the dialog opens once it is.
+func (m *Model) openUndoMerge() tea.Cmd {
+	if m.mergeRunning || m.undoRunning {
+		m.addMessage("A merge or undo is still running; undo once it is done", true)
+		return nil
+	}
+	t, ok := m.tasks.LastMerge()
+	if !ok {
+		m.addMessage("No merge to undo", true)
+		return nil
+	}
+	op := m.startOperation(fmt.Sprintf("Checking the merge of %s", t.GitBranch))
+	return planUndo(t, op, true)
+}
+
+// planUndo runs git.PlanUndo for a task's recorded merge in the background
+func planUndo(t *task.Task, op int, opening bool) tea.Cmd {
+	id, repoRoot, pre, merge := t.ID, t.RepoRoot, t.PreMergeHead, t.MergeCommit
+	return func() tea.Msg {
+		method, err := git.PlanUndo(repoRoot, pre, merge)
+		return undoPlannedMsg{op: op, taskID: id, opening: opening, method: method, err: err}
+	}
+}
+
+// undoPlanned opens the undo dialog, or updates the open one, with a planned undo
+func (m *Model) undoPlanned(msg undoPlannedMsg) {
+	m.finishOperation(msg.op)
+	t, ok := m.tasks.Get(msg.taskID)
+	if !ok {
+		return
+	}
+	if msg.err != nil {
+		if msg.opening {
+			m.addMessage(fmt.Sprintf("Can't undo the merge of %s: %v", t.Name, msg.err), true)
+		}
+		return
+	}
+	switch {
+	case msg.opening && m.mode == viewDashboard:
+		m.undoTaskID = t.ID
+		m.undoMethod = msg.method
+		m.mode = viewConfirmUndoMerge
+	case !msg.opening && m.mode == viewConfirmUndoMerge && m.undoTaskID == t.ID:
+		m.undoMethod = msg.method
+	}
+}
+
+// undoMergeCmd undoes a task's recorded merge in the background
+func (m Model) undoMergeCmd(t *task.Task, method git.UndoMethod, op int) tea.Cmd {
+	worktrees, snapshot := m.config.Worktrees, *t
+	return func() tea.Msg {
+		note, err := chain.UndoMerge(&snapshot, method, worktrees)
+		return undoDoneMsg{op: op, taskID: snapshot.ID, note: note, err: err}
+	}
+}
+
+// undoDone reports a finished undo and forgets the merge it took back
+func (m *Model) undoDone(msg undoDoneMsg) {
+	m.finishOperation(msg.op)
+	m.undoRunning = false
+	t, ok := m.tasks.Get(msg.taskID)
+	if !ok {
+		return
+	}
+	if msg.err != nil {
+		m.addMessage(fmt.Sprintf("Failed to undo the merge of %s: %v", t.Name, msg.err), true)
+		return
+	}
+	if err := m.tasks.ClearMerge(t.ID); err != nil {
+		m.addMessage(fmt.Sprintf("Failed to save task: %v", err), true)
+	}
+	m.addMessage(msg.note, false)
+}
+
+// updateConfirmUndoMerge handles the undo merge dialog input
+func (m Model) updateConfirmUndoMerge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		m.mode = viewDashboard
+		t, ok := m.tasks.Get(m.undoTaskID)
+		if !ok {
+			return m, nil
+		}
+		m.undoRunning = true
+		op := m.startOperation(fmt.Sprintf("Undoing the merge of %s", t.GitBranch))
+		return m, m.undoMergeCmd(t, m.undoMethod, op)
+
+	case "v":
+		// A reset is only offered while nothing was committed after the merge
+		if m.undoMethod == git.UndoReset {
+			m.undoMethod = git.UndoRevert
+		} else if t, ok := m.tasks.Get(m.undoTaskID); ok {
+			op := m.startOperation(fmt.Sprintf("Checking the merge of %s", t.GitBranch))
+			return m, planUndo(t, op, false)
+		}
+
+	case "n", "N", "esc":
+		m.mode = viewDashboard
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// viewConfirmUndoMerge renders the undo merge dialog
+func (m Model) viewConfirmUndoMerge() string {
+	var b strings.Builder
+
+	t, ok := m.tasks.Get(m.undoTaskID)
+	if !ok {
+		return m.viewDashboard()
+	}
+
+	b.WriteString(titleStyle.Render("Undo Merge?"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("Undo the merge of '%s' (%s)", t.GitBranch, t.Name))
+	if t.MergedAt != nil {
+		b.WriteString(fmt.Sprintf(",\nmerged %s", t.MergedAt.Format("Jan 2 15:04")))
+	}
+	b.WriteString("\n\n")
+
+	secondary := lipgloss.NewStyle().Foreground(colorSecondary)
+	pre, merge := t.PreMergeHead, t.MergeCommit
+	if len(pre) > 8 {
+		pre = pre[:8]
+	}
+	if len(merge) > 8 {
+		merge = merge[:8]
+	}
+	if m.undoMethod == git.UndoReset {
+		b.WriteString(fmt.Sprintf("Reset the default branch back to %s.\n", pre))
+		b.WriteString(secondary.Render("Nothing was committed after the merge, and it hasn't been pushed."))
+	} else {
+		b.WriteString(fmt.Sprintf("Commit a revert of %s..%s.\n", pre, merge))
+		b.WriteString(secondary.Render("Later commits are kept; merging the branch again needs the revert reverted."))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(helpStyle.Render("[y/enter]undo  [v] reset/revert  [esc]cancel"))
+
+	return m.centerContent(modalStyle.Render(b.String()))
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dfowler/flock/internal/update"
+)
+
+// updateCheckTimeout keeps a slow network from holding the check open
+const updateCheckTimeout = 10 * time.Second
+
+// updateAvailableMsg reports a newer flock release
+type updateAvailableMsg struct {
+	version string
+}
+
+// checkForUpdate returns a command that looks for a newer release (at most once a day)
+// Failures are ignored; the check is only a hint
+func checkForUpdate(statePath string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+
+		version, err := update.CheckCached(ctx, statePath)
+		if err != nil || version == "" {
+			return nil
+		}
+		return updateAvailableMsg{version: version}
+	}
+}
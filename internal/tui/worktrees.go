@@ -0,0 +1,433 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dfowler/flock/internal/git"
+	"github.com/dfowler/flock/internal/task"
+	"github.com/dfowler/flock/internal/timefmt"
+)
+
+// worktreeRow is one flock worktree shown in the worktrees view
+type worktreeRow struct {
+	repoRoot   string
+	worktree   git.Worktree
+	diskUsage  int64
+	lastCommit string
+	lastActive time.Time // Latest commit or index write
+	dirty      bool
+	changes    int // Uncommitted changes and untracked files, when dirty
+}
+
+// worktreesLoadedMsg carries freshly scanned worktree rows
+type worktreesLoadedMsg struct {
+	rows []worktreeRow
+}
+
+// knownRepos returns every repo referenced by a task or used for one before,
+// whichever directory flock was started in
+func (m Model) knownRepos() []string {
+	seen := make(map[string]bool)
+	var repos []string
+	for _, t := range m.tasks.List() {
+		if t.RepoRoot != "" && !seen[t.RepoRoot] {
+			seen[t.RepoRoot] = true
+			repos = append(repos, t.RepoRoot)
+		}
+	}
+	for _, repoRoot := range m.repoDefaults.Repos() {
+		if !seen[repoRoot] {
+			seen[repoRoot] = true
+			repos = append(repos, repoRoot)
+		}
+	}
+	return repos
+}
+
+// loadWorktrees returns a command that scans all known repos for flock worktrees
+// Disk usage is computed here, off the render loop, since it walks every file
+func (m Model) loadWorktrees() tea.Cmd {
+	repos := m.knownRepos()
+	return func() tea.Msg {
+		var rows []worktreeRow
+		for _, repoRoot := range repos {
+			worktrees, err := git.ListWorktrees(repoRoot)
+			if err != nil {
+				continue
+			}
+			for _, wt := range worktrees {
+				if !git.IsFlockWorktree(wt.Path) {
+					continue
+				}
+				row := worktreeRow{repoRoot: repoRoot, worktree: wt}
+				row.diskUsage, _ = git.DiskUsage(wt.Path)
+				row.lastCommit, _ = git.LastCommit(wt.Path)
+				row.lastActive = git.LastActivity(wt.Path)
+				// A worktree git can't read counts as dirty, as in git.IsDirty
+				files, err := git.ChangedFiles(wt.Path)
+				row.dirty, row.changes = err != nil || len(files) > 0, len(files)
+				rows = append(rows, row)
+			}
+		}
+		return worktreesLoadedMsg{rows: rows}
+	}
+}
+
+// worktreeOwner returns the task using a worktree, if any
+func (m Model) worktreeOwner(path string) (*task.Task, bool) {
+	for _, t := range m.tasks.List() {
+		if t.WorktreePath == path {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// gcWorktrees returns the worktrees garbage collection removes: those no task uses that
+// have been idle for worktrees.gc_days. Dirty worktrees are kept, since their changes
+// exist nowhere else; the second result counts them.
+func (m Model) gcWorktrees() ([]worktreeRow, int) {
+	days := m.config.Worktrees.GCDays
+	if days <= 0 {
+		return nil, 0
+	}
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	var rows []worktreeRow
+	dirty := 0
+	for _, row := range m.worktreeRows {
+		if _, used := m.worktreeOwner(row.worktree.Path); used || row.lastActive.After(cutoff) {
+			continue
+		}
+		if row.dirty {
+			dirty++
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, dirty
+}
+
+// worktreeActionMsg reports a delete, reset or garbage collection finished in the
+// background, so the list can be scanned again
+type worktreeActionMsg struct {
+	op      int
+	notices []notice
+}
+
+// collectWorktrees removes the given rows, as picked by gcWorktrees. A branch with
+// commits the default branch lacks is kept, so only the checkout goes.
+func collectWorktrees(rows []worktreeRow) []notice {
+	var notices []notice
+	removed, keptBranches := 0, 0
+	var reclaimed int64
+	for _, row := range rows {
+		ahead, err := git.CommitsAhead(row.repoRoot, row.worktree.Branch)
+		keepBranch := err != nil || ahead > 0
+		if err := git.RemoveWorktree(row.repoRoot, row.worktree.Path, !keepBranch); err != nil {
+			notices = append(notices, notice{text: fmt.Sprintf("Failed to remove %s: %v", filepath.Base(row.worktree.Path), err), isErr: true})
+			continue
+		}
+		removed++
+		reclaimed += row.diskUsage
+		if keepBranch {
+			keptBranches++
+		}
+	}
+	msg := fmt.Sprintf("Removed %d idle worktrees, reclaiming %s", removed, formatBytes(reclaimed))
+	if keptBranches > 0 {
+		msg += fmt.Sprintf("; kept %d branches with unmerged commits", keptBranches)
+	}
+	return append(notices, notice{text: msg})
+}
+
+// worktreeActionCmd runs a confirmed worktree action off the UI loop. stash saves a
+// dirty worktree's changes before a reset.
+func (m *Model) worktreeActionCmd(action string, row worktreeRow, stash bool) tea.Cmd {
+	name := filepath.Base(row.worktree.Path)
+	label := map[string]string{
+		"gc":     "Removing idle worktrees",
+		"delete": "Deleting worktree " + name,
+		"reset":  "Resetting worktree " + name,
+	}[action]
+	op := m.startOperation(label)
+	var gcRows []worktreeRow
+	if action == "gc" {
+		gcRows, _ = m.gcWorktrees()
+	}
+	assigner := m.gitAssigner
+	return func() tea.Msg {
+		msg := worktreeActionMsg{op: op}
+		if stash {
+			stashName := git.StashMessage(row.worktree.Branch, "reset")
+			if _, err := git.StashChanges(row.worktree.Path, stashName); err != nil {
+				msg.notices = append(msg.notices, notice{text: fmt.Sprintf("Not resetting %s: %v", name, err), isErr: true})
+				return msg
+			}
+			msg.notices = append(msg.notices, notice{text: fmt.Sprintf("Stashed changes as %q (see git stash list)", stashName)})
+		}
+		switch action {
+		case "gc":
+			msg.notices = append(msg.notices, collectWorktrees(gcRows)...)
+		case "delete":
+			var err error
+			if assigner != nil {
+				err = assigner.ReleaseWorktree(row.worktree.Path, row.repoRoot)
+			} else {
+				err = git.RemoveWorktree(row.repoRoot, row.worktree.Path, true)
+			}
+			if err != nil {
+				msg.notices = append(msg.notices, notice{text: fmt.Sprintf("Failed to delete worktree: %v", err), isErr: true})
+			} else {
+				msg.notices = append(msg.notices, notice{text: fmt.Sprintf("Deleted worktree: %s", name)})
+			}
+		case "reset":
+			if err := git.ResetWorktreeBranch(row.worktree.Path); err != nil {
+				msg.notices = append(msg.notices, notice{text: fmt.Sprintf("Failed to reset worktree: %v", err), isErr: true})
+			} else {
+				msg.notices = append(msg.notices, notice{text: fmt.Sprintf("Reset worktree: %s", name)})
+			}
+		}
+		return msg
+	}
+}
+
+// selectedWorktree returns the highlighted worktree row
+func (m Model) selectedWorktree() (worktreeRow, bool) {
+	if m.worktreeSelected < 0 || m.worktreeSelected >= len(m.worktreeRows) {
+		return worktreeRow{}, false
+	}
+	return m.worktreeRows[m.worktreeSelected], true
+}
+
+// updateWorktrees handles worktrees view input
+func (m Model) updateWorktrees(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// A pending delete/reset waits for confirmation
+	if m.worktreeConfirm != "" {
+		action := m.worktreeConfirm
+		m.worktreeConfirm = ""
+		row, ok := m.selectedWorktree()
+		if !ok {
+			return m, nil
+		}
+		// A dirty worktree's changes can be stashed before the reset instead of discarded
+		stash := action == "reset" && row.dirty && msg.String() == "s"
+		if msg.String() != "y" && msg.String() != "Y" && !stash {
+			return m, nil
+		}
+		return m, m.worktreeActionCmd(action, row, stash)
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q", "W":
+		m.mode = viewDashboard
+		return m, nil
+
+	case "j", "down":
+		if m.worktreeSelected < len(m.worktreeRows)-1 {
+			m.worktreeSelected++
+		}
+
+	case "k", "up":
+		if m.worktreeSelected > 0 {
+			m.worktreeSelected--
+		}
+
+	case "R":
+		m.worktreesLoading = true
+		return m, m.loadWorktrees()
+
+	case "g":
+		// Remove worktrees no task uses that have sat idle for worktrees.gc_days
+		if m.config.Worktrees.GCDays <= 0 {
+			m.addMessage("Worktree garbage collection is off (worktrees.gc_days is 0)", true)
+		} else if rows, _ := m.gcWorktrees(); len(rows) == 0 {
+			m.addMessage(fmt.Sprintf("No clean, unused worktrees idle for %d days", m.config.Worktrees.GCDays), false)
+		} else {
+			m.worktreeConfirm = "gc"
+		}
+
+	case "y":
+		// Copy the selected worktree's full path
+		if row, ok := m.selectedWorktree(); ok {
+			m.yankPath(row.worktree.Path)
+		}
+
+	case "d":
+		// Delete worktree and branch (only when no task uses it)
+		if row, ok := m.selectedWorktree(); ok {
+			if t, used := m.worktreeOwner(row.worktree.Path); used {
+				m.addMessage(fmt.Sprintf("Worktree is used by task %s; delete the task instead", t.Name), true)
+			} else {
+				m.worktreeConfirm = "delete"
+			}
+		}
+
+	case "r":
+		// Reset worktree to the default branch (refused while an agent runs in it)
+		if row, ok := m.selectedWorktree(); ok {
+			if t, used := m.worktreeOwner(row.worktree.Path); used && t.IsActive() {
+				m.addMessage(fmt.Sprintf("Worktree is in use by running task %s", t.Name), true)
+			} else {
+				m.worktreeConfirm = "reset"
+			}
+		}
+
+	case "a":
+		// Adopt the worktree into a new task: the form opens with it as working directory
+		if row, ok := m.selectedWorktree(); ok {
+			if t, used := m.worktreeOwner(row.worktree.Path); used {
+				m.addMessage(fmt.Sprintf("Worktree is already used by task %s", t.Name), true)
+				return m, nil
+			}
+			m.mode = viewNewTask
+			m.cwdInput.SetValue(row.worktree.Path)
+			m.nameInput.Focus()
+			m.focusIndex = 0
+			m.useWorktree = true
+			m.agentIndex = 0
+			return m, textinput.Blink
+		}
+	}
+
+	return m, nil
+}
+
+// viewWorktrees renders the worktrees management view
+func (m Model) viewWorktrees() string {
+	var b strings.Builder
+
+	contentWidth := m.width - 6
+	if contentWidth < 40 {
+		contentWidth = 40
+	}
+	nameWidth := 28
+	branchWidth := 20
+	taskWidth := 16
+	ageWidth := 8
+	stateWidth := 9
+	sizeWidth := 8
+	commitWidth := contentWidth - nameWidth - branchWidth - taskWidth - ageWidth - stateWidth - sizeWidth - 6
+	if commitWidth < 10 {
+		commitWidth = 10
+	}
+
+	switch {
+	case m.worktreesLoading && len(m.worktreeRows) == 0:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("Scanning worktrees..."))
+	case len(m.worktreeRows) == 0:
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("No flock worktrees found."))
+	default:
+		header := fmt.Sprintf("%-*s %-*s %-*s %-*s %-*s %-*s %s", nameWidth, "Worktree", branchWidth, "Branch", taskWidth, "Task", ageWidth, "Idle", stateWidth, "State", sizeWidth, "Size", "Last commit")
+		b.WriteString(tableHeaderStyle.Render(header))
+		b.WriteString("\n")
+
+		for i, row := range m.worktreeRows {
+			name := filepath.Base(row.repoRoot) + "/" + filepath.Base(row.worktree.Path)
+			taskName := "-"
+			if git.IsSpareWorktree(row.worktree.Path) {
+				taskName = "(spare)"
+			}
+			if t, ok := m.worktreeOwner(row.worktree.Path); ok {
+				taskName = t.ID + " " + t.Name
+			}
+			idle := "-"
+			if !row.lastActive.IsZero() {
+				idle = strings.TrimSuffix(timefmt.Relative(row.lastActive, time.Now()), " ago")
+			}
+			state := fmt.Sprintf("%-*s", stateWidth, "clean")
+			if row.dirty {
+				state = fmt.Sprintf("%-*s", stateWidth, fmt.Sprintf("dirty %d", row.changes))
+				if i != m.worktreeSelected {
+					state = lipgloss.NewStyle().Foreground(colorWarning).Render(state)
+				}
+			}
+			line := fmt.Sprintf("%-*s %-*s %-*s %-*s %s %-*s %s",
+				nameWidth, truncate(name, nameWidth),
+				branchWidth, truncate(row.worktree.Branch, branchWidth),
+				taskWidth, truncate(taskName, taskWidth),
+				ageWidth, idle,
+				state,
+				sizeWidth, formatBytes(row.diskUsage),
+				truncate(row.lastCommit, commitWidth))
+			if i == m.worktreeSelected {
+				line = selectedRowStyle.Render(line)
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	// Totals, so the space worktrees take is clear without adding up the column
+	if len(m.worktreeRows) > 0 {
+		var total int64
+		for _, row := range m.worktreeRows {
+			total += row.diskUsage
+		}
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("%d worktrees, %s", len(m.worktreeRows), formatBytes(total))))
+		b.WriteString("\n")
+	}
+
+	// The table only names worktrees; the selected one's full path shows below it
+	if row, ok := m.selectedWorktree(); ok && m.worktreeConfirm == "" {
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render(truncateRunes(row.worktree.Path, contentWidth)))
+	}
+
+	if m.worktreeConfirm != "" {
+		if row, ok := m.selectedWorktree(); ok {
+			prompt := fmt.Sprintf("Delete %s and its branch %s? [y/N]", filepath.Base(row.worktree.Path), row.worktree.Branch)
+			if m.worktreeConfirm == "reset" {
+				prompt = fmt.Sprintf("Reset %s to the default branch? [y/N]", filepath.Base(row.worktree.Path))
+				if row.dirty {
+					prompt = fmt.Sprintf("%s has %d uncommitted changes: [s]tash them and reset, [y] discard them and reset, [N]o", filepath.Base(row.worktree.Path), row.changes)
+				}
+			}
+			if m.worktreeConfirm == "gc" {
+				rows, dirty := m.gcWorktrees()
+				var size int64
+				for _, r := range rows {
+					size += r.diskUsage
+				}
+				prompt = fmt.Sprintf("Remove %d worktrees no task uses, idle for %d+ days (%s)? [y/N]", len(rows), m.config.Worktrees.GCDays, formatBytes(size))
+				if dirty > 0 {
+					prompt += fmt.Sprintf("  %d dirty ones are kept", dirty)
+				}
+			}
+			b.WriteString("\n")
+			b.WriteString(lipgloss.NewStyle().Foreground(colorWarning).Render(prompt))
+		}
+	}
+
+	// Deletes, resets and garbage collection run in the background
+	for _, line := range m.operationLines(contentWidth) {
+		b.WriteString("\n")
+		b.WriteString(line)
+	}
+
+	panel := m.renderPanel("Worktrees", b.String(), m.width, m.height-1, true)
+	help := helpStyle.Render("[j/k]navigate  [d]elete  [r]eset  [a]dopt into task  [g]c idle  [y]ank path  [R]efresh  [esc]back")
+	return lipgloss.JoinVertical(lipgloss.Left, panel, help)
+}
+
+// formatBytes formats a byte count as a short human-readable size
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
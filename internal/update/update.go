@@ -0,0 +1,346 @@
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the running flock version, set at build time with
+// -ldflags "-X github.com/dfowler/flock/internal/update.Version=v1.2.3"
+var Version = "dev"
+
+// releasesURL is the GitHub API endpoint for the latest flock release
+const releasesURL = "https://api.github.com/repos/dfowler/flock/releases/latest"
+
+// checkInterval is how often the cached update check hits GitHub
+const checkInterval = 24 * time.Hour
+
+// checksumSuffix names the asset holding another asset's SHA-256 (flock_linux_amd64.tar.gz.sha256)
+const checksumSuffix = ".sha256"
+
+// assetExts are the extensions a release build can have: an archive, or the bare binary
+var assetExts = []string{".tar.gz", ".tgz", ".zip", ".exe", ""}
+
+// Release is a published flock release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a downloadable file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Current returns the running version, falling back to the module version for `go install` builds
+func Current() string {
+	if Version != "dev" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return Version
+}
+
+// Latest fetches the latest release from GitHub
+func Latest(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check for updates: %s", resp.Status)
+	}
+
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("failed to read release: %w", err)
+	}
+	return &rel, nil
+}
+
+// Newer reports whether version latest is newer than current ("v1.2.3" style).
+// Development builds never report updates.
+func Newer(current, latest string) bool {
+	cur, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	lat, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	for i := range cur {
+		if lat[i] != cur[i] {
+			return lat[i] > cur[i]
+		}
+	}
+	return false
+}
+
+// parseVersion splits "v1.2.3" (with optional pre-release or build suffix) into its numbers
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// checkState is the cached result of the last update check
+type checkState struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+// CheckCached returns the latest release version if it is newer than the running one,
+// querying GitHub at most once a day and caching the answer in statePath
+func CheckCached(ctx context.Context, statePath string) (string, error) {
+	current := Current()
+	if _, ok := parseVersion(current); !ok {
+		return "", nil // Development build
+	}
+
+	var state checkState
+	if data, err := os.ReadFile(statePath); err == nil {
+		json.Unmarshal(data, &state)
+	}
+
+	if time.Since(state.CheckedAt) >= checkInterval {
+		rel, err := Latest(ctx)
+		if err != nil {
+			return "", err
+		}
+		state = checkState{CheckedAt: time.Now(), Latest: rel.TagName}
+		if data, err := json.Marshal(state); err == nil {
+			os.WriteFile(statePath, data, 0644)
+		}
+	}
+
+	if Newer(current, state.Latest) {
+		return state.Latest, nil
+	}
+	return "", nil
+}
+
+// AssetFor picks the release asset for an OS and architecture (e.g. flock_linux_amd64.tar.gz)
+func AssetFor(rel *Release, goos, goarch string) (*Asset, error) {
+	for i, asset := range rel.Assets {
+		if assetOS, assetArch, ok := parseAssetName(asset.Name); ok && assetOS == goos && assetArch == goarch {
+			return &rel.Assets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no build for %s/%s", rel.TagName, goos, goarch)
+}
+
+// parseAssetName splits a release asset named flock_<os>_<arch>.<ext> into its OS and
+// architecture. Other names, such as checksums or flock_linux_amd64v3.tar.gz, don't parse.
+func parseAssetName(name string) (goos, goarch string, ok bool) {
+	name = strings.ToLower(name)
+	for _, ext := range assetExts {
+		base, found := strings.CutSuffix(name, ext)
+		if !found {
+			continue
+		}
+		fields := strings.Split(base, "_")
+		if len(fields) != 3 || fields[0] != "flock" || strings.Contains(base, ".") {
+			return "", "", false
+		}
+		return fields[1], fields[2], fields[1] != "" && fields[2] != ""
+	}
+	return "", "", false
+}
+
+// ChecksumFor picks the asset holding the SHA-256 of asset. Releases without one can't be
+// verified, so they are an error rather than an unchecked update.
+func ChecksumFor(rel *Release, asset *Asset) (*Asset, error) {
+	for i := range rel.Assets {
+		if rel.Assets[i].Name == asset.Name+checksumSuffix {
+			return &rel.Assets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no checksum for %s", rel.TagName, asset.Name)
+}
+
+// Apply downloads an asset, checks it against the SHA-256 in checksum and replaces the
+// binary at exePath with it. The new binary is written next to the old one and renamed
+// over it, so a failed or corrupt download never leaves a broken binary behind.
+func Apply(ctx context.Context, asset, checksum *Asset, exePath string) error {
+	sum, err := download(ctx, checksum)
+	if err != nil {
+		return err
+	}
+	// sha256sum format: the hex digest, then optionally the file name
+	fields := strings.Fields(string(sum))
+	if len(fields) == 0 {
+		return fmt.Errorf("checksum %s is empty", checksum.Name)
+	}
+	want := strings.ToLower(fields[0])
+
+	data, err := download(ctx, asset)
+	if err != nil {
+		return err
+	}
+	got := sha256.Sum256(data)
+	if hex.EncodeToString(got[:]) != want {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %x", asset.Name, want, got)
+	}
+
+	var binary io.Reader = bytes.NewReader(data)
+	switch name := strings.ToLower(asset.Name); {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		if binary, err = extractBinary(binary, binaryName(runtime.GOOS)); err != nil {
+			return err
+		}
+	case strings.HasSuffix(name, ".zip"):
+		if binary, err = extractZipBinary(data, binaryName(runtime.GOOS)); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exePath), ".flock-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	var old string
+	if runtime.GOOS == "windows" {
+		// Windows can't replace a running binary, but it can move it aside
+		old = exePath + ".old"
+		os.Remove(old)
+		if err := os.Rename(exePath, old); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", exePath, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), exePath); err != nil {
+		if old != "" {
+			// Put the old binary back rather than leave nothing at exePath
+			os.Rename(old, exePath)
+		}
+		return fmt.Errorf("failed to replace %s: %w", exePath, err)
+	}
+	return nil
+}
+
+// download fetches a release asset
+func download(ctx context.Context, asset *Asset) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", asset.Name, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return data, nil
+}
+
+// binaryName is the name of the flock binary in a release archive for goos
+func binaryName(goos string) string {
+	if goos == "windows" {
+		return "flock.exe"
+	}
+	return "flock"
+}
+
+// extractBinary returns the binary called name from a .tar.gz release archive
+func extractBinary(r io.Reader, name string) (io.Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("archive has no %s binary", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == name {
+			return tr, nil
+		}
+	}
+}
+
+// extractZipBinary returns the binary called name from a .zip release archive
+func extractZipBinary(data []byte, name string) (io.Reader, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.Mode().IsRegular() && path.Base(f.Name) == name {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read archive: %w", err)
+			}
+			defer rc.Close()
+			content, err := io.ReadAll(rc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read archive: %w", err)
+			}
+			return bytes.NewReader(content), nil
+		}
+	}
+	return nil, fmt.Errorf("archive has no %s binary", name)
+}
@@ -0,0 +1,159 @@
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		expected        bool
+	}{
+		{"v1.2.3", "v1.2.4", true},
+		{"v1.2.3", "v1.10.0", true},
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.3.0", "v1.2.9", false},
+		{"v1.2.3-rc1", "v1.2.3", false},
+		{"dev", "v9.9.9", false},
+		{"v1.2.3", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := Newer(tt.current, tt.latest); got != tt.expected {
+			t.Errorf("Newer(%q, %q) expected %v, got %v", tt.current, tt.latest, tt.expected, got)
+		}
+	}
+}
+
+func TestAssetFor(t *testing.T) {
+	rel := &Release{TagName: "v1.0.0", Assets: []Asset{
+		{Name: "flock_darwin_arm64.tar.gz"},
+		{Name: "flock_linux_amd64v3.tar.gz"},
+		{Name: "flock_linux_amd64.tar.gz.sha256"},
+		{Name: "flock_linux_amd64.tar.gz"},
+		{Name: "flock_windows_amd64.zip"},
+		{Name: "flock_freebsd_arm64_debug.tar.gz"},
+	}}
+
+	tests := []struct {
+		goos, goarch string
+		want         string // "" when there is no build
+	}{
+		{"linux", "amd64", "flock_linux_amd64.tar.gz"},
+		{"darwin", "arm64", "flock_darwin_arm64.tar.gz"},
+		{"windows", "amd64", "flock_windows_amd64.zip"},
+		{"windows", "arm64", ""},
+		{"freebsd", "arm64", ""},
+	}
+	for _, tt := range tests {
+		asset, err := AssetFor(rel, tt.goos, tt.goarch)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s/%s: expected an error, got %s", tt.goos, tt.goarch, asset.Name)
+			}
+			continue
+		}
+		if err != nil || asset.Name != tt.want {
+			t.Errorf("%s/%s: expected %s, got %v (%v)", tt.goos, tt.goarch, tt.want, asset, err)
+		}
+	}
+
+	asset := &rel.Assets[3]
+
+	checksum, err := ChecksumFor(rel, asset)
+	if err != nil || checksum.Name != "flock_linux_amd64.tar.gz.sha256" {
+		t.Errorf("expected the archive's checksum, got %v (%v)", checksum, err)
+	}
+	if _, err := ChecksumFor(rel, &rel.Assets[0]); err == nil {
+		t.Errorf("expected an error for an archive without a checksum")
+	}
+}
+
+func TestApply(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	content := []byte("new binary")
+	tw.WriteHeader(&tar.Header{Name: "flock_linux_amd64/flock", Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+
+	sum := fmt.Sprintf("%x  flock_linux_amd64.tar.gz\n", sha256.Sum256(archive.Bytes()))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/archive":
+			w.Write(archive.Bytes())
+		case "/good.sha256":
+			w.Write([]byte(sum))
+		case "/bad.sha256":
+			w.Write([]byte(strings.Repeat("0", 64)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	exePath := filepath.Join(t.TempDir(), "flock")
+	if err := os.WriteFile(exePath, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	asset := &Asset{Name: "flock_linux_amd64.tar.gz", URL: server.URL + "/archive"}
+	for _, path := range []string{"/bad.sha256", "/missing.sha256"} {
+		checksum := &Asset{Name: asset.Name + ".sha256", URL: server.URL + path}
+		if err := Apply(context.Background(), asset, checksum, exePath); err == nil {
+			t.Errorf("%s: expected Apply to fail", path)
+		}
+		if got, _ := os.ReadFile(exePath); string(got) != "old binary" {
+			t.Errorf("%s: expected the binary to be left alone, got %q", path, got)
+		}
+	}
+
+	checksum := &Asset{Name: asset.Name + ".sha256", URL: server.URL + "/good.sha256"}
+	if err := Apply(context.Background(), asset, checksum, exePath); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	got, err := os.ReadFile(exePath)
+	if err != nil || string(got) != "new binary" {
+		t.Errorf("expected binary to be replaced, got %q (%v)", got, err)
+	}
+}
+
+func TestExtractZipBinary(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string]string{"flock_windows_amd64/README.md": "readme", "flock_windows_amd64/flock.exe": "new binary"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	binary, err := extractZipBinary(archive.Bytes(), "flock.exe")
+	if err != nil {
+		t.Fatalf("extractZipBinary failed: %v", err)
+	}
+	if got, _ := io.ReadAll(binary); string(got) != "new binary" {
+		t.Errorf("expected the binary, got %q", got)
+	}
+	if _, err := extractZipBinary(archive.Bytes(), "flock"); err == nil {
+		t.Errorf("expected an error for an archive without the binary")
+	}
+}
@@ -0,0 +1,272 @@
+// Package vault encrypts flock's files at rest with a key derived from a passphrase.
+// Sealed files are AES-256-GCM encrypted; the salt and a passphrase check live in
+// ~/.flock/vault.json. A nil *Vault reads and writes plaintext, so callers never
+// need to check whether encryption is enabled.
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	keySize    = 32
+	saltSize   = 16
+	iterations = 600000
+	checkText  = "flock vault"
+)
+
+// header starts every sealed file, telling it apart from plaintext
+var header = []byte("flock-vault-v1\n")
+
+var (
+	// ErrLocked is returned when reading a sealed file without an unlocked vault
+	ErrLocked = errors.New("file is encrypted; enable encrypt_at_rest and unlock with your passphrase")
+	// ErrPassphrase is returned by Unlock for a passphrase that doesn't match the vault
+	ErrPassphrase = errors.New("wrong passphrase")
+)
+
+// Vault seals and opens files with a passphrase-derived key
+type Vault struct {
+	aead cipher.AEAD
+	seal bool // Encrypt writes; false only decrypts, to migrate back to plaintext
+}
+
+// vaultFile is the on-disk description of a vault's key
+type vaultFile struct {
+	Salt       []byte `json:"salt"`
+	Iterations int    `json:"iterations"`
+	Check      []byte `json:"check"` // checkText sealed with the key
+}
+
+// Exists reports whether a vault has been created at path
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Create makes a new vault at path for passphrase, failing if one already exists
+func Create(path, passphrase string) (*Vault, error) {
+	if Exists(path) {
+		return nil, fmt.Errorf("vault already exists at %s", path)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	v, err := newVault(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	check, err := v.Seal([]byte(checkText))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(vaultFile{Salt: salt, Iterations: iterations, Check: check}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write vault: %w", err)
+	}
+	return v, nil
+}
+
+// Unlock opens the vault at path, returning ErrPassphrase if passphrase is wrong
+func Unlock(path, passphrase string) (*Vault, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	v, err := newVault(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	check, err := v.Open(file.Check)
+	if err != nil || string(check) != checkText {
+		return nil, ErrPassphrase
+	}
+	return v, nil
+}
+
+// newVault derives the key for passphrase
+func newVault(passphrase string, salt []byte, iter int) (*Vault, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iter, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{aead: aead, seal: true}, nil
+}
+
+// SetSealing controls whether writes are encrypted; with sealing off the vault only
+// decrypts, so turning encryption off moves files back to plaintext as they are written
+func (v *Vault) SetSealing(seal bool) {
+	if v != nil {
+		v.seal = seal
+	}
+}
+
+// Sealing reports whether writes are encrypted
+func (v *Vault) Sealing() bool {
+	return v != nil && v.seal
+}
+
+// IsSealed reports whether data was produced by Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+// Seal encrypts data
+func (v *Vault) Seal(data []byte) ([]byte, error) {
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := append(append([]byte{}, header...), nonce...)
+	return v.aead.Seal(sealed, nonce, data, nil), nil
+}
+
+// Open decrypts data produced by Seal
+func (v *Vault) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return nil, fmt.Errorf("data is not sealed")
+	}
+	data = data[len(header):]
+	if len(data) < v.aead.NonceSize() {
+		return nil, fmt.Errorf("sealed data is truncated")
+	}
+	nonce, ciphertext := data[:v.aead.NonceSize()], data[v.aead.NonceSize():]
+	plaintext, err := v.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// ReadFile reads a file, decrypting it if it is sealed. Plaintext files are
+// returned as is, so files written before encryption was enabled still load.
+func (v *Vault) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !IsSealed(data) {
+		return data, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrLocked)
+	}
+	plaintext, err := v.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plaintext, nil
+}
+
+// WriteFile writes a file, sealed if the vault is sealing. The file is replaced
+// atomically, so a crash mid-write leaves the previous version intact.
+func (v *Vault) WriteFile(path string, data []byte, perm os.FileMode) error {
+	if v.Sealing() {
+		sealed, err := v.Seal(data)
+		if err != nil {
+			return err
+		}
+		data = sealed
+	}
+	return writeAtomic(path, data, perm)
+}
+
+// SealFile encrypts a plaintext file in place (a no-op unless the vault is sealing)
+func (v *Vault) SealFile(path string) error {
+	if !v.Sealing() {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || IsSealed(data) {
+		return err
+	}
+	sealed, err := v.Seal(data)
+	if err != nil {
+		return err
+	}
+	return replace(path, sealed)
+}
+
+// OpenFile decrypts a sealed file in place, for tools that need plaintext
+// (agents and editors read prompt files directly)
+func (v *Vault) OpenFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil || !IsSealed(data) {
+		return err
+	}
+	if v == nil {
+		return fmt.Errorf("%s: %w", path, ErrLocked)
+	}
+	plaintext, err := v.Open(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return replace(path, plaintext)
+}
+
+// replace atomically swaps a file's content, keeping its permissions
+func replace(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, data, info.Mode().Perm())
+}
+
+// writeAtomic writes data to a temporary file beside path, flushes it to disk and
+// renames it over path, so readers see either the old contents or the new
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,68 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVault(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vault.json")
+
+	v, err := Create(path, "correct horse")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := Unlock(path, "wrong"); !errors.Is(err, ErrPassphrase) {
+		t.Errorf("expected ErrPassphrase, got %v", err)
+	}
+	if v, err = Unlock(path, "correct horse"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+
+	tasks := filepath.Join(dir, "tasks.json")
+	if err := v.WriteFile(tasks, []byte(`[{"name":"launch plan"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := os.ReadFile(tasks); !IsSealed(raw) {
+		t.Errorf("expected the file to be sealed on disk")
+	}
+	if data, err := v.ReadFile(tasks); err != nil || string(data) != `[{"name":"launch plan"}]` {
+		t.Errorf("expected the content back, got %q (%v)", data, err)
+	}
+	var locked *Vault
+	if _, err := locked.ReadFile(tasks); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked without a vault, got %v", err)
+	}
+
+	// Prompts are opened for agents while flock runs and sealed again afterwards
+	prompt := filepath.Join(dir, "001.md")
+	os.WriteFile(prompt, []byte("# Goal"), 0644)
+	if data, err := v.ReadFile(prompt); err != nil || string(data) != "# Goal" {
+		t.Errorf("expected plaintext files to read as is, got %q (%v)", data, err)
+	}
+	if err := v.SealFile(prompt); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := os.ReadFile(prompt); !IsSealed(raw) {
+		t.Errorf("expected SealFile to seal the prompt")
+	}
+	if err := v.OpenFile(prompt); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := os.ReadFile(prompt); string(raw) != "# Goal" {
+		t.Errorf("expected OpenFile to restore the prompt, got %q", raw)
+	}
+
+	// With sealing off, writes go back to plaintext
+	v.SetSealing(false)
+	v.SealFile(prompt)
+	v.WriteFile(tasks, []byte("[]"), 0644)
+	for _, file := range []string{prompt, tasks} {
+		if raw, _ := os.ReadFile(file); IsSealed(raw) {
+			t.Errorf("expected %s to stay plaintext with sealing off", filepath.Base(file))
+		}
+	}
+}
@@ -1,80 +1,140 @@
 package zellij
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
-)
+	"time"
 
-const (
-	defaultStatusDir = "/tmp/flock"
-	layoutFileName   = "ai_with_editor.kdl"
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/runner"
 )
 
+var _ multiplexer.Backend = (*Controller)(nil)
+
+const layoutFileName = "ai_with_editor.kdl"
+
+// DefaultTimeout is how long a zellij command may take before it counts as hung
+const DefaultTimeout = 5 * time.Second
+
+// retryDelay is the pause before an idempotent command that failed is tried again
+const retryDelay = 200 * time.Millisecond
+
 // Controller manages zellij tabs for AI agent sessions
 type Controller struct {
 	layoutPath    string
 	statusDir     string
 	controllerTab string
+	commands      runner.Runner
+	timeout       time.Duration // Limit for each zellij command
 }
 
-// NewController creates a new zellij controller
+// NewController creates a new zellij controller. The agent tab layout is kept in
+// configDir (~/.flock/zellij/layouts), independent of where flock was started.
 func NewController(configDir string) *Controller {
-	layoutPath := filepath.Join(configDir, "zellij", "layouts", layoutFileName)
+	layoutPath := LayoutPath(configDir)
 	return &Controller{
 		layoutPath:    layoutPath,
-		statusDir:     defaultStatusDir,
+		statusDir:     multiplexer.SessionStatusDir(""),
 		controllerTab: "flock",
+		commands:      runner.Exec{},
+		timeout:       DefaultTimeout,
+	}
+}
+
+// SetRunner replaces the runner used for zellij commands
+func (c *Controller) SetRunner(r runner.Runner) {
+	c.commands = r
+}
+
+// SetTimeout changes how long each zellij command may take (DefaultTimeout if d <= 0)
+func (c *Controller) SetTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultTimeout
+	}
+	c.timeout = d
+}
+
+// run runs a zellij command that changes something, such as opening a tab or typing
+// into it. It isn't retried, since running it twice would do it twice.
+func (c *Controller) run(args ...string) error {
+	_, err := c.output(false, args...)
+	return err
+}
+
+// query runs a zellij command that is safe to repeat, such as switching tabs or listing
+// them, trying once more if it fails
+func (c *Controller) query(args ...string) ([]byte, error) {
+	return c.output(true, args...)
+}
+
+// output runs a zellij command with the controller's timeout, so a busy zellij server
+// can't freeze the dashboard. A timeout wraps multiplexer.ErrTimeout.
+func (c *Controller) output(retry bool, args ...string) ([]byte, error) {
+	cmd := runner.Command("zellij", args...)
+	attempts := 1
+	if retry {
+		attempts = 2
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(retryDelay)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		var output []byte
+		output, err = c.commands.Output(ctx, cmd)
+		timedOut := ctx.Err() == context.DeadlineExceeded
+		cancel()
+		if err == nil {
+			return output, nil
+		}
+		if timedOut {
+			err = fmt.Errorf("zellij %s did not respond within %s: %w", strings.Join(args[:min(2, len(args))], " "), c.timeout, multiplexer.ErrTimeout)
+		}
 	}
+	return nil, err
+}
+
+// Name returns the backend name used in config
+func (c *Controller) Name() string {
+	return "zellij"
 }
 
-// EnsureStatusDir creates the status directory if it doesn't exist
+// EnsureStatusDir creates the status directory if it doesn't exist, private to the user
 func (c *Controller) EnsureStatusDir() error {
-	return os.MkdirAll(c.statusDir, 0755)
+	return multiplexer.EnsureStatusDir(c.statusDir)
 }
 
 // NewTab creates a new zellij tab for a task
-// promptOrFile is either a path to a markdown file (if isFile=true) or inline prompt text (if isFile=false)
-func (c *Controller) NewTab(taskID, taskName, tabName, promptOrFile, cwd string, isFile bool) error {
+func (c *Controller) NewTab(l multiplexer.Launch) error {
 	if err := c.EnsureStatusDir(); err != nil {
 		return fmt.Errorf("failed to create status dir: %w", err)
 	}
 
 	// Create new tab with the AI session layout
-	cmd := exec.Command("zellij", "action", "new-tab", "--name", tabName, "--layout", c.layoutPath)
-	if err := cmd.Run(); err != nil {
+	if err := installLayout(c.layoutPath); err != nil {
+		return err
+	}
+	if err := c.run("action", "new-tab", "--name", l.TabName, "--layout", c.layoutPath); err != nil {
 		return fmt.Errorf("failed to create tab: %w", err)
 	}
 
-	// Focus the claude pane (right pane in the vertical split)
-	focusCmd := exec.Command("zellij", "action", "focus-next-pane")
-	if err := focusCmd.Run(); err != nil {
-		return fmt.Errorf("failed to focus claude pane: %w", err)
+	// Focus the agent pane (right pane in the vertical split)
+	if err := c.run("action", "focus-next-pane"); err != nil {
+		return fmt.Errorf("failed to focus agent pane: %w", err)
 	}
 
-	// Write the claude command with environment variables to the pane
-	// Use export to ensure env vars are available to hook subprocesses
-	// Global hooks at ~/.flock/hooks/ check for FLOCK_TASK_ID
-	var claudePrompt string
-	if isFile {
-		// Tell Claude to review the prompt file using @ syntax
-		claudePrompt = fmt.Sprintf("Review and complete the task described in @%s", promptOrFile)
-	} else {
-		// Legacy: use inline prompt directly
-		claudePrompt = promptOrFile
-	}
-	claudeCmd := fmt.Sprintf("cd %q && export FLOCK_TASK_ID=%s FLOCK_TASK_NAME=%q FLOCK_TAB_NAME=%s FLOCK_STATUS_DIR=%s && claude %q",
-		cwd, taskID, taskName, tabName, c.statusDir, claudePrompt)
-	writeCmd := exec.Command("zellij", "action", "write-chars", claudeCmd)
-	if err := writeCmd.Run(); err != nil {
+	// Write the agent command with environment variables to the pane
+	agentCmd := multiplexer.AgentCommand(l, c.statusDir)
+	if err := c.run("action", "write-chars", agentCmd); err != nil {
 		return fmt.Errorf("failed to write command: %w", err)
 	}
 
-	// Send enter to execute
-	enterCmd := exec.Command("zellij", "action", "write", "10") // ASCII newline
-	if err := enterCmd.Run(); err != nil {
+	// Send enter (ASCII newline) to execute
+	if err := c.run("action", "write", "10"); err != nil {
 		return fmt.Errorf("failed to send enter: %w", err)
 	}
 
@@ -88,8 +148,7 @@ func (c *Controller) NewTab(taskID, taskName, tabName, promptOrFile, cwd string,
 
 // GoToTab switches to the specified tab
 func (c *Controller) GoToTab(tabName string) error {
-	cmd := exec.Command("zellij", "action", "go-to-tab-name", tabName)
-	if err := cmd.Run(); err != nil {
+	if _, err := c.query("action", "go-to-tab-name", tabName); err != nil {
 		return fmt.Errorf("failed to go to tab %s: %w", tabName, err)
 	}
 	return nil
@@ -114,36 +173,141 @@ func (c *Controller) CloseTab(tabName string) error {
 	}
 
 	// Then close it
-	cmd := exec.Command("zellij", "action", "close-tab")
-	if err := cmd.Run(); err != nil {
+	if err := c.run("action", "close-tab"); err != nil {
 		return fmt.Errorf("failed to close tab %s: %w", tabName, err)
 	}
 
 	return nil
 }
 
+// WriteChars types text into the given tab's focused pane, then returns to the controller
+func (c *Controller) WriteChars(tabName, text string) error {
+	if err := c.GoToTab(tabName); err != nil {
+		return err
+	}
+
+	if err := c.run("action", "write-chars", text); err != nil {
+		return fmt.Errorf("failed to write to tab %s: %w", tabName, err)
+	}
+	return c.GoToController()
+}
+
+// keyBytes are the bytes zellij writes for each special key
+var keyBytes = map[multiplexer.Key]string{
+	multiplexer.KeyEnter:  "13",
+	multiplexer.KeyEscape: "27",
+	multiplexer.KeyCtrlC:  "3",
+}
+
+// SendKey presses a special key in the given tab's focused pane, then returns to the controller
+func (c *Controller) SendKey(tabName string, key multiplexer.Key) error {
+	b, ok := keyBytes[key]
+	if !ok {
+		return fmt.Errorf("unsupported key %q", key)
+	}
+	if err := c.GoToTab(tabName); err != nil {
+		return err
+	}
+
+	if err := c.run("action", "write", b); err != nil {
+		return fmt.Errorf("failed to send %s to tab %s: %w", key, tabName, err)
+	}
+	return c.GoToController()
+}
+
+// DumpTab saves the full scrollback of the focused pane in the given tab to path
+func (c *Controller) DumpTab(tabName, path string) error {
+	if err := c.GoToTab(tabName); err != nil {
+		return err
+	}
+
+	if _, err := c.query("action", "dump-screen", "--full", path); err != nil {
+		return fmt.Errorf("failed to dump tab %s: %w", tabName, err)
+	}
+	return nil
+}
+
 // TabExists checks if a tab with the given name exists
 func (c *Controller) TabExists(tabName string) bool {
-	cmd := exec.Command("zellij", "action", "query-tab-names")
-	output, err := cmd.Output()
+	names, err := c.TabNames()
 	if err != nil {
 		return false
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.TrimSpace(line) == tabName {
+	for _, name := range names {
+		if name == tabName {
 			return true
 		}
 	}
 	return false
 }
 
+// TabNames returns the names of all tabs in the current session, in tab order
+func (c *Controller) TabNames() ([]string, error) {
+	output, err := c.query("action", "query-tab-names")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tab names: %w", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// CurrentTabName returns the name of the focused tab
+// zellij has no direct query for this, so it is read from the dumped layout
+func (c *Controller) CurrentTabName() (string, error) {
+	output, err := c.query("action", "dump-layout")
+	if err != nil {
+		return "", fmt.Errorf("failed to dump layout: %w", err)
+	}
+
+	if name := parseFocusedTab(string(output)); name != "" {
+		return name, nil
+	}
+	return "", fmt.Errorf("no focused tab found in layout")
+}
+
+// ControllerFocused reports whether the controller tab is the focused tab
+func (c *Controller) ControllerFocused() bool {
+	name, err := c.CurrentTabName()
+	return err == nil && name == c.controllerTab
+}
+
+// parseFocusedTab extracts the name of the tab marked focus=true from a KDL layout dump
+// Example line: tab name="agent-001-fixTests" focus=true hide_floating_panes=true {
+func parseFocusedTab(layout string) string {
+	for _, line := range strings.Split(layout, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "tab ") || !strings.Contains(line, "focus=true") {
+			continue
+		}
+		start := strings.Index(line, `name="`)
+		if start == -1 {
+			continue
+		}
+		rest := line[start+len(`name="`):]
+		if end := strings.Index(rest, `"`); end != -1 {
+			return rest[:end]
+		}
+	}
+	return ""
+}
+
 // StatusDir returns the status directory path
 func (c *Controller) StatusDir() string {
 	return c.statusDir
 }
 
+// SetStatusDir changes where agents write their status files
+func (c *Controller) SetStatusDir(dir string) {
+	c.statusDir = dir
+}
+
 // SetControllerTab sets the name of the controller tab
 func (c *Controller) SetControllerTab(name string) {
 	c.controllerTab = name
@@ -151,13 +315,17 @@ func (c *Controller) SetControllerTab(name string) {
 
 // RenameCurrentTab renames the current tab
 func (c *Controller) RenameCurrentTab(name string) error {
-	cmd := exec.Command("zellij", "action", "rename-tab", name)
-	if err := cmd.Run(); err != nil {
+	if _, err := c.query("action", "rename-tab", name); err != nil {
 		return fmt.Errorf("failed to rename tab: %w", err)
 	}
 	return nil
 }
 
+// SessionName returns the name of the zellij session flock runs in
+func (c *Controller) SessionName() string {
+	return os.Getenv("ZELLIJ_SESSION_NAME")
+}
+
 // IsInZellij checks if we're running inside a zellij session
 func IsInZellij() bool {
 	return os.Getenv("ZELLIJ") != ""
@@ -165,9 +333,5 @@ func IsInZellij() bool {
 
 // DeleteStatusFile removes the status file for a task
 func (c *Controller) DeleteStatusFile(taskID string) error {
-	statusFile := filepath.Join(c.statusDir, taskID+".status")
-	if err := os.Remove(statusFile); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete status file: %w", err)
-	}
-	return nil
+	return multiplexer.DeleteStatusFile(c.statusDir, taskID)
 }
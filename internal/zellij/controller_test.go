@@ -0,0 +1,87 @@
+package zellij
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dfowler/flock/internal/multiplexer"
+	"github.com/dfowler/flock/internal/runner"
+)
+
+func TestParseFocusedTab(t *testing.T) {
+	tests := []struct {
+		layout   string
+		expected string
+	}{
+		{
+			layout: `layout {
+    tab name="flock" hide_floating_panes=true {
+        pane
+    }
+    tab name="agent-001-fixTests" focus=true hide_floating_panes=true {
+        pane
+    }
+}`,
+			expected: "agent-001-fixTests",
+		},
+		{
+			layout:   `tab name="flock" focus=true {`,
+			expected: "flock",
+		},
+		{
+			layout:   `tab name="flock" {`,
+			expected: "",
+		},
+		{
+			layout:   "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		result := parseFocusedTab(tt.layout)
+		if result != tt.expected {
+			t.Errorf("parseFocusedTab() = %q, expected %q", result, tt.expected)
+		}
+	}
+}
+
+// hangingRunner is a zellij that never answers, counting the commands it was given
+type hangingRunner struct {
+	runner.Fake
+	calls int
+}
+
+func (h *hangingRunner) Output(ctx context.Context, cmd runner.Cmd) ([]byte, error) {
+	h.calls++
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(c *Controller) error
+		expected int // Attempts before giving up
+	}{
+		{"query is retried", func(c *Controller) error { return c.GoToTab("flock") }, 2},
+		{"action is not retried", func(c *Controller) error { return c.run("action", "close-tab") }, 1},
+	}
+
+	for _, tt := range tests {
+		h := &hangingRunner{}
+		c := NewController(t.TempDir())
+		c.SetRunner(h)
+		c.SetTimeout(10 * time.Millisecond)
+
+		err := tt.call(c)
+		if !errors.Is(err, multiplexer.ErrTimeout) {
+			t.Errorf("%s: expected ErrTimeout, got %v", tt.name, err)
+		}
+		if h.calls != tt.expected {
+			t.Errorf("%s: expected %d attempts, got %d", tt.name, tt.expected, h.calls)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package zellij
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// layout is the agent tab layout, with the keybindings back to the dashboard.
+// It ships inside the binary so flock works from any directory.
+//
+//go:embed ai_with_editor.kdl
+var layout string
+
+// LayoutPath returns where the agent tab layout is kept under configDir
+// (~/.flock/zellij/layouts/ai_with_editor.kdl)
+func LayoutPath(configDir string) string {
+	return filepath.Join(configDir, "zellij", "layouts", layoutFileName)
+}
+
+// LayoutCurrent reports whether the agent tab layout at path is the one this flock ships.
+// Before the first agent tab opens there is no layout, and the error is os.ErrNotExist.
+func LayoutCurrent(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return string(data) == layout, nil
+}
+
+// installLayout writes the agent tab layout to path unless it is already there and current
+func installLayout(path string) error {
+	if data, err := os.ReadFile(path); err == nil && string(data) == layout {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create layouts directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(layout), 0644); err != nil {
+		return fmt.Errorf("failed to write layout: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,6 @@
 #!/bin/bash
 
-ZELLIJ_LAYOUTS="${ZELLIJ_LAYOUTS:-$(dirname "$0")/layouts}"
+ZELLIJ_LAYOUTS="${ZELLIJ_LAYOUTS:-$HOME/.flock/zellij/layouts}"
 
 new-ai-tab() {
     zellij action new-tab -n "ai_agent_$1" \